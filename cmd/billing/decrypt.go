@@ -0,0 +1,98 @@
+package main
+
+import (
+	"billing_system_test_task/internal/repositories/reports"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const decryptUsage = `Usage: billing decrypt -in <file> [-out <file>] (-passphrase-env <name> | -key-env <name>)
+
+Decrypts report downloaded with encrypt=passphrase or encrypt=key.
+Passphrase or base64 master key (REPORT_ENCRYPTION_KEY) is read from the given environment variable.
+Output defaults to input name without .enc extension, "-" writes to stdout.
+`
+
+// runDecrypt decrypts encrypted report and returns exit code
+func runDecrypt(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("decrypt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, decryptUsage) }
+	inPath := flags.String("in", "", "path to encrypted report")
+	outPath := flags.String("out", "", "path to decrypted report")
+	passphraseEnv := flags.String("passphrase-env", "", "environment variable with passphrase")
+	keyEnv := flags.String("key-env", "", "environment variable with base64 master key")
+	if parseErr := flags.Parse(args); parseErr != nil {
+		return 2
+	}
+	if *inPath == "" || (*passphraseEnv == "") == (*keyEnv == "") {
+		flags.Usage()
+		return 2
+	}
+	if *outPath == "" {
+		*outPath = strings.TrimSuffix(*inPath, ".enc")
+		if *outPath == *inPath {
+			*outPath += ".dec"
+		}
+	}
+
+	if decryptErr := decryptReport(*inPath, *outPath, *passphraseEnv, *keyEnv, stdout); decryptErr != nil {
+		fmt.Fprintf(stderr, "FAILED: %s\n", decryptErr)
+		return 1
+	}
+	if *outPath != "-" {
+		fmt.Fprintf(stdout, "OK: %s\n", *outPath)
+	}
+	return 0
+}
+
+func decryptReport(inPath, outPath, passphraseEnv, keyEnv string, stdout io.Writer) error {
+	var (
+		passphrase string
+		key        []byte
+	)
+	if passphraseEnv != "" {
+		passphrase = os.Getenv(passphraseEnv)
+		if passphrase == "" {
+			return fmt.Errorf("environment variable %s is empty", passphraseEnv)
+		}
+	} else {
+		var keyErr error
+		key, keyErr = reports.ParseEncryptionKey(os.Getenv(keyEnv))
+		if keyErr != nil {
+			return keyErr
+		}
+		if key == nil {
+			return fmt.Errorf("environment variable %s is empty", keyEnv)
+		}
+	}
+
+	in, openErr := os.Open(inPath)
+	if openErr != nil {
+		return fmt.Errorf("error of report opening: %s", openErr)
+	}
+	defer in.Close()
+	decrypter, decryptErr := reports.NewDecrypter(in, passphrase, key)
+	if decryptErr != nil {
+		return decryptErr
+	}
+
+	if outPath == "-" {
+		_, copyErr := io.Copy(stdout, decrypter)
+		return copyErr
+	}
+	out, createErr := os.Create(outPath)
+	if createErr != nil {
+		return fmt.Errorf("error of output creation: %s", createErr)
+	}
+	if _, copyErr := io.Copy(out, decrypter); copyErr != nil {
+		// Partially decrypted report is not authenticated
+		out.Close()
+		os.Remove(outPath)
+		return copyErr
+	}
+	return out.Close()
+}
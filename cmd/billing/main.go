@@ -4,13 +4,23 @@ import (
 	"billing_system_test_task/internal/app"
 	"billing_system_test_task/internal/entities"
 	"log"
+	"os"
 )
 
 func main() {
-	config := entities.NewEnvConfig()
-	loadEnvErr := config.LoadEnvVariables("cmd")
-	if loadEnvErr != nil {
-		log.Fatal("Unable to variables from .env file")
+	if len(os.Args) > 1 && os.Args[1] == "verify" {
+		os.Exit(runVerify(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "decrypt" {
+		os.Exit(runDecrypt(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	config, configErr := entities.LoadConfig(entities.ProjectDir("cmd"), os.Args[1:], os.Stderr)
+	if configErr != nil {
+		log.Fatal(configErr)
 	}
 	app := app.NewApp(config)
 	app.Run()
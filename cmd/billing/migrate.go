@@ -0,0 +1,102 @@
+package main
+
+import (
+	"billing_system_test_task/internal/app"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/migrator"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const migrateUsage = `Usage: billing migrate up|down|status|to <version>
+
+Applies SQL migrations embedded into the binary to the database configured for the server.
+  up            applies all pending migrations
+  down          reverts the last applied migration
+  status        prints version of the database and states of migrations
+  to <version>  applies or reverts migrations until the database has the version, 0 reverts all migrations
+Concurrent runs are serialized by advisory lock; each migration is applied in its own transaction.
+`
+
+// runMigrate applies or reverts migrations and returns exit code
+func runMigrate(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || (args[0] == "to") != (len(args) == 2) || len(args) > 2 {
+		fmt.Fprint(stderr, migrateUsage)
+		return 2
+	}
+
+	config, configErr := entities.LoadConfig(entities.ProjectDir("cmd"), nil, stderr)
+	if configErr != nil {
+		fmt.Fprintf(stderr, "FAILED: %s\n", configErr)
+		return 1
+	}
+	m, closeDB, openErr := app.NewMigrator(config)
+	if openErr != nil {
+		fmt.Fprintf(stderr, "FAILED: %s\n", openErr)
+		return 1
+	}
+	defer closeDB()
+	ctx := context.Background()
+
+	var (
+		steps  []*migrator.Step
+		runErr error
+	)
+	switch args[0] {
+	case "up":
+		steps, runErr = m.Up(ctx)
+	case "down":
+		steps, runErr = m.Down(ctx)
+	case "to":
+		version, parseErr := strconv.ParseInt(args[1], 10, 64)
+		if parseErr != nil {
+			fmt.Fprint(stderr, migrateUsage)
+			return 2
+		}
+		steps, runErr = m.To(ctx, version)
+	case "status":
+		return printMigrationsStatus(ctx, m, stdout, stderr)
+	default:
+		fmt.Fprint(stderr, migrateUsage)
+		return 2
+	}
+
+	for _, step := range steps {
+		action := "Reverted"
+		if step.Up {
+			action = "Applied"
+		}
+		fmt.Fprintf(stdout, "%s %d_%s\n", action, step.Migration.Version, step.Migration.Name)
+	}
+	if runErr != nil {
+		fmt.Fprintf(stderr, "FAILED: %s\n", runErr)
+		return 1
+	}
+	if len(steps) == 0 {
+		fmt.Fprintln(stdout, "OK: no migrations to run")
+	}
+	return 0
+}
+
+func printMigrationsStatus(ctx context.Context, m *migrator.Migrator, stdout, stderr io.Writer) int {
+	status, statusErr := m.Status(ctx)
+	if statusErr != nil {
+		fmt.Fprintf(stderr, "FAILED: %s\n", statusErr)
+		return 1
+	}
+	dirty := ""
+	if status.Dirty {
+		dirty = " (dirty)"
+	}
+	fmt.Fprintf(stdout, "Version: %d%s\n", status.Version, dirty)
+	for _, migration := range status.Migrations {
+		state := "pending"
+		if migration.Applied {
+			state = "applied"
+		}
+		fmt.Fprintf(stdout, "%-8s %d_%s\n", state, migration.Version, migration.Name)
+	}
+	return 0
+}
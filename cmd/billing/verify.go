@@ -0,0 +1,86 @@
+package main
+
+import (
+	"billing_system_test_task/internal/repositories/reports"
+	"crypto/ed25519"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const verifyUsage = `Usage: billing verify -report <file> -public-key <base64> (-manifest <file> -manifest-signature <value> | -signature <value>)
+
+Checks report's SHA-256 checksum and Ed25519 signature.
+Public key is returned by GET /api/reports/public-key, manifest is base64 json of X-Report-Manifest header
+or its decoded content saved as is, manifest signature is value of X-Report-Manifest-Signature header,
+signature is value of X-Report-Signature header.
+`
+
+// runVerify verifies signed report and returns exit code
+func runVerify(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("verify", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, verifyUsage) }
+	reportPath := flags.String("report", "", "path to report file")
+	publicKeyStr := flags.String("public-key", "", "base64 Ed25519 public key")
+	manifestPath := flags.String("manifest", "", "path to report's manifest")
+	manifestSignatureStr := flags.String("manifest-signature", "", "manifest's signature")
+	signatureStr := flags.String("signature", "", "report's signature")
+	if parseErr := flags.Parse(args); parseErr != nil {
+		return 2
+	}
+	if *reportPath == "" || *publicKeyStr == "" || (*manifestPath == "") == (*signatureStr == "") || (*manifestPath == "") != (*manifestSignatureStr == "") {
+		flags.Usage()
+		return 2
+	}
+
+	if verifyErr := verifyReport(*reportPath, *publicKeyStr, *manifestPath, *manifestSignatureStr, *signatureStr); verifyErr != nil {
+		fmt.Fprintf(stderr, "FAILED: %s\n", verifyErr)
+		return 1
+	}
+	fmt.Fprintf(stdout, "OK: %s\n", *reportPath)
+	return 0
+}
+
+func verifyReport(reportPath, publicKeyStr, manifestPath, manifestSignatureStr, signatureStr string) error {
+	publicKey, decodeErr := base64.StdEncoding.DecodeString(publicKeyStr)
+	if decodeErr != nil {
+		return fmt.Errorf("error of public key decoding: %s", decodeErr)
+	}
+	report, openErr := os.Open(reportPath)
+	if openErr != nil {
+		return fmt.Errorf("error of report opening: %s", openErr)
+	}
+	defer report.Close()
+
+	if manifestPath != "" {
+		data, readErr := os.ReadFile(manifestPath)
+		if readErr != nil {
+			return fmt.Errorf("error of manifest reading: %s", readErr)
+		}
+		// Manifest is trusted only when its own signature is valid
+		manifestData, decodeErr := reports.DecodeManifest(data)
+		if decodeErr != nil {
+			return decodeErr
+		}
+		manifestSignature, manifestSignatureErr := reports.ParseSignature(manifestSignatureStr)
+		if manifestSignatureErr != nil {
+			return manifestSignatureErr
+		}
+		if verifyErr := reports.VerifyManifestSignature(manifestData, manifestSignature, ed25519.PublicKey(publicKey)); verifyErr != nil {
+			return verifyErr
+		}
+		manifest, manifestErr := reports.ParseManifest(manifestData)
+		if manifestErr != nil {
+			return manifestErr
+		}
+		return reports.VerifyManifest(report, manifest, ed25519.PublicKey(publicKey))
+	}
+	signature, signatureErr := reports.ParseSignature(signatureStr)
+	if signatureErr != nil {
+		return signatureErr
+	}
+	return reports.VerifyReport(report, nil, signature, ed25519.PublicKey(publicKey))
+}
@@ -0,0 +1,431 @@
+package main
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/transport/http/forms"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// command executes subcommand and returns exit code
+type command func(args []string, stdout, stderr io.Writer, open openFunc) int
+
+var commands = map[string]command{
+	"create-user": runCreateUser,
+	"enroll":      runEnroll,
+	"transfer":    runTransfer,
+	"show-user":   runShowUser,
+	"show-wallet": runShowWallet,
+	"export":      runExport,
+	"reconcile":   runReconcile,
+	"freeze":      setFrozenCommand("freeze", true),
+	"unfreeze":    setFrozenCommand("unfreeze", false),
+}
+
+// walletOutput represents printed wallet
+type walletOutput struct {
+	ID       int             `json:"id"`
+	UserID   int             `json:"user_id"`
+	Balance  decimal.Decimal `json:"balance"`
+	Currency string          `json:"currency"`
+	Frozen   bool            `json:"frozen"`
+}
+
+// userOutput represents printed user; dry run marks result of rolled back enrollment
+type userOutput struct {
+	ID     int           `json:"id"`
+	Email  string        `json:"email"`
+	Wallet *walletOutput `json:"wallet"`
+	DryRun bool          `json:"dry_run,omitempty"`
+}
+
+// transferOutput represents printed transfer
+type transferOutput struct {
+	WalletFrom int             `json:"wallet_from"`
+	WalletTo   int             `json:"wallet_to"`
+	Amount     decimal.Decimal `json:"amount"`
+	DryRun     bool            `json:"dry_run,omitempty"`
+}
+
+// exportOutput represents written report
+type exportOutput struct {
+	File        string `json:"file"`
+	ContentType string `json:"content_type"`
+	Size        string `json:"size"`
+	SHA256      string `json:"sha256,omitempty"`
+}
+
+// discrepancyOutput represents wallet whose balance differs from its operations
+type discrepancyOutput struct {
+	WalletID   int             `json:"wallet_id"`
+	Balance    decimal.Decimal `json:"balance"`
+	Ledger     decimal.Decimal `json:"ledger"`
+	Difference decimal.Decimal `json:"difference"`
+}
+
+// newFlags returns flags of the command with common -json flag
+func newFlags(name string, stderr io.Writer) (*flag.FlagSet, *bool) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage of billingctl %s:\n", name)
+		flags.PrintDefaults()
+	}
+	asJSON := flags.Bool("json", false, "print json output")
+	return flags, asJSON
+}
+
+func runCreateUser(args []string, stdout, stderr io.Writer, open openFunc) int {
+	flags, asJSON := newFlags("create-user", stderr)
+	email := flags.String("email", "", "email of the user")
+	if flags.Parse(args) != nil {
+		return 2
+	}
+	userForm := forms.UserForm{Email: *email}
+	if formErr := userForm.Submit(); formErr != nil {
+		return failForm(stderr, formErr)
+	}
+
+	uses, closeUses, openErr := open(false)
+	if openErr != nil {
+		return fail(stderr, openErr)
+	}
+	defer closeUses()
+	user, createErr := uses.users.Create(adminContext(), userForm.Email)
+	if createErr != nil {
+		return failUseCase(stderr, createErr)
+	}
+	return printOutput(stdout, *asJSON, newUserOutput(user, false))
+}
+
+func runEnroll(args []string, stdout, stderr io.Writer, open openFunc) int {
+	flags, asJSON := newFlags("enroll", stderr)
+	userID := flags.Int("user", 0, "id of the user")
+	amountStr := flags.String("amount", "", "deposited amount")
+	dryRun := flags.Bool("dry-run", false, "roll back enrollment after it is done")
+	if flags.Parse(args) != nil {
+		return 2
+	}
+	amount, amountErr := decimal.NewFromString(*amountStr)
+	if amountErr != nil {
+		return fail(stderr, fmt.Errorf("amount: %s", amountErr))
+	}
+	enrollForm := forms.EnrollForm{Amount: amount}
+	if formErr := enrollForm.Submit(); formErr != nil {
+		return failForm(stderr, formErr)
+	}
+
+	uses, closeUses, openErr := open(*dryRun)
+	if openErr != nil {
+		return fail(stderr, openErr)
+	}
+	defer closeUses()
+	user, enrollErr := uses.users.Enroll(adminContext(), *userID, enrollForm.Amount)
+	if enrollErr != nil {
+		return failUseCase(stderr, enrollErr)
+	}
+	return printOutput(stdout, *asJSON, newUserOutput(user, *dryRun))
+}
+
+func runTransfer(args []string, stdout, stderr io.Writer, open openFunc) int {
+	flags, asJSON := newFlags("transfer", stderr)
+	walletFrom := flags.Int("from", 0, "id of the source wallet")
+	walletTo := flags.Int("to", 0, "id of the destination wallet")
+	amountStr := flags.String("amount", "", "transferred amount")
+	dryRun := flags.Bool("dry-run", false, "roll back transfer after it is done")
+	if flags.Parse(args) != nil {
+		return 2
+	}
+	amount, amountErr := decimal.NewFromString(*amountStr)
+	if amountErr != nil {
+		return fail(stderr, fmt.Errorf("amount: %s", amountErr))
+	}
+	walletForm := forms.WalletForm{WalletFrom: *walletFrom, WalletTo: *walletTo, Amount: amount}
+	if formErr := walletForm.Submit(); formErr != nil {
+		return failForm(stderr, formErr)
+	}
+
+	uses, closeUses, openErr := open(*dryRun)
+	if openErr != nil {
+		return fail(stderr, openErr)
+	}
+	defer closeUses()
+	if _, transferErr := uses.wallets.Transfer(adminContext(), walletForm.WalletFrom, walletForm.WalletTo, walletForm.Amount); transferErr != nil {
+		return failUseCase(stderr, transferErr)
+	}
+	return printOutput(stdout, *asJSON, &transferOutput{
+		WalletFrom: walletForm.WalletFrom,
+		WalletTo:   walletForm.WalletTo,
+		Amount:     walletForm.Amount,
+		DryRun:     *dryRun,
+	})
+}
+
+func runShowUser(args []string, stdout, stderr io.Writer, open openFunc) int {
+	flags, asJSON := newFlags("show-user", stderr)
+	userID := flags.Int("user", 0, "id of the user")
+	if flags.Parse(args) != nil {
+		return 2
+	}
+
+	uses, closeUses, openErr := open(false)
+	if openErr != nil {
+		return fail(stderr, openErr)
+	}
+	defer closeUses()
+	user, getErr := uses.users.Get(adminContext(), *userID)
+	if getErr != nil {
+		return failUseCase(stderr, getErr)
+	}
+	return printOutput(stdout, *asJSON, newUserOutput(user, false))
+}
+
+func runShowWallet(args []string, stdout, stderr io.Writer, open openFunc) int {
+	flags, asJSON := newFlags("show-wallet", stderr)
+	walletID := flags.Int("wallet", 0, "id of the wallet")
+	if flags.Parse(args) != nil {
+		return 2
+	}
+
+	uses, closeUses, openErr := open(false)
+	if openErr != nil {
+		return fail(stderr, openErr)
+	}
+	defer closeUses()
+	wallet, getErr := uses.wallets.Get(adminContext(), *walletID)
+	if getErr != nil {
+		return failUseCase(stderr, getErr)
+	}
+	return printOutput(stdout, *asJSON, newWalletOutput(wallet))
+}
+
+func runExport(args []string, stdout, stderr io.Writer, open openFunc) int {
+	flags, asJSON := newFlags("export", stderr)
+	outPath := flags.String("o", "", "path to written report, \"-\" writes to stdout")
+	format := flags.String("format", "", "format of the report (default csv)")
+	walletID := flags.Int("wallet", 0, "wallet of operations")
+	from := flags.String("from", "", "start date of the period (YYYY-MM-DD)")
+	to := flags.String("to", "", "end date of the period (YYYY-MM-DD)")
+	rawQuery := flags.String("query", "", "other parameters of GET /api/operations/, e.g. \"compress=gzip&manifest=true\"")
+	if flags.Parse(args) != nil {
+		return 2
+	}
+	if *outPath == "" {
+		flags.Usage()
+		return 2
+	}
+	query, queryErr := url.ParseQuery(*rawQuery)
+	if queryErr != nil {
+		return fail(stderr, fmt.Errorf("query: %s", queryErr))
+	}
+	for name, value := range map[string]string{"format": *format, "from": *from, "to": *to} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	if *walletID != 0 {
+		query.Set("wallet", strconv.Itoa(*walletID))
+	}
+
+	uses, closeUses, openErr := open(false)
+	if openErr != nil {
+		return fail(stderr, openErr)
+	}
+	defer closeUses()
+	fileMetadata, reportErr := uses.operations.GenerateReport(adminContext(), query)
+	if reportErr != nil {
+		return failUseCase(stderr, reportErr)
+	}
+	if writeErr := writeReport(fileMetadata, *outPath, stdout); writeErr != nil {
+		return fail(stderr, writeErr)
+	}
+	if *outPath == "-" {
+		return 0
+	}
+	output := &exportOutput{File: *outPath, ContentType: fileMetadata.ContentType, Size: fileMetadata.Size}
+	if fileMetadata.SHA256 != nil {
+		output.SHA256 = hex.EncodeToString(fileMetadata.SHA256)
+	}
+	return printOutput(stdout, *asJSON, output)
+}
+
+// writeReport copies report to file and closes its content; partially written file is removed
+func writeReport(fileMetadata *entities.FileMetadata, outPath string, stdout io.Writer) error {
+	defer fileMetadata.Content.Close()
+	if outPath == "-" {
+		_, copyErr := io.Copy(stdout, fileMetadata.Content)
+		return copyErr
+	}
+	out, createErr := os.Create(outPath)
+	if createErr != nil {
+		return fmt.Errorf("error of output creation: %s", createErr)
+	}
+	if _, copyErr := io.Copy(out, fileMetadata.Content); copyErr != nil {
+		out.Close()
+		os.Remove(outPath)
+		return fmt.Errorf("error of report writing: %s", copyErr)
+	}
+	return out.Close()
+}
+
+func runReconcile(args []string, stdout, stderr io.Writer, open openFunc) int {
+	flags, asJSON := newFlags("reconcile", stderr)
+	if flags.Parse(args) != nil {
+		return 2
+	}
+
+	uses, closeUses, openErr := open(false)
+	if openErr != nil {
+		return fail(stderr, openErr)
+	}
+	defer closeUses()
+	discrepancies, reconcileErr := uses.wallets.Reconcile(adminContext())
+	if reconcileErr != nil {
+		return failUseCase(stderr, reconcileErr)
+	}
+	output := make([]*discrepancyOutput, 0, len(discrepancies))
+	for _, discrepancy := range discrepancies {
+		output = append(output, &discrepancyOutput{
+			WalletID:   discrepancy.WalletID,
+			Balance:    discrepancy.Balance,
+			Ledger:     discrepancy.Ledger,
+			Difference: discrepancy.Balance.Sub(discrepancy.Ledger),
+		})
+	}
+	if code := printOutput(stdout, *asJSON, output); code != 0 {
+		return code
+	}
+	if len(output) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// setFrozenCommand returns command which freezes or unfreezes wallet
+func setFrozenCommand(name string, frozen bool) command {
+	return func(args []string, stdout, stderr io.Writer, open openFunc) int {
+		return runSetFrozen(name, frozen, args, stdout, stderr, open)
+	}
+}
+
+func runSetFrozen(name string, frozen bool, args []string, stdout, stderr io.Writer, open openFunc) int {
+	flags, asJSON := newFlags(name, stderr)
+	walletID := flags.Int("wallet", 0, "id of the wallet")
+	if flags.Parse(args) != nil {
+		return 2
+	}
+
+	uses, closeUses, openErr := open(false)
+	if openErr != nil {
+		return fail(stderr, openErr)
+	}
+	defer closeUses()
+	wallet, setErr := uses.wallets.SetFrozen(adminContext(), *walletID, frozen)
+	if setErr != nil {
+		return failUseCase(stderr, setErr)
+	}
+	return printOutput(stdout, *asJSON, newWalletOutput(wallet))
+}
+
+func newWalletOutput(wallet *entities.Wallet) *walletOutput {
+	return &walletOutput{
+		ID:       wallet.ID,
+		UserID:   wallet.UserID,
+		Balance:  wallet.Balance,
+		Currency: wallet.Currency,
+		Frozen:   wallet.Frozen,
+	}
+}
+
+func newUserOutput(user *entities.User, dryRun bool) *userOutput {
+	output := &userOutput{ID: user.ID, Email: user.Email, DryRun: dryRun}
+	if user.Wallet != nil {
+		output.Wallet = newWalletOutput(user.Wallet)
+	}
+	return output
+}
+
+// printOutput writes output as json or as text and returns exit code
+func printOutput(stdout io.Writer, asJSON bool, output interface{}) int {
+	if asJSON {
+		if encodeErr := json.NewEncoder(stdout).Encode(output); encodeErr != nil {
+			return 1
+		}
+		return 0
+	}
+	fmt.Fprint(stdout, text(output))
+	return 0
+}
+
+// text returns human-readable output
+func text(output interface{}) string {
+	switch o := output.(type) {
+	case *walletOutput:
+		state := ""
+		if o.Frozen {
+			state = " (frozen)"
+		}
+		return fmt.Sprintf("Wallet %d of user %d: %s %s%s\n", o.ID, o.UserID, o.Balance, o.Currency, state)
+	case *userOutput:
+		result := fmt.Sprintf("User %d: %s\n", o.ID, o.Email)
+		if o.Wallet != nil {
+			result += text(o.Wallet)
+		}
+		if o.DryRun {
+			result += "Dry run: changes are rolled back\n"
+		}
+		return result
+	case *transferOutput:
+		result := fmt.Sprintf("Transferred %s from wallet %d to wallet %d\n", o.Amount, o.WalletFrom, o.WalletTo)
+		if o.DryRun {
+			result += "Dry run: changes are rolled back\n"
+		}
+		return result
+	case *exportOutput:
+		return fmt.Sprintf("OK: %s (%s, %s, sha256 %s)\n", o.File, o.ContentType, o.Size, o.SHA256)
+	case []*discrepancyOutput:
+		if len(o) == 0 {
+			return "OK: balances of all wallets match their operations\n"
+		}
+		lines := make([]string, 0, len(o))
+		for _, d := range o {
+			lines = append(lines, fmt.Sprintf("Wallet %d: balance %s, operations %s, difference %s\n", d.WalletID, d.Balance, d.Ledger, d.Difference))
+		}
+		return strings.Join(lines, "")
+	}
+	return fmt.Sprintf("%v\n", output)
+}
+
+// fail prints error and returns exit code of failed command
+func fail(stderr io.Writer, err error) int {
+	fmt.Fprintf(stderr, "FAILED: %s\n", err)
+	return 1
+}
+
+func failUseCase(stderr io.Writer, useCaseErr adapters.Error) int {
+	return fail(stderr, useCaseErr.GetError())
+}
+
+// failForm prints messages of form validation sorted by fields
+func failForm(stderr io.Writer, formErrors *map[string][]string) int {
+	fields := make([]string, 0, len(*formErrors))
+	for field := range *formErrors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		fmt.Fprintf(stderr, "FAILED: %s: %s\n", field, strings.Join((*formErrors)[field], ", "))
+	}
+	return 2
+}
@@ -0,0 +1,84 @@
+// Command billingctl is the admin CLI of the billing system; it calls use cases directly with the application's configuration.
+package main
+
+import (
+	"billing_system_test_task/internal/app"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/usecases"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+const usage = `Usage: billingctl <command> [flags]
+
+Commands:
+  create-user  -email <email>                       creates user with empty wallet
+  enroll       -user <id> -amount <amount>          deposits amount to wallet of the user
+  transfer     -from <wallet> -to <wallet> -amount <amount>
+                                                    transfers funds between wallets
+  show-user    -user <id>                           prints user with wallet
+  show-wallet  -wallet <id>                         prints wallet
+  export       -o <file> [-format csv] [-wallet <id>] [-from <date>] [-to <date>] [-query <params>]
+                                                    writes operations report to file
+  reconcile                                         compares balances with operations, exits with 1 on discrepancies
+  freeze       -wallet <id>                         forbids changes of wallet's balance
+  unfreeze     -wallet <id>                         allows changes of wallet's balance
+
+Every command accepts -json to print machine-readable output; enroll and transfer accept -dry-run
+to run the operation in a transaction which is rolled back.
+Configuration is loaded like the server does: config.toml, profile of APP_ENV, .env file and environment variables.
+`
+
+// interactors represents use cases called by commands
+type interactors struct {
+	users      usecases.UserUseCase
+	wallets    usecases.WalletUseCase
+	operations usecases.WalletOperationUsecase
+}
+
+// openFunc returns use cases of commands and function releasing them
+type openFunc func(dryRun bool) (*interactors, func() error, error)
+
+func main() {
+	config, configErr := entities.LoadConfig(entities.ProjectDir("cmd"), nil, os.Stderr)
+	if configErr != nil {
+		log.Fatal(configErr)
+	}
+	open := func(dryRun bool) (*interactors, func() error, error) {
+		admin, adminErr := app.NewAdminInteractors(config, dryRun)
+		if adminErr != nil {
+			return nil, nil, adminErr
+		}
+		return &interactors{users: admin.Users, wallets: admin.Wallets, operations: admin.Operations}, admin.Close, nil
+	}
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr, open))
+}
+
+// run executes command and returns exit code
+func run(args []string, stdout, stderr io.Writer, open openFunc) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	command, isCommand := commands[args[0]]
+	if !isCommand {
+		if args[0] != "help" && args[0] != "-h" && args[0] != "--help" {
+			fmt.Fprintf(stderr, "Unknown command %q\n\n", args[0])
+		}
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	return command(args[1:], stdout, stderr, open)
+}
+
+// adminContext returns context of the operator; commands are not limited by access rules of the API
+func adminContext() context.Context {
+	return entities.WithPrincipal(context.Background(), &entities.Principal{
+		Subject: "billingctl",
+		Scopes:  []string{entities.ScopeAdmin},
+		Method:  entities.AuthMethodCLI,
+	})
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/usecases"
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+)
+
+// Test commands call use cases and report results with exit codes
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		dryRun bool
+		setup  func(uses *interactors)
+		code   int
+		stdout string
+	}{
+		{
+			name: "Transfer is rolled back in dry run",
+			args: []string{"transfer", "-from", "3", "-to", "4", "-amount", "2.5", "-dry-run", "-json"},
+			setup: func(uses *interactors) {
+				uses.wallets.(*usecases.MockWalletUseCase).EXPECT().Transfer(gomock.Any(), 3, 4, decimal.RequireFromString("2.5")).Return(3, nil)
+			},
+			dryRun: true,
+			stdout: "{\"wallet_from\":3,\"wallet_to\":4,\"amount\":\"2.5\",\"dry_run\":true}\n",
+		},
+		{
+			name:   "Invalid amount is rejected before connection",
+			args:   []string{"enroll", "-user", "1", "-amount", "-5"},
+			code:   2,
+			stdout: "",
+		},
+		{
+			name: "Wallet is frozen",
+			args: []string{"freeze", "-wallet", "3"},
+			setup: func(uses *interactors) {
+				uses.wallets.(*usecases.MockWalletUseCase).EXPECT().SetFrozen(gomock.Any(), 3, true).DoAndReturn(func(ctx interface{}, walletID int, frozen bool) (*entities.Wallet, adapters.Error) {
+					return &entities.Wallet{ID: 3, UserID: 1, Balance: decimal.NewFromInt(10), Currency: "USD", Frozen: frozen}, nil
+				})
+			},
+			stdout: "Wallet 3 of user 1: 10 USD (frozen)\n",
+		},
+		{
+			name: "Discrepancies fail reconciliation",
+			args: []string{"reconcile", "-json"},
+			setup: func(uses *interactors) {
+				uses.wallets.(*usecases.MockWalletUseCase).EXPECT().Reconcile(gomock.Any()).Return([]*entities.BalanceDiscrepancy{
+					{WalletID: 3, Balance: decimal.NewFromInt(10), Ledger: decimal.NewFromInt(7)},
+				}, nil)
+			},
+			code:   1,
+			stdout: "[{\"wallet_id\":3,\"balance\":\"10\",\"ledger\":\"7\",\"difference\":\"3\"}]\n",
+		},
+		{
+			name: "User is not found",
+			args: []string{"show-user", "-user", "5"},
+			setup: func(uses *interactors) {
+				uses.users.(*usecases.MockUserUseCase).EXPECT().Get(gomock.Any(), 5).Return(nil, adapters.NewHTTPError(404, fmt.Errorf("[USER_GET_BY_ID]: sql: no rows in result set")))
+			},
+			code: 1,
+		},
+		{
+			name: "Unknown command",
+			args: []string{"delete-user"},
+			code: 2,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			uses := &interactors{
+				users:      usecases.NewMockUserUseCase(ctrl),
+				wallets:    usecases.NewMockWalletUseCase(ctrl),
+				operations: usecases.NewMockWalletOperationUsecase(ctrl),
+			}
+			if tc.setup != nil {
+				tc.setup(uses)
+			}
+			open := func(dryRun bool) (*interactors, func() error, error) {
+				if dryRun != tc.dryRun {
+					t.Errorf("Expected dry run %v, got %v", tc.dryRun, dryRun)
+				}
+				return uses, func() error { return nil }, nil
+			}
+
+			var stdout, stderr bytes.Buffer
+			if code := run(tc.args, &stdout, &stderr, open); code != tc.code {
+				t.Errorf("Expected exit code %d, got %d (%s)", tc.code, code, stderr.String())
+			}
+			if stdout.String() != tc.stdout {
+				t.Errorf("Expected output %q, got %q", tc.stdout, stdout.String())
+			}
+		})
+	}
+}
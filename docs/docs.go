@@ -29,13 +29,402 @@ var doc = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/api-keys/": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create API key for server or user, it requires admin scope.\nThe key is returned only in this response, only its hash is stored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Create API key",
+                "parameters": [
+                    {
+                        "description": "Name, user and scopes of the key",
+                        "name": "key",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/forms.APIKeyForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created key",
+                        "schema": {
+                            "$ref": "#/definitions/serializers.APIKeySerializer"
+                        }
+                    },
+                    "400": {
+                        "description": "Form validation error",
+                        "schema": {
+                            "$ref": "#/definitions/http.FormErrorSerializer"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke API key, it requires admin scope",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Revoke API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Key is revoked",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/feeds/{consumer}/ack": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move consumer's offset to the cursor of delivered batch. Repeated acknowledgement does not change the offset.\nOffset is moved by creator of the consumer or admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Acknowledge feed batch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Consumer name",
+                        "name": "consumer",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Cursor of delivered batch (X-Feed-Cursor header)",
+                        "name": "ack",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/forms.FeedAckForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Acknowledged offset of the consumer",
+                        "schema": {
+                            "$ref": "#/definitions/serializers.FeedConsumerSerializer"
+                        }
+                    },
+                    "400": {
+                        "description": "Acknowledgement validation error",
+                        "schema": {
+                            "$ref": "#/definitions/http.FormErrorSerializer"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/feeds/{consumer}/operations": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get wallet operations which are not acknowledged by the consumer yet. Batch is repeated until its cursor is acknowledged.\nConsumer is created by the first request and is available to its creator and admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/vnd.msgpack",
+                    "application/x-protobuf",
+                    "application/gzip",
+                    "application/zip",
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Operations feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Consumer name (letters, digits, '_', '.', '-')",
+                        "name": "consumer",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of operations in the batch (default 1000, max 10000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Report format (json, ndjson, csv, xlsx, msgpack or protobuf)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of amounts in json and msgpack reports (string or number)",
+                        "name": "amount_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated list and order of csv columns",
+                        "name": "columns",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CSV delimiter (single character or 'tab')",
+                        "name": "delimiter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Write csv header (default true)",
+                        "name": "header",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timestamp layout in csv (rfc3339, rfc3339nano, datetime, date or go layout)",
+                        "name": "time_layout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Time zone of csv timestamps (default UTC)",
+                        "name": "time_zone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Decimal separator of csv amounts ('.' or ',')",
+                        "name": "decimal_separator",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rendering of NULL values in csv",
+                        "name": "null_value",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return detached manifest of the report",
+                        "name": "manifest",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Keep the report in storage for later downloads",
+                        "name": "persist",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Compression of the report (gzip or zip)",
+                        "name": "compress",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "AES-256-GCM encryption of the report (passphrase or key)",
+                        "name": "encrypt",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Passphrase of the report encryption (encrypt=passphrase)",
+                        "name": "X-Report-Passphrase",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        },
+                        "headers": {
+                            "Content-Location": {
+                                "type": "string",
+                                "description": "Download path of the stored report (when persist=true)"
+                            },
+                            "Digest": {
+                                "type": "string",
+                                "description": "SHA-256 checksum of the report (base64)"
+                            },
+                            "Server-Timing": {
+                                "type": "string",
+                                "description": "Duration and backpressure metrics of the report pipeline stages"
+                            },
+                            "X-Feed-Cursor": {
+                                "type": "string",
+                                "description": "Cursor of the last operation of the batch for acknowledgement"
+                            },
+                            "X-Feed-Offset": {
+                                "type": "string",
+                                "description": "Acknowledged cursor of the consumer"
+                            },
+                            "X-Report-Manifest": {
+                                "type": "string",
+                                "description": "Base64 json manifest of the report (when manifest=true)"
+                            },
+                            "X-Report-Manifest-Signature": {
+                                "type": "string",
+                                "description": "Ed25519 signature of the manifest's checksum with key id (when manifest=true)"
+                            },
+                            "X-Report-Signature": {
+                                "type": "string",
+                                "description": "Ed25519 signature of the report's checksum with key id"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "No new operations",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "X-Feed-Offset": {
+                                "type": "string",
+                                "description": "Acknowledged cursor of the consumer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    }
+                }
+            }
+        },
         "/api/operations/": {
             "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Get wallet operations logs",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/vnd.msgpack",
+                    "application/xml",
+                    "application/x-ofx",
+                    "application/qif",
+                    "application/x-protobuf",
+                    "text/plain",
+                    "application/gzip",
+                    "application/zip",
                     "application/octet-stream"
                 ],
                 "tags": [
@@ -45,34 +434,1280 @@ var doc = `{
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Report format",
+                        "description": "Report format (json, ndjson, csv, xlsx, msgpack, protobuf, camt053, ofx, qif or template)",
                         "name": "format",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Page number",
-                        "name": "page",
+                        "type": "string",
+                        "description": "Name of report template (required for template format)",
+                        "name": "template",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of amounts in json and msgpack reports (string or number)",
+                        "name": "amount_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated list and order of csv columns",
+                        "name": "columns",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CSV delimiter (single character or 'tab')",
+                        "name": "delimiter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Write csv header (default true)",
+                        "name": "header",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timestamp layout in csv (rfc3339, rfc3339nano, datetime, date or go layout)",
+                        "name": "time_layout",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Number of items per page",
-                        "name": "per_page",
-                        "in": "query"
+                        "type": "string",
+                        "description": "Time zone of csv timestamps (default UTC)",
+                        "name": "time_zone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Decimal separator of csv amounts ('.' or ',')",
+                        "name": "decimal_separator",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rendering of NULL values in csv",
+                        "name": "null_value",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page",
+                        "name": "per_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Wallet of operations (required for camt053, ofx and qif)",
+                        "name": "wallet",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date of the period (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date of the period (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return detached manifest of the report",
+                        "name": "manifest",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Keep the report in storage for later downloads",
+                        "name": "persist",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Compression of the report (gzip or zip)",
+                        "name": "compress",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "AES-256-GCM encryption of the report (passphrase or key)",
+                        "name": "encrypt",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Passphrase of the report encryption (encrypt=passphrase)",
+                        "name": "X-Report-Passphrase",
+                        "in": "header"
+                    }
+                ]
+            }
+        },
+        "/api/report-templates/": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all report templates",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Report templates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/serializers.ReportTemplateSerializer"
+                            }
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/report-templates/{name}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get report template by name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Report template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/serializers.ReportTemplateSerializer"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create or replace text/template layout of operations report, used with format=template\u0026template={name}.\nRow section gets .ID, .Operation, .WalletFrom (0 when absent), .WalletTo, .Amount and .CreatedAt;\nheader and footer get .GeneratedAt, .Rows and .Total (zero in header).\nHelpers: money, neg, date, upper, lower, replace, pad, lpad and csv. Range and nested templates are not allowed,\nprintf width and precision must be literal numbers up to 65536.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Save report template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template name (letters, digits, '_', '.', '-')",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Template sections",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/forms.ReportTemplateForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Saved template",
+                        "schema": {
+                            "$ref": "#/definitions/serializers.ReportTemplateSerializer"
+                        }
+                    },
+                    "400": {
+                        "description": "Template validation error",
+                        "schema": {
+                            "$ref": "#/definitions/http.FormErrorSerializer"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete report template by name",
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Delete report template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Template is deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/reports/balances": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get balance sheet per currency: number of wallets and total balance",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/vnd.msgpack",
+                    "application/gzip",
+                    "application/zip",
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Balances export",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report format (json, ndjson, csv, xlsx or msgpack)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of amounts in json and msgpack reports (string or number)",
+                        "name": "amount_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated list and order of csv columns",
+                        "name": "columns",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CSV delimiter (single character or 'tab')",
+                        "name": "delimiter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Write csv header (default true)",
+                        "name": "header",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Decimal separator of csv amounts ('.' or ',')",
+                        "name": "decimal_separator",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rendering of NULL values in csv",
+                        "name": "null_value",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return detached manifest of the report",
+                        "name": "manifest",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Keep the report in storage for later downloads",
+                        "name": "persist",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Compression of the report (gzip or zip)",
+                        "name": "compress",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "AES-256-GCM encryption of the report (passphrase or key)",
+                        "name": "encrypt",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Passphrase of the report encryption (encrypt=passphrase)",
+                        "name": "X-Report-Passphrase",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        },
+                        "headers": {
+                            "Content-Location": {
+                                "type": "string",
+                                "description": "Download path of the stored report (when persist=true)"
+                            },
+                            "Content-Type": {
+                                "type": "string",
+                                "description": "Content type of the report format"
+                            },
+                            "Digest": {
+                                "type": "string",
+                                "description": "SHA-256 checksum of the report (base64)"
+                            },
+                            "Server-Timing": {
+                                "type": "string",
+                                "description": "Duration and backpressure metrics of the report pipeline stages"
+                            },
+                            "X-Report-Manifest": {
+                                "type": "string",
+                                "description": "Base64 json manifest of the report (when manifest=true)"
+                            },
+                            "X-Report-Manifest-Signature": {
+                                "type": "string",
+                                "description": "Ed25519 signature of the manifest's checksum with key id (when manifest=true)"
+                            },
+                            "X-Report-Signature": {
+                                "type": "string",
+                                "description": "Ed25519 signature of the report's checksum with key id"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/reports/files/{name}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Download report persisted with persist=true",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Download report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        },
+                        "headers": {
+                            "Content-Type": {
+                                "type": "string",
+                                "description": "Content type of the report format"
+                            },
+                            "Digest": {
+                                "type": "string",
+                                "description": "SHA-256 checksum of the report (base64)"
+                            },
+                            "X-Report-Signature": {
+                                "type": "string",
+                                "description": "Ed25519 signature of the report's checksum with key id"
+                            }
+                        }
+                    },
+                    "206": {
+                        "description": "Partial Content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/reports/public-key": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get Ed25519 public key for verification of reports signatures",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Reports public key",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/serializers.ReportPublicKeySerializer"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/reports/summary": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get totals of wallet operations by period, operation type, wallet or currency.\nOperation types are deposit, withdrawal and transfer; transfers are counted in both wallets.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/vnd.msgpack",
+                    "application/gzip",
+                    "application/zip",
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Summary report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report format (json, ndjson, csv, xlsx or msgpack)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Aggregation period (day, week or month)",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated grouping dimensions (operation, wallet, currency)",
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date, inclusive (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of totals in json and msgpack reports (string or number)",
+                        "name": "amount_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated list and order of csv columns",
+                        "name": "columns",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CSV delimiter (single character or 'tab')",
+                        "name": "delimiter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Write csv header (default true)",
+                        "name": "header",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Decimal separator of csv amounts ('.' or ',')",
+                        "name": "decimal_separator",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rendering of NULL values in csv",
+                        "name": "null_value",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return detached manifest of the report",
+                        "name": "manifest",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Keep the report in storage for later downloads",
+                        "name": "persist",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Compression of the report (gzip or zip)",
+                        "name": "compress",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "AES-256-GCM encryption of the report (passphrase or key)",
+                        "name": "encrypt",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Passphrase of the report encryption (encrypt=passphrase)",
+                        "name": "X-Report-Passphrase",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        },
+                        "headers": {
+                            "Content-Location": {
+                                "type": "string",
+                                "description": "Download path of the stored report (when persist=true)"
+                            },
+                            "Content-Type": {
+                                "type": "string",
+                                "description": "Content type of the report format"
+                            },
+                            "Digest": {
+                                "type": "string",
+                                "description": "SHA-256 checksum of the report (base64)"
+                            },
+                            "Server-Timing": {
+                                "type": "string",
+                                "description": "Duration and backpressure metrics of the report pipeline stages"
+                            },
+                            "X-Report-Manifest": {
+                                "type": "string",
+                                "description": "Base64 json manifest of the report (when manifest=true)"
+                            },
+                            "X-Report-Manifest-Signature": {
+                                "type": "string",
+                                "description": "Ed25519 signature of the manifest's checksum with key id (when manifest=true)"
+                            },
+                            "X-Report-Signature": {
+                                "type": "string",
+                                "description": "Ed25519 signature of the report's checksum with key id"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/reports/users": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get list of users with their wallets and balances",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/vnd.msgpack",
+                    "application/gzip",
+                    "application/zip",
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Users export",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report format (json, ndjson, csv, xlsx or msgpack)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of amounts in json and msgpack reports (string or number)",
+                        "name": "amount_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated list and order of csv columns",
+                        "name": "columns",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CSV delimiter (single character or 'tab')",
+                        "name": "delimiter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Write csv header (default true)",
+                        "name": "header",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Decimal separator of csv amounts ('.' or ',')",
+                        "name": "decimal_separator",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rendering of NULL values in csv",
+                        "name": "null_value",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return detached manifest of the report",
+                        "name": "manifest",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Keep the report in storage for later downloads",
+                        "name": "persist",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Compression of the report (gzip or zip)",
+                        "name": "compress",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "AES-256-GCM encryption of the report (passphrase or key)",
+                        "name": "encrypt",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Passphrase of the report encryption (encrypt=passphrase)",
+                        "name": "X-Report-Passphrase",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        },
+                        "headers": {
+                            "Content-Location": {
+                                "type": "string",
+                                "description": "Download path of the stored report (when persist=true)"
+                            },
+                            "Content-Type": {
+                                "type": "string",
+                                "description": "Content type of the report format"
+                            },
+                            "Digest": {
+                                "type": "string",
+                                "description": "SHA-256 checksum of the report (base64)"
+                            },
+                            "Server-Timing": {
+                                "type": "string",
+                                "description": "Duration and backpressure metrics of the report pipeline stages"
+                            },
+                            "X-Report-Manifest": {
+                                "type": "string",
+                                "description": "Base64 json manifest of the report (when manifest=true)"
+                            },
+                            "X-Report-Manifest-Signature": {
+                                "type": "string",
+                                "description": "Ed25519 signature of the manifest's checksum with key id (when manifest=true)"
+                            },
+                            "X-Report-Signature": {
+                                "type": "string",
+                                "description": "Ed25519 signature of the report's checksum with key id"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create new user and wallet",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create new user",
+                "parameters": [
+                    {
+                        "description": "User attributes",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/forms.UserForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Create user response",
+                        "schema": {
+                            "$ref": "#/definitions/serializers.UserSerializer"
+                        }
+                    },
+                    "400": {
+                        "description": "User form validation error",
+                        "schema": {
+                            "$ref": "#/definitions/http.FormErrorSerializer"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/enroll/": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Enroll particular users wallet",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Enroll wallet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Enrollment attributes",
+                        "name": "enroll",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/forms.EnrollForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Retrieving user information with updated balance",
+                        "schema": {
+                            "$ref": "#/definitions/serializers.UserSerializer"
+                        }
+                    },
+                    "400": {
+                        "description": "Enroll form validation error",
+                        "schema": {
+                            "$ref": "#/definitions/http.FormErrorSerializer"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wallets/transfer/": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Transfer funds between two users",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Transfer funds",
+                "parameters": [
+                    {
+                        "description": "Transfer parameters",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/forms.WalletForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Wallet from id",
+                        "schema": {
+                            "$ref": "#/definitions/serializers.WalletSerializer"
+                        }
+                    },
+                    "400": {
+                        "description": "Wallet transfer validation error",
+                        "schema": {
+                            "$ref": "#/definitions/http.FormErrorSerializer"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wallets/{id}/events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Server-Sent Events stream of wallet's changes. \"balance\" event is sent on connection and when balance changes,\n\"operation\" event is sent for each new operation of the wallet, its id is position which resumes\nthe stream in Last-Event-ID header. Comments are sent as heartbeats.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Wallet events",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of the last received operation event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of events",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wallets/{id}/statement": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get bank statement of the wallet; users receive statements of own wallets only",
+                "produces": [
+                    "application/xml",
+                    "application/x-ofx",
+                    "application/qif",
+                    "application/gzip",
+                    "application/zip",
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Wallet statement",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Wallet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Statement format (camt053, ofx or qif)",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date of the period (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date of the period (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return detached manifest of the report",
+                        "name": "manifest",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Keep the report in storage for later downloads",
+                        "name": "persist",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Compression of the report (gzip or zip)",
+                        "name": "compress",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "AES-256-GCM encryption of the report (passphrase or key)",
+                        "name": "encrypt",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Passphrase of the report encryption (encrypt=passphrase)",
+                        "name": "X-Report-Passphrase",
+                        "in": "header"
+                    }
+                ]
+            }
+        },
+        "/api/webhook-deliveries/{id}/redeliver": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Send delivery again with new attempts, e.g. after receiver is fixed; subscription must be active",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Redeliver webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Delivery is scheduled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/webhooks/": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
                     },
                     {
-                        "type": "integer",
-                        "description": "Number of items per page",
-                        "name": "date",
-                        "in": "query"
+                        "BearerAuth": []
                     }
-                ]
-            }
-        },
-        "/api/users/": {
+                ],
+                "description": "Get active webhook subscriptions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Webhook subscriptions",
+                "responses": {
+                    "200": {
+                        "description": "Subscriptions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/serializers.WebhookSerializer"
+                            }
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorMsg"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Create new user and wallet",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Subscribe URL to events: user.created, wallet.enrolled and transfer.completed.\nEvents are posted as {\"id\", \"type\", \"created_at\", \"data\"} with X-Webhook-Event, X-Webhook-Delivery and\nX-Webhook-Signature \"t={unix time},v1={hex HMAC-SHA256 of '{t}.{body}' with the secret}\" headers.\nResponses other than 2xx are retried with exponential backoff. Secret is generated when it is not given\nand is returned only in this response. Subscriptions receive events of all wallets, so they are managed\nby admins only; URLs must resolve to public addresses.",
                 "consumes": [
                     "application/json"
                 ],
@@ -80,29 +1715,29 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "webhooks"
                 ],
-                "summary": "Create new user",
+                "summary": "Create webhook subscription",
                 "parameters": [
                     {
-                        "description": "User attributes",
-                        "name": "user",
+                        "description": "URL, event types and secret of subscription",
+                        "name": "webhook",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/forms.UserForm"
+                            "$ref": "#/definitions/forms.WebhookForm"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Create user response",
+                        "description": "Created subscription",
                         "schema": {
-                            "$ref": "#/definitions/serializers.UserSerializer"
+                            "$ref": "#/definitions/serializers.WebhookSerializer"
                         }
                     },
                     "400": {
-                        "description": "User form validation error",
+                        "description": "Form validation error",
                         "schema": {
                             "$ref": "#/definitions/http.FormErrorSerializer"
                         }
@@ -116,52 +1751,39 @@ var doc = `{
                 }
             }
         },
-        "/api/users/{id}/enroll/": {
-            "post": {
-                "description": "Enroll particular users wallet",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
+        "/api/webhooks/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Deactivate webhook subscription and cancel its pending deliveries, delivery log is kept",
                 "tags": [
-                    "users"
+                    "webhooks"
                 ],
-                "summary": "Enroll wallet",
+                "summary": "Delete webhook subscription",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Subscription ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Enrollment attributes",
-                        "name": "enroll",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/forms.EnrollForm"
-                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Retrieving user information with updated balance",
-                        "schema": {
-                            "$ref": "#/definitions/serializers.UserSerializer"
-                        }
-                    },
-                    "400": {
-                        "description": "Enroll form validation error",
+                    "204": {
+                        "description": "Subscription is deleted",
                         "schema": {
-                            "$ref": "#/definitions/http.FormErrorSerializer"
+                            "type": "string"
                         }
                     },
-                    "default": {
-                        "description": "",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/http.ErrorMsg"
                         }
@@ -169,41 +1791,41 @@ var doc = `{
                 }
             }
         },
-        "/api/wallets/transfer/": {
-            "post": {
-                "description": "Transfer funds between two users",
-                "consumes": [
-                    "application/json"
+        "/api/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Get the latest 100 delivery attempts of subscription, status_code is 0 when response is not received",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "wallets"
+                    "webhooks"
                 ],
-                "summary": "Transfer funds",
+                "summary": "Webhook delivery log",
                 "parameters": [
                     {
-                        "description": "Transfer parameters",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/forms.WalletForm"
-                        }
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Wallet from id",
+                        "description": "Attempts",
                         "schema": {
-                            "$ref": "#/definitions/serializers.WalletSerializer"
-                        }
-                    },
-                    "400": {
-                        "description": "Wallet transfer validation error",
-                        "schema": {
-                            "$ref": "#/definitions/http.FormErrorSerializer"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/serializers.WebhookAttemptSerializer"
+                            }
                         }
                     },
                     "default": {
@@ -217,6 +1839,26 @@ var doc = `{
         }
     },
     "definitions": {
+        "forms.APIKeyForm": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "forms.EnrollForm": {
             "type": "object",
             "required": [
@@ -228,6 +1870,34 @@ var doc = `{
                 }
             }
         },
+        "forms.FeedAckForm": {
+            "type": "object",
+            "required": [
+                "cursor"
+            ],
+            "properties": {
+                "cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "forms.ReportTemplateForm": {
+            "type": "object",
+            "required": [
+                "row"
+            ],
+            "properties": {
+                "footer": {
+                    "type": "string"
+                },
+                "header": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "string"
+                }
+            }
+        },
         "forms.UserForm": {
             "type": "object",
             "required": [
@@ -258,6 +1928,27 @@ var doc = `{
                 }
             }
         },
+        "forms.WebhookForm": {
+            "type": "object",
+            "required": [
+                "event_types",
+                "url"
+            ],
+            "properties": {
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
         "http.ErrorMsg": {
             "type": "object",
             "properties": {
@@ -280,6 +1971,92 @@ var doc = `{
                 }
             }
         },
+        "serializers.APIKeySerializer": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "serializers.FeedConsumerSerializer": {
+            "type": "object",
+            "properties": {
+                "acked_at": {
+                    "type": "string"
+                },
+                "cursor": {
+                    "type": "string"
+                },
+                "last_created_at": {
+                    "type": "string"
+                },
+                "last_operation_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "serializers.ReportPublicKeySerializer": {
+            "type": "object",
+            "properties": {
+                "algorithm": {
+                    "type": "string"
+                },
+                "key_id": {
+                    "type": "string"
+                },
+                "public_key": {
+                    "type": "string"
+                }
+            }
+        },
+        "serializers.ReportTemplateSerializer": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "footer": {
+                    "type": "string"
+                },
+                "header": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
         "serializers.UserSerializer": {
             "type": "object",
             "properties": {
@@ -304,6 +2081,76 @@ var doc = `{
                     "type": "integer"
                 }
             }
+        },
+        "serializers.WebhookAttemptSerializer": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivery_id": {
+                    "type": "integer"
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "integer"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "status_code": {
+                    "type": "integer"
+                }
+            }
+        },
+        "serializers.WebhookSerializer": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -8,6 +8,8 @@ type Error interface {
 type ErrorsFactory interface {
 	NotFound(err error) Error
 	DefaultError(err error) Error
+	Unauthorized(err error) Error
+	Forbidden(err error) Error
 }
 
 type HTTPErrorsFactory struct{}
@@ -28,6 +30,20 @@ func (he *HTTPErrorsFactory) DefaultError(err error) Error {
 	)
 }
 
+// Unauthorized is returned when caller is not authenticated
+func (he *HTTPErrorsFactory) Unauthorized(err error) Error {
+	return NewHTTPError(
+		401, err,
+	)
+}
+
+// Forbidden is returned when authenticated caller has no access to the resource
+func (he *HTTPErrorsFactory) Forbidden(err error) Error {
+	return NewHTTPError(
+		403, err,
+	)
+}
+
 type HTTPError struct {
 	status int
 	err    error
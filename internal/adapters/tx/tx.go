@@ -43,6 +43,32 @@ func (tb *txBeginner) BeginTrx(ctx context.Context, opts *sql.TxOptions) (Tx, er
 	return &tx{sqlTx}, nil
 }
 
+type dryRunTxBeginner struct {
+	SQLAdapter
+}
+
+// NewDryRunTxBeginner returns beginner of transactions which are rolled back instead of commit
+func NewDryRunTxBeginner(sqlDB SQLAdapter) TxBeginner {
+	return &dryRunTxBeginner{sqlDB}
+}
+
+func (tb *dryRunTxBeginner) BeginTrx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	sqlTx, txErr := tb.BeginTx(ctx, opts)
+	if txErr != nil {
+		return nil, fmt.Errorf("transaction initialization error: %s", txErr)
+	}
+	return &dryRunTx{&tx{sqlTx}}, nil
+}
+
+// dryRunTx runs queries of the transaction and discards their changes
+type dryRunTx struct {
+	*tx
+}
+
+func (t *dryRunTx) Commit() error {
+	return t.tx.Rollback()
+}
+
 type tx struct {
 	*sql.Tx
 }
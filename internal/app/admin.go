@@ -0,0 +1,63 @@
+package app
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/adapters/tx"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/repositories"
+	"billing_system_test_task/internal/repositories/reports"
+	"billing_system_test_task/internal/usecases"
+	"database/sql"
+)
+
+// AdminInteractors represents use cases of the admin CLI connected to the configured database
+type AdminInteractors struct {
+	Users      usecases.UserUseCase
+	Wallets    usecases.WalletUseCase
+	Operations usecases.WalletOperationUsecase
+	sqlDB      *sql.DB
+}
+
+// NewAdminInteractors returns use cases of the admin CLI; transactions of dry run are rolled back instead of commit
+func NewAdminInteractors(config entities.ConfigAdapter, dryRun bool) (*AdminInteractors, error) {
+	sqlDB, sqlDBErr := openDB(config)
+	if sqlDBErr != nil {
+		return nil, sqlDBErr
+	}
+	fileHandler, _, fileHandlerErr := newReportFileHandler(config)
+	if fileHandlerErr != nil {
+		_ = sqlDB.Close()
+		return nil, fileHandlerErr
+	}
+
+	txManager := tx.NewTxBeginner(sqlDB)
+	if dryRun {
+		txManager = tx.NewDryRunTxBeginner(sqlDB)
+	}
+	errFactory := adapters.NewHTTPErrorsFactory()
+	walletsRepo := repositories.NewWalletService(sqlDB)
+	usersRepo := repositories.NewUsersService(sqlDB)
+	operationsRepo := repositories.NewWalletOperationRepo(sqlDB)
+	outboxRepo := repositories.NewOutboxService(sqlDB)
+
+	return &AdminInteractors{
+		Users:   usecases.NewUserInteractor(usersRepo, walletsRepo, operationsRepo, outboxRepo, txManager, errFactory),
+		Wallets: usecases.NewWalletInteractor(walletsRepo, operationsRepo, outboxRepo, errFactory, txManager),
+		Operations: usecases.NewWalletOperationInteractor(
+			operationsRepo,
+			walletsRepo,
+			reports.NewStatementService(sqlDB),
+			reports.NewTemplateService(sqlDB),
+			reports.NewQueryParamsReader(),
+			fileHandler,
+			reports.NewOperationsProcessesManager(),
+			errFactory,
+		),
+		sqlDB: sqlDB,
+	}, nil
+}
+
+// Close closes connections to the database
+func (ai *AdminInteractors) Close() error {
+	return ai.sqlDB.Close()
+}
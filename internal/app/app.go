@@ -3,14 +3,24 @@ package app
 import (
 	"billing_system_test_task/internal/adapters"
 	"billing_system_test_task/internal/adapters/tx"
+	"billing_system_test_task/internal/auth"
 	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/events"
+	"billing_system_test_task/internal/migrator"
+	"billing_system_test_task/internal/outbox"
+	"billing_system_test_task/internal/ratelimit"
 	"billing_system_test_task/internal/repositories"
 	"billing_system_test_task/internal/repositories/reports"
+	grpcHandlers "billing_system_test_task/internal/transport/grpc"
 	httpHandlers "billing_system_test_task/internal/transport/http"
 	"billing_system_test_task/internal/usecases"
+	"billing_system_test_task/internal/webhooks"
+	"billing_system_test_task/migrations"
 	"context"
 	"database/sql"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -18,9 +28,22 @@ import (
 	"time"
 
 	"github.com/gorilla/handlers"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	"google.golang.org/grpc"
 )
 
+// Webhooks are sent by the worker of the application; due deliveries are polled with the interval
+const (
+	webhookInterval = 5 * time.Second
+	webhookTimeout  = 10 * time.Second
+)
+
+// walletEventsHeartbeat is the interval of heartbeats of wallet streams, it is shorter than timeouts of proxies
+const walletEventsHeartbeat = 15 * time.Second
+
+// bucketsPurgeInterval is the interval of removing idle rate limit buckets of shared store
+const bucketsPurgeInterval = 10 * time.Minute
+
 type AppAdapter interface {
 	Run()
 }
@@ -31,81 +54,319 @@ type App struct {
 	port   string
 	server *http.Server
 	wait   time.Duration
+
+	grpcAddr   string
+	grpcServer *grpc.Server
+
+	webhookInteractor *usecases.WebhookInteractor
+	dispatcher        *outbox.Dispatcher
+	outboxInterval    time.Duration
+	broker            *events.Broker
+	listener          *pq.Listener
+	rateLimitStore    ratelimit.Store
+	rateLimitIdle     time.Duration
 }
 
 func NewApp(config entities.ConfigAdapter) *App {
 	var (
-		sqlDB        *sql.DB
-		sqlDbOpenErr error
 		host         = config.GetAppHost()
 		port         = config.GetAppPort()
-		dbProvider   = config.GetDBProvider()
+		grpcPort     = config.GetGRPCPort()
 		dbConnString = config.GetDBConnectionString()
 	)
 
-	sqlDB, sqlDbOpenErr = sql.Open(dbProvider, dbConnString)
-	if sqlDbOpenErr != nil {
-		log.Fatalf("Error sql database open: %s", sqlDbOpenErr)
+	sqlDB, sqlDBErr := openDB(config)
+	if sqlDBErr != nil {
+		log.Fatal(sqlDBErr)
 	}
-	if pingErr := sqlDB.Ping(); pingErr != nil {
-		log.Fatalf("Error sql database connection: %s", pingErr)
+	features := config.GetFeatures()
+	if features.AutoMigrate {
+		if migrateErr := migrate(sqlDB); migrateErr != nil {
+			log.Fatalf("Error of migrations: %s", migrateErr)
+		}
 	}
 	errFactory := adapters.NewHTTPErrorsFactory()
 	txManger := tx.NewTxBeginner(sqlDB)
 	walletsRepo := repositories.NewWalletService(sqlDB)
 	usersRepo := repositories.NewUsersService(sqlDB)
 	operationsRepo := repositories.NewWalletOperationRepo(sqlDB)
-	userInteractor := usecases.NewUserInteractor(usersRepo, walletsRepo, operationsRepo, txManger, errFactory)
-	walletInteractor := usecases.NewWalletInteractor(walletsRepo, operationsRepo, errFactory, txManger)
+	outboxRepo := repositories.NewOutboxService(sqlDB)
+	webhookInteractor := usecases.NewWebhookInteractor(repositories.NewWebhookService(sqlDB), webhooks.NewHTTPSender(webhookTimeout, webhooks.IsPublicAddress), errFactory)
+	userInteractor := usecases.NewUserInteractor(usersRepo, walletsRepo, operationsRepo, outboxRepo, txManger, errFactory)
+	walletInteractor := usecases.NewWalletInteractor(walletsRepo, operationsRepo, outboxRepo, errFactory, txManger)
+	outboxConfig := config.GetOutboxConfig()
+	dispatcher := outbox.NewDispatcher(outboxRepo, txManger, newOutboxPublishers(outboxConfig, webhookInteractor)...)
 
 	queryParams := reports.NewQueryParamsReader()
-	fileStorage := reports.NewFileStorage()
-	fileHandler := reports.NewFileHandler(fileStorage)
+	fileHandler, signer, fileHandlerErr := newReportFileHandler(config)
+	if fileHandlerErr != nil {
+		log.Fatal(fileHandlerErr)
+	}
 	pipesManager := reports.NewOperationsProcessesManager()
 
-	operationsInteractor := usecases.NewWalletOperationInteractor(operationsRepo, queryParams, fileHandler, pipesManager, errFactory)
+	summaryRepo := reports.NewSummaryService(sqlDB)
+	exportRepo := reports.NewExportService(sqlDB)
+	statementRepo := reports.NewStatementService(sqlDB)
+	feedRepo := repositories.NewFeedService(sqlDB)
+	templateRepo := reports.NewTemplateService(sqlDB)
+
+	operationsInteractor := usecases.NewWalletOperationInteractor(operationsRepo, walletsRepo, statementRepo, templateRepo, queryParams, fileHandler, pipesManager, errFactory)
+	reportInteractor := usecases.NewReportInteractor(summaryRepo, exportRepo, queryParams, fileHandler, pipesManager, signer, errFactory)
+	templateInteractor := usecases.NewReportTemplateInteractor(templateRepo, errFactory)
+	feedInteractor := usecases.NewFeedInteractor(feedRepo, operationsRepo, queryParams, fileHandler, pipesManager, errFactory)
+
+	broker := events.NewBroker()
+	listener, listenErr := events.Listen(dbConnString)
+	if listenErr != nil {
+		log.Fatalf("Error of wallet notifications listening: %s", listenErr)
+	}
+	walletEventsInteractor := usecases.NewWalletEventsInteractor(walletsRepo, feedRepo, broker, errFactory)
+
+	tokenVerifier, verifierErr := newTokenVerifier(config.GetAuthConfig())
+	if verifierErr != nil {
+		log.Fatalf("Error of JWT keys loading: %s", verifierErr)
+	}
+	authInteractor := usecases.NewAuthInteractor(repositories.NewAPIKeysService(sqlDB), tokenVerifier, errFactory)
+	accessInteractor := usecases.NewAccessInteractor(repositories.NewAuditService(sqlDB), errFactory)
 
 	usersHandler := httpHandlers.NewUserHandler(userInteractor)
 	walletsHandler := httpHandlers.NewWalletsHandler(walletInteractor)
+	walletEventsHandler := httpHandlers.NewWalletEventsHandler(walletEventsInteractor, walletEventsHeartbeat)
 	operationsHandler := httpHandlers.NewOperationsHandler(operationsInteractor)
-	router := httpHandlers.NewRouter(usersHandler, walletsHandler, operationsHandler)
+	reportsHandler := httpHandlers.NewReportsHandler(reportInteractor)
+	feedsHandler := httpHandlers.NewFeedsHandler(feedInteractor)
+	templatesHandler := httpHandlers.NewTemplatesHandler(templateInteractor)
+	webhooksHandler := httpHandlers.NewWebhooksHandler(webhookInteractor)
+	authHandler := httpHandlers.NewAuthHandler(authInteractor)
+	accessHandler := httpHandlers.NewAccessHandler(accessInteractor)
+	rateLimitConfig := config.GetRateLimitConfig()
+	rateLimitStore, limits := newRateLimits(rateLimitConfig, sqlDB)
+	reportsLimiter := ratelimit.NewConcurrencyLimiter(rateLimitConfig.ReportsConcurrency, rateLimitConfig.ReportsConcurrencyPerClient)
+	limitsHandler := httpHandlers.NewLimitsHandler(rateLimitStore, limits, reportsLimiter)
+	router := httpHandlers.NewRouter(usersHandler, walletsHandler, walletEventsHandler, operationsHandler, reportsHandler, feedsHandler, templatesHandler, webhooksHandler, authHandler, accessHandler, limitsHandler)
 
 	url := strings.Join([]string{host, port}, ":")
+	serverTimeouts := config.GetServerTimeouts()
+	server := &http.Server{
+		Handler:      handlers.LoggingHandler(os.Stdout, router),
+		Addr:         url,
+		WriteTimeout: serverTimeouts.Write,
+		ReadTimeout:  serverTimeouts.Read,
+		// Streams extend write deadline of their connections
+		ConnContext: httpHandlers.ConnContext,
+	}
+	server.RegisterOnShutdown(walletEventsHandler.Close)
+
+	var grpcServer *grpc.Server
+	if features.GRPC {
+		grpcServer = grpcHandlers.NewGRPCServer(
+			grpcHandlers.NewServer(userInteractor, walletInteractor, operationsInteractor),
+			grpcHandlers.NewAuthInterceptor(authInteractor, accessInteractor),
+			grpcHandlers.NewLimitsInterceptor(rateLimitStore, limits),
+		)
+	}
 
 	return &App{
-		host: host,
-		port: port,
-		wait: time.Second * 5,
-		server: &http.Server{
-			Handler:      handlers.LoggingHandler(os.Stdout, router),
-			Addr:         url,
-			WriteTimeout: 15 * time.Second,
-			ReadTimeout:  15 * time.Second,
-		},
+		host:              host,
+		port:              port,
+		wait:              config.GetWaitTime(),
+		server:            server,
+		grpcAddr:          strings.Join([]string{host, grpcPort}, ":"),
+		grpcServer:        grpcServer,
+		webhookInteractor: webhookInteractor,
+		dispatcher:        dispatcher,
+		outboxInterval:    outboxConfig.Interval,
+		broker:            broker,
+		listener:          listener,
+		rateLimitStore:    rateLimitStore,
+		rateLimitIdle:     ratelimit.RefillTime(limits),
+	}
+}
+
+// openDB opens and checks connection to the configured database with limits of its pool
+func openDB(config entities.ConfigAdapter) (*sql.DB, error) {
+	sqlDB, sqlDbOpenErr := sql.Open(config.GetDBProvider(), config.GetDBConnectionString())
+	if sqlDbOpenErr != nil {
+		return nil, fmt.Errorf("Error sql database open: %s", sqlDbOpenErr)
+	}
+	pool := config.GetDBPoolConfig()
+	sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+	if pingErr := sqlDB.Ping(); pingErr != nil {
+		_ = sqlDB.Close()
+		return nil, fmt.Errorf("Error sql database connection: %s", pingErr)
+	}
+	return sqlDB, nil
+}
+
+// NewMigrator returns migrator of embedded migrations connected to the configured database
+func NewMigrator(config entities.ConfigAdapter) (*migrator.Migrator, func() error, error) {
+	sqlDB, sqlDBErr := openDB(config)
+	if sqlDBErr != nil {
+		return nil, nil, sqlDBErr
+	}
+	m, loadErr := migrator.NewMigrator(sqlDB, migrations.FS)
+	if loadErr != nil {
+		_ = sqlDB.Close()
+		return nil, nil, loadErr
+	}
+	return m, sqlDB.Close, nil
+}
+
+// migrate applies pending embedded migrations; replicas starting simultaneously wait for each other
+func migrate(sqlDB *sql.DB) error {
+	m, loadErr := migrator.NewMigrator(sqlDB, migrations.FS)
+	if loadErr != nil {
+		return loadErr
 	}
+	steps, upErr := m.Up(context.Background())
+	for _, step := range steps {
+		log.Printf("Applied migration %d_%s", step.Migration.Version, step.Migration.Name)
+	}
+	return upErr
+}
+
+// newReportFileHandler returns handler of reports with configured storage, signing and encryption keys
+func newReportFileHandler(config entities.ConfigAdapter) (*reports.FileHandler, *reports.Ed25519Signer, error) {
+	fileStorage, storageErr := reports.NewStorage(config.GetReportStorageConfig())
+	if storageErr != nil {
+		return nil, nil, fmt.Errorf("Error of reports storage initialization: %s", storageErr)
+	}
+	// Signatures by ephemeral key can not be verified after restart, so the key is required
+	signer, signerErr := reports.LoadEd25519Signer(config.GetReportSigningKey())
+	if signerErr != nil {
+		return nil, nil, fmt.Errorf("Error of reports signing key (REPORT_SIGNING_KEY) loading: %s", signerErr)
+	}
+	encryptionKey, encryptionKeyErr := reports.ParseEncryptionKey(config.GetReportEncryptionKey())
+	if encryptionKeyErr != nil {
+		return nil, nil, fmt.Errorf("Error of reports encryption key loading: %s", encryptionKeyErr)
+	}
+	return reports.NewFileHandler(fileStorage, signer, encryptionKey), signer, nil
+}
+
+// newTokenVerifier returns verifier of JWTs; it is nil when no key is configured, so only API keys are accepted
+func newTokenVerifier(authConfig entities.AuthConfig) (auth.TokenVerifier, error) {
+	jwtConfig := auth.JWTConfig{
+		Secret:   []byte(authConfig.JWTSecret),
+		Issuer:   authConfig.JWTIssuer,
+		Audience: authConfig.JWTAudience,
+		Leeway:   auth.DefaultLeeway,
+	}
+	if authConfig.JWTPublicKey != "" {
+		publicKey, parseErr := auth.ParseRSAPublicKey(authConfig.JWTPublicKey)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		jwtConfig.PublicKey = publicKey
+	}
+	if len(jwtConfig.Secret) == 0 && jwtConfig.PublicKey == nil {
+		log.Printf("[WARNING] AUTH_JWT_SECRET and AUTH_JWT_PUBLIC_KEY are not set, only API keys are accepted")
+		return nil, nil
+	}
+	return auth.NewJWTVerifier(jwtConfig)
+}
+
+// newRateLimits returns configured store of token buckets and limits of routes
+func newRateLimits(rateLimitConfig entities.RateLimitConfig, sqlDB *sql.DB) (ratelimit.Store, map[string]ratelimit.Limit) {
+	limits, limitsErr := ratelimit.ParseLimits(rateLimitConfig.Limits)
+	if limitsErr != nil {
+		log.Fatalf("Error of rate limits loading: %s", limitsErr)
+	}
+	var store ratelimit.Store
+	switch rateLimitConfig.Store {
+	case "memory":
+		store = ratelimit.NewMemoryStore()
+	case "postgres":
+		store = ratelimit.NewPostgresStore(sqlDB)
+	default:
+		log.Fatalf("Unknown rate limit store: %s", rateLimitConfig.Store)
+	}
+	return store, limits
+}
+
+// newOutboxPublishers returns configured publishers of committed events
+func newOutboxPublishers(outboxConfig entities.OutboxConfig, webhookInteractor *usecases.WebhookInteractor) []outbox.Publisher {
+	publishers := make([]outbox.Publisher, 0, len(outboxConfig.Publishers))
+	for _, name := range outboxConfig.Publishers {
+		switch name {
+		case "log":
+			publishers = append(publishers, outbox.NewLogPublisher(log.New(os.Stdout, "", log.LstdFlags)))
+		case "webhooks":
+			publishers = append(publishers, webhookInteractor)
+		case "http":
+			if outboxConfig.HTTPURL == "" {
+				log.Fatalf("OUTBOX_HTTP_URL is required by http publisher")
+			}
+			publishers = append(publishers, outbox.NewHTTPPublisher(outboxConfig.HTTPURL, webhookTimeout))
+		default:
+			log.Fatalf("Unknown outbox publisher: %s", name)
+		}
+	}
+	return publishers
 }
 
 // Run starts application (with gracefull shutdown)
 func (a App) Run() {
 	log.Printf("Starting web server on port %s...", a.port)
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	go a.dispatcher.Run(workersCtx, a.outboxInterval)
+	go a.webhookInteractor.Run(workersCtx, webhookInterval)
+	go a.broker.Run(workersCtx, a.listener.Notify)
+	if sharedBuckets, isShared := a.rateLimitStore.(*ratelimit.PostgresStore); isShared {
+		go sharedBuckets.RunPurge(workersCtx, bucketsPurgeInterval, a.rateLimitIdle)
+	}
+
 	go func() {
 		if err := a.server.ListenAndServe(); err != nil {
 			log.Println(err)
 		}
 	}()
 
+	if a.grpcServer != nil {
+		grpcListener, listenErr := net.Listen("tcp", a.grpcAddr)
+		if listenErr != nil {
+			log.Fatalf("Error of gRPC listening: %s", listenErr)
+		}
+		log.Printf("Starting gRPC server on %s...", a.grpcAddr)
+		go func() {
+			if err := a.grpcServer.Serve(grpcListener); err != nil {
+				log.Println(err)
+			}
+		}()
+	}
+
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
 
 	<-c
+	stopWorkers()
+	_ = a.listener.Close()
 
 	ctx, cancel := context.WithTimeout(context.Background(), a.wait)
 	defer cancel()
 
+	// Streams of gRPC clients are cancelled when graceful stop takes longer than HTTP shutdown allows
+	grpcStopped := make(chan struct{})
+	go func() {
+		if a.grpcServer != nil {
+			a.grpcServer.GracefulStop()
+		}
+		close(grpcStopped)
+	}()
+
 	shutdownErr := a.server.Shutdown(ctx)
 	if shutdownErr != nil {
 		log.Fatalf("Error of server shutdown: %s", shutdownErr)
 	}
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		if a.grpcServer != nil {
+			a.grpcServer.Stop()
+		}
+	}
 
 	log.Println("Shutting down the service...")
 	os.Exit(0)
@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// APIKeyPrefix distinguishes API keys from tokens in Authorization header
+const APIKeyPrefix = "bk_"
+
+// apiKeyVisiblePrefix is length of the key's part stored in plain text to recognize the key
+const apiKeyVisiblePrefix = 8
+
+// GenerateAPIKey returns new random API key, its prefix and hash to store
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	random := make([]byte, 32)
+	if _, readErr := rand.Read(random); readErr != nil {
+		return "", "", "", fmt.Errorf("error of API key generation: %s", readErr)
+	}
+	key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(random)
+	return key, key[:apiKeyVisiblePrefix], HashAPIKey(key), nil
+}
+
+// HashAPIKey returns hex SHA-256 of API key. Keys are random, so hash does not need salt.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey checks that credential is API key and not token
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
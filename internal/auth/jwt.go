@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Signing algorithms of accepted tokens
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+)
+
+// DefaultLeeway is allowed clock skew of exp and nbf claims
+const DefaultLeeway = 30 * time.Second
+
+var errInvalidToken = errors.New("malformed token")
+
+// TokenVerifier defines contracts for verification of end users' tokens
+type TokenVerifier interface {
+	Verify(token string) (*Claims, error)
+}
+
+// Claims represents verified claims of token
+type Claims struct {
+	Subject   string
+	Issuer    string
+	Audience  []string
+	ExpiresAt int64
+	NotBefore int64
+	Scopes    []string
+}
+
+// JWTConfig represents keys and expected claims of tokens
+type JWTConfig struct {
+	Secret    []byte         // key of HS256 tokens, they are rejected when empty
+	PublicKey *rsa.PublicKey // key of RS256 tokens, they are rejected when nil
+	Issuer    string         // expected iss claim, it is not checked when empty
+	Audience  string         // expected aud claim, it is not checked when empty
+	Leeway    time.Duration
+}
+
+// JWTVerifier implements TokenVerifier interface for HS256 and RS256 tokens
+type JWTVerifier struct {
+	config JWTConfig
+	now    func() time.Time
+}
+
+// NewJWTVerifier returns verifier of tokens signed with configured keys
+func NewJWTVerifier(config JWTConfig) (*JWTVerifier, error) {
+	if len(config.Secret) == 0 && config.PublicKey == nil {
+		return nil, fmt.Errorf("neither HS256 secret nor RS256 public key is configured")
+	}
+	return &JWTVerifier{
+		config: config,
+		now:    time.Now,
+	}, nil
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+}
+
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *json.Number    `json:"exp"`
+	NotBefore *json.Number    `json:"nbf"`
+	Scope     string          `json:"scope"`
+	Scopes    []string        `json:"scopes"`
+}
+
+// Verify checks signature and time limits of token and returns its claims.
+// Algorithm of the token must be one of configured ones, tokens without exp claim are rejected.
+func (jv *JWTVerifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errInvalidToken
+	}
+	var header jwtHeader
+	if decodeErr := decodeSegment(parts[0], &header); decodeErr != nil {
+		return nil, decodeErr
+	}
+	signature, signatureErr := base64.RawURLEncoding.DecodeString(parts[2])
+	if signatureErr != nil {
+		return nil, errInvalidToken
+	}
+	if verifyErr := jv.verifySignature(header.Algorithm, parts[0]+"."+parts[1], signature); verifyErr != nil {
+		return nil, verifyErr
+	}
+
+	var raw jwtClaims
+	if decodeErr := decodeSegment(parts[1], &raw); decodeErr != nil {
+		return nil, decodeErr
+	}
+	claims, claimsErr := raw.claims()
+	if claimsErr != nil {
+		return nil, claimsErr
+	}
+	if checkErr := jv.checkClaims(claims); checkErr != nil {
+		return nil, checkErr
+	}
+	return claims, nil
+}
+
+func (jv *JWTVerifier) verifySignature(algorithm, signed string, signature []byte) error {
+	switch {
+	case algorithm == AlgHS256 && len(jv.config.Secret) > 0:
+		if !hmac.Equal(signature, signHMAC(jv.config.Secret, signed)) {
+			return fmt.Errorf("invalid token signature")
+		}
+	case algorithm == AlgRS256 && jv.config.PublicKey != nil:
+		digest := sha256.Sum256([]byte(signed))
+		if rsa.VerifyPKCS1v15(jv.config.PublicKey, crypto.SHA256, digest[:], signature) != nil {
+			return fmt.Errorf("invalid token signature")
+		}
+	default:
+		return fmt.Errorf("token algorithm %q is not accepted", algorithm)
+	}
+	return nil
+}
+
+func (jv *JWTVerifier) checkClaims(claims *Claims) error {
+	now := jv.now().Unix()
+	leeway := int64(jv.config.Leeway / time.Second)
+	if claims.ExpiresAt == 0 {
+		return fmt.Errorf("token has no expiration time")
+	}
+	if now > claims.ExpiresAt+leeway {
+		return fmt.Errorf("token is expired")
+	}
+	if claims.NotBefore != 0 && now < claims.NotBefore-leeway {
+		return fmt.Errorf("token is not valid yet")
+	}
+	if jv.config.Issuer != "" && claims.Issuer != jv.config.Issuer {
+		return fmt.Errorf("unexpected token issuer %q", claims.Issuer)
+	}
+	if jv.config.Audience != "" && !containsString(claims.Audience, jv.config.Audience) {
+		return fmt.Errorf("token is not issued for %q", jv.config.Audience)
+	}
+	if claims.Subject == "" {
+		return fmt.Errorf("token has no subject")
+	}
+	return nil
+}
+
+// claims converts decoded payload: aud may be string or array, scopes are given
+// as space-separated scope claim or as scopes array
+func (jc jwtClaims) claims() (*Claims, error) {
+	claims := &Claims{
+		Subject: jc.Subject,
+		Issuer:  jc.Issuer,
+	}
+	claims.Scopes = append(claims.Scopes, strings.Fields(jc.Scope)...)
+	claims.Scopes = append(claims.Scopes, jc.Scopes...)
+	if len(jc.Audience) > 0 {
+		var audience string
+		if json.Unmarshal(jc.Audience, &audience) == nil {
+			claims.Audience = []string{audience}
+		} else if json.Unmarshal(jc.Audience, &claims.Audience) != nil {
+			return nil, fmt.Errorf("invalid aud claim")
+		}
+	}
+	for _, timeClaim := range []struct {
+		value  *json.Number
+		target *int64
+	}{
+		{jc.ExpiresAt, &claims.ExpiresAt},
+		{jc.NotBefore, &claims.NotBefore},
+	} {
+		if timeClaim.value == nil {
+			continue
+		}
+		seconds, parseErr := timeClaim.value.Float64()
+		if parseErr != nil {
+			return nil, fmt.Errorf("invalid time claim: %s", parseErr)
+		}
+		*timeClaim.target = int64(seconds)
+	}
+	return claims, nil
+}
+
+// SignHS256 returns HS256 token with given claims
+func SignHS256(claims map[string]interface{}, secret []byte) (string, error) {
+	header, _ := json.Marshal(jwtHeader{Algorithm: AlgHS256, Type: "JWT"})
+	payload, marshalErr := json.Marshal(claims)
+	if marshalErr != nil {
+		return "", fmt.Errorf("error of claims marshalling: %s", marshalErr)
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signHMAC(secret, signed)), nil
+}
+
+// ParseRSAPublicKey reads RS256 public key given as PEM or as base64 of DER (PKIX or PKCS #1)
+func ParseRSAPublicKey(encoded string) (*rsa.PublicKey, error) {
+	der := []byte(encoded)
+	if block, _ := pem.Decode(der); block != nil {
+		der = block.Bytes
+	} else {
+		decoded, decodeErr := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if decodeErr != nil {
+			return nil, fmt.Errorf("error of public key decoding: %s", decodeErr)
+		}
+		der = decoded
+	}
+	if publicKey, pkixErr := x509.ParsePKIXPublicKey(der); pkixErr == nil {
+		rsaKey, isRSA := publicKey.(*rsa.PublicKey)
+		if !isRSA {
+			return nil, fmt.Errorf("public key is not RSA key")
+		}
+		return rsaKey, nil
+	}
+	rsaKey, parseErr := x509.ParsePKCS1PublicKey(der)
+	if parseErr != nil {
+		return nil, fmt.Errorf("error of public key parsing: %s", parseErr)
+	}
+	return rsaKey, nil
+}
+
+func decodeSegment(segment string, target interface{}) error {
+	data, decodeErr := base64.RawURLEncoding.DecodeString(segment)
+	if decodeErr != nil {
+		return errInvalidToken
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	if jsonErr := decoder.Decode(target); jsonErr != nil {
+		return errInvalidToken
+	}
+	return nil
+}
+
+func signHMAC(secret []byte, signed string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return mac.Sum(nil)
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte("secret")
+
+// signRS256 returns RS256 token with given claims
+func signRS256(t *testing.T, claims map[string]interface{}, key *rsa.PrivateKey) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	payload, _ := json.Marshal(claims)
+	signed := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, signErr := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if signErr != nil {
+		t.Fatalf("Unexpected error: %s", signErr)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// Test verification of HS256 and RS256 tokens
+func TestJWTVerifier(t *testing.T) {
+	rsaKey, keyErr := rsa.GenerateKey(rand.Reader, 2048)
+	if keyErr != nil {
+		t.Fatalf("Unexpected error: %s", keyErr)
+	}
+	now := time.Unix(1700000000, 0)
+	verifier, _ := NewJWTVerifier(JWTConfig{
+		Secret:    testSecret,
+		PublicKey: &rsaKey.PublicKey,
+		Issuer:    "issuer",
+		Audience:  "billing",
+		Leeway:    DefaultLeeway,
+	})
+	verifier.now = func() time.Time { return now }
+	hsOnly, _ := NewJWTVerifier(JWTConfig{Secret: testSecret})
+	hsOnly.now = verifier.now
+
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		result := map[string]interface{}{
+			"sub": "1", "iss": "issuer", "aud": "billing", "exp": now.Unix() + 60, "scope": "read write",
+		}
+		for key, value := range overrides {
+			if value == nil {
+				delete(result, key)
+				continue
+			}
+			result[key] = value
+		}
+		return result
+	}
+	hs256 := func(overrides map[string]interface{}) string {
+		token, _ := SignHS256(claims(overrides), testSecret)
+		return token
+	}
+	validToken := hs256(nil)
+
+	tests := []struct {
+		name     string
+		verifier *JWTVerifier
+		token    string
+		expected *Claims
+		err      string
+	}{
+		{
+			name:     "HS256 token",
+			verifier: verifier,
+			token:    validToken,
+			expected: &Claims{Subject: "1", Issuer: "issuer", Audience: []string{"billing"}, ExpiresAt: now.Unix() + 60, Scopes: []string{"read", "write"}},
+		},
+		{
+			name:     "RS256 token with audience array and scopes array",
+			verifier: verifier,
+			token:    signRS256(t, claims(map[string]interface{}{"aud": []string{"other", "billing"}, "scope": nil, "scopes": []string{"admin"}}), rsaKey),
+			expected: &Claims{Subject: "1", Issuer: "issuer", Audience: []string{"other", "billing"}, ExpiresAt: now.Unix() + 60, Scopes: []string{"admin"}},
+		},
+		{name: "RS256 token without public key", verifier: hsOnly, token: signRS256(t, claims(nil), rsaKey), err: "algorithm \"RS256\" is not accepted"},
+		{name: "Algorithm none", verifier: verifier, token: base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + strings.Split(validToken, ".")[1] + ".", err: "not accepted"},
+		{name: "Wrong secret", verifier: verifier, token: func() string { token, _ := SignHS256(claims(nil), []byte("other")); return token }(), err: "invalid token signature"},
+		{name: "Modified payload", verifier: verifier, token: strings.Split(validToken, ".")[0] + "." + strings.Split(hs256(map[string]interface{}{"sub": "2"}), ".")[1] + "." + strings.Split(validToken, ".")[2], err: "invalid token signature"},
+		{name: "Expired token", verifier: verifier, token: hs256(map[string]interface{}{"exp": now.Unix() - 31}), err: "token is expired"},
+		{name: "Expired token within leeway", verifier: verifier, token: hs256(map[string]interface{}{"exp": now.Unix() - 10, "scope": nil}), expected: &Claims{Subject: "1", Issuer: "issuer", Audience: []string{"billing"}, ExpiresAt: now.Unix() - 10}},
+		{name: "Token without expiration", verifier: verifier, token: hs256(map[string]interface{}{"exp": nil}), err: "no expiration time"},
+		{name: "Token is not valid yet", verifier: verifier, token: hs256(map[string]interface{}{"nbf": now.Unix() + 60}), err: "not valid yet"},
+		{name: "Wrong issuer", verifier: verifier, token: hs256(map[string]interface{}{"iss": "other"}), err: "unexpected token issuer"},
+		{name: "Wrong audience", verifier: verifier, token: hs256(map[string]interface{}{"aud": "other"}), err: "is not issued for"},
+		{name: "No subject", verifier: verifier, token: hs256(map[string]interface{}{"sub": nil}), err: "no subject"},
+		{name: "Malformed token", verifier: verifier, token: "a.b", err: "malformed token"},
+	}
+	for _, tc := range tests {
+		result, err := tc.verifier.Verify(tc.token)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("[%s] Expected error %q, got %v", tc.name, tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%s] Unexpected error: %s", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, tc.expected) {
+			t.Errorf("[%s] Expected claims %+v, got %+v", tc.name, tc.expected, result)
+		}
+	}
+}
+
+// Test verifier requires at least one key
+func TestNewJWTVerifier(t *testing.T) {
+	if _, err := NewJWTVerifier(JWTConfig{Issuer: "issuer"}); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+// Test parsing of RS256 public key in PEM and base64 DER
+func TestParseRSAPublicKey(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	pkix, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	pkcs1 := x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)
+
+	for name, encoded := range map[string]string{
+		"PEM":          string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix})),
+		"base64 PKIX":  base64.StdEncoding.EncodeToString(pkix),
+		"base64 PKCS1": base64.StdEncoding.EncodeToString(pkcs1),
+	} {
+		publicKey, err := ParseRSAPublicKey(encoded)
+		if err != nil {
+			t.Errorf("[%s] Unexpected error: %s", name, err)
+			continue
+		}
+		if publicKey.N.Cmp(rsaKey.PublicKey.N) != 0 {
+			t.Errorf("[%s] Wrong key", name)
+		}
+	}
+	if _, err := ParseRSAPublicKey("not a key"); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+// Test generation of API keys
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, hash, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !IsAPIKey(key) || !strings.HasPrefix(key, prefix) || len(prefix) != 8 {
+		t.Errorf("Wrong key %s with prefix %s", key, prefix)
+	}
+	if hash != HashAPIKey(key) || len(hash) != 64 {
+		t.Errorf("Wrong hash %s", hash)
+	}
+	other, _, _, _ := GenerateAPIKey()
+	if other == key {
+		t.Errorf("Keys are not random")
+	}
+}
@@ -0,0 +1,82 @@
+package auth
+
+import "billing_system_test_task/internal/entities"
+
+// Roles of principals; roles are granted as scopes, callers bound to user have user role
+const (
+	RoleAdmin   = entities.ScopeAdmin
+	RoleFinance = "finance"
+	RoleSupport = "support"
+	RoleUser    = "user"
+)
+
+// Permissions required by API actions
+const (
+	PermUsersCreate          = "users:create"
+	PermWalletsEnroll        = "wallets:enroll"
+	PermWalletsTransfer      = "wallets:transfer"
+	PermWalletsRead          = "wallets:read"
+	PermOperationsRead       = "operations:read"
+	PermStatementsRead       = "statements:read" // statements of own wallets, operations:read grants statements of any wallet
+	PermReportsRead          = "reports:read"
+	PermReportsDownload      = "reports:download"
+	PermReportsVerify        = "reports:verify"
+	PermReportTemplatesRead  = "report_templates:read"
+	PermReportTemplatesWrite = "report_templates:write"
+	PermFeedsConsume         = "feeds:consume"
+	PermAPIKeysManage        = "api_keys:manage"
+	PermWebhooksManage       = "webhooks:manage" // subscriptions receive events of all wallets, so it is granted to admins only
+)
+
+// rolePermissions is the permission matrix; admin is granted with all permissions
+var rolePermissions = map[string][]string{
+	RoleUser: {
+		PermWalletsTransfer,
+		PermWalletsRead,
+		PermStatementsRead,
+		PermReportsVerify,
+	},
+	RoleSupport: {
+		PermUsersCreate,
+		PermOperationsRead,
+		PermStatementsRead,
+		PermReportsDownload,
+		PermReportsVerify,
+		PermReportTemplatesRead,
+	},
+	RoleFinance: {
+		PermWalletsEnroll,
+		PermOperationsRead,
+		PermStatementsRead,
+		PermReportsRead,
+		PermReportsDownload,
+		PermReportsVerify,
+		PermReportTemplatesRead,
+		PermReportTemplatesWrite,
+		PermFeedsConsume,
+	},
+}
+
+// RolesOf returns roles of principal
+func RolesOf(principal *entities.Principal) []string {
+	var roles []string
+	if principal.UserID != 0 {
+		roles = append(roles, RoleUser)
+	}
+	for _, scope := range principal.Scopes {
+		if _, isRole := rolePermissions[scope]; (isRole || scope == RoleAdmin) && !containsString(roles, scope) {
+			roles = append(roles, scope)
+		}
+	}
+	return roles
+}
+
+// HasPermission checks that one of principal's roles grants permission
+func HasPermission(principal *entities.Principal, permission string) bool {
+	for _, role := range RolesOf(principal) {
+		if role == RoleAdmin || containsString(rolePermissions[role], permission) {
+			return true
+		}
+	}
+	return false
+}
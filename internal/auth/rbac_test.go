@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"billing_system_test_task/internal/entities"
+	"reflect"
+	"testing"
+)
+
+// Test permission matrix of roles
+func TestHasPermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		principal  *entities.Principal
+		roles      []string
+		permission string
+		allowed    bool
+	}{
+		{name: "User transfers funds", principal: &entities.Principal{UserID: 1}, roles: []string{RoleUser}, permission: PermWalletsTransfer, allowed: true},
+		{name: "User reads wallet events", principal: &entities.Principal{UserID: 1}, roles: []string{RoleUser}, permission: PermWalletsRead, allowed: true},
+		{name: "User lists operations", principal: &entities.Principal{UserID: 1}, roles: []string{RoleUser}, permission: PermOperationsRead},
+		{name: "Support creates user", principal: &entities.Principal{Scopes: []string{RoleSupport, "read"}}, roles: []string{RoleSupport}, permission: PermUsersCreate, allowed: true},
+		{name: "Support saves template", principal: &entities.Principal{Scopes: []string{RoleSupport}}, roles: []string{RoleSupport}, permission: PermReportTemplatesWrite},
+		{name: "Finance user reads reports", principal: &entities.Principal{UserID: 2, Scopes: []string{RoleFinance}}, roles: []string{RoleUser, RoleFinance}, permission: PermReportsRead, allowed: true},
+		{name: "Finance manages webhooks", principal: &entities.Principal{Scopes: []string{RoleFinance}}, roles: []string{RoleFinance}, permission: PermWebhooksManage},
+		{name: "Support manages webhooks", principal: &entities.Principal{Scopes: []string{RoleSupport}}, roles: []string{RoleSupport}, permission: PermWebhooksManage},
+		{name: "Finance manages API keys", principal: &entities.Principal{Scopes: []string{RoleFinance}}, roles: []string{RoleFinance}, permission: PermAPIKeysManage},
+		{name: "Admin manages API keys", principal: &entities.Principal{Scopes: []string{RoleAdmin, RoleAdmin}}, roles: []string{RoleAdmin}, permission: PermAPIKeysManage, allowed: true},
+		{name: "Server key without roles", principal: &entities.Principal{Scopes: []string{"read"}}, permission: PermReportsVerify},
+	}
+	for _, tc := range tests {
+		if roles := RolesOf(tc.principal); !reflect.DeepEqual(roles, tc.roles) {
+			t.Errorf("[%s] Expected roles %v, got %v", tc.name, tc.roles, roles)
+		}
+		if allowed := HasPermission(tc.principal, tc.permission); allowed != tc.allowed {
+			t.Errorf("[%s] Expected permission %t, got %t", tc.name, tc.allowed, allowed)
+		}
+	}
+}
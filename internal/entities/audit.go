@@ -0,0 +1,22 @@
+package entities
+
+import "time"
+
+// Outcomes of audited actions
+const (
+	AuditOutcomeDenied = "denied"
+)
+
+// AuditEvent represents record of audit trail about action of principal
+type AuditEvent struct {
+	ID            int
+	Subject       string // empty for anonymous caller
+	AuthMethod    string
+	Action        string // route name
+	Permission    string
+	Outcome       string
+	RequestMethod string
+	Path          string
+	RemoteAddr    string
+	CreatedAt     time.Time
+}
@@ -0,0 +1,59 @@
+package entities
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// ScopeAdmin grants access to wallets of all users and to management of API keys
+const ScopeAdmin = "admin"
+
+// Authentication methods of principals
+const (
+	AuthMethodAPIKey = "api_key"
+	AuthMethodJWT    = "jwt"
+	AuthMethodCLI    = "cli" // operator of the admin CLI with access to the database
+)
+
+// Principal represents authenticated caller of the API
+type Principal struct {
+	Subject string
+	UserID  int // zero when caller is not bound to user, e.g. server's API key
+	Scopes  []string
+	Method  string
+}
+
+// HasScope checks that principal is granted with scope
+func (p *Principal) HasScope(scope string) bool {
+	for _, granted := range p.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns context with authenticated principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns authenticated principal or nil
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
+
+// APIKey represents revocable API key; only hash of the key is stored
+type APIKey struct {
+	ID        int
+	Name      string
+	Prefix    string // first characters of the key to recognize it in lists
+	UserID    int
+	Scopes    []string
+	CreatedAt time.Time
+	RevokedAt sql.NullTime
+}
@@ -2,103 +2,240 @@ package entities
 
 import (
 	"fmt"
-	"log"
-	"os"
-	"path"
-	"strings"
+	"strconv"
 	"time"
-
-	"github.com/joho/godotenv"
 )
 
 type ConfigAdapter interface {
-	LoadEnvVariables(appDelimiter string) error
-	GetPathDelimiter() string
 	GetDBConnectionString() string
+	GetDBProvider() string
+	GetDBPoolConfig() DBPoolConfig
 	GetWaitTime() time.Duration
+	GetServerTimeouts() ServerTimeouts
 	GetAppHost() string
 	GetAppPort() string
-	GetDBProvider() string
+	GetGRPCPort() string
+	GetReportSigningKey() string
+	GetReportStorageConfig() ReportStorageConfig
+	GetReportEncryptionKey() string
+	GetAuthConfig() AuthConfig
+	GetRateLimitConfig() RateLimitConfig
+	GetOutboxConfig() OutboxConfig
+	GetFeatures() Features
 }
 
-type EnvConfig struct {
+// ServerConfig represents addresses and timeouts of HTTP and gRPC servers
+type ServerConfig struct {
+	Host            string        `toml:"host"`
+	Port            int           `toml:"port"`
+	GRPCPort        int           `toml:"grpcPort"`
+	ReadTimeout     time.Duration `toml:"readTimeout"`
+	WriteTimeout    time.Duration `toml:"writeTimeout"`
+	ShutdownTimeout time.Duration `toml:"shutdownTimeout"`
 }
 
-func NewEnvConfig() *EnvConfig {
-	return &EnvConfig{}
+// ServerTimeouts represents timeouts of HTTP requests
+type ServerTimeouts struct {
+	Read  time.Duration
+	Write time.Duration
 }
 
-func (ec EnvConfig) GetPathDelimiter() string {
-	return getEnv("PATH_SEPARATOR", "cmd")
+// DatabaseConfig represents connection to the database and its pool
+type DatabaseConfig struct {
+	Provider string `toml:"provider"`
+	Host     string `toml:"host"`
+	Port     int    `toml:"port"`
+	User     string `toml:"user"`
+	Password string `toml:"password"`
+	Name     string `toml:"name"`
+	SSLMode  string `toml:"sslMode"`
+	DBPoolConfig
 }
 
-func (ec EnvConfig) GetDBConnectionString() string {
-	var (
-		sqlDbUser     = getEnv("DB_USER", "user")
-		sqlDbPassword = getEnv("DB_PASSWORD", "password")
-		sqlDbHost     = getEnv("DB_HOST", "postgres")
-		sqlDbPort     = getEnv("DB_PORT", "3306")
-		sqlDbName     = getEnv("POSTGRES_DB", "billing")
-	)
-	return fmt.Sprintf("port=%s host=%s user=%s "+
-		"password=%s dbname=%s sslmode=disable",
-		sqlDbPort, sqlDbHost, sqlDbUser, sqlDbPassword, sqlDbName)
+// DBPoolConfig represents limits of the connections pool; zero values mean no limit
+type DBPoolConfig struct {
+	MaxOpenConns    int           `toml:"maxOpenConns"`
+	MaxIdleConns    int           `toml:"maxIdleConns"`
+	ConnMaxLifetime time.Duration `toml:"connMaxLifetime"`
+	ConnMaxIdleTime time.Duration `toml:"connMaxIdleTime"`
+}
 
+// ReportsConfig represents keys of reports and their storage
+type ReportsConfig struct {
+	SigningKey    string              `toml:"signingKey"`    // base64 seed of Ed25519 key
+	EncryptionKey string              `toml:"encryptionKey"` // base64 master key of encrypt=key
+	Storage       ReportStorageConfig `toml:"storage"`
 }
 
-func (ec EnvConfig) GetWaitTime() time.Duration {
-	return time.Second * 5
+// AuthConfig represents keys and expected claims of end users' tokens
+type AuthConfig struct {
+	JWTSecret    string `toml:"jwtSecret"`    // HS256 secret
+	JWTPublicKey string `toml:"jwtPublicKey"` // RS256 public key, PEM or base64 of DER
+	JWTIssuer    string `toml:"jwtIssuer"`
+	JWTAudience  string `toml:"jwtAudience"`
 }
 
-func (ec EnvConfig) GetAppHost() string {
-	return getEnv("APP_HOST", "app")
+// ReportStorageConfig represents backend of reports storage
+type ReportStorageConfig struct {
+	Backend     string `toml:"backend"`
+	Dir         string `toml:"dir"`
+	S3Endpoint  string `toml:"s3Endpoint"`
+	S3Region    string `toml:"s3Region"`
+	S3Bucket    string `toml:"s3Bucket"`
+	S3AccessKey string `toml:"s3AccessKey"`
+	S3SecretKey string `toml:"s3SecretKey"`
 }
 
-func (ec EnvConfig) GetAppPort() string {
-	return getEnv("APP_PORT", "8000")
+// RateLimitConfig represents limits of clients' requests and of simultaneous reports generation
+type RateLimitConfig struct {
+	Limits                      string `toml:"limits"` // limits of routes and of client's address, e.g. "default=20/s:40,address=50/s:100,TRANSFER_FUNDS=5/s"
+	Store                       string `toml:"store"`  // memory or postgres
+	ReportsConcurrency          int    `toml:"reportsConcurrency"`
+	ReportsConcurrencyPerClient int    `toml:"reportsConcurrencyPerClient"`
 }
 
-func (ec EnvConfig) GetDBProvider() string {
-	return getEnv("DB_PROVIDER", "postgres")
+// OutboxConfig represents publishers of committed domain events
+type OutboxConfig struct {
+	Publishers []string      `toml:"publishers"` // log, webhooks and http
+	HTTPURL    string        `toml:"httpUrl"`    // receiver of http publisher
+	Interval   time.Duration `toml:"interval"`
 }
 
-func (ec EnvConfig) LoadEnvVariables(appDelimiter string) error {
-	projectPath := ec.getProjectPath(appDelimiter)
-	envPath := path.Join(projectPath, ".env")
-	err := godotenv.Overload(envPath)
-	if err != nil {
-		return err
-	}
-	return nil
+// Features represents toggles of optional parts of the server
+type Features struct {
+	AutoMigrate bool `toml:"autoMigrate"` // apply pending migrations on startup
+	GRPC        bool `toml:"grpc"`        // serve billing.v1 gRPC API
 }
 
-func (ec EnvConfig) getProjectPath(defaultDelimiter string) string {
-	projectDirectory, directoryErr := os.Getwd()
+// Settings represents all values of configuration; sections and keys are names of TOML files and flags
+type Settings struct {
+	Server    ServerConfig    `toml:"server"`
+	Database  DatabaseConfig  `toml:"database"`
+	Reports   ReportsConfig   `toml:"reports"`
+	Auth      AuthConfig      `toml:"auth"`
+	RateLimit RateLimitConfig `toml:"rateLimit"`
+	Outbox    OutboxConfig    `toml:"outbox"`
+	Features  Features        `toml:"features"`
+}
 
-	if directoryErr != nil {
-		log.Fatalf("Could not locate current directory: %s", directoryErr)
+// DefaultSettings returns values of configuration used when no layer sets them
+func DefaultSettings() Settings {
+	return Settings{
+		Server: ServerConfig{
+			Host:            "app",
+			Port:            8000,
+			GRPCPort:        9000,
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    15 * time.Second,
+			ShutdownTimeout: 5 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Provider: "postgres",
+			Host:     "postgres",
+			Port:     5432,
+			User:     "user",
+			Password: "password",
+			Name:     "billing",
+			SSLMode:  "disable",
+			DBPoolConfig: DBPoolConfig{
+				MaxOpenConns:    20,
+				MaxIdleConns:    5,
+				ConnMaxLifetime: 30 * time.Minute,
+				ConnMaxIdleTime: 5 * time.Minute,
+			},
+		},
+		Reports: ReportsConfig{
+			Storage: ReportStorageConfig{Backend: "local", S3Region: "us-east-1"},
+		},
+		RateLimit: RateLimitConfig{
+			Store:                       "memory",
+			ReportsConcurrency:          8,
+			ReportsConcurrencyPerClient: 2,
+		},
+		Outbox: OutboxConfig{
+			Publishers: []string{"log", "webhooks"},
+			Interval:   time.Second,
+		},
+		Features: Features{GRPC: true},
 	}
+}
 
-	isUnderCmd := strings.Contains(projectDirectory, defaultDelimiter)
-	if isUnderCmd {
-		var cmdIdx int
-		splitPath := strings.Split(projectDirectory, "/")
-		for idx, pathElem := range splitPath {
-			if pathElem == defaultDelimiter {
-				cmdIdx = idx
-				break
-			}
-		}
-		projectDirectory = strings.Join(splitPath[:cmdIdx], "/")
-	}
+// Config implements ConfigAdapter with layered settings, see LoadConfig
+type Config struct {
+	Settings
+	sources map[string]string // layers which set the keys, e.g. "env DB_PORT"
+}
 
-	return projectDirectory
+func (c *Config) GetDBConnectionString() string {
+	db := c.Database
+	return fmt.Sprintf("port=%d host=%s user=%s "+
+		"password=%s dbname=%s sslmode=%s",
+		db.Port, db.Host, db.User, db.Password, db.Name, db.SSLMode)
 }
 
-func getEnv(key, def string) string {
-	value := os.Getenv(key)
-	if len(value) == 0 {
-		return def
-	}
-	return value
+func (c *Config) GetDBProvider() string {
+	return c.Database.Provider
+}
+
+// GetDBPoolConfig returns limits of the database connections pool
+func (c *Config) GetDBPoolConfig() DBPoolConfig {
+	return c.Database.DBPoolConfig
+}
+
+// GetWaitTime returns timeout of graceful shutdown
+func (c *Config) GetWaitTime() time.Duration {
+	return c.Server.ShutdownTimeout
+}
+
+// GetServerTimeouts returns timeouts of HTTP requests
+func (c *Config) GetServerTimeouts() ServerTimeouts {
+	return ServerTimeouts{Read: c.Server.ReadTimeout, Write: c.Server.WriteTimeout}
+}
+
+func (c *Config) GetAppHost() string {
+	return c.Server.Host
+}
+
+func (c *Config) GetAppPort() string {
+	return strconv.Itoa(c.Server.Port)
+}
+
+// GetGRPCPort returns port of gRPC API
+func (c *Config) GetGRPCPort() string {
+	return strconv.Itoa(c.Server.GRPCPort)
+}
+
+// GetReportSigningKey returns base64 seed of Ed25519 key for reports signing
+func (c *Config) GetReportSigningKey() string {
+	return c.Reports.SigningKey
+}
+
+// GetReportEncryptionKey returns base64 master key of reports encryption (encrypt=key)
+func (c *Config) GetReportEncryptionKey() string {
+	return c.Reports.EncryptionKey
+}
+
+// GetReportStorageConfig returns backend of reports storage (local, memory or s3) and its settings
+func (c *Config) GetReportStorageConfig() ReportStorageConfig {
+	return c.Reports.Storage
+}
+
+// GetAuthConfig returns settings of JWT authentication; tokens are rejected when neither key is set
+func (c *Config) GetAuthConfig() AuthConfig {
+	return c.Auth
+}
+
+// GetRateLimitConfig returns rate limits of routes, their storage and limits of simultaneous reports
+func (c *Config) GetRateLimitConfig() RateLimitConfig {
+	return c.RateLimit
+}
+
+// GetOutboxConfig returns publishers of outbox events and polling interval of dispatcher
+func (c *Config) GetOutboxConfig() OutboxConfig {
+	return c.Outbox
+}
+
+// GetFeatures returns toggles of optional parts of the server
+func (c *Config) GetFeatures() Features {
+	return c.Features
 }
@@ -0,0 +1,303 @@
+package entities
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+)
+
+// Files of configuration in the project directory; profile file is config.<APP_ENV>.toml next to the main file
+const (
+	ConfigFileName = "config.toml"
+	EnvFileName    = ".env"
+)
+
+// setting represents value of configuration with its key in files and flags and environment variable
+type setting struct {
+	key   string
+	env   string
+	value interface{}   // pointer to the value in Settings
+	unit  time.Duration // unit of integer durations in environment variable, e.g. OUTBOX_INTERVAL_MS
+}
+
+// settings returns values of configuration which can be set by environment variables and flags
+func (s *Settings) settings() []setting {
+	return []setting{
+		{key: "server.host", env: "APP_HOST", value: &s.Server.Host},
+		{key: "server.port", env: "APP_PORT", value: &s.Server.Port},
+		{key: "server.grpcPort", env: "GRPC_PORT", value: &s.Server.GRPCPort},
+		{key: "server.readTimeout", env: "APP_READ_TIMEOUT", value: &s.Server.ReadTimeout},
+		{key: "server.writeTimeout", env: "APP_WRITE_TIMEOUT", value: &s.Server.WriteTimeout},
+		{key: "server.shutdownTimeout", env: "APP_SHUTDOWN_TIMEOUT", value: &s.Server.ShutdownTimeout},
+		{key: "database.provider", env: "DB_PROVIDER", value: &s.Database.Provider},
+		{key: "database.host", env: "DB_HOST", value: &s.Database.Host},
+		{key: "database.port", env: "DB_PORT", value: &s.Database.Port},
+		{key: "database.user", env: "DB_USER", value: &s.Database.User},
+		{key: "database.password", env: "DB_PASSWORD", value: &s.Database.Password},
+		{key: "database.name", env: "POSTGRES_DB", value: &s.Database.Name},
+		{key: "database.sslMode", env: "DB_SSLMODE", value: &s.Database.SSLMode},
+		{key: "database.maxOpenConns", env: "DB_MAX_OPEN_CONNS", value: &s.Database.MaxOpenConns},
+		{key: "database.maxIdleConns", env: "DB_MAX_IDLE_CONNS", value: &s.Database.MaxIdleConns},
+		{key: "database.connMaxLifetime", env: "DB_CONN_MAX_LIFETIME", value: &s.Database.ConnMaxLifetime},
+		{key: "database.connMaxIdleTime", env: "DB_CONN_MAX_IDLE_TIME", value: &s.Database.ConnMaxIdleTime},
+		{key: "reports.signingKey", env: "REPORT_SIGNING_KEY", value: &s.Reports.SigningKey},
+		{key: "reports.encryptionKey", env: "REPORT_ENCRYPTION_KEY", value: &s.Reports.EncryptionKey},
+		{key: "reports.storage.backend", env: "REPORT_STORAGE", value: &s.Reports.Storage.Backend},
+		{key: "reports.storage.dir", env: "REPORT_STORAGE_DIR", value: &s.Reports.Storage.Dir},
+		{key: "reports.storage.s3Endpoint", env: "S3_ENDPOINT", value: &s.Reports.Storage.S3Endpoint},
+		{key: "reports.storage.s3Region", env: "S3_REGION", value: &s.Reports.Storage.S3Region},
+		{key: "reports.storage.s3Bucket", env: "S3_BUCKET", value: &s.Reports.Storage.S3Bucket},
+		{key: "reports.storage.s3AccessKey", env: "S3_ACCESS_KEY", value: &s.Reports.Storage.S3AccessKey},
+		{key: "reports.storage.s3SecretKey", env: "S3_SECRET_KEY", value: &s.Reports.Storage.S3SecretKey},
+		{key: "auth.jwtSecret", env: "AUTH_JWT_SECRET", value: &s.Auth.JWTSecret},
+		{key: "auth.jwtPublicKey", env: "AUTH_JWT_PUBLIC_KEY", value: &s.Auth.JWTPublicKey},
+		{key: "auth.jwtIssuer", env: "AUTH_JWT_ISSUER", value: &s.Auth.JWTIssuer},
+		{key: "auth.jwtAudience", env: "AUTH_JWT_AUDIENCE", value: &s.Auth.JWTAudience},
+		{key: "rateLimit.limits", env: "RATE_LIMITS", value: &s.RateLimit.Limits},
+		{key: "rateLimit.store", env: "RATE_LIMIT_STORE", value: &s.RateLimit.Store},
+		{key: "rateLimit.reportsConcurrency", env: "REPORTS_MAX_CONCURRENCY", value: &s.RateLimit.ReportsConcurrency},
+		{key: "rateLimit.reportsConcurrencyPerClient", env: "REPORTS_MAX_CONCURRENCY_PER_CLIENT", value: &s.RateLimit.ReportsConcurrencyPerClient},
+		{key: "outbox.publishers", env: "OUTBOX_PUBLISHERS", value: &s.Outbox.Publishers},
+		{key: "outbox.httpUrl", env: "OUTBOX_HTTP_URL", value: &s.Outbox.HTTPURL},
+		{key: "outbox.interval", env: "OUTBOX_INTERVAL_MS", value: &s.Outbox.Interval, unit: time.Millisecond},
+		{key: "features.autoMigrate", env: "AUTO_MIGRATE", value: &s.Features.AutoMigrate},
+		{key: "features.grpc", env: "FEATURE_GRPC", value: &s.Features.GRPC},
+	}
+}
+
+// LoadConfig returns configuration of layers applied in order: defaults, TOML file, file of APP_ENV profile,
+// environment variables (.env file of projectDir is loaded into them) and flags of args.
+// File is config.toml of projectDir unless -config flag or CONFIG_FILE variable is set; missing default files are skipped.
+// Flags are keys of settings, e.g. -server.port=8080; -env selects profile instead of APP_ENV.
+// Returned configuration is validated.
+func LoadConfig(projectDir string, args []string, stderr io.Writer) (*Config, error) {
+	config := &Config{Settings: DefaultSettings(), sources: map[string]string{}}
+	settings := config.settings()
+
+	flags := flag.NewFlagSet("billing", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configFile := flags.String("config", "", "path to TOML file of configuration (CONFIG_FILE)")
+	profile := flags.String("env", "", "profile of configuration, config.<env>.toml is applied after the main file (APP_ENV)")
+	flagValues := map[string]*string{}
+	for _, s := range settings {
+		flagValues[s.key] = flags.String(s.key, "", fmt.Sprintf("overrides %s of files and %s variable", s.key, s.env))
+	}
+	if parseErr := flags.Parse(args); parseErr != nil {
+		return nil, parseErr
+	}
+
+	// Variables of .env file do not override variables set in the environment
+	envPath := path.Join(projectDir, EnvFileName)
+	if envErr := godotenv.Load(envPath); envErr != nil && !os.IsNotExist(envErr) {
+		return nil, fmt.Errorf("error of %s loading: %s", envPath, envErr)
+	}
+
+	// Files
+	explicitFile := *configFile != "" || os.Getenv("CONFIG_FILE") != ""
+	filePath := firstNonEmpty(*configFile, os.Getenv("CONFIG_FILE"), path.Join(projectDir, ConfigFileName))
+	if fileErr := config.decodeFile(filePath, !explicitFile); fileErr != nil {
+		return nil, fileErr
+	}
+	if profileName := firstNonEmpty(*profile, os.Getenv("APP_ENV")); profileName != "" {
+		profilePath := path.Join(filepath.Dir(filePath), fmt.Sprintf("config.%s.toml", profileName))
+		if fileErr := config.decodeFile(profilePath, true); fileErr != nil {
+			return nil, fileErr
+		}
+	}
+
+	// Environment variables, empty variables are not set like in .env.sample
+	problems := []string{}
+	for _, s := range settings {
+		if value := os.Getenv(s.env); value != "" {
+			if setErr := setValue(s, value, true); setErr != nil {
+				problems = append(problems, fmt.Sprintf("%s: %s", s.env, setErr))
+				continue
+			}
+			config.sources[s.key] = "env " + s.env
+		}
+	}
+
+	// Flags
+	flags.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.key != f.Name {
+				continue
+			}
+			if setErr := setValue(s, *flagValues[s.key], false); setErr != nil {
+				problems = append(problems, fmt.Sprintf("-%s: %s", s.key, setErr))
+				return
+			}
+			config.sources[s.key] = "flag -" + s.key
+		}
+	})
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n  %s", strings.Join(problems, "\n  "))
+	}
+
+	if validationErr := config.Validate(); validationErr != nil {
+		return nil, validationErr
+	}
+	return config, nil
+}
+
+// decodeFile applies TOML file to settings; unknown keys are rejected to catch typos
+func (c *Config) decodeFile(filePath string, optional bool) error {
+	if _, statErr := os.Stat(filePath); optional && os.IsNotExist(statErr) {
+		return nil
+	}
+	metadata, decodeErr := toml.DecodeFile(filePath, &c.Settings)
+	if decodeErr != nil {
+		return fmt.Errorf("error of %s loading: %s", filePath, decodeErr)
+	}
+	if undecoded := metadata.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, 0, len(undecoded))
+		for _, key := range undecoded {
+			keys = append(keys, key.String())
+		}
+		return fmt.Errorf("error of %s loading: unknown keys %s", filePath, strings.Join(keys, ", "))
+	}
+	for _, key := range metadata.Keys() {
+		c.sources[key.String()] = filePath
+	}
+	return nil
+}
+
+// setValue parses value of environment variable or flag to the setting
+func setValue(s setting, value string, fromEnv bool) error {
+	switch target := s.value.(type) {
+	case *string:
+		*target = value
+	case *int:
+		parsed, parseErr := strconv.Atoi(value)
+		if parseErr != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+		*target = parsed
+	case *bool:
+		parsed, parseErr := strconv.ParseBool(value)
+		if parseErr != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+		*target = parsed
+	case *time.Duration:
+		if fromEnv && s.unit != 0 {
+			parsed, parseErr := strconv.Atoi(value)
+			if parseErr != nil {
+				return fmt.Errorf("%q is not an integer", value)
+			}
+			*target = time.Duration(parsed) * s.unit
+			return nil
+		}
+		parsed, parseErr := time.ParseDuration(value)
+		if parseErr != nil {
+			return fmt.Errorf("%q is not a duration, e.g. 1m30s", value)
+		}
+		*target = parsed
+	case *[]string:
+		*target = []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*target = append(*target, item)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks settings; error lists all invalid ones with layers which set them
+func (c *Config) Validate() error {
+	problems := []string{}
+	check := func(isValid bool, key, format string, args ...interface{}) {
+		if isValid {
+			return
+		}
+		problem := fmt.Sprintf("%s: %s", key, fmt.Sprintf(format, args...))
+		if source, isSet := c.sources[key]; isSet {
+			problem += fmt.Sprintf(" (set by %s)", source)
+		}
+		problems = append(problems, problem)
+	}
+	validPort := func(port int) bool { return port > 0 && port < 65536 }
+
+	s := c.Settings
+	check(validPort(s.Server.Port), "server.port", "must be between 1 and 65535, got %d", s.Server.Port)
+	check(validPort(s.Server.GRPCPort), "server.grpcPort", "must be between 1 and 65535, got %d", s.Server.GRPCPort)
+	check(!s.Features.GRPC || s.Server.GRPCPort != s.Server.Port, "server.grpcPort", "must differ from server.port %d", s.Server.Port)
+	check(s.Server.ReadTimeout > 0, "server.readTimeout", "must be positive, got %s", s.Server.ReadTimeout)
+	check(s.Server.WriteTimeout > 0, "server.writeTimeout", "must be positive, got %s", s.Server.WriteTimeout)
+	check(s.Server.ShutdownTimeout > 0, "server.shutdownTimeout", "must be positive, got %s", s.Server.ShutdownTimeout)
+
+	check(s.Database.Provider == "postgres", "database.provider", "only postgres is supported, got %q", s.Database.Provider)
+	check(s.Database.Host != "", "database.host", "is required")
+	check(validPort(s.Database.Port), "database.port", "must be between 1 and 65535, got %d", s.Database.Port)
+	check(s.Database.Name != "", "database.name", "is required")
+	check(s.Database.MaxOpenConns >= 0, "database.maxOpenConns", "must not be negative, got %d", s.Database.MaxOpenConns)
+	check(s.Database.MaxIdleConns >= 0, "database.maxIdleConns", "must not be negative, got %d", s.Database.MaxIdleConns)
+	check(s.Database.MaxOpenConns == 0 || s.Database.MaxIdleConns <= s.Database.MaxOpenConns, "database.maxIdleConns",
+		"must not exceed database.maxOpenConns %d, got %d", s.Database.MaxOpenConns, s.Database.MaxIdleConns)
+	check(s.Database.ConnMaxLifetime >= 0, "database.connMaxLifetime", "must not be negative, got %s", s.Database.ConnMaxLifetime)
+	check(s.Database.ConnMaxIdleTime >= 0, "database.connMaxIdleTime", "must not be negative, got %s", s.Database.ConnMaxIdleTime)
+
+	storage := s.Reports.Storage
+	check(storage.Backend == "local" || storage.Backend == "memory" || storage.Backend == "s3", "reports.storage.backend",
+		"must be local, memory or s3, got %q", storage.Backend)
+	check(storage.Backend != "s3" || storage.S3Bucket != "", "reports.storage.s3Bucket", "is required by s3 storage")
+
+	check(s.RateLimit.Store == "memory" || s.RateLimit.Store == "postgres", "rateLimit.store", "must be memory or postgres, got %q", s.RateLimit.Store)
+	check(s.RateLimit.ReportsConcurrency > 0, "rateLimit.reportsConcurrency", "must be positive, got %d", s.RateLimit.ReportsConcurrency)
+	check(s.RateLimit.ReportsConcurrencyPerClient > 0, "rateLimit.reportsConcurrencyPerClient", "must be positive, got %d", s.RateLimit.ReportsConcurrencyPerClient)
+
+	for _, publisher := range s.Outbox.Publishers {
+		check(publisher == "log" || publisher == "webhooks" || publisher == "http", "outbox.publishers", "unknown publisher %q, expected log, webhooks or http", publisher)
+		check(publisher != "http" || s.Outbox.HTTPURL != "", "outbox.httpUrl", "is required by http publisher")
+	}
+	check(s.Outbox.Interval > 0, "outbox.interval", "must be positive, got %s", s.Outbox.Interval)
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// ProjectDir returns directory of the project: parent of cmd directory when binary is run under it, current directory otherwise
+func ProjectDir(cmdDelimiter string) string {
+	projectDirectory, directoryErr := os.Getwd()
+
+	if directoryErr != nil {
+		log.Fatalf("Could not locate current directory: %s", directoryErr)
+	}
+
+	isUnderCmd := strings.Contains(projectDirectory, cmdDelimiter)
+	if isUnderCmd {
+		var cmdIdx int
+		splitPath := strings.Split(projectDirectory, "/")
+		for idx, pathElem := range splitPath {
+			if pathElem == cmdDelimiter {
+				cmdIdx = idx
+				break
+			}
+		}
+		projectDirectory = strings.Join(splitPath[:cmdIdx], "/")
+	}
+
+	return projectDirectory
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
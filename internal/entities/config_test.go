@@ -0,0 +1,161 @@
+package entities
+
+import (
+	"io"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFiles writes files of configuration to temporary project directory
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		if writeErr := os.WriteFile(path.Join(dir, name), []byte(content), 0600); writeErr != nil {
+			t.Fatalf("Error of file writing: %s", writeErr)
+		}
+	}
+	return dir
+}
+
+// unsetenv removes variable for the test and restores it on cleanup
+func unsetenv(t *testing.T, name string) {
+	if value, isSet := os.LookupEnv(name); isSet {
+		t.Cleanup(func() { os.Setenv(name, value) })
+	}
+	os.Unsetenv(name)
+	t.Cleanup(func() { os.Unsetenv(name) })
+}
+
+// Test layers override values in order: defaults, file, profile file, environment variables and flags
+func TestLoadConfigLayers(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.toml": `
+[server]
+  port = 8100
+  readTimeout = "20s"
+[database]
+  host = "db"
+  port = 5433
+  maxOpenConns = 10
+[outbox]
+  publishers = ["log"]
+`,
+		"config.staging.toml": `
+[database]
+  host = "staging-db"
+[features]
+  autoMigrate = true
+`,
+		".env": "DB_USER=billing\n",
+	})
+	t.Setenv("APP_ENV", "staging")
+	t.Setenv("DB_PORT", "5434")
+	t.Setenv("OUTBOX_INTERVAL_MS", "250")
+	unsetenv(t, "DB_USER")
+	t.Setenv("DB_PASSWORD", "")
+
+	config, loadErr := LoadConfig(dir, []string{"-server.port=8200", "-outbox.publishers=log,webhooks"}, io.Discard)
+	if loadErr != nil {
+		t.Fatalf("Unexpected error: %s", loadErr)
+	}
+	if config.GetAppPort() != "8200" || config.GetGRPCPort() != "9000" {
+		t.Errorf("Unexpected ports %s and %s", config.GetAppPort(), config.GetGRPCPort())
+	}
+	if timeouts := config.GetServerTimeouts(); timeouts.Read != 20*time.Second || timeouts.Write != 15*time.Second {
+		t.Errorf("Unexpected timeouts %v", timeouts)
+	}
+	expectedDB := "port=5434 host=staging-db user=billing password=password dbname=billing sslmode=disable"
+	if connString := config.GetDBConnectionString(); connString != expectedDB {
+		t.Errorf("Expected %q, got %q", expectedDB, connString)
+	}
+	if pool := config.GetDBPoolConfig(); pool.MaxOpenConns != 10 || pool.MaxIdleConns != 5 {
+		t.Errorf("Unexpected pool %v", pool)
+	}
+	outbox := config.GetOutboxConfig()
+	if !reflect.DeepEqual(outbox.Publishers, []string{"log", "webhooks"}) || outbox.Interval != 250*time.Millisecond {
+		t.Errorf("Unexpected outbox %v", outbox)
+	}
+	if features := config.GetFeatures(); !features.AutoMigrate || !features.GRPC {
+		t.Errorf("Unexpected features %v", features)
+	}
+}
+
+// Test variables of the environment take precedence over .env file
+func TestLoadConfigEnvFilePrecedence(t *testing.T) {
+	dir := writeFiles(t, map[string]string{".env": "DB_HOST=env-file-db\nDB_SSLMODE=require\n"})
+	t.Setenv("DB_HOST", "real-db")
+	unsetenv(t, "DB_SSLMODE")
+
+	config, loadErr := LoadConfig(dir, nil, io.Discard)
+	if loadErr != nil {
+		t.Fatalf("Unexpected error: %s", loadErr)
+	}
+	if config.Database.Host != "real-db" || config.Database.SSLMode != "require" {
+		t.Errorf("Unexpected host %q and sslmode %q", config.Database.Host, config.Database.SSLMode)
+	}
+}
+
+// Test invalid configuration is rejected with messages naming its keys and sources
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		env      map[string]string
+		args     []string
+		messages []string
+	}{
+		{
+			name:     "Unknown key of file",
+			files:    map[string]string{"config.toml": "[database]\n  hots = \"db\"\n"},
+			messages: []string{"unknown keys database.hots"},
+		},
+		{
+			name:     "Invalid variable",
+			env:      map[string]string{"DB_PORT": "postgres"},
+			messages: []string{`DB_PORT: "postgres" is not an integer`},
+		},
+		{
+			name:     "Invalid flag",
+			args:     []string{"-server.readTimeout=15"},
+			messages: []string{`-server.readTimeout: "15" is not a duration`},
+		},
+		{
+			name:  "Invalid values",
+			files: map[string]string{"config.toml": "[database]\n  port = 70000\n  maxOpenConns = 2\n"},
+			env:   map[string]string{"RATE_LIMIT_STORE": "redis"},
+			args:  []string{"-outbox.publishers=http"},
+			messages: []string{
+				"database.port: must be between 1 and 65535, got 70000 (set by ",
+				"database.maxIdleConns: must not exceed database.maxOpenConns 2, got 5",
+				`rateLimit.store: must be memory or postgres, got "redis" (set by env RATE_LIMIT_STORE)`,
+				"outbox.httpUrl: is required by http publisher",
+			},
+		},
+		{
+			name:     "Missing explicit file",
+			args:     []string{"-config=missing.toml"},
+			messages: []string{"error of missing.toml loading"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, tc.files)
+			for name, value := range tc.env {
+				t.Setenv(name, value)
+			}
+			_, loadErr := LoadConfig(dir, tc.args, io.Discard)
+			if loadErr == nil {
+				t.Fatal("Expected error")
+			}
+			for _, message := range tc.messages {
+				if !strings.Contains(loadErr.Error(), message) {
+					t.Errorf("Expected %q in error %q", message, loadErr)
+				}
+			}
+		})
+	}
+}
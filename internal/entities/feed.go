@@ -0,0 +1,63 @@
+package entities
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FeedPosition represents position of the operations feed: transaction of the last delivered
+// operation and its id. Operations are ordered by transaction, so rows committed after the
+// position never appear before it.
+type FeedPosition struct {
+	TxID        int64
+	OperationID int
+}
+
+// String formats position as feed cursor
+func (fp FeedPosition) String() string {
+	return fmt.Sprintf("%d-%d", fp.TxID, fp.OperationID)
+}
+
+// ParseFeedPosition reads position from feed cursor
+func ParseFeedPosition(cursor string) (FeedPosition, error) {
+	parts := strings.Split(cursor, "-")
+	if len(parts) != 2 {
+		return FeedPosition{}, fmt.Errorf("invalid feed cursor: %s", cursor)
+	}
+	txID, txErr := strconv.ParseInt(parts[0], 10, 64)
+	operationID, operationErr := strconv.Atoi(parts[1])
+	if txErr != nil || operationErr != nil || txID < 0 || operationID < 0 {
+		return FeedPosition{}, fmt.Errorf("invalid feed cursor: %s", cursor)
+	}
+	return FeedPosition{TxID: txID, OperationID: operationID}, nil
+}
+
+// Less reports whether position is before other position
+func (fp FeedPosition) Less(other FeedPosition) bool {
+	return fp.TxID < other.TxID || fp.TxID == other.TxID && fp.OperationID < other.OperationID
+}
+
+// FeedOperation represents operation with its feed position
+type FeedOperation struct {
+	Position FeedPosition
+	WalletOperation
+}
+
+// FeedConsumer represents named reader of the operations feed with its acknowledged position
+type FeedConsumer struct {
+	Name          string
+	Position      FeedPosition
+	LastCreatedAt sql.NullTime
+	AckedAt       sql.NullTime
+	Owner         string // subject of principal which created the consumer, empty when it is available to admins only
+}
+
+// FeedBatch represents operations of the feed after consumer's position up to To position.
+// Report is nil when there are no new operations.
+type FeedBatch struct {
+	Consumer *FeedConsumer
+	To       FeedPosition
+	Report   *FileMetadata
+}
@@ -1,29 +1,84 @@
 package entities
 
-import "os"
+import (
+	"io"
+	"time"
+)
 
-// FileParams represents information about created file
+// FileParams represents information about created file; report is stored when writer is closed
 type FileParams struct {
-	File      *os.File
-	Path      string
+	Writer    io.WriteCloser
 	Name      string
 	CsvWriter CSVWriter
 }
 
-// Metadata represents given file's metadata
+// ReportFile represents content of stored report
+type ReportFile interface {
+	io.ReadSeekCloser
+	Size() int64
+	ModTime() time.Time
+}
+
+// Metadata represents given file's metadata; signature is empty when signer is not configured
 type Metadata struct {
 	Size        string
 	ContentType string
+	SHA256      []byte
+	Signature   []byte
+	KeyID       string
 }
 
 type CSVWriter interface {
 	Write(record []string) error
 	Flush()
+	Error() error
 }
 
+// FileMetadata represents stored report; its content should be closed after sending
 type FileMetadata struct {
-	File        *os.File
-	Path        string
+	Name        string
+	Content     ReportFile
+	Persisted   bool
 	Size        string
 	ContentType string
+	SHA256      []byte
+	Signature   []byte
+	KeyID       string
+	Stats       []StageStats
+	Manifest    *ReportManifest
+	// ManifestData is canonical json of the manifest, ManifestSignature is signature of its checksum
+	ManifestData      []byte
+	ManifestSignature []byte
+}
+
+// StageStats represents duration and backpressure metrics of report's pipeline stage
+type StageStats struct {
+	Stage          string
+	ItemsIn        int64
+	ItemsOut       int64
+	SendBlocked    time.Duration
+	ReceiveBlocked time.Duration
+	Duration       time.Duration
+}
+
+// ReportManifest represents detached manifest of generated report
+type ReportManifest struct {
+	Format      string            `json:"format"`
+	Filters     map[string]string `json:"filters"`
+	Rows        int               `json:"rows"`
+	Size        string            `json:"size"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	SHA256      string            `json:"sha256"`
+	Compression string            `json:"compression,omitempty"`
+	Encryption  string            `json:"encryption,omitempty"`
+	Algorithm   string            `json:"algorithm,omitempty"`
+	KeyID       string            `json:"key_id,omitempty"`
+	Signature   string            `json:"signature,omitempty"`
+}
+
+// ReportPublicKey represents public key of reports signatures
+type ReportPublicKey struct {
+	KeyID     string
+	Algorithm string
+	PublicKey []byte
 }
@@ -16,3 +16,29 @@ type WalletOperation struct {
 	Amount     decimal.Decimal `json:"amount"`
 	CreatedAt  time.Time       `json:"created_at"`
 }
+
+// OperationSummary represents aggregated wallet operations for the period.
+// Dimensions which are not used for grouping are NULL.
+type OperationSummary struct {
+	Period    time.Time
+	Operation sql.NullString
+	WalletID  sql.NullInt32
+	Currency  sql.NullString
+	Count     int
+	Total     decimal.Decimal
+}
+
+// AccountStatement represents balances and turnover of the wallet for the period.
+// From and To are zero when the period is not limited.
+type AccountStatement struct {
+	WalletID       int
+	Currency       string
+	From           time.Time
+	To             time.Time
+	OpeningBalance decimal.Decimal
+	ClosingBalance decimal.Decimal
+	CreditCount    int
+	CreditTotal    decimal.Decimal
+	DebitCount     int
+	DebitTotal     decimal.Decimal
+}
@@ -0,0 +1,15 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxEvent represents domain event written in the transaction of its change
+type OutboxEvent struct {
+	ID        int64
+	Type      string
+	Payload   json.RawMessage
+	Attempts  int
+	CreatedAt time.Time
+}
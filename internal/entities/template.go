@@ -0,0 +1,14 @@
+package entities
+
+import "time"
+
+// ReportTemplate represents user-defined layout of operations report: text/template
+// sections written before operations, for each operation and after operations
+type ReportTemplate struct {
+	Name      string
+	Header    string
+	Row       string
+	Footer    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -8,4 +8,12 @@ type Wallet struct {
 	UserID   int
 	Balance  decimal.Decimal
 	Currency string
+	Frozen   bool // balance of frozen wallet can not be changed
+}
+
+// BalanceDiscrepancy represents wallet whose balance differs from balance restored from its operations
+type BalanceDiscrepancy struct {
+	WalletID int
+	Balance  decimal.Decimal
+	Ledger   decimal.Decimal
 }
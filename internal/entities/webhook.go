@@ -0,0 +1,63 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Types of wallet events sent to webhooks
+const (
+	EventUserCreated       = "user.created"
+	EventWalletEnrolled    = "wallet.enrolled"
+	EventTransferCompleted = "transfer.completed"
+)
+
+// WebhookEventTypes are event types available for subscriptions
+var WebhookEventTypes = []string{EventUserCreated, EventWalletEnrolled, EventTransferCompleted}
+
+// Statuses of webhook deliveries
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookSubscription represents receiver of events
+type WebhookSubscription struct {
+	ID         int
+	URL        string
+	EventTypes []string
+	Secret     string
+	Active     bool
+	CreatedAt  time.Time
+}
+
+// WebhookEvent represents event with its data
+type WebhookEvent struct {
+	ID        int
+	Type      string
+	Payload   json.RawMessage
+	CreatedAt time.Time
+}
+
+// WebhookDelivery represents delivery of event to subscription
+type WebhookDelivery struct {
+	ID           int
+	Subscription WebhookSubscription
+	Event        WebhookEvent
+	Status       string
+	Attempts     int
+}
+
+// WebhookAttempt represents record of delivery log
+type WebhookAttempt struct {
+	ID         int
+	DeliveryID int
+	EventID    int
+	EventType  string
+	Attempt    int
+	StatusCode int // zero when response is not received
+	Error      string
+	Duration   time.Duration
+	CreatedAt  time.Time
+}
@@ -0,0 +1,114 @@
+package events
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// WalletChannel is the channel of Postgres notifications about changed wallets, payload is wallet id
+const WalletChannel = "wallet_events"
+
+// Broker wakes subscribers of wallets when wallets change; subscribers read changes themselves,
+// so wake-ups which are not received yet are merged
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[int]map[chan struct{}]struct{}
+}
+
+// NewBroker returns broker without subscribers
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[int]map[chan struct{}]struct{}),
+	}
+}
+
+// Subscribe returns channel of wallet's wake-ups and function which cancels subscription
+func (b *Broker) Subscribe(walletID int) (<-chan struct{}, func()) {
+	wakeUps := make(chan struct{}, 1)
+	b.mu.Lock()
+	if b.subscribers[walletID] == nil {
+		b.subscribers[walletID] = make(map[chan struct{}]struct{})
+	}
+	b.subscribers[walletID][wakeUps] = struct{}{}
+	b.mu.Unlock()
+
+	return wakeUps, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers[walletID], wakeUps)
+		if len(b.subscribers[walletID]) == 0 {
+			delete(b.subscribers, walletID)
+		}
+	}
+}
+
+// Notify wakes subscribers of wallet
+func (b *Broker) Notify(walletID int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for wakeUps := range b.subscribers[walletID] {
+		wakeUp(wakeUps)
+	}
+}
+
+// NotifyAll wakes all subscribers, e.g. when notifications may be lost
+func (b *Broker) NotifyAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, walletSubscribers := range b.subscribers {
+		for wakeUps := range walletSubscribers {
+			wakeUp(wakeUps)
+		}
+	}
+}
+
+func wakeUp(wakeUps chan struct{}) {
+	select {
+	case wakeUps <- struct{}{}:
+	default:
+	}
+}
+
+// Run wakes subscribers by notifications until context is cancelled or channel is closed;
+// nil notification is sent by pq.Listener after reconnection, when notifications may be lost
+func (b *Broker) Run(ctx context.Context, notifications <-chan *pq.Notification) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notification, ok := <-notifications:
+			if !ok {
+				return
+			}
+			if notification == nil {
+				b.NotifyAll()
+				continue
+			}
+			walletID, convErr := strconv.Atoi(notification.Extra)
+			if convErr != nil {
+				log.Printf("[ERROR] Invalid payload of %s notification: %q", notification.Channel, notification.Extra)
+				continue
+			}
+			b.Notify(walletID)
+		}
+	}
+}
+
+// Listen returns listener of wallet notifications with own connection, it reconnects after failures
+func Listen(connString string) (*pq.Listener, error) {
+	listener := pq.NewListener(connString, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("[ERROR] Error of %s listener: %s", WalletChannel, err)
+		}
+	})
+	if listenErr := listener.Listen(WalletChannel); listenErr != nil {
+		_ = listener.Close()
+		return nil, listenErr
+	}
+	return listener, nil
+}
@@ -0,0 +1,66 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// received reports whether wake-up is waiting in channel
+func received(wakeUps <-chan struct{}) bool {
+	select {
+	case <-wakeUps:
+		return true
+	default:
+		return false
+	}
+}
+
+// Test subscribers are woken by notifications of their wallets
+func TestBrokerRun(t *testing.T) {
+	broker := NewBroker()
+	first, cancelFirst := broker.Subscribe(1)
+	second, cancelSecond := broker.Subscribe(2)
+	defer cancelSecond()
+
+	notifications := make(chan *pq.Notification)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		broker.Run(ctx, notifications)
+		close(done)
+	}()
+
+	// Wake-ups are merged until subscriber reads them
+	notifications <- &pq.Notification{Channel: WalletChannel, Extra: "1"}
+	notifications <- &pq.Notification{Channel: WalletChannel, Extra: "1"}
+	notifications <- &pq.Notification{Channel: WalletChannel, Extra: "invalid"}
+	// Unbuffered channel guarantees previous notifications are handled
+	notifications <- &pq.Notification{Channel: WalletChannel, Extra: "3"}
+	if !received(first) || received(first) || received(second) {
+		t.Error("Expected one wake-up of the first wallet")
+	}
+
+	// Reconnection wakes everyone
+	notifications <- nil
+	notifications <- &pq.Notification{Channel: WalletChannel, Extra: "3"}
+	if !received(first) || !received(second) {
+		t.Error("Expected wake-ups of all wallets")
+	}
+
+	cancelFirst()
+	notifications <- &pq.Notification{Channel: WalletChannel, Extra: "1"}
+	notifications <- &pq.Notification{Channel: WalletChannel, Extra: "3"}
+	if received(first) {
+		t.Error("Unexpected wake-up of cancelled subscription")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("Broker is not stopped")
+	}
+}
@@ -0,0 +1,286 @@
+// Package migrator applies embedded SQL migrations. Version is kept in schema_migrations table in format of
+// golang-migrate, so databases migrated by `migrate` tool continue from their version.
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// lockID is the key of advisory lock which serializes runs of migrations
+const lockID int64 = 7249019385512301
+
+var fileNameRegexp = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration represents pair of SQL scripts changing schema to its version and back
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Step represents migration applied in given direction
+type Step struct {
+	Migration *Migration
+	Up        bool
+}
+
+// MigrationStatus represents migration with its state in the database
+type MigrationStatus struct {
+	*Migration
+	Applied bool
+}
+
+// Status represents version of the database and states of known migrations
+type Status struct {
+	Version    int64
+	Dirty      bool
+	Migrations []*MigrationStatus
+}
+
+// Migrator applies migrations to the database
+type Migrator struct {
+	db         *sql.DB
+	migrations []*Migration
+}
+
+// NewMigrator returns migrator of migrations found in root of fsys
+func NewMigrator(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, loadErr := Load(fsys)
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Load returns migrations sorted by versions; each version requires both up and down scripts
+func Load(fsys fs.FS) ([]*Migration, error) {
+	fileNames, globErr := fs.Glob(fsys, "*.sql")
+	if globErr != nil {
+		return nil, fmt.Errorf("[MIGRATIONS_LOAD]: %s", globErr)
+	}
+	byVersion := map[int64]*Migration{}
+	for _, fileName := range fileNames {
+		matches := fileNameRegexp.FindStringSubmatch(path.Base(fileName))
+		if matches == nil {
+			return nil, fmt.Errorf("[MIGRATIONS_LOAD]: %s is not named as <version>_<name>.(up|down).sql", fileName)
+		}
+		version, _ := strconv.ParseInt(matches[1], 10, 64)
+		content, readErr := fs.ReadFile(fsys, fileName)
+		if readErr != nil {
+			return nil, fmt.Errorf("[MIGRATIONS_LOAD]: %s", readErr)
+		}
+		migration, isKnown := byVersion[version]
+		if !isKnown {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != matches[2] {
+			return nil, fmt.Errorf("[MIGRATIONS_LOAD]: version %d has different names %s and %s", version, migration.Name, matches[2])
+		}
+		if matches[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("[MIGRATIONS_LOAD]: version %d requires up and down scripts", migration.Version)
+		}
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up applies all pending migrations
+func (m *Migrator) Up(ctx context.Context) ([]*Step, error) {
+	return m.run(ctx, func(current int64) int64 {
+		if len(m.migrations) == 0 {
+			return current
+		}
+		return m.migrations[len(m.migrations)-1].Version
+	})
+}
+
+// Down reverts the last applied migration
+func (m *Migrator) Down(ctx context.Context) ([]*Step, error) {
+	return m.run(ctx, func(current int64) int64 {
+		target := int64(0)
+		for _, migration := range m.migrations {
+			if migration.Version < current {
+				target = migration.Version
+			}
+		}
+		return target
+	})
+}
+
+// To applies or reverts migrations until the database has given version; version 0 reverts all migrations
+func (m *Migrator) To(ctx context.Context, version int64) ([]*Step, error) {
+	if version != 0 && m.find(version) == nil {
+		return nil, fmt.Errorf("[MIGRATE]: unknown version %d", version)
+	}
+	return m.run(ctx, func(int64) int64 { return version })
+}
+
+// Status returns version of the database and states of migrations
+func (m *Migrator) Status(ctx context.Context) (*Status, error) {
+	var exists bool
+	if existsErr := m.db.QueryRowContext(ctx, "select to_regclass('schema_migrations') is not null").Scan(&exists); existsErr != nil {
+		return nil, fmt.Errorf("[MIGRATE_STATUS]: %s", existsErr)
+	}
+	status := &Status{}
+	if exists {
+		version, dirty, versionErr := currentVersion(ctx, m.db)
+		if versionErr != nil {
+			return nil, versionErr
+		}
+		status.Version, status.Dirty = version, dirty
+	}
+	for _, migration := range m.migrations {
+		status.Migrations = append(status.Migrations, &MigrationStatus{Migration: migration, Applied: migration.Version <= status.Version})
+	}
+	return status, nil
+}
+
+// run applies migrations between current version and target one under advisory lock;
+// it returns steps applied before an error as well
+func (m *Migrator) run(ctx context.Context, target func(current int64) int64) ([]*Step, error) {
+	conn, connErr := m.db.Conn(ctx)
+	if connErr != nil {
+		return nil, fmt.Errorf("[MIGRATE]: %s", connErr)
+	}
+	defer conn.Close()
+
+	// Concurrent runs wait for the lock and find migrations applied
+	if _, lockErr := conn.ExecContext(ctx, "select pg_advisory_lock($1)", lockID); lockErr != nil {
+		return nil, fmt.Errorf("[MIGRATE_LOCK]: %s", lockErr)
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.Background(), "select pg_advisory_unlock($1)", lockID)
+	}()
+
+	if _, createErr := conn.ExecContext(ctx, "create table if not exists schema_migrations (version bigint not null primary key, dirty boolean not null)"); createErr != nil {
+		return nil, fmt.Errorf("[MIGRATE]: error of versions table creation: %s", createErr)
+	}
+	current, dirty, versionErr := currentVersion(ctx, conn)
+	if versionErr != nil {
+		return nil, versionErr
+	}
+	if dirty {
+		return nil, fmt.Errorf("[MIGRATE]: database is dirty at version %d, it should be fixed manually", current)
+	}
+	if current != 0 && m.find(current) == nil {
+		return nil, fmt.Errorf("[MIGRATE]: database has unknown version %d", current)
+	}
+	steps := m.plan(current, target(current))
+	for idx, step := range steps {
+		if applyErr := apply(ctx, conn, step, m.versionAfter(step)); applyErr != nil {
+			return steps[:idx], applyErr
+		}
+	}
+	return steps, nil
+}
+
+// plan returns steps from current version to target one
+func (m *Migrator) plan(current, target int64) []*Step {
+	steps := []*Step{}
+	if target >= current {
+		for _, migration := range m.migrations {
+			if migration.Version > current && migration.Version <= target {
+				steps = append(steps, &Step{Migration: migration, Up: true})
+			}
+		}
+		return steps
+	}
+	for idx := len(m.migrations) - 1; idx >= 0; idx-- {
+		if migration := m.migrations[idx]; migration.Version <= current && migration.Version > target {
+			steps = append(steps, &Step{Migration: migration, Up: false})
+		}
+	}
+	return steps
+}
+
+// versionAfter returns version of the database after the step; it is 0 when the first migration is reverted
+func (m *Migrator) versionAfter(step *Step) int64 {
+	if step.Up {
+		return step.Migration.Version
+	}
+	previous := int64(0)
+	for _, migration := range m.migrations {
+		if migration.Version < step.Migration.Version {
+			previous = migration.Version
+		}
+	}
+	return previous
+}
+
+func (m *Migrator) find(version int64) *Migration {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration
+		}
+	}
+	return nil
+}
+
+// queryRower represents connection or database reading the version
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// currentVersion returns version of the database; it is 0 when no migration is applied
+func currentVersion(ctx context.Context, db queryRower) (int64, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+	scanErr := db.QueryRowContext(ctx, "select version, dirty from schema_migrations limit 1").Scan(&version, &dirty)
+	if scanErr == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if scanErr != nil {
+		return 0, false, fmt.Errorf("[MIGRATE_VERSION]: %s", scanErr)
+	}
+	return version, dirty, nil
+}
+
+// apply runs script of the step and stores new version in one transaction
+func apply(ctx context.Context, conn *sql.Conn, step *Step, version int64) error {
+	script := step.Migration.Down
+	if step.Up {
+		script = step.Migration.Up
+	}
+	tx, txErr := conn.BeginTx(ctx, nil)
+	if txErr != nil {
+		return fmt.Errorf("[MIGRATE]: transaction initialization error: %s", txErr)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, execErr := tx.ExecContext(ctx, script); execErr != nil {
+		return fmt.Errorf("[MIGRATE]: error of %d_%s: %s", step.Migration.Version, step.Migration.Name, execErr)
+	}
+	if _, deleteErr := tx.ExecContext(ctx, "delete from schema_migrations"); deleteErr != nil {
+		return fmt.Errorf("[MIGRATE]: error of version update: %s", deleteErr)
+	}
+	if version != 0 {
+		if _, insertErr := tx.ExecContext(ctx, "insert into schema_migrations (version, dirty) values ($1, false)", version); insertErr != nil {
+			return fmt.Errorf("[MIGRATE]: error of version update: %s", insertErr)
+		}
+	}
+	if commitErr := tx.Commit(); commitErr != nil {
+		return fmt.Errorf("[MIGRATE]: error of %d_%s commit: %s", step.Migration.Version, step.Migration.Name, commitErr)
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package migrator
+
+import (
+	"billing_system_test_task/migrations"
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var testFS = fstest.MapFS{
+	"1_create_users.up.sql":     {Data: []byte("create table users (id int);")},
+	"1_create_users.down.sql":   {Data: []byte("drop table users;")},
+	"2_create_wallets.up.sql":   {Data: []byte("create table wallets (id int);")},
+	"2_create_wallets.down.sql": {Data: []byte("drop table wallets;")},
+	"3_add_frozen.up.sql":       {Data: []byte("alter table wallets add column frozen boolean;")},
+	"3_add_frozen.down.sql":     {Data: []byte("alter table wallets drop column frozen;")},
+}
+
+// Test migrations are loaded in order of versions
+func TestLoad(t *testing.T) {
+	loaded, loadErr := Load(testFS)
+	if loadErr != nil {
+		t.Fatalf("Unexpected error: %s", loadErr)
+	}
+	if len(loaded) != 3 || loaded[0].Version != 1 || loaded[2].Name != "add_frozen" || loaded[1].Down != "drop table wallets;" {
+		t.Errorf("Unexpected migrations: %v", loaded)
+	}
+
+	invalid := []fstest.MapFS{
+		{"1_create_users.up.sql": {Data: []byte("create table users (id int);")}},
+		{"create_users.up.sql": {Data: []byte("create table users (id int);")}},
+		{"1_create_users.up.sql": {Data: []byte("select 1;")}, "1_users.down.sql": {Data: []byte("select 1;")}},
+	}
+	for _, fsys := range invalid {
+		if _, loadErr = Load(fsys); loadErr == nil {
+			t.Errorf("Expected error of %v", fsys)
+		}
+	}
+
+	// Embedded migrations of the application are valid
+	if _, loadErr = Load(migrations.FS); loadErr != nil {
+		t.Errorf("Unexpected error of embedded migrations: %s", loadErr)
+	}
+}
+
+// expectRun expects locking and reading of the current version
+func expectRun(mock sqlmock.Sqlmock, rows *sqlmock.Rows) {
+	mock.ExpectExec(regexp.QuoteMeta("select pg_advisory_lock($1)")).WithArgs(lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("create table if not exists schema_migrations")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("select version, dirty from schema_migrations limit 1")).WillReturnRows(rows)
+}
+
+// expectStep expects script and version update in transaction
+func expectStep(mock sqlmock.Sqlmock, script string, version int64) {
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(script)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("delete from schema_migrations")).WillReturnResult(sqlmock.NewResult(0, 1))
+	if version != 0 {
+		mock.ExpectExec(regexp.QuoteMeta("insert into schema_migrations (version, dirty) values ($1, false)")).
+			WithArgs(version).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+}
+
+func expectUnlock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta("select pg_advisory_unlock($1)")).WithArgs(lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+// Test migrations are applied and reverted under advisory lock
+func TestMigrator(t *testing.T) {
+	versionRows := func(version int64, dirty bool) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"version", "dirty"}).AddRow(version, dirty)
+	}
+	tests := []struct {
+		name   string
+		run    func(m *Migrator) ([]*Step, error)
+		expect func(mock sqlmock.Sqlmock)
+		steps  []string
+		err    bool
+	}{
+		{
+			name: "Pending migrations are applied",
+			run:  func(m *Migrator) ([]*Step, error) { return m.Up(context.Background()) },
+			expect: func(mock sqlmock.Sqlmock) {
+				expectRun(mock, versionRows(1, false))
+				expectStep(mock, "create table wallets (id int);", 2)
+				expectStep(mock, "alter table wallets add column frozen boolean;", 3)
+				expectUnlock(mock)
+			},
+			steps: []string{"up 2", "up 3"},
+		},
+		{
+			name: "Empty database is migrated",
+			run:  func(m *Migrator) ([]*Step, error) { return m.To(context.Background(), 1) },
+			expect: func(mock sqlmock.Sqlmock) {
+				expectRun(mock, sqlmock.NewRows([]string{"version", "dirty"}))
+				expectStep(mock, "create table users (id int);", 1)
+				expectUnlock(mock)
+			},
+			steps: []string{"up 1"},
+		},
+		{
+			name: "Last migration is reverted",
+			run:  func(m *Migrator) ([]*Step, error) { return m.Down(context.Background()) },
+			expect: func(mock sqlmock.Sqlmock) {
+				expectRun(mock, versionRows(3, false))
+				expectStep(mock, "alter table wallets drop column frozen;", 2)
+				expectUnlock(mock)
+			},
+			steps: []string{"down 3"},
+		},
+		{
+			name: "All migrations are reverted",
+			run:  func(m *Migrator) ([]*Step, error) { return m.To(context.Background(), 0) },
+			expect: func(mock sqlmock.Sqlmock) {
+				expectRun(mock, versionRows(2, false))
+				expectStep(mock, "drop table wallets;", 1)
+				expectStep(mock, "drop table users;", 0)
+				expectUnlock(mock)
+			},
+			steps: []string{"down 2", "down 1"},
+		},
+		{
+			name: "Failed migration is rolled back",
+			run:  func(m *Migrator) ([]*Step, error) { return m.Up(context.Background()) },
+			expect: func(mock sqlmock.Sqlmock) {
+				expectRun(mock, versionRows(1, false))
+				expectStep(mock, "create table wallets (id int);", 2)
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("alter table wallets add column frozen boolean;")).WillReturnError(fmt.Errorf("column frozen already exists"))
+				mock.ExpectRollback()
+				expectUnlock(mock)
+			},
+			steps: []string{"up 2"},
+			err:   true,
+		},
+		{
+			name: "Dirty database is not migrated",
+			run:  func(m *Migrator) ([]*Step, error) { return m.Up(context.Background()) },
+			expect: func(mock sqlmock.Sqlmock) {
+				expectRun(mock, versionRows(2, true))
+				expectUnlock(mock)
+			},
+			steps: []string{},
+			err:   true,
+		},
+		{
+			name:   "Unknown version",
+			run:    func(m *Migrator) ([]*Step, error) { return m.To(context.Background(), 5) },
+			expect: func(mock sqlmock.Sqlmock) {},
+			steps:  []string{},
+			err:    true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("cant create mock: %s", err)
+			}
+			defer db.Close()
+			tc.expect(mock)
+
+			migrator, newErr := NewMigrator(db, testFS)
+			if newErr != nil {
+				t.Fatalf("Unexpected error: %s", newErr)
+			}
+			steps, runErr := tc.run(migrator)
+			if (runErr != nil) != tc.err {
+				t.Errorf("Unexpected error: %v", runErr)
+			}
+			applied := []string{}
+			for _, step := range steps {
+				direction := "down"
+				if step.Up {
+					direction = "up"
+				}
+				applied = append(applied, fmt.Sprintf("%s %d", direction, step.Migration.Version))
+			}
+			if fmt.Sprint(applied) != fmt.Sprint(tc.steps) {
+				t.Errorf("Expected steps %v, got %v", tc.steps, applied)
+			}
+			if expectationsErr := mock.ExpectationsWereMet(); expectationsErr != nil {
+				t.Errorf("Unfulfilled expectations: %s", expectationsErr)
+			}
+		})
+	}
+}
+
+// Test status of new and migrated databases
+func TestMigratorStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+	migrator, _ := NewMigrator(db, testFS)
+
+	mock.ExpectQuery(regexp.QuoteMeta("select to_regclass('schema_migrations') is not null")).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	status, statusErr := migrator.Status(context.Background())
+	if statusErr != nil || status.Version != 0 || len(status.Migrations) != 3 || status.Migrations[0].Applied {
+		t.Errorf("Unexpected status: %v (%v)", status, statusErr)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("select to_regclass('schema_migrations') is not null")).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta("select version, dirty from schema_migrations limit 1")).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(2, false))
+	status, statusErr = migrator.Status(context.Background())
+	if statusErr != nil || status.Version != 2 || !status.Migrations[1].Applied || status.Migrations[2].Applied {
+		t.Errorf("Unexpected status: %v (%v)", status, statusErr)
+	}
+
+	if expectationsErr := mock.ExpectationsWereMet(); expectationsErr != nil {
+		t.Errorf("Unfulfilled expectations: %s", expectationsErr)
+	}
+}
@@ -0,0 +1,131 @@
+package outbox
+
+import (
+	trx "billing_system_test_task/internal/adapters/tx"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/repositories"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Retries of events which are not published: attempts are delayed by BaseBackoff doubled after each failure up to MaxBackoff
+const (
+	BaseBackoff = 5 * time.Second
+	MaxBackoff  = 10 * time.Minute
+)
+
+// batchSize is the number of events claimed by one dispatch
+const batchSize = 100
+
+// Publisher represents receiver of committed events; it must tolerate repeated events,
+// because event is published again when marking it as sent fails
+type Publisher interface {
+	Publish(ctx context.Context, event *entities.OutboxEvent) error
+}
+
+// TxPublisher represents publisher which stores events in the database; the dispatcher stores event
+// in the transaction which marks it as sent, so the event is stored once
+type TxPublisher interface {
+	PublishTx(ctx context.Context, tx trx.Tx, event *entities.OutboxEvent) error
+}
+
+// Dispatcher publishes outbox events at least once; events are claimed in order of writing,
+// but failed events are retried after backoff, so they may be published after newer events
+type Dispatcher struct {
+	outboxRepo repositories.OutboxManager
+	txManager  trx.TxBeginner
+	publishers []Publisher
+}
+
+// NewDispatcher returns dispatcher which publishes each event to all publishers
+func NewDispatcher(outboxRepo repositories.OutboxManager, txManager trx.TxBeginner, publishers ...Publisher) *Dispatcher {
+	return &Dispatcher{
+		outboxRepo: outboxRepo,
+		txManager:  txManager,
+		publishers: publishers,
+	}
+}
+
+// Dispatch claims batch of pending events and hands them to publishers; no transaction is held while events
+// are published, so long publishing does not hold back snapshots of readers. Failed events are retried with backoff.
+// Returns the number of handled events.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	events, claimErr := d.outboxRepo.ClaimPending(ctx, batchSize)
+	if claimErr != nil {
+		return 0, claimErr
+	}
+	for _, event := range events {
+		if publishErr := d.publish(ctx, event); publishErr != nil {
+			log.Printf("[ERROR] Error of outbox event %d publishing: %s", event.ID, publishErr)
+			if failedErr := d.outboxRepo.MarkFailed(ctx, event.ID, publishErr.Error(), Backoff(event.Attempts+1)); failedErr != nil {
+				return 0, failedErr
+			}
+		}
+	}
+	return len(events), nil
+}
+
+// publish hands event to publishers; stored publishers and marking of the event as sent share short transaction
+func (d *Dispatcher) publish(ctx context.Context, event *entities.OutboxEvent) error {
+	for _, publisher := range d.publishers {
+		if _, isStored := publisher.(TxPublisher); isStored {
+			continue
+		}
+		if publishErr := publisher.Publish(ctx, event); publishErr != nil {
+			return fmt.Errorf("%T: %s", publisher, publishErr)
+		}
+	}
+
+	tx, txErr := d.txManager.BeginTrx(ctx, nil)
+	if txErr != nil {
+		return txErr
+	}
+	defer func() { trx.RollbackTx(tx, txErr) }()
+
+	for _, publisher := range d.publishers {
+		if txPublisher, isStored := publisher.(TxPublisher); isStored {
+			if txErr = txPublisher.PublishTx(ctx, tx, event); txErr != nil {
+				return fmt.Errorf("%T: %s", publisher, txErr)
+			}
+		}
+	}
+	if txErr = d.outboxRepo.WithTx(tx).MarkSent(ctx, event.ID); txErr != nil {
+		return txErr
+	}
+	txErr = tx.Commit()
+	return txErr
+}
+
+// Run dispatches events until context is cancelled; batches are dispatched without waiting while events are pending
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		handled, dispatchErr := d.Dispatch(ctx)
+		if dispatchErr != nil {
+			log.Printf("[ERROR] Error of outbox dispatching: %s", dispatchErr)
+		}
+		if handled == batchSize && ctx.Err() == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Backoff returns delay before the attempt following given number of failures
+func Backoff(failures int) time.Duration {
+	delay := BaseBackoff
+	for i := 1; i < failures && delay < MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > MaxBackoff {
+		return MaxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,144 @@
+package outbox
+
+import (
+	"billing_system_test_task/internal/adapters/tx"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/repositories"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+)
+
+// failingPublisher rejects events of one type
+type failingPublisher struct {
+	eventType string
+}
+
+func (fp failingPublisher) Publish(ctx context.Context, event *entities.OutboxEvent) error {
+	if event.Type == fp.eventType {
+		return fmt.Errorf("broker is unavailable")
+	}
+	return nil
+}
+
+// storedPublisher records events stored in transaction of dispatcher
+type storedPublisher struct {
+	events []int64
+}
+
+func (sp *storedPublisher) Publish(ctx context.Context, event *entities.OutboxEvent) error {
+	return fmt.Errorf("event must be stored in transaction")
+}
+
+func (sp *storedPublisher) PublishTx(ctx context.Context, t tx.Tx, event *entities.OutboxEvent) error {
+	sp.events = append(sp.events, event.ID)
+	return nil
+}
+
+// Test claimed events are published and each one is marked in its own transaction
+func TestDispatcherDispatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	txManager := tx.NewMockTxBeginner(ctrl)
+	txMock := tx.NewMockTx(ctrl)
+	outboxRepo := repositories.NewMockOutboxManager(ctrl)
+	memory := NewMemoryPublisher()
+	stored := &storedPublisher{}
+
+	outboxRepo.EXPECT().ClaimPending(ctx, batchSize).Return([]*entities.OutboxEvent{
+		{ID: 1, Type: entities.EventUserCreated, Payload: []byte(`{"user_id":1}`)},
+		{ID: 2, Type: entities.EventTransferCompleted, Payload: []byte(`{"amount":"10"}`), Attempts: 2},
+	}, nil)
+	txManager.EXPECT().BeginTrx(ctx, nil).Return(txMock, nil)
+	outboxRepo.EXPECT().WithTx(txMock).Return(outboxRepo)
+	outboxRepo.EXPECT().MarkSent(ctx, int64(1)).Return(nil)
+	txMock.EXPECT().Commit().Return(nil)
+	outboxRepo.EXPECT().MarkFailed(ctx, int64(2), "outbox.failingPublisher: broker is unavailable", Backoff(3)).Return(nil)
+
+	dispatcher := NewDispatcher(outboxRepo, txManager, memory, stored, failingPublisher{eventType: entities.EventTransferCompleted})
+	handled, err := dispatcher.Dispatch(ctx)
+	if err != nil || handled != 2 {
+		t.Fatalf("Unexpected result: %d (%v)", handled, err)
+	}
+	if events := memory.Events(); len(events) != 2 || events[0].ID != 1 || events[1].ID != 2 {
+		t.Errorf("Wrong published events: %v", events)
+	}
+	if len(stored.events) != 1 || stored.events[0] != 1 {
+		t.Errorf("Wrong stored events: %v", stored.events)
+	}
+}
+
+// Test event is retried when its transaction fails
+func TestDispatcherDispatchRollback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	txManager := tx.NewMockTxBeginner(ctrl)
+	txMock := tx.NewMockTx(ctrl)
+	outboxRepo := repositories.NewMockOutboxManager(ctrl)
+
+	outboxRepo.EXPECT().ClaimPending(ctx, batchSize).Return([]*entities.OutboxEvent{{ID: 1, Type: entities.EventUserCreated}}, nil)
+	txManager.EXPECT().BeginTrx(ctx, nil).Return(txMock, nil)
+	outboxRepo.EXPECT().WithTx(txMock).Return(outboxRepo)
+	outboxRepo.EXPECT().MarkSent(ctx, int64(1)).Return(fmt.Errorf("[OUTBOX_SENT]: connection error"))
+	txMock.EXPECT().Rollback().Return(nil)
+	outboxRepo.EXPECT().MarkFailed(ctx, int64(1), "[OUTBOX_SENT]: connection error", Backoff(1)).Return(fmt.Errorf("[OUTBOX_FAILED]: connection error"))
+
+	if _, err := NewDispatcher(outboxRepo, txManager, NewMemoryPublisher()).Dispatch(ctx); err == nil {
+		t.Error("Expected error of marking")
+	}
+}
+
+// Test delays between attempts
+func TestBackoff(t *testing.T) {
+	testCases := []struct {
+		failures int
+		delay    time.Duration
+	}{
+		{failures: 1, delay: 5 * time.Second},
+		{failures: 3, delay: 20 * time.Second},
+		{failures: 30, delay: MaxBackoff},
+	}
+	for _, testCase := range testCases {
+		if delay := Backoff(testCase.failures); delay != testCase.delay {
+			t.Errorf("Failures %d: expected %s, got %s", testCase.failures, testCase.delay, delay)
+		}
+	}
+}
+
+// Test events are posted to HTTP receiver
+func TestHTTPPublisherPublish(t *testing.T) {
+	status := http.StatusNoContent
+	var message Message
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &message)
+		if r.Header.Get("Event-ID") != "7" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	publisher := NewHTTPPublisher(receiver.URL, time.Second)
+	event := &entities.OutboxEvent{ID: 7, Type: entities.EventWalletEnrolled, Payload: []byte(`{"wallet_id":1}`)}
+	if err := publisher.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if message.ID != 7 || message.Type != entities.EventWalletEnrolled || string(message.Data) != `{"wallet_id":1}` {
+		t.Errorf("Wrong message: %+v", message)
+	}
+	status = http.StatusBadGateway
+	if err := publisher.Publish(context.Background(), event); err == nil {
+		t.Error("Expected error of status")
+	}
+}
@@ -0,0 +1,110 @@
+package outbox
+
+import (
+	"billing_system_test_task/internal/entities"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Message is JSON representation of event for external publishers
+type Message struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+func newMessage(event *entities.OutboxEvent) Message {
+	return Message{ID: event.ID, Type: event.Type, CreatedAt: event.CreatedAt.UTC(), Data: event.Payload}
+}
+
+// LogPublisher writes events to log
+type LogPublisher struct {
+	logger *log.Logger
+}
+
+// NewLogPublisher returns publisher writing to logger
+func NewLogPublisher(logger *log.Logger) *LogPublisher {
+	return &LogPublisher{logger: logger}
+}
+
+// Publish writes event to log
+func (lp *LogPublisher) Publish(ctx context.Context, event *entities.OutboxEvent) error {
+	lp.logger.Printf("[EVENT] %d %s %s", event.ID, event.Type, event.Payload)
+	return nil
+}
+
+// HTTPPublisher posts events to URL as JSON messages
+type HTTPPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPPublisher returns publisher posting to URL with request timeout
+func NewHTTPPublisher(url string, timeout time.Duration) *HTTPPublisher {
+	return &HTTPPublisher{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Publish posts event; responses other than 2xx are errors. Receiver can deduplicate events by Event-ID header.
+func (hp *HTTPPublisher) Publish(ctx context.Context, event *entities.OutboxEvent) error {
+	body, marshalErr := json.Marshal(newMessage(event))
+	if marshalErr != nil {
+		return fmt.Errorf("error of event marshalling: %s", marshalErr)
+	}
+	request, requestErr := http.NewRequestWithContext(ctx, http.MethodPost, hp.url, bytes.NewReader(body))
+	if requestErr != nil {
+		return fmt.Errorf("error of event request: %s", requestErr)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Event-ID", strconv.FormatInt(event.ID, 10))
+
+	response, sendErr := hp.client.Do(request)
+	if sendErr != nil {
+		return fmt.Errorf("error of event sending: %s", sendErr)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// MemoryPublisher keeps published events in memory, it is used in tests
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []entities.OutboxEvent
+}
+
+// NewMemoryPublisher returns empty in-memory publisher
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+// Publish appends event
+func (mp *MemoryPublisher) Publish(ctx context.Context, event *entities.OutboxEvent) error {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	mp.events = append(mp.events, *event)
+	return nil
+}
+
+// Events returns copy of published events
+func (mp *MemoryPublisher) Events() []entities.OutboxEvent {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	return append([]entities.OutboxEvent(nil), mp.events...)
+}
@@ -0,0 +1,155 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	stageLabels = []string{"pipeline", "stage"}
+
+	itemsInTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "billing_pipeline_stage_items_in_total",
+		Help: "Number of items received by the pipeline stage",
+	}, stageLabels)
+	itemsOutTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "billing_pipeline_stage_items_out_total",
+		Help: "Number of items sent by the pipeline stage",
+	}, stageLabels)
+	sendBlockedSeconds = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "billing_pipeline_stage_send_blocked_seconds_total",
+		Help: "Time the pipeline stage was blocked on sending to the next stage",
+	}, stageLabels)
+	receiveBlockedSeconds = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "billing_pipeline_stage_receive_blocked_seconds_total",
+		Help: "Time the pipeline stage was blocked on receiving from the previous stage",
+	}, stageLabels)
+	stageDurationSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "billing_pipeline_stage_duration_seconds",
+		Help:    "Duration of the pipeline stage",
+		Buckets: prometheus.ExponentialBuckets(0.001, 4, 10),
+	}, stageLabels)
+)
+
+// StageStats represents metrics collected for the single stage
+type StageStats struct {
+	Pipeline       string
+	Stage          string
+	ItemsIn        int64
+	ItemsOut       int64
+	SendBlocked    time.Duration
+	ReceiveBlocked time.Duration
+	Duration       time.Duration
+}
+
+// stageStats collects metrics of the stage while pipeline is running
+type stageStats struct {
+	itemsIn        int64
+	itemsOut       int64
+	sendBlocked    int64
+	receiveBlocked int64
+
+	mu       sync.Mutex
+	running  int
+	started  time.Time
+	finished time.Time
+}
+
+func (s *stageStats) begin() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running == 0 && s.started.IsZero() {
+		s.started = time.Now()
+	}
+	s.running++
+}
+
+// end marks finish of the stage's workers and returns true if all of them are finished
+func (s *stageStats) end() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running--
+	s.finished = time.Now()
+	return s.running == 0
+}
+
+func (s *stageStats) snapshot(pipeline, stage string) StageStats {
+	s.mu.Lock()
+	duration := s.finished.Sub(s.started)
+	s.mu.Unlock()
+	if duration < 0 {
+		duration = 0
+	}
+	return StageStats{
+		Pipeline:       pipeline,
+		Stage:          stage,
+		ItemsIn:        atomic.LoadInt64(&s.itemsIn),
+		ItemsOut:       atomic.LoadInt64(&s.itemsOut),
+		SendBlocked:    time.Duration(atomic.LoadInt64(&s.sendBlocked)),
+		ReceiveBlocked: time.Duration(atomic.LoadInt64(&s.receiveBlocked)),
+		Duration:       duration,
+	}
+}
+
+// publish exports collected metrics to prometheus
+func (st StageStats) publish() {
+	itemsInTotal.WithLabelValues(st.Pipeline, st.Stage).Add(float64(st.ItemsIn))
+	itemsOutTotal.WithLabelValues(st.Pipeline, st.Stage).Add(float64(st.ItemsOut))
+	sendBlockedSeconds.WithLabelValues(st.Pipeline, st.Stage).Add(st.SendBlocked.Seconds())
+	receiveBlockedSeconds.WithLabelValues(st.Pipeline, st.Stage).Add(st.ReceiveBlocked.Seconds())
+	stageDurationSeconds.WithLabelValues(st.Pipeline, st.Stage).Observe(st.Duration.Seconds())
+}
+
+// send writes item to the channel and records time the stage was blocked on it
+func send[T any](ctx context.Context, stats *stageStats, out chan<- T, item T) error {
+	select {
+	case out <- item:
+		atomic.AddInt64(&stats.itemsOut, 1)
+		return nil
+	default:
+	}
+	start := time.Now()
+	err := Send(ctx, out, item)
+	atomic.AddInt64(&stats.sendBlocked, int64(time.Since(start)))
+	if err == nil {
+		atomic.AddInt64(&stats.itemsOut, 1)
+	}
+	return err
+}
+
+// receive calls handle for every item of the channel and records time the stage was waiting for items
+func receive[T any](ctx context.Context, stats *stageStats, in <-chan T, handle func(item T) error) error {
+	for {
+		var (
+			item T
+			ok   bool
+		)
+		select {
+		case item, ok = <-in:
+		default:
+			start := time.Now()
+			select {
+			case <-ctx.Done():
+				atomic.AddInt64(&stats.receiveBlocked, int64(time.Since(start)))
+				return ctx.Err()
+			case item, ok = <-in:
+				atomic.AddInt64(&stats.receiveBlocked, int64(time.Since(start)))
+			}
+		}
+		if !ok {
+			return nil
+		}
+		atomic.AddInt64(&stats.itemsIn, 1)
+		if err := handle(item); err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
@@ -1,28 +1,258 @@
 package pipeline
 
-import "sync"
+import (
+	"context"
+	"fmt"
+	"sync"
+)
 
-// Pipe represents actions for element of pipeline
-type Pipe interface {
-	Call(in, out chan interface{})
+const (
+	defaultBuffer  = 1
+	defaultWorkers = 1
+)
+
+// Pipeline represents set of stages, connected with typed channels.
+// All stages share the same context, which is cancelled on the first error.
+type Pipeline struct {
+	name   string
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+	err    error
+
+	statsMu    sync.Mutex
+	stats      map[string]*stageStats
+	stageNames []string
+}
+
+// StageOption represents option for particular stage
+type StageOption func(cfg *stageConfig)
+
+type stageConfig struct {
+	buffer  int
+	workers int
+}
+
+// Buffer sets size of the stage's output channel buffer
+func Buffer(size int) StageOption {
+	return func(cfg *stageConfig) {
+		if size >= 0 {
+			cfg.buffer = size
+		}
+	}
+}
+
+// Workers sets number of goroutines, which process stage's input concurrently
+func Workers(n int) StageOption {
+	return func(cfg *stageConfig) {
+		if n > 0 {
+			cfg.workers = n
+		}
+	}
+}
+
+// New returns new pipeline instance bound to given context
+func New(ctx context.Context, name string) *Pipeline {
+	pctx, cancel := context.WithCancel(ctx)
+	return &Pipeline{
+		name:   name,
+		ctx:    pctx,
+		cancel: cancel,
+		stats:  make(map[string]*stageStats),
+	}
+}
+
+// Name returns pipeline's name
+func (p *Pipeline) Name() string {
+	return p.name
+}
+
+// Context returns pipeline's context; it is done when any of the stages fails
+func (p *Pipeline) Context() context.Context {
+	return p.ctx
 }
 
-// ExecutePipeline executes list of pipes
-func ExecutePipeline(pipes ...Pipe) {
-	in := make(chan interface{}, 1)
-	wg := &sync.WaitGroup{}
-	wg.Add(len(pipes))
-	for idx := range pipes {
-		out := make(chan interface{}, 1)
-		j := pipes[idx]
-		go executeJob(in, out, j, wg)
-		in = out
+// Wait blocks until all stages are finished and returns the first occurred error
+func (p *Pipeline) Wait() error {
+	p.wg.Wait()
+	p.cancel()
+	return p.err
+}
+
+// Stats returns metrics of all the stages in order of their creation.
+// Values are complete only after Wait is returned.
+func (p *Pipeline) Stats() []StageStats {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	result := make([]StageStats, 0, len(p.stageNames))
+	for _, name := range p.stageNames {
+		result = append(result, p.stats[name].snapshot(p.name, name))
+	}
+	return result
+}
+
+// stageStats returns metrics collector for the stage; stages with the same name share it
+func (p *Pipeline) stageStats(stage string) *stageStats {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	stats, ok := p.stats[stage]
+	if !ok {
+		stats = &stageStats{}
+		p.stats[stage] = stats
+		p.stageNames = append(p.stageNames, stage)
 	}
-	wg.Wait()
+	return stats
 }
 
-func executeJob(in, out chan interface{}, j Pipe, wg *sync.WaitGroup) {
-	defer wg.Done()
-	j.Call(in, out)
-	close(out)
+// fail stores the first error and stops all the stages
+func (p *Pipeline) fail(stage string, err error) {
+	p.once.Do(func() {
+		p.err = fmt.Errorf("pipeline '%s', stage '%s': %w", p.name, stage, err)
+		p.cancel()
+	})
+}
+
+// start runs given number of workers for the stage and calls done after all of them are finished
+func (p *Pipeline) start(stage string, stats *stageStats, workers int, work func(ctx context.Context, worker int) error, done func()) {
+	stageWg := &sync.WaitGroup{}
+	stageWg.Add(workers)
+	p.wg.Add(workers + 1)
+	stats.begin()
+	for i := 0; i < workers; i++ {
+		go func(worker int) {
+			defer p.wg.Done()
+			defer stageWg.Done()
+			if err := work(p.ctx, worker); err != nil {
+				p.fail(stage, err)
+			}
+		}(i)
+	}
+	go func() {
+		defer p.wg.Done()
+		stageWg.Wait()
+		if done != nil {
+			done()
+		}
+		if stats.end() {
+			stats.snapshot(p.name, stage).publish()
+		}
+	}()
+}
+
+// Source starts stage, which produces items via emit function
+func Source[T any](p *Pipeline, name string, produce func(ctx context.Context, emit func(item T) error) error, opts ...StageOption) <-chan T {
+	cfg := newStageConfig(opts)
+	stats := p.stageStats(name)
+	out := make(chan T, cfg.buffer)
+	p.start(name, stats, 1, func(ctx context.Context, worker int) error {
+		return produce(ctx, func(item T) error {
+			return send(ctx, stats, out, item)
+		})
+	}, func() { close(out) })
+	return out
+}
+
+// Map starts stage, which transforms every received item.
+// With Workers option items are processed concurrently (fan-out) and merged
+// back into the single output channel (fan-in), so their order is not preserved.
+func Map[In, Out any](p *Pipeline, name string, in <-chan In, transform func(ctx context.Context, item In) (Out, error), opts ...StageOption) <-chan Out {
+	cfg := newStageConfig(opts)
+	stats := p.stageStats(name)
+	out := make(chan Out, cfg.buffer)
+	p.start(name, stats, cfg.workers, func(ctx context.Context, worker int) error {
+		return receive(ctx, stats, in, func(item In) error {
+			result, err := transform(ctx, item)
+			if err != nil {
+				return err
+			}
+			return send(ctx, stats, out, result)
+		})
+	}, func() { close(out) })
+	return out
+}
+
+// Sink starts final stage, which consumes every received item
+func Sink[T any](p *Pipeline, name string, in <-chan T, consume func(ctx context.Context, item T) error, opts ...StageOption) {
+	cfg := newStageConfig(opts)
+	stats := p.stageStats(name)
+	p.start(name, stats, cfg.workers, func(ctx context.Context, worker int) error {
+		return receive(ctx, stats, in, func(item T) error {
+			return consume(ctx, item)
+		})
+	}, nil)
+}
+
+// FanOut distributes received items between n output channels
+func FanOut[T any](p *Pipeline, name string, in <-chan T, n int, opts ...StageOption) []<-chan T {
+	cfg := newStageConfig(opts)
+	stats := p.stageStats(name)
+	outs := make([]chan T, n)
+	result := make([]<-chan T, n)
+	for i := 0; i < n; i++ {
+		outs[i] = make(chan T, cfg.buffer)
+		result[i] = outs[i]
+	}
+	p.start(name, stats, n, func(ctx context.Context, worker int) error {
+		return receive(ctx, stats, in, func(item T) error {
+			return send(ctx, stats, outs[worker], item)
+		})
+	}, func() {
+		for _, out := range outs {
+			close(out)
+		}
+	})
+	return result
+}
+
+// FanIn merges given channels into the single output channel
+func FanIn[T any](p *Pipeline, name string, ins []<-chan T, opts ...StageOption) <-chan T {
+	cfg := newStageConfig(opts)
+	stats := p.stageStats(name)
+	out := make(chan T, cfg.buffer)
+	p.start(name, stats, len(ins), func(ctx context.Context, worker int) error {
+		return receive(ctx, stats, ins[worker], func(item T) error {
+			return send(ctx, stats, out, item)
+		})
+	}, func() { close(out) })
+	return out
+}
+
+// Send writes item to the channel or returns error if context is done
+func Send[T any](ctx context.Context, out chan<- T, item T) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case out <- item:
+		return nil
+	}
+}
+
+// Receive calls handle for every item of the channel until it is closed,
+// handle returns error or context is done
+func Receive[T any](ctx context.Context, in <-chan T, handle func(item T) error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case item, ok := <-in:
+			if !ok {
+				return nil
+			}
+			if err := handle(item); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func newStageConfig(opts []StageOption) *stageConfig {
+	cfg := &stageConfig{
+		buffer:  defaultBuffer,
+		workers: defaultWorkers,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
 }
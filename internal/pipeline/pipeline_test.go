@@ -1,36 +1,328 @@
 package pipeline
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
-var entries uint32
+func numbers(n int) func(ctx context.Context, emit func(item int) error) error {
+	return func(ctx context.Context, emit func(item int) error) error {
+		for i := 1; i <= n; i++ {
+			if err := emit(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
 
-type Step1 struct{}
+// Test pipeline running
+func TestPipeline(t *testing.T) {
+	var (
+		entries uint32
+		sum     int64
+	)
+	p := New(context.Background(), "test")
+	in := Source(p, "numbers", numbers(3))
+	doubled := Map(p, "double", in, func(ctx context.Context, item int) (int, error) {
+		return item * 2, nil
+	})
+	Sink(p, "sum", doubled, func(ctx context.Context, item int) error {
+		atomic.AddUint32(&entries, 1)
+		atomic.AddInt64(&sum, int64(item))
+		return nil
+	})
 
-func (s1 Step1) Call(in, out chan interface{}) {
-	out <- 1
-	out <- 2
-	out <- 3
+	if err := p.Wait(); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if entries != 3 {
+		t.Errorf("Execution of pipeline has failed")
+	}
+	if sum != 12 {
+		t.Errorf("Wrong sum. Expected 12, got %d", sum)
+	}
 }
 
-type Step2 struct{}
+// Test pipeline with different types between stages
+func TestPipelineTypedStages(t *testing.T) {
+	var result []string
+	p := New(context.Background(), "test")
+	in := Source(p, "numbers", numbers(3))
+	strs := Map(p, "format", in, func(ctx context.Context, item int) (string, error) {
+		return fmt.Sprintf("#%d", item), nil
+	})
+	Sink(p, "collect", strs, func(ctx context.Context, item string) error {
+		result = append(result, item)
+		return nil
+	})
 
-func (s2 Step2) Call(in, out chan interface{}) {
-	for range in {
-		atomic.AddUint32(&entries, 1)
+	if err := p.Wait(); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if strings.Join(result, ",") != "#1,#2,#3" {
+		t.Errorf("Wrong result: %v", result)
 	}
 }
 
-// Test pipeline running
-func TestPipeline(t *testing.T) {
-	pipeline := []Pipe{
-		Step1{},
-		Step2{},
+// Test stopping of all stages on the first error
+func TestPipelineStopsOnFirstError(t *testing.T) {
+	var produced int32
+	p := New(context.Background(), "test")
+	in := Source(p, "infinite", func(ctx context.Context, emit func(item int) error) error {
+		for i := 0; ; i++ {
+			atomic.AddInt32(&produced, 1)
+			if err := emit(i); err != nil {
+				return err
+			}
+		}
+	})
+	failed := Map(p, "fail", in, func(ctx context.Context, item int) (int, error) {
+		if item == 5 {
+			return 0, fmt.Errorf("marshall error")
+		}
+		return item, nil
+	})
+	Sink(p, "discard", failed, func(ctx context.Context, item int) error {
+		return nil
+	})
+
+	err := p.Wait()
+	if err == nil {
+		t.Fatal("Expected error, got nil")
 	}
-	ExecutePipeline(pipeline...)
-	if entries != 3 {
-		t.Errorf("Execution of pipeline has failed")
+	if !strings.Contains(err.Error(), "marshall error") || !strings.Contains(err.Error(), "'fail'") {
+		t.Errorf("Wrong error message: %s", err)
+	}
+	if errors.Is(err, context.Canceled) {
+		t.Errorf("Expected original error, got cancellation: %s", err)
+	}
+}
+
+// Test that several failing stages do not block each other
+func TestPipelineSeveralFailedStages(t *testing.T) {
+	p := New(context.Background(), "test")
+	in := Source(p, "numbers", numbers(100))
+	mapped := Map(p, "fail", in, func(ctx context.Context, item int) (int, error) {
+		return 0, fmt.Errorf("map error")
+	}, Workers(4))
+	Sink(p, "fail", mapped, func(ctx context.Context, item int) error {
+		return fmt.Errorf("sink error")
+	}, Workers(2))
+
+	done := make(chan error)
+	go func() {
+		done <- p.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Expected error, got nil")
+		}
+	case <-time.After(time.Second):
+		t.Error("Pipeline was blocked")
+	}
+}
+
+// Test cancellation of the parent context
+func TestPipelineParentContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := New(ctx, "test")
+	in := Source(p, "infinite", func(ctx context.Context, emit func(item int) error) error {
+		for {
+			if err := emit(1); err != nil {
+				return err
+			}
+		}
+	})
+	Sink(p, "slow", in, func(ctx context.Context, item int) error {
+		cancel()
+		return nil
+	})
+
+	err := p.Wait()
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled error, got %v", err)
+	}
+}
+
+// Test concurrent processing with Workers option
+func TestPipelineWorkers(t *testing.T) {
+	var (
+		active    int32
+		maxActive int32
+		count     int32
+	)
+	p := New(context.Background(), "test")
+	in := Source(p, "numbers", numbers(20), Buffer(20))
+	mapped := Map(p, "slow", in, func(ctx context.Context, item int) (int, error) {
+		current := atomic.AddInt32(&active, 1)
+		for {
+			max := atomic.LoadInt32(&maxActive)
+			if current <= max || atomic.CompareAndSwapInt32(&maxActive, max, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		return item, nil
+	}, Workers(4))
+	Sink(p, "count", mapped, func(ctx context.Context, item int) error {
+		atomic.AddInt32(&count, 1)
+		return nil
+	})
+
+	if err := p.Wait(); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if count != 20 {
+		t.Errorf("Expected 20 items, got %d", count)
+	}
+	if maxActive < 2 {
+		t.Errorf("Expected concurrent processing, got %d active workers", maxActive)
+	}
+}
+
+// Test FanOut and FanIn stages
+func TestPipelineFanOutFanIn(t *testing.T) {
+	var result []int
+	p := New(context.Background(), "test")
+	in := Source(p, "numbers", numbers(10))
+	branches := FanOut(p, "split", in, 3)
+	if len(branches) != 3 {
+		t.Fatalf("Expected 3 branches, got %d", len(branches))
+	}
+	squared := make([]<-chan int, len(branches))
+	for idx, branch := range branches {
+		squared[idx] = Map(p, "square", branch, func(ctx context.Context, item int) (int, error) {
+			return item * item, nil
+		})
+	}
+	merged := FanIn(p, "merge", squared, Buffer(10))
+	Sink(p, "collect", merged, func(ctx context.Context, item int) error {
+		result = append(result, item)
+		return nil
+	})
+
+	if err := p.Wait(); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	sort.Ints(result)
+	expected := []int{1, 4, 9, 16, 25, 36, 49, 64, 81, 100}
+	if fmt.Sprint(result) != fmt.Sprint(expected) {
+		t.Errorf("Wrong result. Expected %v, got %v", expected, result)
+	}
+}
+
+// Test per-stage buffer size
+func TestPipelineBuffer(t *testing.T) {
+	p := New(context.Background(), "test")
+	out := Source(p, "numbers", numbers(5), Buffer(5))
+	if cap(out) != 5 {
+		t.Errorf("Expected buffer size 5, got %d", cap(out))
+	}
+	Sink(p, "discard", out, func(ctx context.Context, item int) error {
+		return nil
+	})
+	if err := p.Wait(); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
+
+// Benchmark pipeline with three stages
+func BenchmarkPipeline(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		p := New(context.Background(), "bench")
+		in := Source(p, "numbers", numbers(100))
+		mapped := Map(p, "double", in, func(ctx context.Context, item int) (int, error) {
+			return item * 2, nil
+		})
+		Sink(p, "discard", mapped, func(ctx context.Context, item int) error {
+			return nil
+		})
+		_ = p.Wait()
+	}
+}
+
+// Test collecting of stage metrics
+func TestPipelineStats(t *testing.T) {
+	p := New(context.Background(), "stats_test")
+	in := Source(p, "numbers", numbers(5))
+	mapped := Map(p, "double", in, func(ctx context.Context, item int) (int, error) {
+		return item * 2, nil
+	})
+	Sink(p, "slow", mapped, func(ctx context.Context, item int) error {
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+	if err := p.Wait(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	stats := p.Stats()
+	if len(stats) != 3 {
+		t.Fatalf("Expected stats for 3 stages, got %d", len(stats))
+	}
+	expected := []struct {
+		stage    string
+		itemsIn  int64
+		itemsOut int64
+	}{
+		{"numbers", 0, 5},
+		{"double", 5, 5},
+		{"slow", 5, 0},
+	}
+	for idx, e := range expected {
+		st := stats[idx]
+		if st.Pipeline != "stats_test" || st.Stage != e.stage {
+			t.Errorf("Wrong labels. Expected stats_test/%s, got %s/%s", e.stage, st.Pipeline, st.Stage)
+		}
+		if st.ItemsIn != e.itemsIn || st.ItemsOut != e.itemsOut {
+			t.Errorf("[%s] Wrong items count. Expected %d/%d, got %d/%d", e.stage, e.itemsIn, e.itemsOut, st.ItemsIn, st.ItemsOut)
+		}
+		if st.Duration <= 0 {
+			t.Errorf("[%s] Expected positive duration", e.stage)
+		}
+	}
+	if stats[2].Duration < 5*time.Millisecond {
+		t.Errorf("Expected sink duration at least 5ms, got %s", stats[2].Duration)
+	}
+	if stats[0].SendBlocked+stats[1].SendBlocked == 0 {
+		t.Errorf("Expected upstream stages to be blocked by the slow sink")
+	}
+}
+
+// Test exporting of stage metrics to prometheus
+func TestPipelinePrometheusMetrics(t *testing.T) {
+	outBefore := testutil.ToFloat64(itemsOutTotal.WithLabelValues("prometheus_test", "numbers"))
+	inBefore := testutil.ToFloat64(itemsInTotal.WithLabelValues("prometheus_test", "discard"))
+	p := New(context.Background(), "prometheus_test")
+	numbersCh := Source(p, "numbers", numbers(7))
+	Sink(p, "discard", numbersCh, func(ctx context.Context, item int) error {
+		return nil
+	})
+	if err := p.Wait(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	out := testutil.ToFloat64(itemsOutTotal.WithLabelValues("prometheus_test", "numbers")) - outBefore
+	if out != 7 {
+		t.Errorf("Expected 7 items out, got %f", out)
+	}
+	in := testutil.ToFloat64(itemsInTotal.WithLabelValues("prometheus_test", "discard")) - inBefore
+	if in != 7 {
+		t.Errorf("Expected 7 items in, got %f", in)
+	}
+	if testutil.CollectAndCount(stageDurationSeconds) == 0 {
+		t.Errorf("Expected stage duration to be observed")
 	}
 }
@@ -0,0 +1,44 @@
+package ratelimit
+
+import "sync"
+
+// ConcurrencyLimiter limits number of simultaneous tasks in total and per client; zero limit is unlimited
+type ConcurrencyLimiter struct {
+	mu        sync.Mutex
+	total     int
+	perClient int
+	running   int
+	clients   map[string]int
+}
+
+// NewConcurrencyLimiter returns limiter of simultaneous tasks
+func NewConcurrencyLimiter(total, perClient int) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		total:     total,
+		perClient: perClient,
+		clients:   make(map[string]int),
+	}
+}
+
+// TryAcquire starts task of the client when limits allow it; release must be called when the task is finished
+func (cl *ConcurrencyLimiter) TryAcquire(client string) (release func(), acquired bool) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if cl.total > 0 && cl.running >= cl.total || cl.perClient > 0 && cl.clients[client] >= cl.perClient {
+		return nil, false
+	}
+	cl.running++
+	cl.clients[client]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cl.mu.Lock()
+			defer cl.mu.Unlock()
+			cl.running--
+			if cl.clients[client]--; cl.clients[client] == 0 {
+				delete(cl.clients, client)
+			}
+		})
+	}, true
+}
@@ -0,0 +1,121 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRoute is the name of limit applied to routes without own limit
+const DefaultRoute = "default"
+
+// AddressRoute is the name of limit of one client's address applied before authentication
+const AddressRoute = "address"
+
+// Limit represents token bucket: Burst tokens at most, refilled with Rate tokens per second
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Decision represents result of taking a token from bucket
+type Decision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // zero when request is allowed
+	Reset      time.Duration // time until bucket is full
+}
+
+// Store defines contracts for token buckets storage
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (*Decision, error)
+}
+
+// newDecision returns decision for bucket with given tokens left after taking
+func newDecision(tokens float64, allowed bool, limit Limit) *Decision {
+	decision := &Decision{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     secondsDuration((float64(limit.Burst) - tokens) / limit.Rate),
+	}
+	if !allowed {
+		decision.RetryAfter = secondsDuration((1 - tokens) / limit.Rate)
+	}
+	return decision
+}
+
+func secondsDuration(seconds float64) time.Duration {
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
+
+// RefillTime returns time after which idle bucket of any of the limits is full
+func RefillTime(limits map[string]Limit) time.Duration {
+	var longest time.Duration
+	for _, limit := range limits {
+		if refill := secondsDuration(float64(limit.Burst) / limit.Rate); refill > longest {
+			longest = refill
+		}
+	}
+	return longest
+}
+
+// ParseLimits reads limits of routes given as "ROUTE=rate/unit[:burst],..." where unit is s, m or h,
+// e.g. "default=20/s:40,address=50/s:100,TRANSFER_FUNDS=5/s,OPERATIONS_LIST=6/m:2". Burst is equal to rate by default.
+func ParseLimits(value string) (map[string]Limit, error) {
+	limits := make(map[string]Limit)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid rate limit %q", item)
+		}
+		limit, parseErr := parseLimit(parts[1])
+		if parseErr != nil {
+			return nil, fmt.Errorf("invalid rate limit of %s: %s", parts[0], parseErr)
+		}
+		limits[parts[0]] = limit
+	}
+	return limits, nil
+}
+
+func parseLimit(value string) (Limit, error) {
+	var (
+		burst    = -1
+		burstErr error
+	)
+	if idx := strings.Index(value, ":"); idx >= 0 {
+		burst, burstErr = strconv.Atoi(value[idx+1:])
+		if burstErr != nil || burst < 1 {
+			return Limit{}, fmt.Errorf("burst must be positive integer")
+		}
+		value = value[:idx]
+	}
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 {
+		return Limit{}, fmt.Errorf("rate must be given as number/unit")
+	}
+	count, countErr := strconv.ParseFloat(parts[0], 64)
+	if countErr != nil || count <= 0 {
+		return Limit{}, fmt.Errorf("rate must be positive number")
+	}
+	units := map[string]time.Duration{"s": time.Second, "m": time.Minute, "h": time.Hour}
+	unit, exists := units[parts[1]]
+	if !exists {
+		return Limit{}, fmt.Errorf("unknown unit %q", parts[1])
+	}
+	if burst < 0 {
+		burst = int(math.Max(1, math.Ceil(count)))
+	}
+	return Limit{Rate: count / unit.Seconds(), Burst: burst}, nil
+}
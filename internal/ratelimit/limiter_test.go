@@ -0,0 +1,155 @@
+package ratelimit
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// Test parsing of routes' limits
+func TestParseLimits(t *testing.T) {
+	limits, err := ParseLimits("default=20/s:40, TRANSFER_FUNDS=5/s ,OPERATIONS_LIST=6/m:2,REPORTS_SUMMARY=0.5/s")
+	expected := map[string]Limit{
+		DefaultRoute:      {Rate: 20, Burst: 40},
+		"TRANSFER_FUNDS":  {Rate: 5, Burst: 5},
+		"OPERATIONS_LIST": {Rate: 0.1, Burst: 2},
+		"REPORTS_SUMMARY": {Rate: 0.5, Burst: 1},
+	}
+	if err != nil || !reflect.DeepEqual(limits, expected) {
+		t.Errorf("Expected limits %v, got %v (%v)", expected, limits, err)
+	}
+	if limits, err := ParseLimits(""); err != nil || len(limits) != 0 {
+		t.Errorf("Expected no limits, got %v (%v)", limits, err)
+	}
+	for _, value := range []string{"TRANSFER_FUNDS", "=5/s", "A=5", "A=-1/s", "A=5/d", "A=5/s:0", "A=5/s:x"} {
+		if _, err := ParseLimits(value); err == nil {
+			t.Errorf("[%s] Expected error, got nil", value)
+		}
+	}
+}
+
+// Test token buckets of memory store
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, time.November, 15, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := Limit{Rate: 2, Burst: 3}
+
+	for i := 2; i >= 0; i-- {
+		decision, _ := store.Take(ctx, "client", limit)
+		if !decision.Allowed || decision.Remaining != i || decision.Limit != 3 {
+			t.Fatalf("Expected allowed request with %d remaining, got %+v", i, decision)
+		}
+	}
+	decision, _ := store.Take(ctx, "client", limit)
+	if decision.Allowed || decision.RetryAfter != 500*time.Millisecond || decision.Reset != 1500*time.Millisecond {
+		t.Errorf("Expected denied request, got %+v", decision)
+	}
+	if other, _ := store.Take(ctx, "other", limit); !other.Allowed {
+		t.Errorf("Buckets of clients are not separated")
+	}
+
+	now = now.Add(time.Second)
+	decision, _ = store.Take(ctx, "client", limit)
+	if !decision.Allowed || decision.Remaining != 1 {
+		t.Errorf("Expected refilled bucket, got %+v", decision)
+	}
+	now = now.Add(time.Hour)
+	if decision, _ = store.Take(ctx, "client", limit); decision.Remaining != 2 {
+		t.Errorf("Bucket is refilled over burst: %+v", decision)
+	}
+}
+
+// Test memory store removes full buckets when there are too many of them
+func TestMemoryStoreCleanup(t *testing.T) {
+	store := NewMemoryStore()
+	for i := 0; i < maxIdleBuckets; i++ {
+		store.buckets[string(rune(i))] = &bucket{tokens: 1, updated: time.Now().Add(-time.Minute), limit: Limit{Rate: 1, Burst: 1}}
+	}
+	store.buckets["busy"] = &bucket{tokens: 0, updated: time.Now(), limit: Limit{Rate: 0.001, Burst: 1}}
+	if _, err := store.Take(context.Background(), "new", Limit{Rate: 1, Burst: 1}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(store.buckets) != 2 || store.buckets["busy"] == nil {
+		t.Errorf("Expected busy and new buckets, got %d buckets", len(store.buckets))
+	}
+}
+
+// Test token buckets of Postgres store
+func TestPostgresStore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+	store := NewPostgresStore(db)
+	limit := Limit{Rate: 1, Burst: 5}
+
+	mock.ExpectQuery(regexp.QuoteMeta("insert into rate_limit_buckets as b (key, tokens, allowed, updated_at) values ($1, $2::float8 - 1, true, now()) on conflict (key) do update")).
+		WithArgs("TRANSFER_FUNDS|principal:7", 5, 1.0).
+		WillReturnRows(sqlmock.NewRows([]string{"tokens", "allowed"}).AddRow(3.5, true))
+	decision, takeErr := store.Take(context.Background(), "TRANSFER_FUNDS|principal:7", limit)
+	if takeErr != nil || !decision.Allowed || decision.Remaining != 3 || decision.Reset != 1500*time.Millisecond {
+		t.Errorf("Wrong decision: %+v (%v)", decision, takeErr)
+	}
+
+	mock.ExpectQuery("insert into rate_limit_buckets").
+		WillReturnRows(sqlmock.NewRows([]string{"tokens", "allowed"}).AddRow(0.25, false))
+	decision, takeErr = store.Take(context.Background(), "TRANSFER_FUNDS|principal:7", limit)
+	if takeErr != nil || decision.Allowed || decision.RetryAfter != 750*time.Millisecond {
+		t.Errorf("Wrong decision: %+v (%v)", decision, takeErr)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta("delete from rate_limit_buckets where updated_at < now() - make_interval(secs => $1)")).
+		WithArgs(5.0).WillReturnResult(sqlmock.NewResult(0, 3))
+	purged, purgeErr := store.Purge(context.Background(), RefillTime(map[string]Limit{"default": limit, "TRANSFER_FUNDS": {Rate: 2, Burst: 4}}))
+	if purgeErr != nil || purged != 3 {
+		t.Errorf("Unexpected purge result: %d (%v)", purged, purgeErr)
+	}
+
+	if expectationsErr := mock.ExpectationsWereMet(); expectationsErr != nil {
+		t.Errorf("Unfulfilled expectations: %s", expectationsErr)
+	}
+}
+
+// Test limits of simultaneous tasks
+func TestConcurrencyLimiter(t *testing.T) {
+	limiter := NewConcurrencyLimiter(3, 2)
+	releaseFirst, acquired := limiter.TryAcquire("a")
+	if !acquired {
+		t.Fatal("Expected acquired task")
+	}
+	if _, acquired = limiter.TryAcquire("a"); !acquired {
+		t.Fatal("Expected acquired task")
+	}
+	if _, acquired = limiter.TryAcquire("a"); acquired {
+		t.Error("Limit of client is exceeded")
+	}
+	if _, acquired = limiter.TryAcquire("b"); !acquired {
+		t.Fatal("Expected acquired task")
+	}
+	if _, acquired = limiter.TryAcquire("c"); acquired {
+		t.Error("Total limit is exceeded")
+	}
+
+	releaseFirst()
+	releaseFirst()
+	if _, acquired = limiter.TryAcquire("c"); !acquired {
+		t.Error("Released task is not freed")
+	}
+	if _, acquired = limiter.TryAcquire("c"); acquired {
+		t.Error("Task is released twice")
+	}
+
+	unlimited := NewConcurrencyLimiter(0, 0)
+	for i := 0; i < 100; i++ {
+		if _, acquired := unlimited.TryAcquire("a"); !acquired {
+			t.Fatal("Expected acquired task")
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// maxIdleBuckets is the number of buckets after which full buckets are removed
+const maxIdleBuckets = 10000
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   Limit
+}
+
+// MemoryStore implements Store interface with buckets of this instance
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// NewMemoryStore returns in-memory token buckets
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Take refills bucket of the key and takes a token when it is available
+func (ms *MemoryStore) Take(ctx context.Context, key string, limit Limit) (*Decision, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	now := ms.now()
+	b, exists := ms.buckets[key]
+	if !exists {
+		if len(ms.buckets) >= maxIdleBuckets {
+			ms.removeFull(now)
+		}
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		ms.buckets[key] = b
+	}
+	b.limit = limit
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+		b.updated = now
+	}
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return newDecision(b.tokens, allowed, limit), nil
+}
+
+// removeFull removes buckets which would be refilled by now, they are equal to new ones
+func (ms *MemoryStore) removeFull(now time.Time) {
+	for key, b := range ms.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*b.limit.Rate >= float64(b.limit.Burst) {
+			delete(ms.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"billing_system_test_task/internal/adapters/tx"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// refilledTokens is the number of tokens of existing bucket refilled up to the burst by database clock,
+// so instances with different clocks share the same buckets
+const refilledTokens = "least($2::float8, b.tokens + greatest(0, extract(epoch from (now() - b.updated_at))) * $3::float8)"
+
+// PostgresStore implements Store interface with buckets shared by all instances
+type PostgresStore struct {
+	db tx.SQLQueryAdapter
+}
+
+// NewPostgresStore returns token buckets stored in rate_limit_buckets table
+func NewPostgresStore(db tx.SQLQueryAdapter) *PostgresStore {
+	return &PostgresStore{
+		db: db,
+	}
+}
+
+// Take refills bucket and takes a token in one statement, so concurrent requests of instances
+// are serialized by the row lock
+func (ps *PostgresStore) Take(ctx context.Context, key string, limit Limit) (*Decision, error) {
+	var (
+		tokens  float64
+		allowed bool
+	)
+	scanErr := ps.db.QueryRowContext(
+		ctx,
+		"insert into rate_limit_buckets as b (key, tokens, allowed, updated_at) values ($1, $2::float8 - 1, true, now()) "+
+			"on conflict (key) do update set "+
+			"tokens = case when "+refilledTokens+" >= 1 then "+refilledTokens+" - 1 else "+refilledTokens+" end, "+
+			"allowed = "+refilledTokens+" >= 1, "+
+			"updated_at = greatest(b.updated_at, now()) "+
+			"returning tokens, allowed",
+		key, limit.Burst, limit.Rate,
+	).Scan(&tokens, &allowed)
+	if scanErr != nil {
+		return nil, fmt.Errorf("[RATE_LIMIT]: %s", scanErr)
+	}
+	return newDecision(tokens, allowed, limit), nil
+}
+
+// Purge removes buckets which are not used longer than idle time and returns the number of removed buckets;
+// buckets idle longer than RefillTime of the limits are full, so they are equal to new ones
+func (ps *PostgresStore) Purge(ctx context.Context, idle time.Duration) (int64, error) {
+	result, execErr := ps.db.ExecContext(ctx, "delete from rate_limit_buckets where updated_at < now() - make_interval(secs => $1)", idle.Seconds())
+	if execErr != nil {
+		return 0, fmt.Errorf("[RATE_LIMIT_PURGE]: %s", execErr)
+	}
+	purged, _ := result.RowsAffected()
+	return purged, nil
+}
+
+// RunPurge purges idle buckets with given interval until context is cancelled
+func (ps *PostgresStore) RunPurge(ctx context.Context, interval, idle time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, purgeErr := ps.Purge(ctx, idle); purgeErr != nil {
+			log.Printf("[ERROR] Error of rate limit buckets purging: %s", purgeErr)
+		}
+	}
+}
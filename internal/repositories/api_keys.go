@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"billing_system_test_task/internal/adapters/tx"
+	"billing_system_test_task/internal/entities"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrAPIKeyNotFound is returned when API key does not exist or is revoked
+var ErrAPIKeyNotFound = errors.New("API key is not found")
+
+// APIKeysManager represents storage of hashed API keys
+type APIKeysManager interface {
+	Create(ctx context.Context, apiKey *entities.APIKey, keyHash string) (*entities.APIKey, error)
+	GetByHash(ctx context.Context, keyHash string) (*entities.APIKey, error)
+	Revoke(ctx context.Context, id int) error
+}
+
+// APIKeysService implements APIKeysManager interface
+type APIKeysService struct {
+	db tx.SQLQueryAdapter
+}
+
+// NewAPIKeysService returns API keys repository
+func NewAPIKeysService(db tx.SQLQueryAdapter) *APIKeysService {
+	return &APIKeysService{
+		db: db,
+	}
+}
+
+const apiKeyColumns = "id, name, prefix, user_id, scopes, created_at, revoked_at"
+
+// Create stores hash of new API key
+func (as *APIKeysService) Create(ctx context.Context, apiKey *entities.APIKey, keyHash string) (*entities.APIKey, error) {
+	userID := sql.NullInt32{Int32: int32(apiKey.UserID), Valid: apiKey.UserID != 0}
+	row := as.db.QueryRowContext(
+		ctx,
+		"insert into api_keys(name, prefix, key_hash, user_id, scopes) values($1, $2, $3, $4, $5) returning "+apiKeyColumns,
+		apiKey.Name, apiKey.Prefix, keyHash, userID, strings.Join(apiKey.Scopes, " "),
+	)
+	created, scanErr := scanAPIKey(row)
+	if scanErr != nil {
+		return nil, fmt.Errorf("[API_KEY_CREATE]: %s", scanErr)
+	}
+	return created, nil
+}
+
+// GetByHash returns active API key by hash
+func (as *APIKeysService) GetByHash(ctx context.Context, keyHash string) (*entities.APIKey, error) {
+	row := as.db.QueryRowContext(
+		ctx,
+		"select "+apiKeyColumns+" from api_keys where key_hash = $1 and revoked_at is null",
+		keyHash,
+	)
+	apiKey, scanErr := scanAPIKey(row)
+	if scanErr == sql.ErrNoRows {
+		return nil, ErrAPIKeyNotFound
+	}
+	if scanErr != nil {
+		return nil, fmt.Errorf("[API_KEY]: %s", scanErr)
+	}
+	return apiKey, nil
+}
+
+// Revoke marks API key as revoked; revoked keys are kept for audit
+func (as *APIKeysService) Revoke(ctx context.Context, id int) error {
+	result, updateErr := as.db.ExecContext(
+		ctx,
+		"update api_keys set revoked_at = current_timestamp where id = $1 and revoked_at is null",
+		id,
+	)
+	if updateErr != nil {
+		return fmt.Errorf("[API_KEY_REVOKE]: %s", updateErr)
+	}
+	if revoked, _ := result.RowsAffected(); revoked == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// scanAPIKey reads API key from row of query with apiKeyColumns
+func scanAPIKey(row *sql.Row) (*entities.APIKey, error) {
+	var (
+		apiKey entities.APIKey
+		userID sql.NullInt32
+		scopes string
+	)
+	scanErr := row.Scan(
+		&apiKey.ID,
+		&apiKey.Name,
+		&apiKey.Prefix,
+		&userID,
+		&scopes,
+		&apiKey.CreatedAt,
+		&apiKey.RevokedAt,
+	)
+	if scanErr != nil {
+		return nil, scanErr
+	}
+	apiKey.UserID = int(userID.Int32)
+	apiKey.Scopes = strings.Fields(scopes)
+	return &apiKey, nil
+}
@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repositories/api_keys.go
+
+// Package repositories is a generated GoMock package.
+package repositories
+
+import (
+	entities "billing_system_test_task/internal/entities"
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockAPIKeysManager is a mock of APIKeysManager interface
+type MockAPIKeysManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeysManagerMockRecorder
+}
+
+// MockAPIKeysManagerMockRecorder is the mock recorder for MockAPIKeysManager
+type MockAPIKeysManagerMockRecorder struct {
+	mock *MockAPIKeysManager
+}
+
+// NewMockAPIKeysManager creates a new mock instance
+func NewMockAPIKeysManager(ctrl *gomock.Controller) *MockAPIKeysManager {
+	mock := &MockAPIKeysManager{ctrl: ctrl}
+	mock.recorder = &MockAPIKeysManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAPIKeysManager) EXPECT() *MockAPIKeysManagerMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockAPIKeysManager) Create(ctx context.Context, apiKey *entities.APIKey, keyHash string) (*entities.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, apiKey, keyHash)
+	ret0, _ := ret[0].(*entities.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockAPIKeysManagerMockRecorder) Create(ctx, apiKey, keyHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAPIKeysManager)(nil).Create), ctx, apiKey, keyHash)
+}
+
+// GetByHash mocks base method
+func (m *MockAPIKeysManager) GetByHash(ctx context.Context, keyHash string) (*entities.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByHash", ctx, keyHash)
+	ret0, _ := ret[0].(*entities.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByHash indicates an expected call of GetByHash
+func (mr *MockAPIKeysManagerMockRecorder) GetByHash(ctx, keyHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHash", reflect.TypeOf((*MockAPIKeysManager)(nil).GetByHash), ctx, keyHash)
+}
+
+// Revoke mocks base method
+func (m *MockAPIKeysManager) Revoke(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke
+func (mr *MockAPIKeysManagerMockRecorder) Revoke(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockAPIKeysManager)(nil).Revoke), ctx, id)
+}
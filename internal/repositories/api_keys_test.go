@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"billing_system_test_task/internal/entities"
+	"context"
+	"database/sql"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var apiKeyRowColumns = []string{"id", "name", "prefix", "user_id", "scopes", "created_at", "revoked_at"}
+
+// Test storing, lookup and revocation of API keys
+func TestAPIKeysService(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2022, time.November, 1, 12, 0, 0, 0, time.UTC)
+	service := NewAPIKeysService(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("insert into api_keys(name, prefix, key_hash, user_id, scopes) values($1, $2, $3, $4, $5)")).
+		WithArgs("partner", "bk_abcde", "hash", sql.NullInt32{}, "admin read").
+		WillReturnRows(sqlmock.NewRows(apiKeyRowColumns).AddRow(1, "partner", "bk_abcde", nil, "admin read", now, nil))
+	created, createErr := service.Create(ctx, &entities.APIKey{Name: "partner", Prefix: "bk_abcde", Scopes: []string{"admin", "read"}}, "hash")
+	expected := &entities.APIKey{ID: 1, Name: "partner", Prefix: "bk_abcde", Scopes: []string{"admin", "read"}, CreatedAt: now}
+	if createErr != nil || !reflect.DeepEqual(created, expected) {
+		t.Errorf("Expected API key %+v, got %+v (%v)", expected, created, createErr)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("from api_keys where key_hash = $1 and revoked_at is null")).
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows(apiKeyRowColumns).AddRow(2, "user", "bk_12345", 7, "", now, nil))
+	found, getErr := service.GetByHash(ctx, "hash")
+	if getErr != nil || found.UserID != 7 || len(found.Scopes) != 0 {
+		t.Errorf("Wrong API key: %+v (%v)", found, getErr)
+	}
+
+	mock.ExpectQuery("from api_keys where key_hash").WillReturnRows(sqlmock.NewRows(apiKeyRowColumns))
+	if _, getErr := service.GetByHash(ctx, "unknown"); getErr != ErrAPIKeyNotFound {
+		t.Errorf("Expected not found error, got %v", getErr)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta("update api_keys set revoked_at = current_timestamp where id = $1 and revoked_at is null")).
+		WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	if revokeErr := service.Revoke(ctx, 1); revokeErr != nil {
+		t.Errorf("Unexpected error: %s", revokeErr)
+	}
+	mock.ExpectExec("update api_keys").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+	if revokeErr := service.Revoke(ctx, 1); revokeErr != ErrAPIKeyNotFound {
+		t.Errorf("Expected not found error, got %v", revokeErr)
+	}
+
+	if expectationsErr := mock.ExpectationsWereMet(); expectationsErr != nil {
+		t.Errorf("Unfulfilled expectations: %s", expectationsErr)
+	}
+}
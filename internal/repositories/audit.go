@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"billing_system_test_task/internal/adapters/tx"
+	"billing_system_test_task/internal/entities"
+	"context"
+	"fmt"
+)
+
+// AuditManager represents audit trail storage
+type AuditManager interface {
+	Record(ctx context.Context, event *entities.AuditEvent) error
+}
+
+// AuditService implements AuditManager interface
+type AuditService struct {
+	db tx.SQLQueryAdapter
+}
+
+// NewAuditService returns audit trail repository
+func NewAuditService(db tx.SQLQueryAdapter) *AuditService {
+	return &AuditService{
+		db: db,
+	}
+}
+
+// Record appends event to audit trail
+func (as *AuditService) Record(ctx context.Context, event *entities.AuditEvent) error {
+	_, insertErr := as.db.ExecContext(
+		ctx,
+		"insert into audit_events(subject, auth_method, action, permission, outcome, request_method, path, remote_addr) "+
+			"values($1, $2, $3, $4, $5, $6, $7, $8)",
+		event.Subject, event.AuthMethod, event.Action, event.Permission, event.Outcome,
+		event.RequestMethod, event.Path, event.RemoteAddr,
+	)
+	if insertErr != nil {
+		return fmt.Errorf("[AUDIT_RECORD]: %s", insertErr)
+	}
+	return nil
+}
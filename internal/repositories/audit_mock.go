@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repositories/audit.go
+
+// Package repositories is a generated GoMock package.
+package repositories
+
+import (
+	entities "billing_system_test_task/internal/entities"
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockAuditManager is a mock of AuditManager interface
+type MockAuditManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditManagerMockRecorder
+}
+
+// MockAuditManagerMockRecorder is the mock recorder for MockAuditManager
+type MockAuditManagerMockRecorder struct {
+	mock *MockAuditManager
+}
+
+// NewMockAuditManager creates a new mock instance
+func NewMockAuditManager(ctrl *gomock.Controller) *MockAuditManager {
+	mock := &MockAuditManager{ctrl: ctrl}
+	mock.recorder = &MockAuditManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAuditManager) EXPECT() *MockAuditManagerMockRecorder {
+	return m.recorder
+}
+
+// Record mocks base method
+func (m *MockAuditManager) Record(ctx context.Context, event *entities.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Record", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Record indicates an expected call of Record
+func (mr *MockAuditManagerMockRecorder) Record(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockAuditManager)(nil).Record), ctx, event)
+}
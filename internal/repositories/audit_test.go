@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"billing_system_test_task/internal/entities"
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// Test recording of audit events
+func TestAuditService(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	service := NewAuditService(db)
+	event := &entities.AuditEvent{
+		Subject:       "7",
+		AuthMethod:    entities.AuthMethodJWT,
+		Action:        "OPERATIONS_LIST",
+		Permission:    "operations:read",
+		Outcome:       entities.AuditOutcomeDenied,
+		RequestMethod: "GET",
+		Path:          "/api/operations/",
+		RemoteAddr:    "10.0.0.1:5000",
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta("insert into audit_events(subject, auth_method, action, permission, outcome, request_method, path, remote_addr)")).
+		WithArgs("7", "jwt", "OPERATIONS_LIST", "operations:read", "denied", "GET", "/api/operations/", "10.0.0.1:5000").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	if recordErr := service.Record(ctx, event); recordErr != nil {
+		t.Errorf("Unexpected error: %s", recordErr)
+	}
+	mock.ExpectExec("insert into audit_events").WillReturnError(fmt.Errorf("connection error"))
+	if recordErr := service.Record(ctx, event); recordErr == nil || recordErr.Error() != "[AUDIT_RECORD]: connection error" {
+		t.Errorf("Expected error, got %v", recordErr)
+	}
+
+	if expectationsErr := mock.ExpectationsWereMet(); expectationsErr != nil {
+		t.Errorf("Unfulfilled expectations: %s", expectationsErr)
+	}
+}
@@ -0,0 +1,173 @@
+package repositories
+
+import (
+	"billing_system_test_task/internal/adapters/tx"
+	"billing_system_test_task/internal/entities"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrFeedConsumerNotFound is returned when consumer of the feed does not exist
+var ErrFeedConsumerNotFound = errors.New("feed consumer is not found")
+
+// visibleOperations limits feed by operations of finished transactions: transaction ids are
+// assigned before commit, so rows of transactions running now may appear later below the last
+// delivered id, while all transactions older than snapshot's xmin are already finished.
+const visibleOperations = "tx_id < txid_snapshot_xmin(txid_current_snapshot())"
+
+// FeedManager represents actions of operations feed consumers
+type FeedManager interface {
+	Consumer(ctx context.Context, name, owner string) (*entities.FeedConsumer, error)
+	Get(ctx context.Context, name string) (*entities.FeedConsumer, error)
+	NextBatch(ctx context.Context, after entities.FeedPosition, limit int) (*entities.FeedPosition, error)
+	Ack(ctx context.Context, name string, position entities.FeedPosition) (*entities.FeedConsumer, error)
+	WalletPosition(ctx context.Context, walletID int) (entities.FeedPosition, error)
+	WalletOperations(ctx context.Context, walletID int, after entities.FeedPosition, limit int) ([]*entities.FeedOperation, error)
+}
+
+// FeedService implements FeedManager interface
+type FeedService struct {
+	db tx.SQLQueryAdapter
+}
+
+// NewFeedService returns feed consumers repository
+func NewFeedService(db tx.SQLQueryAdapter) *FeedService {
+	return &FeedService{
+		db: db,
+	}
+}
+
+// Consumer returns consumer with its acknowledged position; new consumer of the owner starts from the beginning
+// of the feed, owner of existing consumer is not changed
+func (fs *FeedService) Consumer(ctx context.Context, name, owner string) (*entities.FeedConsumer, error) {
+	if _, insertErr := fs.db.ExecContext(
+		ctx,
+		"insert into feed_consumers(name, owner) values($1, $2) on conflict (name) do nothing",
+		name, owner,
+	); insertErr != nil {
+		return nil, fmt.Errorf("[FEED_CONSUMER_CREATE]: %s", insertErr)
+	}
+	return fs.Get(ctx, name)
+}
+
+// NextBatch returns position of the last operation of the next batch after given position;
+// it is nil when there are no new operations
+func (fs *FeedService) NextBatch(ctx context.Context, after entities.FeedPosition, limit int) (*entities.FeedPosition, error) {
+	var position entities.FeedPosition
+	scanErr := fs.db.QueryRowContext(
+		ctx,
+		"select tx_id, id from ("+
+			"select tx_id, id from wallet_operations where (tx_id, id) > ($1, $2) and "+visibleOperations+
+			" order by tx_id, id limit $3"+
+			") batch order by tx_id desc, id desc limit 1",
+		after.TxID, after.OperationID, limit,
+	).Scan(&position.TxID, &position.OperationID)
+	if scanErr == sql.ErrNoRows {
+		return nil, nil
+	}
+	if scanErr != nil {
+		return nil, fmt.Errorf("[FEED_NEXT_BATCH]: %s", scanErr)
+	}
+	return &position, nil
+}
+
+// Ack moves consumer's position forward to delivered operation. Repeated ack of
+// the same or earlier position does not change consumer.
+func (fs *FeedService) Ack(ctx context.Context, name string, position entities.FeedPosition) (*entities.FeedConsumer, error) {
+	consumer := entities.FeedConsumer{}
+	scanErr := fs.db.QueryRowContext(
+		ctx,
+		"update feed_consumers c set last_tx_id = o.tx_id, last_operation_id = o.id, "+
+			"last_created_at = o.created_at, acked_at = current_timestamp from wallet_operations o "+
+			"where c.name = $1 and o.tx_id = $2 and o.id = $3 and "+
+			"(c.last_tx_id, c.last_operation_id) < (o.tx_id, o.id) and o."+visibleOperations+
+			" returning c.name, c.last_tx_id, c.last_operation_id, c.last_created_at, c.acked_at, coalesce(c.owner, '')",
+		name, position.TxID, position.OperationID,
+	).Scan(&consumer.Name, &consumer.Position.TxID, &consumer.Position.OperationID, &consumer.LastCreatedAt, &consumer.AckedAt, &consumer.Owner)
+	if scanErr == nil {
+		return &consumer, nil
+	}
+	if scanErr != sql.ErrNoRows {
+		return nil, fmt.Errorf("[FEED_ACK]: %s", scanErr)
+	}
+
+	// Nothing is updated: position is already acknowledged or it is not delivered by the feed
+	current, getErr := fs.Get(ctx, name)
+	if getErr != nil {
+		return nil, getErr
+	}
+	if position.Less(current.Position) || position == current.Position {
+		return current, nil
+	}
+	return nil, fmt.Errorf("feed cursor %s does not point to delivered operation", position)
+}
+
+// WalletPosition returns position of the last visible operation of wallet; it is zero when there are no operations
+func (fs *FeedService) WalletPosition(ctx context.Context, walletID int) (entities.FeedPosition, error) {
+	var position entities.FeedPosition
+	scanErr := fs.db.QueryRowContext(
+		ctx,
+		"select tx_id, id from wallet_operations where wallet_to = $1 and "+visibleOperations+" order by tx_id desc, id desc limit 1",
+		walletID,
+	).Scan(&position.TxID, &position.OperationID)
+	if scanErr != nil && scanErr != sql.ErrNoRows {
+		return position, fmt.Errorf("[FEED_WALLET_POSITION]: %s", scanErr)
+	}
+	return position, nil
+}
+
+// WalletOperations returns visible operations of wallet after given position in feed order
+func (fs *FeedService) WalletOperations(ctx context.Context, walletID int, after entities.FeedPosition, limit int) ([]*entities.FeedOperation, error) {
+	rows, queryErr := fs.db.QueryContext(
+		ctx,
+		"select tx_id, id, operation, wallet_from, wallet_to, amount, created_at from wallet_operations "+
+			"where wallet_to = $1 and (tx_id, id) > ($2, $3) and "+visibleOperations+" order by tx_id, id limit $4",
+		walletID, after.TxID, after.OperationID, limit,
+	)
+	if queryErr != nil {
+		return nil, fmt.Errorf("[FEED_WALLET_OPERATIONS]: %s", queryErr)
+	}
+	defer rows.Close()
+
+	operations := []*entities.FeedOperation{}
+	for rows.Next() {
+		operation := entities.FeedOperation{}
+		scanErr := rows.Scan(
+			&operation.Position.TxID,
+			&operation.Position.OperationID,
+			&operation.Operation,
+			&operation.WalletFrom,
+			&operation.WalletTo,
+			&operation.Amount,
+			&operation.CreatedAt,
+		)
+		if scanErr != nil {
+			return nil, fmt.Errorf("[FEED_WALLET_OPERATIONS_ROW]: %s", scanErr)
+		}
+		operation.ID = operation.Position.OperationID
+		operations = append(operations, &operation)
+	}
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, fmt.Errorf("[FEED_WALLET_OPERATIONS]: %s", rowsErr)
+	}
+	return operations, nil
+}
+
+// Get returns existing consumer
+func (fs *FeedService) Get(ctx context.Context, name string) (*entities.FeedConsumer, error) {
+	consumer := entities.FeedConsumer{}
+	scanErr := fs.db.QueryRowContext(
+		ctx,
+		"select name, last_tx_id, last_operation_id, last_created_at, acked_at, coalesce(owner, '') from feed_consumers where name = $1",
+		name,
+	).Scan(&consumer.Name, &consumer.Position.TxID, &consumer.Position.OperationID, &consumer.LastCreatedAt, &consumer.AckedAt, &consumer.Owner)
+	if scanErr == sql.ErrNoRows {
+		return nil, ErrFeedConsumerNotFound
+	}
+	if scanErr != nil {
+		return nil, fmt.Errorf("[FEED_CONSUMER]: %s", scanErr)
+	}
+	return &consumer, nil
+}
@@ -0,0 +1,125 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repositories/feed.go
+
+// Package repositories is a generated GoMock package.
+package repositories
+
+import (
+	entities "billing_system_test_task/internal/entities"
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockFeedManager is a mock of FeedManager interface
+type MockFeedManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedManagerMockRecorder
+}
+
+// MockFeedManagerMockRecorder is the mock recorder for MockFeedManager
+type MockFeedManagerMockRecorder struct {
+	mock *MockFeedManager
+}
+
+// NewMockFeedManager creates a new mock instance
+func NewMockFeedManager(ctrl *gomock.Controller) *MockFeedManager {
+	mock := &MockFeedManager{ctrl: ctrl}
+	mock.recorder = &MockFeedManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockFeedManager) EXPECT() *MockFeedManagerMockRecorder {
+	return m.recorder
+}
+
+// Consumer mocks base method
+func (m *MockFeedManager) Consumer(ctx context.Context, name, owner string) (*entities.FeedConsumer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consumer", ctx, name, owner)
+	ret0, _ := ret[0].(*entities.FeedConsumer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Consumer indicates an expected call of Consumer
+func (mr *MockFeedManagerMockRecorder) Consumer(ctx, name, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consumer", reflect.TypeOf((*MockFeedManager)(nil).Consumer), ctx, name, owner)
+}
+
+// Get mocks base method
+func (m *MockFeedManager) Get(ctx context.Context, name string) (*entities.FeedConsumer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, name)
+	ret0, _ := ret[0].(*entities.FeedConsumer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockFeedManagerMockRecorder) Get(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockFeedManager)(nil).Get), ctx, name)
+}
+
+// NextBatch mocks base method
+func (m *MockFeedManager) NextBatch(ctx context.Context, after entities.FeedPosition, limit int) (*entities.FeedPosition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NextBatch", ctx, after, limit)
+	ret0, _ := ret[0].(*entities.FeedPosition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NextBatch indicates an expected call of NextBatch
+func (mr *MockFeedManagerMockRecorder) NextBatch(ctx, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NextBatch", reflect.TypeOf((*MockFeedManager)(nil).NextBatch), ctx, after, limit)
+}
+
+// Ack mocks base method
+func (m *MockFeedManager) Ack(ctx context.Context, name string, position entities.FeedPosition) (*entities.FeedConsumer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ack", ctx, name, position)
+	ret0, _ := ret[0].(*entities.FeedConsumer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Ack indicates an expected call of Ack
+func (mr *MockFeedManagerMockRecorder) Ack(ctx, name, position interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ack", reflect.TypeOf((*MockFeedManager)(nil).Ack), ctx, name, position)
+}
+
+// WalletPosition mocks base method
+func (m *MockFeedManager) WalletPosition(ctx context.Context, walletID int) (entities.FeedPosition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WalletPosition", ctx, walletID)
+	ret0, _ := ret[0].(entities.FeedPosition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WalletPosition indicates an expected call of WalletPosition
+func (mr *MockFeedManagerMockRecorder) WalletPosition(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalletPosition", reflect.TypeOf((*MockFeedManager)(nil).WalletPosition), ctx, walletID)
+}
+
+// WalletOperations mocks base method
+func (m *MockFeedManager) WalletOperations(ctx context.Context, walletID int, after entities.FeedPosition, limit int) ([]*entities.FeedOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WalletOperations", ctx, walletID, after, limit)
+	ret0, _ := ret[0].([]*entities.FeedOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WalletOperations indicates an expected call of WalletOperations
+func (mr *MockFeedManagerMockRecorder) WalletOperations(ctx, walletID, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalletOperations", reflect.TypeOf((*MockFeedManager)(nil).WalletOperations), ctx, walletID, after, limit)
+}
@@ -0,0 +1,216 @@
+package repositories
+
+import (
+	"billing_system_test_task/internal/entities"
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var consumerColumns = []string{"name", "last_tx_id", "last_operation_id", "last_created_at", "acked_at", "owner"}
+
+// Test receiving of feed consumer
+func TestFeedRepoConsumer(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	mock.
+		ExpectExec(regexp.QuoteMeta("insert into feed_consumers(name, owner) values($1, $2) on conflict (name) do nothing")).
+		WithArgs("warehouse", "api_key:1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.
+		ExpectQuery(regexp.QuoteMeta("select name, last_tx_id, last_operation_id, last_created_at, acked_at, coalesce(owner, '') from feed_consumers")).
+		WithArgs("warehouse").
+		WillReturnRows(sqlmock.NewRows(consumerColumns).AddRow("warehouse", 0, 0, nil, nil, "api_key:1"))
+
+	consumer, err := NewFeedService(db).Consumer(context.Background(), "warehouse", "api_key:1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if consumer.Name != "warehouse" || consumer.Owner != "api_key:1" || consumer.Position != (entities.FeedPosition{}) || consumer.AckedAt.Valid {
+		t.Errorf("Wrong new consumer: %+v", consumer)
+	}
+
+	mock.ExpectExec("insert into feed_consumers").WillReturnError(fmt.Errorf("insert error"))
+	if _, err = NewFeedService(db).Consumer(context.Background(), "warehouse", "api_key:1"); err == nil || !strings.Contains(err.Error(), "[FEED_CONSUMER_CREATE]: insert error") {
+		t.Errorf("Expected insert error, got %v", err)
+	}
+}
+
+// Test receiving of next batch's position
+func TestFeedRepoNextBatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		mockQuery func(mock sqlmock.Sqlmock)
+		expected  *entities.FeedPosition
+		err       string
+	}{
+		{
+			name: "Next batch",
+			mockQuery: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery(regexp.QuoteMeta("where (tx_id, id) > ($1, $2) and tx_id < txid_snapshot_xmin(txid_current_snapshot()) order by tx_id, id limit $3")).
+					WithArgs([]driver.Value{500, 10, 100}...).
+					WillReturnRows(sqlmock.NewRows([]string{"tx_id", "id"}).AddRow(510, 9))
+			},
+			expected: &entities.FeedPosition{TxID: 510, OperationID: 9},
+		},
+		{
+			name: "No new operations",
+			mockQuery: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("select tx_id, id from").WillReturnRows(sqlmock.NewRows([]string{"tx_id", "id"}))
+			},
+		},
+		{
+			name: "Query error",
+			mockQuery: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("select tx_id, id from").WillReturnError(fmt.Errorf("query error"))
+			},
+			err: "[FEED_NEXT_BATCH]: query error",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			defer db.Close()
+			tc.mockQuery(mock)
+
+			position, err := NewFeedService(db).NextBatch(context.Background(), entities.FeedPosition{TxID: 500, OperationID: 10}, 100)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Errorf("Expected error '%s', got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if (position == nil) != (tc.expected == nil) || position != nil && *position != *tc.expected {
+				t.Errorf("Expected position %v, got %v", tc.expected, position)
+			}
+		})
+	}
+}
+
+// Test acknowledgement of delivered operations
+func TestFeedRepoAck(t *testing.T) {
+	ackedAt := time.Date(2022, time.October, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		mockQuery func(mock sqlmock.Sqlmock)
+		expected  entities.FeedPosition
+		err       string
+	}{
+		{
+			name: "Position is moved",
+			mockQuery: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery(regexp.QuoteMeta("(c.last_tx_id, c.last_operation_id) < (o.tx_id, o.id) and o.tx_id < txid_snapshot_xmin")).
+					WithArgs([]driver.Value{"warehouse", 510, 9}...).
+					WillReturnRows(sqlmock.NewRows(consumerColumns).AddRow("warehouse", 510, 9, ackedAt, ackedAt, "api_key:1"))
+			},
+			expected: entities.FeedPosition{TxID: 510, OperationID: 9},
+		},
+		{
+			name: "Repeated acknowledgement",
+			mockQuery: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("update feed_consumers").WillReturnRows(sqlmock.NewRows(consumerColumns))
+				mock.
+					ExpectQuery(regexp.QuoteMeta("select name, last_tx_id, last_operation_id, last_created_at, acked_at, coalesce(owner, '') from feed_consumers")).
+					WillReturnRows(sqlmock.NewRows(consumerColumns).AddRow("warehouse", 520, 3, ackedAt, ackedAt, "api_key:1"))
+			},
+			expected: entities.FeedPosition{TxID: 520, OperationID: 3},
+		},
+		{
+			name: "Undelivered operation",
+			mockQuery: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("update feed_consumers").WillReturnRows(sqlmock.NewRows(consumerColumns))
+				mock.
+					ExpectQuery(regexp.QuoteMeta("select name, last_tx_id, last_operation_id, last_created_at, acked_at, coalesce(owner, '') from feed_consumers")).
+					WillReturnRows(sqlmock.NewRows(consumerColumns).AddRow("warehouse", 500, 10, nil, nil, "api_key:1"))
+			},
+			err: "feed cursor 510-9 does not point to delivered operation",
+		},
+		{
+			name: "Unknown consumer",
+			mockQuery: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("update feed_consumers").WillReturnRows(sqlmock.NewRows(consumerColumns))
+				mock.ExpectQuery("select name").WillReturnRows(sqlmock.NewRows(consumerColumns))
+			},
+			err: ErrFeedConsumerNotFound.Error(),
+		},
+		{
+			name: "Update error",
+			mockQuery: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("update feed_consumers").WillReturnError(fmt.Errorf("update error"))
+			},
+			err: "[FEED_ACK]: update error",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			defer db.Close()
+			tc.mockQuery(mock)
+
+			consumer, err := NewFeedService(db).Ack(context.Background(), "warehouse", entities.FeedPosition{TxID: 510, OperationID: 9})
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Errorf("Expected error '%s', got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if consumer.Position != tc.expected || !consumer.AckedAt.Valid {
+				t.Errorf("Wrong acknowledged consumer: %+v", consumer)
+			}
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+// Test reading of wallet's operations by feed positions
+func TestFeedRepoWalletOperations(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2022, time.December, 6, 12, 0, 0, 0, time.UTC)
+	service := NewFeedService(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("select tx_id, id from wallet_operations where wallet_to = $1 and tx_id < txid_snapshot_xmin")).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"tx_id", "id"}).AddRow(700, 12))
+	if position, err := service.WalletPosition(ctx, 1); err != nil || position != (entities.FeedPosition{TxID: 700, OperationID: 12}) {
+		t.Errorf("Wrong position: %v (%v)", position, err)
+	}
+	mock.ExpectQuery("select tx_id, id from wallet_operations").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"tx_id", "id"}))
+	if position, err := service.WalletPosition(ctx, 2); err != nil || position != (entities.FeedPosition{}) {
+		t.Errorf("Expected zero position, got %v (%v)", position, err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("where wallet_to = $1 and (tx_id, id) > ($2, $3) and tx_id < txid_snapshot_xmin(txid_current_snapshot()) order by tx_id, id limit $4")).
+		WithArgs(1, int64(700), 12, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"tx_id", "id", "operation", "wallet_from", "wallet_to", "amount", "created_at"}).
+			AddRow(701, 15, "deposit", 2, 1, "10.00", now).
+			AddRow(702, 14, "create wallet", nil, 1, "0.00", now))
+	operations, err := service.WalletOperations(ctx, 1, entities.FeedPosition{TxID: 700, OperationID: 12}, 100)
+	if err != nil || len(operations) != 2 {
+		t.Fatalf("Wrong operations: %v (%v)", operations, err)
+	}
+	if operations[0].ID != 15 || operations[0].Position.String() != "701-15" || !operations[0].WalletFrom.Valid || operations[1].WalletFrom.Valid {
+		t.Errorf("Wrong operations: %+v %+v", operations[0], operations[1])
+	}
+
+	if expectationsErr := mock.ExpectationsWereMet(); expectationsErr != nil {
+		t.Errorf("Unfulfilled expectations: %s", expectationsErr)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"billing_system_test_task/internal/entities"
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/shopspring/decimal"
 )
@@ -19,7 +20,7 @@ const (
 type OperationsManager interface {
 	WithTx(t tx.Tx) OperationsManager
 	Create(ctx context.Context, operation string, walletFrom, walletTo int, amount decimal.Decimal) (int, error)
-	List(ctx context.Context, params *ListParams) (chan *entities.WalletOperation, error)
+	List(ctx context.Context, params *ListParams) (<-chan *entities.WalletOperation, <-chan error, error)
 }
 
 type WalletOperationService struct {
@@ -27,9 +28,15 @@ type WalletOperationService struct {
 }
 
 type ListParams struct {
-	Page    int
-	PerPage int
-	Date    string
+	Page     int
+	PerPage  int
+	Date     string
+	WalletID int
+	From     string
+	To       string
+	// After and Until limit operations by feed positions
+	After *entities.FeedPosition
+	Until *entities.FeedPosition
 }
 
 func NewWalletOperationRepo(db tx.SQLQueryAdapter) *WalletOperationService {
@@ -74,10 +81,9 @@ func (wor WalletOperationService) Create(ctx context.Context, operation string,
 	return walletOperationID, nil
 }
 
-func (wor WalletOperationService) List(ctx context.Context, params *ListParams) (chan *entities.WalletOperation, error) {
-	opCh := make(chan *entities.WalletOperation, 1)
-	defer close(opCh)
-
+// List sends operations filtered by parameters as they are scanned; error of reading is sent to the errors channel
+// before operations channel is closed. Reading stops when context is cancelled.
+func (wor WalletOperationService) List(ctx context.Context, params *ListParams) (<-chan *entities.WalletOperation, <-chan error, error) {
 	query := "select id, operation, wallet_from, wallet_to, amount, created_at from wallet_operations"
 	args := []interface{}{}
 	if params != nil {
@@ -88,26 +94,43 @@ func (wor WalletOperationService) List(ctx context.Context, params *ListParams)
 			page -= 1
 		}
 
+		conditions := []string{}
 		if params.Date != "" {
-			query += " where created_at = to_date($1, 'YYYY-MM-DD') "
 			args = append(args, params.Date)
+			conditions = append(conditions, fmt.Sprintf("created_at = to_date($%d, 'YYYY-MM-DD')", len(args)))
+		}
+		if params.WalletID != 0 {
+			args = append(args, params.WalletID)
+			conditions = append(conditions, fmt.Sprintf("wallet_to = $%d", len(args)))
+		}
+		if params.From != "" {
+			args = append(args, params.From)
+			conditions = append(conditions, fmt.Sprintf("created_at >= to_date($%d, 'YYYY-MM-DD')", len(args)))
+		}
+		if params.To != "" {
+			args = append(args, params.To)
+			conditions = append(conditions, fmt.Sprintf("created_at < to_date($%d, 'YYYY-MM-DD') + 1", len(args)))
+		}
+		if params.After != nil {
+			args = append(args, params.After.TxID, params.After.OperationID)
+			conditions = append(conditions, fmt.Sprintf("(tx_id, id) > ($%d, $%d)", len(args)-1, len(args)))
+		}
+		if params.Until != nil {
+			args = append(args, params.Until.TxID, params.Until.OperationID)
+			conditions = append(conditions, fmt.Sprintf("(tx_id, id) <= ($%d, $%d)", len(args)-1, len(args)))
+		}
+		if len(conditions) > 0 {
+			query += " where " + strings.Join(conditions, " and ")
+		}
+		if params.After != nil || params.Until != nil {
+			// Feed is ordered by commit of transactions
+			query += " order by tx_id, id"
+		} else {
+			query += " order by created_at, id"
 		}
 
 		if params.PerPage != 0 {
-			var (
-				pageIdx    int
-				perPageIdx int
-				argsLen    = len(args)
-			)
-
-			if argsLen == 0 {
-				pageIdx = 1
-				perPageIdx = 2
-			} else {
-				pageIdx = argsLen + 1
-				perPageIdx = pageIdx + 1
-			}
-			query += fmt.Sprintf(" offset $%d limit $%d", pageIdx, perPageIdx)
+			query += fmt.Sprintf(" offset $%d limit $%d", len(args)+1, len(args)+2)
 			args = append(args, page*params.PerPage)
 			args = append(args, params.PerPage)
 		}
@@ -118,17 +141,32 @@ func (wor WalletOperationService) List(ctx context.Context, params *ListParams)
 		args...,
 	)
 	if queryRowErr != nil {
-		return nil, fmt.Errorf("[OPERATIONS_LIST]: %s", queryRowErr)
+		return nil, nil, fmt.Errorf("[OPERATIONS_LIST]: %s", queryRowErr)
 	}
 
-	for rows.Next() {
-		operation := entities.WalletOperation{}
-		scanErr := rows.Scan(&operation.ID, &operation.Operation, &operation.WalletFrom, &operation.WalletTo, &operation.Amount, &operation.CreatedAt)
-		if scanErr != nil {
-			return nil, fmt.Errorf("[OPERATIONS_LIST_ROW]: %s", scanErr)
+	opCh := make(chan *entities.WalletOperation)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(errCh)
+		defer close(opCh)
+		defer rows.Close()
+		for rows.Next() {
+			operation := entities.WalletOperation{}
+			scanErr := rows.Scan(&operation.ID, &operation.Operation, &operation.WalletFrom, &operation.WalletTo, &operation.Amount, &operation.CreatedAt)
+			if scanErr != nil {
+				errCh <- fmt.Errorf("[OPERATIONS_LIST_ROW]: %s", scanErr)
+				return
+			}
+			select {
+			case opCh <- &operation:
+			case <-ctx.Done():
+				errCh <- fmt.Errorf("[OPERATIONS_LIST]: %s", ctx.Err())
+				return
+			}
 		}
-		opCh <- &operation
-	}
-
-	return opCh, nil
+		if rowsErr := rows.Err(); rowsErr != nil {
+			errCh <- fmt.Errorf("[OPERATIONS_LIST]: %s", rowsErr)
+		}
+	}()
+	return opCh, errCh, nil
 }
@@ -66,12 +66,13 @@ func (mr *MockOperationsManagerMockRecorder) Create(ctx, operation, walletFrom,
 }
 
 // List mocks base method
-func (m *MockOperationsManager) List(ctx context.Context, params *ListParams) (chan *entities.WalletOperation, error) {
+func (m *MockOperationsManager) List(ctx context.Context, params *ListParams) (<-chan *entities.WalletOperation, <-chan error, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "List", ctx, params)
-	ret0, _ := ret[0].(chan *entities.WalletOperation)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret0, _ := ret[0].(<-chan *entities.WalletOperation)
+	ret1, _ := ret[1].(<-chan error)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // List indicates an expected call of List
@@ -7,6 +7,7 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
@@ -205,6 +206,53 @@ var operationRepoTestCases = []operationRepoTestCase{
 			return operation.ID == 1
 		},
 	},
+	operationRepoTestCase{
+		name:     "Success receiving list of wallet's items within period",
+		funcName: "List",
+		args:     []driver.Value{&ListParams{WalletID: 1, From: "2020-01-01", To: "2020-01-31"}},
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			rows := sqlmock.NewRows([]string{"id", "operation", "wallet_from", "wallet_to", "amount", "created_at"})
+			rows = rows.AddRow(1, Create, nil, 1, decimal.NewFromInt(0), time.Now())
+			rows = rows.AddRow(2, Deposit, nil, 1, decimal.NewFromInt(10), time.Now())
+
+			mock.
+				ExpectQuery(regexp.QuoteMeta("where wallet_to = $1 and created_at >= to_date($2, 'YYYY-MM-DD') and created_at < to_date($3, 'YYYY-MM-DD') + 1 order by created_at, id")).
+				WithArgs([]driver.Value{1, "2020-01-01", "2020-01-31"}...).
+				WillReturnRows(rows)
+
+		},
+		expectedResultMatch: func(actual interface{}) bool {
+			rows := actual.(chan *entities.WalletOperation)
+			ids := []int{}
+			for row := range rows {
+				ids = append(ids, row.ID)
+			}
+			return len(ids) == 2 && ids[0] == 1 && ids[1] == 2
+		},
+	},
+	operationRepoTestCase{
+		name:     "Success receiving of feed batch",
+		funcName: "List",
+		args: []driver.Value{&ListParams{
+			After: &entities.FeedPosition{TxID: 500, OperationID: 10},
+			Until: &entities.FeedPosition{TxID: 502, OperationID: 12},
+		}},
+		mockQuery: func(mock sqlmock.Sqlmock) {
+			rows := sqlmock.NewRows([]string{"id", "operation", "wallet_from", "wallet_to", "amount", "created_at"})
+			rows = rows.AddRow(12, Deposit, nil, 1, decimal.NewFromInt(10), time.Now())
+
+			mock.
+				ExpectQuery(regexp.QuoteMeta("where (tx_id, id) > ($1, $2) and (tx_id, id) <= ($3, $4) order by tx_id, id")).
+				WithArgs([]driver.Value{500, 10, 502, 12}...).
+				WillReturnRows(rows)
+
+		},
+		expectedResultMatch: func(actual interface{}) bool {
+			rows := actual.(chan *entities.WalletOperation)
+			operation := <-rows
+			return operation.ID == 12
+		},
+	},
 	operationRepoTestCase{
 		name:     "Success receiving of empty list",
 		funcName: "List",
@@ -305,10 +353,20 @@ func TestOperationsRepo(t *testing.T) {
 			}
 			var reflectErr error
 			resultValue := result[0].Interface()
-			rerr := result[1].Interface()
+			rerr := result[len(result)-1].Interface()
 			if rerr != nil {
 				reflectErr = rerr.(error)
 			}
+			// Streamed operations are received before error of reading
+			if operations, isStream := resultValue.(<-chan *entities.WalletOperation); isStream && reflectErr == nil {
+				received := make(chan *entities.WalletOperation, 100)
+				for operation := range operations {
+					received <- operation
+				}
+				close(received)
+				resultValue = received
+				reflectErr = <-result[1].Interface().(<-chan error)
+			}
 
 			if reflectErr != nil && tc.err == nil {
 				t.Errorf("unexpected err: %s", reflectErr)
@@ -381,6 +439,58 @@ func BenchmarkCreate(b *testing.B) {
 	}
 }
 
+// Test operations are streamed as they are scanned and errors of reading are reported
+func TestWalletOperationServiceList(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+	repo := NewWalletOperationRepo(db)
+	createdAt := time.Date(2022, time.December, 1, 10, 0, 0, 0, time.UTC)
+	columns := []string{"id", "operation", "wallet_from", "wallet_to", "amount", "created_at"}
+	query := regexp.QuoteMeta("select id, operation, wallet_from, wallet_to, amount, created_at from wallet_operations where wallet_to = $1")
+
+	mock.ExpectQuery(query).WithArgs(3).WillReturnRows(sqlmock.NewRows(columns).
+		AddRow(1, Deposit, nil, 3, "10", createdAt).
+		AddRow(2, Withdrawal, 3, 4, "5", createdAt).
+		RowError(1, fmt.Errorf("connection reset")))
+	operations, readErrs, listErr := repo.List(context.Background(), &ListParams{WalletID: 3})
+	if listErr != nil {
+		t.Fatalf("Unexpected error: %s", listErr)
+	}
+	received := []int{}
+	for operation := range operations {
+		received = append(received, operation.ID)
+	}
+	if readErr := <-readErrs; len(received) != 1 || readErr == nil || !strings.Contains(readErr.Error(), "connection reset") {
+		t.Errorf("Unexpected operations %v (%v)", received, readErr)
+	}
+
+	// Reading stops when reader is gone
+	ctx, cancel := context.WithCancel(context.Background())
+	mock.ExpectQuery(query).WithArgs(3).WillReturnRows(sqlmock.NewRows(columns).
+		AddRow(1, Deposit, nil, 3, "10", createdAt).
+		AddRow(2, Deposit, nil, 3, "5", createdAt))
+	operations, readErrs, _ = repo.List(ctx, &ListParams{WalletID: 3})
+	<-operations
+	cancel()
+	if readErr := <-readErrs; readErr == nil {
+		t.Error("Expected error of cancelled reading")
+	}
+	if _, isOpen := <-operations; isOpen {
+		t.Error("Expected closed channel of operations")
+	}
+
+	mock.ExpectQuery(query).WithArgs(3).WillReturnError(fmt.Errorf("connection refused"))
+	if _, _, listErr = repo.List(context.Background(), &ListParams{WalletID: 3}); listErr == nil {
+		t.Error("Expected error of query")
+	}
+	if expectationsErr := mock.ExpectationsWereMet(); expectationsErr != nil {
+		t.Errorf("Unfulfilled expectations: %s", expectationsErr)
+	}
+}
+
 // Benchmark repository List operation
 func BenchmarkList(b *testing.B) {
 	sqlDB, mock, err := sqlmock.New()
@@ -399,6 +509,6 @@ func BenchmarkList(b *testing.B) {
 	repo := NewWalletOperationRepo(sqlDB)
 
 	for i := 0; i < b.N; i++ {
-		_, _ = repo.List(ctx, nil)
+		_, _, _ = repo.List(ctx, nil)
 	}
 }
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"billing_system_test_task/internal/adapters/tx"
+	"billing_system_test_task/internal/entities"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// outboxLease postpones claimed events until they are published or marked as failed
+const outboxLease = "interval '5 minutes'"
+
+// OutboxManager represents storage of domain events which are published after commit
+type OutboxManager interface {
+	WithTx(t tx.Tx) OutboxManager
+	Add(ctx context.Context, eventType string, payload interface{}) error
+	ClaimPending(ctx context.Context, limit int) ([]*entities.OutboxEvent, error)
+	MarkSent(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, reason string, retryIn time.Duration) error
+}
+
+// OutboxService implements OutboxManager interface
+type OutboxService struct {
+	db tx.SQLQueryAdapter
+}
+
+// NewOutboxService returns outbox repository
+func NewOutboxService(db tx.SQLQueryAdapter) *OutboxService {
+	return &OutboxService{
+		db: db,
+	}
+}
+
+// WithTx returns repository which works in transaction
+func (ob OutboxService) WithTx(t tx.Tx) OutboxManager {
+	return NewOutboxService(t.(tx.SQLQueryAdapter))
+}
+
+// Add writes event; it must be called with transaction of the change, so the event is lost or emitted with it
+func (ob OutboxService) Add(ctx context.Context, eventType string, payload interface{}) error {
+	data, marshalErr := json.Marshal(payload)
+	if marshalErr != nil {
+		return fmt.Errorf("[OUTBOX_ADD]: %s", marshalErr)
+	}
+	if _, execErr := ob.db.ExecContext(ctx, "insert into outbox(event_type, payload) values($1, $2)", eventType, string(data)); execErr != nil {
+		return fmt.Errorf("[OUTBOX_ADD]: %s", execErr)
+	}
+	return nil
+}
+
+// ClaimPending returns due unsent events in order of writing and postpones them by the lease, so concurrent
+// dispatchers skip them while they are published and they are retried when dispatcher stops
+func (ob OutboxService) ClaimPending(ctx context.Context, limit int) ([]*entities.OutboxEvent, error) {
+	rows, queryErr := ob.db.QueryContext(
+		ctx,
+		"with claimed as ("+
+			"update outbox set next_attempt_at = current_timestamp + "+outboxLease+" where id in ("+
+			"select id from outbox where sent_at is null and next_attempt_at <= current_timestamp "+
+			"order by id limit $1 for update skip locked"+
+			") returning id, event_type, payload, attempts, created_at"+
+			") select id, event_type, payload, attempts, created_at from claimed order by id",
+		limit,
+	)
+	if queryErr != nil {
+		return nil, fmt.Errorf("[OUTBOX_CLAIM]: %s", queryErr)
+	}
+	defer rows.Close()
+
+	events := []*entities.OutboxEvent{}
+	for rows.Next() {
+		var (
+			event   entities.OutboxEvent
+			payload []byte
+		)
+		if scanErr := rows.Scan(&event.ID, &event.Type, &payload, &event.Attempts, &event.CreatedAt); scanErr != nil {
+			return nil, fmt.Errorf("[OUTBOX_CLAIM_ROW]: %s", scanErr)
+		}
+		event.Payload = payload
+		events = append(events, &event)
+	}
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, fmt.Errorf("[OUTBOX_CLAIM]: %s", rowsErr)
+	}
+	return events, nil
+}
+
+// MarkSent marks event as published
+func (ob OutboxService) MarkSent(ctx context.Context, id int64) error {
+	if _, execErr := ob.db.ExecContext(ctx, "update outbox set sent_at = current_timestamp where id = $1", id); execErr != nil {
+		return fmt.Errorf("[OUTBOX_SENT]: %s", execErr)
+	}
+	return nil
+}
+
+// MarkFailed records failed publishing and postpones the next attempt
+func (ob OutboxService) MarkFailed(ctx context.Context, id int64, reason string, retryIn time.Duration) error {
+	_, execErr := ob.db.ExecContext(
+		ctx,
+		"update outbox set attempts = attempts + 1, last_error = $2, next_attempt_at = current_timestamp + make_interval(secs => $3) where id = $1",
+		id, reason, retryIn.Seconds(),
+	)
+	if execErr != nil {
+		return fmt.Errorf("[OUTBOX_FAILED]: %s", execErr)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repositories/outbox.go
+
+// Package repositories is a generated GoMock package.
+package repositories
+
+import (
+	tx "billing_system_test_task/internal/adapters/tx"
+	entities "billing_system_test_task/internal/entities"
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+	time "time"
+)
+
+// MockOutboxManager is a mock of OutboxManager interface
+type MockOutboxManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxManagerMockRecorder
+}
+
+// MockOutboxManagerMockRecorder is the mock recorder for MockOutboxManager
+type MockOutboxManagerMockRecorder struct {
+	mock *MockOutboxManager
+}
+
+// NewMockOutboxManager creates a new mock instance
+func NewMockOutboxManager(ctrl *gomock.Controller) *MockOutboxManager {
+	mock := &MockOutboxManager{ctrl: ctrl}
+	mock.recorder = &MockOutboxManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockOutboxManager) EXPECT() *MockOutboxManagerMockRecorder {
+	return m.recorder
+}
+
+// WithTx mocks base method
+func (m *MockOutboxManager) WithTx(t tx.Tx) OutboxManager {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", t)
+	ret0, _ := ret[0].(OutboxManager)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx
+func (mr *MockOutboxManagerMockRecorder) WithTx(t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockOutboxManager)(nil).WithTx), t)
+}
+
+// Add mocks base method
+func (m *MockOutboxManager) Add(ctx context.Context, eventType string, payload interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, eventType, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add
+func (mr *MockOutboxManagerMockRecorder) Add(ctx, eventType, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockOutboxManager)(nil).Add), ctx, eventType, payload)
+}
+
+// ClaimPending mocks base method
+func (m *MockOutboxManager) ClaimPending(ctx context.Context, limit int) ([]*entities.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimPending", ctx, limit)
+	ret0, _ := ret[0].([]*entities.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimPending indicates an expected call of ClaimPending
+func (mr *MockOutboxManagerMockRecorder) ClaimPending(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimPending", reflect.TypeOf((*MockOutboxManager)(nil).ClaimPending), ctx, limit)
+}
+
+// MarkSent mocks base method
+func (m *MockOutboxManager) MarkSent(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSent", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSent indicates an expected call of MarkSent
+func (mr *MockOutboxManagerMockRecorder) MarkSent(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSent", reflect.TypeOf((*MockOutboxManager)(nil).MarkSent), ctx, id)
+}
+
+// MarkFailed mocks base method
+func (m *MockOutboxManager) MarkFailed(ctx context.Context, id int64, reason string, retryIn time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkFailed", ctx, id, reason, retryIn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkFailed indicates an expected call of MarkFailed
+func (mr *MockOutboxManagerMockRecorder) MarkFailed(ctx, id, reason, retryIn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFailed", reflect.TypeOf((*MockOutboxManager)(nil).MarkFailed), ctx, id, reason, retryIn)
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// Test writing and dispatching of outbox events
+func TestOutboxService(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2022, time.November, 29, 12, 0, 0, 0, time.UTC)
+	service := NewOutboxService(db)
+
+	mock.ExpectExec(regexp.QuoteMeta("insert into outbox(event_type, payload) values($1, $2)")).
+		WithArgs("user.created", `{"user_id":1}`).WillReturnResult(sqlmock.NewResult(1, 1))
+	if addErr := service.Add(ctx, "user.created", map[string]int{"user_id": 1}); addErr != nil {
+		t.Errorf("Unexpected error: %s", addErr)
+	}
+	if addErr := service.Add(ctx, "user.created", func() {}); addErr == nil {
+		t.Error("Expected error of payload marshalling")
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("update outbox set next_attempt_at = current_timestamp + interval '5 minutes' where id in (")).WithArgs(100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "event_type", "payload", "attempts", "created_at"}).
+			AddRow(1, "user.created", []byte(`{"user_id":1}`), 0, now).
+			AddRow(2, "transfer.completed", []byte(`{"amount":"10"}`), 2, now))
+	events, claimErr := service.ClaimPending(ctx, 100)
+	if claimErr != nil || len(events) != 2 || events[1].Attempts != 2 || string(events[0].Payload) != `{"user_id":1}` {
+		t.Errorf("Wrong events: %v (%v)", events, claimErr)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta("update outbox set sent_at = current_timestamp where id = $1")).
+		WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	if sentErr := service.MarkSent(ctx, 1); sentErr != nil {
+		t.Errorf("Unexpected error: %s", sentErr)
+	}
+	mock.ExpectExec(regexp.QuoteMeta("update outbox set attempts = attempts + 1, last_error = $2")).
+		WithArgs(int64(2), "connection refused", 60.0).WillReturnResult(sqlmock.NewResult(0, 1))
+	if failedErr := service.MarkFailed(ctx, 2, "connection refused", time.Minute); failedErr != nil {
+		t.Errorf("Unexpected error: %s", failedErr)
+	}
+
+	if expectationsErr := mock.ExpectationsWereMet(); expectationsErr != nil {
+		t.Errorf("Unfulfilled expectations: %s", expectationsErr)
+	}
+}
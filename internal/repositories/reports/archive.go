@@ -0,0 +1,113 @@
+package reports
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// Compressions of report archives
+const (
+	CompressionGzip = "gzip"
+	CompressionZip  = "zip"
+)
+
+// File extensions of report archives
+var archiveExtensions = map[string]string{
+	CompressionGzip: "gz",
+	CompressionZip:  "zip",
+}
+
+// encryptedExtension is the file extension of encrypted reports
+const encryptedExtension = "enc"
+
+// ArchiveOptions represents compression and encryption of report's output
+type ArchiveOptions struct {
+	Compression string
+	Encryption  string
+	Passphrase  string
+}
+
+// archiveName returns name of archive with report of given name
+func archiveName(name string, archive *ArchiveOptions) string {
+	if archive == nil {
+		return name
+	}
+	if archive.Compression != "" {
+		name += "." + archiveExtensions[archive.Compression]
+	}
+	if archive.Encryption != "" {
+		name += "." + encryptedExtension
+	}
+	return name
+}
+
+// newArchiveWriter wraps storage's writer with encryption and compression; report named entry is written to zip archive
+func newArchiveWriter(w io.WriteCloser, entry string, archive *ArchiveOptions, encryptionKey []byte) (io.WriteCloser, error) {
+	if archive == nil {
+		return w, nil
+	}
+
+	switch archive.Encryption {
+	case "":
+	case EncryptionPassphrase:
+		encrypter, encryptErr := NewPassphraseEncrypter(w, archive.Passphrase)
+		if encryptErr != nil {
+			return nil, encryptErr
+		}
+		w = encrypter
+	case EncryptionKey:
+		encrypter, encryptErr := NewKeyEncrypter(w, encryptionKey)
+		if encryptErr != nil {
+			return nil, encryptErr
+		}
+		w = encrypter
+	default:
+		return nil, fmt.Errorf("unsupported encryption: %s", archive.Encryption)
+	}
+
+	switch archive.Compression {
+	case "":
+		return w, nil
+	case CompressionGzip:
+		return &gzipWriter{Writer: gzip.NewWriter(w), w: w}, nil
+	case CompressionZip:
+		zw := zip.NewWriter(w)
+		entryWriter, entryErr := zw.Create(entry)
+		if entryErr != nil {
+			return nil, fmt.Errorf("error of zip entry creation: %s", entryErr)
+		}
+		return &zipWriter{Writer: entryWriter, zw: zw, w: w}, nil
+	}
+	return nil, fmt.Errorf("unsupported compression: %s", archive.Compression)
+}
+
+// gzipWriter closes gzip stream and underlying writer
+type gzipWriter struct {
+	*gzip.Writer
+	w io.WriteCloser
+}
+
+func (gw *gzipWriter) Close() error {
+	if closeErr := gw.Writer.Close(); closeErr != nil {
+		gw.w.Close()
+		return fmt.Errorf("error of gzip closing: %s", closeErr)
+	}
+	return gw.w.Close()
+}
+
+// zipWriter writes report to the single entry of zip archive
+type zipWriter struct {
+	io.Writer
+	zw *zip.Writer
+	w  io.WriteCloser
+}
+
+func (zw *zipWriter) Close() error {
+	if closeErr := zw.zw.Close(); closeErr != nil {
+		zw.w.Close()
+		return fmt.Errorf("error of zip closing: %s", closeErr)
+	}
+	return zw.w.Close()
+}
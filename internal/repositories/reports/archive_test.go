@@ -0,0 +1,219 @@
+package reports
+
+import (
+	"archive/zip"
+	"billing_system_test_task/internal/entities"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Test compressed and encrypted reports are readable after unpacking
+func TestFileHandlerCreateArchive(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	data := bytes.Repeat([]byte("1,10.00\n"), 10000)
+	tests := []struct {
+		archive     *ArchiveOptions
+		suffix      string
+		contentType string
+	}{
+		{archive: &ArchiveOptions{Compression: CompressionGzip}, suffix: ".csv.gz", contentType: "application/gzip"},
+		{archive: &ArchiveOptions{Compression: CompressionZip}, suffix: ".csv.zip", contentType: "application/zip"},
+		{archive: &ArchiveOptions{Encryption: EncryptionKey}, suffix: ".csv.enc", contentType: "application/octet-stream"},
+		{archive: &ArchiveOptions{Compression: CompressionGzip, Encryption: EncryptionPassphrase, Passphrase: "secret"}, suffix: ".csv.gz.enc", contentType: "application/octet-stream"},
+	}
+	for _, tc := range tests {
+		storage := NewMemoryStorage()
+		fh := NewFileHandler(storage, nil, key)
+		params, err := fh.Create("csv", tc.archive)
+		if err != nil {
+			t.Fatalf("[%s] Unexpected error: %s", tc.suffix, err)
+		}
+		if !strings.HasSuffix(params.Name, tc.suffix) {
+			t.Errorf("[%s] Wrong name of archive: %s", tc.suffix, params.Name)
+		}
+		_, _ = params.Writer.Write(data)
+		if closeErr := params.Writer.Close(); closeErr != nil {
+			t.Fatalf("[%s] Unexpected error: %s", tc.suffix, closeErr)
+		}
+
+		stored, _ := storage.Open(params.Name)
+		metadata, _ := fh.GetFileMetadata(stored, FormatByName(params.Name))
+		if metadata.ContentType != tc.contentType {
+			t.Errorf("[%s] Wrong content type: %s", tc.suffix, metadata.ContentType)
+		}
+		_, _ = stored.Seek(0, io.SeekStart)
+
+		var content io.Reader = stored
+		if tc.archive.Encryption != "" {
+			content, err = NewDecrypter(content, tc.archive.Passphrase, key)
+			if err != nil {
+				t.Fatalf("[%s] Unexpected error: %s", tc.suffix, err)
+			}
+		}
+		switch tc.archive.Compression {
+		case CompressionGzip:
+			content, err = gzip.NewReader(content)
+		case CompressionZip:
+			archived, _ := ioutil.ReadAll(content)
+			zr, zipErr := zip.NewReader(bytes.NewReader(archived), int64(len(archived)))
+			if zipErr != nil || len(zr.File) != 1 || zr.File[0].Name+".zip" != params.Name {
+				t.Fatalf("[%s] Wrong zip archive: %v", tc.suffix, zipErr)
+			}
+			content, err = zr.File[0].Open()
+		}
+		if err != nil {
+			t.Fatalf("[%s] Unexpected error: %s", tc.suffix, err)
+		}
+		unpacked, _ := ioutil.ReadAll(content)
+		if !bytes.Equal(unpacked, data) {
+			t.Errorf("[%s] Wrong unpacked report: %d bytes", tc.suffix, len(unpacked))
+		}
+	}
+}
+
+// Test failed archive creation removes stored report
+func TestFileHandlerCreateArchiveErrors(t *testing.T) {
+	storage := NewMemoryStorage()
+	fh := NewFileHandler(storage, nil, nil)
+	if _, err := fh.Create("csv", &ArchiveOptions{Encryption: EncryptionKey}); err == nil || err.Error() != "encryption key is not configured" {
+		t.Errorf("Expected encryption key error, got %v", err)
+	}
+	if _, err := fh.Create("csv", &ArchiveOptions{Compression: "bzip2"}); err == nil {
+		t.Errorf("Expected compression error, got nil")
+	}
+	if len(storage.objects) != 0 {
+		t.Errorf("Reports are not removed: %v", storage.objects)
+	}
+}
+
+// discardStorage implements FileStorageManager interface which drops reports
+type discardStorage struct {
+	written int64
+}
+
+func (ds *discardStorage) Create(name string) (io.WriteCloser, error) {
+	return ds, nil
+}
+
+func (ds *discardStorage) Write(p []byte) (int, error) {
+	ds.written += int64(len(p))
+	return len(p), nil
+}
+
+func (ds *discardStorage) Close() error {
+	return nil
+}
+
+func (ds *discardStorage) Open(name string) (entities.ReportFile, error) {
+	return nil, ErrReportNotFound
+}
+
+func (ds *discardStorage) Remove(name string) error {
+	return nil
+}
+
+// heapInUse returns size of live heap objects
+func heapInUse() uint64 {
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+// Test large exports are compressed and encrypted by streaming with bounded memory
+func TestFileHandlerLargeArchiveBoundedMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("large export is skipped in short mode")
+	}
+	const (
+		reportSize = 64 << 20
+		memLimit   = 16 << 20
+	)
+	key := bytes.Repeat([]byte{7}, 32)
+	for _, archive := range []*ArchiveOptions{
+		{Compression: CompressionGzip, Encryption: EncryptionKey},
+		{Compression: CompressionZip, Encryption: EncryptionKey},
+	} {
+		storage := &discardStorage{}
+		fh := NewFileHandler(storage, nil, key)
+		baseline := heapInUse()
+
+		params, err := fh.Create("csv", archive)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		row := make([]byte, 0, 64)
+		for written, id := 0, 0; written < reportSize; id++ {
+			row = strconv.AppendInt(row[:0], int64(id), 10)
+			row = append(row, ',')
+			row = strconv.AppendInt(row, int64(id%977), 10)
+			row = append(row, ".00,deposit,2021-03-01\n"...)
+			n, _ := params.Writer.Write(row)
+			written += n
+			if id%250000 == 0 {
+				if inUse := heapInUse(); inUse > baseline+memLimit {
+					t.Fatalf("[%s] Heap grows with report: %d bytes after %d bytes of report", archive.Compression, inUse-baseline, written)
+				}
+			}
+		}
+		if closeErr := params.Writer.Close(); closeErr != nil {
+			t.Fatalf("Unexpected error: %s", closeErr)
+		}
+		if storage.written == 0 || storage.written >= reportSize {
+			t.Errorf("[%s] Report is not compressed: %d bytes", archive.Compression, storage.written)
+		}
+	}
+}
+
+// Test large encrypted reports are decrypted by streaming with bounded memory
+func TestDecrypterLargeReportBoundedMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("large export is skipped in short mode")
+	}
+	const (
+		reportSize = 256 << 20
+		memLimit   = 16 << 20
+	)
+	key := bytes.Repeat([]byte{7}, 32)
+	r, w := io.Pipe()
+	go func() {
+		encrypter, _ := NewKeyEncrypter(w, key)
+		chunk := bytes.Repeat([]byte{'x'}, 32*1024)
+		for written := 0; written < reportSize; written += len(chunk) {
+			_, _ = encrypter.Write(chunk)
+		}
+		_ = encrypter.Close()
+	}()
+
+	baseline := heapInUse()
+	decrypter, err := NewDecrypter(r, "", key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	buffer := make([]byte, 32*1024)
+	var read int64
+	for {
+		n, readErr := decrypter.Read(buffer)
+		read += int64(n)
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			t.Fatalf("Unexpected error: %s", readErr)
+		}
+		if read%(32<<20) == 0 {
+			if inUse := heapInUse(); inUse > baseline+memLimit {
+				t.Fatalf("Heap grows with report: %d bytes after %d bytes of report", inUse-baseline, read)
+			}
+		}
+	}
+	if read != reportSize {
+		t.Errorf("Expected %d bytes, got %d", reportSize, read)
+	}
+}
@@ -0,0 +1,285 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/entities"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.08"
+	camt053Issuer    = "BILLING"
+
+	camtDateLayout     = "2006-01-02"
+	camtDateTimeLayout = "2006-01-02T15:04:05"
+
+	camtCredit = "CRDT"
+	camtDebit  = "DBIT"
+)
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtGroupHeader struct {
+	XMLName   xml.Name `xml:"GrpHdr"`
+	MessageID string   `xml:"MsgId"`
+	CreatedAt string   `xml:"CreDtTm"`
+}
+
+type camtPeriod struct {
+	XMLName xml.Name `xml:"FrToDt"`
+	From    string   `xml:"FrDtTm"`
+	To      string   `xml:"ToDtTm"`
+}
+
+type camtAccount struct {
+	XMLName  xml.Name `xml:"Acct"`
+	ID       string   `xml:"Id>Othr>Id"`
+	Currency string   `xml:"Ccy"`
+}
+
+type camtBalance struct {
+	XMLName     xml.Name   `xml:"Bal"`
+	Code        string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount      camtAmount `xml:"Amt"`
+	CreditDebit string     `xml:"CdtDbtInd"`
+	Date        string     `xml:"Dt>Dt"`
+}
+
+type camtTotals struct {
+	Count string `xml:"NbOfNtries"`
+	Sum   string `xml:"Sum"`
+}
+
+type camtSummary struct {
+	XMLName xml.Name   `xml:"TxsSummry"`
+	Credits camtTotals `xml:"TtlCdtNtries"`
+	Debits  camtTotals `xml:"TtlDbtNtries"`
+}
+
+type camtReferences struct {
+	ServicerReference string `xml:"AcctSvcrRef"`
+	EndToEndID        string `xml:"EndToEndId"`
+	TransactionID     string `xml:"TxId"`
+}
+
+type camtTransaction struct {
+	References     camtReferences `xml:"Refs"`
+	Amount         camtAmount     `xml:"Amt"`
+	CreditDebit    string         `xml:"CdtDbtInd"`
+	AdditionalInfo string         `xml:"AddtlTxInf,omitempty"`
+}
+
+type camtEntry struct {
+	XMLName           xml.Name        `xml:"Ntry"`
+	Reference         string          `xml:"NtryRef"`
+	Amount            camtAmount      `xml:"Amt"`
+	CreditDebit       string          `xml:"CdtDbtInd"`
+	Status            string          `xml:"Sts>Cd"`
+	BookingDate       string          `xml:"BookgDt>DtTm"`
+	ValueDate         string          `xml:"ValDt>Dt"`
+	ServicerReference string          `xml:"AcctSvcrRef"`
+	TransactionCode   string          `xml:"BkTxCd>Prtry>Cd"`
+	TransactionIssuer string          `xml:"BkTxCd>Prtry>Issr"`
+	Transaction       camtTransaction `xml:"NtryDtls>TxDtls"`
+}
+
+// Camt053Handler implements FileMarshallingManager interface for ISO 20022 camt.053 bank statement.
+// Statement header with balances is written on creation, entries are streamed after it.
+type Camt053Handler struct {
+	encoder   *xml.Encoder
+	mu        *sync.Mutex
+	statement *entities.AccountStatement
+}
+
+// NewCamt053Handler writes document's header with balances of given statement
+func NewCamt053Handler(w io.Writer, mu *sync.Mutex, statement *entities.AccountStatement, createdAt time.Time) (*Camt053Handler, error) {
+	if _, writeErr := io.WriteString(w, xml.Header); writeErr != nil {
+		return nil, fmt.Errorf("error of camt053 header writing: %s", writeErr)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	createdAt = createdAt.UTC()
+	openingDate, closingDate := createdAt, createdAt
+	if !statement.From.IsZero() {
+		openingDate = statement.From
+	}
+	if !statement.To.IsZero() {
+		closingDate = statement.To
+	}
+
+	tokens := []xml.Token{
+		xml.StartElement{Name: xml.Name{Local: "Document"}, Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: camt053Namespace}}},
+		xml.StartElement{Name: xml.Name{Local: "BkToCstmrStmt"}},
+	}
+	for _, token := range tokens {
+		if tokenErr := encoder.EncodeToken(token); tokenErr != nil {
+			return nil, fmt.Errorf("error of camt053 header writing: %s", tokenErr)
+		}
+	}
+
+	header := []interface{}{
+		camtGroupHeader{
+			MessageID: fmt.Sprintf("STMT-%d-%s", statement.WalletID, createdAt.Format("20060102150405")),
+			CreatedAt: createdAt.Format(time.RFC3339),
+		},
+		xml.StartElement{Name: xml.Name{Local: "Stmt"}},
+		struct {
+			XMLName xml.Name `xml:"Id"`
+			Value   string   `xml:",chardata"`
+		}{Value: statementID(statement, createdAt)},
+		struct {
+			XMLName xml.Name `xml:"CreDtTm"`
+			Value   string   `xml:",chardata"`
+		}{Value: createdAt.Format(time.RFC3339)},
+	}
+	if !statement.From.IsZero() && !statement.To.IsZero() {
+		header = append(header, camtPeriod{
+			From: statement.From.Format(camtDateTimeLayout),
+			To:   statement.To.Add(24*time.Hour - time.Second).Format(camtDateTimeLayout),
+		})
+	}
+	header = append(header,
+		camtAccount{
+			ID:       strconv.Itoa(statement.WalletID),
+			Currency: statement.Currency,
+		},
+		newCamtBalance("OPBD", statement.OpeningBalance, statement.Currency, openingDate),
+		newCamtBalance("CLBD", statement.ClosingBalance, statement.Currency, closingDate),
+		camtSummary{
+			Credits: camtTotals{Count: strconv.Itoa(statement.CreditCount), Sum: statement.CreditTotal.StringFixed(2)},
+			Debits:  camtTotals{Count: strconv.Itoa(statement.DebitCount), Sum: statement.DebitTotal.StringFixed(2)},
+		},
+	)
+	for _, element := range header {
+		var encodeErr error
+		if token, isToken := element.(xml.StartElement); isToken {
+			encodeErr = encoder.EncodeToken(token)
+		} else {
+			encodeErr = encoder.Encode(element)
+		}
+		if encodeErr != nil {
+			return nil, fmt.Errorf("error of camt053 header writing: %s", encodeErr)
+		}
+	}
+	if flushErr := encoder.Flush(); flushErr != nil {
+		return nil, fmt.Errorf("error of camt053 header writing: %s", flushErr)
+	}
+
+	return &Camt053Handler{
+		encoder:   encoder,
+		mu:        mu,
+		statement: statement,
+	}, nil
+}
+
+// MarshallRow converts wallet's deposit or withdrawal to statement entry; other operations are skipped
+func (ch *Camt053Handler) MarshallRow(row ReportRow) (*MarshalledResult, error) {
+	operation, rowErr := rowOperation(row, "camt053")
+	if rowErr != nil {
+		return nil, rowErr
+	}
+	sign := statementEntrySign(ch.statement, operation)
+	if sign == 0 {
+		return &MarshalledResult{id: operation.ID}, nil
+	}
+	creditDebit := camtCredit
+	if sign < 0 {
+		creditDebit = camtDebit
+	}
+
+	amount := camtAmount{
+		Currency: ch.statement.Currency,
+		Value:    operation.Amount.StringFixed(2),
+	}
+	reference := operationReference(operation)
+	entry := camtEntry{
+		Reference:         strconv.Itoa(operation.ID),
+		Amount:            amount,
+		CreditDebit:       creditDebit,
+		Status:            "BOOK",
+		BookingDate:       operation.CreatedAt.Format(camtDateTimeLayout),
+		ValueDate:         operation.CreatedAt.Format(camtDateLayout),
+		ServicerReference: reference,
+		TransactionCode:   operation.Operation,
+		TransactionIssuer: camt053Issuer,
+		Transaction: camtTransaction{
+			References: camtReferences{
+				ServicerReference: reference,
+				EndToEndID:        "NOTPROVIDED",
+				TransactionID:     reference,
+			},
+			Amount:         amount,
+			CreditDebit:    creditDebit,
+			AdditionalInfo: transferInfo(operation, sign),
+		},
+	}
+	return &MarshalledResult{
+		id:   operation.ID,
+		data: entry,
+	}, nil
+}
+
+// WriteToFile writes statement entry
+func (ch *Camt053Handler) WriteToFile(mr *MarshalledResult) error {
+	entry, isEntry := mr.data.(camtEntry)
+	if !isEntry {
+		return nil
+	}
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	if encodeErr := ch.encoder.Encode(entry); encodeErr != nil {
+		return fmt.Errorf("error of camt053 entry writing: %s", encodeErr)
+	}
+	return nil
+}
+
+// Close closes statement and document elements
+func (ch *Camt053Handler) Close() error {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	for _, name := range []string{"Stmt", "BkToCstmrStmt", "Document"} {
+		if tokenErr := ch.encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}}); tokenErr != nil {
+			return fmt.Errorf("error of camt053 closing: %s", tokenErr)
+		}
+	}
+	if flushErr := ch.encoder.Flush(); flushErr != nil {
+		return fmt.Errorf("error of camt053 closing: %s", flushErr)
+	}
+	return nil
+}
+
+// newCamtBalance returns balance with credit/debit indicator instead of sign
+func newCamtBalance(code string, value decimal.Decimal, currency string, date time.Time) camtBalance {
+	creditDebit := camtCredit
+	if value.IsNegative() {
+		creditDebit = camtDebit
+	}
+	return camtBalance{
+		Code:        code,
+		Amount:      camtAmount{Currency: currency, Value: value.Abs().StringFixed(2)},
+		CreditDebit: creditDebit,
+		Date:        date.Format(camtDateLayout),
+	}
+}
+
+// statementID returns identifier of the statement (wallet and period)
+func statementID(statement *entities.AccountStatement, createdAt time.Time) string {
+	from, to := "START", createdAt.Format(camtDateLayout)
+	if !statement.From.IsZero() {
+		from = statement.From.Format(camtDateLayout)
+	}
+	if !statement.To.IsZero() {
+		to = statement.To.Format(camtDateLayout)
+	}
+	return fmt.Sprintf("%d-%s-%s", statement.WalletID, from, to)
+}
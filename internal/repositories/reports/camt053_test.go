@@ -0,0 +1,123 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/repositories"
+	"bytes"
+	"database/sql"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var camtStatement = &entities.AccountStatement{
+	WalletID:       1,
+	Currency:       "USD",
+	From:           time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC),
+	To:             time.Date(2021, time.March, 31, 0, 0, 0, 0, time.UTC),
+	OpeningBalance: decimal.NewFromInt(-5),
+	ClosingBalance: decimal.NewFromFloat(15.5),
+	CreditCount:    1,
+	CreditTotal:    decimal.NewFromFloat(30.5),
+	DebitCount:     1,
+	DebitTotal:     decimal.NewFromInt(10),
+}
+
+var camtOperations = []*entities.WalletOperation{
+	{ID: 1, Operation: repositories.Create, WalletTo: 1, Amount: decimal.Zero, CreatedAt: time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC)},
+	{ID: 2, Operation: repositories.Deposit, WalletTo: 1, Amount: decimal.NewFromFloat(30.5), CreatedAt: time.Date(2021, time.March, 2, 11, 30, 0, 0, time.UTC)},
+	{ID: 3, Operation: repositories.Withdrawal, WalletFrom: sql.NullInt32{Int32: 2, Valid: true}, WalletTo: 1, Amount: decimal.NewFromInt(10), CreatedAt: time.Date(2021, time.March, 3, 12, 0, 0, 0, time.UTC)},
+	{ID: 4, Operation: repositories.Deposit, WalletFrom: sql.NullInt32{Int32: 1, Valid: true}, WalletTo: 2, Amount: decimal.NewFromInt(10), CreatedAt: time.Date(2021, time.March, 3, 12, 0, 0, 0, time.UTC)},
+}
+
+// writeCamt053 writes statement with given operations
+func writeCamt053(t *testing.T, statement *entities.AccountStatement) []byte {
+	buf := &bytes.Buffer{}
+	handler, handlerErr := NewCamt053Handler(buf, &sync.Mutex{}, statement, time.Date(2021, time.April, 1, 8, 0, 0, 0, time.UTC))
+	if handlerErr != nil {
+		t.Fatalf("unexpected error: %s", handlerErr)
+	}
+	for _, operation := range camtOperations {
+		mr, marshallErr := handler.MarshallRow(OperationRow{operation})
+		if marshallErr != nil {
+			t.Fatalf("unexpected error: %s", marshallErr)
+		}
+		if writeErr := handler.WriteToFile(mr); writeErr != nil {
+			t.Fatalf("unexpected error: %s", writeErr)
+		}
+	}
+	if closeErr := handler.Close(); closeErr != nil {
+		t.Fatalf("unexpected error: %s", closeErr)
+	}
+	return buf.Bytes()
+}
+
+// Test camt053 entries, balances and references
+func TestCamt053HandlerStatement(t *testing.T) {
+	document := string(writeCamt053(t, camtStatement))
+	expected := []string{
+		`<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08">`,
+		"<Id>1-2021-03-01-2021-03-31</Id>",
+		"<FrDtTm>2021-03-01T00:00:00</FrDtTm>",
+		"<ToDtTm>2021-03-31T23:59:59</ToDtTm>",
+		"<Cd>OPBD</Cd>",
+		`<Amt Ccy="USD">5.00</Amt>`,
+		"<CdtDbtInd>DBIT</CdtDbtInd>",
+		"<Cd>CLBD</Cd>",
+		`<Amt Ccy="USD">15.50</Amt>`,
+		"<NtryRef>2</NtryRef>",
+		`<Amt Ccy="USD">30.50</Amt>`,
+		"<AcctSvcrRef>OP-3</AcctSvcrRef>",
+		"<AddtlTxInf>Transfer to wallet 2</AddtlTxInf>",
+	}
+	for _, value := range expected {
+		if !strings.Contains(document, value) {
+			t.Errorf("Expected %s in statement:\n%s", value, document)
+		}
+	}
+	for _, skipped := range []string{"<NtryRef>1</NtryRef>", "<NtryRef>4</NtryRef>"} {
+		if strings.Contains(document, skipped) {
+			t.Errorf("Unexpected entry %s in statement", skipped)
+		}
+	}
+	if strings.Count(document, "<Ntry>") != 2 {
+		t.Errorf("Expected 2 entries, got %d", strings.Count(document, "<Ntry>"))
+	}
+}
+
+// Test camt053 document validation against ISO 20022 schema
+func TestCamt053HandlerSchemaValidation(t *testing.T) {
+	xmllint, lookErr := exec.LookPath("xmllint")
+	if lookErr != nil {
+		t.Skip("xmllint is not installed")
+	}
+	statements := map[string]*entities.AccountStatement{
+		"period":     camtStatement,
+		"all_time":   {WalletID: 1, Currency: "EUR", ClosingBalance: decimal.NewFromFloat(20.5)},
+		"no_entries": {WalletID: 7, Currency: "USD", From: camtStatement.From},
+	}
+	for name, statement := range statements {
+		path := filepath.Join(t.TempDir(), name+".xml")
+		if writeErr := os.WriteFile(path, writeCamt053(t, statement), 0666); writeErr != nil {
+			t.Fatalf("unexpected error: %s", writeErr)
+		}
+		output, lintErr := exec.Command(xmllint, "--noout", "--schema", "testdata/camt.053.001.08.xsd", path).CombinedOutput()
+		if lintErr != nil {
+			t.Errorf("[%s] statement is not valid: %s\n%s", name, lintErr, output)
+		}
+	}
+}
+
+// Test camt053 rejects summary rows
+func TestCamt053HandlerMarshallRowKinds(t *testing.T) {
+	handler, _ := NewCamt053Handler(&bytes.Buffer{}, &sync.Mutex{}, camtStatement, time.Now())
+	if _, marshallErr := handler.MarshallRow(SummaryRow{&entities.OperationSummary{}}); marshallErr == nil {
+		t.Error("Expected error, got nil")
+	}
+}
@@ -0,0 +1,384 @@
+package reports
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Modes of report's encryption key derivation
+const (
+	EncryptionPassphrase = "passphrase"
+	EncryptionKey        = "key"
+)
+
+// Envelope of encrypted report:
+//
+//	header: magic "BSRENC", version, mode, PBKDF2 iterations, salt, key id, nonce prefix, chunk size
+//	chunks: uint32 length with final flag in the highest bit, AES-256-GCM sealed chunk
+//
+// Chunk's nonce is nonce prefix, chunk's number and final flag, so chunks can not be reordered,
+// and truncation is detected by missing final chunk. Header is authenticated as additional data.
+const (
+	envelopeMagic        = "BSRENC"
+	envelopeVersion      = 1
+	envelopeHeaderSize   = len(envelopeMagic) + 1 + 1 + 4 + envelopeSaltSize + 8 + envelopeNoncePrefix + 4
+	envelopeSaltSize     = 16
+	envelopeNoncePrefix  = 7
+	envelopeChunkSize    = 64 * 1024
+	envelopeFinalFlag    = 1 << 31
+	envelopeMaxChunkSize = 1 << 24
+	modePassphrase       = 1
+	modeKey              = 2
+	passphraseIterations = 600000
+	maxIterations        = 10000000
+	encryptionKeySize    = 32
+)
+
+// ErrDecryption is returned when report was modified or decryption key is wrong
+var ErrDecryption = errors.New("report is corrupted or decryption key is wrong")
+
+// ParseEncryptionKey decodes base64 master key of reports encryption; empty value means encryption with key is disabled
+func ParseEncryptionKey(encodedKey string) ([]byte, error) {
+	if encodedKey == "" {
+		return nil, nil
+	}
+	key, decodeErr := base64.StdEncoding.DecodeString(encodedKey)
+	if decodeErr != nil {
+		return nil, fmt.Errorf("error of encryption key decoding: %s", decodeErr)
+	}
+	if len(key) != encryptionKeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", encryptionKeySize, len(key))
+	}
+	return key, nil
+}
+
+type envelopeHeader struct {
+	mode        byte
+	iterations  uint32
+	salt        []byte
+	keyID       []byte
+	noncePrefix []byte
+	chunkSize   uint32
+}
+
+func (eh envelopeHeader) marshal() []byte {
+	header := make([]byte, 0, envelopeHeaderSize)
+	header = append(header, envelopeMagic...)
+	header = append(header, envelopeVersion, eh.mode)
+	header = append(header, uint32Bytes(eh.iterations)...)
+	header = append(header, eh.salt...)
+	header = append(header, eh.keyID...)
+	header = append(header, eh.noncePrefix...)
+	return append(header, uint32Bytes(eh.chunkSize)...)
+}
+
+func unmarshalEnvelopeHeader(data []byte) (*envelopeHeader, error) {
+	if !bytes.HasPrefix(data, []byte(envelopeMagic)) {
+		return nil, fmt.Errorf("report is not encrypted")
+	}
+	data = data[len(envelopeMagic):]
+	if data[0] != envelopeVersion {
+		return nil, fmt.Errorf("unsupported version of encrypted report: %d", data[0])
+	}
+	header := &envelopeHeader{mode: data[1]}
+	data = data[2:]
+	header.iterations, data = binary.BigEndian.Uint32(data), data[4:]
+	header.salt, data = data[:envelopeSaltSize], data[envelopeSaltSize:]
+	header.keyID, data = data[:8], data[8:]
+	header.noncePrefix, data = data[:envelopeNoncePrefix], data[envelopeNoncePrefix:]
+	header.chunkSize = binary.BigEndian.Uint32(data)
+	if header.chunkSize == 0 || header.chunkSize > envelopeMaxChunkSize {
+		return nil, fmt.Errorf("invalid chunk size of encrypted report: %d", header.chunkSize)
+	}
+	return header, nil
+}
+
+// NewPassphraseEncrypter returns writer which encrypts report with key derived from passphrase by PBKDF2-HMAC-SHA256
+func NewPassphraseEncrypter(w io.WriteCloser, passphrase string) (io.WriteCloser, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase of report encryption is empty")
+	}
+	header, headerErr := newEnvelopeHeader(modePassphrase)
+	if headerErr != nil {
+		return nil, headerErr
+	}
+	header.iterations = passphraseIterations
+	key := pbkdf2.Key([]byte(passphrase), header.salt, int(header.iterations), encryptionKeySize, sha256.New)
+	return newEncryptWriter(w, key, header)
+}
+
+// NewKeyEncrypter returns writer which encrypts report with key derived from master key and random salt by HKDF-SHA256
+func NewKeyEncrypter(w io.WriteCloser, masterKey []byte) (io.WriteCloser, error) {
+	if len(masterKey) != encryptionKeySize {
+		return nil, fmt.Errorf("encryption key is not configured")
+	}
+	header, headerErr := newEnvelopeHeader(modeKey)
+	if headerErr != nil {
+		return nil, headerErr
+	}
+	header.keyID = encryptionKeyID(masterKey)
+	key, keyErr := hkdfSHA256(masterKey, header.salt)
+	if keyErr != nil {
+		return nil, keyErr
+	}
+	return newEncryptWriter(w, key, header)
+}
+
+func newEnvelopeHeader(mode byte) (*envelopeHeader, error) {
+	header := &envelopeHeader{
+		mode:        mode,
+		salt:        make([]byte, envelopeSaltSize),
+		keyID:       make([]byte, 8),
+		noncePrefix: make([]byte, envelopeNoncePrefix),
+		chunkSize:   envelopeChunkSize,
+	}
+	if _, randErr := rand.Read(header.salt); randErr != nil {
+		return nil, fmt.Errorf("error of salt generation: %s", randErr)
+	}
+	if _, randErr := rand.Read(header.noncePrefix); randErr != nil {
+		return nil, fmt.Errorf("error of nonce generation: %s", randErr)
+	}
+	return header, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, blockErr := aes.NewCipher(key)
+	if blockErr != nil {
+		return nil, fmt.Errorf("error of cipher creation: %s", blockErr)
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce returns nonce of chunk with given number
+func chunkNonce(prefix []byte, counter uint32, final bool) []byte {
+	nonce := make([]byte, 0, 12)
+	nonce = append(nonce, prefix...)
+	nonce = append(nonce, uint32Bytes(counter)...)
+	if final {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
+
+// encryptWriter seals report by chunks; at most one chunk is buffered
+type encryptWriter struct {
+	w       io.WriteCloser
+	aead    cipher.AEAD
+	header  *envelopeHeader
+	aad     []byte
+	buffer  []byte
+	sealed  []byte
+	counter uint32
+	started bool
+}
+
+func newEncryptWriter(w io.WriteCloser, key []byte, header *envelopeHeader) (*encryptWriter, error) {
+	aead, aeadErr := newAEAD(key)
+	if aeadErr != nil {
+		return nil, aeadErr
+	}
+	return &encryptWriter{
+		w:      w,
+		aead:   aead,
+		header: header,
+		aad:    header.marshal(),
+		buffer: make([]byte, 0, header.chunkSize),
+	}, nil
+}
+
+func (ew *encryptWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		// Full chunk is sealed only when more data follows, so the last chunk is always marked as final
+		if len(ew.buffer) == cap(ew.buffer) {
+			if sealErr := ew.seal(false); sealErr != nil {
+				return written, sealErr
+			}
+		}
+		n := copy(ew.buffer[len(ew.buffer):cap(ew.buffer)], p)
+		ew.buffer = ew.buffer[:len(ew.buffer)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (ew *encryptWriter) seal(final bool) error {
+	if !ew.started {
+		if _, writeErr := ew.w.Write(ew.aad); writeErr != nil {
+			return writeErr
+		}
+		ew.started = true
+	}
+	ew.sealed = ew.aead.Seal(ew.sealed[:0], chunkNonce(ew.header.noncePrefix, ew.counter, final), ew.buffer, ew.aad)
+	length := uint32(len(ew.sealed))
+	if final {
+		length |= envelopeFinalFlag
+	}
+	if _, writeErr := ew.w.Write(uint32Bytes(length)); writeErr != nil {
+		return writeErr
+	}
+	if _, writeErr := ew.w.Write(ew.sealed); writeErr != nil {
+		return writeErr
+	}
+	ew.buffer = ew.buffer[:0]
+	ew.counter++
+	return nil
+}
+
+// Close seals the final chunk and closes underlying writer
+func (ew *encryptWriter) Close() error {
+	if sealErr := ew.seal(true); sealErr != nil {
+		ew.w.Close()
+		return fmt.Errorf("error of report encryption: %s", sealErr)
+	}
+	return ew.w.Close()
+}
+
+// NewDecrypter returns reader of decrypted report; passphrase or master key is used according to envelope's mode
+func NewDecrypter(r io.Reader, passphrase string, masterKey []byte) (io.Reader, error) {
+	data := make([]byte, envelopeHeaderSize)
+	if _, readErr := io.ReadFull(r, data); readErr != nil {
+		return nil, fmt.Errorf("error of encrypted report header reading: %s", readErr)
+	}
+	header, headerErr := unmarshalEnvelopeHeader(data)
+	if headerErr != nil {
+		return nil, headerErr
+	}
+
+	var key []byte
+	switch header.mode {
+	case modePassphrase:
+		if passphrase == "" {
+			return nil, fmt.Errorf("report is encrypted with passphrase")
+		}
+		if header.iterations == 0 || header.iterations > maxIterations {
+			return nil, fmt.Errorf("invalid iterations count of encrypted report: %d", header.iterations)
+		}
+		key = pbkdf2.Key([]byte(passphrase), header.salt, int(header.iterations), encryptionKeySize, sha256.New)
+	case modeKey:
+		if len(masterKey) != encryptionKeySize {
+			return nil, fmt.Errorf("report is encrypted with key %s", hex.EncodeToString(header.keyID))
+		}
+		if !bytes.Equal(header.keyID, encryptionKeyID(masterKey)) {
+			return nil, fmt.Errorf("report is encrypted with key %s, given key is %s", hex.EncodeToString(header.keyID), hex.EncodeToString(encryptionKeyID(masterKey)))
+		}
+		var keyErr error
+		if key, keyErr = hkdfSHA256(masterKey, header.salt); keyErr != nil {
+			return nil, keyErr
+		}
+	default:
+		return nil, fmt.Errorf("unsupported mode of encrypted report: %d", header.mode)
+	}
+
+	aead, aeadErr := newAEAD(key)
+	if aeadErr != nil {
+		return nil, aeadErr
+	}
+	return &decryptReader{
+		r:      r,
+		aead:   aead,
+		header: header,
+		aad:    data,
+		sealed: make([]byte, 0, int(header.chunkSize)+aead.Overhead()),
+	}, nil
+}
+
+// decryptReader opens report by chunks; at most one chunk is buffered
+type decryptReader struct {
+	r       io.Reader
+	aead    cipher.AEAD
+	header  *envelopeHeader
+	aad     []byte
+	sealed  []byte
+	chunk   []byte
+	counter uint32
+	final   bool
+}
+
+func (dr *decryptReader) Read(p []byte) (int, error) {
+	for len(dr.chunk) == 0 {
+		if dr.final {
+			return 0, io.EOF
+		}
+		if openErr := dr.open(); openErr != nil {
+			return 0, openErr
+		}
+	}
+	n := copy(p, dr.chunk)
+	dr.chunk = dr.chunk[n:]
+	return n, nil
+}
+
+func (dr *decryptReader) open() error {
+	lengthData := make([]byte, 4)
+	if _, readErr := io.ReadFull(dr.r, lengthData); readErr != nil {
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			return ErrDecryption
+		}
+		return readErr
+	}
+	length := binary.BigEndian.Uint32(lengthData)
+	final := length&envelopeFinalFlag != 0
+	length &^= envelopeFinalFlag
+	if int(length) > cap(dr.sealed) {
+		return ErrDecryption
+	}
+	dr.sealed = dr.sealed[:length]
+	if _, readErr := io.ReadFull(dr.r, dr.sealed); readErr != nil {
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			return ErrDecryption
+		}
+		return readErr
+	}
+	chunk, openErr := dr.aead.Open(dr.sealed[:0], chunkNonce(dr.header.noncePrefix, dr.counter, final), dr.sealed, dr.aad)
+	if openErr != nil {
+		return ErrDecryption
+	}
+	if final {
+		// Bytes after the final chunk are not authenticated, so the report is rejected
+		if _, readErr := io.ReadFull(dr.r, make([]byte, 1)); readErr != io.EOF {
+			if readErr == nil {
+				return ErrDecryption
+			}
+			return readErr
+		}
+	}
+	dr.chunk = chunk
+	dr.counter++
+	dr.final = final
+	return nil
+}
+
+func uint32Bytes(value uint32) []byte {
+	data := make([]byte, 4)
+	binary.BigEndian.PutUint32(data, value)
+	return data
+}
+
+// encryptionKeyID returns first 8 bytes of master key's SHA-256
+func encryptionKeyID(masterKey []byte) []byte {
+	sum := sha256.Sum256(masterKey)
+	return sum[:8]
+}
+
+// hkdfSHA256 derives 32 bytes key from master key and salt (RFC 5869)
+func hkdfSHA256(masterKey, salt []byte) ([]byte, error) {
+	key := make([]byte, encryptionKeySize)
+	if _, readErr := io.ReadFull(hkdf.New(sha256.New, masterKey, salt, []byte("billing report encryption")), key); readErr != nil {
+		return nil, fmt.Errorf("error of encryption key derivation: %s", readErr)
+	}
+	return key, nil
+}
@@ -0,0 +1,162 @@
+package reports
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// nopWriteCloser adds Close method to writer
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nwc nopWriteCloser) Close() error {
+	return nil
+}
+
+// encryptReport returns report encrypted with passphrase or master key
+func encryptReport(t *testing.T, data []byte, passphrase string, key []byte) []byte {
+	var (
+		encrypted = &bytes.Buffer{}
+		w         io.WriteCloser
+		err       error
+	)
+	if passphrase != "" {
+		w, err = NewPassphraseEncrypter(nopWriteCloser{encrypted}, passphrase)
+	} else {
+		w, err = NewKeyEncrypter(nopWriteCloser{encrypted}, key)
+	}
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	// Odd sizes of writes cross chunks' boundaries
+	for len(data) > 0 {
+		n := 1000
+		if n > len(data) {
+			n = len(data)
+		}
+		_, _ = w.Write(data[:n])
+		data = data[n:]
+	}
+	if closeErr := w.Close(); closeErr != nil {
+		t.Fatalf("Unexpected error: %s", closeErr)
+	}
+	return encrypted.Bytes()
+}
+
+// Test encryption and decryption of reports with passphrase and master key
+func TestEncryptionRoundTrip(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	sizes := []int{0, 1, envelopeChunkSize, envelopeChunkSize + 1, 3*envelopeChunkSize + 17}
+	for _, size := range sizes {
+		data := bytes.Repeat([]byte("id,amount\n"), size/10+1)[:size]
+
+		encrypted := encryptReport(t, data, "", key)
+		if bytes.Contains(encrypted, []byte("id,amount")) {
+			t.Errorf("[%d] Report is not encrypted", size)
+		}
+		decrypter, err := NewDecrypter(bytes.NewReader(encrypted), "", key)
+		if err != nil {
+			t.Fatalf("[%d] Unexpected error: %s", size, err)
+		}
+		decrypted, readErr := ioutil.ReadAll(decrypter)
+		if readErr != nil || !bytes.Equal(decrypted, data) {
+			t.Errorf("[%d] Wrong decrypted report (%v): %d bytes", size, readErr, len(decrypted))
+		}
+	}
+
+	encrypted := encryptReport(t, []byte("id,amount\n1,10.00\n"), "correct horse", nil)
+	decrypter, err := NewDecrypter(bytes.NewReader(encrypted), "correct horse", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if decrypted, _ := ioutil.ReadAll(decrypter); string(decrypted) != "id,amount\n1,10.00\n" {
+		t.Errorf("Wrong decrypted report: %q", decrypted)
+	}
+}
+
+// Test modified, truncated and wrongly keyed reports are not decrypted
+func TestDecryptionFailures(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	data := bytes.Repeat([]byte("1,10.00\n"), envelopeChunkSize/4)
+	encrypted := encryptReport(t, data, "", key)
+	passphraseEncrypted := encryptReport(t, data, "secret", nil)
+
+	modified := append([]byte{}, encrypted...)
+	modified[len(modified)-1] ^= 1
+	modifiedHeader := append([]byte{}, encrypted...)
+	modifiedHeader[envelopeHeaderSize-1] ^= 1
+	// Final chunk is removed, so the report ends on chunk boundary
+	truncated := encrypted[:envelopeHeaderSize+4+envelopeChunkSize+16]
+	trailing := append(append([]byte{}, encrypted...), 0)
+
+	tests := []struct {
+		name       string
+		data       []byte
+		passphrase string
+		key        []byte
+		err        string
+	}{
+		{name: "modified chunk", data: modified, key: key, err: ErrDecryption.Error()},
+		{name: "modified header", data: modifiedHeader, key: key, err: ErrDecryption.Error()},
+		{name: "truncated", data: truncated, key: key, err: ErrDecryption.Error()},
+		{name: "trailing bytes", data: trailing, key: key, err: ErrDecryption.Error()},
+		{name: "wrong key", data: encrypted, key: bytes.Repeat([]byte{8}, 32), err: "report is encrypted with key"},
+		{name: "missing key", data: encrypted, passphrase: "secret", err: "report is encrypted with key " + hex.EncodeToString(encryptionKeyID(key))},
+		{name: "wrong passphrase", data: passphraseEncrypted, passphrase: "guess", err: ErrDecryption.Error()},
+		{name: "missing passphrase", data: passphraseEncrypted, key: key, err: "report is encrypted with passphrase"},
+		{name: "not encrypted", data: bytes.Repeat([]byte("id,amount\n"), 10), key: key, err: "report is not encrypted"},
+	}
+	for _, tc := range tests {
+		decrypter, err := NewDecrypter(bytes.NewReader(tc.data), tc.passphrase, tc.key)
+		if err == nil {
+			_, err = ioutil.ReadAll(decrypter)
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("[%s] Expected error '%s', got %v", tc.name, tc.err, err)
+		}
+	}
+}
+
+// Test decoding of master key
+func TestParseEncryptionKey(t *testing.T) {
+	key, err := ParseEncryptionKey(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)))
+	if err != nil || len(key) != 32 {
+		t.Errorf("Wrong key %v: %v", key, err)
+	}
+	if key, err = ParseEncryptionKey(""); key != nil || err != nil {
+		t.Errorf("Expected empty key, got %v %v", key, err)
+	}
+	if _, err = ParseEncryptionKey("AQID"); err == nil {
+		t.Errorf("Expected error of short key, got nil")
+	}
+	if _, err = NewKeyEncrypter(nopWriteCloser{ioutil.Discard}, nil); err == nil {
+		t.Errorf("Expected error of missing key, got nil")
+	}
+}
+
+// Test key derivation from master key is compatible with reports encrypted before
+func TestHKDFSHA256(t *testing.T) {
+	expected := "a391ece38008b8045c7c5b031bd1ff180f0fdf420f4fcd34ce13a34cf042e0e7"
+	key, err := hkdfSHA256(bytes.Repeat([]byte{7}, 32), bytes.Repeat([]byte{1}, 16))
+	if err != nil || hex.EncodeToString(key) != expected {
+		t.Errorf("Wrong derived key %x: %v", key, err)
+	}
+}
+
+// Test key derivation against PBKDF2-HMAC-SHA256 test vector of RFC 7914
+func TestPBKDF2SHA256(t *testing.T) {
+	expected := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+		"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+	if actual := hex.EncodeToString(pbkdf2.Key([]byte("passwd"), []byte("salt"), 1, 64, sha256.New)); actual != expected {
+		t.Errorf("Wrong derived key: %s", actual)
+	}
+}
@@ -0,0 +1,167 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/adapters/tx"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Exports of entities besides wallet operations
+const (
+	ExportUsers    = "users"
+	ExportBalances = "balances"
+)
+
+// ExportColumns contains available columns of exports
+var ExportColumns = map[string][]string{
+	ExportUsers:    {"id", "email", "wallet_id", "currency", "balance"},
+	ExportBalances: {"currency", "wallets", "total", "as_of"},
+}
+
+// SQL queries of exports
+var exportQueries = map[string]string{
+	ExportUsers: "select u.id, u.email, w.id, w.currency, w.balance from users u " +
+		"left join wallets w on w.user_id = u.id order by u.id, w.id",
+	ExportBalances: "select currency, count(*), coalesce(sum(balance), 0), now() from wallets " +
+		"group by currency order by currency",
+}
+
+// UserRow represents row of users export; user without wallet has NULL wallet's columns
+type UserRow struct {
+	ID       int
+	Email    string
+	WalletID sql.NullInt32
+	Currency sql.NullString
+	Balance  decimal.NullDecimal
+}
+
+// Kind returns kind of users export rows
+func (ur UserRow) Kind() string {
+	return ExportUsers
+}
+
+// RowID returns user's id
+func (ur UserRow) RowID() int {
+	return ur.ID
+}
+
+// Value returns value of user's column
+func (ur UserRow) Value(column string) interface{} {
+	switch column {
+	case "id":
+		return ur.ID
+	case "email":
+		return ur.Email
+	case "wallet_id":
+		if !ur.WalletID.Valid {
+			return nil
+		}
+		return int(ur.WalletID.Int32)
+	case "currency":
+		if !ur.Currency.Valid {
+			return nil
+		}
+		return ur.Currency.String
+	case "balance":
+		if !ur.Balance.Valid {
+			return nil
+		}
+		return ur.Balance.Decimal
+	}
+	return nil
+}
+
+// BalanceRow represents balance sheet row of a currency
+type BalanceRow struct {
+	Currency string
+	Wallets  int
+	Total    decimal.Decimal
+	AsOf     time.Time
+}
+
+// Kind returns kind of balances export rows
+func (br BalanceRow) Kind() string {
+	return ExportBalances
+}
+
+// RowID returns zero, balance rows have no identifiers
+func (br BalanceRow) RowID() int {
+	return 0
+}
+
+// Value returns value of balance's column
+func (br BalanceRow) Value(column string) interface{} {
+	switch column {
+	case "currency":
+		return br.Currency
+	case "wallets":
+		return br.Wallets
+	case "total":
+		return br.Total
+	case "as_of":
+		return br.AsOf
+	}
+	return nil
+}
+
+// ExportManager defines contracts for reading rows of exported entities
+type ExportManager interface {
+	Rows(ctx context.Context, export string, emit func(row ReportRow) error) error
+}
+
+// ExportService implements ExportManager interface
+type ExportService struct {
+	db tx.SQLQueryAdapter
+}
+
+// NewExportService returns new instance of ExportService
+func NewExportService(db tx.SQLQueryAdapter) *ExportService {
+	return &ExportService{
+		db: db,
+	}
+}
+
+// Rows reads rows of given export from database and emits them one by one
+func (es ExportService) Rows(ctx context.Context, export string, emit func(row ReportRow) error) error {
+	query, exists := exportQueries[export]
+	if !exists {
+		return fmt.Errorf("unknown export: %s", export)
+	}
+	tag := strings.ToUpper(export) + "_EXPORT"
+	rows, queryErr := es.db.QueryContext(ctx, query)
+	if queryErr != nil {
+		return fmt.Errorf("[%s]: %s", tag, queryErr)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		row, scanErr := scanExportRow(export, rows)
+		if scanErr != nil {
+			return fmt.Errorf("[%s_ROW]: %s", tag, scanErr)
+		}
+		if emitErr := emit(row); emitErr != nil {
+			return emitErr
+		}
+	}
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return fmt.Errorf("[%s]: %s", tag, rowsErr)
+	}
+	return nil
+}
+
+// scanExportRow scans current database row of given export
+func scanExportRow(export string, rows *sql.Rows) (ReportRow, error) {
+	if export == ExportBalances {
+		row := BalanceRow{}
+		err := rows.Scan(&row.Currency, &row.Wallets, &row.Total, &row.AsOf)
+		return row, err
+	}
+	row := UserRow{}
+	err := rows.Scan(&row.ID, &row.Email, &row.WalletID, &row.Currency, &row.Balance)
+	return row, err
+}
@@ -0,0 +1,48 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repositories/reports/export.go
+
+// Package reports is a generated GoMock package.
+package reports
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockExportManager is a mock of ExportManager interface
+type MockExportManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockExportManagerMockRecorder
+}
+
+// MockExportManagerMockRecorder is the mock recorder for MockExportManager
+type MockExportManagerMockRecorder struct {
+	mock *MockExportManager
+}
+
+// NewMockExportManager creates a new mock instance
+func NewMockExportManager(ctrl *gomock.Controller) *MockExportManager {
+	mock := &MockExportManager{ctrl: ctrl}
+	mock.recorder = &MockExportManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockExportManager) EXPECT() *MockExportManagerMockRecorder {
+	return m.recorder
+}
+
+// Rows mocks base method
+func (m *MockExportManager) Rows(ctx context.Context, export string, emit func(ReportRow) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rows", ctx, export, emit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Rows indicates an expected call of Rows
+func (mr *MockExportManagerMockRecorder) Rows(ctx, export, emit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rows", reflect.TypeOf((*MockExportManager)(nil).Rows), ctx, export, emit)
+}
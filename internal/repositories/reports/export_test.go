@@ -0,0 +1,114 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+)
+
+// Test streaming of users export, users without wallets have NULL wallet's columns
+func TestExportServiceUsers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "email", "wallet_id", "currency", "balance"}).
+		AddRow(1, "user@example.com", 5, "USD", "10.50").
+		AddRow(2, "new@example.com", nil, nil, nil)
+	mock.ExpectQuery(regexp.QuoteMeta("select u.id, u.email, w.id, w.currency, w.balance from users u")).
+		WillReturnRows(rows)
+
+	emitted := []ReportRow{}
+	rowsErr := NewExportService(db).Rows(context.Background(), ExportUsers, func(row ReportRow) error {
+		emitted = append(emitted, row)
+		return nil
+	})
+	if rowsErr != nil {
+		t.Fatalf("Unexpected error: %s", rowsErr)
+	}
+	if len(emitted) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(emitted))
+	}
+	first := emitted[0]
+	if first.RowID() != 1 || first.Value("wallet_id") != 5 || !first.Value("balance").(decimal.Decimal).Equal(decimal.RequireFromString("10.5")) {
+		t.Errorf("Wrong user row: %+v", first)
+	}
+	second := emitted[1]
+	if second.Value("email") != "new@example.com" || second.Value("wallet_id") != nil || second.Value("currency") != nil || second.Value("balance") != nil {
+		t.Errorf("Wrong user row without wallet: %+v", second)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+// Test reading of balance sheet per currency
+func TestExportServiceBalances(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+
+	asOf := time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"currency", "count", "sum", "now"}).
+		AddRow("EUR", 1, "5.00", asOf).
+		AddRow("USD", 3, "150.50", asOf)
+	mock.ExpectQuery(regexp.QuoteMeta("select currency, count(*), coalesce(sum(balance), 0), now() from wallets group by currency")).
+		WillReturnRows(rows)
+
+	emitted := []ReportRow{}
+	rowsErr := NewExportService(db).Rows(context.Background(), ExportBalances, func(row ReportRow) error {
+		emitted = append(emitted, row)
+		return nil
+	})
+	if rowsErr != nil {
+		t.Fatalf("Unexpected error: %s", rowsErr)
+	}
+	if len(emitted) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(emitted))
+	}
+	usd := emitted[1]
+	if usd.Value("currency") != "USD" || usd.Value("wallets") != 3 || !usd.Value("total").(decimal.Decimal).Equal(decimal.RequireFromString("150.5")) || !usd.Value("as_of").(time.Time).Equal(asOf) {
+		t.Errorf("Wrong balance row: %+v", usd)
+	}
+}
+
+// Test failed exports reading
+func TestFailedExportServiceRows(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+	es := NewExportService(db)
+	emit := func(row ReportRow) error {
+		return fmt.Errorf("emit error")
+	}
+
+	if rowsErr := es.Rows(context.Background(), "holds", emit); rowsErr == nil || rowsErr.Error() != "unknown export: holds" {
+		t.Errorf("Expected unknown export error, got %v", rowsErr)
+	}
+
+	mock.ExpectQuery("select").WillReturnError(fmt.Errorf("connection refused"))
+	if rowsErr := es.Rows(context.Background(), ExportUsers, emit); rowsErr == nil || rowsErr.Error() != "[USERS_EXPORT]: connection refused" {
+		t.Errorf("Expected query error, got %v", rowsErr)
+	}
+
+	mock.ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"currency", "count", "sum", "now"}).AddRow("USD", "many", "1", time.Now()))
+	if rowsErr := es.Rows(context.Background(), ExportBalances, emit); rowsErr == nil || !regexp.MustCompile(`^\[BALANCES_EXPORT_ROW\]`).MatchString(rowsErr.Error()) {
+		t.Errorf("Expected scan error, got %v", rowsErr)
+	}
+
+	mock.ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"id", "email", "wallet_id", "currency", "balance"}).AddRow(1, "user@example.com", nil, nil, nil))
+	if rowsErr := es.Rows(context.Background(), ExportUsers, emit); rowsErr == nil || rowsErr.Error() != "emit error" {
+		t.Errorf("Expected emit error, got %v", rowsErr)
+	}
+}
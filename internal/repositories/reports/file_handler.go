@@ -2,131 +2,248 @@ package reports
 
 import (
 	"billing_system_test_task/internal/entities"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
-	"os"
 	"path/filepath"
-	"runtime"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
-type FileWithMetadata interface {
-	Read(p []byte) (n int, err error)
-	Stat() (os.FileInfo, error)
-}
-
 // FileHandlingManager represents interface for file handler
 type FileHandlingManager interface {
-	Create(format string) (*entities.FileParams, error)
-	CreateMarshaller(file *os.File, format string, csvWriter CSVWriter) (FileMarshallingManager, error)
-	GetFileMetadata(file FileWithMetadata) (*entities.Metadata, error)
+	Create(format string, archive *ArchiveOptions) (*entities.FileParams, error)
+	CreateMarshaller(file io.Writer, format string, csvWriter CSVWriter, options *FormatOptions) (FileMarshallingManager, error)
+	Open(name string) (entities.ReportFile, error)
+	Remove(name string) error
+	GetFileMetadata(file entities.ReportFile, format string) (*entities.Metadata, error)
+	SignManifest(manifest *entities.ReportManifest) ([]byte, []byte, error)
 }
 
-// FileStorageManager represents interface for file storage
-type FileStorageManager interface {
-	Create(path string, flag int, perm os.FileMode) (*os.File, error)
+// Content types of the supported report formats
+var reportContentTypes = map[string]string{
+	"json":     "application/json",
+	"ndjson":   "application/x-ndjson",
+	"csv":      "text/csv; charset=utf-8",
+	"xlsx":     "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"camt053":  "application/xml",
+	"ofx":      "application/x-ofx",
+	"qif":      "application/qif",
+	"msgpack":  "application/vnd.msgpack",
+	"protobuf": "application/x-protobuf",
+	"template": "text/plain; charset=utf-8",
+	"gz":       "application/gzip",
+	"zip":      "application/zip",
+	"enc":      "application/octet-stream",
 }
 
-// FileHandler implements FileHandlingManager interface
-type FileHandler struct {
-	fileStorage FileStorageManager
+// Constructors of wallet statement marshallers
+var statementHandlers = map[string]func(w io.Writer, mu *sync.Mutex, statement *entities.AccountStatement, createdAt time.Time) (FileMarshallingManager, error){
+	"camt053": func(w io.Writer, mu *sync.Mutex, statement *entities.AccountStatement, createdAt time.Time) (FileMarshallingManager, error) {
+		return NewCamt053Handler(w, mu, statement, createdAt)
+	},
+	"ofx": func(w io.Writer, mu *sync.Mutex, statement *entities.AccountStatement, createdAt time.Time) (FileMarshallingManager, error) {
+		return NewOFXHandler(w, mu, statement, createdAt)
+	},
+	"qif": func(w io.Writer, mu *sync.Mutex, statement *entities.AccountStatement, createdAt time.Time) (FileMarshallingManager, error) {
+		return NewQIFHandler(w, mu, statement, createdAt)
+	},
 }
 
-// FileStorage implements FileStorageManager interface
-type FileStorage struct{}
+// File extensions of formats which differ from format's name
+var reportExtensions = map[string]string{
+	"camt053":  "xml",
+	"protobuf": "pb",
+	"template": "txt",
+}
 
-func NewFileStorage() *FileStorage {
-	return &FileStorage{}
+// FileHandler implements FileHandlingManager interface
+type FileHandler struct {
+	fileStorage   FileStorageManager
+	signer        ReportSigner
+	encryptionKey []byte
 }
 
-// NewFileHandler returns new instance of FileHandler
-func NewFileHandler(storage FileStorageManager) *FileHandler {
+// NewFileHandler returns new instance of FileHandler; reports are not signed when signer is nil
+// and can not be encrypted with key when encryption key is nil
+func NewFileHandler(storage FileStorageManager, signer ReportSigner, encryptionKey []byte) *FileHandler {
 	return &FileHandler{
-		fileStorage: storage,
+		fileStorage:   storage,
+		signer:        signer,
+		encryptionKey: encryptionKey,
 	}
 }
 
-// Create creates new file
-func (fs FileStorage) Create(path string, flag int, perm os.FileMode) (*os.File, error) {
-	return os.OpenFile(path, flag, perm)
-}
-
-// Create file with attributes
-func (fh FileHandler) Create(format string) (*entities.FileParams, error) {
-	var (
-		path      string
-		name      string
-		csvWriter CSVWriter
-	)
+// Create creates new report in storage with unique name; report is compressed and encrypted
+// on the fly according to archive options
+func (fh FileHandler) Create(format string, archive *ArchiveOptions) (*entities.FileParams, error) {
+	var csvWriter CSVWriter
 
-	name = "report." + format
-	_, b, _, _ := runtime.Caller(0)
-	basepath := filepath.Dir(b)
-	path = filepath.Join(basepath, name)
-	f, fileOpenErr := fh.fileStorage.Create(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0666)
-	if fileOpenErr != nil {
-		return nil, fmt.Errorf("error of creating file: %s", fileOpenErr)
+	suffix := make([]byte, 8)
+	if _, randErr := rand.Read(suffix); randErr != nil {
+		return nil, fmt.Errorf("error of report name generation: %s", randErr)
+	}
+	extension, renamed := reportExtensions[format]
+	if !renamed {
+		extension = format
+	}
+	entry := fmt.Sprintf("report-%s-%s.%s", time.Now().UTC().Format("20060102T150405"), hex.EncodeToString(suffix), extension)
+	name := archiveName(entry, archive)
+	stored, createErr := fh.fileStorage.Create(name)
+	if createErr != nil {
+		return nil, fmt.Errorf("error of creating file: %s", createErr)
+	}
+	w, archiveErr := newArchiveWriter(stored, entry, archive, fh.encryptionKey)
+	if archiveErr != nil {
+		stored.Close()
+		fh.fileStorage.Remove(name)
+		return nil, archiveErr
 	}
 
 	if format == "csv" {
-		csvWriter = csv.NewWriter(f)
+		csvWriter = csv.NewWriter(w)
 	}
 
 	return &entities.FileParams{
+		Writer:    w,
 		Name:      name,
-		Path:      path,
-		File:      f,
 		CsvWriter: csvWriter,
 	}, nil
 }
 
+// Open opens stored report for reading
+func (fh FileHandler) Open(name string) (entities.ReportFile, error) {
+	return fh.fileStorage.Open(name)
+}
+
+// Remove removes stored report
+func (fh FileHandler) Remove(name string) error {
+	return fh.fileStorage.Remove(name)
+}
+
+// FormatByName returns format of stored report by its extension; archives have format of their last extension
+func FormatByName(name string) string {
+	extension := strings.TrimPrefix(filepath.Ext(name), ".")
+	for format, formatExtension := range reportExtensions {
+		if formatExtension == extension {
+			return format
+		}
+	}
+	return extension
+}
+
 // CreateMarshaller returns file marshaller for particular format
-func (fh FileHandler) CreateMarshaller(file *os.File, format string, csvWriter CSVWriter) (FileMarshallingManager, error) {
+func (fh FileHandler) CreateMarshaller(file io.Writer, format string, csvWriter CSVWriter, options *FormatOptions) (FileMarshallingManager, error) {
 	var (
 		mu          = &sync.Mutex{}
 		fileHandler FileMarshallingManager
 	)
+	if options == nil {
+		options = DefaultFormatOptions()
+	}
 
-	if format == "csv" {
-		headers := []string{
-			"id", "operation", "wallet_from", "wallet_to", "amount", "created_at",
+	switch format {
+	case "csv":
+		// Delimiter can be changed only for encoding/csv writer
+		if writer, ok := csvWriter.(*csv.Writer); ok {
+			writer.Comma = options.Delimiter
 		}
-		writeErr := csvWriter.Write(headers)
-		if writeErr != nil {
-			return nil, fmt.Errorf("error fo csv writing: %s", writeErr)
+		if options.Header {
+			writeErr := csvWriter.Write(options.Columns)
+			if writeErr != nil {
+				return nil, fmt.Errorf("error fo csv writing: %s", writeErr)
+			}
 		}
 		fileHandler = &CSVHandler{
 			csvWriter: csvWriter,
 			mu:        mu,
+			options:   options,
 		}
-	} else if format == "json" {
+	case "json", "ndjson":
 		fileHandler = &JSONHandler{
-			file:     file,
-			mu:       mu,
-			marshall: json.Marshal,
+			file:          file,
+			mu:            mu,
+			marshall:      json.Marshal,
+			amountFormat:  options.AmountFormat,
+			lineDelimited: format == "ndjson",
+			columns:       options.Columns,
+		}
+	case "msgpack":
+		fileHandler = NewMsgpackHandler(file, mu, options.Columns, options.AmountFormat)
+	case "protobuf":
+		fileHandler = NewProtobufHandler(file, mu)
+	case "xlsx":
+		xlsxWriter, xlsxErr := NewXLSXWriter(file, "Report", xlsxColumns(options.Columns))
+		if xlsxErr != nil {
+			return nil, xlsxErr
+		}
+		fileHandler = &XLSXHandler{
+			xlsxWriter: xlsxWriter,
+			mu:         mu,
+			columns:    options.Columns,
+		}
+	case TemplateFormat:
+		if options.Template == nil {
+			return nil, fmt.Errorf("report template is required for template format")
 		}
+		templateHandler, templateErr := NewTemplateHandler(file, mu, options.Template, time.Now())
+		if templateErr != nil {
+			return nil, templateErr
+		}
+		fileHandler = templateHandler
+	case "camt053", "ofx", "qif":
+		if options.Statement == nil {
+			return nil, fmt.Errorf("account statement is required for %s format", format)
+		}
+		statementHandler, statementErr := statementHandlers[format](file, mu, options.Statement, time.Now())
+		if statementErr != nil {
+			return nil, statementErr
+		}
+		fileHandler = statementHandler
+	default:
+		return nil, fmt.Errorf("unsupported report format: %s", format)
 	}
 	return fileHandler, nil
 }
 
-// GetFileMetadata retrieves file's metadata
-func (fh FileHandler) GetFileMetadata(file FileWithMetadata) (*entities.Metadata, error) {
-	header := make([]byte, 512)
-	_, readErr := file.Read(header)
-	if readErr != nil {
-		return nil, fmt.Errorf("error of file header's reading: %s", readErr)
+// GetFileMetadata retrieves file's metadata, SHA-256 checksum and its signature;
+// content type is detected only for unknown formats
+func (fh FileHandler) GetFileMetadata(file entities.ReportFile, format string) (*entities.Metadata, error) {
+	hash := sha256.New()
+	contentType, known := reportContentTypes[format]
+	if !known {
+		header := make([]byte, 512)
+		n, readErr := file.Read(header)
+		if readErr != nil {
+			return nil, fmt.Errorf("error of file header's reading: %s", readErr)
+		}
+		hash.Write(header[:n])
+		contentType = http.DetectContentType(header[:n])
 	}
-	stat, statErr := file.Stat()
-	if statErr != nil {
-		return nil, fmt.Errorf("error of file stats's receiving: %s", statErr)
+	if _, readErr := io.Copy(hash, file); readErr != nil {
+		return nil, fmt.Errorf("error of file checksum's calculation: %s", readErr)
 	}
-	size := strconv.FormatInt(stat.Size(), 10)
-	contentType := http.DetectContentType(header)
-	return &entities.Metadata{
+	size := strconv.FormatInt(file.Size(), 10)
+	metadata := &entities.Metadata{
 		Size:        size,
 		ContentType: contentType,
-	}, nil
+		SHA256:      hash.Sum(nil),
+	}
+	if fh.signer != nil {
+		metadata.Signature = fh.signer.Sign(metadata.SHA256)
+		metadata.KeyID = fh.signer.KeyID()
+	}
+	return metadata, nil
+}
+
+// SignManifest returns canonical json of report's manifest and its signature, see SignManifest
+func (fh FileHandler) SignManifest(manifest *entities.ReportManifest) ([]byte, []byte, error) {
+	return SignManifest(manifest, fh.signer)
 }
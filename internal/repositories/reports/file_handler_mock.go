@@ -7,63 +7,10 @@ package reports
 import (
 	entities "billing_system_test_task/internal/entities"
 	gomock "github.com/golang/mock/gomock"
-	os "os"
+	io "io"
 	reflect "reflect"
 )
 
-// MockFileWithMetadata is a mock of FileWithMetadata interface
-type MockFileWithMetadata struct {
-	ctrl     *gomock.Controller
-	recorder *MockFileWithMetadataMockRecorder
-}
-
-// MockFileWithMetadataMockRecorder is the mock recorder for MockFileWithMetadata
-type MockFileWithMetadataMockRecorder struct {
-	mock *MockFileWithMetadata
-}
-
-// NewMockFileWithMetadata creates a new mock instance
-func NewMockFileWithMetadata(ctrl *gomock.Controller) *MockFileWithMetadata {
-	mock := &MockFileWithMetadata{ctrl: ctrl}
-	mock.recorder = &MockFileWithMetadataMockRecorder{mock}
-	return mock
-}
-
-// EXPECT returns an object that allows the caller to indicate expected use
-func (m *MockFileWithMetadata) EXPECT() *MockFileWithMetadataMockRecorder {
-	return m.recorder
-}
-
-// Read mocks base method
-func (m *MockFileWithMetadata) Read(p []byte) (int, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Read", p)
-	ret0, _ := ret[0].(int)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// Read indicates an expected call of Read
-func (mr *MockFileWithMetadataMockRecorder) Read(p interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockFileWithMetadata)(nil).Read), p)
-}
-
-// Stat mocks base method
-func (m *MockFileWithMetadata) Stat() (os.FileInfo, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Stat")
-	ret0, _ := ret[0].(os.FileInfo)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// Stat indicates an expected call of Stat
-func (mr *MockFileWithMetadataMockRecorder) Stat() *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stat", reflect.TypeOf((*MockFileWithMetadata)(nil).Stat))
-}
-
 // MockFileHandlingManager is a mock of FileHandlingManager interface
 type MockFileHandlingManager struct {
 	ctrl     *gomock.Controller
@@ -88,84 +35,91 @@ func (m *MockFileHandlingManager) EXPECT() *MockFileHandlingManagerMockRecorder
 }
 
 // Create mocks base method
-func (m *MockFileHandlingManager) Create(format string) (*entities.FileParams, error) {
+func (m *MockFileHandlingManager) Create(format string, archive *ArchiveOptions) (*entities.FileParams, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", format)
+	ret := m.ctrl.Call(m, "Create", format, archive)
 	ret0, _ := ret[0].(*entities.FileParams)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create
-func (mr *MockFileHandlingManagerMockRecorder) Create(format interface{}) *gomock.Call {
+func (mr *MockFileHandlingManagerMockRecorder) Create(format, archive interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFileHandlingManager)(nil).Create), format)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFileHandlingManager)(nil).Create), format, archive)
 }
 
 // CreateMarshaller mocks base method
-func (m *MockFileHandlingManager) CreateMarshaller(file *os.File, format string, csvWriter CSVWriter) (FileMarshallingManager, error) {
+func (m *MockFileHandlingManager) CreateMarshaller(file io.Writer, format string, csvWriter CSVWriter, options *FormatOptions) (FileMarshallingManager, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateMarshaller", file, format, csvWriter)
+	ret := m.ctrl.Call(m, "CreateMarshaller", file, format, csvWriter, options)
 	ret0, _ := ret[0].(FileMarshallingManager)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateMarshaller indicates an expected call of CreateMarshaller
-func (mr *MockFileHandlingManagerMockRecorder) CreateMarshaller(file, format, csvWriter interface{}) *gomock.Call {
+func (mr *MockFileHandlingManagerMockRecorder) CreateMarshaller(file, format, csvWriter, options interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMarshaller", reflect.TypeOf((*MockFileHandlingManager)(nil).CreateMarshaller), file, format, csvWriter)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMarshaller", reflect.TypeOf((*MockFileHandlingManager)(nil).CreateMarshaller), file, format, csvWriter, options)
 }
 
-// GetFileMetadata mocks base method
-func (m *MockFileHandlingManager) GetFileMetadata(file FileWithMetadata) (*entities.Metadata, error) {
+// Open mocks base method
+func (m *MockFileHandlingManager) Open(name string) (entities.ReportFile, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFileMetadata", file)
-	ret0, _ := ret[0].(*entities.Metadata)
+	ret := m.ctrl.Call(m, "Open", name)
+	ret0, _ := ret[0].(entities.ReportFile)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetFileMetadata indicates an expected call of GetFileMetadata
-func (mr *MockFileHandlingManagerMockRecorder) GetFileMetadata(file interface{}) *gomock.Call {
+// Open indicates an expected call of Open
+func (mr *MockFileHandlingManagerMockRecorder) Open(name interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileMetadata", reflect.TypeOf((*MockFileHandlingManager)(nil).GetFileMetadata), file)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockFileHandlingManager)(nil).Open), name)
 }
 
-// MockFileStorageManager is a mock of FileStorageManager interface
-type MockFileStorageManager struct {
-	ctrl     *gomock.Controller
-	recorder *MockFileStorageManagerMockRecorder
+// Remove mocks base method
+func (m *MockFileHandlingManager) Remove(name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", name)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// MockFileStorageManagerMockRecorder is the mock recorder for MockFileStorageManager
-type MockFileStorageManagerMockRecorder struct {
-	mock *MockFileStorageManager
+// Remove indicates an expected call of Remove
+func (mr *MockFileHandlingManagerMockRecorder) Remove(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockFileHandlingManager)(nil).Remove), name)
 }
 
-// NewMockFileStorageManager creates a new mock instance
-func NewMockFileStorageManager(ctrl *gomock.Controller) *MockFileStorageManager {
-	mock := &MockFileStorageManager{ctrl: ctrl}
-	mock.recorder = &MockFileStorageManagerMockRecorder{mock}
-	return mock
+// GetFileMetadata mocks base method
+func (m *MockFileHandlingManager) GetFileMetadata(file entities.ReportFile, format string) (*entities.Metadata, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileMetadata", file, format)
+	ret0, _ := ret[0].(*entities.Metadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
-func (m *MockFileStorageManager) EXPECT() *MockFileStorageManagerMockRecorder {
-	return m.recorder
+// GetFileMetadata indicates an expected call of GetFileMetadata
+func (mr *MockFileHandlingManagerMockRecorder) GetFileMetadata(file, format interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileMetadata", reflect.TypeOf((*MockFileHandlingManager)(nil).GetFileMetadata), file, format)
 }
 
-// Create mocks base method
-func (m *MockFileStorageManager) Create(path string, flag int, perm os.FileMode) (*os.File, error) {
+// SignManifest mocks base method
+func (m *MockFileHandlingManager) SignManifest(manifest *entities.ReportManifest) ([]byte, []byte, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", path, flag, perm)
-	ret0, _ := ret[0].(*os.File)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "SignManifest", manifest)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].([]byte)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
-// Create indicates an expected call of Create
-func (mr *MockFileStorageManagerMockRecorder) Create(path, flag, perm interface{}) *gomock.Call {
+// SignManifest indicates an expected call of SignManifest
+func (mr *MockFileHandlingManagerMockRecorder) SignManifest(manifest interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFileStorageManager)(nil).Create), path, flag, perm)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignManifest", reflect.TypeOf((*MockFileHandlingManager)(nil).SignManifest), manifest)
 }
@@ -1,33 +1,81 @@
 package reports
 
 import (
+	"billing_system_test_task/internal/entities"
+	"bytes"
+	"crypto/sha256"
 	"encoding/csv"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"os"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
-	gomock "github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
 )
 
 // FailedFileStore represents implementation of store interface with error methods
 type FailedFileStore struct {
 }
 
-func (ffs FailedFileStore) Create(path string, flag int, perm os.FileMode) (*os.File, error) {
+func (ffs FailedFileStore) Create(name string) (io.WriteCloser, error) {
 	return nil, fmt.Errorf("error file creation")
 }
 
+func (ffs FailedFileStore) Open(name string) (entities.ReportFile, error) {
+	return nil, ErrReportNotFound
+}
+
+func (ffs FailedFileStore) Remove(name string) error {
+	return ErrReportNotFound
+}
+
+// FailedReportFile represents stored report with reading error
+type FailedReportFile struct {
+}
+
+func (frf FailedReportFile) Read(p []byte) (int, error) {
+	return 0, fmt.Errorf("report read error")
+}
+
+func (frf FailedReportFile) Seek(offset int64, whence int) (int64, error) {
+	return 0, nil
+}
+
+func (frf FailedReportFile) Close() error {
+	return nil
+}
+
+func (frf FailedReportFile) Size() int64 {
+	return 0
+}
+
+func (frf FailedReportFile) ModTime() time.Time {
+	return time.Time{}
+}
+
+// storedReport saves data to memory storage and opens it
+func storedReport(t *testing.T, data []byte) entities.ReportFile {
+	storage := NewMemoryStorage()
+	w, _ := storage.Create("report.csv")
+	_, _ = w.Write(data)
+	_ = w.Close()
+	report, err := storage.Open("report.csv")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return report
+}
+
 // Test success file creation (json format)
 func TestFileHandlerSuccessCreateFile(t *testing.T) {
 	fh := FileHandler{
-		fileStorage: FileStorage{},
+		fileStorage: NewMemoryStorage(),
 	}
-	defer os.Remove("report.json")
 
-	_, err := fh.Create("json")
+	_, err := fh.Create("json", nil)
 	if err != nil {
 		t.Errorf("File was not created: %s", err)
 	}
@@ -38,9 +86,8 @@ func TestFileHandlerFailedCreateFile(t *testing.T) {
 	fh := FileHandler{
 		fileStorage: FailedFileStore{},
 	}
-	defer os.Remove("report.json")
 
-	_, err := fh.Create("json")
+	_, err := fh.Create("json", nil)
 	if err == nil {
 		t.Errorf("Expected error, got nil")
 	}
@@ -49,11 +96,10 @@ func TestFileHandlerFailedCreateFile(t *testing.T) {
 // Test success file creation (csv format)
 func TestFileHandlerSuccessCreateFileCSVFormat(t *testing.T) {
 	fh := FileHandler{
-		fileStorage: FileStorage{},
+		fileStorage: NewMemoryStorage(),
 	}
-	defer os.Remove("report.csv")
 
-	params, err := fh.Create("csv")
+	params, err := fh.Create("csv", nil)
 	if err != nil {
 		t.Errorf("File was not created: %s", err)
 	}
@@ -66,11 +112,11 @@ func TestFileHandlerSuccessCreateFileCSVFormat(t *testing.T) {
 // Test success operation marshalling (csv format)
 func TestFileHandlerSuccessCreateMarshallerCSV(t *testing.T) {
 	fh := FileHandler{
-		fileStorage: FileStorage{},
+		fileStorage: NewMemoryStorage(),
 	}
 	f, _ := os.CreateTemp("", "_example_file")
 	csvWriter := csv.NewWriter(f)
-	marshaller, _ := fh.CreateMarshaller(f, "csv", csvWriter)
+	marshaller, _ := fh.CreateMarshaller(f, "csv", csvWriter, nil)
 	if reflect.TypeOf(marshaller) != reflect.TypeOf(&CSVHandler{}) {
 		t.Errorf("Types mismatch. Expected: %s. Got: %s", reflect.TypeOf(CSVHandler{}), reflect.TypeOf(marshaller))
 	}
@@ -79,11 +125,11 @@ func TestFileHandlerSuccessCreateMarshallerCSV(t *testing.T) {
 // Test failed operation marshalling (csv format)
 func TestFileHandlerFailedCreateMarshallerCSV(t *testing.T) {
 	fh := FileHandler{
-		fileStorage: FileStorage{},
+		fileStorage: NewMemoryStorage(),
 	}
 	f, _ := os.CreateTemp("", "_example_file")
 	csvWriter := ErrorCSVFile{}
-	_, marshallErr := fh.CreateMarshaller(f, "csv", csvWriter)
+	_, marshallErr := fh.CreateMarshaller(f, "csv", csvWriter, nil)
 	if marshallErr == nil {
 		t.Errorf("Expected error, got nil")
 	}
@@ -93,39 +139,214 @@ func TestFileHandlerFailedCreateMarshallerCSV(t *testing.T) {
 	}
 }
 
+// Test csv marshaller creation with custom delimiter and without header
+func TestFileHandlerSuccessCreateMarshallerCSVDialect(t *testing.T) {
+	fh := FileHandler{
+		fileStorage: NewMemoryStorage(),
+	}
+	tests := []struct {
+		name     string
+		header   bool
+		expected string
+	}{
+		{"With header", true, "id;amount\n1;10\n"},
+		{"Without header", false, "1;10\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			csvWriter := csv.NewWriter(buf)
+			options := DefaultFormatOptions()
+			options.Columns = []string{"id", "amount"}
+			options.Delimiter = ';'
+			options.Header = tc.header
+			marshaller, err := fh.CreateMarshaller(nil, "csv", csvWriter, options)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			mr, _ := marshaller.MarshallRow(OperationRow{&entities.WalletOperation{ID: 1, Amount: decimal.NewFromInt(10)}})
+			_ = marshaller.WriteToFile(mr)
+			_ = marshaller.Close()
+			if buf.String() != tc.expected {
+				t.Errorf("Wrong csv. Expected %q, got %q", tc.expected, buf.String())
+			}
+		})
+	}
+}
+
 // Test success operation marshalling (json format)
 func TestFileHandlerSuccessCreateMarshallerJSON(t *testing.T) {
 	fh := FileHandler{
-		fileStorage: FileStorage{},
+		fileStorage: NewMemoryStorage(),
 	}
 	f, _ := os.CreateTemp("", "_example_file")
 	csvWriter := csv.NewWriter(f)
-	marshaller, _ := fh.CreateMarshaller(f, "json", csvWriter)
+	marshaller, _ := fh.CreateMarshaller(f, "json", csvWriter, nil)
 	if reflect.TypeOf(marshaller) != reflect.TypeOf(&JSONHandler{}) {
 		t.Errorf("Types mismatch. Expected: %s. Got: %s", reflect.TypeOf(&JSONHandler{}), reflect.TypeOf(marshaller))
 	}
 }
 
+// Test success operation marshalling (ndjson format)
+func TestFileHandlerSuccessCreateMarshallerNDJSON(t *testing.T) {
+	fh := FileHandler{
+		fileStorage: NewMemoryStorage(),
+	}
+	f, _ := os.CreateTemp("", "_example_file")
+	defer os.Remove(f.Name())
+	marshaller, err := fh.CreateMarshaller(f, "ndjson", nil, &FormatOptions{AmountFormat: AmountAsNumber})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	handler := marshaller.(*JSONHandler)
+	if !handler.lineDelimited || handler.amountFormat != AmountAsNumber {
+		t.Errorf("Handler is not configured with given format and options")
+	}
+}
+
+// Test success operation marshalling (xlsx format)
+func TestFileHandlerSuccessCreateMarshallerXLSX(t *testing.T) {
+	fh := FileHandler{
+		fileStorage: NewMemoryStorage(),
+	}
+	f, _ := os.CreateTemp("", "_example_file")
+	defer os.Remove(f.Name())
+	marshaller, err := fh.CreateMarshaller(f, "xlsx", nil, nil)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if reflect.TypeOf(marshaller) != reflect.TypeOf(&XLSXHandler{}) {
+		t.Errorf("Types mismatch. Expected: %s. Got: %s", reflect.TypeOf(&XLSXHandler{}), reflect.TypeOf(marshaller))
+	}
+}
+
+// Test statement marshallers creation requires account statement
+func TestFileHandlerCreateMarshallerStatement(t *testing.T) {
+	fh := FileHandler{
+		fileStorage: NewMemoryStorage(),
+	}
+	f, _ := os.CreateTemp("", "_example_file")
+	defer os.Remove(f.Name())
+	options := DefaultFormatOptions()
+	options.Statement = &entities.AccountStatement{WalletID: 1, Currency: "USD"}
+	for format, handlerType := range map[string]reflect.Type{
+		"camt053": reflect.TypeOf(&Camt053Handler{}),
+		"ofx":     reflect.TypeOf(&OFXHandler{}),
+		"qif":     reflect.TypeOf(&QIFHandler{}),
+	} {
+		if _, err := fh.CreateMarshaller(f, format, nil, nil); err == nil {
+			t.Errorf("[%s] Expected error, got nil", format)
+		}
+		marshaller, err := fh.CreateMarshaller(f, format, nil, options)
+		if err != nil {
+			t.Errorf("[%s] Unexpected error: %s", format, err)
+		}
+		if reflect.TypeOf(marshaller) != handlerType {
+			t.Errorf("Types mismatch. Expected: %s. Got: %s", handlerType, reflect.TypeOf(marshaller))
+		}
+	}
+}
+
+// Test template marshaller requires compiled template
+func TestFileHandlerCreateMarshallerTemplate(t *testing.T) {
+	fh := FileHandler{
+		fileStorage: NewMemoryStorage(),
+	}
+	buf := &bytes.Buffer{}
+	if _, err := fh.CreateMarshaller(buf, TemplateFormat, nil, nil); err == nil || err.Error() != "report template is required for template format" {
+		t.Errorf("Expected error of missing template, got %v", err)
+	}
+	options := DefaultFormatOptions()
+	options.Template, _ = CompileTemplate(&entities.ReportTemplate{Header: "operations\n", Row: "{{.ID}}\n"})
+	marshaller, err := fh.CreateMarshaller(buf, TemplateFormat, nil, options)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if _, isTemplate := marshaller.(*TemplateHandler); !isTemplate || buf.String() != "operations\n" {
+		t.Errorf("Wrong marshaller %T with header %q", marshaller, buf.String())
+	}
+}
+
+// Test camt053 file has xml extension
+func TestFileHandlerSuccessCreateFileCamt053Format(t *testing.T) {
+	fh := FileHandler{
+		fileStorage: NewMemoryStorage(),
+	}
+
+	params, err := fh.Create("camt053", nil)
+	if err != nil {
+		t.Fatalf("File was not created: %s", err)
+	}
+	if !strings.HasPrefix(params.Name, "report-") || !strings.HasSuffix(params.Name, ".xml") {
+		t.Errorf("Expected report-*.xml, got %s", params.Name)
+	}
+}
+
+// Test failed operation marshalling (unsupported format)
+func TestFileHandlerFailedCreateMarshallerUnsupportedFormat(t *testing.T) {
+	fh := FileHandler{
+		fileStorage: NewMemoryStorage(),
+	}
+	f, _ := os.CreateTemp("", "_example_file")
+	defer os.Remove(f.Name())
+	_, err := fh.CreateMarshaller(f, "pdf", nil, nil)
+	if err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
 // Test file handling constructor
 func TestNewFileHandlerFunction(t *testing.T) {
-	storage := FileStorage{}
-	handler := NewFileHandler(storage)
+	storage := NewMemoryStorage()
+	signer, _ := GenerateEd25519Signer()
+	handler := NewFileHandler(storage, signer, nil)
 	if reflect.TypeOf(handler.fileStorage) != reflect.TypeOf(storage) {
 		t.Errorf("Types mismatch. Expected: %s. Got: %s", reflect.TypeOf(storage), reflect.TypeOf(handler.fileStorage))
 	}
+	if handler.signer != signer {
+		t.Errorf("Signer is not set")
+	}
+}
+
+// Test checksum and signature of file's metadata
+func TestSuccessFileHandlerGetFileMetadataSignature(t *testing.T) {
+	signer, _ := GenerateEd25519Signer()
+	data := []byte("id,amount\n1,10.00\n")
+	tmpFile := storedReport(t, data)
+
+	for _, format := range []string{"csv", ""} {
+		_, _ = tmpFile.Seek(0, 0)
+		res, err := NewFileHandler(NewMemoryStorage(), signer, nil).GetFileMetadata(tmpFile, format)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		digest := sha256.Sum256(data)
+		if !bytes.Equal(res.SHA256, digest[:]) {
+			t.Errorf("[%s] Wrong checksum: %x", format, res.SHA256)
+		}
+		if res.KeyID != signer.KeyID() {
+			t.Errorf("[%s] Wrong key id: %s", format, res.KeyID)
+		}
+		if verifyErr := VerifyReport(bytes.NewReader(data), res.SHA256, res.Signature, signer.PublicKey()); verifyErr != nil {
+			t.Errorf("[%s] Signature is not verified: %s", format, verifyErr)
+		}
+	}
+
+	_, _ = tmpFile.Seek(0, 0)
+	res, _ := NewFileHandler(NewMemoryStorage(), nil, nil).GetFileMetadata(tmpFile, "csv")
+	if res.Signature != nil || res.SHA256 == nil {
+		t.Errorf("Expected checksum without signature, got %+v", res)
+	}
 }
 
 // Test success receiving of file's metadata
 func TestSuccessFileHandlerGetFileMetadata(t *testing.T) {
 	fh := FileHandler{
-		fileStorage: FileStorage{},
+		fileStorage: NewMemoryStorage(),
 	}
-	tmpFile, _ := ioutil.TempFile(os.TempDir(), "test")
-	data := []byte("Test file\n")
-	_, _ = tmpFile.Write(data)
-	_, _ = tmpFile.Seek(0, 0)
+	tmpFile := storedReport(t, []byte("Test file\n"))
 
-	res, err := fh.GetFileMetadata(tmpFile)
+	res, err := fh.GetFileMetadata(tmpFile, "")
 	if err != nil {
 		t.Errorf("Unexpected error: %s", err)
 	}
@@ -138,43 +359,101 @@ func TestSuccessFileHandlerGetFileMetadata(t *testing.T) {
 // Test failed receiving file's metadata (Read() error)
 func TestFailedFileHandlerGetFileMetadataErrorRead(t *testing.T) {
 	fh := FileHandler{
-		fileStorage: FileStorage{},
+		fileStorage: NewMemoryStorage(),
 	}
-	tmpFile, _ := ioutil.TempFile(os.TempDir(), "test")
-	data := []byte("Test file\n")
-	_, _ = tmpFile.Write(data)
+	tmpFile := storedReport(t, []byte("Test file\n"))
+	_, _ = tmpFile.Seek(0, io.SeekEnd)
 
-	_, err := fh.GetFileMetadata(tmpFile)
+	_, err := fh.GetFileMetadata(tmpFile, "")
 	if err == nil {
 		t.Errorf("Expected error, got nil")
 	}
 }
 
-// Test failed receiving file's metadata (Stat() error)
-func TestFailedFileHandlerGetFileMetadataErrorStats(t *testing.T) {
+// Test failed receiving file's metadata (checksum reading error)
+func TestFailedFileHandlerGetFileMetadataErrorChecksum(t *testing.T) {
 	fh := FileHandler{
-		fileStorage: FileStorage{},
+		fileStorage: NewMemoryStorage(),
 	}
 
-	ctrl := gomock.NewController(t)
-	mockFM := NewMockFileWithMetadata(ctrl)
-	mockFM.EXPECT().Read(gomock.Any()).Return(0, nil)
-	mockFM.EXPECT().Stat().Return(nil, fmt.Errorf("file stat get error"))
-
-	_, err := fh.GetFileMetadata(mockFM)
+	_, err := fh.GetFileMetadata(FailedReportFile{}, "csv")
 	if err == nil {
-		t.Errorf("Expected error, got nil")
+		t.Fatalf("Expected error, got nil")
 	}
 
-	if !strings.Contains(err.Error(), "file stat get error") {
-		t.Errorf("Error should contain 'file stat get error' message")
+	if !strings.Contains(err.Error(), "report read error") {
+		t.Errorf("Error should contain 'report read error' message")
+	}
+}
+
+// Test receiving of file's metadata for known format (content type is not detected)
+func TestSuccessFileHandlerGetFileMetadataKnownFormat(t *testing.T) {
+	fh := FileHandler{
+		fileStorage: NewMemoryStorage(),
+	}
+	tmpFile := storedReport(t, []byte("{\"id\":1}\n"))
+
+	tests := map[string]string{
+		"json":   "application/json",
+		"ndjson": "application/x-ndjson",
+		"csv":    "text/csv; charset=utf-8",
+		"xlsx":   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	}
+	for format, contentType := range tests {
+		res, err := fh.GetFileMetadata(tmpFile, format)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		if res.ContentType != contentType {
+			t.Errorf("[%s] Wrong content type. Expected %s, got %s", format, contentType, res.ContentType)
+		}
 	}
 }
 
-// Test success return of FileStorage instance
-func TestNewFileStorage(t *testing.T) {
-	storage := NewFileStorage()
-	if storage == nil {
-		t.Error("Expected FileStorage instance, got nil")
+// Test opening and removing of created report
+func TestFileHandlerOpenRemove(t *testing.T) {
+	fh := NewFileHandler(NewMemoryStorage(), nil, nil)
+	params, err := fh.Create("camt053", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	_, _ = params.Writer.Write([]byte("<Document/>"))
+	_ = params.Writer.Close()
+
+	report, openErr := fh.Open(params.Name)
+	if openErr != nil {
+		t.Fatalf("Unexpected error: %s", openErr)
+	}
+	if report.Size() != 11 {
+		t.Errorf("Expected size 11, got %d", report.Size())
+	}
+	if format := FormatByName(params.Name); format != "camt053" {
+		t.Errorf("Expected camt053 format, got %s", format)
+	}
+
+	if removeErr := fh.Remove(params.Name); removeErr != nil {
+		t.Errorf("Unexpected error: %s", removeErr)
+	}
+	if _, openErr = fh.Open(params.Name); openErr != ErrReportNotFound {
+		t.Errorf("Expected ErrReportNotFound, got %v", openErr)
+	}
+}
+
+// Test format of stored report by its name
+func TestFormatByName(t *testing.T) {
+	tests := map[string]string{
+		"report-1.json":    "json",
+		"report-1.csv":     "csv",
+		"report-1.xml":     "camt053",
+		"report-1.qif":     "qif",
+		"report-1.pb":      "protobuf",
+		"report-1.msgpack": "msgpack",
+		"report-1.txt":     "template",
+	}
+	for name, format := range tests {
+		if actual := FormatByName(name); actual != format {
+			t.Errorf("[%s] Expected %s, got %s", name, format, actual)
+		}
 	}
 }
@@ -1,109 +1,290 @@
 package reports
 
 import (
-	"billing_system_test_task/internal/entities"
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 // FileMarshallingManager defines contracts for file marshalling
 type FileMarshallingManager interface {
-	MarshallOperation(operation *entities.WalletOperation) (*MarshalledResult, error)
+	MarshallRow(row ReportRow) (*MarshalledResult, error)
 	WriteToFile(mr *MarshalledResult) error
+	Close() error
 }
 
 // FileMarshallingManager represents methods for csv writing
 type CSVWriter interface {
 	Write(record []string) error
 	Flush()
+	Error() error
 }
 
-// JSONHandler implements FileMarshallingManager interface for json format
+// JSONHandler implements FileMarshallingManager interface for json and ndjson formats.
+// Operations are written as json array unless lineDelimited is set.
 type JSONHandler struct {
-	file     io.Writer
-	mu       *sync.Mutex
-	marshall func(v interface{}) ([]byte, error)
+	file          io.Writer
+	mu            *sync.Mutex
+	marshall      func(v interface{}) ([]byte, error)
+	amountFormat  string
+	lineDelimited bool
+	columns       []string
+	written       int
 }
 
+// NewJSONHandler returns handler which writes operations as json array
 func NewJSONHandler(file io.Writer, mu *sync.Mutex, marshall func(v interface{}) ([]byte, error)) *JSONHandler {
 	return &JSONHandler{
-		file:     file,
-		mu:       mu,
-		marshall: marshall,
+		file:         file,
+		mu:           mu,
+		marshall:     marshall,
+		amountFormat: AmountAsString,
 	}
 }
 
-// MarshallOperation marshal entities.WalletOperation instance to json
-func (jh *JSONHandler) MarshallOperation(operation *entities.WalletOperation) (*MarshalledResult, error) {
-	jsonBytes, jsonMarshallErr := jh.marshall(operation)
+// NewNDJSONHandler returns handler which writes operations as newline-delimited json
+func NewNDJSONHandler(file io.Writer, mu *sync.Mutex, marshall func(v interface{}) ([]byte, error)) *JSONHandler {
+	handler := NewJSONHandler(file, mu, marshall)
+	handler.lineDelimited = true
+	return handler
+}
+
+// MarshallRow marshal row to json object; operations and summary have fixed schema,
+// rows of exports contain selected columns
+func (jh *JSONHandler) MarshallRow(row ReportRow) (*MarshalledResult, error) {
+	var (
+		jsonBytes       []byte
+		jsonMarshallErr error
+	)
+	switch typedRow := row.(type) {
+	case OperationRow:
+		jsonBytes, jsonMarshallErr = jh.marshall(NewOperationReport(typedRow.WalletOperation, jh.amountFormat))
+	case SummaryRow:
+		jsonBytes, jsonMarshallErr = jh.marshall(NewSummaryReport(typedRow.OperationSummary, jh.amountFormat))
+	default:
+		jsonBytes, jsonMarshallErr = marshallJSONRow(row, jh.columns, jh.amountFormat, jh.marshall)
+	}
 	if jsonMarshallErr != nil {
 		return nil, fmt.Errorf("error of json marshalling: %s", jsonMarshallErr)
 	}
-	newLine := []byte("\n")
-	data := append(jsonBytes, newLine...)
 	return &MarshalledResult{
-		id:   operation.ID,
-		data: data,
+		id:   row.RowID(),
+		data: jsonBytes,
 	}, nil
 }
 
 // WriteToFile writes given marshall result to json file
 func (jh *JSONHandler) WriteToFile(mr *MarshalledResult) error {
-	var syncErr error
 	bytesData := mr.data.([]byte)
 	jh.mu.Lock()
-	_, writeErr := jh.file.Write(bytesData)
-	if writeErr != nil {
-		return fmt.Errorf("write file error: %s", syncErr)
+	defer jh.mu.Unlock()
+
+	var separator string
+	switch {
+	case jh.lineDelimited:
+		separator = ""
+	case jh.written == 0:
+		separator = "[\n"
+	default:
+		separator = ",\n"
+	}
+	record := make([]byte, 0, len(separator)+len(bytesData)+1)
+	record = append(record, separator...)
+	record = append(record, bytesData...)
+	if jh.lineDelimited {
+		record = append(record, '\n')
 	}
-	jh.mu.Unlock()
+	if _, writeErr := jh.file.Write(record); writeErr != nil {
+		return fmt.Errorf("write file error: %s", writeErr)
+	}
+	jh.written++
 	return nil
 }
 
-// JSONHandler implements FileMarshallingManager interface for csv format
+// Close finalizes json array; ndjson file does not need finalization
+func (jh *JSONHandler) Close() error {
+	jh.mu.Lock()
+	defer jh.mu.Unlock()
+	if jh.lineDelimited {
+		return nil
+	}
+
+	closing := "\n]\n"
+	if jh.written == 0 {
+		closing = "[]\n"
+	}
+	if _, writeErr := io.WriteString(jh.file, closing); writeErr != nil {
+		return fmt.Errorf("write file error: %s", writeErr)
+	}
+	return nil
+}
+
+// CSVHandler implements FileMarshallingManager interface for csv format
 type CSVHandler struct {
 	csvWriter CSVWriter
 	mu        *sync.Mutex
+	options   *FormatOptions
 }
 
 func NewCSVHandler(csvWriter CSVWriter, mu *sync.Mutex) *CSVHandler {
 	return &CSVHandler{
 		csvWriter: csvWriter,
 		mu:        mu,
+		options:   DefaultFormatOptions(),
 	}
 }
 
-// MarshallOperation marshal entities.WalletOperation instance to csv
-func (ch *CSVHandler) MarshallOperation(operation *entities.WalletOperation) (*MarshalledResult, error) {
-	idStr := strconv.Itoa(operation.ID)
-	walletFromStr := strconv.Itoa(int(operation.WalletFrom.Int32))
-	walletToStr := strconv.Itoa(int(operation.WalletTo))
-	amountStr := operation.Amount.String()
-	createdAtStr := operation.CreatedAt.String()
-	row := []string{
-		idStr,
-		operation.Operation,
-		walletFromStr,
-		walletToStr,
-		amountStr,
-		createdAtStr,
+// MarshallRow marshal row to csv row with selected columns
+func (ch *CSVHandler) MarshallRow(row ReportRow) (*MarshalledResult, error) {
+	record := make([]string, 0, len(ch.options.Columns))
+	for _, column := range ch.options.Columns {
+		record = append(record, ch.formatValue(row.Value(column)))
 	}
 	return &MarshalledResult{
-		id:   operation.ID,
-		data: row,
+		id:   row.RowID(),
+		data: record,
 	}, nil
 }
 
+// formatValue formats row's value according to csv dialect
+func (ch *CSVHandler) formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ch.options.NullValue
+	case int:
+		return strconv.Itoa(v)
+	case string:
+		return v
+	case decimal.Decimal:
+		return ch.formatAmount(v)
+	case time.Time:
+		return v.In(ch.options.Location).Format(ch.options.TimeLayout)
+	case ReportDate:
+		return time.Time(v).Format(summaryPeriodLayout)
+	}
+	return fmt.Sprint(value)
+}
+
+// formatAmount formats decimal with configured separator
+func (ch *CSVHandler) formatAmount(amount decimal.Decimal) string {
+	formatted := amount.String()
+	if ch.options.DecimalSeparator != "." {
+		formatted = strings.Replace(formatted, ".", ch.options.DecimalSeparator, 1)
+	}
+	return formatted
+}
+
 // WriteToFile writes given marshall result to csv file
 func (ch *CSVHandler) WriteToFile(mr *MarshalledResult) error {
 	row := mr.data.([]string)
 	ch.mu.Lock()
+	defer ch.mu.Unlock()
 	csvWriteErr := ch.csvWriter.Write(row)
 	if csvWriteErr != nil {
 		return fmt.Errorf("error fo csv writing: %s", csvWriteErr)
 	}
-	ch.mu.Unlock()
 	return nil
 }
+
+// Close flushes buffered rows to csv file
+func (ch *CSVHandler) Close() error {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	ch.csvWriter.Flush()
+	if flushErr := ch.csvWriter.Error(); flushErr != nil {
+		return fmt.Errorf("error of csv flushing: %s", flushErr)
+	}
+	return nil
+}
+
+// XLSXHandler implements FileMarshallingManager interface for xlsx format
+type XLSXHandler struct {
+	xlsxWriter *XLSXWriter
+	mu         *sync.Mutex
+	columns    []string
+}
+
+// Widths of the worksheet columns
+var xlsxColumnWidths = map[string]float64{
+	"id":            10,
+	"operation":     16,
+	"wallet_from":   12,
+	"wallet_to":     12,
+	"amount":        14,
+	"created_at":    20,
+	"period":        12,
+	GroupByWallet:   12,
+	GroupByCurrency: 10,
+	"count":         10,
+	"total":         16,
+	"email":         28,
+	"wallet_id":     12,
+	"balance":       14,
+	"wallets":       10,
+	"as_of":         20,
+}
+
+// Columns of the operations worksheet
+var operationXLSXColumns = xlsxColumns(operationColumns)
+
+// xlsxColumns returns worksheet columns with given names
+func xlsxColumns(names []string) []XLSXColumn {
+	columns := make([]XLSXColumn, 0, len(names))
+	for _, name := range names {
+		columns = append(columns, XLSXColumn{Name: name, Width: xlsxColumnWidths[name]})
+	}
+	return columns
+}
+
+func NewXLSXHandler(xlsxWriter *XLSXWriter, mu *sync.Mutex) *XLSXHandler {
+	return &XLSXHandler{
+		xlsxWriter: xlsxWriter,
+		mu:         mu,
+		columns:    operationColumns,
+	}
+}
+
+// MarshallRow marshal row to xlsx row; amounts are formatted with amount style
+func (xh *XLSXHandler) MarshallRow(row ReportRow) (*MarshalledResult, error) {
+	record := XLSXRow{}
+	for _, column := range xh.columns {
+		switch v := row.Value(column).(type) {
+		case int:
+			record.Number(strconv.Itoa(v), xlsxStyleDefault)
+		case string:
+			record.String(v, xlsxStyleDefault)
+		case decimal.Decimal:
+			record.Number(v.String(), xlsxStyleAmount)
+		case time.Time:
+			record.Date(v)
+		case ReportDate:
+			record.Day(time.Time(v))
+		default:
+			record.Empty()
+		}
+	}
+	return &MarshalledResult{
+		id:   row.RowID(),
+		data: record,
+	}, nil
+}
+
+// WriteToFile writes given marshall result to xlsx worksheet
+func (xh *XLSXHandler) WriteToFile(mr *MarshalledResult) error {
+	row := mr.data.(XLSXRow)
+	xh.mu.Lock()
+	defer xh.mu.Unlock()
+	return xh.xlsxWriter.WriteRow(row)
+}
+
+// Close finishes xlsx workbook
+func (xh *XLSXHandler) Close() error {
+	xh.mu.Lock()
+	defer xh.mu.Unlock()
+	return xh.xlsxWriter.Close()
+}
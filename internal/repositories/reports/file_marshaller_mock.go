@@ -5,7 +5,6 @@
 package reports
 
 import (
-	entities "billing_system_test_task/internal/entities"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
 )
@@ -33,19 +32,19 @@ func (m *MockFileMarshallingManager) EXPECT() *MockFileMarshallingManagerMockRec
 	return m.recorder
 }
 
-// MarshallOperation mocks base method
-func (m *MockFileMarshallingManager) MarshallOperation(operation *entities.WalletOperation) (*MarshalledResult, error) {
+// MarshallRow mocks base method
+func (m *MockFileMarshallingManager) MarshallRow(row ReportRow) (*MarshalledResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "MarshallOperation", operation)
+	ret := m.ctrl.Call(m, "MarshallRow", row)
 	ret0, _ := ret[0].(*MarshalledResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// MarshallOperation indicates an expected call of MarshallOperation
-func (mr *MockFileMarshallingManagerMockRecorder) MarshallOperation(operation interface{}) *gomock.Call {
+// MarshallRow indicates an expected call of MarshallRow
+func (mr *MockFileMarshallingManagerMockRecorder) MarshallRow(row interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarshallOperation", reflect.TypeOf((*MockFileMarshallingManager)(nil).MarshallOperation), operation)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarshallRow", reflect.TypeOf((*MockFileMarshallingManager)(nil).MarshallRow), row)
 }
 
 // WriteToFile mocks base method
@@ -62,6 +61,20 @@ func (mr_2 *MockFileMarshallingManagerMockRecorder) WriteToFile(mr interface{})
 	return mr_2.mock.ctrl.RecordCallWithMethodType(mr_2.mock, "WriteToFile", reflect.TypeOf((*MockFileMarshallingManager)(nil).WriteToFile), mr)
 }
 
+// Close mocks base method
+func (m *MockFileMarshallingManager) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close
+func (mr *MockFileMarshallingManagerMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockFileMarshallingManager)(nil).Close))
+}
+
 // MockCSVWriter is a mock of CSVWriter interface
 type MockCSVWriter struct {
 	ctrl     *gomock.Controller
@@ -110,3 +123,17 @@ func (mr *MockCSVWriterMockRecorder) Flush() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockCSVWriter)(nil).Flush))
 }
+
+// Error mocks base method
+func (m *MockCSVWriter) Error() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Error")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Error indicates an expected call of Error
+func (mr *MockCSVWriterMockRecorder) Error() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Error", reflect.TypeOf((*MockCSVWriter)(nil).Error))
+}
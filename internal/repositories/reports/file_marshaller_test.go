@@ -2,11 +2,13 @@ package reports
 
 import (
 	"billing_system_test_task/internal/entities"
+	"bytes"
 	"database/sql"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -25,7 +27,7 @@ func TestJSONHandlerFileMarshallerSuccessMarshallOperation(t *testing.T) {
 		mu:       mu,
 		marshall: json.Marshal,
 	}
-	mr, _ := handler.MarshallOperation(operation)
+	mr, _ := handler.MarshallRow(OperationRow{operation})
 	if mr.id != operation.ID {
 		t.Errorf("ID of marshalled result does not matched. Expected: %d, got: %d", operation.ID, mr.id)
 	}
@@ -45,7 +47,7 @@ func TestJSONHandlerFileMarshallerFailedMarshallOperation(t *testing.T) {
 			return nil, fmt.Errorf("Marshall error")
 		},
 	}
-	_, mrErr := handler.MarshallOperation(operation)
+	_, mrErr := handler.MarshallRow(OperationRow{operation})
 	if mrErr == nil {
 		t.Errorf("Expected error, got nil")
 	}
@@ -63,13 +65,74 @@ func TestJSONHandlerFileMarshallerSuccessWriteFile(t *testing.T) {
 		mu:       mu,
 		marshall: json.Marshal,
 	}
-	mr, _ := handler.MarshallOperation(operation)
+	mr, _ := handler.MarshallRow(OperationRow{operation})
 	err := handler.WriteToFile(mr)
 	if err != nil {
 		t.Errorf("Expected does not receive error, but error received: %s", err)
 	}
 }
 
+// Test writing of json array
+func TestJSONHandlerFileMarshallArray(t *testing.T) {
+	tests := []struct {
+		name       string
+		operations int
+		expected   string
+	}{
+		{"Empty report", 0, "[]\n"},
+		{"Several operations", 2, "[\n{\"id\":1},\n{\"id\":2}\n]\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			handler := NewJSONHandler(buf, &sync.Mutex{}, func(v interface{}) ([]byte, error) {
+				return []byte(fmt.Sprintf(`{"id":%d}`, v.(*OperationReport).ID)), nil
+			})
+			for id := 1; id <= tc.operations; id++ {
+				mr, _ := handler.MarshallRow(OperationRow{&entities.WalletOperation{ID: id}})
+				if writeErr := handler.WriteToFile(mr); writeErr != nil {
+					t.Fatalf("Unexpected error: %s", writeErr)
+				}
+			}
+			if closeErr := handler.Close(); closeErr != nil {
+				t.Fatalf("Unexpected error: %s", closeErr)
+			}
+			if buf.String() != tc.expected {
+				t.Errorf("Wrong output. Expected %q, got %q", tc.expected, buf.String())
+			}
+			if !json.Valid(buf.Bytes()) {
+				t.Errorf("Output is not valid json: %s", buf.String())
+			}
+		})
+	}
+}
+
+// Test writing of newline-delimited json
+func TestJSONHandlerFileMarshallNDJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := NewNDJSONHandler(buf, &sync.Mutex{}, json.Marshal)
+	for id := 1; id <= 2; id++ {
+		mr, _ := handler.MarshallRow(OperationRow{&entities.WalletOperation{ID: id, Amount: decimal.NewFromInt(10)}})
+		_ = handler.WriteToFile(mr)
+	}
+	if closeErr := handler.Close(); closeErr != nil {
+		t.Fatalf("Unexpected error: %s", closeErr)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		report := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &report); err != nil {
+			t.Errorf("Line is not valid json: %s", line)
+		}
+		if report["amount"] != "10" || report["wallet_from"] != nil {
+			t.Errorf("Wrong encoding of fields: %s", line)
+		}
+	}
+}
+
 // ErrorFile implements Writer interface
 type ErrorFile struct{}
 
@@ -88,6 +151,10 @@ func (ecf ErrorCSVFile) Write(record []string) error {
 
 func (ecf ErrorCSVFile) Flush() {}
 
+func (ecf ErrorCSVFile) Error() error {
+	return fmt.Errorf("Error of file writing")
+}
+
 // Test failed json marshalling for json format
 func TestJSONHandlerFileMarshallFailedWriteFile(t *testing.T) {
 
@@ -100,7 +167,7 @@ func TestJSONHandlerFileMarshallFailedWriteFile(t *testing.T) {
 		mu:       mu,
 		marshall: json.Marshal,
 	}
-	mr, _ := handler.MarshallOperation(operation)
+	mr, _ := handler.MarshallRow(OperationRow{operation})
 	err := handler.WriteToFile(mr)
 	if err == nil {
 		t.Errorf("Expected receive error, but error received it")
@@ -122,8 +189,9 @@ func TestCSVHandlerFileMarshallSuccessMarshallOperation(t *testing.T) {
 	handler := CSVHandler{
 		mu:        mu,
 		csvWriter: csvWriter,
+		options:   DefaultFormatOptions(),
 	}
-	mr, mrErr := handler.MarshallOperation(operation)
+	mr, mrErr := handler.MarshallRow(OperationRow{operation})
 	if mrErr != nil {
 		t.Errorf("Unexpected error: %s", mrErr)
 	}
@@ -132,6 +200,66 @@ func TestCSVHandlerFileMarshallSuccessMarshallOperation(t *testing.T) {
 	}
 }
 
+// Test csv marshalling with different dialect options
+func TestCSVHandlerFileMarshallDialect(t *testing.T) {
+	moscow, _ := time.LoadLocation("Europe/Moscow")
+	operation := &entities.WalletOperation{
+		ID:         7,
+		Operation:  "deposit",
+		WalletFrom: sql.NullInt32{},
+		WalletTo:   3,
+		Amount:     decimal.RequireFromString("1250.75"),
+		CreatedAt:  time.Date(2021, time.March, 1, 21, 30, 0, 0, time.UTC),
+	}
+	tests := []struct {
+		name     string
+		options  func(options *FormatOptions)
+		expected []string
+	}{
+		{
+			name:     "Default options",
+			options:  func(options *FormatOptions) {},
+			expected: []string{"7", "deposit", "", "3", "1250.75", "2021-03-01T21:30:00Z"},
+		},
+		{
+			name: "Selected columns in custom order",
+			options: func(options *FormatOptions) {
+				options.Columns = []string{"amount", "id"}
+			},
+			expected: []string{"1250.75", "7"},
+		},
+		{
+			name: "European dialect",
+			options: func(options *FormatOptions) {
+				options.DecimalSeparator = ","
+				options.NullValue = "NULL"
+				options.TimeLayout = "02.01.2006 15:04"
+				options.Location = moscow
+			},
+			expected: []string{"7", "deposit", "NULL", "3", "1250,75", "02.03.2021 00:30"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			options := DefaultFormatOptions()
+			tc.options(options)
+			handler := CSVHandler{
+				mu:        &sync.Mutex{},
+				csvWriter: csv.NewWriter(os.Stdout),
+				options:   options,
+			}
+			mr, mrErr := handler.MarshallRow(OperationRow{operation})
+			if mrErr != nil {
+				t.Fatalf("Unexpected error: %s", mrErr)
+			}
+			row := mr.data.([]string)
+			if strings.Join(row, "|") != strings.Join(tc.expected, "|") {
+				t.Errorf("Wrong row. Expected %v, got %v", tc.expected, row)
+			}
+		})
+	}
+}
+
 // Test failed file write for csv format (write error)
 func TestCSVHandlerFileMarshallFailedWriteFile(t *testing.T) {
 	mu := &sync.Mutex{}
@@ -147,8 +275,9 @@ func TestCSVHandlerFileMarshallFailedWriteFile(t *testing.T) {
 	handler := CSVHandler{
 		mu:        mu,
 		csvWriter: csvWriter,
+		options:   DefaultFormatOptions(),
 	}
-	mr, _ := handler.MarshallOperation(operation)
+	mr, _ := handler.MarshallRow(OperationRow{operation})
 	writeErr := handler.WriteToFile(mr)
 	if writeErr == nil {
 		t.Errorf("Expected error, got nil")
@@ -170,14 +299,225 @@ func TestCSVHandlerFileMarshallSuccessWriteFile(t *testing.T) {
 	handler := CSVHandler{
 		mu:        mu,
 		csvWriter: csvWriter,
+		options:   DefaultFormatOptions(),
 	}
-	mr, _ := handler.MarshallOperation(operation)
+	mr, _ := handler.MarshallRow(OperationRow{operation})
 	writeErr := handler.WriteToFile(mr)
 	if writeErr != nil {
 		t.Errorf("Expected nil, got error: %s", writeErr)
 	}
 }
 
+// Test success flushing for csv format
+func TestCSVHandlerFileMarshallSuccessClose(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := CSVHandler{
+		mu:        &sync.Mutex{},
+		csvWriter: csv.NewWriter(buf),
+		options:   DefaultFormatOptions(),
+	}
+	mr, _ := handler.MarshallRow(OperationRow{&entities.WalletOperation{ID: 1, Operation: "deposit"}})
+	_ = handler.WriteToFile(mr)
+	if buf.Len() != 0 {
+		t.Errorf("Expected rows to be buffered before closing")
+	}
+	if closeErr := handler.Close(); closeErr != nil {
+		t.Errorf("Unexpected error: %s", closeErr)
+	}
+	if !strings.HasPrefix(buf.String(), "1,deposit,") {
+		t.Errorf("Expected flushed row, got '%s'", buf.String())
+	}
+}
+
+// Test failed flushing for csv format
+func TestCSVHandlerFileMarshallFailedClose(t *testing.T) {
+	handler := CSVHandler{
+		mu:        &sync.Mutex{},
+		csvWriter: ErrorCSVFile{},
+	}
+	if closeErr := handler.Close(); closeErr == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+// Test success marshalling and writing for xlsx format
+func TestXLSXHandlerFileMarshallSuccess(t *testing.T) {
+	buf := &bytes.Buffer{}
+	xw, _ := NewXLSXWriter(buf, "Operations", operationXLSXColumns)
+	handler := NewXLSXHandler(xw, &sync.Mutex{})
+	operations := []*entities.WalletOperation{
+		{
+			ID:         1,
+			Operation:  "create wallet",
+			WalletFrom: sql.NullInt32{},
+			WalletTo:   1,
+			Amount:     decimal.NewFromInt(0),
+			CreatedAt:  time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			ID:         2,
+			Operation:  "deposit",
+			WalletFrom: sql.NullInt32{Int32: 1, Valid: true},
+			WalletTo:   2,
+			Amount:     decimal.RequireFromString("100.50"),
+			CreatedAt:  time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, operation := range operations {
+		mr, mrErr := handler.MarshallRow(OperationRow{operation})
+		if mrErr != nil {
+			t.Fatalf("Unexpected error: %s", mrErr)
+		}
+		if mr.id != operation.ID {
+			t.Errorf("ID of marshalled result does not matched. Expected: %d, got: %d", operation.ID, mr.id)
+		}
+		if writeErr := handler.WriteToFile(mr); writeErr != nil {
+			t.Fatalf("Unexpected error: %s", writeErr)
+		}
+	}
+	if closeErr := handler.Close(); closeErr != nil {
+		t.Fatalf("Unexpected error: %s", closeErr)
+	}
+
+	sheet := readXLSXPart(t, buf.Bytes(), "xl/worksheets/sheet1.xml")
+	expectedRows := []string{
+		`<row r="2"><c r="A2"><v>1</v></c><c r="B2" t="inlineStr"><is><t>create wallet</t></is></c><c r="D2"><v>1</v></c><c r="E2" s="3"><v>0</v></c><c r="F2" s="2"><v>44256</v></c></row>`,
+		`<row r="3"><c r="A3"><v>2</v></c><c r="B3" t="inlineStr"><is><t>deposit</t></is></c><c r="C3"><v>1</v></c><c r="D3"><v>2</v></c><c r="E3" s="3"><v>100.5</v></c><c r="F3" s="2"><v>44256.5</v></c></row>`,
+	}
+	for _, row := range expectedRows {
+		if !strings.Contains(sheet, row) {
+			t.Errorf("Sheet does not contain row '%s'", row)
+		}
+	}
+}
+
+// Test marshalling of summary rows for all formats
+func TestFileMarshallSummary(t *testing.T) {
+	summary := &entities.OperationSummary{
+		Period:    time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC),
+		Operation: sql.NullString{String: "deposit", Valid: true},
+		Currency:  sql.NullString{String: "USD", Valid: true},
+		Count:     3,
+		Total:     decimal.RequireFromString("150.50"),
+	}
+
+	jsonBuf := &bytes.Buffer{}
+	jsonHandler := NewNDJSONHandler(jsonBuf, &sync.Mutex{}, json.Marshal)
+	mr, _ := jsonHandler.MarshallRow(SummaryRow{summary})
+	_ = jsonHandler.WriteToFile(mr)
+	expectedJSON := `{"period":"2021-03-01","operation":"deposit","currency":"USD","count":3,"total":"150.5"}` + "\n"
+	if jsonBuf.String() != expectedJSON {
+		t.Errorf("Wrong json. Expected %s, got %s", expectedJSON, jsonBuf.String())
+	}
+
+	options := DefaultFormatOptions()
+	options.Columns = []string{"period", "operation", "wallet", "currency", "count", "total"}
+	options.DecimalSeparator = ","
+	options.NullValue = "-"
+	csvHandler := CSVHandler{
+		mu:        &sync.Mutex{},
+		csvWriter: csv.NewWriter(os.Stdout),
+		options:   options,
+	}
+	mr, _ = csvHandler.MarshallRow(SummaryRow{summary})
+	row := strings.Join(mr.data.([]string), "|")
+	if row != "2021-03-01|deposit|-|USD|3|150,5" {
+		t.Errorf("Wrong csv row: %s", row)
+	}
+
+	xlsxBuf := &bytes.Buffer{}
+	xw, _ := NewXLSXWriter(xlsxBuf, "Report", xlsxColumns(options.Columns))
+	xlsxHandler := &XLSXHandler{xlsxWriter: xw, mu: &sync.Mutex{}, columns: options.Columns}
+	mr, _ = xlsxHandler.MarshallRow(SummaryRow{summary})
+	_ = xlsxHandler.WriteToFile(mr)
+	_ = xlsxHandler.Close()
+	sheet := readXLSXPart(t, xlsxBuf.Bytes(), "xl/worksheets/sheet1.xml")
+	expectedRow := `<row r="2"><c r="A2" s="4"><v>44256</v></c><c r="B2" t="inlineStr"><is><t>deposit</t></is></c><c r="D2" t="inlineStr"><is><t>USD</t></is></c><c r="E2"><v>3</v></c><c r="F2" s="3"><v>150.5</v></c></row>`
+	if !strings.Contains(sheet, expectedRow) {
+		t.Errorf("Sheet does not contain row '%s'", expectedRow)
+	}
+}
+
+// Test marshalling of exported rows for all formats
+func TestFileMarshallRow(t *testing.T) {
+	columns := ExportColumns[ExportUsers]
+	withWallet := UserRow{
+		ID:       1,
+		Email:    "user@example.com",
+		WalletID: sql.NullInt32{Int32: 5, Valid: true},
+		Currency: sql.NullString{String: "USD", Valid: true},
+		Balance:  decimal.NullDecimal{Decimal: decimal.RequireFromString("10.50"), Valid: true},
+	}
+	withoutWallet := UserRow{ID: 2, Email: "new@example.com"}
+
+	jsonBuf := &bytes.Buffer{}
+	jsonHandler := NewNDJSONHandler(jsonBuf, &sync.Mutex{}, json.Marshal)
+	jsonHandler.columns = columns
+	jsonHandler.amountFormat = AmountAsNumber
+	for _, row := range []ReportRow{withWallet, withoutWallet} {
+		mr, _ := jsonHandler.MarshallRow(row)
+		_ = jsonHandler.WriteToFile(mr)
+	}
+	expectedJSON := `{"id":1,"email":"user@example.com","wallet_id":5,"currency":"USD","balance":10.5}` + "\n" +
+		`{"id":2,"email":"new@example.com","wallet_id":null,"currency":null,"balance":null}` + "\n"
+	if jsonBuf.String() != expectedJSON {
+		t.Errorf("Wrong json. Expected %s, got %s", expectedJSON, jsonBuf.String())
+	}
+
+	options := DefaultFormatOptions()
+	options.Columns = ExportColumns[ExportBalances]
+	options.NullValue = "-"
+	csvHandler := CSVHandler{
+		mu:        &sync.Mutex{},
+		csvWriter: csv.NewWriter(os.Stdout),
+		options:   options,
+	}
+	asOf := time.Date(2021, time.March, 1, 12, 30, 0, 0, time.UTC)
+	mr, _ := csvHandler.MarshallRow(BalanceRow{Currency: "EUR", Wallets: 2, Total: decimal.RequireFromString("99.90"), AsOf: asOf})
+	row := strings.Join(mr.data.([]string), "|")
+	if row != "EUR|2|99.9|2021-03-01T12:30:00Z" {
+		t.Errorf("Wrong csv row: %s", row)
+	}
+	options.Columns = columns
+	mr, _ = csvHandler.MarshallRow(withoutWallet)
+	if row = strings.Join(mr.data.([]string), "|"); row != "2|new@example.com|-|-|-" {
+		t.Errorf("Wrong csv row: %s", row)
+	}
+
+	xlsxBuf := &bytes.Buffer{}
+	xw, _ := NewXLSXWriter(xlsxBuf, "Report", xlsxColumns(columns))
+	xlsxHandler := &XLSXHandler{xlsxWriter: xw, mu: &sync.Mutex{}, columns: columns}
+	mr, _ = xlsxHandler.MarshallRow(withWallet)
+	_ = xlsxHandler.WriteToFile(mr)
+	_ = xlsxHandler.Close()
+	sheet := readXLSXPart(t, xlsxBuf.Bytes(), "xl/worksheets/sheet1.xml")
+	expectedRow := `<row r="2"><c r="A2"><v>1</v></c><c r="B2" t="inlineStr"><is><t>user@example.com</t></is></c><c r="C2"><v>5</v></c><c r="D2" t="inlineStr"><is><t>USD</t></is></c><c r="E2" s="3"><v>10.5</v></c></row>`
+	if !strings.Contains(sheet, expectedRow) {
+		t.Errorf("Sheet does not contain row '%s'", expectedRow)
+	}
+}
+
+// Test formats with fixed schema declare kinds of their rows
+func TestSupportsRows(t *testing.T) {
+	rows := []ReportRow{OperationRow{}, SummaryRow{}, UserRow{}, BalanceRow{}}
+	kinds := []string{OperationRows, SummaryRows, ExportUsers, ExportBalances}
+	for idx, row := range rows {
+		if row.Kind() != kinds[idx] {
+			t.Errorf("Expected kind %s, got %s", kinds[idx], row.Kind())
+		}
+		for _, format := range []string{"json", "ndjson", "csv", "xlsx", "msgpack"} {
+			if !SupportsRows(format, row.Kind()) {
+				t.Errorf("Expected %s rows in %s format", row.Kind(), format)
+			}
+		}
+		for _, format := range []string{"protobuf", TemplateFormat, "camt053", "ofx", "qif"} {
+			if SupportsRows(format, row.Kind()) != (row.Kind() == OperationRows) {
+				t.Errorf("Unexpected support of %s rows in %s format", row.Kind(), format)
+			}
+		}
+	}
+}
+
 // Benchmark json marshalling
 func BenchmarkMarshallOperationJSON(b *testing.B) {
 	wo := &entities.WalletOperation{
@@ -196,7 +536,7 @@ func BenchmarkMarshallOperationJSON(b *testing.B) {
 	}
 
 	for i := 0; i < b.N; i++ {
-		_, _ = handler.MarshallOperation(wo)
+		_, _ = handler.MarshallRow(OperationRow{wo})
 	}
 }
 
@@ -215,10 +555,11 @@ func BenchmarkMarshallOperationCSV(b *testing.B) {
 	handler := CSVHandler{
 		mu:        mu,
 		csvWriter: csvWriter,
+		options:   DefaultFormatOptions(),
 	}
 
 	for i := 0; i < b.N; i++ {
-		_, _ = handler.MarshallOperation(operation)
+		_, _ = handler.MarshallRow(OperationRow{operation})
 	}
 }
 
@@ -238,7 +579,7 @@ func BenchmarkWriteToFileJSON(b *testing.B) {
 		mu:       &sync.Mutex{},
 		marshall: json.Marshal,
 	}
-	mr, _ := handler.MarshallOperation(wo)
+	mr, _ := handler.MarshallRow(OperationRow{wo})
 	for i := 0; i < b.N; i++ {
 		_ = handler.WriteToFile(mr)
 	}
@@ -260,8 +601,9 @@ func BenchmarkWriteToFileCSV(b *testing.B) {
 	handler := CSVHandler{
 		mu:        mu,
 		csvWriter: csvWriter,
+		options:   DefaultFormatOptions(),
 	}
-	mr, _ := handler.MarshallOperation(wo)
+	mr, _ := handler.MarshallRow(OperationRow{wo})
 	for i := 0; i < b.N; i++ {
 		_ = handler.WriteToFile(mr)
 	}
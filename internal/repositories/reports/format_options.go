@@ -0,0 +1,68 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/entities"
+	"time"
+)
+
+// Columns available in operations reports
+var operationColumns = []string{
+	"id", "operation", "wallet_from", "wallet_to", "amount", "created_at",
+}
+
+// Columns available in summary reports
+var summaryColumns = []string{
+	"period", GroupByOperation, GroupByWallet, GroupByCurrency, "count", "total",
+}
+
+// Named layouts for report's timestamps; any other value is treated as go time layout
+var timeLayouts = map[string]string{
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+	"datetime":    "2006-01-02 15:04:05",
+	"date":        "2006-01-02",
+}
+
+// FormatOptions represents options of the report's output format
+type FormatOptions struct {
+	AmountFormat string
+
+	// CSV dialect
+	Columns          []string
+	Delimiter        rune
+	Header           bool
+	TimeLayout       string
+	Location         *time.Location
+	DecimalSeparator string
+	NullValue        string
+
+	// Account statement of camt053 report
+	Statement *entities.AccountStatement
+
+	// User-defined template; it is loaded by name from templates storage
+	TemplateName string
+	Template     *CompiledTemplate
+}
+
+// DefaultFormatOptions returns options used when query parameters are not set
+func DefaultFormatOptions() *FormatOptions {
+	columns := make([]string, len(operationColumns))
+	copy(columns, operationColumns)
+	return &FormatOptions{
+		AmountFormat:     AmountAsString,
+		Columns:          columns,
+		Delimiter:        ',',
+		Header:           true,
+		TimeLayout:       time.RFC3339,
+		Location:         time.UTC,
+		DecimalSeparator: ".",
+		NullValue:        "",
+	}
+}
+
+// summaryReportColumns returns default columns of summary report: period, grouping dimensions and totals
+func summaryReportColumns(groupBy []string) []string {
+	columns := []string{"period"}
+	columns = append(columns, groupBy...)
+	return append(columns, "count", "total")
+}
@@ -0,0 +1,150 @@
+package reports
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// MsgpackHandler implements FileMarshallingManager interface for MessagePack format.
+// Report is a stream of maps, one per row, keyed by selected columns; it is read
+// with reportcodec.MsgpackReader.
+type MsgpackHandler struct {
+	file         io.Writer
+	mu           *sync.Mutex
+	columns      []string
+	amountFormat string
+}
+
+// NewMsgpackHandler returns handler which writes rows with given columns
+func NewMsgpackHandler(file io.Writer, mu *sync.Mutex, columns []string, amountFormat string) *MsgpackHandler {
+	return &MsgpackHandler{
+		file:         file,
+		mu:           mu,
+		columns:      columns,
+		amountFormat: amountFormat,
+	}
+}
+
+// MarshallRow marshal row to msgpack map with selected columns.
+// Amounts are strings unless numbers are requested; timestamps use msgpack timestamp extension.
+func (mh *MsgpackHandler) MarshallRow(row ReportRow) (*MarshalledResult, error) {
+	data := appendMsgpackMapHeader(make([]byte, 0, 128), len(mh.columns))
+	for _, column := range mh.columns {
+		data = appendMsgpackString(data, column)
+		switch v := row.Value(column).(type) {
+		case nil:
+			data = append(data, 0xc0)
+		case int:
+			data = appendMsgpackInt(data, int64(v))
+		case string:
+			data = appendMsgpackString(data, v)
+		case decimal.Decimal:
+			if mh.amountFormat == AmountAsNumber {
+				amount, _ := v.Float64()
+				data = appendMsgpackFloat(data, amount)
+			} else {
+				data = appendMsgpackString(data, v.String())
+			}
+		case time.Time:
+			data = appendMsgpackTime(data, v)
+		case ReportDate:
+			data = appendMsgpackString(data, time.Time(v).Format(summaryPeriodLayout))
+		default:
+			return nil, fmt.Errorf("unsupported value of '%s' column: %T", column, v)
+		}
+	}
+	return &MarshalledResult{
+		id:   row.RowID(),
+		data: data,
+	}, nil
+}
+
+// WriteToFile writes msgpack map
+func (mh *MsgpackHandler) WriteToFile(mr *MarshalledResult) error {
+	mh.mu.Lock()
+	defer mh.mu.Unlock()
+	if _, writeErr := mh.file.Write(mr.data.([]byte)); writeErr != nil {
+		return fmt.Errorf("error of msgpack writing: %s", writeErr)
+	}
+	return nil
+}
+
+// Close does nothing: msgpack stream has no closing records
+func (mh *MsgpackHandler) Close() error {
+	return nil
+}
+
+func appendMsgpackMapHeader(data []byte, size int) []byte {
+	switch {
+	case size < 16:
+		return append(data, 0x80|byte(size))
+	case size <= math.MaxUint16:
+		return append(data, 0xde, byte(size>>8), byte(size))
+	}
+	return append(append(data, 0xdf), uint32Bytes(uint32(size))...)
+}
+
+func appendMsgpackString(data []byte, value string) []byte {
+	size := len(value)
+	switch {
+	case size < 32:
+		data = append(data, 0xa0|byte(size))
+	case size <= math.MaxUint8:
+		data = append(data, 0xd9, byte(size))
+	case size <= math.MaxUint16:
+		data = append(data, 0xda, byte(size>>8), byte(size))
+	default:
+		data = append(append(data, 0xdb), uint32Bytes(uint32(size))...)
+	}
+	return append(data, value...)
+}
+
+// appendMsgpackInt writes integer in the shortest signed encoding
+func appendMsgpackInt(data []byte, value int64) []byte {
+	switch {
+	case value >= 0 && value <= math.MaxInt8:
+		return append(data, byte(value))
+	case value < 0 && value >= -32:
+		return append(data, byte(int8(value)))
+	case value >= math.MinInt8 && value <= math.MaxInt8:
+		return append(data, 0xd0, byte(value))
+	case value >= math.MinInt16 && value <= math.MaxInt16:
+		return append(data, 0xd1, byte(value>>8), byte(value))
+	case value >= math.MinInt32 && value <= math.MaxInt32:
+		return append(append(data, 0xd2), uint32Bytes(uint32(value))...)
+	}
+	buffer := make([]byte, 8)
+	binary.BigEndian.PutUint64(buffer, uint64(value))
+	return append(append(data, 0xd3), buffer...)
+}
+
+func appendMsgpackFloat(data []byte, value float64) []byte {
+	buffer := make([]byte, 8)
+	binary.BigEndian.PutUint64(buffer, math.Float64bits(value))
+	return append(append(data, 0xcb), buffer...)
+}
+
+// appendMsgpackTime writes time as timestamp extension (type -1) in the shortest format
+func appendMsgpackTime(data []byte, value time.Time) []byte {
+	seconds, nanos := value.Unix(), uint32(value.Nanosecond())
+	if seconds>>34 == 0 {
+		packed := uint64(nanos)<<34 | uint64(seconds)
+		if packed>>32 == 0 {
+			return append(append(data, 0xd6, 0xff), uint32Bytes(uint32(packed))...)
+		}
+		buffer := make([]byte, 8)
+		binary.BigEndian.PutUint64(buffer, packed)
+		return append(append(data, 0xd7, 0xff), buffer...)
+	}
+	buffer := make([]byte, 8)
+	binary.BigEndian.PutUint64(buffer, uint64(seconds))
+	data = append(data, 0xc7, 12, 0xff)
+	data = append(data, uint32Bytes(nanos)...)
+	return append(data, buffer...)
+}
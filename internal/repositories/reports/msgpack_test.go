@@ -0,0 +1,113 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/pkg/reportcodec"
+	"bytes"
+	"database/sql"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Test msgpack report of operations is read back with public decoder
+func TestMsgpackHandlerRoundTrip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler, handlerErr := NewFileHandler(NewMemoryStorage(), nil, nil).CreateMarshaller(buf, "msgpack", nil, nil)
+	if handlerErr != nil {
+		t.Fatalf("unexpected error: %s", handlerErr)
+	}
+	for _, operation := range camtOperations {
+		mr, _ := handler.MarshallRow(OperationRow{operation})
+		if writeErr := handler.WriteToFile(mr); writeErr != nil {
+			t.Fatalf("unexpected error: %s", writeErr)
+		}
+	}
+	_ = handler.Close()
+
+	reader := reportcodec.NewMsgpackReader(buf)
+	first, readErr := reader.Next()
+	if readErr != nil {
+		t.Fatalf("unexpected error: %s", readErr)
+	}
+	expected := map[string]interface{}{
+		"id":          int64(1),
+		"operation":   "create wallet",
+		"wallet_from": nil,
+		"wallet_to":   int64(1),
+		"amount":      "0",
+		"created_at":  time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(first, expected) {
+		t.Errorf("Wrong row.\nExpected: %v\nGot:      %v", expected, first)
+	}
+	rows := 1
+	for {
+		row, nextErr := reader.Next()
+		if nextErr == io.EOF {
+			break
+		}
+		if nextErr != nil {
+			t.Fatalf("unexpected error: %s", nextErr)
+		}
+		if row["id"] != int64(camtOperations[rows].ID) || row["amount"] != camtOperations[rows].Amount.String() {
+			t.Errorf("Wrong row: %v", row)
+		}
+		rows++
+	}
+	if rows != len(camtOperations) {
+		t.Errorf("Expected %d rows, got %d", len(camtOperations), rows)
+	}
+}
+
+// Test msgpack rows with selected columns, numeric amounts and dates
+func TestMsgpackHandlerMarshallRow(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := NewMsgpackHandler(buf, &sync.Mutex{}, []string{"period", "wallet", "count", "total"}, AmountAsNumber)
+	summary := &entities.OperationSummary{
+		Period:   time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC),
+		WalletID: sql.NullInt32{Int32: 70000, Valid: true},
+		Count:    -200,
+		Total:    decimal.RequireFromString("150.25"),
+	}
+	mr, _ := handler.MarshallRow(SummaryRow{summary})
+	_ = handler.WriteToFile(mr)
+	mr, _ = handler.MarshallRow(UserRow{ID: 1, Email: strings.Repeat("a", 300)})
+	_ = handler.WriteToFile(mr)
+
+	reader := reportcodec.NewMsgpackReader(buf)
+	row, readErr := reader.Next()
+	if readErr != nil {
+		t.Fatalf("unexpected error: %s", readErr)
+	}
+	expected := map[string]interface{}{"period": "2021-03-01", "wallet": int64(70000), "count": int64(-200), "total": 150.25}
+	if !reflect.DeepEqual(row, expected) {
+		t.Errorf("Wrong row.\nExpected: %v\nGot:      %v", expected, row)
+	}
+	if row, readErr = reader.Next(); readErr != nil || len(row) != 4 || row["period"] != nil {
+		t.Errorf("Wrong row of unknown columns: %v (%v)", row, readErr)
+	}
+}
+
+// Test msgpack timestamps in all formats of extension
+func TestAppendMsgpackTime(t *testing.T) {
+	for _, value := range []time.Time{
+		time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, time.March, 1, 0, 0, 0, 500, time.UTC),
+		time.Date(1969, time.December, 31, 23, 59, 59, 1, time.UTC),
+		time.Date(2600, time.January, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		data := appendMsgpackMapHeader(nil, 1)
+		data = appendMsgpackString(data, "t")
+		data = appendMsgpackTime(data, value)
+		row, readErr := reportcodec.NewMsgpackReader(bytes.NewReader(data)).Next()
+		if readErr != nil || !row["t"].(time.Time).Equal(value) {
+			t.Errorf("Wrong timestamp %s: %v (%v)", value, row["t"], readErr)
+		}
+	}
+}
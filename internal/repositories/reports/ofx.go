@@ -0,0 +1,197 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/entities"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	ofxDateTimeLayout = "20060102150405"
+	ofxBankID         = "BILLING"
+)
+
+type ofxStatus struct {
+	XMLName  xml.Name `xml:"STATUS"`
+	Code     int      `xml:"CODE"`
+	Severity string   `xml:"SEVERITY"`
+}
+
+type ofxSignOn struct {
+	XMLName  xml.Name  `xml:"SIGNONMSGSRSV1"`
+	Status   ofxStatus `xml:"SONRS>STATUS"`
+	Server   string    `xml:"SONRS>DTSERVER"`
+	Language string    `xml:"SONRS>LANGUAGE"`
+}
+
+type ofxAccount struct {
+	XMLName xml.Name `xml:"BANKACCTFROM"`
+	BankID  string   `xml:"BANKID"`
+	ID      string   `xml:"ACCTID"`
+	Type    string   `xml:"ACCTTYPE"`
+}
+
+type ofxTransaction struct {
+	XMLName xml.Name `xml:"STMTTRN"`
+	Type    string   `xml:"TRNTYPE"`
+	Posted  string   `xml:"DTPOSTED"`
+	Amount  string   `xml:"TRNAMT"`
+	FITID   string   `xml:"FITID"`
+	Name    string   `xml:"NAME"`
+	Memo    string   `xml:"MEMO,omitempty"`
+}
+
+type ofxBalance struct {
+	XMLName xml.Name `xml:"LEDGERBAL"`
+	Amount  string   `xml:"BALAMT"`
+	AsOf    string   `xml:"DTASOF"`
+}
+
+// OFXHandler implements FileMarshallingManager interface for OFX 2.2 bank statement.
+// Money in is written with positive amount, money out - with negative one.
+type OFXHandler struct {
+	encoder   *xml.Encoder
+	mu        *sync.Mutex
+	statement *entities.AccountStatement
+	end       time.Time
+}
+
+// NewOFXHandler writes statement's header and opens list of transactions
+func NewOFXHandler(w io.Writer, mu *sync.Mutex, statement *entities.AccountStatement, createdAt time.Time) (*OFXHandler, error) {
+	if _, writeErr := io.WriteString(w, xml.Header); writeErr != nil {
+		return nil, fmt.Errorf("error of ofx header writing: %s", writeErr)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	// Statement without start date covers the whole history of the wallet
+	createdAt = createdAt.UTC()
+	start, end := time.Unix(0, 0).UTC(), createdAt
+	if !statement.From.IsZero() {
+		start = statement.From
+	}
+	if !statement.To.IsZero() {
+		end = statement.To.Add(24*time.Hour - time.Second)
+	}
+	status := ofxStatus{Code: 0, Severity: "INFO"}
+
+	header := []interface{}{
+		xml.ProcInst{Target: "OFX", Inst: []byte(`OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"`)},
+		xml.StartElement{Name: xml.Name{Local: "OFX"}},
+		ofxSignOn{Status: status, Server: createdAt.Format(ofxDateTimeLayout), Language: "ENG"},
+		xml.StartElement{Name: xml.Name{Local: "BANKMSGSRSV1"}},
+		xml.StartElement{Name: xml.Name{Local: "STMTTRNRS"}},
+		struct {
+			XMLName xml.Name `xml:"TRNUID"`
+			Value   string   `xml:",chardata"`
+		}{Value: statementID(statement, createdAt)},
+		status,
+		xml.StartElement{Name: xml.Name{Local: "STMTRS"}},
+		struct {
+			XMLName xml.Name `xml:"CURDEF"`
+			Value   string   `xml:",chardata"`
+		}{Value: statement.Currency},
+		ofxAccount{BankID: ofxBankID, ID: strconv.Itoa(statement.WalletID), Type: "CHECKING"},
+		xml.StartElement{Name: xml.Name{Local: "BANKTRANLIST"}},
+		struct {
+			XMLName xml.Name `xml:"DTSTART"`
+			Value   string   `xml:",chardata"`
+		}{Value: start.Format(ofxDateTimeLayout)},
+		struct {
+			XMLName xml.Name `xml:"DTEND"`
+			Value   string   `xml:",chardata"`
+		}{Value: end.Format(ofxDateTimeLayout)},
+	}
+	for _, element := range header {
+		var encodeErr error
+		switch token := element.(type) {
+		case xml.ProcInst, xml.StartElement:
+			encodeErr = encoder.EncodeToken(token)
+		default:
+			encodeErr = encoder.Encode(element)
+		}
+		if encodeErr != nil {
+			return nil, fmt.Errorf("error of ofx header writing: %s", encodeErr)
+		}
+	}
+	if flushErr := encoder.Flush(); flushErr != nil {
+		return nil, fmt.Errorf("error of ofx header writing: %s", flushErr)
+	}
+
+	return &OFXHandler{
+		encoder:   encoder,
+		mu:        mu,
+		statement: statement,
+		end:       end,
+	}, nil
+}
+
+// MarshallRow converts wallet's deposit or withdrawal to statement transaction; other operations are skipped
+func (oh *OFXHandler) MarshallRow(row ReportRow) (*MarshalledResult, error) {
+	operation, rowErr := rowOperation(row, "ofx")
+	if rowErr != nil {
+		return nil, rowErr
+	}
+	sign := statementEntrySign(oh.statement, operation)
+	if sign == 0 {
+		return &MarshalledResult{id: operation.ID}, nil
+	}
+	transactionType, amount := "CREDIT", operation.Amount
+	if sign < 0 {
+		transactionType, amount = "DEBIT", amount.Neg()
+	}
+	return &MarshalledResult{
+		id: operation.ID,
+		data: ofxTransaction{
+			Type:   transactionType,
+			Posted: operation.CreatedAt.Format(ofxDateTimeLayout),
+			Amount: amount.StringFixed(2),
+			FITID:  operationReference(operation),
+			Name:   operation.Operation,
+			Memo:   transferInfo(operation, sign),
+		},
+	}, nil
+}
+
+// WriteToFile writes statement transaction
+func (oh *OFXHandler) WriteToFile(mr *MarshalledResult) error {
+	transaction, isTransaction := mr.data.(ofxTransaction)
+	if !isTransaction {
+		return nil
+	}
+	oh.mu.Lock()
+	defer oh.mu.Unlock()
+	if encodeErr := oh.encoder.Encode(transaction); encodeErr != nil {
+		return fmt.Errorf("error of ofx transaction writing: %s", encodeErr)
+	}
+	return nil
+}
+
+// Close closes list of transactions and writes balance at the end of the period
+func (oh *OFXHandler) Close() error {
+	oh.mu.Lock()
+	defer oh.mu.Unlock()
+	if tokenErr := oh.encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: "BANKTRANLIST"}}); tokenErr != nil {
+		return fmt.Errorf("error of ofx closing: %s", tokenErr)
+	}
+	balance := ofxBalance{
+		Amount: oh.statement.ClosingBalance.StringFixed(2),
+		AsOf:   oh.end.Format(ofxDateTimeLayout),
+	}
+	if encodeErr := oh.encoder.Encode(balance); encodeErr != nil {
+		return fmt.Errorf("error of ofx closing: %s", encodeErr)
+	}
+	for _, name := range []string{"STMTRS", "STMTTRNRS", "BANKMSGSRSV1", "OFX"} {
+		if tokenErr := oh.encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}}); tokenErr != nil {
+			return fmt.Errorf("error of ofx closing: %s", tokenErr)
+		}
+	}
+	if flushErr := oh.encoder.Flush(); flushErr != nil {
+		return fmt.Errorf("error of ofx closing: %s", flushErr)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package reports
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Test ofx statement transactions, signs and closing balance
+func TestOFXHandlerStatement(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler, handlerErr := NewOFXHandler(buf, &sync.Mutex{}, camtStatement, time.Date(2021, time.April, 1, 8, 0, 0, 0, time.UTC))
+	if handlerErr != nil {
+		t.Fatalf("unexpected error: %s", handlerErr)
+	}
+	for _, operation := range camtOperations {
+		mr, _ := handler.MarshallRow(OperationRow{operation})
+		if writeErr := handler.WriteToFile(mr); writeErr != nil {
+			t.Fatalf("unexpected error: %s", writeErr)
+		}
+	}
+	if closeErr := handler.Close(); closeErr != nil {
+		t.Fatalf("unexpected error: %s", closeErr)
+	}
+
+	var document struct {
+		Currency     string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>CURDEF"`
+		Account      string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKACCTFROM>ACCTID"`
+		Start        string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKTRANLIST>DTSTART"`
+		End          string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKTRANLIST>DTEND"`
+		Transactions []ofxTransaction `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKTRANLIST>STMTTRN"`
+		Balance      ofxBalance       `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>LEDGERBAL"`
+	}
+	if unmarshallErr := xml.Unmarshal(buf.Bytes(), &document); unmarshallErr != nil {
+		t.Fatalf("statement is not well-formed: %s\n%s", unmarshallErr, buf.String())
+	}
+	if !strings.Contains(buf.String(), `<?OFX OFXHEADER="200" VERSION="220"`) {
+		t.Errorf("OFX header is missing:\n%s", buf.String())
+	}
+	if document.Currency != "USD" || document.Account != "1" || document.Start != "20210301000000" || document.End != "20210331235959" {
+		t.Errorf("Wrong statement header: %+v", document)
+	}
+	if len(document.Transactions) != 2 {
+		t.Fatalf("Expected 2 transactions, got %d", len(document.Transactions))
+	}
+	credit, debit := document.Transactions[0], document.Transactions[1]
+	if credit.Type != "CREDIT" || credit.Amount != "30.50" || credit.FITID != "OP-2" || credit.Posted != "20210302113000" {
+		t.Errorf("Wrong credit transaction: %+v", credit)
+	}
+	if debit.Type != "DEBIT" || debit.Amount != "-10.00" || debit.FITID != "OP-3" || debit.Memo != "Transfer to wallet 2" {
+		t.Errorf("Wrong debit transaction: %+v", debit)
+	}
+	if document.Balance.Amount != "15.50" || document.Balance.AsOf != "20210331235959" {
+		t.Errorf("Wrong ledger balance: %+v", document.Balance)
+	}
+}
@@ -0,0 +1,95 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/entities"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Layout of periods in summary reports
+const summaryPeriodLayout = "2006-01-02"
+
+// Encodings of amounts in json reports
+const (
+	AmountAsString = "string"
+	AmountAsNumber = "number"
+)
+
+// OperationReport represents wallet operation in json reports
+type OperationReport struct {
+	ID         int          `json:"id"`
+	Operation  string       `json:"operation"`
+	WalletFrom *int         `json:"wallet_from"`
+	WalletTo   int          `json:"wallet_to"`
+	Amount     ReportAmount `json:"amount"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// ReportAmount encodes decimal amount as json string or json number
+type ReportAmount struct {
+	Value    decimal.Decimal
+	AsNumber bool
+}
+
+// NewOperationReport converts entities.WalletOperation to its report representation
+func NewOperationReport(operation *entities.WalletOperation, amountFormat string) *OperationReport {
+	var walletFrom *int
+	if operation.WalletFrom.Valid {
+		id := int(operation.WalletFrom.Int32)
+		walletFrom = &id
+	}
+	return &OperationReport{
+		ID:         operation.ID,
+		Operation:  operation.Operation,
+		WalletFrom: walletFrom,
+		WalletTo:   operation.WalletTo,
+		Amount: ReportAmount{
+			Value:    operation.Amount,
+			AsNumber: amountFormat == AmountAsNumber,
+		},
+		CreatedAt: operation.CreatedAt,
+	}
+}
+
+// SummaryReport represents aggregated operations in json reports; dimensions without grouping are omitted
+type SummaryReport struct {
+	Period    string       `json:"period"`
+	Operation *string      `json:"operation,omitempty"`
+	Wallet    *int         `json:"wallet,omitempty"`
+	Currency  *string      `json:"currency,omitempty"`
+	Count     int          `json:"count"`
+	Total     ReportAmount `json:"total"`
+}
+
+// NewSummaryReport converts entities.OperationSummary to its report representation
+func NewSummaryReport(summary *entities.OperationSummary, amountFormat string) *SummaryReport {
+	report := &SummaryReport{
+		Period: summary.Period.Format(summaryPeriodLayout),
+		Count:  summary.Count,
+		Total: ReportAmount{
+			Value:    summary.Total,
+			AsNumber: amountFormat == AmountAsNumber,
+		},
+	}
+	if summary.Operation.Valid {
+		report.Operation = &summary.Operation.String
+	}
+	if summary.WalletID.Valid {
+		wallet := int(summary.WalletID.Int32)
+		report.Wallet = &wallet
+	}
+	if summary.Currency.Valid {
+		report.Currency = &summary.Currency.String
+	}
+	return report
+}
+
+// MarshalJSON returns amount as json number or quoted string (default)
+func (ra ReportAmount) MarshalJSON() ([]byte, error) {
+	if ra.AsNumber {
+		return []byte(ra.Value.String()), nil
+	}
+	return []byte(strconv.Quote(ra.Value.String())), nil
+}
@@ -0,0 +1,59 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/entities"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Test json encoding of report's operation
+func TestOperationReportJSON(t *testing.T) {
+	createdAt := time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		operation    *entities.WalletOperation
+		amountFormat string
+		expected     string
+	}{
+		{
+			name: "Empty wallet_from and amount as string",
+			operation: &entities.WalletOperation{
+				ID:        1,
+				Operation: "create wallet",
+				WalletTo:  1,
+				Amount:    decimal.RequireFromString("100.50"),
+				CreatedAt: createdAt,
+			},
+			amountFormat: AmountAsString,
+			expected:     `{"id":1,"operation":"create wallet","wallet_from":null,"wallet_to":1,"amount":"100.5","created_at":"2021-03-01T12:00:00Z"}`,
+		},
+		{
+			name: "Filled wallet_from and amount as number",
+			operation: &entities.WalletOperation{
+				ID:         2,
+				Operation:  "transfer",
+				WalletFrom: sql.NullInt32{Int32: 5, Valid: true},
+				WalletTo:   1,
+				Amount:     decimal.RequireFromString("0.01"),
+				CreatedAt:  createdAt,
+			},
+			amountFormat: AmountAsNumber,
+			expected:     `{"id":2,"operation":"transfer","wallet_from":5,"wallet_to":1,"amount":0.01,"created_at":"2021-03-01T12:00:00Z"}`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(NewOperationReport(tc.operation, tc.amountFormat))
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if string(data) != tc.expected {
+				t.Errorf("Wrong json. Expected %s, got %s", tc.expected, data)
+			}
+		})
+	}
+}
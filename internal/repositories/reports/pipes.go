@@ -6,12 +6,21 @@ import (
 	"billing_system_test_task/internal/repositories"
 	"context"
 	"fmt"
-	"sync"
+)
+
+const (
+	operationsPipelineName = "operations_report"
+	summaryPipelineName    = "summary_report"
+	exportPipelineSuffix   = "_report"
+	readBufferSize         = 64
+	marshallBufferSize     = 64
 )
 
 // PipelineManager defines operations for processing entities.WalletOperation
 type PipelineManager interface {
-	Process(ctx context.Context, or repositories.OperationsManager, listParams *repositories.ListParams, marshaller FileMarshallingManager) error
+	Process(ctx context.Context, or repositories.OperationsManager, listParams *repositories.ListParams, marshaller FileMarshallingManager) ([]pipeline.StageStats, error)
+	ProcessSummary(ctx context.Context, sm SummaryManager, summaryParams *SummaryParams, marshaller FileMarshallingManager) ([]pipeline.StageStats, error)
+	ProcessExport(ctx context.Context, em ExportManager, export string, marshaller FileMarshallingManager) ([]pipeline.StageStats, error)
 }
 
 // OperationsProcessesManager represents PipelineManager interface
@@ -21,125 +30,161 @@ func NewOperationsProcessesManager() *OperationsProcessesManager {
 	return &OperationsProcessesManager{}
 }
 
-// Process runs pipeline through all the stages
-func (op OperationsProcessesManager) Process(ctx context.Context, or repositories.OperationsManager, listParams *repositories.ListParams, marshaller FileMarshallingManager) error {
-	var (
-		wg     = &sync.WaitGroup{}
-		errors = make(chan error, 1)
-	)
+// Process runs pipeline through all the stages and returns metrics of each stage
+func (op OperationsProcessesManager) Process(ctx context.Context, or repositories.OperationsManager, listParams *repositories.ListParams, marshaller FileMarshallingManager) ([]pipeline.StageStats, error) {
 	readPipe := ReadPipe{
 		or:     or,
-		wg:     wg,
 		params: listParams,
-		ctx:    ctx,
-		errors: errors,
 	}
 	marshallPipe := MarshallPipe{
-		wg:     wg,
-		fm:     marshaller,
-		errors: errors,
+		fm: marshaller,
+	}
+	writePipe := WritePipe{
+		fm: marshaller,
+	}
+
+	p := pipeline.New(ctx, operationsPipelineName)
+	operations := pipeline.Source(p, "read", readPipe.Call, pipeline.Buffer(readBufferSize))
+	marshalled := pipeline.Map(p, "marshall", operations, marshallPipe.Call, pipeline.Buffer(marshallBufferSize))
+	pipeline.Sink(p, "write", marshalled, writePipe.Call)
+
+	if err := p.Wait(); err != nil {
+		return p.Stats(), fmt.Errorf("operations processing failed: %s", err)
+	}
+	return p.Stats(), nil
+}
+
+// ProcessSummary runs aggregation in database and writes its rows through the pipeline
+func (op OperationsProcessesManager) ProcessSummary(ctx context.Context, sm SummaryManager, summaryParams *SummaryParams, marshaller FileMarshallingManager) ([]pipeline.StageStats, error) {
+	readPipe := ReadSummaryPipe{
+		sm:     sm,
+		params: summaryParams,
+	}
+	marshallPipe := MarshallPipe{
+		fm: marshaller,
 	}
 	writePipe := WritePipe{
-		wg:     wg,
-		fm:     marshaller,
-		errors: errors,
+		fm: marshaller,
+	}
+
+	p := pipeline.New(ctx, summaryPipelineName)
+	summary := pipeline.Source(p, "read", readPipe.Call)
+	marshalled := pipeline.Map(p, "marshall", summary, marshallPipe.Call)
+	pipeline.Sink(p, "write", marshalled, writePipe.Call)
+
+	if err := p.Wait(); err != nil {
+		return p.Stats(), fmt.Errorf("summary processing failed: %s", err)
+	}
+	return p.Stats(), nil
+}
+
+// ProcessExport reads rows of given export and writes them through the pipeline
+func (op OperationsProcessesManager) ProcessExport(ctx context.Context, em ExportManager, export string, marshaller FileMarshallingManager) ([]pipeline.StageStats, error) {
+	readPipe := ReadExportPipe{
+		em:     em,
+		export: export,
+	}
+	marshallPipe := MarshallPipe{
+		fm: marshaller,
 	}
-	pipes := []pipeline.Pipe{
-		readPipe,
-		marshallPipe,
-		writePipe,
+	writePipe := WritePipe{
+		fm: marshaller,
 	}
 
-	wg.Add(len(pipes))
-	pipeline.ExecutePipeline(pipes...)
-	wg.Wait()
+	p := pipeline.New(ctx, export+exportPipelineSuffix)
+	rows := pipeline.Source(p, "read", readPipe.Call, pipeline.Buffer(readBufferSize))
+	marshalled := pipeline.Map(p, "marshall", rows, marshallPipe.Call, pipeline.Buffer(marshallBufferSize))
+	pipeline.Sink(p, "write", marshalled, writePipe.Call)
 
-	select {
-	case err := <-errors:
-		return fmt.Errorf("operations read failed: %s", err)
-	default:
-		return nil
+	if err := p.Wait(); err != nil {
+		return p.Stats(), fmt.Errorf("%s export processing failed: %s", export, err)
 	}
+	return p.Stats(), nil
 }
 
 // ReadPipe represents reading part of pipeline
 type ReadPipe struct {
 	or     repositories.OperationsManager
-	errors chan error
-	wg     *sync.WaitGroup
 	params *repositories.ListParams
-	ctx    context.Context
 }
 
 // Call reads rows from database and pass them further throught the pipeline
-func (rp ReadPipe) Call(in, out chan interface{}) {
-	defer rp.wg.Done()
-	var counter int
+func (rp ReadPipe) Call(ctx context.Context, emit func(row ReportRow) error) error {
+	rowsCh, errCh, rowsErr := rp.or.List(ctx, rp.params)
+	if rowsErr != nil {
+		return fmt.Errorf("error of row retrieving: %s", rowsErr)
+	}
+	emitOperation := func(operation *entities.WalletOperation) error {
+		return emit(OperationRow{operation})
+	}
+	if receiveErr := pipeline.Receive(ctx, rowsCh, emitOperation); receiveErr != nil {
+		return receiveErr
+	}
+	if readErr := <-errCh; readErr != nil {
+		return fmt.Errorf("error of row retrieving: %s", readErr)
+	}
+	return nil
+}
 
-	rowsCh, rowsErr := rp.or.List(rp.ctx, rp.params)
+// ReadSummaryPipe represents reading part of summary pipeline
+type ReadSummaryPipe struct {
+	sm     SummaryManager
+	params *SummaryParams
+}
+
+// Call aggregates operations in database and pass rows further throught the pipeline
+func (rsp ReadSummaryPipe) Call(ctx context.Context, emit func(row ReportRow) error) error {
+	rows, rowsErr := rsp.sm.Summary(ctx, rsp.params)
 	if rowsErr != nil {
-		rp.errors <- fmt.Errorf("error of row retrieving: %s", rowsErr)
-		out <- nil
-		return
+		return fmt.Errorf("error of summary retrieving: %s", rowsErr)
 	}
-	for operation := range rowsCh {
-		counter++
-		out <- operation
+	for _, row := range rows {
+		if err := emit(SummaryRow{row}); err != nil {
+			return err
+		}
 	}
-	if counter == 0 {
-		out <- nil
+	return nil
+}
+
+// ReadExportPipe represents reading part of export pipeline
+type ReadExportPipe struct {
+	em     ExportManager
+	export string
+}
+
+// Call reads rows of export from database and pass them further throught the pipeline
+func (rep ReadExportPipe) Call(ctx context.Context, emit func(row ReportRow) error) error {
+	if rowsErr := rep.em.Rows(ctx, rep.export, emit); rowsErr != nil {
+		return fmt.Errorf("error of export retrieving: %s", rowsErr)
 	}
+	return nil
 }
 
 // MarshallPipe represents marshalling part of pipeline (to csv or json)
 type MarshallPipe struct {
-	wg     *sync.WaitGroup
-	fm     FileMarshallingManager
-	errors chan error
+	fm FileMarshallingManager
 }
 
-// Call marshall received rows to csv or json
-func (mp MarshallPipe) Call(in, out chan interface{}) {
-	defer mp.wg.Done()
-
-	for chunk := range in {
-		if chunk == nil {
-			out <- nil
-			return
-		}
-		operation := chunk.(*entities.WalletOperation)
-		mr, mrErr := mp.fm.MarshallOperation(operation)
-		if mrErr != nil {
-			out <- nil
-			err := fmt.Errorf("[ERROR] Marshalling error: %s", mrErr)
-			mp.errors <- err
-			return
-		}
-		out <- mr
+// Call marshall received row of operations, summary or export
+func (mp MarshallPipe) Call(ctx context.Context, row ReportRow) (*MarshalledResult, error) {
+	mr, mrErr := mp.fm.MarshallRow(row)
+	if mrErr != nil {
+		return nil, fmt.Errorf("marshalling error: %s", mrErr)
 	}
+	return mr, nil
 }
 
 // WritePipe represents writing to file part of pipeline
 type WritePipe struct {
-	wg     *sync.WaitGroup
-	fm     FileMarshallingManager
-	errors chan error
-}
-
-// Call write receive marshalled items to file
-func (wp WritePipe) Call(in, out chan interface{}) {
-	defer wp.wg.Done()
-
-	for chunk := range in {
-		if chunk != nil {
-			mr := chunk.(*MarshalledResult)
-			writeErr := wp.fm.WriteToFile(mr)
-			if writeErr != nil {
-				wp.errors <- fmt.Errorf("[ERROR] Write to file error: %s", writeErr)
-				return
-			}
-		}
+	fm FileMarshallingManager
+}
+
+// Call write received marshalled item to file
+func (wp WritePipe) Call(ctx context.Context, mr *MarshalledResult) error {
+	if writeErr := wp.fm.WriteToFile(mr); writeErr != nil {
+		return fmt.Errorf("write to file error: %s", writeErr)
 	}
+	return nil
 }
 
 // MarshalledResult represents result of marshalling operation
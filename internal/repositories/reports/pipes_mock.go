@@ -5,6 +5,7 @@
 package reports
 
 import (
+	pipeline "billing_system_test_task/internal/pipeline"
 	repositories "billing_system_test_task/internal/repositories"
 	context "context"
 	gomock "github.com/golang/mock/gomock"
@@ -35,11 +36,12 @@ func (m *MockPipelineManager) EXPECT() *MockPipelineManagerMockRecorder {
 }
 
 // Process mocks base method
-func (m *MockPipelineManager) Process(ctx context.Context, or repositories.OperationsManager, listParams *repositories.ListParams, marshaller FileMarshallingManager) error {
+func (m *MockPipelineManager) Process(ctx context.Context, or repositories.OperationsManager, listParams *repositories.ListParams, marshaller FileMarshallingManager) ([]pipeline.StageStats, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Process", ctx, or, listParams, marshaller)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].([]pipeline.StageStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Process indicates an expected call of Process
@@ -47,3 +49,33 @@ func (mr *MockPipelineManagerMockRecorder) Process(ctx, or, listParams, marshall
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Process", reflect.TypeOf((*MockPipelineManager)(nil).Process), ctx, or, listParams, marshaller)
 }
+
+// ProcessSummary mocks base method
+func (m *MockPipelineManager) ProcessSummary(ctx context.Context, sm SummaryManager, summaryParams *SummaryParams, marshaller FileMarshallingManager) ([]pipeline.StageStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessSummary", ctx, sm, summaryParams, marshaller)
+	ret0, _ := ret[0].([]pipeline.StageStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProcessSummary indicates an expected call of ProcessSummary
+func (mr *MockPipelineManagerMockRecorder) ProcessSummary(ctx, sm, summaryParams, marshaller interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessSummary", reflect.TypeOf((*MockPipelineManager)(nil).ProcessSummary), ctx, sm, summaryParams, marshaller)
+}
+
+// ProcessExport mocks base method
+func (m *MockPipelineManager) ProcessExport(ctx context.Context, em ExportManager, export string, marshaller FileMarshallingManager) ([]pipeline.StageStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessExport", ctx, em, export, marshaller)
+	ret0, _ := ret[0].([]pipeline.StageStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProcessExport indicates an expected call of ProcessExport
+func (mr *MockPipelineManagerMockRecorder) ProcessExport(ctx, em, export, marshaller interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessExport", reflect.TypeOf((*MockPipelineManager)(nil).ProcessExport), ctx, em, export, marshaller)
+}
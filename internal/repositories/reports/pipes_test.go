@@ -7,7 +7,6 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
-	"sync"
 	"testing"
 	"time"
 
@@ -24,12 +23,9 @@ func TestSuccessReadPipe(t *testing.T) {
 	}
 	defer db.Close()
 
-	errCh := make(chan error)
+	var received []ReportRow
 	or := repositories.NewWalletOperationRepo(db)
-	wg := &sync.WaitGroup{}
 	ctx := context.Background()
-	in := make(chan interface{}, 1)
-	out := make(chan interface{}, 1)
 	op := entities.WalletOperation{
 		ID:         1,
 		Operation:  "deposit",
@@ -45,20 +41,22 @@ func TestSuccessReadPipe(t *testing.T) {
 
 	readPipe := ReadPipe{
 		or:     or,
-		wg:     wg,
 		params: nil,
-		ctx:    ctx,
-		errors: errCh,
 	}
 
-	wg.Add(1)
-	go readPipe.Call(in, out)
-	wg.Wait()
+	readErr := readPipe.Call(ctx, func(row ReportRow) error {
+		received = append(received, row)
+		return nil
+	})
+	if readErr != nil {
+		t.Errorf("Unexpected error: %s", readErr)
+	}
 
-	res := <-out
-	chanOp := res.(*entities.WalletOperation)
-	if chanOp.ID != op.ID {
-		t.Errorf("Received ID do not match. Expected %d, got %d", op.ID, chanOp.ID)
+	if len(received) != 1 {
+		t.Fatalf("Expected 1 row, got %d", len(received))
+	}
+	if received[0].RowID() != op.ID {
+		t.Errorf("Received ID do not match. Expected %d, got %d", op.ID, received[0].RowID())
 	}
 }
 
@@ -70,37 +68,23 @@ func TestFailedReadPipeRowsError(t *testing.T) {
 	}
 	defer db.Close()
 
-	errCh := make(chan error, 1)
 	or := repositories.NewWalletOperationRepo(db)
-	wg := &sync.WaitGroup{}
 	ctx := context.Background()
-	in := make(chan interface{}, 1)
-	out := make(chan interface{}, 1)
 
 	mock.ExpectQuery("select").WillReturnError(fmt.Errorf("log error"))
 
 	readPipe := ReadPipe{
 		or:     or,
-		wg:     wg,
 		params: nil,
-		ctx:    ctx,
-		errors: errCh,
 	}
 
-	wg.Add(1)
-	go readPipe.Call(in, out)
-	wg.Wait()
-	// close(out)
-
-	err = <-errCh
+	err = readPipe.Call(ctx, func(row ReportRow) error {
+		t.Errorf("Unexpected row %v", row)
+		return nil
+	})
 	if err == nil {
 		t.Errorf("Expected error, got nil")
 	}
-
-	nilData := <-out
-	if nilData != nil {
-		t.Errorf("Expected nil value, got %s", nilData)
-	}
 }
 
 // Test failed read pipe rows receiving (scan error)
@@ -111,12 +95,8 @@ func TestFailedReadPipeScanError(t *testing.T) {
 	}
 	defer db.Close()
 
-	errCh := make(chan error, 1)
 	or := repositories.NewWalletOperationRepo(db)
-	wg := &sync.WaitGroup{}
 	ctx := context.Background()
-	in := make(chan interface{}, 1)
-	out := make(chan interface{}, 1)
 
 	op := entities.WalletOperation{
 		ID:         1,
@@ -132,60 +112,71 @@ func TestFailedReadPipeScanError(t *testing.T) {
 
 	readPipe := ReadPipe{
 		or:     or,
-		wg:     wg,
 		params: nil,
-		ctx:    ctx,
-		errors: errCh,
 	}
 
-	wg.Add(1)
-	go readPipe.Call(in, out)
-	wg.Wait()
-
-	err = <-errCh
+	err = readPipe.Call(ctx, func(row ReportRow) error {
+		t.Errorf("Unexpected row %v", row)
+		return nil
+	})
 	if err == nil {
 		t.Errorf("Expected error, got nil")
 	}
+}
 
-	nilData := <-out
-	if nilData != nil {
-		t.Errorf("Expected nil value, got %s", nilData)
+// Test read pipe rows receiving (query returns emtpy result)
+func TestReadPipeEmptyQuery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	or := repositories.NewWalletOperationRepo(db)
+	ctx := context.Background()
+
+	rows := sqlmock.NewRows([]string{"id", "operation", "wallet_from", "wallet_to", "amount", "created_at"})
+	mock.ExpectQuery("select").WillReturnRows(rows)
+
+	readPipe := ReadPipe{
+		or:     or,
+		params: nil,
+	}
+
+	err = readPipe.Call(ctx, func(row ReportRow) error {
+		t.Errorf("Unexpected row %v", row)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
 	}
 }
 
-// Test failed read pipe rows receiving (query returns emtpy result)
-func TestFailedReadPipeEmptyQuery(t *testing.T) {
+// Test failed read pipe rows receiving (emit error)
+func TestFailedReadPipeEmitError(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 	defer db.Close()
 
-	errCh := make(chan error, 1)
 	or := repositories.NewWalletOperationRepo(db)
-	wg := &sync.WaitGroup{}
 	ctx := context.Background()
-	in := make(chan interface{}, 1)
-	out := make(chan interface{}, 1)
 
 	rows := sqlmock.NewRows([]string{"id", "operation", "wallet_from", "wallet_to", "amount", "created_at"})
+	rows = rows.AddRow(1, "deposit", 1, 2, decimal.NewFromInt(100), time.Now())
 	mock.ExpectQuery("select").WillReturnRows(rows)
 
 	readPipe := ReadPipe{
 		or:     or,
-		wg:     wg,
 		params: nil,
-		ctx:    ctx,
-		errors: errCh,
 	}
 
-	wg.Add(1)
-	go readPipe.Call(in, out)
-	wg.Wait()
-
-	nilRes := <-out
-	if nilRes != nil {
-		t.Errorf("Expected nil result, got %s", nilRes)
+	err = readPipe.Call(ctx, func(row ReportRow) error {
+		return context.Canceled
+	})
+	if err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
 	}
 }
 
@@ -193,10 +184,6 @@ func TestFailedReadPipeEmptyQuery(t *testing.T) {
 func TestSuccessMarshallPipe(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockFileMarshaller := NewMockFileMarshallingManager(ctrl)
-	errCh := make(chan error, 1)
-	wg := &sync.WaitGroup{}
-	in := make(chan interface{}, 1)
-	out := make(chan interface{}, 1)
 	op := entities.WalletOperation{
 		ID:         1,
 		Operation:  "deposit",
@@ -207,26 +194,20 @@ func TestSuccessMarshallPipe(t *testing.T) {
 	}
 
 	marshallPipe := MarshallPipe{
-		wg:     wg,
-		errors: errCh,
-		fm:     mockFileMarshaller,
+		fm: mockFileMarshaller,
 	}
 
-	mockFileMarshaller.EXPECT().MarshallOperation(&op).Return(&MarshalledResult{
+	mockFileMarshaller.EXPECT().MarshallRow(OperationRow{&op}).Return(&MarshalledResult{
 		id:   op.ID,
 		data: op,
 	}, nil)
 
-	in <- &op
-	wg.Add(1)
-	go marshallPipe.Call(in, out)
-	close(in)
-	wg.Wait()
-
-	res := <-out
-	chanOp := res.(*MarshalledResult)
-	if chanOp.id != op.ID {
-		t.Errorf("Received ID do not match. Expected %d, got %d", op.ID, chanOp.id)
+	res, err := marshallPipe.Call(context.Background(), OperationRow{&op})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if res.id != op.ID {
+		t.Errorf("Received ID do not match. Expected %d, got %d", op.ID, res.id)
 	}
 }
 
@@ -234,10 +215,6 @@ func TestSuccessMarshallPipe(t *testing.T) {
 func TestFailedMarshallPipeErrorMarshalling(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockFileMarshaller := NewMockFileMarshallingManager(ctrl)
-	errCh := make(chan error, 1)
-	wg := &sync.WaitGroup{}
-	in := make(chan interface{}, 1)
-	out := make(chan interface{}, 1)
 	op := entities.WalletOperation{
 		ID:         1,
 		Operation:  "deposit",
@@ -248,54 +225,18 @@ func TestFailedMarshallPipeErrorMarshalling(t *testing.T) {
 	}
 
 	marshallPipe := MarshallPipe{
-		wg:     wg,
-		errors: errCh,
-		fm:     mockFileMarshaller,
+		fm: mockFileMarshaller,
 	}
 
-	mockFileMarshaller.EXPECT().MarshallOperation(&op).Return(nil, fmt.Errorf("marshall error"))
+	mockFileMarshaller.EXPECT().MarshallRow(OperationRow{&op}).Return(nil, fmt.Errorf("marshall error"))
 
-	in <- &op
-	wg.Add(1)
-	go marshallPipe.Call(in, out)
-	wg.Wait()
-
-	err := <-errCh
-	res := <-out
+	res, err := marshallPipe.Call(context.Background(), OperationRow{&op})
 	if err == nil {
 		t.Errorf("Expected error, got nil")
 	}
 
 	if res != nil {
-		t.Errorf("Expected nil result, got %s", res)
-	}
-}
-
-// Test failed marshall pipe json marshalling (previous step cancelled)
-func TestFailedMarshallPipePrevPipeCancelled(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	mockFileMarshaller := NewMockFileMarshallingManager(ctrl)
-	errCh := make(chan error)
-	wg := &sync.WaitGroup{}
-	in := make(chan interface{}, 1)
-	out := make(chan interface{}, 1)
-
-	marshallPipe := MarshallPipe{
-		wg:     wg,
-		errors: errCh,
-		fm:     mockFileMarshaller,
-	}
-
-	in <- nil
-
-	wg.Add(1)
-	go marshallPipe.Call(in, out)
-	wg.Wait()
-
-	res := <-out
-
-	if res != nil {
-		t.Errorf("Expected nil result, got %s", res)
+		t.Errorf("Expected nil result, got %v", res)
 	}
 }
 
@@ -303,10 +244,6 @@ func TestFailedMarshallPipePrevPipeCancelled(t *testing.T) {
 func TestSuccessWritePipe(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockFileMarshaller := NewMockFileMarshallingManager(ctrl)
-	errCh := make(chan error)
-	wg := &sync.WaitGroup{}
-	in := make(chan interface{}, 1)
-	out := make(chan interface{}, 1)
 	op := entities.WalletOperation{
 		ID:         1,
 		Operation:  "deposit",
@@ -321,26 +258,13 @@ func TestSuccessWritePipe(t *testing.T) {
 	}
 
 	writePipe := WritePipe{
-		wg:     wg,
-		errors: errCh,
-		fm:     mockFileMarshaller,
+		fm: mockFileMarshaller,
 	}
 
-	in <- &mr
 	mockFileMarshaller.EXPECT().WriteToFile(&mr).Return(nil)
 
-	wg.Add(1)
-	go writePipe.Call(in, out)
-	close(in)
-	wg.Wait()
-
-	select {
-	case err := <-errCh:
+	if err := writePipe.Call(context.Background(), &mr); err != nil {
 		t.Errorf("Unexpected error %s", err)
-	case nilData := <-out:
-		t.Errorf("Unexpected nil data from out %s", nilData)
-	default:
-		fmt.Println("Success file write")
 	}
 }
 
@@ -348,10 +272,6 @@ func TestSuccessWritePipe(t *testing.T) {
 func TestFailedWritePipe(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockFileMarshaller := NewMockFileMarshallingManager(ctrl)
-	errCh := make(chan error, 1)
-	wg := &sync.WaitGroup{}
-	in := make(chan interface{}, 1)
-	out := make(chan interface{}, 1)
 	op := entities.WalletOperation{
 		ID:         1,
 		Operation:  "deposit",
@@ -366,20 +286,12 @@ func TestFailedWritePipe(t *testing.T) {
 	}
 
 	writePipe := WritePipe{
-		wg:     wg,
-		errors: errCh,
-		fm:     mockFileMarshaller,
+		fm: mockFileMarshaller,
 	}
 
-	in <- &mr
 	mockFileMarshaller.EXPECT().WriteToFile(&mr).Return(fmt.Errorf("File error"))
 
-	wg.Add(1)
-	go writePipe.Call(in, out)
-	close(in)
-	wg.Wait()
-
-	err := <-errCh
+	err := writePipe.Call(context.Background(), &mr)
 	if err == nil {
 		t.Errorf("Expected error, got nil")
 	}
@@ -413,14 +325,26 @@ func TestSuccessPipelineRun(t *testing.T) {
 	rows := sqlmock.NewRows([]string{"id", "operation", "wallet_from", "wallet_to", "amount", "created_at"})
 	rows = rows.AddRow(op.ID, op.Operation, op.WalletFrom.Int32, op.WalletTo, op.Amount, op.CreatedAt)
 	mock.ExpectQuery("select").WillReturnRows(rows)
-	mockFileMarshaller.EXPECT().MarshallOperation(&op).Return(&mr, nil)
+	mockFileMarshaller.EXPECT().MarshallRow(OperationRow{&op}).Return(&mr, nil)
 	mockFileMarshaller.EXPECT().WriteToFile(&mr).Return(nil)
 
 	oProcessor := OperationsProcessesManager{}
-	processErr := oProcessor.Process(ctx, or, nil, mockFileMarshaller)
+	stats, processErr := oProcessor.Process(ctx, or, nil, mockFileMarshaller)
 	if processErr != nil {
 		t.Errorf("Unexpected error: %s", processErr)
 	}
+
+	if len(stats) != 3 {
+		t.Fatalf("Expected stats for 3 stages, got %d", len(stats))
+	}
+	for idx, stage := range []string{"read", "marshall", "write"} {
+		if stats[idx].Pipeline != operationsPipelineName || stats[idx].Stage != stage {
+			t.Errorf("Wrong stage labels. Expected %s/%s, got %s/%s", operationsPipelineName, stage, stats[idx].Pipeline, stats[idx].Stage)
+		}
+	}
+	if stats[2].ItemsIn != 1 {
+		t.Errorf("Expected 1 written item, got %d", stats[2].ItemsIn)
+	}
 }
 
 // Test failed pipeline running
@@ -447,10 +371,10 @@ func TestFailedPipelineRun(t *testing.T) {
 	rows := sqlmock.NewRows([]string{"id", "operation", "wallet_from", "wallet_to", "amount", "created_at"})
 	rows = rows.AddRow(op.ID, op.Operation, op.WalletFrom.Int32, op.WalletTo, op.Amount, op.CreatedAt)
 	mock.ExpectQuery("select").WillReturnRows(rows)
-	mockFileMarshaller.EXPECT().MarshallOperation(&op).Return(nil, fmt.Errorf("marshall error"))
+	mockFileMarshaller.EXPECT().MarshallRow(OperationRow{&op}).Return(nil, fmt.Errorf("marshall error"))
 
 	oProcessor := OperationsProcessesManager{}
-	processErr := oProcessor.Process(ctx, or, nil, mockFileMarshaller)
+	_, processErr := oProcessor.Process(ctx, or, nil, mockFileMarshaller)
 	if processErr == nil {
 		t.Errorf("Expected error, got nil")
 	}
@@ -488,13 +412,13 @@ func BenchmarkPipeline(b *testing.B) {
 	rows := sqlmock.NewRows([]string{"id", "operation", "wallet_from", "wallet_to", "amount", "created_at"})
 	rows = rows.AddRow(op.ID, op.Operation, op.WalletFrom.Int32, op.WalletTo, op.Amount, op.CreatedAt)
 	mock.ExpectQuery("select").WillReturnRows(rows)
-	mockFileMarshaller.EXPECT().MarshallOperation(&op).Return(&mr, nil)
+	mockFileMarshaller.EXPECT().MarshallRow(OperationRow{&op}).Return(&mr, nil)
 	mockFileMarshaller.EXPECT().WriteToFile(&mr).Return(nil)
 
 	oProcessor := OperationsProcessesManager{}
 
 	for i := 0; i < b.N; i++ {
-		_ = oProcessor.Process(ctx, or, nil, mockFileMarshaller)
+		_, _ = oProcessor.Process(ctx, or, nil, mockFileMarshaller)
 	}
 }
 
@@ -506,12 +430,8 @@ func BenchmarkReadPipe(b *testing.B) {
 	}
 	defer db.Close()
 
-	errCh := make(chan error)
 	or := repositories.NewWalletOperationRepo(db)
-	wg := &sync.WaitGroup{}
 	ctx := context.Background()
-	in := make(chan interface{}, 1)
-	out := make(chan interface{}, 1)
 	op := entities.WalletOperation{
 		ID:         1,
 		Operation:  "deposit",
@@ -527,15 +447,13 @@ func BenchmarkReadPipe(b *testing.B) {
 
 	readPipe := ReadPipe{
 		or:     or,
-		wg:     wg,
 		params: nil,
-		ctx:    ctx,
-		errors: errCh,
 	}
 
 	for i := 0; i < b.N; i++ {
-		wg.Add(1)
-		go readPipe.Call(in, out)
+		_ = readPipe.Call(ctx, func(row ReportRow) error {
+			return nil
+		})
 	}
 }
 
@@ -543,10 +461,7 @@ func BenchmarkReadPipe(b *testing.B) {
 func BenchmarkMarshallPipe(b *testing.B) {
 	ctrl := gomock.NewController(b)
 	mockFileMarshaller := NewMockFileMarshallingManager(ctrl)
-	errCh := make(chan error, 1)
-	wg := &sync.WaitGroup{}
-	in := make(chan interface{}, 1)
-	out := make(chan interface{}, 1)
+	ctx := context.Background()
 	op := entities.WalletOperation{
 		ID:         1,
 		Operation:  "deposit",
@@ -557,20 +472,16 @@ func BenchmarkMarshallPipe(b *testing.B) {
 	}
 
 	marshallPipe := MarshallPipe{
-		wg:     wg,
-		errors: errCh,
-		fm:     mockFileMarshaller,
+		fm: mockFileMarshaller,
 	}
 
-	mockFileMarshaller.EXPECT().MarshallOperation(&op).Return(&MarshalledResult{
+	mockFileMarshaller.EXPECT().MarshallRow(OperationRow{&op}).Return(&MarshalledResult{
 		id:   op.ID,
 		data: op,
 	}, nil).AnyTimes()
 
 	for i := 0; i < b.N; i++ {
-		in <- &op
-		wg.Add(1)
-		go marshallPipe.Call(in, out)
+		_, _ = marshallPipe.Call(ctx, OperationRow{&op})
 	}
 }
 
@@ -578,34 +489,160 @@ func BenchmarkMarshallPipe(b *testing.B) {
 func BenchmarkWritePipe(b *testing.B) {
 	ctrl := gomock.NewController(b)
 	mockFileMarshaller := NewMockFileMarshallingManager(ctrl)
-	errCh := make(chan error, 1)
-	wg := &sync.WaitGroup{}
-	in := make(chan interface{}, 1)
-	out := make(chan interface{}, 1)
-	op := entities.WalletOperation{
-		ID:         1,
-		Operation:  "deposit",
-		WalletFrom: sql.NullInt32{Int32: 1},
-		WalletTo:   2,
-		Amount:     decimal.NewFromInt(100),
-		CreatedAt:  time.Now(),
+	ctx := context.Background()
+	mr := MarshalledResult{
+		id:   1,
+		data: []byte("{}"),
 	}
 
-	marshallPipe := MarshallPipe{
-		wg:     wg,
-		errors: errCh,
-		fm:     mockFileMarshaller,
+	writePipe := WritePipe{
+		fm: mockFileMarshaller,
 	}
 
-	mockFileMarshaller.EXPECT().MarshallOperation(&op).Return(&MarshalledResult{
-		id:   op.ID,
-		data: op,
-	}, nil).AnyTimes()
+	mockFileMarshaller.EXPECT().WriteToFile(&mr).Return(nil).AnyTimes()
 
 	for i := 0; i < b.N; i++ {
-		in <- &op
-		wg.Add(1)
-		go marshallPipe.Call(in, out)
+		_ = writePipe.Call(ctx, &mr)
+	}
+}
+
+// Test summary pipeline running
+func TestProcessSummary(t *testing.T) {
+	params := &SummaryParams{Period: PeriodDay, GroupBy: []string{GroupByOperation}}
+	summary := []*entities.OperationSummary{
+		{Operation: sql.NullString{String: "deposit", Valid: true}, Count: 2},
+		{Operation: sql.NullString{String: "withdrawal", Valid: true}, Count: 1},
+	}
+	tests := []struct {
+		name     string
+		mockData func(mockSummary *MockSummaryManager, mockFileMarshaller *MockFileMarshallingManager)
+		err      string
+		written  int64
+	}{
+		{
+			name: "Success summary processing",
+			mockData: func(mockSummary *MockSummaryManager, mockFileMarshaller *MockFileMarshallingManager) {
+				mockSummary.EXPECT().Summary(gomock.Any(), params).Return(summary, nil)
+				for _, row := range summary {
+					mr := &MarshalledResult{data: row.Operation.String}
+					mockFileMarshaller.EXPECT().MarshallRow(SummaryRow{row}).Return(mr, nil)
+					mockFileMarshaller.EXPECT().WriteToFile(mr).Return(nil)
+				}
+			},
+			written: 2,
+		},
+		{
+			name: "Failed summary processing (aggregation error)",
+			mockData: func(mockSummary *MockSummaryManager, mockFileMarshaller *MockFileMarshallingManager) {
+				mockSummary.EXPECT().Summary(gomock.Any(), params).Return(nil, fmt.Errorf("query error"))
+			},
+			err: "error of summary retrieving: query error",
+		},
+		{
+			name: "Failed summary processing (marshalling error)",
+			mockData: func(mockSummary *MockSummaryManager, mockFileMarshaller *MockFileMarshallingManager) {
+				mockSummary.EXPECT().Summary(gomock.Any(), params).Return(summary[:1], nil)
+				mockFileMarshaller.EXPECT().MarshallRow(SummaryRow{summary[0]}).Return(nil, fmt.Errorf("marshall error"))
+			},
+			err: "marshalling error: marshall error",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockSummary := NewMockSummaryManager(ctrl)
+			mockFileMarshaller := NewMockFileMarshallingManager(ctrl)
+			tc.mockData(mockSummary, mockFileMarshaller)
+
+			stats, processErr := OperationsProcessesManager{}.ProcessSummary(context.Background(), mockSummary, params, mockFileMarshaller)
+			if tc.err != "" {
+				if processErr == nil || !strings.Contains(processErr.Error(), tc.err) {
+					t.Errorf("Expected error '%s', got %v", tc.err, processErr)
+				}
+				return
+			}
+			if processErr != nil {
+				t.Fatalf("Unexpected error: %s", processErr)
+			}
+			if len(stats) != 3 || stats[2].Pipeline != summaryPipelineName || stats[2].ItemsIn != tc.written {
+				t.Errorf("Wrong stats: %+v", stats)
+			}
+		})
+	}
+}
+
+// Test export pipeline running
+func TestProcessExport(t *testing.T) {
+	rows := []ReportRow{
+		UserRow{ID: 1, Email: "user@example.com"},
+		UserRow{ID: 2, Email: "new@example.com"},
+	}
+	tests := []struct {
+		name     string
+		mockData func(mockExport *MockExportManager, mockFileMarshaller *MockFileMarshallingManager)
+		err      string
+		written  int64
+	}{
+		{
+			name: "Success export processing",
+			mockData: func(mockExport *MockExportManager, mockFileMarshaller *MockFileMarshallingManager) {
+				mockExport.EXPECT().Rows(gomock.Any(), ExportUsers, gomock.Any()).DoAndReturn(func(ctx context.Context, export string, emit func(row ReportRow) error) error {
+					for _, row := range rows {
+						if err := emit(row); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+				for _, row := range rows {
+					mr := &MarshalledResult{id: row.RowID()}
+					mockFileMarshaller.EXPECT().MarshallRow(row).Return(mr, nil)
+					mockFileMarshaller.EXPECT().WriteToFile(mr).Return(nil)
+				}
+			},
+			written: 2,
+		},
+		{
+			name: "Failed export processing (reading error)",
+			mockData: func(mockExport *MockExportManager, mockFileMarshaller *MockFileMarshallingManager) {
+				mockExport.EXPECT().Rows(gomock.Any(), ExportUsers, gomock.Any()).Return(fmt.Errorf("query error"))
+			},
+			err: "error of export retrieving: query error",
+		},
+		{
+			name: "Failed export processing (marshalling error)",
+			mockData: func(mockExport *MockExportManager, mockFileMarshaller *MockFileMarshallingManager) {
+				mockExport.EXPECT().Rows(gomock.Any(), ExportUsers, gomock.Any()).DoAndReturn(func(ctx context.Context, export string, emit func(row ReportRow) error) error {
+					return emit(rows[0])
+				})
+				mockFileMarshaller.EXPECT().MarshallRow(rows[0]).Return(nil, fmt.Errorf("marshall error"))
+			},
+			err: "marshalling error: marshall error",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockExport := NewMockExportManager(ctrl)
+			mockFileMarshaller := NewMockFileMarshallingManager(ctrl)
+			tc.mockData(mockExport, mockFileMarshaller)
+
+			stats, processErr := OperationsProcessesManager{}.ProcessExport(context.Background(), mockExport, ExportUsers, mockFileMarshaller)
+			if tc.err != "" {
+				if processErr == nil || !strings.Contains(processErr.Error(), tc.err) {
+					t.Errorf("Expected error '%s', got %v", tc.err, processErr)
+				}
+				return
+			}
+			if processErr != nil {
+				t.Fatalf("Unexpected error: %s", processErr)
+			}
+			if len(stats) != 3 || stats[2].Pipeline != "users_report" || stats[2].ItemsIn != tc.written {
+				t.Errorf("Wrong stats: %+v", stats)
+			}
+		})
 	}
 }
 
@@ -0,0 +1,61 @@
+package reports
+
+import (
+	"billing_system_test_task/pkg/reportcodec"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ProtobufHandler implements FileMarshallingManager interface for length-delimited stream of
+// billing.reports.v1.WalletOperation messages; it is read with reportcodec.OperationReader
+type ProtobufHandler struct {
+	file io.Writer
+	mu   *sync.Mutex
+}
+
+// NewProtobufHandler returns handler which writes operations as protobuf messages
+func NewProtobufHandler(file io.Writer, mu *sync.Mutex) *ProtobufHandler {
+	return &ProtobufHandler{
+		file: file,
+		mu:   mu,
+	}
+}
+
+// MarshallRow marshal wallet operation to size-prefixed protobuf message
+func (ph *ProtobufHandler) MarshallRow(row ReportRow) (*MarshalledResult, error) {
+	operation, rowErr := rowOperation(row, "protobuf")
+	if rowErr != nil {
+		return nil, rowErr
+	}
+	message := &reportcodec.WalletOperation{
+		ID:        int64(operation.ID),
+		Operation: operation.Operation,
+		WalletTo:  int64(operation.WalletTo),
+		Amount:    operation.Amount.String(),
+		CreatedAt: operation.CreatedAt,
+	}
+	if operation.WalletFrom.Valid {
+		walletFrom := int64(operation.WalletFrom.Int32)
+		message.WalletFrom = &walletFrom
+	}
+	return &MarshalledResult{
+		id:   operation.ID,
+		data: message.AppendDelimited(nil),
+	}, nil
+}
+
+// WriteToFile writes protobuf message
+func (ph *ProtobufHandler) WriteToFile(mr *MarshalledResult) error {
+	ph.mu.Lock()
+	defer ph.mu.Unlock()
+	if _, writeErr := ph.file.Write(mr.data.([]byte)); writeErr != nil {
+		return fmt.Errorf("error of protobuf writing: %s", writeErr)
+	}
+	return nil
+}
+
+// Close does nothing: protobuf stream has no closing records
+func (ph *ProtobufHandler) Close() error {
+	return nil
+}
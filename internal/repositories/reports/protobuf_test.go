@@ -0,0 +1,56 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/pkg/reportcodec"
+	"bytes"
+	"io"
+	"testing"
+)
+
+// Test protobuf report is read back with public decoder
+func TestProtobufHandlerRoundTrip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler, handlerErr := NewFileHandler(NewMemoryStorage(), nil, nil).CreateMarshaller(buf, "protobuf", nil, nil)
+	if handlerErr != nil {
+		t.Fatalf("unexpected error: %s", handlerErr)
+	}
+	for _, operation := range camtOperations {
+		mr, _ := handler.MarshallRow(OperationRow{operation})
+		if writeErr := handler.WriteToFile(mr); writeErr != nil {
+			t.Fatalf("unexpected error: %s", writeErr)
+		}
+	}
+	if closeErr := handler.Close(); closeErr != nil {
+		t.Fatalf("unexpected error: %s", closeErr)
+	}
+
+	reader := reportcodec.NewOperationReader(buf)
+	for _, expected := range camtOperations {
+		operation, readErr := reader.Next()
+		if readErr != nil {
+			t.Fatalf("unexpected error: %s", readErr)
+		}
+		if operation.ID != int64(expected.ID) || operation.Operation != expected.Operation || operation.WalletTo != int64(expected.WalletTo) ||
+			operation.Amount != expected.Amount.String() || !operation.CreatedAt.Equal(expected.CreatedAt) {
+			t.Errorf("Wrong operation %d: %+v", expected.ID, operation)
+		}
+		if (operation.WalletFrom != nil) != expected.WalletFrom.Valid || (operation.WalletFrom != nil && *operation.WalletFrom != int64(expected.WalletFrom.Int32)) {
+			t.Errorf("Wrong source wallet of operation %d: %v", expected.ID, operation.WalletFrom)
+		}
+	}
+	if _, readErr := reader.Next(); readErr != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", readErr)
+	}
+}
+
+// Test protobuf rejects rows of summary and exports
+func TestProtobufHandlerMarshallRowKinds(t *testing.T) {
+	handler := NewProtobufHandler(&bytes.Buffer{}, nil)
+	if _, marshallErr := handler.MarshallRow(SummaryRow{&entities.OperationSummary{}}); marshallErr == nil || marshallErr.Error() != "summary rows are not supported by protobuf format" {
+		t.Errorf("Expected error of summary, got %v", marshallErr)
+	}
+	if _, marshallErr := handler.MarshallRow(UserRow{}); marshallErr == nil || marshallErr.Error() != "users rows are not supported by protobuf format" {
+		t.Errorf("Expected error of users export, got %v", marshallErr)
+	}
+}
@@ -0,0 +1,96 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/entities"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const qifDateLayout = "01/02/2006"
+
+// QIFHandler implements FileMarshallingManager interface for Quicken Interchange Format.
+// Account block with balance at the end of the period is written before transactions.
+type QIFHandler struct {
+	file      io.Writer
+	mu        *sync.Mutex
+	statement *entities.AccountStatement
+}
+
+// NewQIFHandler writes account's block with statement balance
+func NewQIFHandler(w io.Writer, mu *sync.Mutex, statement *entities.AccountStatement, createdAt time.Time) (*QIFHandler, error) {
+	balanceDate := createdAt.UTC()
+	if !statement.To.IsZero() {
+		balanceDate = statement.To
+	}
+	header := strings.Join([]string{
+		"!Account",
+		fmt.Sprintf("NWallet %d", statement.WalletID),
+		"TBank",
+		fmt.Sprintf("D%s wallet", statement.Currency),
+		"/" + balanceDate.Format(qifDateLayout),
+		"$" + statement.ClosingBalance.StringFixed(2),
+		"^",
+		"!Type:Bank",
+		"",
+	}, "\n")
+	if _, writeErr := io.WriteString(w, header); writeErr != nil {
+		return nil, fmt.Errorf("error of qif header writing: %s", writeErr)
+	}
+	return &QIFHandler{
+		file:      w,
+		mu:        mu,
+		statement: statement,
+	}, nil
+}
+
+// MarshallRow converts wallet's deposit or withdrawal to qif transaction; other operations are skipped
+func (qh *QIFHandler) MarshallRow(row ReportRow) (*MarshalledResult, error) {
+	operation, rowErr := rowOperation(row, "qif")
+	if rowErr != nil {
+		return nil, rowErr
+	}
+	sign := statementEntrySign(qh.statement, operation)
+	if sign == 0 {
+		return &MarshalledResult{id: operation.ID}, nil
+	}
+	amount := operation.Amount
+	if sign < 0 {
+		amount = amount.Neg()
+	}
+	lines := []string{
+		"D" + operation.CreatedAt.Format(qifDateLayout),
+		"T" + amount.StringFixed(2),
+		"N" + operationReference(operation),
+		"P" + operation.Operation,
+	}
+	if info := transferInfo(operation, sign); info != "" {
+		lines = append(lines, "M"+info)
+	}
+	lines = append(lines, "^", "")
+	return &MarshalledResult{
+		id:   operation.ID,
+		data: []byte(strings.Join(lines, "\n")),
+	}, nil
+}
+
+// WriteToFile writes qif transaction
+func (qh *QIFHandler) WriteToFile(mr *MarshalledResult) error {
+	record, isRecord := mr.data.([]byte)
+	if !isRecord {
+		return nil
+	}
+	qh.mu.Lock()
+	defer qh.mu.Unlock()
+	if _, writeErr := qh.file.Write(record); writeErr != nil {
+		return fmt.Errorf("error of qif writing: %s", writeErr)
+	}
+	return nil
+}
+
+// Close does nothing: qif has no closing records
+func (qh *QIFHandler) Close() error {
+	return nil
+}
@@ -0,0 +1,42 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/entities"
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Test qif account block and transactions
+func TestQIFHandlerStatement(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler, handlerErr := NewQIFHandler(buf, &sync.Mutex{}, camtStatement, time.Now())
+	if handlerErr != nil {
+		t.Fatalf("unexpected error: %s", handlerErr)
+	}
+	for _, operation := range camtOperations {
+		mr, _ := handler.MarshallRow(OperationRow{operation})
+		if writeErr := handler.WriteToFile(mr); writeErr != nil {
+			t.Fatalf("unexpected error: %s", writeErr)
+		}
+	}
+	if closeErr := handler.Close(); closeErr != nil {
+		t.Fatalf("unexpected error: %s", closeErr)
+	}
+
+	expected := "!Account\nNWallet 1\nTBank\nDUSD wallet\n/03/31/2021\n$15.50\n^\n!Type:Bank\n" +
+		"D03/02/2021\nT30.50\nNOP-2\nPdeposit\n^\n" +
+		"D03/03/2021\nT-10.00\nNOP-3\nPwithdrawal\nMTransfer to wallet 2\n^\n"
+	if buf.String() != expected {
+		t.Errorf("Wrong qif statement.\nExpected:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
+
+// Test qif rejects summary rows
+func TestQIFHandlerMarshallRowKinds(t *testing.T) {
+	handler, _ := NewQIFHandler(&bytes.Buffer{}, &sync.Mutex{}, camtStatement, time.Now())
+	if _, marshallErr := handler.MarshallRow(SummaryRow{&entities.OperationSummary{}}); marshallErr == nil {
+		t.Error("Expected error, got nil")
+	}
+}
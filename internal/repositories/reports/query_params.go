@@ -5,19 +5,46 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 // QueryReaderManager represents actions for query parameters reading
 type QueryReaderManager interface {
 	Parse(query url.Values) (*QueryParams, error)
+	ParseSummary(query url.Values) (*QueryParams, error)
+	ParseExport(export string, query url.Values) (*QueryParams, error)
+	ParseFeed(query url.Values) (*QueryParams, error)
 }
 
 // QueryParams represents parameters for
 type QueryParams struct {
 	Format     string
 	ListParams *repositories.ListParams
+	Summary    *SummaryParams
+	Export     string
+	Limit      int
+	Options    *FormatOptions
+	Filters    map[string]string
+	Manifest   bool
+	Persist    bool
+	Archive    *ArchiveOptions
 }
 
+// Filters of operations and summary reports written to report's manifest
+var (
+	operationFilters = []string{"page", "per_page", "date", "wallet", "from", "to", "template"}
+	summaryFilters   = []string{"period", "group_by", "from", "to"}
+	feedFilters      = []string{"limit"}
+)
+
+// Number of operations in a batch of the feed
+const (
+	DefaultFeedLimit = 1000
+	MaxFeedLimit     = 10000
+)
+
 // QueryParams implements QueryReaderManager interface
 type QueryParamsReader struct{}
 
@@ -28,8 +55,9 @@ func NewQueryParamsReader() *QueryParamsReader {
 // Parse returns given URL query parameters
 func (qpr QueryParamsReader) Parse(query url.Values) (*QueryParams, error) {
 	var (
-		format string
-		params = &repositories.ListParams{}
+		format  string
+		params  = &repositories.ListParams{}
+		options = DefaultFormatOptions()
 	)
 	format = query.Get("format")
 	pageStr := query.Get("page")
@@ -40,6 +68,10 @@ func (qpr QueryParamsReader) Parse(query url.Values) (*QueryParams, error) {
 		format = "json"
 	}
 
+	if optionsErr := parseFormatOptions(query, options, operationColumns); optionsErr != nil {
+		return nil, optionsErr
+	}
+
 	if pageStr != "" && perPageStr != "" {
 		page, pageConvError := strconv.Atoi(pageStr)
 		if pageConvError != nil {
@@ -58,8 +90,384 @@ func (qpr QueryParamsReader) Parse(query url.Values) (*QueryParams, error) {
 		params.Date = date
 	}
 
+	if walletStr := query.Get("wallet"); walletStr != "" {
+		walletID, walletConvErr := strconv.Atoi(walletStr)
+		if walletConvErr != nil || walletID <= 0 {
+			return nil, fmt.Errorf("invalid 'wallet' attribute: %s", walletStr)
+		}
+		params.WalletID = walletID
+	}
+	if IsStatementFormat(format) && params.WalletID == 0 {
+		return nil, fmt.Errorf("'wallet' attribute is required for %s format", format)
+	}
+	if format == TemplateFormat {
+		options.TemplateName = query.Get("template")
+		if options.TemplateName == "" {
+			return nil, fmt.Errorf("'template' attribute is required for template format")
+		}
+	}
+
+	from, to, periodErr := parseDatePeriod(query)
+	if periodErr != nil {
+		return nil, periodErr
+	}
+	params.From, params.To = from, to
+
+	manifest, manifestErr := parseFlag(query, "manifest")
+	if manifestErr != nil {
+		return nil, manifestErr
+	}
+	persist, persistErr := parseFlag(query, "persist")
+	if persistErr != nil {
+		return nil, persistErr
+	}
+	archive, archiveErr := parseArchiveOptions(query)
+	if archiveErr != nil {
+		return nil, archiveErr
+	}
+
 	return &QueryParams{
 		Format:     format,
 		ListParams: params,
+		Options:    options,
+		Filters:    usedFilters(query, operationFilters),
+		Manifest:   manifest,
+		Persist:    persist,
+		Archive:    archive,
+	}, nil
+}
+
+// ParseSummary returns URL query parameters of summary report
+func (qpr QueryParamsReader) ParseSummary(query url.Values) (*QueryParams, error) {
+	var (
+		format  = query.Get("format")
+		params  = &SummaryParams{Period: PeriodDay, GroupBy: []string{GroupByOperation}}
+		options = DefaultFormatOptions()
+	)
+	if format == "" {
+		format = "json"
+	}
+
+	if !SupportsRows(format, SummaryRows) {
+		return nil, fmt.Errorf("unsupported format of summary report: %s", format)
+	}
+
+	switch period := query.Get("period"); period {
+	case "":
+	case PeriodDay, PeriodWeek, PeriodMonth:
+		params.Period = period
+	default:
+		return nil, fmt.Errorf("unsupported 'period' value: %s", period)
+	}
+
+	if groupByStr := query.Get("group_by"); groupByStr != "" {
+		groupBy := strings.Split(groupByStr, ",")
+		for idx, dimension := range groupBy {
+			if dimension != GroupByOperation && dimension != GroupByWallet && dimension != GroupByCurrency {
+				return nil, fmt.Errorf("unsupported dimension in 'group_by' attribute: %s", dimension)
+			}
+			if hasString(groupBy[:idx], dimension) {
+				return nil, fmt.Errorf("duplicated dimension in 'group_by' attribute: %s", dimension)
+			}
+		}
+		params.GroupBy = groupBy
+	}
+
+	from, to, periodErr := parseDatePeriod(query)
+	if periodErr != nil {
+		return nil, periodErr
+	}
+	params.From, params.To = from, to
+
+	options.Columns = summaryReportColumns(params.GroupBy)
+	if optionsErr := parseFormatOptions(query, options, summaryColumns); optionsErr != nil {
+		return nil, optionsErr
+	}
+
+	manifest, manifestErr := parseFlag(query, "manifest")
+	if manifestErr != nil {
+		return nil, manifestErr
+	}
+	persist, persistErr := parseFlag(query, "persist")
+	if persistErr != nil {
+		return nil, persistErr
+	}
+	archive, archiveErr := parseArchiveOptions(query)
+	if archiveErr != nil {
+		return nil, archiveErr
+	}
+
+	return &QueryParams{
+		Format:   format,
+		Summary:  params,
+		Options:  options,
+		Filters:  usedFilters(query, summaryFilters),
+		Manifest: manifest,
+		Persist:  persist,
+		Archive:  archive,
+	}, nil
+}
+
+// ParseExport returns URL query parameters of users or balances export
+func (qpr QueryParamsReader) ParseExport(export string, query url.Values) (*QueryParams, error) {
+	columns, exists := ExportColumns[export]
+	if !exists {
+		return nil, fmt.Errorf("unknown export: %s", export)
+	}
+	var (
+		format  = query.Get("format")
+		options = DefaultFormatOptions()
+	)
+	if format == "" {
+		format = "json"
+	}
+	if !SupportsRows(format, export) {
+		return nil, fmt.Errorf("unsupported format of %s export: %s", export, format)
+	}
+
+	options.Columns = append([]string{}, columns...)
+	if optionsErr := parseFormatOptions(query, options, columns); optionsErr != nil {
+		return nil, optionsErr
+	}
+
+	manifest, manifestErr := parseFlag(query, "manifest")
+	if manifestErr != nil {
+		return nil, manifestErr
+	}
+	persist, persistErr := parseFlag(query, "persist")
+	if persistErr != nil {
+		return nil, persistErr
+	}
+	archive, archiveErr := parseArchiveOptions(query)
+	if archiveErr != nil {
+		return nil, archiveErr
+	}
+
+	return &QueryParams{
+		Format:   format,
+		Export:   export,
+		Options:  options,
+		Filters:  map[string]string{},
+		Manifest: manifest,
+		Persist:  persist,
+		Archive:  archive,
+	}, nil
+}
+
+// ParseFeed returns URL query parameters of operations feed batch; range of operations is defined by consumer
+func (qpr QueryParamsReader) ParseFeed(query url.Values) (*QueryParams, error) {
+	var (
+		format  = query.Get("format")
+		limit   = DefaultFeedLimit
+		options = DefaultFormatOptions()
+	)
+	if format == "" {
+		format = "json"
+	}
+	if IsStatementFormat(format) || format == TemplateFormat {
+		return nil, fmt.Errorf("unsupported format of operations feed: %s", format)
+	}
+
+	if optionsErr := parseFormatOptions(query, options, operationColumns); optionsErr != nil {
+		return nil, optionsErr
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limitValue, limitConvErr := strconv.Atoi(limitStr)
+		if limitConvErr != nil || limitValue <= 0 || limitValue > MaxFeedLimit {
+			return nil, fmt.Errorf("invalid 'limit' attribute (1-%d): %s", MaxFeedLimit, limitStr)
+		}
+		limit = limitValue
+	}
+
+	manifest, manifestErr := parseFlag(query, "manifest")
+	if manifestErr != nil {
+		return nil, manifestErr
+	}
+	persist, persistErr := parseFlag(query, "persist")
+	if persistErr != nil {
+		return nil, persistErr
+	}
+	archive, archiveErr := parseArchiveOptions(query)
+	if archiveErr != nil {
+		return nil, archiveErr
+	}
+
+	return &QueryParams{
+		Format:     format,
+		ListParams: &repositories.ListParams{},
+		Limit:      limit,
+		Options:    options,
+		Filters:    usedFilters(query, feedFilters),
+		Manifest:   manifest,
+		Persist:    persist,
+		Archive:    archive,
 	}, nil
 }
+
+// parseFlag reads boolean attribute; it is false when attribute is empty
+func parseFlag(query url.Values, name string) (bool, error) {
+	flagStr := query.Get(name)
+	if flagStr == "" {
+		return false, nil
+	}
+	flag, flagErr := strconv.ParseBool(flagStr)
+	if flagErr != nil {
+		return false, fmt.Errorf("error of '%s' attribute converting: %s", name, flagErr)
+	}
+	return flag, nil
+}
+
+// parseArchiveOptions reads compression and encryption of report; passphrase is passed by transport from request's header
+func parseArchiveOptions(query url.Values) (*ArchiveOptions, error) {
+	archive := &ArchiveOptions{
+		Compression: query.Get("compress"),
+		Encryption:  query.Get("encrypt"),
+		Passphrase:  query.Get("passphrase"),
+	}
+	if archive.Compression == "" && archive.Encryption == "" {
+		return nil, nil
+	}
+	if _, supported := archiveExtensions[archive.Compression]; archive.Compression != "" && !supported {
+		return nil, fmt.Errorf("unsupported 'compress' value: %s", archive.Compression)
+	}
+	switch archive.Encryption {
+	case "", EncryptionKey:
+	case EncryptionPassphrase:
+		if archive.Passphrase == "" {
+			return nil, fmt.Errorf("passphrase is required for passphrase encryption")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported 'encrypt' value: %s", archive.Encryption)
+	}
+	return archive, nil
+}
+
+// usedFilters returns non-empty values of given filters
+func usedFilters(query url.Values, names []string) map[string]string {
+	filters := map[string]string{}
+	for _, name := range names {
+		if value := query.Get(name); value != "" {
+			filters[name] = value
+		}
+	}
+	return filters
+}
+
+// parseDatePeriod validates optional 'from' and 'to' dates of report
+func parseDatePeriod(query url.Values) (string, string, error) {
+	dates := map[string]string{}
+	for _, attribute := range []string{"from", "to"} {
+		date := query.Get(attribute)
+		if date == "" {
+			continue
+		}
+		if _, dateErr := time.Parse("2006-01-02", date); dateErr != nil {
+			return "", "", fmt.Errorf("error of '%s' attribute parsing: %s", attribute, dateErr)
+		}
+		dates[attribute] = date
+	}
+	if dates["from"] != "" && dates["to"] != "" && dates["from"] > dates["to"] {
+		return "", "", fmt.Errorf("'from' date should not be after 'to' date")
+	}
+	return dates["from"], dates["to"], nil
+}
+
+// parseFormatOptions validates output format parameters and sets them to options
+func parseFormatOptions(query url.Values, options *FormatOptions, columns []string) error {
+	switch amountFormat := query.Get("amount_format"); amountFormat {
+	case "":
+	case AmountAsString, AmountAsNumber:
+		options.AmountFormat = amountFormat
+	default:
+		return fmt.Errorf("unsupported 'amount_format' value: %s", amountFormat)
+	}
+	return parseCSVOptions(query, options, columns)
+}
+
+// parseCSVOptions validates csv dialect parameters and sets them to options
+func parseCSVOptions(query url.Values, options *FormatOptions, availableColumns []string) error {
+	if columnsStr := query.Get("columns"); columnsStr != "" {
+		columns := strings.Split(columnsStr, ",")
+		seen := make(map[string]bool, len(columns))
+		for _, column := range columns {
+			if !hasString(availableColumns, column) {
+				return fmt.Errorf("unknown column in 'columns' attribute: %s", column)
+			}
+			if seen[column] {
+				return fmt.Errorf("duplicated column in 'columns' attribute: %s", column)
+			}
+			seen[column] = true
+		}
+		options.Columns = columns
+	}
+
+	if delimiterStr := query.Get("delimiter"); delimiterStr != "" {
+		if delimiterStr == "tab" {
+			delimiterStr = "\t"
+		}
+		delimiter, size := utf8.DecodeRuneInString(delimiterStr)
+		if size != len(delimiterStr) || !validDelimiter(delimiter) {
+			return fmt.Errorf("invalid 'delimiter' attribute: %s", delimiterStr)
+		}
+		options.Delimiter = delimiter
+	}
+
+	if headerStr := query.Get("header"); headerStr != "" {
+		header, headerErr := strconv.ParseBool(headerStr)
+		if headerErr != nil {
+			return fmt.Errorf("error of 'header' attribute converting: %s", headerErr)
+		}
+		options.Header = header
+	}
+
+	if layoutStr := query.Get("time_layout"); layoutStr != "" {
+		layout, named := timeLayouts[layoutStr]
+		if !named {
+			layout = layoutStr
+			if !validTimeLayout(layout) {
+				return fmt.Errorf("invalid 'time_layout' attribute: %s", layoutStr)
+			}
+		}
+		options.TimeLayout = layout
+	}
+
+	if zoneStr := query.Get("time_zone"); zoneStr != "" {
+		location, locationErr := time.LoadLocation(zoneStr)
+		if locationErr != nil {
+			return fmt.Errorf("error of 'time_zone' attribute loading: %s", locationErr)
+		}
+		options.Location = location
+	}
+
+	if separator := query.Get("decimal_separator"); separator != "" {
+		if separator != "." && separator != "," {
+			return fmt.Errorf("unsupported 'decimal_separator' value: %s", separator)
+		}
+		options.DecimalSeparator = separator
+	}
+	if options.DecimalSeparator == string(options.Delimiter) {
+		return fmt.Errorf("'decimal_separator' must differ from 'delimiter'")
+	}
+
+	if nullValues, ok := query["null_value"]; ok && len(nullValues) > 0 {
+		options.NullValue = nullValues[0]
+	}
+	return nil
+}
+
+// validDelimiter checks restrictions of encoding/csv writer
+func validDelimiter(r rune) bool {
+	return r != 0 && r != '"' && r != '\r' && r != '\n' && utf8.ValidRune(r) && r != utf8.RuneError
+}
+
+// validTimeLayout checks that layout has time elements and can be parsed back
+func validTimeLayout(layout string) bool {
+	reference := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+	formatted := reference.Format(layout)
+	if formatted == layout {
+		return false
+	}
+	_, parseErr := time.Parse(layout, formatted)
+	return parseErr == nil
+}
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: ./internal/operations/query_params.go
+// Source: ./internal/repositories/reports/query_params.go
 
 // Package reports is a generated GoMock package.
 package reports
@@ -47,3 +47,48 @@ func (mr *MockQueryReaderManagerMockRecorder) Parse(query interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Parse", reflect.TypeOf((*MockQueryReaderManager)(nil).Parse), query)
 }
+
+// ParseSummary mocks base method
+func (m *MockQueryReaderManager) ParseSummary(query url.Values) (*QueryParams, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseSummary", query)
+	ret0, _ := ret[0].(*QueryParams)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseSummary indicates an expected call of ParseSummary
+func (mr *MockQueryReaderManagerMockRecorder) ParseSummary(query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseSummary", reflect.TypeOf((*MockQueryReaderManager)(nil).ParseSummary), query)
+}
+
+// ParseExport mocks base method
+func (m *MockQueryReaderManager) ParseExport(export string, query url.Values) (*QueryParams, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseExport", export, query)
+	ret0, _ := ret[0].(*QueryParams)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseExport indicates an expected call of ParseExport
+func (mr *MockQueryReaderManagerMockRecorder) ParseExport(export, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseExport", reflect.TypeOf((*MockQueryReaderManager)(nil).ParseExport), export, query)
+}
+
+// ParseFeed mocks base method
+func (m *MockQueryReaderManager) ParseFeed(query url.Values) (*QueryParams, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseFeed", query)
+	ret0, _ := ret[0].(*QueryParams)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseFeed indicates an expected call of ParseFeed
+func (mr *MockQueryReaderManagerMockRecorder) ParseFeed(query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseFeed", reflect.TypeOf((*MockQueryReaderManager)(nil).ParseFeed), query)
+}
@@ -90,6 +90,443 @@ func TestFailedQueryParamsParserWrongPerPageFormat(t *testing.T) {
 	}
 }
 
+// Test parsing of amount_format parameter
+func TestQueryParamsParserAmountFormat(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+		err      bool
+	}{
+		{"", AmountAsString, false},
+		{"string", AmountAsString, false},
+		{"number", AmountAsNumber, false},
+		{"float", "", true},
+	}
+	qpr := QueryParamsReader{}
+	for _, tc := range tests {
+		params := make(url.Values)
+		params.Set("amount_format", tc.value)
+		queryParams, err := qpr.Parse(params)
+		if tc.err {
+			if err == nil || !strings.Contains(err.Error(), "unsupported 'amount_format' value") {
+				t.Errorf("[%s] Expected amount_format error, got %v", tc.value, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%s] Unexpected error: %s", tc.value, err)
+			continue
+		}
+		if queryParams.Options.AmountFormat != tc.expected {
+			t.Errorf("[%s] Amount format mismatch. Expected %s, got %s", tc.value, tc.expected, queryParams.Options.AmountFormat)
+		}
+	}
+}
+
+// Test parsing of csv dialect parameters
+func TestQueryParamsParserCSVOptions(t *testing.T) {
+	params := make(url.Values)
+	params.Set("format", "csv")
+	params.Set("columns", "created_at,id,amount")
+	params.Set("delimiter", ";")
+	params.Set("header", "false")
+	params.Set("time_layout", "datetime")
+	params.Set("time_zone", "Europe/Berlin")
+	params.Set("decimal_separator", ",")
+	params.Set("null_value", "NULL")
+	qpr := QueryParamsReader{}
+	queryParams, err := qpr.Parse(params)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	options := queryParams.Options
+	if strings.Join(options.Columns, ",") != "created_at,id,amount" {
+		t.Errorf("Columns mismatch: %v", options.Columns)
+	}
+	if options.Delimiter != ';' || options.Header {
+		t.Errorf("Delimiter or header mismatch")
+	}
+	if options.TimeLayout != "2006-01-02 15:04:05" || options.Location.String() != "Europe/Berlin" {
+		t.Errorf("Time options mismatch: %s, %s", options.TimeLayout, options.Location)
+	}
+	if options.DecimalSeparator != "," || options.NullValue != "NULL" {
+		t.Errorf("Decimal separator or null value mismatch")
+	}
+}
+
+// Test validation of csv dialect parameters
+func TestFailedQueryParamsParserCSVOptions(t *testing.T) {
+	tests := []struct {
+		param string
+		value string
+		err   string
+	}{
+		{"columns", "id,balance", "unknown column in 'columns' attribute: balance"},
+		{"columns", "id,id", "duplicated column in 'columns' attribute: id"},
+		{"delimiter", ";;", "invalid 'delimiter' attribute"},
+		{"delimiter", "\"", "invalid 'delimiter' attribute"},
+		{"header", "maybe", "error of 'header' attribute converting"},
+		{"time_layout", "yyyy-mm-dd", "invalid 'time_layout' attribute"},
+		{"time_zone", "Mars/Olympus", "error of 'time_zone' attribute loading"},
+		{"decimal_separator", "_", "unsupported 'decimal_separator' value"},
+		{"decimal_separator", ",", "'decimal_separator' must differ from 'delimiter'"},
+	}
+	qpr := QueryParamsReader{}
+	for _, tc := range tests {
+		params := make(url.Values)
+		params.Set(tc.param, tc.value)
+		_, err := qpr.Parse(params)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("[%s=%s] Expected error '%s', got %v", tc.param, tc.value, tc.err, err)
+		}
+	}
+}
+
+// Test success parsing of summary report parameters
+func TestSuccessQueryParamsParserSummary(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   map[string]string
+		period  string
+		groupBy string
+		columns string
+	}{
+		{
+			name:    "Default parameters",
+			query:   map[string]string{},
+			period:  PeriodDay,
+			groupBy: "operation",
+			columns: "period,operation,count,total",
+		},
+		{
+			name:    "Monthly totals by wallet and currency",
+			query:   map[string]string{"period": "month", "group_by": "wallet,currency", "from": "2021-01-01", "to": "2021-01-31"},
+			period:  PeriodMonth,
+			groupBy: "wallet,currency",
+			columns: "period,wallet,currency,count,total",
+		},
+		{
+			name:    "Selected columns",
+			query:   map[string]string{"group_by": "currency", "columns": "currency,total"},
+			period:  PeriodDay,
+			groupBy: "currency",
+			columns: "currency,total",
+		},
+	}
+	qpr := QueryParamsReader{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			params := make(url.Values)
+			for k, v := range tc.query {
+				params.Set(k, v)
+			}
+			queryParams, err := qpr.ParseSummary(params)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if queryParams.Format != "json" || queryParams.Summary.Period != tc.period {
+				t.Errorf("Format or period mismatch: %s, %s", queryParams.Format, queryParams.Summary.Period)
+			}
+			if strings.Join(queryParams.Summary.GroupBy, ",") != tc.groupBy {
+				t.Errorf("Group by mismatch. Expected %s, got %v", tc.groupBy, queryParams.Summary.GroupBy)
+			}
+			if strings.Join(queryParams.Options.Columns, ",") != tc.columns {
+				t.Errorf("Columns mismatch. Expected %s, got %v", tc.columns, queryParams.Options.Columns)
+			}
+			if queryParams.Summary.From != tc.query["from"] || queryParams.Summary.To != tc.query["to"] {
+				t.Errorf("Dates mismatch")
+			}
+		})
+	}
+}
+
+// Test parsing of users and balances export parameters
+func TestQueryParamsParserExport(t *testing.T) {
+	tests := []struct {
+		name    string
+		export  string
+		query   map[string]string
+		format  string
+		columns string
+		err     string
+	}{
+		{
+			name:    "Default users columns",
+			export:  ExportUsers,
+			query:   map[string]string{},
+			format:  "json",
+			columns: "id,email,wallet_id,currency,balance",
+		},
+		{
+			name:    "Selected balances columns",
+			export:  ExportBalances,
+			query:   map[string]string{"format": "csv", "columns": "currency,total"},
+			format:  "csv",
+			columns: "currency,total",
+		},
+		{
+			name:   "Column of another export",
+			export: ExportBalances,
+			query:  map[string]string{"columns": "email"},
+			err:    "unknown column in 'columns' attribute: email",
+		},
+		{
+			name:   "Statement format",
+			export: ExportUsers,
+			query:  map[string]string{"format": "ofx"},
+			err:    "unsupported format of users export: ofx",
+		},
+		{
+			name:   "Protobuf format",
+			export: ExportBalances,
+			query:  map[string]string{"format": "protobuf"},
+			err:    "unsupported format of balances export: protobuf",
+		},
+		{
+			name:    "Msgpack format",
+			export:  ExportBalances,
+			query:   map[string]string{"format": "msgpack"},
+			format:  "msgpack",
+			columns: "currency,wallets,total,as_of",
+		},
+		{
+			name:   "Unknown export",
+			export: "holds",
+			query:  map[string]string{},
+			err:    "unknown export: holds",
+		},
+	}
+	qpr := QueryParamsReader{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			params := make(url.Values)
+			for k, v := range tc.query {
+				params.Set(k, v)
+			}
+			queryParams, err := qpr.ParseExport(tc.export, params)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Errorf("Expected error '%s', got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if queryParams.Format != tc.format || queryParams.Export != tc.export {
+				t.Errorf("Format or export mismatch: %s, %s", queryParams.Format, queryParams.Export)
+			}
+			if strings.Join(queryParams.Options.Columns, ",") != tc.columns {
+				t.Errorf("Columns mismatch. Expected %s, got %v", tc.columns, queryParams.Options.Columns)
+			}
+		})
+	}
+	if strings.Join(ExportColumns[ExportBalances], ",") != "currency,wallets,total,as_of" {
+		t.Errorf("Columns of export are modified: %v", ExportColumns[ExportBalances])
+	}
+}
+
+// Test parsing of operations feed parameters
+func TestQueryParamsParserFeed(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  map[string]string
+		format string
+		limit  int
+		err    string
+	}{
+		{name: "Default parameters", query: map[string]string{}, format: "json", limit: DefaultFeedLimit},
+		{name: "Protobuf batch", query: map[string]string{"format": "protobuf", "limit": "500"}, format: "protobuf", limit: 500},
+		{name: "Maximal limit", query: map[string]string{"limit": "10000"}, format: "json", limit: MaxFeedLimit},
+		{name: "Too large limit", query: map[string]string{"limit": "10001"}, err: "invalid 'limit' attribute (1-10000): 10001"},
+		{name: "Zero limit", query: map[string]string{"limit": "0"}, err: "invalid 'limit' attribute (1-10000): 0"},
+		{name: "Statement format", query: map[string]string{"format": "qif"}, err: "unsupported format of operations feed: qif"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			params := make(url.Values)
+			for k, v := range tc.query {
+				params.Set(k, v)
+			}
+			queryParams, err := QueryParamsReader{}.ParseFeed(params)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Errorf("Expected error '%s', got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if queryParams.Format != tc.format || queryParams.Limit != tc.limit {
+				t.Errorf("Format or limit mismatch: %s, %d", queryParams.Format, queryParams.Limit)
+			}
+			if queryParams.ListParams == nil || queryParams.ListParams.After != nil {
+				t.Errorf("Range of operations should be empty: %+v", queryParams.ListParams)
+			}
+		})
+	}
+}
+
+// Test wallet and period parameters of operations report
+func TestQueryParamsParserWalletPeriod(t *testing.T) {
+	params := make(url.Values)
+	params.Set("format", "camt053")
+	params.Set("wallet", "3")
+	params.Set("from", "2021-03-01")
+	params.Set("to", "2021-03-31")
+	qp, err := QueryParamsReader{}.Parse(params)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if qp.ListParams.WalletID != 3 || qp.ListParams.From != "2021-03-01" || qp.ListParams.To != "2021-03-31" {
+		t.Errorf("Wrong list params: %+v", qp.ListParams)
+	}
+
+	params = url.Values{"format": {"template"}, "template": {"partner"}}
+	if qp, err = (QueryParamsReader{}).Parse(params); err != nil || qp.Options.TemplateName != "partner" {
+		t.Errorf("Wrong template parameters: %+v (%v)", qp, err)
+	}
+
+	tests := []struct {
+		query map[string]string
+		err   string
+	}{
+		{map[string]string{"format": "camt053"}, "'wallet' attribute is required for camt053 format"},
+		{map[string]string{"format": "qif"}, "'wallet' attribute is required for qif format"},
+		{map[string]string{"wallet": "-1"}, "invalid 'wallet' attribute: -1"},
+		{map[string]string{"format": "template"}, "'template' attribute is required for template format"},
+		{map[string]string{"to": "31.03.2021"}, "error of 'to' attribute parsing"},
+		{map[string]string{"from": "2021-04-01", "to": "2021-03-31"}, "'from' date should not be after 'to' date"},
+	}
+	for _, tc := range tests {
+		params := make(url.Values)
+		for k, v := range tc.query {
+			params.Set(k, v)
+		}
+		_, err := QueryParamsReader{}.Parse(params)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("[%v] Expected error '%s', got %v", tc.query, tc.err, err)
+		}
+	}
+}
+
+// Test manifest flag and filters written to manifest
+func TestQueryParamsParserManifest(t *testing.T) {
+	params := make(url.Values)
+	params.Set("manifest", "true")
+	params.Set("wallet", "3")
+	params.Set("from", "2021-03-01")
+	params.Set("delimiter", ";")
+	qp, err := QueryParamsReader{}.Parse(params)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !qp.Manifest || len(qp.Filters) != 2 || qp.Filters["wallet"] != "3" || qp.Filters["from"] != "2021-03-01" {
+		t.Errorf("Wrong manifest params: %v %v", qp.Manifest, qp.Filters)
+	}
+
+	params.Set("period", "month")
+	summary, err := QueryParamsReader{}.ParseSummary(params)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !summary.Manifest || summary.Filters["period"] != "month" || summary.Filters["wallet"] != "" {
+		t.Errorf("Wrong summary manifest params: %v %v", summary.Manifest, summary.Filters)
+	}
+
+	params.Set("manifest", "yes")
+	if _, err := (QueryParamsReader{}).Parse(params); err == nil || !strings.Contains(err.Error(), "'manifest' attribute") {
+		t.Errorf("Expected manifest error, got %v", err)
+	}
+}
+
+// Test flag of report persisting in storage
+func TestQueryParamsParserPersist(t *testing.T) {
+	params := url.Values{"persist": []string{"true"}}
+	qp, err := QueryParamsReader{}.Parse(params)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	summary, err := QueryParamsReader{}.ParseSummary(params)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !qp.Persist || !summary.Persist || qp.Filters["persist"] != "" {
+		t.Errorf("Wrong persist params: %v %v %v", qp.Persist, summary.Persist, qp.Filters)
+	}
+
+	params.Set("persist", "forever")
+	if _, err := (QueryParamsReader{}).ParseSummary(params); err == nil || !strings.Contains(err.Error(), "'persist' attribute") {
+		t.Errorf("Expected persist error, got %v", err)
+	}
+}
+
+// Test compression and encryption of report
+func TestQueryParamsParserArchive(t *testing.T) {
+	qp, err := QueryParamsReader{}.Parse(url.Values{})
+	if err != nil || qp.Archive != nil {
+		t.Errorf("Expected report without archive, got %v %v", qp, err)
+	}
+
+	params := url.Values{"compress": []string{"gzip"}, "encrypt": []string{"passphrase"}, "passphrase": []string{"secret"}}
+	summary, err := QueryParamsReader{}.ParseSummary(params)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := ArchiveOptions{Compression: CompressionGzip, Encryption: EncryptionPassphrase, Passphrase: "secret"}
+	if *summary.Archive != expected {
+		t.Errorf("Wrong archive options: %+v", summary.Archive)
+	}
+
+	tests := []struct {
+		query map[string]string
+		err   string
+	}{
+		{map[string]string{"compress": "bzip2"}, "unsupported 'compress' value: bzip2"},
+		{map[string]string{"encrypt": "rsa"}, "unsupported 'encrypt' value: rsa"},
+		{map[string]string{"encrypt": "passphrase"}, "passphrase is required for passphrase encryption"},
+	}
+	for _, tc := range tests {
+		query := url.Values{}
+		for k, v := range tc.query {
+			query.Set(k, v)
+		}
+		if _, err := (QueryParamsReader{}).Parse(query); err == nil || err.Error() != tc.err {
+			t.Errorf("Expected error '%s', got %v", tc.err, err)
+		}
+	}
+}
+
+// Test validation of summary report parameters
+func TestFailedQueryParamsParserSummary(t *testing.T) {
+	tests := []struct {
+		query map[string]string
+		err   string
+	}{
+		{map[string]string{"period": "year"}, "unsupported 'period' value: year"},
+		{map[string]string{"group_by": "user"}, "unsupported dimension in 'group_by' attribute: user"},
+		{map[string]string{"group_by": "wallet,wallet"}, "duplicated dimension in 'group_by' attribute: wallet"},
+		{map[string]string{"from": "01.01.2021"}, "error of 'from' attribute parsing"},
+		{map[string]string{"from": "2021-02-01", "to": "2021-01-01"}, "'from' date should not be after 'to' date"},
+		{map[string]string{"columns": "id"}, "unknown column in 'columns' attribute: id"},
+		{map[string]string{"amount_format": "float"}, "unsupported 'amount_format' value"},
+		{map[string]string{"format": "protobuf"}, "unsupported format of summary report: protobuf"},
+		{map[string]string{"format": "qif"}, "unsupported format of summary report: qif"},
+	}
+	qpr := QueryParamsReader{}
+	for _, tc := range tests {
+		params := make(url.Values)
+		for k, v := range tc.query {
+			params.Set(k, v)
+		}
+		_, err := qpr.ParseSummary(params)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("[%v] Expected error '%s', got %v", tc.query, tc.err, err)
+		}
+	}
+}
+
 // Benchmark parameters parsing
 func BenchmarkParse(b *testing.B) {
 	params := make(url.Values)
@@ -0,0 +1,160 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/entities"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Kinds of report rows; rows of exports have names of their exports as kinds
+const (
+	OperationRows = "operations"
+	SummaryRows   = "summary"
+)
+
+// Kinds of rows of formats with fixed schema; formats which are not listed contain rows of any kind
+var formatRowKinds = map[string][]string{
+	"protobuf":     {OperationRows},
+	TemplateFormat: {OperationRows},
+	"camt053":      {OperationRows},
+	"ofx":          {OperationRows},
+	"qif":          {OperationRows},
+}
+
+// SupportsRows checks whether report format can contain rows of the kind
+func SupportsRows(format, kind string) bool {
+	kinds, restricted := formatRowKinds[format]
+	return !restricted || hasString(kinds, kind)
+}
+
+// ReportRow represents row of any reported entity. Values of columns are nil (NULL), int, string,
+// decimal.Decimal (amounts), time.Time (timestamps) or ReportDate (days).
+type ReportRow interface {
+	Kind() string
+	RowID() int
+	Value(column string) interface{}
+}
+
+// ReportDate represents day value of report's row
+type ReportDate time.Time
+
+// OperationRow adapts entities.WalletOperation to ReportRow interface
+type OperationRow struct {
+	*entities.WalletOperation
+}
+
+// Kind returns kind of operation rows
+func (or OperationRow) Kind() string {
+	return OperationRows
+}
+
+// RowID returns operation's id
+func (or OperationRow) RowID() int {
+	return or.ID
+}
+
+// Value returns value of operation's column
+func (or OperationRow) Value(column string) interface{} {
+	switch column {
+	case "id":
+		return or.ID
+	case "operation":
+		return or.Operation
+	case "wallet_from":
+		if !or.WalletFrom.Valid {
+			return nil
+		}
+		return int(or.WalletFrom.Int32)
+	case "wallet_to":
+		return or.WalletTo
+	case "amount":
+		return or.Amount
+	case "created_at":
+		return or.CreatedAt
+	}
+	return nil
+}
+
+// SummaryRow adapts entities.OperationSummary to ReportRow interface
+type SummaryRow struct {
+	*entities.OperationSummary
+}
+
+// Kind returns kind of summary rows
+func (sr SummaryRow) Kind() string {
+	return SummaryRows
+}
+
+// RowID returns zero, summary rows have no identifiers
+func (sr SummaryRow) RowID() int {
+	return 0
+}
+
+// Value returns value of summary's column; dimensions without grouping are NULL
+func (sr SummaryRow) Value(column string) interface{} {
+	switch column {
+	case "period":
+		return ReportDate(sr.Period)
+	case GroupByOperation:
+		if !sr.Operation.Valid {
+			return nil
+		}
+		return sr.Operation.String
+	case GroupByWallet:
+		if !sr.WalletID.Valid {
+			return nil
+		}
+		return int(sr.WalletID.Int32)
+	case GroupByCurrency:
+		if !sr.Currency.Valid {
+			return nil
+		}
+		return sr.Currency.String
+	case "count":
+		return sr.Count
+	case "total":
+		return sr.Total
+	}
+	return nil
+}
+
+// rowOperation returns wallet operation of row; formats of wallet operations reject rows of other kinds
+func rowOperation(row ReportRow, format string) (*entities.WalletOperation, error) {
+	operationRow, isOperation := row.(OperationRow)
+	if !isOperation {
+		return nil, fmt.Errorf("%s rows are not supported by %s format", row.Kind(), format)
+	}
+	return operationRow.WalletOperation, nil
+}
+
+// jsonValue marshals value of row's column to json
+func jsonValue(value interface{}, amountFormat string, marshall func(v interface{}) ([]byte, error)) ([]byte, error) {
+	switch v := value.(type) {
+	case decimal.Decimal:
+		return ReportAmount{Value: v, AsNumber: amountFormat == AmountAsNumber}.MarshalJSON()
+	case ReportDate:
+		return []byte(strconv.Quote(time.Time(v).Format(summaryPeriodLayout))), nil
+	}
+	return marshall(value)
+}
+
+// marshallJSONRow marshals row's columns to json object keeping columns' order
+func marshallJSONRow(row ReportRow, columns []string, amountFormat string, marshall func(v interface{}) ([]byte, error)) ([]byte, error) {
+	data := []byte{'{'}
+	for idx, column := range columns {
+		if idx > 0 {
+			data = append(data, ',')
+		}
+		value, valueErr := jsonValue(row.Value(column), amountFormat, marshall)
+		if valueErr != nil {
+			return nil, fmt.Errorf("error of '%s' column marshalling: %s", column, valueErr)
+		}
+		data = append(data, strconv.Quote(column)...)
+		data = append(data, ':')
+		data = append(data, value...)
+	}
+	return append(data, '}'), nil
+}
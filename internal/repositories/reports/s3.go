@@ -0,0 +1,315 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/entities"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm        = "AWS4-HMAC-SHA256"
+	s3DateTimeLayout   = "20060102T150405Z"
+	s3DateLayout       = "20060102"
+	s3EmptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// S3Config represents connection to S3-compatible object store
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+// S3Storage implements FileStorageManager interface for S3-compatible object store.
+// Objects are addressed in path style: endpoint/bucket/name.
+type S3Storage struct {
+	config S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Storage returns storage in given bucket
+func NewS3Storage(config S3Config, client *http.Client) (*S3Storage, error) {
+	if config.Endpoint == "" || config.Bucket == "" {
+		return nil, fmt.Errorf("endpoint and bucket of s3 storage are required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	return &S3Storage{
+		config: config,
+		client: client,
+		now:    time.Now,
+	}, nil
+}
+
+// Create returns writer which buffers report in temporary file and uploads it on closing
+func (ss S3Storage) Create(name string) (io.WriteCloser, error) {
+	if nameErr := validReportName(name); nameErr != nil {
+		return nil, nameErr
+	}
+	buffer, tmpErr := os.CreateTemp("", "s3-upload-*")
+	if tmpErr != nil {
+		return nil, fmt.Errorf("error of upload buffer creation: %s", tmpErr)
+	}
+	return &s3Writer{
+		storage: ss,
+		name:    name,
+		buffer:  buffer,
+		hash:    sha256.New(),
+	}, nil
+}
+
+// Open returns reader of stored object; content is requested lazily with ranged GET requests
+func (ss S3Storage) Open(name string) (entities.ReportFile, error) {
+	if nameErr := validReportName(name); nameErr != nil {
+		return nil, nameErr
+	}
+	resp, reqErr := ss.do(http.MethodHead, name, nil, -1, s3EmptyPayloadHash, nil)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrReportNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error of s3 object receiving: %s", resp.Status)
+	}
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &s3Object{
+		storage: ss,
+		name:    name,
+		size:    resp.ContentLength,
+		modTime: modTime,
+	}, nil
+}
+
+// Remove removes stored object
+func (ss S3Storage) Remove(name string) error {
+	if nameErr := validReportName(name); nameErr != nil {
+		return nameErr
+	}
+	resp, reqErr := ss.do(http.MethodDelete, name, nil, -1, s3EmptyPayloadHash, nil)
+	if reqErr != nil {
+		return reqErr
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return ErrReportNotFound
+	}
+	return fmt.Errorf("error of s3 object removing: %s", resp.Status)
+}
+
+// do sends signed request for the object
+func (ss S3Storage) do(method, name string, body io.Reader, size int64, payloadHash string, headers map[string]string) (*http.Response, error) {
+	objectURL := strings.TrimRight(ss.config.Endpoint, "/") + "/" + url.PathEscape(ss.config.Bucket) + "/" + url.PathEscape(name)
+	req, reqErr := http.NewRequest(method, objectURL, body)
+	if reqErr != nil {
+		return nil, fmt.Errorf("error of s3 request creation: %s", reqErr)
+	}
+	if size >= 0 {
+		req.ContentLength = size
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	signS3Request(req, payloadHash, ss.config, ss.now())
+	resp, doErr := ss.client.Do(req)
+	if doErr != nil {
+		return nil, fmt.Errorf("error of s3 request: %s", doErr)
+	}
+	return resp, nil
+}
+
+type s3Writer struct {
+	storage S3Storage
+	name    string
+	buffer  *os.File
+	hash    hash.Hash
+	size    int64
+}
+
+func (sw *s3Writer) Write(p []byte) (int, error) {
+	n, writeErr := sw.buffer.Write(p)
+	sw.hash.Write(p[:n])
+	sw.size += int64(n)
+	return n, writeErr
+}
+
+// Close uploads buffered report and removes temporary file
+func (sw *s3Writer) Close() error {
+	defer func() {
+		sw.buffer.Close()
+		os.Remove(sw.buffer.Name())
+	}()
+	if _, seekErr := sw.buffer.Seek(0, io.SeekStart); seekErr != nil {
+		return fmt.Errorf("error of upload buffer reading: %s", seekErr)
+	}
+	resp, reqErr := sw.storage.do(http.MethodPut, sw.name, io.NopCloser(sw.buffer), sw.size, hex.EncodeToString(sw.hash.Sum(nil)), nil)
+	if reqErr != nil {
+		return reqErr
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error of s3 object uploading: %s", resp.Status)
+	}
+	return nil
+}
+
+type s3Object struct {
+	storage S3Storage
+	name    string
+	size    int64
+	modTime time.Time
+	offset  int64
+	body    io.ReadCloser
+}
+
+func (so *s3Object) Read(p []byte) (int, error) {
+	if so.offset >= so.size {
+		return 0, io.EOF
+	}
+	if so.body == nil {
+		headers := map[string]string{"Range": "bytes=" + strconv.FormatInt(so.offset, 10) + "-"}
+		resp, reqErr := so.storage.do(http.MethodGet, so.name, nil, -1, s3EmptyPayloadHash, headers)
+		if reqErr != nil {
+			return 0, reqErr
+		}
+		if resp.StatusCode != http.StatusPartialContent && resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return 0, fmt.Errorf("error of s3 object reading: %s", resp.Status)
+		}
+		so.body = resp.Body
+	}
+	n, readErr := so.body.Read(p)
+	so.offset += int64(n)
+	if readErr == io.EOF && so.offset < so.size {
+		readErr = io.ErrUnexpectedEOF
+	}
+	return n, readErr
+}
+
+// Seek moves offset of the next ranged request
+func (so *s3Object) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += so.offset
+	case io.SeekEnd:
+		offset += so.size
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative offset of s3 object: %d", offset)
+	}
+	if offset != so.offset {
+		so.Close()
+		so.offset = offset
+	}
+	return offset, nil
+}
+
+func (so *s3Object) Close() error {
+	if so.body == nil {
+		return nil
+	}
+	closeErr := so.body.Close()
+	so.body = nil
+	return closeErr
+}
+
+func (so *s3Object) Size() int64 {
+	return so.size
+}
+
+func (so *s3Object) ModTime() time.Time {
+	return so.modTime
+}
+
+// signS3Request adds AWS Signature Version 4 headers; host, range and x-amz-* headers are signed
+func signS3Request(req *http.Request, payloadHash string, config S3Config, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(s3DateTimeLayout)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		name := strings.ToLower(key)
+		if name == "range" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	canonicalHeaders := ""
+	for _, name := range names {
+		canonicalHeaders += name + ":" + headers[name] + "\n"
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	query := req.URL.Query()
+	queryKeys := make([]string, 0, len(query))
+	for key := range query {
+		queryKeys = append(queryKeys, key)
+	}
+	sort.Strings(queryKeys)
+	canonicalQuery := make([]string, 0, len(queryKeys))
+	for _, key := range queryKeys {
+		for _, value := range query[key] {
+			canonicalQuery = append(canonicalQuery, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+
+	canonicalURI := req.URL.EscapedPath()
+	if canonicalURI == "" {
+		canonicalURI = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		strings.Join(canonicalQuery, "&"),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{now.Format(s3DateLayout), config.Region, "s3", "aws4_request"}, "/")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{s3Algorithm, amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+config.SecretKey), now.Format(s3DateLayout))
+	key = hmacSHA256(key, config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, config.AccessKey, scope, signedHeaders, signature,
+	))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,186 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/entities"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SignatureAlgorithm is the algorithm of reports signatures
+const SignatureAlgorithm = "ed25519"
+
+// ReportSigner defines contracts for signing of report's SHA-256 checksum
+type ReportSigner interface {
+	Sign(digest []byte) []byte
+	KeyID() string
+	PublicKey() ed25519.PublicKey
+}
+
+// Ed25519Signer implements ReportSigner interface
+type Ed25519Signer struct {
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+// NewEd25519Signer returns signer with given private key
+func NewEd25519Signer(key ed25519.PrivateKey) *Ed25519Signer {
+	return &Ed25519Signer{
+		key:   key,
+		keyID: KeyID(key.Public().(ed25519.PublicKey)),
+	}
+}
+
+// GenerateEd25519Signer returns signer with new random key; its signatures can not be verified after restart
+func GenerateEd25519Signer() (*Ed25519Signer, error) {
+	_, key, generateErr := ed25519.GenerateKey(rand.Reader)
+	if generateErr != nil {
+		return nil, fmt.Errorf("error of signing key generation: %s", generateErr)
+	}
+	return NewEd25519Signer(key), nil
+}
+
+// LoadEd25519Signer decodes base64 seed of private key
+func LoadEd25519Signer(encodedSeed string) (*Ed25519Signer, error) {
+	if encodedSeed == "" {
+		return nil, fmt.Errorf("signing key is not configured")
+	}
+	seed, decodeErr := base64.StdEncoding.DecodeString(encodedSeed)
+	if decodeErr != nil {
+		return nil, fmt.Errorf("error of signing key decoding: %s", decodeErr)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("signing key seed must be %d bytes, got %d", ed25519.SeedSize, len(seed))
+	}
+	return NewEd25519Signer(ed25519.NewKeyFromSeed(seed)), nil
+}
+
+// Sign signs report's checksum
+func (es Ed25519Signer) Sign(digest []byte) []byte {
+	return ed25519.Sign(es.key, digest)
+}
+
+// KeyID returns identifier of signer's public key
+func (es Ed25519Signer) KeyID() string {
+	return es.keyID
+}
+
+// PublicKey returns public key for signatures verification
+func (es Ed25519Signer) PublicKey() ed25519.PublicKey {
+	return es.key.Public().(ed25519.PublicKey)
+}
+
+// KeyID returns first 8 bytes of public key's SHA-256 in hex
+func KeyID(publicKey ed25519.PublicKey) string {
+	sum := sha256.Sum256(publicKey)
+	return hex.EncodeToString(sum[:8])
+}
+
+// VerifyReport checks report's content against expected checksum and its signature
+func VerifyReport(report io.Reader, digest, signature []byte, publicKey ed25519.PublicKey) error {
+	hash := sha256.New()
+	if _, readErr := io.Copy(hash, report); readErr != nil {
+		return fmt.Errorf("error of report reading: %s", readErr)
+	}
+	actual := hash.Sum(nil)
+	if digest != nil && !bytes.Equal(actual, digest) {
+		return fmt.Errorf("checksum mismatch: report was modified")
+	}
+	if len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("public key must be %d bytes, got %d", ed25519.PublicKeySize, len(publicKey))
+	}
+	if !ed25519.Verify(publicKey, actual, signature) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+// SignManifest returns canonical json of report's manifest and signature of its SHA-256 checksum;
+// signature is nil without signer
+func SignManifest(manifest *entities.ReportManifest, signer ReportSigner) ([]byte, []byte, error) {
+	// Fields are marshalled in order of declaration and filters in order of keys, so the bytes are canonical
+	data, marshallErr := json.Marshal(manifest)
+	if marshallErr != nil {
+		return nil, nil, fmt.Errorf("error of manifest marshalling: %s", marshallErr)
+	}
+	if signer == nil {
+		return data, nil, nil
+	}
+	digest := sha256.Sum256(data)
+	return data, signer.Sign(digest[:]), nil
+}
+
+// DecodeManifest returns json of report's manifest saved as is or as base64 value of X-Report-Manifest header
+func DecodeManifest(data []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] != '{' {
+		decoded, decodeErr := base64.StdEncoding.DecodeString(string(data))
+		if decodeErr != nil {
+			return nil, fmt.Errorf("error of manifest decoding: %s", decodeErr)
+		}
+		data = decoded
+	}
+	return data, nil
+}
+
+// ParseManifest decodes report's manifest saved as json or as base64 value of X-Report-Manifest header
+func ParseManifest(data []byte) (*entities.ReportManifest, error) {
+	data, decodeErr := DecodeManifest(data)
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+	manifest := &entities.ReportManifest{}
+	if unmarshallErr := json.Unmarshal(data, manifest); unmarshallErr != nil {
+		return nil, fmt.Errorf("error of manifest unmarshalling: %s", unmarshallErr)
+	}
+	return manifest, nil
+}
+
+// VerifyManifestSignature checks signature of manifest's json given by DecodeManifest
+func VerifyManifestSignature(data, signature []byte, publicKey ed25519.PublicKey) error {
+	if verifyErr := VerifyReport(bytes.NewReader(data), nil, signature, publicKey); verifyErr != nil {
+		return fmt.Errorf("manifest is not verified: %s", verifyErr)
+	}
+	return nil
+}
+
+// ParseSignature decodes signature given as base64 or as value of X-Report-Signature header
+func ParseSignature(value string) ([]byte, error) {
+	for _, param := range strings.Split(value, ",") {
+		name, paramValue, found := strings.Cut(strings.TrimSpace(param), "=")
+		if found && name == "signature" {
+			value = strings.Trim(paramValue, `"`)
+		}
+	}
+	signature, decodeErr := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if decodeErr != nil {
+		return nil, fmt.Errorf("error of signature decoding: %s", decodeErr)
+	}
+	return signature, nil
+}
+
+// VerifyManifest checks report against checksum and signature of its manifest
+func VerifyManifest(report io.Reader, manifest *entities.ReportManifest, publicKey ed25519.PublicKey) error {
+	if manifest.Algorithm != SignatureAlgorithm {
+		return fmt.Errorf("unsupported signature algorithm: %q", manifest.Algorithm)
+	}
+	if manifest.KeyID != KeyID(publicKey) {
+		return fmt.Errorf("report is signed with key %s, public key is %s", manifest.KeyID, KeyID(publicKey))
+	}
+	digest, digestErr := hex.DecodeString(manifest.SHA256)
+	if digestErr != nil {
+		return fmt.Errorf("error of manifest checksum decoding: %s", digestErr)
+	}
+	signature, signatureErr := ParseSignature(manifest.Signature)
+	if signatureErr != nil {
+		return signatureErr
+	}
+	return VerifyReport(report, digest, signature, publicKey)
+}
@@ -0,0 +1,145 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/entities"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// Test loading of signing key from base64 seed
+func TestLoadEd25519Signer(t *testing.T) {
+	seed := bytes.Repeat([]byte{7}, ed25519.SeedSize)
+	signer, err := LoadEd25519Signer(base64.StdEncoding.EncodeToString(seed))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+	if !bytes.Equal(signer.PublicKey(), expected) || signer.KeyID() != KeyID(expected) || len(signer.KeyID()) != 16 {
+		t.Errorf("Wrong key: %s", signer.KeyID())
+	}
+
+	for _, encoded := range []string{"", "not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := LoadEd25519Signer(encoded); err == nil {
+			t.Errorf("[%s] Expected error, got nil", encoded)
+		}
+	}
+}
+
+// Test verification of signed reports
+func TestVerifyReport(t *testing.T) {
+	signer, _ := GenerateEd25519Signer()
+	other, _ := GenerateEd25519Signer()
+	report := []byte("{\"id\":1}\n")
+	digest := sha256.Sum256(report)
+	signature := signer.Sign(digest[:])
+
+	tests := []struct {
+		name      string
+		report    []byte
+		digest    []byte
+		publicKey ed25519.PublicKey
+		err       string
+	}{
+		{name: "Valid report", report: report, digest: digest[:], publicKey: signer.PublicKey()},
+		{name: "Valid report without checksum", report: report, publicKey: signer.PublicKey()},
+		{name: "Modified report", report: []byte("{\"id\":2}\n"), digest: digest[:], publicKey: signer.PublicKey(), err: "checksum mismatch"},
+		{name: "Modified report without checksum", report: []byte("{\"id\":2}\n"), publicKey: signer.PublicKey(), err: "invalid signature"},
+		{name: "Another key", report: report, digest: digest[:], publicKey: other.PublicKey(), err: "invalid signature"},
+		{name: "Wrong public key", report: report, publicKey: []byte("key"), err: "public key must be 32 bytes"},
+	}
+	for _, tc := range tests {
+		verifyErr := VerifyReport(bytes.NewReader(tc.report), tc.digest, signature, tc.publicKey)
+		if tc.err == "" && verifyErr != nil {
+			t.Errorf("[%s] Unexpected error: %s", tc.name, verifyErr)
+		}
+		if tc.err != "" && (verifyErr == nil || !strings.Contains(verifyErr.Error(), tc.err)) {
+			t.Errorf("[%s] Expected error '%s', got %v", tc.name, tc.err, verifyErr)
+		}
+	}
+}
+
+// Test verification of report by its manifest saved from response header
+func TestVerifyManifest(t *testing.T) {
+	signer, _ := GenerateEd25519Signer()
+	report := []byte("id,amount\n1,10.00\n")
+	digest := sha256.Sum256(report)
+	manifestJSON, _ := json.Marshal(entities.ReportManifest{
+		Format:    "csv",
+		SHA256:    hex.EncodeToString(digest[:]),
+		Algorithm: SignatureAlgorithm,
+		KeyID:     signer.KeyID(),
+		Signature: base64.StdEncoding.EncodeToString(signer.Sign(digest[:])),
+	})
+
+	for _, data := range [][]byte{manifestJSON, []byte(base64.StdEncoding.EncodeToString(manifestJSON) + "\n")} {
+		manifest, parseErr := ParseManifest(data)
+		if parseErr != nil {
+			t.Fatalf("Unexpected error: %s", parseErr)
+		}
+		if verifyErr := VerifyManifest(bytes.NewReader(report), manifest, signer.PublicKey()); verifyErr != nil {
+			t.Errorf("Unexpected error: %s", verifyErr)
+		}
+		if verifyErr := VerifyManifest(bytes.NewReader([]byte("id,amount\n1,99.00\n")), manifest, signer.PublicKey()); verifyErr == nil {
+			t.Errorf("Expected error for modified report, got nil")
+		}
+	}
+
+	other, _ := GenerateEd25519Signer()
+	manifest, _ := ParseManifest(manifestJSON)
+	if verifyErr := VerifyManifest(bytes.NewReader(report), manifest, other.PublicKey()); verifyErr == nil || !strings.Contains(verifyErr.Error(), "report is signed with key") {
+		t.Errorf("Expected key mismatch error, got %v", verifyErr)
+	}
+	if _, parseErr := ParseManifest([]byte("not a manifest")); parseErr == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+// Test canonical manifest is signed and its modification is detected
+func TestSignManifest(t *testing.T) {
+	signer, _ := GenerateEd25519Signer()
+	manifest := &entities.ReportManifest{Format: "csv", Filters: map[string]string{"wallet": "3", "from": "2021-03-01"}, Rows: 2}
+	data, signature, signErr := SignManifest(manifest, signer)
+	if signErr != nil {
+		t.Fatalf("Unexpected error: %s", signErr)
+	}
+	again, _, _ := SignManifest(manifest, signer)
+	if !bytes.Equal(data, again) {
+		t.Errorf("Manifest is not canonical: %s and %s", data, again)
+	}
+
+	header := []byte(base64.StdEncoding.EncodeToString(data) + "\n")
+	decoded, decodeErr := DecodeManifest(header)
+	if decodeErr != nil {
+		t.Fatalf("Unexpected error: %s", decodeErr)
+	}
+	if verifyErr := VerifyManifestSignature(decoded, signature, signer.PublicKey()); verifyErr != nil {
+		t.Errorf("Unexpected error: %s", verifyErr)
+	}
+	modified := bytes.Replace(decoded, []byte(`"rows":2`), []byte(`"rows":20`), 1)
+	if verifyErr := VerifyManifestSignature(modified, signature, signer.PublicKey()); verifyErr == nil || !strings.Contains(verifyErr.Error(), "manifest is not verified") {
+		t.Errorf("Expected error for modified manifest, got %v", verifyErr)
+	}
+
+	if _, unsigned, _ := SignManifest(manifest, nil); unsigned != nil {
+		t.Errorf("Expected no signature without signer, got %v", unsigned)
+	}
+}
+
+// Test decoding of signature from X-Report-Signature header
+func TestParseSignature(t *testing.T) {
+	for _, value := range []string{"AQID", `keyId="0102030405060708", algorithm="ed25519", signature="AQID"`} {
+		signature, err := ParseSignature(value)
+		if err != nil || !bytes.Equal(signature, []byte{1, 2, 3}) {
+			t.Errorf("[%s] Wrong signature %v: %v", value, signature, err)
+		}
+	}
+	if _, err := ParseSignature(`signature="%%%"`); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
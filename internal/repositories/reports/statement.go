@@ -0,0 +1,139 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/adapters/tx"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/repositories"
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Formats of wallet statements; they need wallet's balances before entries are written
+var statementFormats = map[string]bool{
+	"camt053": true,
+	"ofx":     true,
+	"qif":     true,
+}
+
+// IsStatementFormat checks whether report format is wallet statement
+func IsStatementFormat(format string) bool {
+	return statementFormats[format]
+}
+
+// StatementManager defines contracts for receiving of wallet statement's balances
+type StatementManager interface {
+	Statement(ctx context.Context, params *repositories.ListParams) (*entities.AccountStatement, error)
+}
+
+// StatementService implements StatementManager interface
+type StatementService struct {
+	db tx.SQLQueryAdapter
+}
+
+// NewStatementService returns new instance of StatementService
+func NewStatementService(db tx.SQLQueryAdapter) *StatementService {
+	return &StatementService{
+		db: db,
+	}
+}
+
+// Opening balance is restored from the current one and operations made since the period start.
+// Operation row belongs to the wallet from wallet_to column: deposit credits it, withdrawal debits it.
+const statementQuery = `with bounds as (
+	select coalesce(to_date($2, 'YYYY-MM-DD')::timestamp, '-infinity'::timestamp) as from_ts,
+		coalesce(to_date($3, 'YYYY-MM-DD')::timestamp + interval '1 day', 'infinity'::timestamp) as to_ts
+)
+select w.id, w.currency, w.balance,
+	coalesce(sum(o.amount) filter (where o.operation = $4 and o.created_at >= b.from_ts), 0),
+	coalesce(sum(o.amount) filter (where o.operation = $5 and o.created_at >= b.from_ts), 0),
+	count(o.id) filter (where o.operation = $4 and o.created_at >= b.from_ts and o.created_at < b.to_ts),
+	coalesce(sum(o.amount) filter (where o.operation = $4 and o.created_at >= b.from_ts and o.created_at < b.to_ts), 0),
+	count(o.id) filter (where o.operation = $5 and o.created_at >= b.from_ts and o.created_at < b.to_ts),
+	coalesce(sum(o.amount) filter (where o.operation = $5 and o.created_at >= b.from_ts and o.created_at < b.to_ts), 0)
+from wallets w
+cross join bounds b
+left join wallet_operations o on o.wallet_to = w.id
+where w.id = $1
+group by w.id, w.currency, w.balance`
+
+// Statement returns opening and closing balances and turnover of the wallet for the period
+func (ss StatementService) Statement(ctx context.Context, params *repositories.ListParams) (*entities.AccountStatement, error) {
+	var (
+		statement      = entities.AccountStatement{}
+		balance        decimal.Decimal
+		creditsSince   decimal.Decimal
+		debitsSince    decimal.Decimal
+		from, to       interface{}
+		fromErr, toErr error
+	)
+	if params.From != "" {
+		from = params.From
+		statement.From, fromErr = time.Parse("2006-01-02", params.From)
+	}
+	if params.To != "" {
+		to = params.To
+		statement.To, toErr = time.Parse("2006-01-02", params.To)
+	}
+	if fromErr != nil || toErr != nil {
+		return nil, fmt.Errorf("[STATEMENT]: wrong period %s - %s", params.From, params.To)
+	}
+
+	row := ss.db.QueryRowContext(ctx, statementQuery, params.WalletID, from, to, repositories.Deposit, repositories.Withdrawal)
+	scanErr := row.Scan(
+		&statement.WalletID,
+		&statement.Currency,
+		&balance,
+		&creditsSince,
+		&debitsSince,
+		&statement.CreditCount,
+		&statement.CreditTotal,
+		&statement.DebitCount,
+		&statement.DebitTotal,
+	)
+	if scanErr == sql.ErrNoRows {
+		return nil, fmt.Errorf("[STATEMENT]: wallet %d does not exist", params.WalletID)
+	}
+	if scanErr != nil {
+		return nil, fmt.Errorf("[STATEMENT]: %s", scanErr)
+	}
+
+	statement.OpeningBalance = balance.Sub(creditsSince).Add(debitsSince)
+	statement.ClosingBalance = statement.OpeningBalance.Add(statement.CreditTotal).Sub(statement.DebitTotal)
+	return &statement, nil
+}
+
+// statementEntrySign returns 1 for money in, -1 for money out of statement's wallet and 0 for operations out of statement
+func statementEntrySign(statement *entities.AccountStatement, operation *entities.WalletOperation) int {
+	if operation.WalletTo != statement.WalletID {
+		return 0
+	}
+	switch operation.Operation {
+	case repositories.Deposit:
+		return 1
+	case repositories.Withdrawal:
+		return -1
+	}
+	return 0
+}
+
+// operationReference returns stable reference of wallet operation
+func operationReference(operation *entities.WalletOperation) string {
+	return "OP-" + strconv.Itoa(operation.ID)
+}
+
+// transferInfo describes counterparty of transfer; it is empty for other operations
+func transferInfo(operation *entities.WalletOperation, sign int) string {
+	if !operation.WalletFrom.Valid {
+		return ""
+	}
+	direction := "from"
+	if sign < 0 {
+		direction = "to"
+	}
+	return fmt.Sprintf("Transfer %s wallet %d", direction, operation.WalletFrom.Int32)
+}
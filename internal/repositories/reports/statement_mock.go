@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repositories/reports/statement.go
+
+// Package reports is a generated GoMock package.
+package reports
+
+import (
+	entities "billing_system_test_task/internal/entities"
+	repositories "billing_system_test_task/internal/repositories"
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockStatementManager is a mock of StatementManager interface
+type MockStatementManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatementManagerMockRecorder
+}
+
+// MockStatementManagerMockRecorder is the mock recorder for MockStatementManager
+type MockStatementManagerMockRecorder struct {
+	mock *MockStatementManager
+}
+
+// NewMockStatementManager creates a new mock instance
+func NewMockStatementManager(ctrl *gomock.Controller) *MockStatementManager {
+	mock := &MockStatementManager{ctrl: ctrl}
+	mock.recorder = &MockStatementManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockStatementManager) EXPECT() *MockStatementManagerMockRecorder {
+	return m.recorder
+}
+
+// Statement mocks base method
+func (m *MockStatementManager) Statement(ctx context.Context, params *repositories.ListParams) (*entities.AccountStatement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Statement", ctx, params)
+	ret0, _ := ret[0].(*entities.AccountStatement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Statement indicates an expected call of Statement
+func (mr *MockStatementManagerMockRecorder) Statement(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Statement", reflect.TypeOf((*MockStatementManager)(nil).Statement), ctx, params)
+}
@@ -0,0 +1,93 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/repositories"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+)
+
+var statementColumns = []string{"id", "currency", "balance", "credits_since", "debits_since", "credit_count", "credit_total", "debit_count", "debit_total"}
+
+// Test success statement receiving with balances restored from current balance
+func TestSuccessStatementServiceStatement(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+
+	// balance 100 = opening + 70 (since 2021-03-01) - 20 (since 2021-03-01); period turnover: +50, -20
+	rows := sqlmock.NewRows(statementColumns).AddRow(1, "USD", "100", "70", "20", 2, "50", 1, "20")
+	mock.ExpectQuery("with bounds as").
+		WithArgs([]driver.Value{1, "2021-03-01", "2021-03-31", repositories.Deposit, repositories.Withdrawal}...).
+		WillReturnRows(rows)
+
+	ss := NewStatementService(db)
+	statement, statementErr := ss.Statement(context.Background(), &repositories.ListParams{WalletID: 1, From: "2021-03-01", To: "2021-03-31"})
+	if statementErr != nil {
+		t.Fatalf("Unexpected error: %s", statementErr)
+	}
+	if !statement.OpeningBalance.Equal(decimal.NewFromInt(50)) || !statement.ClosingBalance.Equal(decimal.NewFromInt(80)) {
+		t.Errorf("Wrong balances: %s - %s", statement.OpeningBalance, statement.ClosingBalance)
+	}
+	if statement.Currency != "USD" || statement.CreditCount != 2 || statement.DebitCount != 1 || statement.From.Format("2006-01-02") != "2021-03-01" {
+		t.Errorf("Wrong statement: %+v", statement)
+	}
+}
+
+// Test failed statement receiving
+func TestFailedStatementServiceStatement(t *testing.T) {
+	tests := []struct {
+		name   string
+		params *repositories.ListParams
+		mock   func(mock sqlmock.Sqlmock)
+		err    string
+	}{
+		{
+			name:   "Wallet does not exist",
+			params: &repositories.ListParams{WalletID: 5},
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("with bounds as").
+					WithArgs([]driver.Value{5, nil, nil, repositories.Deposit, repositories.Withdrawal}...).
+					WillReturnError(sql.ErrNoRows)
+			},
+			err: "[STATEMENT]: wallet 5 does not exist",
+		},
+		{
+			name:   "Query error",
+			params: &repositories.ListParams{WalletID: 1},
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("with bounds as").WillReturnError(fmt.Errorf("query error"))
+			},
+			err: "[STATEMENT]: query error",
+		},
+		{
+			name:   "Wrong period",
+			params: &repositories.ListParams{WalletID: 1, From: "01.03.2021"},
+			mock:   func(mock sqlmock.Sqlmock) {},
+			err:    "[STATEMENT]: wrong period",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("cant create mock: %s", err)
+			}
+			defer db.Close()
+			tc.mock(mock)
+
+			_, statementErr := NewStatementService(db).Statement(context.Background(), tc.params)
+			if statementErr == nil || !strings.Contains(statementErr.Error(), tc.err) {
+				t.Errorf("Expected error '%s', got %v", tc.err, statementErr)
+			}
+		})
+	}
+}
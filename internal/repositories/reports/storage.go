@@ -0,0 +1,213 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/entities"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backends of reports storage
+const (
+	StorageLocal  = "local"
+	StorageMemory = "memory"
+	StorageS3     = "s3"
+)
+
+// ErrReportNotFound is returned by storages when report does not exist
+var ErrReportNotFound = errors.New("report not found")
+
+var reportNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// FileStorageManager represents interface for reports storage backend
+type FileStorageManager interface {
+	Create(name string) (io.WriteCloser, error)
+	Open(name string) (entities.ReportFile, error)
+	Remove(name string) error
+}
+
+// NewStorage returns storage backend from configuration
+func NewStorage(config entities.ReportStorageConfig) (FileStorageManager, error) {
+	switch config.Backend {
+	case "", StorageLocal:
+		return NewLocalStorage(config.Dir)
+	case StorageMemory:
+		return NewMemoryStorage(), nil
+	case StorageS3:
+		return NewS3Storage(S3Config{
+			Endpoint:  config.S3Endpoint,
+			Region:    config.S3Region,
+			Bucket:    config.S3Bucket,
+			AccessKey: config.S3AccessKey,
+			SecretKey: config.S3SecretKey,
+		}, http.DefaultClient)
+	}
+	return nil, fmt.Errorf("unsupported reports storage: %s", config.Backend)
+}
+
+// validReportName checks that name can not escape storage's directory or bucket
+func validReportName(name string) error {
+	if !reportNamePattern.MatchString(name) || strings.Contains(name, "..") {
+		return fmt.Errorf("invalid report name: %q", name)
+	}
+	return nil
+}
+
+// LocalStorage implements FileStorageManager interface for local directory
+type LocalStorage struct {
+	dir string
+}
+
+// NewLocalStorage returns storage in given directory; directory is created when it does not exist
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "billing-reports")
+	}
+	if mkdirErr := os.MkdirAll(dir, 0755); mkdirErr != nil {
+		return nil, fmt.Errorf("error of reports directory creation: %s", mkdirErr)
+	}
+	return &LocalStorage{
+		dir: dir,
+	}, nil
+}
+
+// Create creates new file in storage's directory
+func (ls LocalStorage) Create(name string) (io.WriteCloser, error) {
+	if nameErr := validReportName(name); nameErr != nil {
+		return nil, nameErr
+	}
+	return os.OpenFile(filepath.Join(ls.dir, name), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+}
+
+// Open opens stored file for reading
+func (ls LocalStorage) Open(name string) (entities.ReportFile, error) {
+	if nameErr := validReportName(name); nameErr != nil {
+		return nil, nameErr
+	}
+	f, openErr := os.Open(filepath.Join(ls.dir, name))
+	if os.IsNotExist(openErr) {
+		return nil, ErrReportNotFound
+	}
+	if openErr != nil {
+		return nil, openErr
+	}
+	info, statErr := f.Stat()
+	if statErr != nil {
+		f.Close()
+		return nil, statErr
+	}
+	return &localFile{File: f, info: info}, nil
+}
+
+// Remove removes stored file
+func (ls LocalStorage) Remove(name string) error {
+	if nameErr := validReportName(name); nameErr != nil {
+		return nameErr
+	}
+	removeErr := os.Remove(filepath.Join(ls.dir, name))
+	if os.IsNotExist(removeErr) {
+		return ErrReportNotFound
+	}
+	return removeErr
+}
+
+type localFile struct {
+	*os.File
+	info os.FileInfo
+}
+
+func (lf localFile) Size() int64 {
+	return lf.info.Size()
+}
+
+func (lf localFile) ModTime() time.Time {
+	return lf.info.ModTime()
+}
+
+// MemoryStorage implements FileStorageManager interface in memory; it is used in tests
+type MemoryStorage struct {
+	mu      *sync.RWMutex
+	objects map[string]*memoryObject
+}
+
+type memoryObject struct {
+	data    []byte
+	modTime time.Time
+}
+
+// NewMemoryStorage returns empty in-memory storage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		mu:      &sync.RWMutex{},
+		objects: map[string]*memoryObject{},
+	}
+}
+
+// Create returns writer which stores content on closing
+func (ms MemoryStorage) Create(name string) (io.WriteCloser, error) {
+	if nameErr := validReportName(name); nameErr != nil {
+		return nil, nameErr
+	}
+	return &memoryWriter{storage: ms, name: name}, nil
+}
+
+// Open returns reader of stored content
+func (ms MemoryStorage) Open(name string) (entities.ReportFile, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	object, exists := ms.objects[name]
+	if !exists {
+		return nil, ErrReportNotFound
+	}
+	return &memoryFile{Reader: bytes.NewReader(object.data), size: int64(len(object.data)), modTime: object.modTime}, nil
+}
+
+// Remove removes stored content
+func (ms MemoryStorage) Remove(name string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if _, exists := ms.objects[name]; !exists {
+		return ErrReportNotFound
+	}
+	delete(ms.objects, name)
+	return nil
+}
+
+type memoryWriter struct {
+	bytes.Buffer
+	storage MemoryStorage
+	name    string
+}
+
+func (mw *memoryWriter) Close() error {
+	mw.storage.mu.Lock()
+	defer mw.storage.mu.Unlock()
+	mw.storage.objects[mw.name] = &memoryObject{data: mw.Bytes(), modTime: time.Now()}
+	return nil
+}
+
+type memoryFile struct {
+	*bytes.Reader
+	size    int64
+	modTime time.Time
+}
+
+func (mf memoryFile) Size() int64 {
+	return mf.size
+}
+
+func (mf memoryFile) ModTime() time.Time {
+	return mf.modTime
+}
+
+func (mf memoryFile) Close() error {
+	return nil
+}
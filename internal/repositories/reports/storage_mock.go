@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repositories/reports/storage.go
+
+// Package reports is a generated GoMock package.
+package reports
+
+import (
+	entities "billing_system_test_task/internal/entities"
+	gomock "github.com/golang/mock/gomock"
+	io "io"
+	reflect "reflect"
+)
+
+// MockFileStorageManager is a mock of FileStorageManager interface
+type MockFileStorageManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockFileStorageManagerMockRecorder
+}
+
+// MockFileStorageManagerMockRecorder is the mock recorder for MockFileStorageManager
+type MockFileStorageManagerMockRecorder struct {
+	mock *MockFileStorageManager
+}
+
+// NewMockFileStorageManager creates a new mock instance
+func NewMockFileStorageManager(ctrl *gomock.Controller) *MockFileStorageManager {
+	mock := &MockFileStorageManager{ctrl: ctrl}
+	mock.recorder = &MockFileStorageManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockFileStorageManager) EXPECT() *MockFileStorageManagerMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockFileStorageManager) Create(name string) (io.WriteCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", name)
+	ret0, _ := ret[0].(io.WriteCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockFileStorageManagerMockRecorder) Create(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFileStorageManager)(nil).Create), name)
+}
+
+// Open mocks base method
+func (m *MockFileStorageManager) Open(name string) (entities.ReportFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", name)
+	ret0, _ := ret[0].(entities.ReportFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Open indicates an expected call of Open
+func (mr *MockFileStorageManagerMockRecorder) Open(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockFileStorageManager)(nil).Open), name)
+}
+
+// Remove mocks base method
+func (m *MockFileStorageManager) Remove(name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove
+func (mr *MockFileStorageManagerMockRecorder) Remove(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockFileStorageManager)(nil).Remove), name)
+}
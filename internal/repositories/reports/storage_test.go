@@ -0,0 +1,106 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/entities"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Test write, read and removing of report in storages
+func TestStorages(t *testing.T) {
+	localStorage, localErr := NewLocalStorage(t.TempDir())
+	if localErr != nil {
+		t.Fatalf("Unexpected error: %s", localErr)
+	}
+	storages := map[string]FileStorageManager{
+		"local":  localStorage,
+		"memory": NewMemoryStorage(),
+	}
+	for name, storage := range storages {
+		w, createErr := storage.Create("report-1.csv")
+		if createErr != nil {
+			t.Fatalf("[%s] Unexpected error: %s", name, createErr)
+		}
+		_, _ = w.Write([]byte("id,amount\n"))
+		_, _ = w.Write([]byte("1,10.00\n"))
+		if closeErr := w.Close(); closeErr != nil {
+			t.Fatalf("[%s] Unexpected error: %s", name, closeErr)
+		}
+
+		report, openErr := storage.Open("report-1.csv")
+		if openErr != nil {
+			t.Fatalf("[%s] Unexpected error: %s", name, openErr)
+		}
+		if report.Size() != 18 || report.ModTime().IsZero() {
+			t.Errorf("[%s] Wrong size %d or modification time %s", name, report.Size(), report.ModTime())
+		}
+		_, _ = report.Seek(10, io.SeekStart)
+		data, _ := ioutil.ReadAll(report)
+		if string(data) != "1,10.00\n" {
+			t.Errorf("[%s] Wrong content after seeking: %q", name, data)
+		}
+		_ = report.Close()
+
+		if removeErr := storage.Remove("report-1.csv"); removeErr != nil {
+			t.Errorf("[%s] Unexpected error: %s", name, removeErr)
+		}
+		if _, openErr = storage.Open("report-1.csv"); openErr != ErrReportNotFound {
+			t.Errorf("[%s] Expected ErrReportNotFound, got %v", name, openErr)
+		}
+		if removeErr := storage.Remove("report-1.csv"); removeErr != ErrReportNotFound {
+			t.Errorf("[%s] Expected ErrReportNotFound, got %v", name, removeErr)
+		}
+	}
+}
+
+// Test names escaping storage's directory are rejected
+func TestStorageInvalidName(t *testing.T) {
+	dir := t.TempDir()
+	storage, _ := NewLocalStorage(filepath.Join(dir, "reports"))
+	for _, name := range []string{"", "../report.csv", "a/b.csv", ".hidden", "report..csv"} {
+		if _, err := storage.Create(name); err == nil {
+			t.Errorf("[%q] Expected error, got nil", name)
+		}
+		if _, err := NewMemoryStorage().Create(name); err == nil {
+			t.Errorf("[%q] Expected error of memory storage, got nil", name)
+		}
+	}
+	if _, statErr := os.Stat(filepath.Join(dir, "report.csv")); !os.IsNotExist(statErr) {
+		t.Errorf("Report was created outside of storage's directory")
+	}
+}
+
+// Test storage selection by configuration
+func TestNewStorage(t *testing.T) {
+	tests := []struct {
+		config   entities.ReportStorageConfig
+		expected interface{}
+		err      bool
+	}{
+		{config: entities.ReportStorageConfig{Dir: t.TempDir()}, expected: &LocalStorage{}},
+		{config: entities.ReportStorageConfig{Backend: StorageMemory}, expected: &MemoryStorage{}},
+		{config: entities.ReportStorageConfig{Backend: StorageS3, S3Endpoint: "http://localhost:9000", S3Bucket: "reports"}, expected: &S3Storage{}},
+		{config: entities.ReportStorageConfig{Backend: StorageS3}, err: true},
+		{config: entities.ReportStorageConfig{Backend: "ftp"}, err: true},
+	}
+	for _, tc := range tests {
+		storage, err := NewStorage(tc.config)
+		if tc.err {
+			if err == nil {
+				t.Errorf("[%s] Expected error, got nil", tc.config.Backend)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%s] Unexpected error: %s", tc.config.Backend, err)
+			continue
+		}
+		if reflect.TypeOf(storage) != reflect.TypeOf(tc.expected) {
+			t.Errorf("[%s] Expected %T, got %T", tc.config.Backend, tc.expected, storage)
+		}
+	}
+}
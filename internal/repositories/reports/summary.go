@@ -0,0 +1,127 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/adapters/tx"
+	"billing_system_test_task/internal/entities"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Periods of summary report
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// Dimensions of summary report grouping
+const (
+	GroupByOperation = "operation"
+	GroupByWallet    = "wallet"
+	GroupByCurrency  = "currency"
+)
+
+// SQL expressions of grouping dimensions. Operations of transfers (with source wallet) are grouped as transfer
+// apart from deposits and withdrawals; each transfer is counted by its withdrawal and deposit in both wallets.
+var summaryDimensions = []struct {
+	name       string
+	expression string
+	empty      string
+}{
+	{GroupByOperation, "case when o.wallet_from is not null then 'transfer' else o.operation end", "null::varchar"},
+	{GroupByWallet, "o.wallet_to", "null::int"},
+	{GroupByCurrency, "w.currency", "null::varchar"},
+}
+
+// SummaryParams represents parameters of summary report
+type SummaryParams struct {
+	Period  string
+	GroupBy []string
+	From    string
+	To      string
+}
+
+// SummaryManager defines contracts for aggregation of wallet operations
+type SummaryManager interface {
+	Summary(ctx context.Context, params *SummaryParams) ([]*entities.OperationSummary, error)
+}
+
+// SummaryService implements SummaryManager interface
+type SummaryService struct {
+	db tx.SQLQueryAdapter
+}
+
+// NewSummaryService returns new instance of SummaryService
+func NewSummaryService(db tx.SQLQueryAdapter) *SummaryService {
+	return &SummaryService{
+		db: db,
+	}
+}
+
+// Summary aggregates wallet operations by period and given dimensions in database
+func (ss SummaryService) Summary(ctx context.Context, params *SummaryParams) ([]*entities.OperationSummary, error) {
+	query, args := summaryQuery(params)
+	rows, queryErr := ss.db.QueryContext(ctx, query, args...)
+	if queryErr != nil {
+		return nil, fmt.Errorf("[OPERATIONS_SUMMARY]: %s", queryErr)
+	}
+	defer rows.Close()
+
+	summary := []*entities.OperationSummary{}
+	for rows.Next() {
+		row := entities.OperationSummary{}
+		scanErr := rows.Scan(&row.Period, &row.Operation, &row.WalletID, &row.Currency, &row.Count, &row.Total)
+		if scanErr != nil {
+			return nil, fmt.Errorf("[OPERATIONS_SUMMARY_ROW]: %s", scanErr)
+		}
+		summary = append(summary, &row)
+	}
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, fmt.Errorf("[OPERATIONS_SUMMARY]: %s", rowsErr)
+	}
+	return summary, nil
+}
+
+// summaryQuery builds aggregation query; period and dimensions should be validated before
+func summaryQuery(params *SummaryParams) (string, []interface{}) {
+	columns := []string{"date_trunc($1, o.created_at) as period"}
+	groupBy := []string{"1"}
+	for idx, dimension := range summaryDimensions {
+		if hasString(params.GroupBy, dimension.name) {
+			columns = append(columns, dimension.expression)
+			groupBy = append(groupBy, fmt.Sprint(idx+2))
+		} else {
+			columns = append(columns, dimension.empty)
+		}
+	}
+	columns = append(columns, "count(*)", "coalesce(sum(o.amount), 0)")
+
+	args := []interface{}{params.Period}
+	conditions := []string{}
+	if params.From != "" {
+		args = append(args, params.From)
+		conditions = append(conditions, fmt.Sprintf("o.created_at >= to_date($%d, 'YYYY-MM-DD')", len(args)))
+	}
+	if params.To != "" {
+		args = append(args, params.To)
+		conditions = append(conditions, fmt.Sprintf("o.created_at < to_date($%d, 'YYYY-MM-DD') + 1", len(args)))
+	}
+
+	query := "select " + strings.Join(columns, ", ") +
+		" from wallet_operations o left join wallets w on w.id = o.wallet_to"
+	if len(conditions) > 0 {
+		query += " where " + strings.Join(conditions, " and ")
+	}
+	query += " group by " + strings.Join(groupBy, ", ") + " order by " + strings.Join(groupBy, ", ")
+	return query, args
+}
+
+func hasString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repositories/reports/summary.go
+
+// Package reports is a generated GoMock package.
+package reports
+
+import (
+	entities "billing_system_test_task/internal/entities"
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockSummaryManager is a mock of SummaryManager interface
+type MockSummaryManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockSummaryManagerMockRecorder
+}
+
+// MockSummaryManagerMockRecorder is the mock recorder for MockSummaryManager
+type MockSummaryManagerMockRecorder struct {
+	mock *MockSummaryManager
+}
+
+// NewMockSummaryManager creates a new mock instance
+func NewMockSummaryManager(ctrl *gomock.Controller) *MockSummaryManager {
+	mock := &MockSummaryManager{ctrl: ctrl}
+	mock.recorder = &MockSummaryManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSummaryManager) EXPECT() *MockSummaryManagerMockRecorder {
+	return m.recorder
+}
+
+// Summary mocks base method
+func (m *MockSummaryManager) Summary(ctx context.Context, params *SummaryParams) ([]*entities.OperationSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Summary", ctx, params)
+	ret0, _ := ret[0].([]*entities.OperationSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Summary indicates an expected call of Summary
+func (mr *MockSummaryManagerMockRecorder) Summary(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Summary", reflect.TypeOf((*MockSummaryManager)(nil).Summary), ctx, params)
+}
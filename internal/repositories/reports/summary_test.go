@@ -0,0 +1,130 @@
+package reports
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+)
+
+// Test building of summary query
+func TestSummaryQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   *SummaryParams
+		query    string
+		argsSize int
+	}{
+		{
+			name:     "Grouping by operation",
+			params:   &SummaryParams{Period: PeriodDay, GroupBy: []string{GroupByOperation}},
+			query:    "select date_trunc($1, o.created_at) as period, case when o.wallet_from is not null then 'transfer' else o.operation end, null::int, null::varchar, count(*), coalesce(sum(o.amount), 0) from wallet_operations o left join wallets w on w.id = o.wallet_to group by 1, 2 order by 1, 2",
+			argsSize: 1,
+		},
+		{
+			name:     "Grouping by wallet and currency within dates",
+			params:   &SummaryParams{Period: PeriodMonth, GroupBy: []string{GroupByCurrency, GroupByWallet}, From: "2021-01-01", To: "2021-03-31"},
+			query:    "select date_trunc($1, o.created_at) as period, null::varchar, o.wallet_to, w.currency, count(*), coalesce(sum(o.amount), 0) from wallet_operations o left join wallets w on w.id = o.wallet_to where o.created_at >= to_date($2, 'YYYY-MM-DD') and o.created_at < to_date($3, 'YYYY-MM-DD') + 1 group by 1, 3, 4 order by 1, 3, 4",
+			argsSize: 3,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			query, args := summaryQuery(tc.params)
+			if query != tc.query {
+				t.Errorf("Wrong query.\nExpected: %s\nGot:      %s", tc.query, query)
+			}
+			if len(args) != tc.argsSize || args[0] != tc.params.Period {
+				t.Errorf("Wrong arguments: %v", args)
+			}
+		})
+	}
+}
+
+// Test success summary aggregation
+func TestSuccessSummaryServiceSummary(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+
+	period := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"period", "operation", "wallet", "currency", "count", "sum"}).
+		AddRow(period, "deposit", nil, nil, 3, "150.50").
+		AddRow(period, "transfer", nil, nil, 2, "30.00").
+		AddRow(period, "withdrawal", nil, nil, 1, "20.00")
+	mock.ExpectQuery(regexp.QuoteMeta("select date_trunc($1, o.created_at)")).
+		WithArgs([]driver.Value{PeriodWeek, "2021-03-01"}...).
+		WillReturnRows(rows)
+
+	ss := NewSummaryService(db)
+	summary, summaryErr := ss.Summary(context.Background(), &SummaryParams{
+		Period:  PeriodWeek,
+		GroupBy: []string{GroupByOperation},
+		From:    "2021-03-01",
+	})
+	if summaryErr != nil {
+		t.Fatalf("Unexpected error: %s", summaryErr)
+	}
+	if len(summary) != 3 {
+		t.Fatalf("Expected 3 rows, got %d", len(summary))
+	}
+	first := summary[0]
+	if !first.Period.Equal(period) || first.Operation.String != "deposit" || first.WalletID.Valid || first.Count != 3 || !first.Total.Equal(decimal.RequireFromString("150.5")) {
+		t.Errorf("Wrong summary row: %+v", first)
+	}
+	if transfers := summary[1]; transfers.Operation.String != "transfer" || transfers.Count != 2 || !transfers.Total.Equal(decimal.NewFromInt(30)) {
+		t.Errorf("Wrong summary row of transfers: %+v", transfers)
+	}
+	if mockErr := mock.ExpectationsWereMet(); mockErr != nil {
+		t.Errorf("Unfulfilled expectations: %s", mockErr)
+	}
+}
+
+// Test failed summary aggregation
+func TestFailedSummaryServiceSummary(t *testing.T) {
+	tests := []struct {
+		name      string
+		mockQuery func(mock sqlmock.Sqlmock)
+		err       string
+	}{
+		{
+			name: "Query error",
+			mockQuery: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("select").WillReturnError(fmt.Errorf("query error"))
+			},
+			err: "[OPERATIONS_SUMMARY]: query error",
+		},
+		{
+			name: "Scan error",
+			mockQuery: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"period", "operation", "wallet", "currency", "count", "sum"}).
+					AddRow("not a date", "deposit", nil, nil, 1, "1")
+				mock.ExpectQuery("select").WillReturnRows(rows)
+			},
+			err: "[OPERATIONS_SUMMARY_ROW]",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("cant create mock: %s", err)
+			}
+			defer db.Close()
+			tc.mockQuery(mock)
+
+			_, summaryErr := NewSummaryService(db).Summary(context.Background(), &SummaryParams{Period: PeriodDay})
+			if summaryErr == nil || !strings.Contains(summaryErr.Error(), tc.err) {
+				t.Errorf("Expected error '%s', got %v", tc.err, summaryErr)
+			}
+		})
+	}
+}
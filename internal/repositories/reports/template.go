@@ -0,0 +1,364 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/entities"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// TemplateFormat is the format of operations report with user-defined template
+const TemplateFormat = "template"
+
+// Limits of report templates: size of a section and size of its output for one execution
+const (
+	MaxTemplateSize       = 16 << 10
+	maxTemplateOutputSize = 64 << 10
+)
+
+var errTemplateOutput = errors.New("output of template exceeds limit")
+
+// templateFuncs are helpers available in templates besides text/template builtins
+var templateFuncs = template.FuncMap{
+	// money formats amount with fixed number of decimal places: {{.Amount | money 2}}
+	"money": func(places int, amount TemplateAmount) string {
+		return amount.value.StringFixed(int32(placesSize(places)))
+	},
+	// neg changes sign of amount, e.g. for withdrawals: {{.Amount | neg | money 2}}
+	"neg": func(amount TemplateAmount) TemplateAmount {
+		return TemplateAmount{amount.value.Neg()}
+	},
+	// date formats time with named (rfc3339, rfc3339nano, datetime, date) or go layout: {{.CreatedAt | date "date"}}
+	"date": func(layout string, t time.Time) string {
+		if named, exists := timeLayouts[layout]; exists {
+			layout = named
+		}
+		return t.Format(layout)
+	},
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"replace": func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	// pad and lpad align value to the width of fixed-width layouts
+	"pad": func(width int, s string) string {
+		return s + strings.Repeat(" ", paddingSize(width, s))
+	},
+	"lpad": func(width int, s string) string {
+		return strings.Repeat(" ", paddingSize(width, s)) + s
+	},
+	// csv quotes value of csv field when it is needed
+	"csv": func(s string) string {
+		if !strings.ContainsAny(s, "\",;\t\r\n") {
+			return s
+		}
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	},
+	// printf replaces builtin printf, its width and precision are limited by template output limit
+	"printf": func(format string, args ...interface{}) (string, error) {
+		if formatErr := checkFormatSizes(format); formatErr != nil {
+			return "", formatErr
+		}
+		return fmt.Sprintf(format, args...), nil
+	},
+}
+
+// placesSize returns number of decimal places of money; it is limited by template output limit
+func placesSize(places int) int {
+	if places < 0 {
+		return 0
+	}
+	if places > maxTemplateOutputSize {
+		return maxTemplateOutputSize
+	}
+	return places
+}
+
+// checkFormatSizes rejects printf format with width or precision exceeding template output limit
+// and with width or precision taken from arguments
+func checkFormatSizes(format string) error {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		size, inIndex := 0, false
+		for i++; i < len(format) && strings.IndexByte("+-# 0123456789.[]*", format[i]) >= 0; i++ {
+			switch c := format[i]; {
+			case c == '*':
+				return fmt.Errorf("width and precision of printf can not be arguments")
+			case c == '[':
+				inIndex = true
+			case c == ']':
+				inIndex = false
+			case c >= '0' && c <= '9' && !inIndex:
+				if size = size*10 + int(c-'0'); size > maxTemplateOutputSize {
+					return fmt.Errorf("width and precision of printf exceed %d", maxTemplateOutputSize)
+				}
+				continue
+			}
+			size = 0
+		}
+	}
+	return nil
+}
+
+// paddingSize returns number of spaces up to the width; width is limited by template output limit
+func paddingSize(width int, s string) int {
+	if width > maxTemplateOutputSize {
+		width = maxTemplateOutputSize
+	}
+	if size := width - len([]rune(s)); size > 0 {
+		return size
+	}
+	return 0
+}
+
+// TemplateAmount is amount available in templates; it is printed as decimal string.
+// Decimal is not exposed to keep its methods out of templates.
+type TemplateAmount struct {
+	value decimal.Decimal
+}
+
+// String returns amount as decimal string
+func (ta TemplateAmount) String() string {
+	return ta.value.String()
+}
+
+// TemplateOperation is data of template's row section
+type TemplateOperation struct {
+	ID         int
+	Operation  string
+	WalletFrom int // zero when operation has no source wallet
+	WalletTo   int
+	Amount     TemplateAmount
+	CreatedAt  time.Time
+}
+
+// TemplateReport is data of template's header and footer sections; Rows and Total are zero in header
+type TemplateReport struct {
+	GeneratedAt time.Time
+	Rows        int
+	Total       TemplateAmount
+}
+
+// CompiledTemplate represents parsed sections of report template; empty sections are nil
+type CompiledTemplate struct {
+	Name   string
+	header *template.Template
+	row    *template.Template
+	footer *template.Template
+}
+
+// CompileTemplate parses sections of report template. Templates are sandboxed: they can use only
+// given data and helpers, can not define or include other templates, have no range loops and
+// output of each section is limited.
+func CompileTemplate(reportTemplate *entities.ReportTemplate) (*CompiledTemplate, error) {
+	if strings.TrimSpace(reportTemplate.Row) == "" {
+		return nil, fmt.Errorf("row section of template is required")
+	}
+	compiled := &CompiledTemplate{Name: reportTemplate.Name}
+	sections := []struct {
+		name   string
+		text   string
+		target **template.Template
+	}{
+		{"header", reportTemplate.Header, &compiled.header},
+		{"row", reportTemplate.Row, &compiled.row},
+		{"footer", reportTemplate.Footer, &compiled.footer},
+	}
+	for _, section := range sections {
+		if section.text == "" {
+			continue
+		}
+		if len(section.text) > MaxTemplateSize {
+			return nil, fmt.Errorf("%s section of template exceeds %d bytes", section.name, MaxTemplateSize)
+		}
+		parsed, parseErr := template.New(section.name).Funcs(templateFuncs).Parse(section.text)
+		if parseErr != nil {
+			return nil, fmt.Errorf("error of %s section parsing: %s", section.name, parseErr)
+		}
+		if len(parsed.Templates()) > 1 {
+			return nil, fmt.Errorf("error of %s section parsing: nested template definitions are not allowed", section.name)
+		}
+		if parsed.Tree != nil {
+			if nodeErr := checkTemplateNodes(parsed.Tree.Root); nodeErr != nil {
+				return nil, fmt.Errorf("error of %s section parsing: %s", section.name, nodeErr)
+			}
+		}
+		*section.target = parsed
+	}
+	return compiled, nil
+}
+
+// ValidateTemplate compiles report template and executes its sections with sample data,
+// so unknown fields and wrong helpers' arguments are rejected on upload
+func ValidateTemplate(reportTemplate *entities.ReportTemplate) error {
+	compiled, compileErr := CompileTemplate(reportTemplate)
+	if compileErr != nil {
+		return compileErr
+	}
+	amount := TemplateAmount{decimal.NewFromInt(10)}
+	report := &TemplateReport{GeneratedAt: time.Now().UTC(), Rows: 1, Total: amount}
+	operation := &TemplateOperation{ID: 1, Operation: "deposit", WalletTo: 1, Amount: amount, CreatedAt: report.GeneratedAt}
+	if _, execErr := executeTemplate(compiled.header, "header", report); execErr != nil {
+		return execErr
+	}
+	if _, execErr := executeTemplate(compiled.row, "row", operation); execErr != nil {
+		return execErr
+	}
+	_, execErr := executeTemplate(compiled.footer, "footer", report)
+	return execErr
+}
+
+// checkTemplateNodes rejects actions which are not allowed in report templates
+func checkTemplateNodes(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if childErr := checkTemplateNodes(child); childErr != nil {
+				return childErr
+			}
+		}
+	case *parse.IfNode:
+		return checkBranchNodes(&n.BranchNode)
+	case *parse.WithNode:
+		return checkBranchNodes(&n.BranchNode)
+	case *parse.RangeNode:
+		return fmt.Errorf("range action is not allowed")
+	case *parse.TemplateNode:
+		return fmt.Errorf("template action is not allowed")
+	}
+	return nil
+}
+
+func checkBranchNodes(branch *parse.BranchNode) error {
+	if listErr := checkTemplateNodes(branch.List); listErr != nil {
+		return listErr
+	}
+	return checkTemplateNodes(branch.ElseList)
+}
+
+// limitedBuffer collects template output up to the limit
+type limitedBuffer struct {
+	bytes.Buffer
+}
+
+func (lb *limitedBuffer) Write(p []byte) (int, error) {
+	if lb.Len()+len(p) > maxTemplateOutputSize {
+		return 0, errTemplateOutput
+	}
+	return lb.Buffer.Write(p)
+}
+
+// executeTemplate returns output of template's section; empty section has no output
+func executeTemplate(section *template.Template, name string, data interface{}) ([]byte, error) {
+	if section == nil {
+		return nil, nil
+	}
+	buffer := &limitedBuffer{}
+	if execErr := section.Execute(buffer, data); execErr != nil {
+		return nil, fmt.Errorf("error of %s section execution: %s", name, execErr)
+	}
+	return buffer.Bytes(), nil
+}
+
+// templateRecord is marshalled operation with its amount for footer's total
+type templateRecord struct {
+	data   []byte
+	amount decimal.Decimal
+}
+
+// TemplateHandler implements FileMarshallingManager interface for user-defined templates.
+// Header is written on creation, footer with number of rows and total amount is written on closing.
+type TemplateHandler struct {
+	file        io.Writer
+	mu          *sync.Mutex
+	template    *CompiledTemplate
+	generatedAt time.Time
+	rows        int
+	total       decimal.Decimal
+}
+
+// NewTemplateHandler writes template's header
+func NewTemplateHandler(w io.Writer, mu *sync.Mutex, compiled *CompiledTemplate, createdAt time.Time) (*TemplateHandler, error) {
+	th := &TemplateHandler{
+		file:        w,
+		mu:          mu,
+		template:    compiled,
+		generatedAt: createdAt.UTC(),
+	}
+	header, execErr := executeTemplate(compiled.header, "header", &TemplateReport{GeneratedAt: th.generatedAt})
+	if execErr != nil {
+		return nil, execErr
+	}
+	if _, writeErr := w.Write(header); writeErr != nil {
+		return nil, fmt.Errorf("error of template header writing: %s", writeErr)
+	}
+	return th, nil
+}
+
+// MarshallRow executes template's row section for wallet operation
+func (th *TemplateHandler) MarshallRow(row ReportRow) (*MarshalledResult, error) {
+	operation, rowErr := rowOperation(row, TemplateFormat)
+	if rowErr != nil {
+		return nil, rowErr
+	}
+	data := &TemplateOperation{
+		ID:        operation.ID,
+		Operation: operation.Operation,
+		WalletTo:  operation.WalletTo,
+		Amount:    TemplateAmount{operation.Amount},
+		CreatedAt: operation.CreatedAt,
+	}
+	if operation.WalletFrom.Valid {
+		data.WalletFrom = int(operation.WalletFrom.Int32)
+	}
+	record, execErr := executeTemplate(th.template.row, "row", data)
+	if execErr != nil {
+		return nil, execErr
+	}
+	return &MarshalledResult{
+		id:   operation.ID,
+		data: &templateRecord{data: record, amount: operation.Amount},
+	}, nil
+}
+
+// WriteToFile writes output of row section
+func (th *TemplateHandler) WriteToFile(mr *MarshalledResult) error {
+	record := mr.data.(*templateRecord)
+	th.mu.Lock()
+	defer th.mu.Unlock()
+	if _, writeErr := th.file.Write(record.data); writeErr != nil {
+		return fmt.Errorf("error of template writing: %s", writeErr)
+	}
+	th.rows++
+	th.total = th.total.Add(record.amount)
+	return nil
+}
+
+// Close writes template's footer
+func (th *TemplateHandler) Close() error {
+	th.mu.Lock()
+	defer th.mu.Unlock()
+	footer, execErr := executeTemplate(th.template.footer, "footer", &TemplateReport{
+		GeneratedAt: th.generatedAt,
+		Rows:        th.rows,
+		Total:       TemplateAmount{th.total},
+	})
+	if execErr != nil {
+		return execErr
+	}
+	if _, writeErr := th.file.Write(footer); writeErr != nil {
+		return fmt.Errorf("error of template footer writing: %s", writeErr)
+	}
+	return nil
+}
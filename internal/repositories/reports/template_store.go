@@ -0,0 +1,120 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/adapters/tx"
+	"billing_system_test_task/internal/entities"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrTemplateNotFound is returned when report template does not exist
+var ErrTemplateNotFound = errors.New("report template is not found")
+
+// TemplateManager represents storage of report templates
+type TemplateManager interface {
+	Save(ctx context.Context, reportTemplate *entities.ReportTemplate) (*entities.ReportTemplate, error)
+	Get(ctx context.Context, name string) (*entities.ReportTemplate, error)
+	List(ctx context.Context) ([]*entities.ReportTemplate, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// TemplateService implements TemplateManager interface
+type TemplateService struct {
+	db tx.SQLQueryAdapter
+}
+
+// NewTemplateService returns new instance of TemplateService
+func NewTemplateService(db tx.SQLQueryAdapter) *TemplateService {
+	return &TemplateService{
+		db: db,
+	}
+}
+
+const templateColumns = "name, header, row, footer, created_at, updated_at"
+
+// Save creates template or replaces sections of existing one
+func (ts TemplateService) Save(ctx context.Context, reportTemplate *entities.ReportTemplate) (*entities.ReportTemplate, error) {
+	row := ts.db.QueryRowContext(
+		ctx,
+		"insert into report_templates(name, header, row, footer) values($1, $2, $3, $4) "+
+			"on conflict (name) do update set header = excluded.header, row = excluded.row, "+
+			"footer = excluded.footer, updated_at = current_timestamp returning "+templateColumns,
+		reportTemplate.Name, reportTemplate.Header, reportTemplate.Row, reportTemplate.Footer,
+	)
+	saved, scanErr := scanTemplate(row)
+	if scanErr != nil {
+		return nil, fmt.Errorf("[TEMPLATE_SAVE]: %s", scanErr)
+	}
+	return saved, nil
+}
+
+// Get returns template by name
+func (ts TemplateService) Get(ctx context.Context, name string) (*entities.ReportTemplate, error) {
+	row := ts.db.QueryRowContext(ctx, "select "+templateColumns+" from report_templates where name = $1", name)
+	reportTemplate, scanErr := scanTemplate(row)
+	if scanErr == sql.ErrNoRows {
+		return nil, ErrTemplateNotFound
+	}
+	if scanErr != nil {
+		return nil, fmt.Errorf("[TEMPLATE]: %s", scanErr)
+	}
+	return reportTemplate, nil
+}
+
+// List returns all templates ordered by name
+func (ts TemplateService) List(ctx context.Context) ([]*entities.ReportTemplate, error) {
+	rows, queryErr := ts.db.QueryContext(ctx, "select "+templateColumns+" from report_templates order by name")
+	if queryErr != nil {
+		return nil, fmt.Errorf("[TEMPLATES_LIST]: %s", queryErr)
+	}
+	defer rows.Close()
+
+	templates := []*entities.ReportTemplate{}
+	for rows.Next() {
+		reportTemplate, scanErr := scanTemplate(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("[TEMPLATES_LIST_ROW]: %s", scanErr)
+		}
+		templates = append(templates, reportTemplate)
+	}
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, fmt.Errorf("[TEMPLATES_LIST]: %s", rowsErr)
+	}
+	return templates, nil
+}
+
+// Delete removes template
+func (ts TemplateService) Delete(ctx context.Context, name string) error {
+	result, deleteErr := ts.db.ExecContext(ctx, "delete from report_templates where name = $1", name)
+	if deleteErr != nil {
+		return fmt.Errorf("[TEMPLATE_DELETE]: %s", deleteErr)
+	}
+	if deleted, _ := result.RowsAffected(); deleted == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+// rowScanner is implemented by sql.Row and sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTemplate reads template from row of query with templateColumns
+func scanTemplate(row rowScanner) (*entities.ReportTemplate, error) {
+	reportTemplate := entities.ReportTemplate{}
+	scanErr := row.Scan(
+		&reportTemplate.Name,
+		&reportTemplate.Header,
+		&reportTemplate.Row,
+		&reportTemplate.Footer,
+		&reportTemplate.CreatedAt,
+		&reportTemplate.UpdatedAt,
+	)
+	if scanErr != nil {
+		return nil, scanErr
+	}
+	return &reportTemplate, nil
+}
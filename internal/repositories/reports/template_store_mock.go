@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repositories/reports/template_store.go
+
+// Package reports is a generated GoMock package.
+package reports
+
+import (
+	entities "billing_system_test_task/internal/entities"
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockTemplateManager is a mock of TemplateManager interface
+type MockTemplateManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockTemplateManagerMockRecorder
+}
+
+// MockTemplateManagerMockRecorder is the mock recorder for MockTemplateManager
+type MockTemplateManagerMockRecorder struct {
+	mock *MockTemplateManager
+}
+
+// NewMockTemplateManager creates a new mock instance
+func NewMockTemplateManager(ctrl *gomock.Controller) *MockTemplateManager {
+	mock := &MockTemplateManager{ctrl: ctrl}
+	mock.recorder = &MockTemplateManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTemplateManager) EXPECT() *MockTemplateManagerMockRecorder {
+	return m.recorder
+}
+
+// Save mocks base method
+func (m *MockTemplateManager) Save(ctx context.Context, reportTemplate *entities.ReportTemplate) (*entities.ReportTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, reportTemplate)
+	ret0, _ := ret[0].(*entities.ReportTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save
+func (mr *MockTemplateManagerMockRecorder) Save(ctx, reportTemplate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockTemplateManager)(nil).Save), ctx, reportTemplate)
+}
+
+// Get mocks base method
+func (m *MockTemplateManager) Get(ctx context.Context, name string) (*entities.ReportTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, name)
+	ret0, _ := ret[0].(*entities.ReportTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockTemplateManagerMockRecorder) Get(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTemplateManager)(nil).Get), ctx, name)
+}
+
+// List mocks base method
+func (m *MockTemplateManager) List(ctx context.Context) ([]*entities.ReportTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]*entities.ReportTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockTemplateManagerMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTemplateManager)(nil).List), ctx)
+}
+
+// Delete mocks base method
+func (m *MockTemplateManager) Delete(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockTemplateManagerMockRecorder) Delete(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTemplateManager)(nil).Delete), ctx, name)
+}
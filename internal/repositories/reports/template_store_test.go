@@ -0,0 +1,88 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/entities"
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var templateRowColumns = []string{"name", "header", "row", "footer", "created_at", "updated_at"}
+
+// Test saving and reading of report templates
+func TestTemplateService(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2022, time.October, 25, 12, 0, 0, 0, time.UTC)
+	service := NewTemplateService(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("insert into report_templates(name, header, row, footer) values($1, $2, $3, $4) on conflict (name) do update")).
+		WithArgs("partner", "id\n", "{{.ID}}\n", "").
+		WillReturnRows(sqlmock.NewRows(templateRowColumns).AddRow("partner", "id\n", "{{.ID}}\n", "", now, now))
+	saved, saveErr := service.Save(ctx, &entities.ReportTemplate{Name: "partner", Header: "id\n", Row: "{{.ID}}\n"})
+	if saveErr != nil || saved.Name != "partner" || !saved.UpdatedAt.Equal(now) {
+		t.Errorf("Wrong saved template: %+v (%v)", saved, saveErr)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("from report_templates where name = $1")).
+		WithArgs("partner").
+		WillReturnRows(sqlmock.NewRows(templateRowColumns).AddRow("partner", "id\n", "{{.ID}}\n", "", now, now))
+	if found, getErr := service.Get(ctx, "partner"); getErr != nil || found.Row != "{{.ID}}\n" {
+		t.Errorf("Wrong template: %+v (%v)", found, getErr)
+	}
+
+	mock.ExpectQuery("from report_templates where name").WillReturnRows(sqlmock.NewRows(templateRowColumns))
+	if _, getErr := service.Get(ctx, "unknown"); getErr != ErrTemplateNotFound {
+		t.Errorf("Expected not found error, got %v", getErr)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("from report_templates order by name")).
+		WillReturnRows(sqlmock.NewRows(templateRowColumns).
+			AddRow("a", "", "{{.ID}}", "", now, now).
+			AddRow("b", "", "{{.ID}}", "", now, now))
+	if templates, listErr := service.List(ctx); listErr != nil || len(templates) != 2 || templates[1].Name != "b" {
+		t.Errorf("Wrong templates list: %v (%v)", templates, listErr)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta("delete from report_templates where name = $1")).WithArgs("partner").WillReturnResult(sqlmock.NewResult(0, 1))
+	if deleteErr := service.Delete(ctx, "partner"); deleteErr != nil {
+		t.Errorf("Unexpected error: %s", deleteErr)
+	}
+	mock.ExpectExec("delete from report_templates").WillReturnResult(sqlmock.NewResult(0, 0))
+	if deleteErr := service.Delete(ctx, "partner"); deleteErr != ErrTemplateNotFound {
+		t.Errorf("Expected not found error, got %v", deleteErr)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+// Test errors of templates storage
+func TestFailedTemplateService(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	ctx := context.Background()
+	service := NewTemplateService(db)
+
+	mock.ExpectQuery("insert into report_templates").WillReturnError(fmt.Errorf("insert error"))
+	if _, err := service.Save(ctx, &entities.ReportTemplate{Name: "partner"}); err == nil || err.Error() != "[TEMPLATE_SAVE]: insert error" {
+		t.Errorf("Expected save error, got %v", err)
+	}
+	mock.ExpectQuery("from report_templates order by name").WillReturnError(fmt.Errorf("query error"))
+	if _, err := service.List(ctx); err == nil || err.Error() != "[TEMPLATES_LIST]: query error" {
+		t.Errorf("Expected list error, got %v", err)
+	}
+	mock.ExpectExec("delete from report_templates").WillReturnError(fmt.Errorf("delete error"))
+	if err := service.Delete(ctx, "partner"); err == nil || err.Error() != "[TEMPLATE_DELETE]: delete error" {
+		t.Errorf("Expected delete error, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package reports
+
+import (
+	"billing_system_test_task/internal/entities"
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Test report with header, rows and footer of user-defined template
+func TestTemplateHandler(t *testing.T) {
+	compiled, compileErr := CompileTemplate(&entities.ReportTemplate{
+		Name:   "partner",
+		Header: "WALLET OPERATIONS {{.GeneratedAt | date \"date\"}}\n",
+		Row: "{{.ID | printf \"%04d\"}}|{{.Operation | upper | pad 12}}|{{if .WalletFrom}}{{.WalletFrom}}{{else}}-{{end}}|" +
+			"{{if eq .Operation \"withdrawal\"}}{{.Amount | neg | money 2}}{{else}}{{.Amount | money 2 | replace \".\" \",\"}}{{end}}|" +
+			"{{.CreatedAt | date \"02.01.2006\"}}\n",
+		Footer: "TOTAL {{.Rows}} {{.Total | money 2}}\n",
+	})
+	if compileErr != nil {
+		t.Fatalf("unexpected error: %s", compileErr)
+	}
+
+	buf := &bytes.Buffer{}
+	handler, handlerErr := NewTemplateHandler(buf, &sync.Mutex{}, compiled, time.Date(2021, time.April, 1, 8, 0, 0, 0, time.UTC))
+	if handlerErr != nil {
+		t.Fatalf("unexpected error: %s", handlerErr)
+	}
+	for _, operation := range camtOperations[1:3] {
+		mr, marshallErr := handler.MarshallRow(OperationRow{operation})
+		if marshallErr != nil {
+			t.Fatalf("unexpected error: %s", marshallErr)
+		}
+		if writeErr := handler.WriteToFile(mr); writeErr != nil {
+			t.Fatalf("unexpected error: %s", writeErr)
+		}
+	}
+	if closeErr := handler.Close(); closeErr != nil {
+		t.Fatalf("unexpected error: %s", closeErr)
+	}
+
+	expected := "WALLET OPERATIONS 2021-04-01\n" +
+		"0002|DEPOSIT     |-|30,50|02.03.2021\n" +
+		"0003|WITHDRAWAL  |2|-10.00|03.03.2021\n" +
+		"TOTAL 2 40.50\n"
+	if buf.String() != expected {
+		t.Errorf("Wrong report.\nExpected:\n%s\nGot:\n%s", expected, buf.String())
+	}
+
+	if _, marshallErr := handler.MarshallRow(SummaryRow{&entities.OperationSummary{}}); marshallErr == nil {
+		t.Error("Expected error of summary, got nil")
+	}
+}
+
+// Test validation of templates' sections and sandbox restrictions
+func TestValidateTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template entities.ReportTemplate
+		err      string
+	}{
+		{name: "Row only", template: entities.ReportTemplate{Row: "{{.ID}},{{.Operation | csv}}\n"}},
+		{name: "Empty row", template: entities.ReportTemplate{Header: "id\n", Row: " "}, err: "row section of template is required"},
+		{name: "Syntax error", template: entities.ReportTemplate{Row: "{{.ID"}, err: "error of row section parsing"},
+		{name: "Unknown helper", template: entities.ReportTemplate{Row: "{{exec .ID}}"}, err: "function \"exec\" not defined"},
+		{name: "Unknown field", template: entities.ReportTemplate{Row: "{{.Balance}}"}, err: "error of row section execution"},
+		{name: "Unknown footer field", template: entities.ReportTemplate{Row: "{{.ID}}", Footer: "{{.ID}}"}, err: "error of footer section execution"},
+		{name: "Decimal methods", template: entities.ReportTemplate{Row: "{{.Amount.Shift 1000000}}"}, err: "error of row section execution"},
+		{name: "Range action", template: entities.ReportTemplate{Row: "{{range .ID}}x{{end}}"}, err: "range action is not allowed"},
+		{name: "Nested range", template: entities.ReportTemplate{Row: "{{if .ID}}{{else}}{{with .ID}}{{range .}}{{end}}{{end}}{{end}}"}, err: "range action is not allowed"},
+		{name: "Template definition", template: entities.ReportTemplate{Row: "{{define \"x\"}}{{template \"x\"}}{{end}}"}, err: "nested template definitions are not allowed"},
+		{name: "Template action", template: entities.ReportTemplate{Row: "{{template \"row\" .}}"}, err: "template action is not allowed"},
+		{name: "Too large section", template: entities.ReportTemplate{Row: strings.Repeat("x", MaxTemplateSize+1)}, err: "row section of template exceeds 16384 bytes"},
+		{name: "Too large output", template: entities.ReportTemplate{Row: "{{pad 70000 \"\"}}x"}, err: errTemplateOutput.Error()},
+		{name: "Too many decimal places", template: entities.ReportTemplate{Row: "{{.Amount | money 2000000000}}"}, err: errTemplateOutput.Error()},
+		{name: "Negative decimal places", template: entities.ReportTemplate{Row: "{{.Amount | money -2000000000}}"}},
+		{name: "Printf", template: entities.ReportTemplate{Row: "{{printf \"%08.2f|%-6s|%[1]v%%\" 1.5 .Operation}}"}},
+		{name: "Too large printf width", template: entities.ReportTemplate{Row: "{{printf \"%999999999d\" 1}}"}, err: "width and precision of printf exceed 65536"},
+		{name: "Too large printf precision", template: entities.ReportTemplate{Row: "{{printf \"%.99999999999999999999f\" 1.5}}"}, err: "width and precision of printf exceed 65536"},
+		{name: "Printf width argument", template: entities.ReportTemplate{Row: "{{printf \"%*d\" 999999999 1}}"}, err: "width and precision of printf can not be arguments"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateTemplate(&tc.template)
+			if tc.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("Expected error '%s', got %v", tc.err, err)
+			}
+		})
+	}
+}
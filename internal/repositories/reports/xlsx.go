@@ -0,0 +1,246 @@
+package reports
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Styles defined in xlsxStyles (indexes of cellXfs)
+const (
+	xlsxStyleDefault = iota
+	xlsxStyleHeader
+	xlsxStyleDateTime
+	xlsxStyleAmount
+	xlsxStyleDate
+)
+
+// XLSXColumn represents column of the worksheet
+type XLSXColumn struct {
+	Name  string
+	Width float64
+}
+
+// XLSXWriter streams rows into single-sheet Office Open XML workbook.
+// Rows are written directly to the zip entry, so memory usage does not depend on their number.
+type XLSXWriter struct {
+	zw    *zip.Writer
+	sheet io.Writer
+	rows  int
+}
+
+// NewXLSXWriter writes workbook parts and worksheet's header with given columns
+func NewXLSXWriter(w io.Writer, sheetName string, columns []XLSXColumn) (*XLSXWriter, error) {
+	zw := zip.NewWriter(w)
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, xmlEscape(sheetName))},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, part := range parts {
+		pw, createErr := zw.Create(part.name)
+		if createErr != nil {
+			return nil, fmt.Errorf("error of xlsx part creation: %s", createErr)
+		}
+		if _, writeErr := io.WriteString(pw, part.content); writeErr != nil {
+			return nil, fmt.Errorf("error of xlsx part writing: %s", writeErr)
+		}
+	}
+
+	sheet, sheetErr := zw.Create("xl/worksheets/sheet1.xml")
+	if sheetErr != nil {
+		return nil, fmt.Errorf("error of xlsx sheet creation: %s", sheetErr)
+	}
+
+	header := &bytes.Buffer{}
+	header.WriteString(xlsxSheetStart)
+	header.WriteString("<cols>")
+	for idx, column := range columns {
+		fmt.Fprintf(header, `<col min="%d" max="%d" width="%s" customWidth="1"/>`, idx+1, idx+1, strconv.FormatFloat(column.Width, 'f', -1, 64))
+	}
+	header.WriteString("</cols><sheetData>")
+	if _, writeErr := sheet.Write(header.Bytes()); writeErr != nil {
+		return nil, fmt.Errorf("error of xlsx sheet writing: %s", writeErr)
+	}
+
+	xw := &XLSXWriter{
+		zw:    zw,
+		sheet: sheet,
+	}
+	headerRow := XLSXRow{}
+	for _, column := range columns {
+		headerRow.String(column.Name, xlsxStyleHeader)
+	}
+	if writeErr := xw.WriteRow(headerRow); writeErr != nil {
+		return nil, writeErr
+	}
+	return xw, nil
+}
+
+// WriteRow appends row to the worksheet
+func (xw *XLSXWriter) WriteRow(row XLSXRow) error {
+	xw.rows++
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, `<row r="%d">`, xw.rows)
+	for idx, cell := range row.cells {
+		if cell.kind == xlsxCellEmpty {
+			continue
+		}
+		ref := columnName(idx) + strconv.Itoa(xw.rows)
+		switch cell.kind {
+		case xlsxCellString:
+			fmt.Fprintf(buf, `<c r="%s" t="inlineStr"%s><is><t>%s</t></is></c>`, ref, styleAttr(cell.style), xmlEscape(cell.value))
+		case xlsxCellNumber:
+			fmt.Fprintf(buf, `<c r="%s"%s><v>%s</v></c>`, ref, styleAttr(cell.style), cell.value)
+		}
+	}
+	buf.WriteString("</row>")
+	if _, err := xw.sheet.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("error of xlsx row writing: %s", err)
+	}
+	return nil
+}
+
+// Close finishes worksheet and writes zip's central directory
+func (xw *XLSXWriter) Close() error {
+	if _, err := io.WriteString(xw.sheet, "</sheetData></worksheet>"); err != nil {
+		return fmt.Errorf("error of xlsx sheet closing: %s", err)
+	}
+	if err := xw.zw.Close(); err != nil {
+		return fmt.Errorf("error of xlsx archive closing: %s", err)
+	}
+	return nil
+}
+
+const (
+	xlsxCellEmpty = iota
+	xlsxCellString
+	xlsxCellNumber
+)
+
+type xlsxCell struct {
+	kind  int
+	style int
+	value string
+}
+
+// XLSXRow represents typed cells of the single row
+type XLSXRow struct {
+	cells []xlsxCell
+}
+
+// String appends string cell
+func (r *XLSXRow) String(value string, style int) {
+	r.cells = append(r.cells, xlsxCell{kind: xlsxCellString, style: style, value: value})
+}
+
+// Number appends numeric cell; value should be valid decimal number
+func (r *XLSXRow) Number(value string, style int) {
+	r.cells = append(r.cells, xlsxCell{kind: xlsxCellNumber, style: style, value: value})
+}
+
+// Date appends date cell, stored as Excel serial number
+func (r *XLSXRow) Date(value time.Time) {
+	r.Number(strconv.FormatFloat(excelSerialDate(value), 'f', -1, 64), xlsxStyleDateTime)
+}
+
+// Day appends date cell without time part
+func (r *XLSXRow) Day(value time.Time) {
+	r.Number(strconv.FormatFloat(excelSerialDate(value), 'f', -1, 64), xlsxStyleDate)
+}
+
+// Empty appends empty cell
+func (r *XLSXRow) Empty() {
+	r.cells = append(r.cells, xlsxCell{kind: xlsxCellEmpty})
+}
+
+// columnName converts zero-based column index to its letters (0 -> A, 26 -> AA)
+func columnName(idx int) string {
+	name := ""
+	for idx >= 0 {
+		name = string(rune('A'+idx%26)) + name
+		idx = idx/26 - 1
+	}
+	return name
+}
+
+// excelSerialDate converts time to number of days since 1899-12-30 (wall clock time is used)
+func excelSerialDate(t time.Time) float64 {
+	epoch := time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	seconds := wall.Sub(epoch).Truncate(time.Millisecond).Seconds()
+	return seconds / (24 * 60 * 60)
+}
+
+func styleAttr(style int) string {
+	if style == xlsxStyleDefault {
+		return ""
+	}
+	return fmt.Sprintf(` s="%d"`, style)
+}
+
+func xmlEscape(value string) string {
+	buf := &bytes.Buffer{}
+	_ = xml.EscapeText(buf, []byte(value))
+	return buf.String()
+}
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>` +
+	`</workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="2"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/><numFmt numFmtId="165" formatCode="yyyy-mm-dd"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="5">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
+
+// Header of the worksheet with frozen first row
+const xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheetViews><sheetView workbookViewId="0">` +
+	`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>` +
+	`<selection pane="bottomLeft" activeCell="A2" sqref="A2"/>` +
+	`</sheetView></sheetViews>` +
+	`<sheetFormatPr defaultRowHeight="15"/>`
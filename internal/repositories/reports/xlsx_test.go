@@ -0,0 +1,134 @@
+package reports
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readXLSXPart returns content of the workbook's part
+func readXLSXPart(t *testing.T, data []byte, name string) string {
+	zr, zipErr := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if zipErr != nil {
+		t.Fatalf("Unexpected zip error: %s", zipErr)
+	}
+	for _, f := range zr.File {
+		if f.Name == name {
+			rc, openErr := f.Open()
+			if openErr != nil {
+				t.Fatalf("Unexpected part open error: %s", openErr)
+			}
+			defer rc.Close()
+			content, _ := ioutil.ReadAll(rc)
+			return string(content)
+		}
+	}
+	t.Fatalf("Part %s is not found", name)
+	return ""
+}
+
+// Test success workbook writing
+func TestXLSXWriterSuccess(t *testing.T) {
+	buf := &bytes.Buffer{}
+	columns := []XLSXColumn{
+		{Name: "id", Width: 10},
+		{Name: "name", Width: 20.5},
+		{Name: "optional", Width: 8},
+		{Name: "created_at", Width: 20},
+	}
+	xw, err := NewXLSXWriter(buf, "Sheet <1>", columns)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	row := XLSXRow{}
+	row.Number("1", xlsxStyleDefault)
+	row.String("a & b", xlsxStyleDefault)
+	row.Empty()
+	row.Date(time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC))
+	if writeErr := xw.WriteRow(row); writeErr != nil {
+		t.Fatalf("Unexpected error: %s", writeErr)
+	}
+	if closeErr := xw.Close(); closeErr != nil {
+		t.Fatalf("Unexpected error: %s", closeErr)
+	}
+
+	for _, part := range []string{"[Content_Types].xml", "_rels/.rels", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		readXLSXPart(t, buf.Bytes(), part)
+	}
+	workbook := readXLSXPart(t, buf.Bytes(), "xl/workbook.xml")
+	if !strings.Contains(workbook, `name="Sheet &lt;1&gt;"`) {
+		t.Errorf("Sheet name is not escaped: %s", workbook)
+	}
+
+	sheet := readXLSXPart(t, buf.Bytes(), "xl/worksheets/sheet1.xml")
+	expectedFragments := []string{
+		`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>`,
+		`<col min="2" max="2" width="20.5" customWidth="1"/>`,
+		`<row r="1"><c r="A1" t="inlineStr" s="1"><is><t>id</t></is></c>`,
+		`<row r="2"><c r="A2"><v>1</v></c><c r="B2" t="inlineStr"><is><t>a &amp; b</t></is></c><c r="D2" s="2"><v>44256.5</v></c></row>`,
+		`</sheetData></worksheet>`,
+	}
+	for _, fragment := range expectedFragments {
+		if !strings.Contains(sheet, fragment) {
+			t.Errorf("Sheet does not contain '%s'", fragment)
+		}
+	}
+}
+
+// Test failed workbook writing (write error is returned not later than on closing)
+func TestXLSXWriterFailedWrite(t *testing.T) {
+	xw, err := NewXLSXWriter(ErrorFile{}, "Sheet", []XLSXColumn{{Name: "id", Width: 10}})
+	if err != nil {
+		return
+	}
+	if closeErr := xw.Close(); closeErr == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+// Test conversion of column index to its name
+func TestXLSXColumnName(t *testing.T) {
+	cases := map[int]string{0: "A", 5: "F", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"}
+	for idx, expected := range cases {
+		if actual := columnName(idx); actual != expected {
+			t.Errorf("Wrong column name for %d. Expected %s, got %s", idx, expected, actual)
+		}
+	}
+}
+
+// Test conversion of time to Excel serial date
+func TestExcelSerialDate(t *testing.T) {
+	cases := []struct {
+		value    time.Time
+		expected float64
+	}{
+		{time.Date(1900, time.March, 1, 0, 0, 0, 0, time.UTC), 61},
+		{time.Date(2021, time.March, 1, 18, 0, 0, 0, time.UTC), 44256.75},
+		{time.Date(2021, time.March, 1, 18, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60)), 44256.75},
+	}
+	for _, c := range cases {
+		if actual := excelSerialDate(c.value); actual != c.expected {
+			t.Errorf("Wrong serial date for %s. Expected %f, got %f", c.value, c.expected, actual)
+		}
+	}
+}
+
+// Benchmark xlsx rows writing
+func BenchmarkXLSXWriteRow(b *testing.B) {
+	xw, _ := NewXLSXWriter(ioutil.Discard, "Sheet", operationXLSXColumns)
+	row := XLSXRow{}
+	row.Number("1", xlsxStyleDefault)
+	row.String("deposit", xlsxStyleDefault)
+	row.Empty()
+	row.Number("2", xlsxStyleDefault)
+	row.Number("100.25", xlsxStyleAmount)
+	row.Date(time.Now())
+	for i := 0; i < b.N; i++ {
+		_ = xw.WriteRow(row)
+	}
+	_ = xw.Close()
+}
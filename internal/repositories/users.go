@@ -39,7 +39,7 @@ func (us *UsersService) WithTx(t tx.Tx) UsersManager {
 func (ds UsersService) GetByID(ctx context.Context, userID int) (*entities.User, error) {
 	user := entities.User{}
 	query := `
-		select u.id, u.email, w.id, w.user_id, w.balance, w.currency 
+		select u.id, u.email, w.id, w.user_id, w.balance, w.currency, w.frozen 
 		from users as u 
 		join wallets as w 
 		on u.id = w.user_id 
@@ -59,6 +59,7 @@ func (ds UsersService) GetByID(ctx context.Context, userID int) (*entities.User,
 			&wallet.UserID,
 			&wallet.Balance,
 			&wallet.Currency,
+			&wallet.Frozen,
 		)
 		if scanErr != nil {
 			return nil, fmt.Errorf("GetByID: Error of reading the result: %s", scanErr)
@@ -73,7 +74,7 @@ func (ds UsersService) GetByID(ctx context.Context, userID int) (*entities.User,
 func (ds UsersService) GetByWalletID(ctx context.Context, walletID int) (*entities.User, error) {
 	user := entities.User{}
 	query := `
-		select u.id, u.email, w.id, w.user_id, w.balance, w.currency 
+		select u.id, u.email, w.id, w.user_id, w.balance, w.currency, w.frozen 
 		from users as u
 		join wallets as w
 		on u.id = w.user_id
@@ -93,6 +94,7 @@ func (ds UsersService) GetByWalletID(ctx context.Context, walletID int) (*entiti
 			&wallet.UserID,
 			&wallet.Balance,
 			&wallet.Currency,
+			&wallet.Frozen,
 		)
 		if scanErr != nil {
 			return nil, fmt.Errorf("GetByWalletID: Error of reading the result: %s", scanErr)
@@ -79,9 +79,9 @@ var UserRepoTestCases = []userRepoTestCase{
 		funcName: "GetByID",
 		args:     []driver.Value{1},
 		mockQuery: func(mock sqlmock.Sqlmock) {
-			query := "select u.id, u.email, w.id, w.user_id, w.balance, w.currency  from users as u"
-			rows := sqlmock.NewRows([]string{"id", "email", "wallets.id", "user_id", "balance", "currency"})
-			rows = rows.AddRow(1, "test@example.com", 1, 1, decimal.NewFromInt(100), "USD")
+			query := "select u.id, u.email, w.id, w.user_id, w.balance, w.currency, w.frozen  from users as u"
+			rows := sqlmock.NewRows([]string{"id", "email", "wallets.id", "user_id", "balance", "currency", "frozen"})
+			rows = rows.AddRow(1, "test@example.com", 1, 1, decimal.NewFromInt(100), "USD", false)
 			mock.
 				ExpectQuery(query).
 				WithArgs([]driver.Value{1}...).
@@ -112,7 +112,7 @@ var UserRepoTestCases = []userRepoTestCase{
 		args:     []driver.Value{1},
 		mockQuery: func(mock sqlmock.Sqlmock) {
 			query := `
-				select u.id, u.email, w.id, w.user_id, w.balance, w.currency
+				select u.id, u.email, w.id, w.user_id, w.balance, w.currency, w.frozen
 			`
 
 			mock.
@@ -128,10 +128,10 @@ var UserRepoTestCases = []userRepoTestCase{
 		args:     []driver.Value{1},
 		mockQuery: func(mock sqlmock.Sqlmock) {
 			query := `
-				select u.id, u.email, w.id, w.user_id, w.balance, w.currency
+				select u.id, u.email, w.id, w.user_id, w.balance, w.currency, w.frozen
 			`
-			rows := sqlmock.NewRows([]string{"id", "email", "wallets.id", "user_id", "balance", "currency"}).
-				AddRow(nil, "test@example.com", nil, 1, decimal.NewFromInt(100), "USD").
+			rows := sqlmock.NewRows([]string{"id", "email", "wallets.id", "user_id", "balance", "currency", "frozen"}).
+				AddRow(nil, "test@example.com", nil, 1, decimal.NewFromInt(100), "USD", false).
 				RowError(1, fmt.Errorf("Scan error"))
 			mock.
 				ExpectQuery(query).
@@ -145,10 +145,10 @@ var UserRepoTestCases = []userRepoTestCase{
 		funcName: "GetByWalletID",
 		args:     []driver.Value{1},
 		mockQuery: func(mock sqlmock.Sqlmock) {
-			rows := sqlmock.NewRows([]string{"id", "email", "wallets.id", "user_id", "balance", "currency"})
-			rows = rows.AddRow(1, "test@example.com", 1, 1, decimal.NewFromInt(100), "USD")
+			rows := sqlmock.NewRows([]string{"id", "email", "wallets.id", "user_id", "balance", "currency", "frozen"})
+			rows = rows.AddRow(1, "test@example.com", 1, 1, decimal.NewFromInt(100), "USD", false)
 			mock.
-				ExpectQuery("select u.id, u.email, w.id, w.user_id, w.balance, w.currency from users as u").
+				ExpectQuery("select u.id, u.email, w.id, w.user_id, w.balance, w.currency, w.frozen from users as u").
 				WithArgs([]driver.Value{1}...).
 				WillReturnRows(rows)
 		},
@@ -176,7 +176,7 @@ var UserRepoTestCases = []userRepoTestCase{
 		args:     []driver.Value{1},
 		mockQuery: func(mock sqlmock.Sqlmock) {
 			mock.
-				ExpectQuery("select u.id, u.email, w.id, w.user_id, w.balance, w.currency from users as u").
+				ExpectQuery("select u.id, u.email, w.id, w.user_id, w.balance, w.currency, w.frozen from users as u").
 				WithArgs([]driver.Value{1}...).
 				WillReturnError(fmt.Errorf("Error of user retrieving"))
 		},
@@ -188,10 +188,10 @@ var UserRepoTestCases = []userRepoTestCase{
 		args:     []driver.Value{1},
 		mockQuery: func(mock sqlmock.Sqlmock) {
 			query := `
-				select u.id, u.email, w.id, w.user_id, w.balance, w.currency
+				select u.id, u.email, w.id, w.user_id, w.balance, w.currency, w.frozen
 			`
-			rows := sqlmock.NewRows([]string{"id", "email", "wallets.id", "user_id", "balance", "currency"}).
-				AddRow(nil, "test@example.com", nil, 1, decimal.NewFromInt(100), "USD").
+			rows := sqlmock.NewRows([]string{"id", "email", "wallets.id", "user_id", "balance", "currency", "frozen"}).
+				AddRow(nil, "test@example.com", nil, 1, decimal.NewFromInt(100), "USD", false).
 				RowError(1, fmt.Errorf("Scan error"))
 			mock.
 				ExpectQuery(query).
@@ -302,9 +302,9 @@ func BenchmarkGetById(b *testing.B) {
 	defer sqlDB.Close()
 	ctx := context.Background()
 
-	query := "select u.id, u.email, w.id, w.user_id, w.balance, w.currency  from users as u"
-	rows := sqlmock.NewRows([]string{"id", "email", "wallets.id", "user_id", "balance", "currency"})
-	rows = rows.AddRow(1, "test@example.com", 1, 1, decimal.NewFromInt(100), "USD")
+	query := "select u.id, u.email, w.id, w.user_id, w.balance, w.currency, w.frozen  from users as u"
+	rows := sqlmock.NewRows([]string{"id", "email", "wallets.id", "user_id", "balance", "currency", "frozen"})
+	rows = rows.AddRow(1, "test@example.com", 1, 1, decimal.NewFromInt(100), "USD", false)
 	mock.
 		ExpectQuery(query).
 		WithArgs([]driver.Value{1}...).
@@ -329,10 +329,10 @@ func BenchmarkGetByWalletID(b *testing.B) {
 	defer sqlDB.Close()
 	ctx := context.Background()
 
-	rows := sqlmock.NewRows([]string{"id", "email", "wallets.id", "user_id", "balance", "currency"})
-	rows = rows.AddRow(1, "test@example.com", 1, 1, decimal.NewFromInt(100), "USD")
+	rows := sqlmock.NewRows([]string{"id", "email", "wallets.id", "user_id", "balance", "currency", "frozen"})
+	rows = rows.AddRow(1, "test@example.com", 1, 1, decimal.NewFromInt(100), "USD", false)
 	mock.
-		ExpectQuery("select u.id, u.email, w.id, w.user_id, w.balance, w.currency from users as u").
+		ExpectQuery("select u.id, u.email, w.id, w.user_id, w.balance, w.currency, w.frozen from users as u").
 		WithArgs([]driver.Value{1}...).
 		WillReturnRows(rows)
 
@@ -23,6 +23,8 @@ type WalletsManager interface {
 	GetByUserId(ctx context.Context, userID int) (*entities.Wallet, error)
 	GetByID(ctx context.Context, walletID int) (*entities.Wallet, error)
 	Transfer(ctx context.Context, walletFrom, walletTo int, amount decimal.Decimal) (int, error)
+	SetFrozen(ctx context.Context, walletID int, frozen bool) error
+	Reconcile(ctx context.Context) ([]*entities.BalanceDiscrepancy, error)
 }
 
 // WalletService shows structure for service of wallets
@@ -89,8 +91,8 @@ func (ws WalletService) Enroll(ctx context.Context, walletID int, amount decimal
 func (ws WalletService) GetByID(ctx context.Context, walletID int) (*entities.Wallet, error) {
 	wallet := entities.Wallet{}
 	getWalletErr := ws.db.
-		QueryRowContext(ctx, "select id, user_id, balance, currency from wallets where id=$1", walletID).
-		Scan(&wallet.ID, &wallet.UserID, &wallet.Balance, &wallet.Currency)
+		QueryRowContext(ctx, "select id, user_id, balance, currency, frozen from wallets where id=$1", walletID).
+		Scan(&wallet.ID, &wallet.UserID, &wallet.Balance, &wallet.Currency, &wallet.Frozen)
 	if getWalletErr != nil {
 		return nil, getWalletErr
 	}
@@ -101,8 +103,8 @@ func (ws WalletService) GetByID(ctx context.Context, walletID int) (*entities.Wa
 func (ws WalletService) GetByUserId(ctx context.Context, userID int) (*entities.Wallet, error) {
 	wallet := entities.Wallet{}
 	getWalletErr := ws.db.
-		QueryRowContext(ctx, "select id, user_id, balance, currency from wallets where user_id=$1", userID).
-		Scan(&wallet.ID, &wallet.UserID, &wallet.Balance, &wallet.Currency)
+		QueryRowContext(ctx, "select id, user_id, balance, currency, frozen from wallets where user_id=$1", userID).
+		Scan(&wallet.ID, &wallet.UserID, &wallet.Balance, &wallet.Currency, &wallet.Frozen)
 	if getWalletErr != nil {
 		return nil, getWalletErr
 	}
@@ -125,3 +127,48 @@ func (ws WalletService) Transfer(ctx context.Context, walletFrom, walletTo int,
 
 	return walletFrom, nil
 }
+
+// SetFrozen freezes or unfreezes wallet
+func (ws WalletService) SetFrozen(ctx context.Context, walletID int, frozen bool) error {
+	result, updateErr := ws.db.ExecContext(ctx, "update wallets set frozen=$1 where id=$2", frozen, walletID)
+	if updateErr != nil {
+		return fmt.Errorf("[WALLET_SET_FROZEN]: %s", updateErr)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("[WALLET_SET_FROZEN]: wallet %d does not exist", walletID)
+	}
+	return nil
+}
+
+// Ledger balance of the wallet is restored from its operations: deposit credits it, withdrawal debits it
+const reconcileQuery = `select id, balance, ledger from (
+	select w.id, w.balance,
+		coalesce(sum(o.amount) filter (where o.operation = $1), 0) - coalesce(sum(o.amount) filter (where o.operation = $2), 0) as ledger
+	from wallets w
+	left join wallet_operations o on o.wallet_to = w.id
+	group by w.id, w.balance
+) as balances
+where balance <> ledger
+order by id`
+
+// Reconcile returns wallets whose balances differ from their ledger balances
+func (ws WalletService) Reconcile(ctx context.Context) ([]*entities.BalanceDiscrepancy, error) {
+	rows, queryErr := ws.db.QueryContext(ctx, reconcileQuery, Deposit, Withdrawal)
+	if queryErr != nil {
+		return nil, fmt.Errorf("[WALLETS_RECONCILE]: %s", queryErr)
+	}
+	defer rows.Close()
+
+	discrepancies := []*entities.BalanceDiscrepancy{}
+	for rows.Next() {
+		discrepancy := entities.BalanceDiscrepancy{}
+		if scanErr := rows.Scan(&discrepancy.WalletID, &discrepancy.Balance, &discrepancy.Ledger); scanErr != nil {
+			return nil, fmt.Errorf("[WALLETS_RECONCILE_ROW]: %s", scanErr)
+		}
+		discrepancies = append(discrepancies, &discrepancy)
+	}
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, fmt.Errorf("[WALLETS_RECONCILE]: %s", rowsErr)
+	}
+	return discrepancies, nil
+}
@@ -124,3 +124,32 @@ func (mr *MockWalletsManagerMockRecorder) Transfer(ctx, walletFrom, walletTo, am
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockWalletsManager)(nil).Transfer), ctx, walletFrom, walletTo, amount)
 }
+
+// SetFrozen mocks base method
+func (m *MockWalletsManager) SetFrozen(ctx context.Context, walletID int, frozen bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFrozen", ctx, walletID, frozen)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetFrozen indicates an expected call of SetFrozen
+func (mr *MockWalletsManagerMockRecorder) SetFrozen(ctx, walletID, frozen interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFrozen", reflect.TypeOf((*MockWalletsManager)(nil).SetFrozen), ctx, walletID, frozen)
+}
+
+// Reconcile mocks base method
+func (m *MockWalletsManager) Reconcile(ctx context.Context) ([]*entities.BalanceDiscrepancy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconcile", ctx)
+	ret0, _ := ret[0].([]*entities.BalanceDiscrepancy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reconcile indicates an expected call of Reconcile
+func (mr *MockWalletsManagerMockRecorder) Reconcile(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockWalletsManager)(nil).Reconcile), ctx)
+}
@@ -7,6 +7,7 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -143,14 +144,14 @@ var WalletsRepoTestCase = []walletRepoTestCase{
 		name:     "Success wallet retrieving by user id",
 		funcName: "GetByUserId",
 		queryMock: sqlQueryMock{
-			query: "select id, user_id, balance, currency from wallets",
+			query: "select id, user_id, balance, currency, frozen from wallets",
 			args:  []driver.Value{1},
 		},
 		mockQuery: func(mock sqlmock.Sqlmock) {
-			rows := sqlmock.NewRows([]string{"id", "user_id", "balance", "currency"})
-			rows = rows.AddRow(1, 1, 100, "USD")
+			rows := sqlmock.NewRows([]string{"id", "user_id", "balance", "currency", "frozen"})
+			rows = rows.AddRow(1, 1, 100, "USD", false)
 			mock.
-				ExpectQuery("select id, user_id, balance, currency from wallets").
+				ExpectQuery("select id, user_id, balance, currency, frozen from wallets").
 				WithArgs([]driver.Value{1}...).
 				WillReturnRows(rows)
 		},
@@ -163,12 +164,12 @@ var WalletsRepoTestCase = []walletRepoTestCase{
 		name:     "failed wallet retrieving by user id",
 		funcName: "GetByUserId",
 		queryMock: sqlQueryMock{
-			query: "select id, user_id, balance, currency from wallets",
+			query: "select id, user_id, balance, currency, frozen from wallets",
 			args:  []driver.Value{1},
 		},
 		mockQuery: func(mock sqlmock.Sqlmock) {
 			mock.
-				ExpectQuery("select id, user_id, balance, currency from wallets").
+				ExpectQuery("select id, user_id, balance, currency, frozen from wallets").
 				WithArgs([]driver.Value{1}...).
 				WillReturnError(fmt.Errorf("Wallet retrieving error"))
 		},
@@ -208,10 +209,10 @@ var WalletsRepoTestCase = []walletRepoTestCase{
 		},
 		mockQuery: func(mock sqlmock.Sqlmock) {
 			// Select source wallet
-			rows := sqlmock.NewRows([]string{"id", "user_id", "balance", "currency"})
-			rows = rows.AddRow(1, 1, 0, "USD")
+			rows := sqlmock.NewRows([]string{"id", "user_id", "balance", "currency", "frozen"})
+			rows = rows.AddRow(1, 1, 0, "USD", false)
 			mock.
-				ExpectQuery("select id, user_id, balance, currency from wallets").
+				ExpectQuery("select id, user_id, balance, currency, frozen from wallets").
 				WithArgs([]driver.Value{1}...).
 				WillReturnRows(rows)
 		},
@@ -227,7 +228,7 @@ var WalletsRepoTestCase = []walletRepoTestCase{
 		mockQuery: func(mock sqlmock.Sqlmock) {
 			// Select source wallet error
 			mock.
-				ExpectQuery("select id, user_id, balance, currency from wallets").
+				ExpectQuery("select id, user_id, balance, currency, frozen from wallets").
 				WithArgs([]driver.Value{1}...).
 				WillReturnError(fmt.Errorf("error of receiving source wallet"))
 		},
@@ -299,12 +300,12 @@ var WalletsRepoTestCase = []walletRepoTestCase{
 		name:     "Success wallet retrieving by id",
 		funcName: "GetByID",
 		queryMock: sqlQueryMock{
-			query: "select id, user_id, balance, currency from wallets",
+			query: "select id, user_id, balance, currency, frozen from wallets",
 			args:  []driver.Value{1},
 		},
 		mockQuery: func(mock sqlmock.Sqlmock) {
 			mock.
-				ExpectQuery("select id, user_id, balance, currency from wallets").
+				ExpectQuery("select id, user_id, balance, currency, frozen from wallets").
 				WithArgs([]driver.Value{1}...).
 				WillReturnError(fmt.Errorf("Wallet retrieving error"))
 		},
@@ -314,14 +315,14 @@ var WalletsRepoTestCase = []walletRepoTestCase{
 		name:     "Failed wallet retrieving by id (get error)",
 		funcName: "GetByID",
 		queryMock: sqlQueryMock{
-			query: "select id, user_id, balance, currency from wallets",
+			query: "select id, user_id, balance, currency, frozen from wallets",
 			args:  []driver.Value{1},
 		},
 		mockQuery: func(mock sqlmock.Sqlmock) {
-			rows := sqlmock.NewRows([]string{"id", "user_id", "balance", "currency"})
-			rows = rows.AddRow(1, 1, 100, "USD")
+			rows := sqlmock.NewRows([]string{"id", "user_id", "balance", "currency", "frozen"})
+			rows = rows.AddRow(1, 1, 100, "USD", false)
 			mock.
-				ExpectQuery("select id, user_id, balance, currency from wallets").
+				ExpectQuery("select id, user_id, balance, currency, frozen from wallets").
 				WithArgs([]driver.Value{1}...).
 				WillReturnRows(rows)
 		},
@@ -418,6 +419,49 @@ func TestWithTransactionWalletService(t *testing.T) {
 	}
 }
 
+// Test freezing of wallets
+func TestWalletRepoSetFrozen(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	ctx := context.Background()
+	service := NewWalletService(db)
+
+	mock.ExpectExec(regexp.QuoteMeta("update wallets set frozen=$1 where id=$2")).WithArgs(true, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := service.SetFrozen(ctx, 1, true); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	mock.ExpectExec(regexp.QuoteMeta("update wallets set frozen=$1 where id=$2")).WithArgs(false, 5).WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := service.SetFrozen(ctx, 5, false); err == nil || err.Error() != "[WALLET_SET_FROZEN]: wallet 5 does not exist" {
+		t.Errorf("Expected error of missing wallet, got %v", err)
+	}
+
+	if expectationsErr := mock.ExpectationsWereMet(); expectationsErr != nil {
+		t.Errorf("Unfulfilled expectations: %s", expectationsErr)
+	}
+}
+
+// Test wallets with balances different from operations are returned
+func TestWalletRepoReconcile(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	service := NewWalletService(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("select id, balance, ledger from")).
+		WithArgs(Deposit, Withdrawal).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "ledger"}).AddRow(3, "110.00", "100.00"))
+	discrepancies, err := service.Reconcile(context.Background())
+	if err != nil || len(discrepancies) != 1 {
+		t.Fatalf("Wrong discrepancies: %v (%v)", discrepancies, err)
+	}
+	if discrepancies[0].WalletID != 3 || !discrepancies[0].Balance.Equal(decimal.NewFromInt(110)) || !discrepancies[0].Ledger.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("Wrong discrepancy: %+v", discrepancies[0])
+	}
+
+	if expectationsErr := mock.ExpectationsWereMet(); expectationsErr != nil {
+		t.Errorf("Unfulfilled expectations: %s", expectationsErr)
+	}
+}
+
 // Tests repository Create action
 func BenchmarkCreateWallet(b *testing.B) {
 	sqlDB, mock, err := sqlmock.New()
@@ -474,13 +518,13 @@ func BenchmarkGetByUserID(b *testing.B) {
 	defer sqlDB.Close()
 	ctx := context.Background()
 
-	rows := sqlmock.NewRows([]string{"id", "user_id", "balance", "currency"})
-	rows = rows.AddRow(1, 1, 100, "USD")
+	rows := sqlmock.NewRows([]string{"id", "user_id", "balance", "currency", "frozen"})
+	rows = rows.AddRow(1, 1, 100, "USD", false)
 
 	repo := NewWalletService(sqlDB)
 
 	mock.
-		ExpectQuery("select id, user_id, balance, currency from wallets").
+		ExpectQuery("select id, user_id, balance, currency, frozen from wallets").
 		WithArgs([]driver.Value{1}...).
 		WillReturnRows(rows)
 
@@ -498,14 +542,14 @@ func BenchmarkGetByID(b *testing.B) {
 	defer sqlDB.Close()
 	ctx := context.Background()
 
-	rows := sqlmock.NewRows([]string{"id", "user_id", "balance", "currency"})
-	rows = rows.AddRow(1, 1, 100, "USD")
+	rows := sqlmock.NewRows([]string{"id", "user_id", "balance", "currency", "frozen"})
+	rows = rows.AddRow(1, 1, 100, "USD", false)
 
 	// walletOperation := NewWalletOperationRepo(sqlDB)
 	repo := NewWalletService(sqlDB)
 
 	mock.
-		ExpectQuery("select id, user_id, balance, currency from wallets").
+		ExpectQuery("select id, user_id, balance, currency, frozen from wallets").
 		WithArgs([]driver.Value{1}...).
 		WillReturnRows(rows)
 
@@ -526,10 +570,10 @@ func BenchmarkTransfer(b *testing.B) {
 	repo := NewWalletService(sqlDB)
 
 	// Select source wallet
-	rows := sqlmock.NewRows([]string{"id", "user_id", "balance", "currency"})
-	rows = rows.AddRow(1, 1, 100, "USD")
+	rows := sqlmock.NewRows([]string{"id", "user_id", "balance", "currency", "frozen"})
+	rows = rows.AddRow(1, 1, 100, "USD", false)
 	mock.
-		ExpectQuery("select id, user_id, balance, currency from wallets").
+		ExpectQuery("select id, user_id, balance, currency, frozen from wallets").
 		WithArgs([]driver.Value{1}...).
 		WillReturnRows(rows)
 
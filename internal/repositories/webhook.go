@@ -0,0 +1,277 @@
+package repositories
+
+import (
+	"billing_system_test_task/internal/adapters/tx"
+	"billing_system_test_task/internal/entities"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrWebhookNotFound is returned when subscription or delivery does not exist
+var ErrWebhookNotFound = errors.New("webhook is not found")
+
+// deliveryLease postpones claimed deliveries, so they are retried when worker stops during delivery
+const deliveryLease = "interval '5 minutes'"
+
+// WebhooksManager represents storage of webhook subscriptions, events and deliveries
+type WebhooksManager interface {
+	WithTx(t tx.Tx) WebhooksManager
+	CreateSubscription(ctx context.Context, subscription *entities.WebhookSubscription) (*entities.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]*entities.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int) error
+	CreateEvent(ctx context.Context, outboxID int64, eventType string, payload []byte) (*entities.WebhookEvent, error)
+	ClaimDueDeliveries(ctx context.Context, limit int) ([]*entities.WebhookDelivery, error)
+	RecordAttempt(ctx context.Context, attempt *entities.WebhookAttempt, status string, retryIn time.Duration) error
+	ListAttempts(ctx context.Context, subscriptionID, limit int) ([]*entities.WebhookAttempt, error)
+	Redeliver(ctx context.Context, deliveryID int) error
+}
+
+// WebhookService implements WebhooksManager interface
+type WebhookService struct {
+	db tx.SQLQueryAdapter
+}
+
+// NewWebhookService returns webhooks repository
+func NewWebhookService(db tx.SQLQueryAdapter) *WebhookService {
+	return &WebhookService{
+		db: db,
+	}
+}
+
+// WithTx returns repository which works in transaction
+func (ws *WebhookService) WithTx(t tx.Tx) WebhooksManager {
+	return NewWebhookService(t.(tx.SQLQueryAdapter))
+}
+
+const subscriptionColumns = "id, url, event_types, secret, active, created_at"
+
+// CreateSubscription stores new subscription
+func (ws *WebhookService) CreateSubscription(ctx context.Context, subscription *entities.WebhookSubscription) (*entities.WebhookSubscription, error) {
+	row := ws.db.QueryRowContext(
+		ctx,
+		"insert into webhook_subscriptions(url, event_types, secret) values($1, $2, $3) returning "+subscriptionColumns,
+		subscription.URL, pq.Array(subscription.EventTypes), subscription.Secret,
+	)
+	created, scanErr := scanSubscription(row)
+	if scanErr != nil {
+		return nil, fmt.Errorf("[WEBHOOK_CREATE]: %s", scanErr)
+	}
+	return created, nil
+}
+
+// ListSubscriptions returns active subscriptions
+func (ws *WebhookService) ListSubscriptions(ctx context.Context) ([]*entities.WebhookSubscription, error) {
+	rows, queryErr := ws.db.QueryContext(ctx, "select "+subscriptionColumns+" from webhook_subscriptions where active order by id")
+	if queryErr != nil {
+		return nil, fmt.Errorf("[WEBHOOKS_LIST]: %s", queryErr)
+	}
+	defer rows.Close()
+
+	subscriptions := []*entities.WebhookSubscription{}
+	for rows.Next() {
+		subscription, scanErr := scanSubscription(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("[WEBHOOKS_LIST_ROW]: %s", scanErr)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, fmt.Errorf("[WEBHOOKS_LIST]: %s", rowsErr)
+	}
+	return subscriptions, nil
+}
+
+// DeleteSubscription deactivates subscription and cancels its pending deliveries; delivery log is kept
+func (ws *WebhookService) DeleteSubscription(ctx context.Context, id int) error {
+	result, updateErr := ws.db.ExecContext(
+		ctx,
+		"with cancelled as ("+
+			"update webhook_deliveries set status = $2, updated_at = current_timestamp where subscription_id = $1 and status = $3"+
+			") update webhook_subscriptions set active = false where id = $1 and active",
+		id, entities.DeliveryFailed, entities.DeliveryPending,
+	)
+	if updateErr != nil {
+		return fmt.Errorf("[WEBHOOK_DELETE]: %s", updateErr)
+	}
+	if deleted, _ := result.RowsAffected(); deleted == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// CreateEvent stores event of outbox and schedules its deliveries to subscriptions of event's type;
+// nil event is returned when the event of outbox is already stored
+func (ws *WebhookService) CreateEvent(ctx context.Context, outboxID int64, eventType string, payload []byte) (*entities.WebhookEvent, error) {
+	event := entities.WebhookEvent{Type: eventType, Payload: payload}
+	scanErr := ws.db.QueryRowContext(
+		ctx,
+		"with event as (insert into webhook_events(outbox_id, type, payload) values($1, $2, $3) "+
+			"on conflict (outbox_id) do nothing returning id, created_at), "+
+			"deliveries as (insert into webhook_deliveries(subscription_id, event_id) "+
+			"select s.id, event.id from webhook_subscriptions s, event where s.active and $2 = any(s.event_types)) "+
+			"select id, created_at from event",
+		outboxID, eventType, string(payload),
+	).Scan(&event.ID, &event.CreatedAt)
+	if scanErr == sql.ErrNoRows {
+		return nil, nil
+	}
+	if scanErr != nil {
+		return nil, fmt.Errorf("[WEBHOOK_EVENT_CREATE]: %s", scanErr)
+	}
+	return &event, nil
+}
+
+// ClaimDueDeliveries returns pending deliveries which are due and postpones them by the lease,
+// so concurrent workers do not send the same delivery
+func (ws *WebhookService) ClaimDueDeliveries(ctx context.Context, limit int) ([]*entities.WebhookDelivery, error) {
+	rows, queryErr := ws.db.QueryContext(
+		ctx,
+		"with claimed as ("+
+			"update webhook_deliveries set next_attempt_at = current_timestamp + "+deliveryLease+" where id in ("+
+			"select id from webhook_deliveries where status = $1 and next_attempt_at <= current_timestamp "+
+			"order by next_attempt_at limit $2 for update skip locked"+
+			") returning id, subscription_id, event_id, status, attempts"+
+			") select claimed.id, claimed.status, claimed.attempts, s.id, s.url, s.secret, e.id, e.type, e.payload, e.created_at "+
+			"from claimed join webhook_subscriptions s on s.id = claimed.subscription_id "+
+			"join webhook_events e on e.id = claimed.event_id order by claimed.id",
+		entities.DeliveryPending, limit,
+	)
+	if queryErr != nil {
+		return nil, fmt.Errorf("[WEBHOOK_DELIVERIES_CLAIM]: %s", queryErr)
+	}
+	defer rows.Close()
+
+	deliveries := []*entities.WebhookDelivery{}
+	for rows.Next() {
+		var (
+			delivery entities.WebhookDelivery
+			payload  []byte
+		)
+		scanErr := rows.Scan(
+			&delivery.ID,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.Subscription.ID,
+			&delivery.Subscription.URL,
+			&delivery.Subscription.Secret,
+			&delivery.Event.ID,
+			&delivery.Event.Type,
+			&payload,
+			&delivery.Event.CreatedAt,
+		)
+		if scanErr != nil {
+			return nil, fmt.Errorf("[WEBHOOK_DELIVERIES_CLAIM_ROW]: %s", scanErr)
+		}
+		delivery.Event.Payload = payload
+		deliveries = append(deliveries, &delivery)
+	}
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, fmt.Errorf("[WEBHOOK_DELIVERIES_CLAIM]: %s", rowsErr)
+	}
+	return deliveries, nil
+}
+
+// RecordAttempt appends attempt to delivery log and updates state of the delivery;
+// pending delivery is retried after the delay by database clock
+func (ws *WebhookService) RecordAttempt(ctx context.Context, attempt *entities.WebhookAttempt, status string, retryIn time.Duration) error {
+	_, execErr := ws.db.ExecContext(
+		ctx,
+		"with attempt as ("+
+			"insert into webhook_attempts(delivery_id, attempt, status_code, error, duration_ms) values($1, $2, $3, $4, $5)"+
+			") update webhook_deliveries set attempts = $2, status = $6, next_attempt_at = current_timestamp + make_interval(secs => $7), updated_at = current_timestamp where id = $1",
+		attempt.DeliveryID, attempt.Attempt, attempt.StatusCode, attempt.Error, attempt.Duration.Milliseconds(),
+		status, retryIn.Seconds(),
+	)
+	if execErr != nil {
+		return fmt.Errorf("[WEBHOOK_ATTEMPT]: %s", execErr)
+	}
+	return nil
+}
+
+// ListAttempts returns the latest delivery attempts of subscription
+func (ws *WebhookService) ListAttempts(ctx context.Context, subscriptionID, limit int) ([]*entities.WebhookAttempt, error) {
+	rows, queryErr := ws.db.QueryContext(
+		ctx,
+		"select a.id, a.delivery_id, e.id, e.type, a.attempt, a.status_code, a.error, a.duration_ms, a.created_at "+
+			"from webhook_attempts a join webhook_deliveries d on d.id = a.delivery_id "+
+			"join webhook_events e on e.id = d.event_id "+
+			"where d.subscription_id = $1 order by a.id desc limit $2",
+		subscriptionID, limit,
+	)
+	if queryErr != nil {
+		return nil, fmt.Errorf("[WEBHOOK_ATTEMPTS]: %s", queryErr)
+	}
+	defer rows.Close()
+
+	attempts := []*entities.WebhookAttempt{}
+	for rows.Next() {
+		var (
+			attempt    entities.WebhookAttempt
+			durationMs int64
+		)
+		scanErr := rows.Scan(
+			&attempt.ID,
+			&attempt.DeliveryID,
+			&attempt.EventID,
+			&attempt.EventType,
+			&attempt.Attempt,
+			&attempt.StatusCode,
+			&attempt.Error,
+			&durationMs,
+			&attempt.CreatedAt,
+		)
+		if scanErr != nil {
+			return nil, fmt.Errorf("[WEBHOOK_ATTEMPTS_ROW]: %s", scanErr)
+		}
+		attempt.Duration = time.Duration(durationMs) * time.Millisecond
+		attempts = append(attempts, &attempt)
+	}
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, fmt.Errorf("[WEBHOOK_ATTEMPTS]: %s", rowsErr)
+	}
+	return attempts, nil
+}
+
+// Redeliver schedules delivery of active subscription to be sent now with new attempts' budget
+func (ws *WebhookService) Redeliver(ctx context.Context, deliveryID int) error {
+	result, updateErr := ws.db.ExecContext(
+		ctx,
+		"update webhook_deliveries d set status = $2, attempts = 0, next_attempt_at = current_timestamp, updated_at = current_timestamp "+
+			"from webhook_subscriptions s where d.id = $1 and s.id = d.subscription_id and s.active",
+		deliveryID, entities.DeliveryPending,
+	)
+	if updateErr != nil {
+		return fmt.Errorf("[WEBHOOK_REDELIVER]: %s", updateErr)
+	}
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// rowScanner is implemented by sql.Row and sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanSubscription reads subscription from row of query with subscriptionColumns
+func scanSubscription(row rowScanner) (*entities.WebhookSubscription, error) {
+	subscription := entities.WebhookSubscription{}
+	scanErr := row.Scan(
+		&subscription.ID,
+		&subscription.URL,
+		pq.Array(&subscription.EventTypes),
+		&subscription.Secret,
+		&subscription.Active,
+		&subscription.CreatedAt,
+	)
+	if scanErr != nil {
+		return nil, scanErr
+	}
+	return &subscription, nil
+}
@@ -0,0 +1,209 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repositories/webhook.go
+
+// Package repositories is a generated GoMock package.
+package repositories
+
+import (
+	tx "billing_system_test_task/internal/adapters/tx"
+	entities "billing_system_test_task/internal/entities"
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+	time "time"
+)
+
+// MockWebhooksManager is a mock of WebhooksManager interface
+type MockWebhooksManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhooksManagerMockRecorder
+}
+
+// MockWebhooksManagerMockRecorder is the mock recorder for MockWebhooksManager
+type MockWebhooksManagerMockRecorder struct {
+	mock *MockWebhooksManager
+}
+
+// NewMockWebhooksManager creates a new mock instance
+func NewMockWebhooksManager(ctrl *gomock.Controller) *MockWebhooksManager {
+	mock := &MockWebhooksManager{ctrl: ctrl}
+	mock.recorder = &MockWebhooksManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWebhooksManager) EXPECT() *MockWebhooksManagerMockRecorder {
+	return m.recorder
+}
+
+// WithTx mocks base method
+func (m *MockWebhooksManager) WithTx(t tx.Tx) WebhooksManager {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", t)
+	ret0, _ := ret[0].(WebhooksManager)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx
+func (mr *MockWebhooksManagerMockRecorder) WithTx(t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockWebhooksManager)(nil).WithTx), t)
+}
+
+// CreateSubscription mocks base method
+func (m *MockWebhooksManager) CreateSubscription(ctx context.Context, subscription *entities.WebhookSubscription) (*entities.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubscription", ctx, subscription)
+	ret0, _ := ret[0].(*entities.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSubscription indicates an expected call of CreateSubscription
+func (mr *MockWebhooksManagerMockRecorder) CreateSubscription(ctx, subscription interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscription", reflect.TypeOf((*MockWebhooksManager)(nil).CreateSubscription), ctx, subscription)
+}
+
+// ListSubscriptions mocks base method
+func (m *MockWebhooksManager) ListSubscriptions(ctx context.Context) ([]*entities.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubscriptions", ctx)
+	ret0, _ := ret[0].([]*entities.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubscriptions indicates an expected call of ListSubscriptions
+func (mr *MockWebhooksManagerMockRecorder) ListSubscriptions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscriptions", reflect.TypeOf((*MockWebhooksManager)(nil).ListSubscriptions), ctx)
+}
+
+// DeleteSubscription mocks base method
+func (m *MockWebhooksManager) DeleteSubscription(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSubscription", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSubscription indicates an expected call of DeleteSubscription
+func (mr *MockWebhooksManagerMockRecorder) DeleteSubscription(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubscription", reflect.TypeOf((*MockWebhooksManager)(nil).DeleteSubscription), ctx, id)
+}
+
+// CreateEvent mocks base method
+func (m *MockWebhooksManager) CreateEvent(ctx context.Context, outboxID int64, eventType string, payload []byte) (*entities.WebhookEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEvent", ctx, outboxID, eventType, payload)
+	ret0, _ := ret[0].(*entities.WebhookEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEvent indicates an expected call of CreateEvent
+func (mr *MockWebhooksManagerMockRecorder) CreateEvent(ctx, outboxID, eventType, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEvent", reflect.TypeOf((*MockWebhooksManager)(nil).CreateEvent), ctx, outboxID, eventType, payload)
+}
+
+// ClaimDueDeliveries mocks base method
+func (m *MockWebhooksManager) ClaimDueDeliveries(ctx context.Context, limit int) ([]*entities.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueDeliveries", ctx, limit)
+	ret0, _ := ret[0].([]*entities.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueDeliveries indicates an expected call of ClaimDueDeliveries
+func (mr *MockWebhooksManagerMockRecorder) ClaimDueDeliveries(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueDeliveries", reflect.TypeOf((*MockWebhooksManager)(nil).ClaimDueDeliveries), ctx, limit)
+}
+
+// RecordAttempt mocks base method
+func (m *MockWebhooksManager) RecordAttempt(ctx context.Context, attempt *entities.WebhookAttempt, status string, retryIn time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAttempt", ctx, attempt, status, retryIn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordAttempt indicates an expected call of RecordAttempt
+func (mr *MockWebhooksManagerMockRecorder) RecordAttempt(ctx, attempt, status, retryIn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAttempt", reflect.TypeOf((*MockWebhooksManager)(nil).RecordAttempt), ctx, attempt, status, retryIn)
+}
+
+// ListAttempts mocks base method
+func (m *MockWebhooksManager) ListAttempts(ctx context.Context, subscriptionID, limit int) ([]*entities.WebhookAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAttempts", ctx, subscriptionID, limit)
+	ret0, _ := ret[0].([]*entities.WebhookAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAttempts indicates an expected call of ListAttempts
+func (mr *MockWebhooksManagerMockRecorder) ListAttempts(ctx, subscriptionID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAttempts", reflect.TypeOf((*MockWebhooksManager)(nil).ListAttempts), ctx, subscriptionID, limit)
+}
+
+// Redeliver mocks base method
+func (m *MockWebhooksManager) Redeliver(ctx context.Context, deliveryID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeliver", ctx, deliveryID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Redeliver indicates an expected call of Redeliver
+func (mr *MockWebhooksManagerMockRecorder) Redeliver(ctx, deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeliver", reflect.TypeOf((*MockWebhooksManager)(nil).Redeliver), ctx, deliveryID)
+}
+
+// MockrowScanner is a mock of rowScanner interface
+type MockrowScanner struct {
+	ctrl     *gomock.Controller
+	recorder *MockrowScannerMockRecorder
+}
+
+// MockrowScannerMockRecorder is the mock recorder for MockrowScanner
+type MockrowScannerMockRecorder struct {
+	mock *MockrowScanner
+}
+
+// NewMockrowScanner creates a new mock instance
+func NewMockrowScanner(ctrl *gomock.Controller) *MockrowScanner {
+	mock := &MockrowScanner{ctrl: ctrl}
+	mock.recorder = &MockrowScannerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockrowScanner) EXPECT() *MockrowScannerMockRecorder {
+	return m.recorder
+}
+
+// Scan mocks base method
+func (m *MockrowScanner) Scan(dest ...interface{}) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range dest {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Scan", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Scan indicates an expected call of Scan
+func (mr *MockrowScannerMockRecorder) Scan(dest ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scan", reflect.TypeOf((*MockrowScanner)(nil).Scan), dest...)
+}
@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"billing_system_test_task/internal/entities"
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+var subscriptionRowColumns = []string{"id", "url", "event_types", "secret", "active", "created_at"}
+
+// Test storing of webhook subscriptions
+func TestWebhookServiceSubscriptions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2022, time.November, 22, 12, 0, 0, 0, time.UTC)
+	service := NewWebhookService(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("insert into webhook_subscriptions(url, event_types, secret) values($1, $2, $3)")).
+		WithArgs("https://example.com/hook", pq.Array([]string{entities.EventUserCreated}), "secret").
+		WillReturnRows(sqlmock.NewRows(subscriptionRowColumns).AddRow(1, "https://example.com/hook", "{user.created}", "secret", true, now))
+	created, createErr := service.CreateSubscription(ctx, &entities.WebhookSubscription{
+		URL: "https://example.com/hook", EventTypes: []string{entities.EventUserCreated}, Secret: "secret",
+	})
+	if createErr != nil || created.ID != 1 || len(created.EventTypes) != 1 || created.EventTypes[0] != entities.EventUserCreated || !created.Active {
+		t.Errorf("Wrong subscription: %+v (%v)", created, createErr)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("from webhook_subscriptions where active order by id")).
+		WillReturnRows(sqlmock.NewRows(subscriptionRowColumns).
+			AddRow(1, "https://example.com/hook", "{user.created,transfer.completed}", "secret", true, now))
+	if subscriptions, listErr := service.ListSubscriptions(ctx); listErr != nil || len(subscriptions) != 1 || len(subscriptions[0].EventTypes) != 2 {
+		t.Errorf("Wrong subscriptions: %v (%v)", subscriptions, listErr)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta("update webhook_subscriptions set active = false where id = $1 and active")).
+		WithArgs(1, entities.DeliveryFailed, entities.DeliveryPending).WillReturnResult(sqlmock.NewResult(0, 1))
+	if deleteErr := service.DeleteSubscription(ctx, 1); deleteErr != nil {
+		t.Errorf("Unexpected error: %s", deleteErr)
+	}
+	mock.ExpectExec("update webhook_subscriptions").WillReturnResult(sqlmock.NewResult(0, 0))
+	if deleteErr := service.DeleteSubscription(ctx, 1); deleteErr != ErrWebhookNotFound {
+		t.Errorf("Expected not found error, got %v", deleteErr)
+	}
+
+	if expectationsErr := mock.ExpectationsWereMet(); expectationsErr != nil {
+		t.Errorf("Unfulfilled expectations: %s", expectationsErr)
+	}
+}
+
+// Test events, deliveries and delivery log
+func TestWebhookServiceDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2022, time.November, 22, 12, 0, 0, 0, time.UTC)
+	service := NewWebhookService(db)
+
+	createEventQuery := regexp.QuoteMeta("insert into webhook_events(outbox_id, type, payload) values($1, $2, $3) on conflict (outbox_id) do nothing")
+	mock.ExpectQuery(createEventQuery).
+		WithArgs(int64(9), entities.EventUserCreated, `{"user_id":1}`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(5, now))
+	event, eventErr := service.CreateEvent(ctx, 9, entities.EventUserCreated, []byte(`{"user_id":1}`))
+	if eventErr != nil || event.ID != 5 || !event.CreatedAt.Equal(now) {
+		t.Errorf("Wrong event: %+v (%v)", event, eventErr)
+	}
+	// Repeated outbox event is not stored again
+	mock.ExpectQuery(createEventQuery).
+		WithArgs(int64(9), entities.EventUserCreated, `{"user_id":1}`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}))
+	if event, eventErr = service.CreateEvent(ctx, 9, entities.EventUserCreated, []byte(`{"user_id":1}`)); eventErr != nil || event != nil {
+		t.Errorf("Expected no event, got %+v (%v)", event, eventErr)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("update webhook_deliveries set next_attempt_at = current_timestamp + interval '5 minutes'")).
+		WithArgs(entities.DeliveryPending, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "attempts", "s_id", "url", "secret", "e_id", "type", "payload", "created_at"}).
+			AddRow(3, "pending", 1, 1, "https://example.com/hook", "secret", 5, entities.EventUserCreated, []byte(`{"user_id":1}`), now))
+	deliveries, claimErr := service.ClaimDueDeliveries(ctx, 10)
+	if claimErr != nil || len(deliveries) != 1 || deliveries[0].Attempts != 1 || deliveries[0].Subscription.URL != "https://example.com/hook" || string(deliveries[0].Event.Payload) != `{"user_id":1}` {
+		t.Errorf("Wrong deliveries: %v (%v)", deliveries, claimErr)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta("insert into webhook_attempts(delivery_id, attempt, status_code, error, duration_ms)")).
+		WithArgs(3, 2, 500, "unexpected status 500", int64(120), entities.DeliveryPending, 60.0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	recordErr := service.RecordAttempt(ctx, &entities.WebhookAttempt{
+		DeliveryID: 3, Attempt: 2, StatusCode: 500, Error: "unexpected status 500", Duration: 120 * time.Millisecond,
+	}, entities.DeliveryPending, time.Minute)
+	if recordErr != nil {
+		t.Errorf("Unexpected error: %s", recordErr)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("from webhook_attempts a join webhook_deliveries d on d.id = a.delivery_id")).
+		WithArgs(1, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "delivery_id", "event_id", "type", "attempt", "status_code", "error", "duration_ms", "created_at"}).
+			AddRow(7, 3, 5, entities.EventUserCreated, 2, 500, "unexpected status 500", 120, now))
+	attempts, attemptsErr := service.ListAttempts(ctx, 1, 100)
+	if attemptsErr != nil || len(attempts) != 1 || attempts[0].Duration != 120*time.Millisecond || attempts[0].EventType != entities.EventUserCreated {
+		t.Errorf("Wrong attempts: %v (%v)", attempts, attemptsErr)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta("update webhook_deliveries d set status = $2, attempts = 0")).
+		WithArgs(3, entities.DeliveryPending).WillReturnResult(sqlmock.NewResult(0, 1))
+	if redeliverErr := service.Redeliver(ctx, 3); redeliverErr != nil {
+		t.Errorf("Unexpected error: %s", redeliverErr)
+	}
+	mock.ExpectExec("update webhook_deliveries d").WillReturnResult(sqlmock.NewResult(0, 0))
+	if redeliverErr := service.Redeliver(ctx, 4); redeliverErr != ErrWebhookNotFound {
+		t.Errorf("Expected not found error, got %v", redeliverErr)
+	}
+
+	if expectationsErr := mock.ExpectationsWereMet(); expectationsErr != nil {
+		t.Errorf("Unfulfilled expectations: %s", expectationsErr)
+	}
+}
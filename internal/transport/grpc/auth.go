@@ -0,0 +1,115 @@
+package grpc
+
+import (
+	"billing_system_test_task/internal/auth"
+	"billing_system_test_task/internal/entities"
+	billingv1 "billing_system_test_task/internal/transport/grpc/billing/v1"
+	"billing_system_test_task/internal/usecases"
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// APIKeyMetadata is alternative to authorization metadata for servers' API keys
+const APIKeyMetadata = "x-api-key"
+
+// methodPermissions maps methods of BillingService to required permissions; methods missing here are denied
+var methodPermissions = map[string]string{
+	billingv1.BillingService_CreateUser_FullMethodName:     auth.PermUsersCreate,
+	billingv1.BillingService_GetUser_FullMethodName:        auth.PermWalletsRead,
+	billingv1.BillingService_Enroll_FullMethodName:         auth.PermWalletsEnroll,
+	billingv1.BillingService_Transfer_FullMethodName:       auth.PermWalletsTransfer,
+	billingv1.BillingService_GetWallet_FullMethodName:      auth.PermWalletsRead,
+	billingv1.BillingService_ListOperations_FullMethodName: auth.PermOperationsRead,
+}
+
+// AuthInterceptor authenticates calls like the HTTP API does and checks permissions of methods
+type AuthInterceptor struct {
+	authUseCase   usecases.AuthUsecase
+	accessUseCase usecases.AccessUsecase
+}
+
+// NewAuthInterceptor returns interceptor instance
+func NewAuthInterceptor(authUseCase usecases.AuthUsecase, accessUseCase usecases.AccessUsecase) *AuthInterceptor {
+	return &AuthInterceptor{
+		authUseCase:   authUseCase,
+		accessUseCase: accessUseCase,
+	}
+}
+
+// Unary authorizes unary calls
+func (ai *AuthInterceptor) Unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	authorizedCtx, authErr := ai.authorize(ctx, info.FullMethod)
+	if authErr != nil {
+		return nil, authErr
+	}
+	return handler(authorizedCtx, req)
+}
+
+// Stream authorizes streaming calls
+func (ai *AuthInterceptor) Stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	authorizedCtx, authErr := ai.authorize(ss.Context(), info.FullMethod)
+	if authErr != nil {
+		return authErr
+	}
+	return handler(srv, &serverStream{ServerStream: ss, ctx: authorizedCtx})
+}
+
+// authorize returns context with principal of the call
+func (ai *AuthInterceptor) authorize(ctx context.Context, fullMethod string) (context.Context, error) {
+	credential := credentialFromMetadata(ctx)
+	if credential == "" {
+		return nil, status.Error(codes.Unauthenticated, "Authentication is required")
+	}
+	principal, authErr := ai.authUseCase.Authenticate(ctx, credential)
+	if authErr != nil {
+		return nil, statusError(authErr)
+	}
+	ctx = entities.WithPrincipal(ctx, principal)
+
+	attempt := &entities.AuditEvent{
+		Action:        fullMethod,
+		Permission:    methodPermissions[fullMethod],
+		RequestMethod: "POST",
+		Path:          fullMethod,
+	}
+	if p, isPeer := peer.FromContext(ctx); isPeer {
+		attempt.RemoteAddr = p.Addr.String()
+	}
+	if accessErr := ai.accessUseCase.Authorize(ctx, attempt); accessErr != nil {
+		return nil, statusError(accessErr)
+	}
+	return ctx, nil
+}
+
+// credentialFromMetadata returns API key of x-api-key metadata or credential of bearer authorization metadata
+func credentialFromMetadata(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if apiKeys := md.Get(APIKeyMetadata); len(apiKeys) > 0 && apiKeys[0] != "" {
+		return apiKeys[0]
+	}
+	authorization := md.Get("authorization")
+	if len(authorization) == 0 {
+		return ""
+	}
+	parts := strings.SplitN(authorization[0], " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
+
+// serverStream replaces context of the stream with authorized one
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ss *serverStream) Context() context.Context {
+	return ss.ctx
+}
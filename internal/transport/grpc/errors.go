@@ -0,0 +1,46 @@
+package grpc
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusCodes maps statuses of use cases' errors to gRPC codes
+var statusCodes = map[int]codes.Code{
+	http.StatusBadRequest:         codes.InvalidArgument,
+	http.StatusUnauthorized:       codes.Unauthenticated,
+	http.StatusForbidden:          codes.PermissionDenied,
+	http.StatusNotFound:           codes.NotFound,
+	http.StatusConflict:           codes.AlreadyExists,
+	http.StatusTooManyRequests:    codes.ResourceExhausted,
+	http.StatusServiceUnavailable: codes.Unavailable,
+}
+
+// statusError returns gRPC status of use case's error
+func statusError(useCaseErr adapters.Error) error {
+	code, isMapped := statusCodes[useCaseErr.GetStatus()]
+	if !isMapped {
+		code = codes.Internal
+	}
+	return status.Error(code, useCaseErr.GetError().Error())
+}
+
+// formError returns InvalidArgument status with messages of form validation
+func formError(formErrors *map[string][]string) error {
+	fields := make([]string, 0, len(*formErrors))
+	for field := range *formErrors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, fmt.Sprintf("%s: %s", field, strings.Join((*formErrors)[field], ", ")))
+	}
+	return status.Error(codes.InvalidArgument, strings.Join(messages, "; "))
+}
@@ -0,0 +1,134 @@
+package grpc
+
+import (
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/ratelimit"
+	billingv1 "billing_system_test_task/internal/transport/grpc/billing/v1"
+	"context"
+	"log"
+	"math"
+	"net"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// methodRoutes maps methods of BillingService to routes of the HTTP API, so methods share configured limits and
+// token buckets of routes; methods missing here are limited by the default limit in own buckets
+var methodRoutes = map[string]string{
+	billingv1.BillingService_CreateUser_FullMethodName:     "CREATE_USER",
+	billingv1.BillingService_Enroll_FullMethodName:         "ENROLL_USER_WALLET",
+	billingv1.BillingService_Transfer_FullMethodName:       "TRANSFER_FUNDS",
+	billingv1.BillingService_ListOperations_FullMethodName: "OPERATIONS_LIST",
+}
+
+// LimitsInterceptor limits calls of clients with token buckets of the HTTP API's store
+type LimitsInterceptor struct {
+	store  ratelimit.Store
+	limits map[string]ratelimit.Limit
+}
+
+// NewLimitsInterceptor returns interceptor instance; methods without limit and without default limit are not rate limited
+func NewLimitsInterceptor(store ratelimit.Store, limits map[string]ratelimit.Limit) *LimitsInterceptor {
+	return &LimitsInterceptor{
+		store:  store,
+		limits: limits,
+	}
+}
+
+// AddressUnary limits unary calls of client's address; it must precede authentication
+func (li *LimitsInterceptor) AddressUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if limitErr := li.limitAddress(ctx, info.FullMethod); limitErr != nil {
+		return nil, limitErr
+	}
+	return handler(ctx, req)
+}
+
+// AddressStream limits streaming calls of client's address; it must precede authentication
+func (li *LimitsInterceptor) AddressStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if limitErr := li.limitAddress(ss.Context(), info.FullMethod); limitErr != nil {
+		return limitErr
+	}
+	return handler(srv, ss)
+}
+
+// Unary limits unary calls of principal to the method; it must follow authentication
+func (li *LimitsInterceptor) Unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if limitErr := li.limitPrincipal(ctx, info.FullMethod); limitErr != nil {
+		return nil, limitErr
+	}
+	return handler(ctx, req)
+}
+
+// Stream limits streaming calls of principal to the method; it must follow authentication
+func (li *LimitsInterceptor) Stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if limitErr := li.limitPrincipal(ss.Context(), info.FullMethod); limitErr != nil {
+		return limitErr
+	}
+	return handler(srv, ss)
+}
+
+// limitAddress takes a token of client's address; address limit is used when it is set, otherwise limit of the method
+func (li *LimitsInterceptor) limitAddress(ctx context.Context, fullMethod string) error {
+	limit, limited := li.limits[ratelimit.AddressRoute]
+	if !limited {
+		limit, limited = li.methodLimit(fullMethod)
+	}
+	if !limited {
+		return nil
+	}
+	address := "unknown"
+	if p, isPeer := peer.FromContext(ctx); isPeer {
+		address = p.Addr.String()
+		if host, _, splitErr := net.SplitHostPort(address); splitErr == nil {
+			address = host
+		}
+	}
+	return li.take(ctx, bucketName(fullMethod)+"|ip:"+address, limit)
+}
+
+// limitPrincipal takes a token of authenticated principal
+func (li *LimitsInterceptor) limitPrincipal(ctx context.Context, fullMethod string) error {
+	limit, limited := li.methodLimit(fullMethod)
+	principal := entities.PrincipalFromContext(ctx)
+	if !limited || principal == nil {
+		return nil
+	}
+	return li.take(ctx, bucketName(fullMethod)+"|principal:"+principal.Subject, limit)
+}
+
+// bucketName returns route of the method, so calls and HTTP requests of the route take tokens of one bucket
+func bucketName(fullMethod string) string {
+	if route, isRoute := methodRoutes[fullMethod]; isRoute {
+		return route
+	}
+	return fullMethod
+}
+
+// methodLimit returns limit of the method's route or default limit
+func (li *LimitsInterceptor) methodLimit(fullMethod string) (ratelimit.Limit, bool) {
+	if limit, limited := li.limits[methodRoutes[fullMethod]]; limited {
+		return limit, true
+	}
+	limit, limited := li.limits[ratelimit.DefaultRoute]
+	return limit, limited
+}
+
+// take takes a token from bucket of the key; calls are not rejected when limits storage is unavailable
+func (li *LimitsInterceptor) take(ctx context.Context, key string, limit ratelimit.Limit) error {
+	decision, takeErr := li.store.Take(ctx, key, limit)
+	if takeErr != nil {
+		log.Printf("[ERROR] Rate limit of %s is not checked: %s", key, takeErr)
+		return nil
+	}
+	if decision.Allowed {
+		return nil
+	}
+	retryAfter := int(math.Max(1, math.Ceil(decision.RetryAfter.Seconds())))
+	_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(retryAfter)))
+	return status.Error(codes.ResourceExhausted, "Rate limit is exceeded")
+}
@@ -0,0 +1,193 @@
+// Package grpc implements billing.v1 gRPC API for internal services; it shares use cases with the HTTP API.
+//
+// Code of billing/v1 is generated from billing/v1/billing.proto by `make proto`.
+package grpc
+
+import (
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/repositories"
+	billingv1 "billing_system_test_task/internal/transport/grpc/billing/v1"
+	"billing_system_test_task/internal/transport/http/forms"
+	"billing_system_test_task/internal/usecases"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// dateLayout is format of dates of operations' filters
+const dateLayout = "2006-01-02"
+
+// Server implements BillingService
+type Server struct {
+	billingv1.UnimplementedBillingServiceServer
+	userUseCase      usecases.UserUseCase
+	walletUseCase    usecases.WalletUseCase
+	operationUseCase usecases.WalletOperationUsecase
+}
+
+// NewServer returns BillingService instance
+func NewServer(userUseCase usecases.UserUseCase, walletUseCase usecases.WalletUseCase, operationUseCase usecases.WalletOperationUsecase) *Server {
+	return &Server{
+		userUseCase:      userUseCase,
+		walletUseCase:    walletUseCase,
+		operationUseCase: operationUseCase,
+	}
+}
+
+// NewGRPCServer returns gRPC server with registered BillingService, rate limits and authorization of calls;
+// calls are limited by address before authentication and by principal after it
+func NewGRPCServer(billingServer *Server, authInterceptor *AuthInterceptor, limitsInterceptor *LimitsInterceptor) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(limitsInterceptor.AddressUnary, authInterceptor.Unary, limitsInterceptor.Unary),
+		grpc.ChainStreamInterceptor(limitsInterceptor.AddressStream, authInterceptor.Stream, limitsInterceptor.Stream),
+	)
+	billingv1.RegisterBillingServiceServer(server, billingServer)
+	return server
+}
+
+// CreateUser creates user with empty wallet
+func (s *Server) CreateUser(ctx context.Context, req *billingv1.CreateUserRequest) (*billingv1.User, error) {
+	userForm := forms.UserForm{Email: req.GetEmail()}
+	if formErr := userForm.Submit(); formErr != nil {
+		return nil, formError(formErr)
+	}
+	user, createErr := s.userUseCase.Create(ctx, userForm.Email)
+	if createErr != nil {
+		return nil, statusError(createErr)
+	}
+	return userMessage(user), nil
+}
+
+// GetUser returns user with wallet
+func (s *Server) GetUser(ctx context.Context, req *billingv1.GetUserRequest) (*billingv1.User, error) {
+	user, getErr := s.userUseCase.Get(ctx, int(req.GetUserId()))
+	if getErr != nil {
+		return nil, statusError(getErr)
+	}
+	return userMessage(user), nil
+}
+
+// Enroll deposits amount to wallet of the user
+func (s *Server) Enroll(ctx context.Context, req *billingv1.EnrollRequest) (*billingv1.User, error) {
+	amount, amountErr := parseAmount(req.GetAmount())
+	if amountErr != nil {
+		return nil, amountErr
+	}
+	enrollForm := forms.EnrollForm{Amount: amount}
+	if formErr := enrollForm.Submit(); formErr != nil {
+		return nil, formError(formErr)
+	}
+	user, enrollErr := s.userUseCase.Enroll(ctx, int(req.GetUserId()), enrollForm.Amount)
+	if enrollErr != nil {
+		return nil, statusError(enrollErr)
+	}
+	return userMessage(user), nil
+}
+
+// Transfer transfers funds between wallets
+func (s *Server) Transfer(ctx context.Context, req *billingv1.TransferRequest) (*billingv1.TransferResponse, error) {
+	amount, amountErr := parseAmount(req.GetAmount())
+	if amountErr != nil {
+		return nil, amountErr
+	}
+	walletForm := forms.WalletForm{WalletFrom: int(req.GetWalletFrom()), WalletTo: int(req.GetWalletTo()), Amount: amount}
+	if formErr := walletForm.Submit(); formErr != nil {
+		return nil, formError(formErr)
+	}
+	walletID, transferErr := s.walletUseCase.Transfer(ctx, walletForm.WalletFrom, walletForm.WalletTo, walletForm.Amount)
+	if transferErr != nil {
+		return nil, statusError(transferErr)
+	}
+	return &billingv1.TransferResponse{WalletId: int64(walletID)}, nil
+}
+
+// GetWallet returns wallet
+func (s *Server) GetWallet(ctx context.Context, req *billingv1.GetWalletRequest) (*billingv1.Wallet, error) {
+	wallet, getErr := s.walletUseCase.Get(ctx, int(req.GetWalletId()))
+	if getErr != nil {
+		return nil, statusError(getErr)
+	}
+	return walletMessage(wallet), nil
+}
+
+// ListOperations streams wallet operations
+func (s *Server) ListOperations(req *billingv1.ListOperationsRequest, stream billingv1.BillingService_ListOperationsServer) error {
+	for _, date := range []string{req.GetDate(), req.GetFrom(), req.GetTo()} {
+		if _, parseErr := time.Parse(dateLayout, date); date != "" && parseErr != nil {
+			return status.Error(codes.InvalidArgument, fmt.Sprintf("invalid date %q, expected YYYY-MM-DD", date))
+		}
+	}
+	if req.GetPage() < 0 || req.GetPerPage() < 0 {
+		return status.Error(codes.InvalidArgument, "page and per_page must not be negative")
+	}
+
+	operations, readErrs, listErr := s.operationUseCase.List(stream.Context(), &repositories.ListParams{
+		Page:     int(req.GetPage()),
+		PerPage:  int(req.GetPerPage()),
+		Date:     req.GetDate(),
+		WalletID: int(req.GetWalletId()),
+		From:     req.GetFrom(),
+		To:       req.GetTo(),
+	})
+	if listErr != nil {
+		return statusError(listErr)
+	}
+	for operation := range operations {
+		if sendErr := stream.Send(operationMessage(operation)); sendErr != nil {
+			return sendErr
+		}
+	}
+	if readErr := <-readErrs; readErr != nil {
+		return status.Error(codes.Internal, readErr.Error())
+	}
+	return nil
+}
+
+func parseAmount(amount string) (decimal.Decimal, error) {
+	parsed, parseErr := decimal.NewFromString(amount)
+	if parseErr != nil {
+		return decimal.Zero, status.Error(codes.InvalidArgument, fmt.Sprintf("amount: %s", parseErr))
+	}
+	return parsed, nil
+}
+
+func userMessage(user *entities.User) *billingv1.User {
+	message := &billingv1.User{
+		Id:    int64(user.ID),
+		Email: user.Email,
+	}
+	if user.Wallet != nil {
+		message.Wallet = walletMessage(user.Wallet)
+	}
+	return message
+}
+
+func walletMessage(wallet *entities.Wallet) *billingv1.Wallet {
+	return &billingv1.Wallet{
+		Id:       int64(wallet.ID),
+		UserId:   int64(wallet.UserID),
+		Balance:  wallet.Balance.String(),
+		Currency: wallet.Currency,
+	}
+}
+
+func operationMessage(operation *entities.WalletOperation) *billingv1.Operation {
+	message := &billingv1.Operation{
+		Id:        int64(operation.ID),
+		Operation: operation.Operation,
+		WalletTo:  int64(operation.WalletTo),
+		Amount:    operation.Amount.String(),
+		CreatedAt: timestamppb.New(operation.CreatedAt),
+	}
+	if operation.WalletFrom.Valid {
+		walletFrom := int64(operation.WalletFrom.Int32)
+		message.WalletFrom = &walletFrom
+	}
+	return message
+}
@@ -0,0 +1,350 @@
+package grpc
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/ratelimit"
+	"billing_system_test_task/internal/repositories"
+	billingv1 "billing_system_test_task/internal/transport/grpc/billing/v1"
+	httpHandlers "billing_system_test_task/internal/transport/http"
+	"billing_system_test_task/internal/usecases"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// billingTest represents mocks of use cases and client connected to BillingService over bufconn
+type billingTest struct {
+	userUseCase      *usecases.MockUserUseCase
+	walletUseCase    *usecases.MockWalletUseCase
+	operationUseCase *usecases.MockWalletOperationUsecase
+	authUseCase      *usecases.MockAuthUsecase
+	accessUseCase    *usecases.MockAccessUsecase
+	limitsStore      ratelimit.Store
+	client           billingv1.BillingServiceClient
+}
+
+// newBillingTest starts server with given rate limits on in-memory listener, it is stopped by cleanup of the test
+func newBillingTest(t *testing.T, ctrl *gomock.Controller, limits map[string]ratelimit.Limit) *billingTest {
+	bt := &billingTest{
+		userUseCase:      usecases.NewMockUserUseCase(ctrl),
+		walletUseCase:    usecases.NewMockWalletUseCase(ctrl),
+		operationUseCase: usecases.NewMockWalletOperationUsecase(ctrl),
+		authUseCase:      usecases.NewMockAuthUsecase(ctrl),
+		accessUseCase:    usecases.NewMockAccessUsecase(ctrl),
+		limitsStore:      ratelimit.NewMemoryStore(),
+	}
+	listener := bufconn.Listen(1 << 20)
+	server := NewGRPCServer(
+		NewServer(bt.userUseCase, bt.walletUseCase, bt.operationUseCase),
+		NewAuthInterceptor(bt.authUseCase, bt.accessUseCase),
+		NewLimitsInterceptor(bt.limitsStore, limits),
+	)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	conn, dialErr := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if dialErr != nil {
+		t.Fatalf("Error of connection: %s", dialErr)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+		server.Stop()
+	})
+	bt.client = billingv1.NewBillingServiceClient(conn)
+	return bt
+}
+
+// authorize expects authentication of the API key and access to the method
+func (bt *billingTest) authorize(principal *entities.Principal, method string) {
+	bt.authUseCase.EXPECT().Authenticate(gomock.Any(), "bk_test").Return(principal, nil)
+	bt.accessUseCase.EXPECT().Authorize(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, attempt *entities.AuditEvent) adapters.Error {
+		if attempt.Action != method || attempt.Permission != methodPermissions[method] || entities.PrincipalFromContext(ctx) != principal {
+			return adapters.NewHTTPError(403, fmt.Errorf("unexpected attempt %+v", attempt))
+		}
+		return nil
+	})
+}
+
+func withAPIKey() context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), APIKeyMetadata, "bk_test")
+}
+
+// Test unary calls are authorized and mapped to use cases
+func TestServerUnary(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bt := newBillingTest(t, ctrl, nil)
+	principal := &entities.Principal{Subject: "1", UserID: 1}
+	user := &entities.User{ID: 1, Email: "user@example.com", Wallet: &entities.Wallet{ID: 3, UserID: 1, Balance: decimal.RequireFromString("10.50"), Currency: "USD"}}
+
+	bt.authorize(principal, billingv1.BillingService_GetUser_FullMethodName)
+	bt.userUseCase.EXPECT().Get(gomock.Any(), 1).Return(user, nil)
+	received, err := bt.client.GetUser(withAPIKey(), &billingv1.GetUserRequest{UserId: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if received.GetEmail() != user.Email || received.GetWallet().GetId() != 3 || received.GetWallet().GetBalance() != "10.5" {
+		t.Errorf("Unexpected user: %v", received)
+	}
+
+	bt.authorize(principal, billingv1.BillingService_Transfer_FullMethodName)
+	bt.walletUseCase.EXPECT().Transfer(gomock.Any(), 3, 4, decimal.RequireFromString("2.5")).Return(3, nil)
+	transferred, err := bt.client.Transfer(withAPIKey(), &billingv1.TransferRequest{WalletFrom: 3, WalletTo: 4, Amount: "2.5"})
+	if err != nil || transferred.GetWalletId() != 3 {
+		t.Errorf("Unexpected transfer: %v (%v)", transferred, err)
+	}
+
+	bt.authorize(principal, billingv1.BillingService_Enroll_FullMethodName)
+	bt.userUseCase.EXPECT().Enroll(gomock.Any(), 1, decimal.NewFromInt(5)).Return(user, nil)
+	if _, err = bt.client.Enroll(withAPIKey(), &billingv1.EnrollRequest{UserId: 1, Amount: "5"}); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
+
+// Test calls are limited by address before authentication and by principal after it
+func TestServerRateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	principal := &entities.Principal{Subject: "1", UserID: 1}
+	wallet := &entities.Wallet{ID: 3, UserID: 1, Balance: decimal.NewFromInt(1), Currency: "USD"}
+
+	// Anonymous calls are limited
+	bt := newBillingTest(t, ctrl, map[string]ratelimit.Limit{ratelimit.AddressRoute: {Rate: 0.1, Burst: 1}})
+	if _, err := bt.client.GetWallet(context.Background(), &billingv1.GetWalletRequest{WalletId: 3}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected unauthenticated call, got %v", err)
+	}
+	var header metadata.MD
+	_, err := bt.client.GetWallet(context.Background(), &billingv1.GetWalletRequest{WalletId: 3}, grpc.Header(&header))
+	if status.Code(err) != codes.ResourceExhausted || len(header.Get("retry-after")) == 0 || header.Get("retry-after")[0] != "10" {
+		t.Errorf("Expected rate limited call, got %v %v", err, header)
+	}
+
+	// Principal has own bucket of the method
+	bt = newBillingTest(t, ctrl, map[string]ratelimit.Limit{ratelimit.DefaultRoute: {Rate: 0.1, Burst: 1}, ratelimit.AddressRoute: {Rate: 10, Burst: 10}})
+	bt.authorize(principal, billingv1.BillingService_GetWallet_FullMethodName)
+	bt.walletUseCase.EXPECT().Get(gomock.Any(), 3).Return(wallet, nil)
+	if _, err = bt.client.GetWallet(withAPIKey(), &billingv1.GetWalletRequest{WalletId: 3}); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	bt.authorize(principal, billingv1.BillingService_GetWallet_FullMethodName)
+	if _, err = bt.client.GetWallet(withAPIKey(), &billingv1.GetWalletRequest{WalletId: 3}); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected rate limited call, got %v", err)
+	}
+}
+
+// Test calls and HTTP requests of the route take tokens of one bucket
+func TestServerRateLimitSharedWithHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	principal := &entities.Principal{Subject: "1", UserID: 1}
+	limits := map[string]ratelimit.Limit{"TRANSFER_FUNDS": {Rate: 0.1, Burst: 1}}
+	bt := newBillingTest(t, ctrl, limits)
+
+	router := mux.NewRouter()
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(entities.WithPrincipal(r.Context(), principal)))
+		})
+	}, httpHandlers.NewLimitsHandler(bt.limitsStore, limits, ratelimit.NewConcurrencyLimiter(1, 1)).Middleware)
+	router.HandleFunc("/api/wallets/transfer/", func(w http.ResponseWriter, r *http.Request) {}).Methods("POST").Name("TRANSFER_FUNDS")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/wallets/transfer/", nil))
+	if w.Code != 200 {
+		t.Fatalf("Unexpected HTTP response %d", w.Code)
+	}
+
+	bt.authorize(principal, billingv1.BillingService_Transfer_FullMethodName)
+	_, err := bt.client.Transfer(withAPIKey(), &billingv1.TransferRequest{WalletFrom: 3, WalletTo: 4, Amount: "1"})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected rate limited call, got %v", err)
+	}
+}
+
+// Test errors of calls are returned with gRPC codes
+func TestServerErrors(t *testing.T) {
+	principal := &entities.Principal{Subject: "1", UserID: 1}
+	tests := []struct {
+		name  string
+		call  func(bt *billingTest) error
+		code  codes.Code
+		setup func(bt *billingTest)
+	}{
+		{
+			name: "Credential is required",
+			call: func(bt *billingTest) error {
+				_, err := bt.client.GetWallet(context.Background(), &billingv1.GetWalletRequest{WalletId: 3})
+				return err
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "Invalid API key",
+			setup: func(bt *billingTest) {
+				bt.authUseCase.EXPECT().Authenticate(gomock.Any(), "bk_test").Return(nil, adapters.NewHTTPError(401, fmt.Errorf("invalid API key")))
+			},
+			call: func(bt *billingTest) error {
+				_, err := bt.client.GetWallet(withAPIKey(), &billingv1.GetWalletRequest{WalletId: 3})
+				return err
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "Permission is denied",
+			setup: func(bt *billingTest) {
+				bt.authUseCase.EXPECT().Authenticate(gomock.Any(), "bk_test").Return(principal, nil)
+				bt.accessUseCase.EXPECT().Authorize(gomock.Any(), gomock.Any()).Return(adapters.NewHTTPError(403, fmt.Errorf("users:create permission is required")))
+			},
+			call: func(bt *billingTest) error {
+				_, err := bt.client.CreateUser(withAPIKey(), &billingv1.CreateUserRequest{Email: "user@example.com"})
+				return err
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "Wallet is not found",
+			setup: func(bt *billingTest) {
+				bt.authorize(principal, billingv1.BillingService_GetWallet_FullMethodName)
+				bt.walletUseCase.EXPECT().Get(gomock.Any(), 3).Return(nil, adapters.NewHTTPError(404, fmt.Errorf("[WALLET_GET_BY_ID]: sql: no rows in result set")))
+			},
+			call: func(bt *billingTest) error {
+				_, err := bt.client.GetWallet(withAPIKey(), &billingv1.GetWalletRequest{WalletId: 3})
+				return err
+			},
+			code: codes.NotFound,
+		},
+		{
+			name: "Invalid email",
+			setup: func(bt *billingTest) {
+				bt.authorize(principal, billingv1.BillingService_CreateUser_FullMethodName)
+			},
+			call: func(bt *billingTest) error {
+				_, err := bt.client.CreateUser(withAPIKey(), &billingv1.CreateUserRequest{Email: "user"})
+				return err
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "Invalid amount",
+			setup: func(bt *billingTest) {
+				bt.authorize(principal, billingv1.BillingService_Transfer_FullMethodName)
+			},
+			call: func(bt *billingTest) error {
+				_, err := bt.client.Transfer(withAPIKey(), &billingv1.TransferRequest{WalletFrom: 3, WalletTo: 4, Amount: "-1"})
+				return err
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "Invalid date of operations",
+			setup: func(bt *billingTest) {
+				bt.authorize(principal, billingv1.BillingService_ListOperations_FullMethodName)
+			},
+			call: func(bt *billingTest) error {
+				stream, err := bt.client.ListOperations(withAPIKey(), &billingv1.ListOperationsRequest{From: "01.12.2022"})
+				if err != nil {
+					return err
+				}
+				_, err = stream.Recv()
+				return err
+			},
+			code: codes.InvalidArgument,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			bt := newBillingTest(t, ctrl, nil)
+			if tc.setup != nil {
+				tc.setup(bt)
+			}
+			if code := status.Code(tc.call(bt)); code != tc.code {
+				t.Errorf("Expected code %s, got %s", tc.code, code)
+			}
+		})
+	}
+}
+
+// Test operations are streamed
+func TestServerListOperations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	bt := newBillingTest(t, ctrl, nil)
+	createdAt := time.Date(2022, time.December, 1, 10, 0, 0, 0, time.UTC)
+	operations := make(chan *entities.WalletOperation, 2)
+	operations <- &entities.WalletOperation{ID: 1, Operation: "create wallet", WalletTo: 3, Amount: decimal.Zero, CreatedAt: createdAt}
+	operations <- &entities.WalletOperation{ID: 2, Operation: "deposit", WalletFrom: sql.NullInt32{Int32: 4, Valid: true}, WalletTo: 3, Amount: decimal.NewFromInt(7), CreatedAt: createdAt}
+	close(operations)
+	readErrs := make(chan error)
+	close(readErrs)
+
+	bt.authorize(&entities.Principal{Subject: "finance", Scopes: []string{"finance"}}, billingv1.BillingService_ListOperations_FullMethodName)
+	bt.operationUseCase.EXPECT().List(gomock.Any(), &repositories.ListParams{WalletID: 3, From: "2022-12-01", Page: 1, PerPage: 2}).
+		Return((<-chan *entities.WalletOperation)(operations), (<-chan error)(readErrs), nil)
+	stream, err := bt.client.ListOperations(withAPIKey(), &billingv1.ListOperationsRequest{WalletId: 3, From: "2022-12-01", Page: 1, PerPage: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	var received []*billingv1.Operation
+	for {
+		operation, recvErr := stream.Recv()
+		if recvErr == io.EOF {
+			break
+		}
+		if recvErr != nil {
+			t.Fatalf("Unexpected error: %s", recvErr)
+		}
+		received = append(received, operation)
+	}
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 operations, got %d", len(received))
+	}
+	if received[0].WalletFrom != nil || received[1].GetWalletFrom() != 4 || received[1].GetAmount() != "7" || !received[1].GetCreatedAt().AsTime().Equal(createdAt) {
+		t.Errorf("Unexpected operations: %v", received)
+	}
+}
+
+// Test statuses of use cases' errors are mapped to gRPC codes
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		status int
+		code   codes.Code
+	}{
+		{status: 400, code: codes.InvalidArgument},
+		{status: 401, code: codes.Unauthenticated},
+		{status: 403, code: codes.PermissionDenied},
+		{status: 404, code: codes.NotFound},
+		{status: 429, code: codes.ResourceExhausted},
+		{status: 500, code: codes.Internal},
+	}
+	for _, tc := range tests {
+		err := statusError(adapters.NewHTTPError(tc.status, fmt.Errorf("error")))
+		if code := status.Code(err); code != tc.code {
+			t.Errorf("Expected code %s for status %d, got %s", tc.code, tc.status, code)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"billing_system_test_task/internal/auth"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/usecases"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// routePermissions maps names of routes defined in NewRouter to required permissions;
+// routes missing here are denied
+var routePermissions = map[string]string{
+	"CREATE_USER":             auth.PermUsersCreate,
+	"ENROLL_USER_WALLET":      auth.PermWalletsEnroll,
+	"TRANSFER_FUNDS":          auth.PermWalletsTransfer,
+	"WALLET_EVENTS":           auth.PermWalletsRead,
+	"WALLET_STATEMENT":        auth.PermStatementsRead,
+	"OPERATIONS_LIST":         auth.PermOperationsRead,
+	"REPORTS_SUMMARY":         auth.PermReportsRead,
+	"REPORTS_USERS":           auth.PermReportsRead,
+	"REPORTS_BALANCES":        auth.PermReportsRead,
+	"REPORTS_PUBLIC_KEY":      auth.PermReportsVerify,
+	"REPORTS_DOWNLOAD":        auth.PermReportsDownload,
+	"REPORT_TEMPLATES_LIST":   auth.PermReportTemplatesRead,
+	"REPORT_TEMPLATES_GET":    auth.PermReportTemplatesRead,
+	"REPORT_TEMPLATES_SAVE":   auth.PermReportTemplatesWrite,
+	"REPORT_TEMPLATES_DELETE": auth.PermReportTemplatesWrite,
+	"FEEDS_OPERATIONS":        auth.PermFeedsConsume,
+	"FEEDS_ACK":               auth.PermFeedsConsume,
+	"WEBHOOKS_CREATE":         auth.PermWebhooksManage,
+	"WEBHOOKS_LIST":           auth.PermWebhooksManage,
+	"WEBHOOKS_DELETE":         auth.PermWebhooksManage,
+	"WEBHOOKS_DELIVERIES":     auth.PermWebhooksManage,
+	"WEBHOOKS_REDELIVER":      auth.PermWebhooksManage,
+	"API_KEYS_CREATE":         auth.PermAPIKeysManage,
+	"API_KEYS_REVOKE":         auth.PermAPIKeysManage,
+}
+
+// AccessHandler represents role-based access control of routes
+type AccessHandler struct {
+	accessUseCase usecases.AccessUsecase
+}
+
+// NewAccessHandler returns controller instance
+func NewAccessHandler(accessUseCase usecases.AccessUsecase) *AccessHandler {
+	return &AccessHandler{
+		accessUseCase: accessUseCase,
+	}
+}
+
+// Middleware checks permission of matched route; it must follow authentication middleware
+func (ah *AccessHandler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var routeName string
+		if route := mux.CurrentRoute(r); route != nil {
+			routeName = route.GetName()
+		}
+		accessErr := ah.accessUseCase.Authorize(r.Context(), &entities.AuditEvent{
+			Action:        routeName,
+			Permission:    routePermissions[routeName],
+			RequestMethod: r.Method,
+			Path:          r.URL.Path,
+			RemoteAddr:    r.RemoteAddr,
+		})
+		if accessErr != nil {
+			JsonResponseError(w, accessErr.GetStatus(), accessErr.GetError().Error())
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/auth"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/usecases"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+)
+
+// Test permissions of routes are checked by name
+func TestAccessHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		expected       *entities.AuditEvent
+		accessErr      adapters.Error
+		expectedStatus int
+	}{
+		{
+			name:           "Allowed route",
+			url:            "/api/operations/",
+			expected:       &entities.AuditEvent{Action: "OPERATIONS_LIST", Permission: auth.PermOperationsRead, RequestMethod: "GET", Path: "/api/operations/", RemoteAddr: "192.0.2.1:1234"},
+			expectedStatus: 200,
+		},
+		{
+			name:           "Denied route",
+			url:            "/api/operations/",
+			expected:       &entities.AuditEvent{Action: "OPERATIONS_LIST", Permission: auth.PermOperationsRead, RequestMethod: "GET", Path: "/api/operations/", RemoteAddr: "192.0.2.1:1234"},
+			accessErr:      adapters.NewHTTPError(403, fmt.Errorf("operations:read permission is required")),
+			expectedStatus: 403,
+		},
+		{
+			name:           "Route without permission",
+			url:            "/api/unmapped",
+			expected:       &entities.AuditEvent{Action: "UNMAPPED", RequestMethod: "GET", Path: "/api/unmapped", RemoteAddr: "192.0.2.1:1234"},
+			accessErr:      adapters.NewHTTPError(403, fmt.Errorf("UNMAPPED is not allowed")),
+			expectedStatus: 403,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			accessUseCase := usecases.NewMockAccessUsecase(ctrl)
+			accessUseCase.EXPECT().Authorize(gomock.Any(), tc.expected).Return(tc.accessErr)
+
+			r := mux.NewRouter()
+			api := r.PathPrefix("/api").Subrouter()
+			api.Use(NewAccessHandler(accessUseCase).Middleware)
+			ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+			api.HandleFunc("/operations/", ok).Methods("GET").Name("OPERATIONS_LIST")
+			api.HandleFunc("/unmapped", ok).Methods("GET").Name("UNMAPPED")
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", tc.url, nil))
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body)
+			}
+		})
+	}
+}
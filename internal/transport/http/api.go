@@ -18,14 +18,41 @@ import (
 // @license.url http://www.apache.org/licenses/LICENSE-2.0.html
 // @host localhost:8000
 // @BasePath /
-func NewRouter(usersHandler *UsersHandler, walletsHandler *WalletsHandler, operationsHandler *OperationsHandler) http.Handler {
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+func NewRouter(usersHandler *UsersHandler, walletsHandler *WalletsHandler, walletEventsHandler *WalletEventsHandler, operationsHandler *OperationsHandler, reportsHandler *ReportsHandler, feedsHandler *FeedsHandler, templatesHandler *TemplatesHandler, webhooksHandler *WebhooksHandler, authHandler *AuthHandler, accessHandler *AccessHandler, limitsHandler *LimitsHandler) http.Handler {
 	r := mux.NewRouter()
 
 	api := r.PathPrefix("/api").Subrouter()
+	api.Use(limitsHandler.AddressMiddleware, authHandler.Middleware, limitsHandler.Middleware, accessHandler.Middleware)
 	api.HandleFunc("/users/", usersHandler.Create).Methods("POST").Name("CREATE_USER")
 	api.HandleFunc("/users/{id}/enroll/", usersHandler.Enroll).Methods("POST").Name("ENROLL_USER_WALLET")
-	api.HandleFunc("/wallets/transfer/", walletsHandler.Transfer).Methods("POST").Name("Transfer funds")
+	api.HandleFunc("/wallets/transfer/", walletsHandler.Transfer).Methods("POST").Name("TRANSFER_FUNDS")
+	api.HandleFunc("/wallets/{id}/events", walletEventsHandler.Stream).Methods("GET").Name("WALLET_EVENTS")
+	api.HandleFunc("/wallets/{id}/statement", operationsHandler.Statement).Methods("GET").Name("WALLET_STATEMENT")
 	api.HandleFunc("/operations/", operationsHandler.List).Methods("GET").Name("OPERATIONS_LIST")
+	api.HandleFunc("/reports/summary", reportsHandler.Summary).Methods("GET").Name("REPORTS_SUMMARY")
+	api.HandleFunc("/reports/users", reportsHandler.Users).Methods("GET").Name("REPORTS_USERS")
+	api.HandleFunc("/reports/balances", reportsHandler.Balances).Methods("GET").Name("REPORTS_BALANCES")
+	api.HandleFunc("/reports/public-key", reportsHandler.PublicKey).Methods("GET").Name("REPORTS_PUBLIC_KEY")
+	api.HandleFunc("/reports/files/{name}", reportsHandler.Download).Methods("GET").Name("REPORTS_DOWNLOAD")
+	api.HandleFunc("/report-templates/", templatesHandler.List).Methods("GET").Name("REPORT_TEMPLATES_LIST")
+	api.HandleFunc("/report-templates/{name}", templatesHandler.Get).Methods("GET").Name("REPORT_TEMPLATES_GET")
+	api.HandleFunc("/report-templates/{name}", templatesHandler.Save).Methods("PUT").Name("REPORT_TEMPLATES_SAVE")
+	api.HandleFunc("/report-templates/{name}", templatesHandler.Delete).Methods("DELETE").Name("REPORT_TEMPLATES_DELETE")
+	api.HandleFunc("/feeds/{consumer}/operations", feedsHandler.Operations).Methods("GET").Name("FEEDS_OPERATIONS")
+	api.HandleFunc("/feeds/{consumer}/ack", feedsHandler.Ack).Methods("POST").Name("FEEDS_ACK")
+	api.HandleFunc("/webhooks/", webhooksHandler.Create).Methods("POST").Name("WEBHOOKS_CREATE")
+	api.HandleFunc("/webhooks/", webhooksHandler.List).Methods("GET").Name("WEBHOOKS_LIST")
+	api.HandleFunc("/webhooks/{id}", webhooksHandler.Delete).Methods("DELETE").Name("WEBHOOKS_DELETE")
+	api.HandleFunc("/webhooks/{id}/deliveries", webhooksHandler.Deliveries).Methods("GET").Name("WEBHOOKS_DELIVERIES")
+	api.HandleFunc("/webhook-deliveries/{id}/redeliver", webhooksHandler.Redeliver).Methods("POST").Name("WEBHOOKS_REDELIVER")
+	api.HandleFunc("/api-keys/", authHandler.CreateAPIKey).Methods("POST").Name("API_KEYS_CREATE")
+	api.HandleFunc("/api-keys/{id}", authHandler.RevokeAPIKey).Methods("DELETE").Name("API_KEYS_REVOKE")
 	r.PathPrefix("/swagger").Handler(httpSwagger.WrapHandler)
 	r.Handle("/metrics", promhttp.Handler())
 	return r
@@ -1,8 +1,10 @@
 package http
 
 import (
+	"billing_system_test_task/internal/ratelimit"
 	"billing_system_test_task/internal/usecases"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/gorilla/mux"
@@ -13,18 +15,45 @@ func TestAPINewRouter(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	userUseCase := usecases.NewMockUserUseCase(ctrl)
 	walletUseCase := usecases.NewMockWalletUseCase(ctrl)
+	walletEventsUseCase := usecases.NewMockWalletEventsUsecase(ctrl)
 	operationUseCase := usecases.NewMockWalletOperationUsecase(ctrl)
+	reportUseCase := usecases.NewMockReportUsecase(ctrl)
+	feedUseCase := usecases.NewMockFeedUsecase(ctrl)
+	templateUseCase := usecases.NewMockReportTemplateUsecase(ctrl)
+	webhookUseCase := usecases.NewMockWebhookUsecase(ctrl)
+	authUseCase := usecases.NewMockAuthUsecase(ctrl)
+	accessUseCase := usecases.NewMockAccessUsecase(ctrl)
 
 	userHandler := NewUserHandler(userUseCase)
 	walletHandler := NewWalletsHandler(walletUseCase)
+	walletEventsHandler := NewWalletEventsHandler(walletEventsUseCase, time.Second)
 	operationHandler := NewOperationsHandler(operationUseCase)
+	reportHandler := NewReportsHandler(reportUseCase)
+	feedHandler := NewFeedsHandler(feedUseCase)
+	templateHandler := NewTemplatesHandler(templateUseCase)
+	webhookHandler := NewWebhooksHandler(webhookUseCase)
+	authHandler := NewAuthHandler(authUseCase)
+	accessHandler := NewAccessHandler(accessUseCase)
+	limitsHandler := NewLimitsHandler(ratelimit.NewMemoryStore(), map[string]ratelimit.Limit{}, ratelimit.NewConcurrencyLimiter(0, 0))
 
-	router := NewRouter(userHandler, walletHandler, operationHandler)
+	router := NewRouter(userHandler, walletHandler, walletEventsHandler, operationHandler, reportHandler, feedHandler, templateHandler, webhookHandler, authHandler, accessHandler, limitsHandler)
 	if router == nil {
 		t.Error("Expected implementation of http.Handler, got nil")
 	}
-	_, isMux := router.(*mux.Router)
+	muxRouter, isMux := router.(*mux.Router)
 	if !isMux {
-		t.Error("Received instance is not *mux.Router type")
+		t.Fatal("Received instance is not *mux.Router type")
 	}
+
+	// Every API route must require permission
+	_ = muxRouter.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, _ := route.GetPathTemplate()
+		if len(ancestors) == 0 || route.GetHandler() == nil {
+			return nil
+		}
+		if _, exists := routePermissions[route.GetName()]; !exists {
+			t.Errorf("Route %s (%q) has no permission", template, route.GetName())
+		}
+		return nil
+	})
 }
@@ -0,0 +1,152 @@
+package http
+
+import (
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/transport/http/forms"
+	"billing_system_test_task/internal/transport/http/serializers"
+	"billing_system_test_task/internal/usecases"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// APIKeyHeader is alternative to Authorization header for servers' API keys
+const APIKeyHeader = "X-API-Key"
+
+// AuthHandler represents authentication middleware and API keys management
+type AuthHandler struct {
+	authUseCase usecases.AuthUsecase
+}
+
+// NewAuthHandler returns controller instance
+func NewAuthHandler(authUseCase usecases.AuthUsecase) *AuthHandler {
+	return &AuthHandler{
+		authUseCase: authUseCase,
+	}
+}
+
+// Middleware authenticates request with API key or bearer token and puts principal into request's context
+func (ah *AuthHandler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		credential := credentialFromRequest(r)
+		if credential == "" {
+			unauthorized(w, "Authentication is required")
+			return
+		}
+		principal, authErr := ah.authUseCase.Authenticate(r.Context(), credential)
+		if authErr != nil {
+			if authErr.GetStatus() == http.StatusUnauthorized {
+				unauthorized(w, authErr.GetError().Error())
+				return
+			}
+			JsonResponseError(w, authErr.GetStatus(), authErr.GetError().Error())
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(entities.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// credentialFromRequest returns API key of X-API-Key header or credential of bearer Authorization header
+func credentialFromRequest(r *http.Request) string {
+	if apiKey := r.Header.Get(APIKeyHeader); apiKey != "" {
+		return apiKey
+	}
+	authorization := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	if len(authorization) != 2 || !strings.EqualFold(authorization[0], "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(authorization[1])
+}
+
+func unauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="billing"`)
+	JsonResponseError(w, http.StatusUnauthorized, message)
+}
+
+// CreateAPIKey godoc
+// @Summary Create API key
+// @Description Create API key for server or user, it requires admin scope.
+// @Description The key is returned only in this response, only its hash is stored.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param key body forms.APIKeyForm true "Name, user and scopes of the key"
+// @Success 201 {object} serializers.APIKeySerializer "Created key"
+// @Failure 400 {object} FormErrorSerializer "Form validation error"
+// @Failure 403 {object} ErrorMsg
+// @Failure default {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/api-keys/ [post]
+func (ah *AuthHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var keyForm forms.APIKeyForm
+	decoder := json.NewDecoder(r.Body)
+	if decodeErr := decoder.Decode(&keyForm); decodeErr != nil {
+		JsonResponseError(w, http.StatusBadRequest, fmt.Sprintf("Error json form decoding: %s", decodeErr))
+		return
+	}
+
+	// Validate body parameters
+	if formError := keyForm.Submit(); formError != nil {
+		log.Println(fmt.Sprintf("[ERROR] API key error - %s", *formError))
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(FormErrorSerializer{Messages: *formError})
+		return
+	}
+
+	apiKey, key, createErr := ah.authUseCase.CreateAPIKey(r.Context(), &entities.APIKey{
+		Name:   keyForm.Name,
+		UserID: keyForm.UserID,
+		Scopes: keyForm.Scopes,
+	})
+	if createErr != nil {
+		JsonResponseError(w, createErr.GetStatus(), fmt.Sprintf("Error of API key creation: %s", createErr.GetError()))
+		return
+	}
+	serialized := serializers.APIKeySerializer{
+		ID:        apiKey.ID,
+		Name:      apiKey.Name,
+		Key:       key,
+		Prefix:    apiKey.Prefix,
+		Scopes:    apiKey.Scopes,
+		CreatedAt: apiKey.CreatedAt,
+	}
+	if apiKey.UserID != 0 {
+		serialized.UserID = &apiKey.UserID
+	}
+	if serialized.Scopes == nil {
+		serialized.Scopes = []string{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(serialized)
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke API key
+// @Description Revoke API key, it requires admin scope
+// @Tags auth
+// @Param id path int true "API key ID"
+// @Success 204 {string} string "Key is revoked"
+// @Failure 403 {object} ErrorMsg
+// @Failure 404 {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/api-keys/{id} [delete]
+func (ah *AuthHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, convErr := strconv.Atoi(mux.Vars(r)["id"])
+	if convErr != nil {
+		JsonResponseError(w, http.StatusBadRequest, fmt.Sprintf("Error formatting API key id to int: %s", convErr))
+		return
+	}
+	if revokeErr := ah.authUseCase.RevokeAPIKey(r.Context(), id); revokeErr != nil {
+		JsonResponseError(w, revokeErr.GetStatus(), revokeErr.GetError().Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,170 @@
+package http
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/usecases"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+)
+
+// newAuthRouter returns router with API keys endpoints and endpoint printing principal's subject
+func newAuthRouter(authUseCase usecases.AuthUsecase) *mux.Router {
+	r := mux.NewRouter()
+	handler := NewAuthHandler(authUseCase)
+	api := r.PathPrefix("/api").Subrouter()
+	api.Use(handler.Middleware)
+	api.HandleFunc("/whoami", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(entities.PrincipalFromContext(r.Context()).Subject))
+	}).Methods("GET")
+	api.HandleFunc("/api-keys/", handler.CreateAPIKey).Methods("POST")
+	api.HandleFunc("/api-keys/{id}", handler.RevokeAPIKey).Methods("DELETE")
+	return r
+}
+
+// Test authentication middleware and API keys endpoints
+func TestAuthHandler(t *testing.T) {
+	now := time.Date(2022, time.November, 1, 12, 0, 0, 0, time.UTC)
+	admin := &entities.Principal{Subject: "api_key:1", Scopes: []string{entities.ScopeAdmin}, Method: entities.AuthMethodAPIKey}
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		headers        map[string]string
+		body           string
+		mockData       func(authUseCase *usecases.MockAuthUsecase)
+		expectedStatus int
+		expected       string
+	}{
+		{
+			name:           "Missing credentials",
+			method:         "GET",
+			url:            "/api/whoami",
+			mockData:       func(authUseCase *usecases.MockAuthUsecase) {},
+			expectedStatus: 401,
+			expected:       "Authentication is required",
+		},
+		{
+			name:           "Unsupported authorization scheme",
+			method:         "GET",
+			url:            "/api/whoami",
+			headers:        map[string]string{"Authorization": "Basic dXNlcjpwYXNz"},
+			mockData:       func(authUseCase *usecases.MockAuthUsecase) {},
+			expectedStatus: 401,
+			expected:       "Authentication is required",
+		},
+		{
+			name:    "Bearer token",
+			method:  "GET",
+			url:     "/api/whoami",
+			headers: map[string]string{"Authorization": "bearer token"},
+			mockData: func(authUseCase *usecases.MockAuthUsecase) {
+				authUseCase.EXPECT().Authenticate(gomock.Any(), "token").Return(&entities.Principal{Subject: "7", UserID: 7}, nil)
+			},
+			expectedStatus: 200,
+			expected:       "7",
+		},
+		{
+			name:    "API key header",
+			method:  "GET",
+			url:     "/api/whoami",
+			headers: map[string]string{APIKeyHeader: "bk_key"},
+			mockData: func(authUseCase *usecases.MockAuthUsecase) {
+				authUseCase.EXPECT().Authenticate(gomock.Any(), "bk_key").Return(admin, nil)
+			},
+			expectedStatus: 200,
+			expected:       "api_key:1",
+		},
+		{
+			name:    "Revoked API key",
+			method:  "GET",
+			url:     "/api/whoami",
+			headers: map[string]string{"Authorization": "Bearer bk_revoked"},
+			mockData: func(authUseCase *usecases.MockAuthUsecase) {
+				authUseCase.EXPECT().Authenticate(gomock.Any(), "bk_revoked").Return(nil, adapters.NewHTTPError(401, fmt.Errorf("invalid API key")))
+			},
+			expectedStatus: 401,
+			expected:       "invalid API key",
+		},
+		{
+			name:    "Success API key creation",
+			method:  "POST",
+			url:     "/api/api-keys/",
+			headers: map[string]string{APIKeyHeader: "bk_key"},
+			body:    `{"name": "partner", "user_id": 7, "scopes": ["read"]}`,
+			mockData: func(authUseCase *usecases.MockAuthUsecase) {
+				authUseCase.EXPECT().Authenticate(gomock.Any(), "bk_key").Return(admin, nil)
+				authUseCase.EXPECT().CreateAPIKey(gomock.Any(), &entities.APIKey{Name: "partner", UserID: 7, Scopes: []string{"read"}}).
+					Return(&entities.APIKey{ID: 2, Name: "partner", Prefix: "bk_new12", UserID: 7, Scopes: []string{"read"}, CreatedAt: now}, "bk_new123", nil)
+			},
+			expectedStatus: 201,
+			expected:       `{"id":2,"name":"partner","key":"bk_new123","prefix":"bk_new12","user_id":7,"scopes":["read"],"created_at":"2022-11-01T12:00:00Z"}`,
+		},
+		{
+			name:    "API key without name",
+			method:  "POST",
+			url:     "/api/api-keys/",
+			headers: map[string]string{APIKeyHeader: "bk_key"},
+			body:    `{"scopes": ["read"]}`,
+			mockData: func(authUseCase *usecases.MockAuthUsecase) {
+				authUseCase.EXPECT().Authenticate(gomock.Any(), "bk_key").Return(admin, nil)
+			},
+			expectedStatus: 400,
+			expected:       `"name"`,
+		},
+		{
+			name:    "API key revocation without admin scope",
+			method:  "DELETE",
+			url:     "/api/api-keys/2",
+			headers: map[string]string{"Authorization": "Bearer token"},
+			mockData: func(authUseCase *usecases.MockAuthUsecase) {
+				authUseCase.EXPECT().Authenticate(gomock.Any(), "token").Return(&entities.Principal{Subject: "7", UserID: 7}, nil)
+				authUseCase.EXPECT().RevokeAPIKey(gomock.Any(), 2).Return(adapters.NewHTTPError(403, fmt.Errorf("admin scope is required")))
+			},
+			expectedStatus: 403,
+			expected:       "admin scope is required",
+		},
+		{
+			name:    "Success API key revocation",
+			method:  "DELETE",
+			url:     "/api/api-keys/2",
+			headers: map[string]string{APIKeyHeader: "bk_key"},
+			mockData: func(authUseCase *usecases.MockAuthUsecase) {
+				authUseCase.EXPECT().Authenticate(gomock.Any(), "bk_key").Return(admin, nil)
+				authUseCase.EXPECT().RevokeAPIKey(gomock.Any(), 2).Return(nil)
+			},
+			expectedStatus: 204,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			authUseCase := usecases.NewMockAuthUsecase(ctrl)
+			tc.mockData(authUseCase)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+			for key, value := range tc.headers {
+				r.Header.Set(key, value)
+			}
+			newAuthRouter(authUseCase).ServeHTTP(w, r)
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body)
+			}
+			if tc.expectedStatus == 401 && w.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("Missing WWW-Authenticate header")
+			}
+			if !strings.Contains(w.Body.String(), tc.expected) {
+				t.Errorf("Wrong response: %s", w.Body)
+			}
+		})
+	}
+}
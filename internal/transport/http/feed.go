@@ -0,0 +1,140 @@
+package http
+
+import (
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/transport/http/forms"
+	"billing_system_test_task/internal/transport/http/serializers"
+	"billing_system_test_task/internal/usecases"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// FeedsHandler represents handler structure for the incremental operations feeds
+type FeedsHandler struct {
+	feedUseCase usecases.FeedUsecase
+}
+
+// NewFeedsHandler returns controller instance
+func NewFeedsHandler(feedUseCase usecases.FeedUsecase) *FeedsHandler {
+	return &FeedsHandler{
+		feedUseCase: feedUseCase,
+	}
+}
+
+// Operations godoc
+// @Summary Operations feed
+// @Description Get wallet operations which are not acknowledged by the consumer yet. Batch is repeated until its cursor is acknowledged.
+// @Description Consumer is created by the first request and is available to its creator and admins only.
+// @Tags feeds
+// @Accept  json
+// @Produce json,application/x-ndjson,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,application/vnd.msgpack,application/x-protobuf,application/gzip,application/zip,application/octet-stream
+// @Param consumer path string true "Consumer name (letters, digits, '_', '.', '-')"
+// @Param limit query int false "Maximum number of operations in the batch (default 1000, max 10000)"
+// @Param format query string false "Report format (json, ndjson, csv, xlsx, msgpack or protobuf)"
+// @Param amount_format query string false "Encoding of amounts in json and msgpack reports (string or number)"
+// @Param columns query string false "Comma-separated list and order of csv columns"
+// @Param delimiter query string false "CSV delimiter (single character or 'tab')"
+// @Param header query bool false "Write csv header (default true)"
+// @Param time_layout query string false "Timestamp layout in csv (rfc3339, rfc3339nano, datetime, date or go layout)"
+// @Param time_zone query string false "Time zone of csv timestamps (default UTC)"
+// @Param decimal_separator query string false "Decimal separator of csv amounts ('.' or ',')"
+// @Param null_value query string false "Rendering of NULL values in csv"
+// @Param manifest query bool false "Return detached manifest of the report"
+// @Param persist query bool false "Keep the report in storage for later downloads"
+// @Param compress query string false "Compression of the report (gzip or zip)"
+// @Param encrypt query string false "AES-256-GCM encryption of the report (passphrase or key)"
+// @Param X-Report-Passphrase header string false "Passphrase of the report encryption (encrypt=passphrase)"
+// @Success 200 {file} file
+// @Success 204 {string} string "No new operations"
+// @Failure 400 {object} ErrorMsg
+// @Failure 404 {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/feeds/{consumer}/operations [get]
+// @Header 200,204 {string} X-Feed-Offset "Acknowledged cursor of the consumer"
+// @Header 200 {string} X-Feed-Cursor "Cursor of the last operation of the batch for acknowledgement"
+// @Header 200 {string} Server-Timing "Duration and backpressure metrics of the report pipeline stages"
+// @Header 200 {string} Digest "SHA-256 checksum of the report (base64)"
+// @Header 200 {string} X-Report-Signature "Ed25519 signature of the report's checksum with key id"
+// @Header 200 {string} X-Report-Manifest "Base64 json manifest of the report (when manifest=true)"
+// @Header 200 {string} X-Report-Manifest-Signature "Ed25519 signature of the manifest's checksum with key id (when manifest=true)"
+// @Header 200 {string} Content-Location "Download path of the stored report (when persist=true)"
+func (fh *FeedsHandler) Operations(w http.ResponseWriter, r *http.Request) {
+	batch, feedErr := fh.feedUseCase.Operations(r.Context(), mux.Vars(r)["consumer"], reportQuery(r))
+	if feedErr != nil {
+		JsonResponseError(w, feedErr.GetStatus(), feedErr.GetError().Error())
+		return
+	}
+	w.Header().Set("X-Feed-Offset", batch.Consumer.Position.String())
+	if batch.Report == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("X-Feed-Cursor", batch.To.String())
+	sendReportFile(w, r, batch.Report)
+}
+
+// Ack godoc
+// @Summary Acknowledge feed batch
+// @Description Move consumer's offset to the cursor of delivered batch. Repeated acknowledgement does not change the offset.
+// @Description Offset is moved by creator of the consumer or admin only.
+// @Tags feeds
+// @Accept  json
+// @Produce  json
+// @Param consumer path string true "Consumer name"
+// @Param ack body forms.FeedAckForm true "Cursor of delivered batch (X-Feed-Cursor header)"
+// @Success 200 {object} serializers.FeedConsumerSerializer "Acknowledged offset of the consumer"
+// @Failure 400 {object} FormErrorSerializer "Acknowledgement validation error"
+// @Failure 404 {object} ErrorMsg
+// @Failure default {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/feeds/{consumer}/ack [post]
+func (fh *FeedsHandler) Ack(w http.ResponseWriter, r *http.Request) {
+	var ackForm forms.FeedAckForm
+	decoder := json.NewDecoder(r.Body)
+	if decodeErr := decoder.Decode(&ackForm); decodeErr != nil {
+		JsonResponseError(w, http.StatusBadRequest, fmt.Sprintf("Error json form decoding: %s", decodeErr))
+		return
+	}
+
+	// Validate body parameters
+	if formError := ackForm.Submit(); formError != nil {
+		log.Println(fmt.Sprintf("[ERROR] Feed ack error - %s", *formError))
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(FormErrorSerializer{Messages: *formError})
+		return
+	}
+
+	consumer, ackErr := fh.feedUseCase.Ack(r.Context(), mux.Vars(r)["consumer"], ackForm.Cursor)
+	if ackErr != nil {
+		JsonResponseError(w, ackErr.GetStatus(), fmt.Sprintf("Error of feed acknowledgement: %s", ackErr.GetError()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(newFeedConsumerSerializer(consumer))
+}
+
+// newFeedConsumerSerializer returns serialized consumer; empty timestamps are null
+func newFeedConsumerSerializer(consumer *entities.FeedConsumer) serializers.FeedConsumerSerializer {
+	nullTime := func(t sql.NullTime) *time.Time {
+		if !t.Valid {
+			return nil
+		}
+		return &t.Time
+	}
+	return serializers.FeedConsumerSerializer{
+		Name:            consumer.Name,
+		Cursor:          consumer.Position.String(),
+		LastOperationID: consumer.Position.OperationID,
+		LastCreatedAt:   nullTime(consumer.LastCreatedAt),
+		AckedAt:         nullTime(consumer.AckedAt),
+	}
+}
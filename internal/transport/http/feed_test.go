@@ -0,0 +1,179 @@
+package http
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/usecases"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+)
+
+// newFeedsRouter returns router with feed endpoints
+func newFeedsRouter(feedUseCase usecases.FeedUsecase) *mux.Router {
+	r := mux.NewRouter()
+	handler := NewFeedsHandler(feedUseCase)
+	r.HandleFunc("/api/feeds/{consumer}/operations", handler.Operations).Methods("GET")
+	r.HandleFunc("/api/feeds/{consumer}/ack", handler.Ack).Methods("POST")
+	return r
+}
+
+// Test operations feed endpoint
+func TestFeedsHandlerOperations(t *testing.T) {
+	consumer := &entities.FeedConsumer{Name: "warehouse", Position: entities.FeedPosition{TxID: 500, OperationID: 10}}
+	tests := []struct {
+		name           string
+		url            string
+		mockData       func(feedUseCase *usecases.MockFeedUsecase)
+		expectedStatus int
+		cursor         string
+		body           string
+	}{
+		{
+			name: "New operations",
+			url:  "/api/feeds/warehouse/operations?limit=10&format=ndjson",
+			mockData: func(feedUseCase *usecases.MockFeedUsecase) {
+				feedUseCase.EXPECT().Operations(gomock.Any(), "warehouse", gomock.Any()).DoAndReturn(func(ctx interface{}, name string, query map[string][]string) (*entities.FeedBatch, adapters.Error) {
+					if query["limit"][0] != "10" {
+						t.Errorf("Query parameters are not passed to use case: %v", query)
+					}
+					return &entities.FeedBatch{
+						Consumer: consumer,
+						To:       entities.FeedPosition{TxID: 510, OperationID: 11},
+						Report: &entities.FileMetadata{
+							Name:        "report.ndjson",
+							Content:     storedReport("{\"id\":11}\n"),
+							ContentType: "application/x-ndjson",
+						},
+					}, nil
+				})
+			},
+			expectedStatus: 200,
+			cursor:         "510-11",
+			body:           "{\"id\":11}\n",
+		},
+		{
+			name: "No new operations",
+			url:  "/api/feeds/warehouse/operations",
+			mockData: func(feedUseCase *usecases.MockFeedUsecase) {
+				feedUseCase.EXPECT().Operations(gomock.Any(), "warehouse", gomock.Any()).Return(&entities.FeedBatch{Consumer: consumer, To: consumer.Position}, nil)
+			},
+			expectedStatus: 204,
+		},
+		{
+			name: "Invalid parameters",
+			url:  "/api/feeds/warehouse/operations?limit=0",
+			mockData: func(feedUseCase *usecases.MockFeedUsecase) {
+				feedUseCase.EXPECT().Operations(gomock.Any(), "warehouse", gomock.Any()).Return(nil, adapters.NewHTTPError(400, fmt.Errorf("invalid 'limit' attribute (1-10000): 0")))
+			},
+			expectedStatus: 400,
+			body:           "invalid 'limit' attribute",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			feedUseCase := usecases.NewMockFeedUsecase(ctrl)
+			tc.mockData(feedUseCase)
+
+			w := httptest.NewRecorder()
+			newFeedsRouter(feedUseCase).ServeHTTP(w, httptest.NewRequest("GET", tc.url, nil))
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body)
+			}
+			if tc.expectedStatus == 400 {
+				if !strings.Contains(w.Body.String(), tc.body) {
+					t.Errorf("Wrong error message: %s", w.Body)
+				}
+				return
+			}
+			if w.Header().Get("X-Feed-Offset") != "500-10" || w.Header().Get("X-Feed-Cursor") != tc.cursor {
+				t.Errorf("Wrong feed headers: %v", w.Header())
+			}
+			if w.Body.String() != tc.body {
+				t.Errorf("Wrong body: %q", w.Body)
+			}
+		})
+	}
+}
+
+// Test acknowledgement endpoint
+func TestFeedsHandlerAck(t *testing.T) {
+	ackedAt := time.Date(2022, time.October, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		body           string
+		mockData       func(feedUseCase *usecases.MockFeedUsecase)
+		expectedStatus int
+		expected       string
+	}{
+		{
+			name: "Success acknowledgement",
+			body: `{"cursor": "510-11"}`,
+			mockData: func(feedUseCase *usecases.MockFeedUsecase) {
+				feedUseCase.EXPECT().Ack(gomock.Any(), "warehouse", "510-11").Return(&entities.FeedConsumer{
+					Name:          "warehouse",
+					Position:      entities.FeedPosition{TxID: 510, OperationID: 11},
+					LastCreatedAt: sql.NullTime{Time: ackedAt, Valid: true},
+					AckedAt:       sql.NullTime{Time: ackedAt, Valid: true},
+				}, nil)
+			},
+			expectedStatus: 200,
+			expected:       `{"name":"warehouse","cursor":"510-11","last_operation_id":11,"last_created_at":"2022-10-18T12:00:00Z","acked_at":"2022-10-18T12:00:00Z"}`,
+		},
+		{
+			name:           "Missing cursor",
+			body:           `{}`,
+			mockData:       func(feedUseCase *usecases.MockFeedUsecase) {},
+			expectedStatus: 400,
+			expected:       `"cursor"`,
+		},
+		{
+			name:           "Invalid json",
+			body:           `cursor`,
+			mockData:       func(feedUseCase *usecases.MockFeedUsecase) {},
+			expectedStatus: 400,
+			expected:       "Error json form decoding",
+		},
+		{
+			name: "Unknown consumer",
+			body: `{"cursor": "510-11"}`,
+			mockData: func(feedUseCase *usecases.MockFeedUsecase) {
+				feedUseCase.EXPECT().Ack(gomock.Any(), "warehouse", "510-11").Return(nil, adapters.NewHTTPError(404, fmt.Errorf("feed consumer is not found")))
+			},
+			expectedStatus: 404,
+			expected:       "Error of feed acknowledgement: feed consumer is not found",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			feedUseCase := usecases.NewMockFeedUsecase(ctrl)
+			tc.mockData(feedUseCase)
+
+			w := httptest.NewRecorder()
+			newFeedsRouter(feedUseCase).ServeHTTP(w, httptest.NewRequest("POST", "/api/feeds/warehouse/ack", strings.NewReader(tc.body)))
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body)
+			}
+			if tc.expectedStatus == http.StatusOK {
+				if strings.TrimSpace(w.Body.String()) != tc.expected {
+					t.Errorf("Wrong acknowledged consumer: %s", w.Body)
+				}
+				return
+			}
+			if !strings.Contains(w.Body.String(), tc.expected) {
+				t.Errorf("Wrong error message: %s", w.Body)
+			}
+		})
+	}
+}
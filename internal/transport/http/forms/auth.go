@@ -0,0 +1,26 @@
+package forms
+
+// APIKeyForm represents parameters of new API key
+type APIKeyForm struct {
+	Name   string   `json:"name" validate:"required"`
+	UserID int      `json:"user_id"`
+	Scopes []string `json:"scopes"`
+}
+
+// Submit validates given parameters of API key
+func (af *APIKeyForm) Submit() *map[string][]string {
+	var (
+		errors = ValidateForm(af, make(map[string][]string))
+	)
+
+	if af.UserID < 0 {
+		errors["user_id"] = append(errors["user_id"], "less than a zero")
+	}
+
+	// Perform validations by tags
+	if len(errors) > 0 {
+		return &errors
+	}
+
+	return nil
+}
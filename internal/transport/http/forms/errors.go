@@ -31,6 +31,8 @@ func errorTagMessage(tag string) string {
 		result = "Field required"
 	case "email":
 		result = "Invalid email format"
+	case "url":
+		result = "Invalid url format"
 	}
 	return result
 }
@@ -0,0 +1,20 @@
+package forms
+
+// FeedAckForm represents acknowledgement of delivered feed batch
+type FeedAckForm struct {
+	Cursor string `json:"cursor" validate:"required"`
+}
+
+// Submit validates given parameters of acknowledgement
+func (ff *FeedAckForm) Submit() *map[string][]string {
+	var (
+		errors = ValidateForm(ff, make(map[string][]string))
+	)
+
+	// Perform validations by tags
+	if len(errors) > 0 {
+		return &errors
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package forms
+
+// ReportTemplateForm represents sections of report template
+type ReportTemplateForm struct {
+	Header string `json:"header"`
+	Row    string `json:"row" validate:"required"`
+	Footer string `json:"footer"`
+}
+
+// Submit validates given sections of report template
+func (rf *ReportTemplateForm) Submit() *map[string][]string {
+	var (
+		errors = ValidateForm(rf, make(map[string][]string))
+	)
+
+	// Perform validations by tags
+	if len(errors) > 0 {
+		return &errors
+	}
+
+	return nil
+}
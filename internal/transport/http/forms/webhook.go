@@ -0,0 +1,26 @@
+package forms
+
+// WebhookForm represents parameters of new webhook subscription
+type WebhookForm struct {
+	URL        string   `json:"url" validate:"required,url"`
+	EventTypes []string `json:"event_types" validate:"required"`
+	Secret     string   `json:"secret"`
+}
+
+// Submit validates given parameters of webhook subscription
+func (wf *WebhookForm) Submit() *map[string][]string {
+	var (
+		errors = ValidateForm(wf, make(map[string][]string))
+	)
+
+	if len(wf.Secret) > 0 && len(wf.Secret) < 16 {
+		errors["secret"] = append(errors["secret"], "shorter than 16 characters")
+	}
+
+	// Perform validations by tags
+	if len(errors) > 0 {
+		return &errors
+	}
+
+	return nil
+}
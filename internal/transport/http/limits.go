@@ -0,0 +1,149 @@
+package http
+
+import (
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/ratelimit"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// reportRoutes are routes generating reports, they are limited by number of simultaneous requests
+var reportRoutes = map[string]bool{
+	"OPERATIONS_LIST":  true,
+	"REPORTS_SUMMARY":  true,
+	"REPORTS_USERS":    true,
+	"REPORTS_BALANCES": true,
+	"FEEDS_OPERATIONS": true,
+	"WALLET_STATEMENT": true,
+}
+
+// reportsRetryAfter is suggested delay when all report generation slots are busy
+const reportsRetryAfter = 5 * time.Second
+
+// LimitsHandler represents rate limits of clients and concurrency limits of report generation
+type LimitsHandler struct {
+	store   ratelimit.Store
+	limits  map[string]ratelimit.Limit
+	reports *ratelimit.ConcurrencyLimiter
+}
+
+// NewLimitsHandler returns controller instance; routes without limit and without default limit are not rate limited
+func NewLimitsHandler(store ratelimit.Store, limits map[string]ratelimit.Limit, reports *ratelimit.ConcurrencyLimiter) *LimitsHandler {
+	return &LimitsHandler{
+		store:   store,
+		limits:  limits,
+		reports: reports,
+	}
+}
+
+// AddressMiddleware limits requests of client's address to the route; it must precede authentication middleware,
+// so requests with missing or invalid credentials are limited too. Address limit is used when it is set,
+// otherwise limit of the route is applied to each address.
+func (lh *LimitsHandler) AddressMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		routeName := currentRouteName(r)
+		limit, limited := lh.limits[ratelimit.AddressRoute]
+		if !limited {
+			limit, limited = lh.routeLimit(routeName)
+		}
+		if limited && !lh.take(w, r, routeName+"|"+addressKey(r), limit) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Middleware limits requests of client to the route; it must follow authentication middleware
+func (lh *LimitsHandler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		routeName := currentRouteName(r)
+		client := clientKey(r)
+		if limit, limited := lh.routeLimit(routeName); limited && !lh.take(w, r, routeName+"|"+client, limit) {
+			return
+		}
+
+		if reportRoutes[routeName] {
+			release, acquired := lh.reports.TryAcquire(client)
+			if !acquired {
+				tooManyRequests(w, reportsRetryAfter, "Too many reports are generated at once")
+				return
+			}
+			defer release()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// routeLimit returns limit of the route or default limit
+func (lh *LimitsHandler) routeLimit(routeName string) (ratelimit.Limit, bool) {
+	if limit, limited := lh.limits[routeName]; limited {
+		return limit, true
+	}
+	limit, limited := lh.limits[ratelimit.DefaultRoute]
+	return limit, limited
+}
+
+// take takes a token from bucket of the key and responds with 429 when the limit is exceeded;
+// requests are not rejected when limits storage is unavailable
+func (lh *LimitsHandler) take(w http.ResponseWriter, r *http.Request, key string, limit ratelimit.Limit) bool {
+	decision, takeErr := lh.store.Take(r.Context(), key, limit)
+	if takeErr != nil {
+		log.Printf("[ERROR] Rate limit of %s is not checked: %s", key, takeErr)
+		return true
+	}
+	setRateLimitHeaders(w, decision)
+	if !decision.Allowed {
+		tooManyRequests(w, decision.RetryAfter, "Rate limit is exceeded")
+		return false
+	}
+	return true
+}
+
+func currentRouteName(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		return route.GetName()
+	}
+	return ""
+}
+
+// clientKey identifies client by authenticated principal or by IP address
+func clientKey(r *http.Request) string {
+	if principal := entities.PrincipalFromContext(r.Context()); principal != nil {
+		return "principal:" + principal.Subject
+	}
+	return addressKey(r)
+}
+
+// addressKey identifies client by IP address
+func addressKey(r *http.Request) string {
+	host, _, splitErr := net.SplitHostPort(r.RemoteAddr)
+	if splitErr != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+func setRateLimitHeaders(w http.ResponseWriter, decision *ratelimit.Decision) {
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.Reset)))
+}
+
+func tooManyRequests(w http.ResponseWriter, retryAfter time.Duration, message string) {
+	seconds := ceilSeconds(retryAfter)
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	JsonResponseError(w, http.StatusTooManyRequests, message)
+}
+
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
@@ -0,0 +1,217 @@
+package http
+
+import (
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/ratelimit"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// failingStore is limits storage which is unavailable
+type failingStore struct{}
+
+func (fs failingStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (*ratelimit.Decision, error) {
+	return nil, fmt.Errorf("[RATE_LIMIT]: connection error")
+}
+
+// newLimitsRouter returns router with limited routes; blocked report waits on the channel
+func newLimitsRouter(limitsHandler *LimitsHandler, blocked chan struct{}) *mux.Router {
+	r := mux.NewRouter()
+	api := r.PathPrefix("/api").Subrouter()
+	api.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if subject := r.Header.Get("X-Subject"); subject != "" {
+				r = r.WithContext(entities.WithPrincipal(r.Context(), &entities.Principal{Subject: subject}))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}, limitsHandler.Middleware)
+	api.HandleFunc("/wallets/transfer/", func(w http.ResponseWriter, r *http.Request) {}).Methods("POST").Name("TRANSFER_FUNDS")
+	api.HandleFunc("/users/", func(w http.ResponseWriter, r *http.Request) {}).Methods("POST").Name("CREATE_USER")
+	api.HandleFunc("/operations/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("block") != "" {
+			<-blocked
+		}
+	}).Methods("GET").Name("OPERATIONS_LIST")
+	api.HandleFunc("/wallets/{id}/statement", func(w http.ResponseWriter, r *http.Request) {}).Methods("GET").Name("WALLET_STATEMENT")
+	return r
+}
+
+// Test rate limits of clients
+func TestLimitsHandlerRateLimit(t *testing.T) {
+	limits := map[string]ratelimit.Limit{
+		"TRANSFER_FUNDS":       {Rate: 0.1, Burst: 2},
+		ratelimit.DefaultRoute: {Rate: 0.1, Burst: 1},
+	}
+	router := newLimitsRouter(NewLimitsHandler(ratelimit.NewMemoryStore(), limits, ratelimit.NewConcurrencyLimiter(0, 0)), nil)
+	request := func(method, url, subject, remoteAddr string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, url, nil)
+		r.RemoteAddr = remoteAddr
+		if subject != "" {
+			r.Header.Set("X-Subject", subject)
+		}
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	for i, remaining := range []string{"1", "0"} {
+		w := request("POST", "/api/wallets/transfer/", "7", "192.0.2.1:1000")
+		if w.Code != 200 || w.Header().Get("RateLimit-Limit") != "2" || w.Header().Get("RateLimit-Remaining") != remaining {
+			t.Fatalf("[%d] Expected allowed request with %s remaining, got %d %v", i, remaining, w.Code, w.Header())
+		}
+	}
+	w := request("POST", "/api/wallets/transfer/", "7", "192.0.2.1:1000")
+	if w.Code != 429 || w.Header().Get("Retry-After") != "10" || w.Header().Get("RateLimit-Reset") != "20" {
+		t.Errorf("Expected rate limited request, got %d %v", w.Code, w.Header())
+	}
+	// Another principal from the same address has own bucket
+	if w := request("POST", "/api/wallets/transfer/", "8", "192.0.2.1:1000"); w.Code != 200 {
+		t.Errorf("Expected allowed request, got %d", w.Code)
+	}
+	// Route without own limit uses default limit and own bucket
+	if w := request("POST", "/api/users/", "7", "192.0.2.1:1000"); w.Code != 200 || w.Header().Get("RateLimit-Limit") != "1" {
+		t.Errorf("Expected allowed request, got %d %v", w.Code, w.Header())
+	}
+	// Anonymous clients are limited by address
+	if w := request("POST", "/api/users/", "", "192.0.2.1:1000"); w.Code != 200 {
+		t.Errorf("Expected allowed request, got %d", w.Code)
+	}
+	if w := request("POST", "/api/users/", "", "192.0.2.1:2000"); w.Code != 429 {
+		t.Errorf("Expected rate limited request, got %d", w.Code)
+	}
+
+	// Unavailable storage does not reject requests
+	failing := newLimitsRouter(NewLimitsHandler(failingStore{}, limits, ratelimit.NewConcurrencyLimiter(0, 0)), nil)
+	w = httptest.NewRecorder()
+	failing.ServeHTTP(w, httptest.NewRequest("POST", "/api/wallets/transfer/", nil))
+	if w.Code != 200 || w.Header().Get("RateLimit-Limit") != "" {
+		t.Errorf("Expected allowed request without limit headers, got %d %v", w.Code, w.Header())
+	}
+}
+
+// Test requests are limited by address before authentication, so anonymous clients are limited too
+func TestLimitsHandlerAddressLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits map[string]ratelimit.Limit
+	}{
+		{name: "Address limit", limits: map[string]ratelimit.Limit{ratelimit.AddressRoute: {Rate: 0.1, Burst: 2}, ratelimit.DefaultRoute: {Rate: 10, Burst: 10}}},
+		{name: "Route limit of address", limits: map[string]ratelimit.Limit{"TRANSFER_FUNDS": {Rate: 0.1, Burst: 2}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			limitsHandler := NewLimitsHandler(ratelimit.NewMemoryStore(), tc.limits, ratelimit.NewConcurrencyLimiter(0, 0))
+			r := mux.NewRouter()
+			api := r.PathPrefix("/api").Subrouter()
+			api.Use(limitsHandler.AddressMiddleware, func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					unauthorized(w, "Authentication is required")
+				})
+			}, limitsHandler.Middleware)
+			api.HandleFunc("/wallets/transfer/", func(w http.ResponseWriter, r *http.Request) {}).Methods("POST").Name("TRANSFER_FUNDS")
+
+			codes := []int{}
+			for _, remoteAddr := range []string{"192.0.2.1:1000", "192.0.2.1:2000", "192.0.2.1:3000", "192.0.2.2:1000"} {
+				w := httptest.NewRecorder()
+				request := httptest.NewRequest("POST", "/api/wallets/transfer/", nil)
+				request.RemoteAddr = remoteAddr
+				r.ServeHTTP(w, request)
+				codes = append(codes, w.Code)
+				if w.Code == 429 && w.Header().Get("Retry-After") != "10" {
+					t.Errorf("Wrong Retry-After: %v", w.Header())
+				}
+			}
+			if fmt.Sprint(codes) != fmt.Sprint([]int{401, 401, 429, 401}) {
+				t.Errorf("Unexpected codes %v", codes)
+			}
+		})
+	}
+}
+
+// Test simultaneous report generations are limited
+func TestLimitsHandlerReportsConcurrency(t *testing.T) {
+	blocked := make(chan struct{})
+	router := newLimitsRouter(NewLimitsHandler(ratelimit.NewMemoryStore(), nil, ratelimit.NewConcurrencyLimiter(2, 1)), blocked)
+	request := func(url, subject string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", url, nil)
+		r.Header.Set("X-Subject", subject)
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	done := make(chan int)
+	go func() { done <- request("/api/operations/?block=1", "a").Code }()
+	go func() { done <- request("/api/operations/?block=1", "b").Code }()
+	// Requests of other clients pass until both blocked requests are running
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		w := request("/api/operations/", "c")
+		if w.Code == 429 {
+			if w.Header().Get("Retry-After") != "5" {
+				t.Errorf("Wrong Retry-After: %v", w.Header())
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Total limit of reports is not applied")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if w := request("/api/operations/", "a"); w.Code != 429 {
+		t.Errorf("Expected limited request of busy client, got %d", w.Code)
+	}
+	close(blocked)
+	for i := 0; i < 2; i++ {
+		if code := <-done; code != 200 {
+			t.Errorf("Expected finished report, got %d", code)
+		}
+	}
+	if w := request("/api/operations/", "c"); w.Code != 200 {
+		t.Errorf("Expected released slots, got %d", w.Code)
+	}
+}
+
+// Test statements are limited by busy report generation slots
+func TestLimitsHandlerStatementConcurrency(t *testing.T) {
+	blocked := make(chan struct{})
+	router := newLimitsRouter(NewLimitsHandler(ratelimit.NewMemoryStore(), nil, ratelimit.NewConcurrencyLimiter(1, 1)), blocked)
+	request := func(url, subject string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", url, nil)
+		r.Header.Set("X-Subject", subject)
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	done := make(chan int)
+	go func() { done <- request("/api/operations/?block=1", "a").Code }()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		w := request("/api/wallets/1/statement?format=ofx", "b")
+		if w.Code == 429 {
+			if w.Header().Get("Retry-After") != "5" {
+				t.Errorf("Wrong Retry-After: %v", w.Header())
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Limit of reports is not applied to statements")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(blocked)
+	if code := <-done; code != 200 {
+		t.Errorf("Expected finished report, got %d", code)
+	}
+	if w := request("/api/wallets/1/statement?format=ofx", "b"); w.Code != 200 {
+		t.Errorf("Expected released slot, got %d", w.Code)
+	}
+}
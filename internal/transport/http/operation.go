@@ -1,9 +1,17 @@
 package http
 
 import (
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/repositories/reports"
 	"billing_system_test_task/internal/usecases"
+	"encoding/base64"
+	"fmt"
 	"net/http"
-	"os"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
 )
 
 // OperationsHandler represents handler structure for the operatons
@@ -23,33 +31,158 @@ func NewOperationsHandler(woUseCase usecases.WalletOperationUsecase) *Operations
 // @Description Get wallet operations logs
 // @Tags operations
 // @Accept  json
-// @Produce application/octet-stream
-// @Param format query string false "Report format"
+// @Produce json,application/x-ndjson,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,application/vnd.msgpack,application/xml,application/x-ofx,application/qif,application/x-protobuf,text/plain,application/gzip,application/zip,application/octet-stream
+// @Param format query string false "Report format (json, ndjson, csv, xlsx, msgpack, protobuf, camt053, ofx, qif or template)"
+// @Param template query string false "Name of report template (required for template format)"
+// @Param amount_format query string false "Encoding of amounts in json and msgpack reports (string or number)"
+// @Param columns query string false "Comma-separated list and order of csv columns"
+// @Param delimiter query string false "CSV delimiter (single character or 'tab')"
+// @Param header query bool false "Write csv header (default true)"
+// @Param time_layout query string false "Timestamp layout in csv (rfc3339, rfc3339nano, datetime, date or go layout)"
+// @Param time_zone query string false "Time zone of csv timestamps (default UTC)"
+// @Param decimal_separator query string false "Decimal separator of csv amounts ('.' or ',')"
+// @Param null_value query string false "Rendering of NULL values in csv"
 // @Param page query int false "Page number"
 // @Param per_page query int false "Number of items per page"
 // @Param date query int false "Number of items per page"
+// @Param wallet query int false "Wallet of operations (required for camt053, ofx and qif)"
+// @Param from query string false "Start date of the period (YYYY-MM-DD)"
+// @Param to query string false "End date of the period (YYYY-MM-DD)"
+// @Param manifest query bool false "Return detached manifest of the report"
+// @Param persist query bool false "Keep the report in storage for later downloads"
+// @Param compress query string false "Compression of the report (gzip or zip)"
+// @Param encrypt query string false "AES-256-GCM encryption of the report (passphrase or key)"
+// @Param X-Report-Passphrase header string false "Passphrase of the report encryption (encrypt=passphrase)"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /api/operations/ [get]
-// @Header 200 {string} Content-Type "application/octet-stream"
+// @Header 200 {string} Content-Type "Content type of the report format"
 // @Header 200 {string} Expires "0"
+// @Header 200 {string} Server-Timing "Duration and backpressure metrics of the report pipeline stages"
+// @Header 200 {string} Digest "SHA-256 checksum of the report (base64)"
+// @Header 200 {string} X-Report-Signature "Ed25519 signature of the report's checksum with key id"
+// @Header 200 {string} X-Report-Manifest "Base64 json manifest of the report (when manifest=true)"
+// @Header 200 {string} X-Report-Manifest-Signature "Ed25519 signature of the manifest's checksum with key id (when manifest=true)"
+// @Header 200 {string} Content-Location "Download path of the stored report (when persist=true)"
 func (oh *OperationsHandler) List(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	v := r.URL.Query()
-	fileMetadata, grErr := oh.woUseCase.GenerateReport(ctx, v)
+	fileMetadata, grErr := oh.woUseCase.GenerateReport(ctx, reportQuery(r))
+	if grErr != nil {
+		JsonResponseError(w, grErr.GetStatus(), grErr.GetError().Error())
+		return
+	}
+	sendReportFile(w, r, fileMetadata)
+}
+
+// Statement godoc
+// @Summary Wallet statement
+// @Description Get bank statement of the wallet; users receive statements of own wallets only
+// @Tags wallets
+// @Produce application/xml,application/x-ofx,application/qif,application/gzip,application/zip,application/octet-stream
+// @Param id path int true "Wallet ID"
+// @Param format query string true "Statement format (camt053, ofx or qif)"
+// @Param from query string false "Start date of the period (YYYY-MM-DD)"
+// @Param to query string false "End date of the period (YYYY-MM-DD)"
+// @Param manifest query bool false "Return detached manifest of the report"
+// @Param persist query bool false "Keep the report in storage for later downloads"
+// @Param compress query string false "Compression of the report (gzip or zip)"
+// @Param encrypt query string false "AES-256-GCM encryption of the report (passphrase or key)"
+// @Param X-Report-Passphrase header string false "Passphrase of the report encryption (encrypt=passphrase)"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/wallets/{id}/statement [get]
+// @Header 200 {string} Digest "SHA-256 checksum of the report (base64)"
+// @Header 200 {string} X-Report-Signature "Ed25519 signature of the report's checksum with key id"
+// @Header 200 {string} X-Report-Manifest "Base64 json manifest of the report (when manifest=true)"
+// @Header 200 {string} X-Report-Manifest-Signature "Ed25519 signature of the manifest's checksum with key id (when manifest=true)"
+// @Header 200 {string} Content-Location "Download path of the stored report (when persist=true)"
+func (oh *OperationsHandler) Statement(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	walletID, convErr := strconv.Atoi(mux.Vars(r)["id"])
+	if convErr != nil {
+		JsonResponseError(w, http.StatusBadRequest, fmt.Sprintf("Error formatting wallet id to int: %s", convErr))
+		return
+	}
+	query := reportQuery(r)
+	if !reports.IsStatementFormat(query.Get("format")) {
+		JsonResponseError(w, http.StatusBadRequest, fmt.Sprintf("Format %q is not a statement format", query.Get("format")))
+		return
+	}
+	query.Set("wallet", strconv.Itoa(walletID))
+
+	fileMetadata, grErr := oh.woUseCase.GenerateReport(ctx, query)
 	if grErr != nil {
 		JsonResponseError(w, grErr.GetStatus(), grErr.GetError().Error())
 		return
 	}
+	sendReportFile(w, r, fileMetadata)
+}
+
+// reportQuery returns report's query parameters with encryption passphrase from X-Report-Passphrase header;
+// passphrase is not accepted in URL to keep it out of access logs
+func reportQuery(r *http.Request) url.Values {
+	query := r.URL.Query()
+	query.Del("passphrase")
+	if passphrase := r.Header.Get("X-Report-Passphrase"); passphrase != "" {
+		query.Set("passphrase", passphrase)
+	}
+	return query
+}
 
-	defer func(path string, f *os.File) {
-		f.Close()
-		os.Remove(path)
-	}(fileMetadata.Path, fileMetadata.File)
+// sendReportFile writes report to response and closes its content; temporary report is removed on closing
+func sendReportFile(w http.ResponseWriter, r *http.Request, fileMetadata *entities.FileMetadata) {
+	defer fileMetadata.Content.Close()
 
-	w.Header().Set("Content-Disposition", "attachment; filename="+fileMetadata.File.Name())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileMetadata.Name))
 	w.Header().Set("Content-Type", fileMetadata.ContentType)
-	w.Header().Set("Content-Length", fileMetadata.Size)
-	w.WriteHeader(http.StatusOK)
+	if fileMetadata.Persisted {
+		w.Header().Set("Content-Location", reportDownloadPath+fileMetadata.Name)
+	}
+	if len(fileMetadata.Stats) > 0 {
+		w.Header().Set("Server-Timing", serverTiming(fileMetadata.Stats))
+	}
+	if fileMetadata.SHA256 != nil {
+		w.Header().Set("Digest", "SHA-256="+base64.StdEncoding.EncodeToString(fileMetadata.SHA256))
+	}
+	if fileMetadata.Signature != nil {
+		w.Header().Set("X-Report-Signature", fmt.Sprintf(
+			"keyId=%q, algorithm=%q, signature=%q",
+			fileMetadata.KeyID,
+			reports.SignatureAlgorithm,
+			base64.StdEncoding.EncodeToString(fileMetadata.Signature),
+		))
+	}
+	if fileMetadata.ManifestData != nil {
+		// Manifest is detached from the report's body and can be saved from header for verification
+		w.Header().Set("X-Report-Manifest", base64.StdEncoding.EncodeToString(fileMetadata.ManifestData))
+	}
+	if fileMetadata.ManifestSignature != nil {
+		w.Header().Set("X-Report-Manifest-Signature", fmt.Sprintf(
+			"keyId=%q, algorithm=%q, signature=%q",
+			fileMetadata.KeyID,
+			reports.SignatureAlgorithm,
+			base64.StdEncoding.EncodeToString(fileMetadata.ManifestSignature),
+		))
+	}
 
-	http.ServeFile(w, r, fileMetadata.Path)
+	// Content-Length and range requests are handled by ServeContent
+	http.ServeContent(w, r, fileMetadata.Name, fileMetadata.Content.ModTime(), fileMetadata.Content)
+}
+
+// serverTiming formats pipeline stages metrics as Server-Timing header value
+func serverTiming(stats []entities.StageStats) string {
+	metrics := make([]string, 0, len(stats))
+	for _, st := range stats {
+		metrics = append(metrics, fmt.Sprintf(
+			"%s;dur=%.3f;desc=\"in=%d out=%d send_blocked=%.3fms receive_blocked=%.3fms\"",
+			st.Stage,
+			float64(st.Duration.Microseconds())/1000,
+			st.ItemsIn,
+			st.ItemsOut,
+			float64(st.SendBlocked.Microseconds())/1000,
+			float64(st.ReceiveBlocked.Microseconds())/1000,
+		))
+	}
+	return strings.Join(metrics, ", ")
 }
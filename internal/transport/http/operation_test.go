@@ -14,9 +14,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
-	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/gorilla/mux"
@@ -38,6 +38,7 @@ type operationWalletTest struct {
 	mockData       func(operationUseCase *usecases.MockWalletOperationUsecase)
 	formError      bool
 	errMsg         string
+	headers        map[string]string
 }
 
 var httpTests = []operationWalletTest{
@@ -49,16 +50,36 @@ var httpTests = []operationWalletTest{
 			"email": "example@mail.com",
 		},
 		mockData: func(operationUseCase *usecases.MockWalletOperationUsecase) {
-			f, _ := os.CreateTemp("", "_example_file")
 			operationUseCase.EXPECT().GenerateReport(gomock.Any(), gomock.Any()).Return(&entities.FileMetadata{
-				File:        f,
-				Path:        "/a/b/c/" + f.Name(),
-				Size:        "100",
+				Name:        "report.json",
+				Content:     storedReport("[]\n"),
+				Size:        "3",
 				ContentType: "json",
 			}, nil)
 		},
 		expectedStatus: 200,
 	},
+	operationWalletTest{
+		name:   "Success file receiving with pipeline metrics",
+		method: "GET",
+		url:    "/api/operations/",
+		mockData: func(operationUseCase *usecases.MockWalletOperationUsecase) {
+			operationUseCase.EXPECT().GenerateReport(gomock.Any(), gomock.Any()).Return(&entities.FileMetadata{
+				Name:        "report.json",
+				Content:     storedReport("[]\n"),
+				Size:        "3",
+				ContentType: "json",
+				Stats: []entities.StageStats{
+					{Stage: "read", ItemsOut: 10, Duration: 1500 * time.Microsecond},
+					{Stage: "write", ItemsIn: 10, Duration: 2 * time.Millisecond},
+				},
+			}, nil)
+		},
+		expectedStatus: 200,
+		headers: map[string]string{
+			"Server-Timing": `read;dur=1.500;desc="in=0 out=10 send_blocked=0.000ms receive_blocked=0.000ms", write;dur=2.000;desc="in=10 out=0 send_blocked=0.000ms receive_blocked=0.000ms"`,
+		},
+	},
 	operationWalletTest{
 		name:   "Failed file receiving",
 		method: "GET",
@@ -71,6 +92,52 @@ var httpTests = []operationWalletTest{
 		},
 		expectedStatus: 400,
 	},
+	operationWalletTest{
+		name:   "Success statement receiving",
+		method: "GET",
+		url:    "/api/wallets/2/statement?format=ofx&wallet=1",
+		mockData: func(operationUseCase *usecases.MockWalletOperationUsecase) {
+			operationUseCase.EXPECT().GenerateReport(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, query url.Values) (*entities.FileMetadata, adapters.Error) {
+					if query.Get("wallet") != "2" {
+						return nil, adapters.NewHTTPError(400, fmt.Errorf("unexpected wallet %s", query.Get("wallet")))
+					}
+					return &entities.FileMetadata{
+						Name:        "statement.ofx",
+						Content:     storedReport("OFXHEADER:100\n"),
+						Size:        "14",
+						ContentType: "application/x-ofx",
+					}, nil
+				})
+		},
+		expectedStatus: 200,
+	},
+	operationWalletTest{
+		name:           "Failed statement receiving (format is not a statement)",
+		method:         "GET",
+		url:            "/api/wallets/2/statement?format=csv",
+		mockData:       func(operationUseCase *usecases.MockWalletOperationUsecase) {},
+		expectedStatus: 400,
+		errMsg:         `Format "csv" is not a statement format`,
+	},
+	operationWalletTest{
+		name:           "Failed statement receiving (invalid wallet id)",
+		method:         "GET",
+		url:            "/api/wallets/first/statement?format=ofx",
+		mockData:       func(operationUseCase *usecases.MockWalletOperationUsecase) {},
+		expectedStatus: 400,
+		errMsg:         "Error formatting wallet id to int",
+	},
+	operationWalletTest{
+		name:   "Failed statement receiving (wallet of another user)",
+		method: "GET",
+		url:    "/api/wallets/3/statement?format=qif",
+		mockData: func(operationUseCase *usecases.MockWalletOperationUsecase) {
+			operationUseCase.EXPECT().GenerateReport(gomock.Any(), gomock.Any()).Return(nil, adapters.NewHTTPError(404, fmt.Errorf("wallet 3 is not found")))
+		},
+		expectedStatus: 404,
+		errMsg:         "wallet 3 is not found",
+	},
 }
 
 // Test operations package endpoints
@@ -87,6 +154,7 @@ func TestOperationsHandler(t *testing.T) {
 			handler := NewOperationsHandler(useCase)
 			api_router := r.PathPrefix("/api").Subrouter()
 			api_router.HandleFunc("/operations/", handler.List).Methods("GET")
+			api_router.HandleFunc("/wallets/{id}/statement", handler.Statement).Methods("GET")
 			tc.mockData(useCase)
 
 			testServer := httptest.NewServer(r)
@@ -106,6 +174,11 @@ func TestOperationsHandler(t *testing.T) {
 			if resp.StatusCode != tc.expectedStatus {
 				t.Errorf("[%s] Expected response code %d. Got %d", testLabel, tc.expectedStatus, resp.StatusCode)
 			}
+			for header, value := range tc.headers {
+				if resp.Header.Get(header) != value {
+					t.Errorf("[%s] Expected header %s '%s'. Got '%s'", testLabel, header, value, resp.Header.Get(header))
+				}
+			}
 			if tc.errMsg != "" {
 				errors := make(map[string]string)
 				respBody, _ := ioutil.ReadAll(resp.Body)
@@ -185,12 +258,11 @@ func BenchmarkOperationsList(b *testing.B) {
 	defer ctrl.Finish()
 
 	r := mux.NewRouter()
-	f, _ := os.CreateTemp("", "_example_file")
 	useCase := usecases.NewMockWalletOperationUsecase(ctrl)
 	useCase.EXPECT().GenerateReport(ctx, gomock.Any()).Return(&entities.FileMetadata{
-		File:        f,
-		Path:        "/a/b/c/" + f.Name(),
-		Size:        "100",
+		Name:        "report.json",
+		Content:     storedReport("[]\n"),
+		Size:        "3",
 		ContentType: "json",
 	}, nil).AnyTimes()
 	handler := NewOperationsHandler(useCase)
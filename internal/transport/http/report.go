@@ -0,0 +1,199 @@
+package http
+
+import (
+	"billing_system_test_task/internal/repositories/reports"
+	"billing_system_test_task/internal/transport/http/serializers"
+	"billing_system_test_task/internal/usecases"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// reportDownloadPath is the prefix of persisted reports' download path
+const reportDownloadPath = "/api/reports/files/"
+
+// ReportsHandler represents handler structure for the aggregated reports
+type ReportsHandler struct {
+	reportUseCase usecases.ReportUsecase
+}
+
+// NewReportsHandler returns controller instance
+func NewReportsHandler(reportUseCase usecases.ReportUsecase) *ReportsHandler {
+	return &ReportsHandler{
+		reportUseCase: reportUseCase,
+	}
+}
+
+// Summary godoc
+// @Summary Summary report
+// @Description Get totals of wallet operations by period, operation type, wallet or currency.
+// @Description Operation types are deposit, withdrawal and transfer; transfers are counted in both wallets.
+// @Tags reports
+// @Accept  json
+// @Produce json,application/x-ndjson,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,application/vnd.msgpack,application/gzip,application/zip,application/octet-stream
+// @Param format query string false "Report format (json, ndjson, csv, xlsx or msgpack)"
+// @Param period query string false "Aggregation period (day, week or month)"
+// @Param group_by query string false "Comma-separated grouping dimensions (operation, wallet, currency)"
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date, inclusive (YYYY-MM-DD)"
+// @Param amount_format query string false "Encoding of totals in json and msgpack reports (string or number)"
+// @Param columns query string false "Comma-separated list and order of csv columns"
+// @Param delimiter query string false "CSV delimiter (single character or 'tab')"
+// @Param header query bool false "Write csv header (default true)"
+// @Param decimal_separator query string false "Decimal separator of csv amounts ('.' or ',')"
+// @Param null_value query string false "Rendering of NULL values in csv"
+// @Param manifest query bool false "Return detached manifest of the report"
+// @Param persist query bool false "Keep the report in storage for later downloads"
+// @Param compress query string false "Compression of the report (gzip or zip)"
+// @Param encrypt query string false "AES-256-GCM encryption of the report (passphrase or key)"
+// @Param X-Report-Passphrase header string false "Passphrase of the report encryption (encrypt=passphrase)"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/reports/summary [get]
+// @Header 200 {string} Content-Type "Content type of the report format"
+// @Header 200 {string} Server-Timing "Duration and backpressure metrics of the report pipeline stages"
+// @Header 200 {string} Digest "SHA-256 checksum of the report (base64)"
+// @Header 200 {string} X-Report-Signature "Ed25519 signature of the report's checksum with key id"
+// @Header 200 {string} X-Report-Manifest "Base64 json manifest of the report (when manifest=true)"
+// @Header 200 {string} X-Report-Manifest-Signature "Ed25519 signature of the manifest's checksum with key id (when manifest=true)"
+// @Header 200 {string} Content-Location "Download path of the stored report (when persist=true)"
+func (rh *ReportsHandler) Summary(w http.ResponseWriter, r *http.Request) {
+	fileMetadata, gsErr := rh.reportUseCase.GenerateSummary(r.Context(), reportQuery(r))
+	if gsErr != nil {
+		JsonResponseError(w, gsErr.GetStatus(), gsErr.GetError().Error())
+		return
+	}
+	sendReportFile(w, r, fileMetadata)
+}
+
+// Users godoc
+// @Summary Users export
+// @Description Get list of users with their wallets and balances
+// @Tags reports
+// @Accept  json
+// @Produce json,application/x-ndjson,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,application/vnd.msgpack,application/gzip,application/zip,application/octet-stream
+// @Param format query string false "Report format (json, ndjson, csv, xlsx or msgpack)"
+// @Param amount_format query string false "Encoding of amounts in json and msgpack reports (string or number)"
+// @Param columns query string false "Comma-separated list and order of csv columns"
+// @Param delimiter query string false "CSV delimiter (single character or 'tab')"
+// @Param header query bool false "Write csv header (default true)"
+// @Param decimal_separator query string false "Decimal separator of csv amounts ('.' or ',')"
+// @Param null_value query string false "Rendering of NULL values in csv"
+// @Param manifest query bool false "Return detached manifest of the report"
+// @Param persist query bool false "Keep the report in storage for later downloads"
+// @Param compress query string false "Compression of the report (gzip or zip)"
+// @Param encrypt query string false "AES-256-GCM encryption of the report (passphrase or key)"
+// @Param X-Report-Passphrase header string false "Passphrase of the report encryption (encrypt=passphrase)"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/reports/users [get]
+// @Header 200 {string} Content-Type "Content type of the report format"
+// @Header 200 {string} Server-Timing "Duration and backpressure metrics of the report pipeline stages"
+// @Header 200 {string} Digest "SHA-256 checksum of the report (base64)"
+// @Header 200 {string} X-Report-Signature "Ed25519 signature of the report's checksum with key id"
+// @Header 200 {string} X-Report-Manifest "Base64 json manifest of the report (when manifest=true)"
+// @Header 200 {string} X-Report-Manifest-Signature "Ed25519 signature of the manifest's checksum with key id (when manifest=true)"
+// @Header 200 {string} Content-Location "Download path of the stored report (when persist=true)"
+func (rh *ReportsHandler) Users(w http.ResponseWriter, r *http.Request) {
+	rh.sendExport(w, r, reports.ExportUsers)
+}
+
+// Balances godoc
+// @Summary Balances export
+// @Description Get balance sheet per currency: number of wallets and total balance
+// @Tags reports
+// @Accept  json
+// @Produce json,application/x-ndjson,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,application/vnd.msgpack,application/gzip,application/zip,application/octet-stream
+// @Param format query string false "Report format (json, ndjson, csv, xlsx or msgpack)"
+// @Param amount_format query string false "Encoding of amounts in json and msgpack reports (string or number)"
+// @Param columns query string false "Comma-separated list and order of csv columns"
+// @Param delimiter query string false "CSV delimiter (single character or 'tab')"
+// @Param header query bool false "Write csv header (default true)"
+// @Param decimal_separator query string false "Decimal separator of csv amounts ('.' or ',')"
+// @Param null_value query string false "Rendering of NULL values in csv"
+// @Param manifest query bool false "Return detached manifest of the report"
+// @Param persist query bool false "Keep the report in storage for later downloads"
+// @Param compress query string false "Compression of the report (gzip or zip)"
+// @Param encrypt query string false "AES-256-GCM encryption of the report (passphrase or key)"
+// @Param X-Report-Passphrase header string false "Passphrase of the report encryption (encrypt=passphrase)"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/reports/balances [get]
+// @Header 200 {string} Content-Type "Content type of the report format"
+// @Header 200 {string} Server-Timing "Duration and backpressure metrics of the report pipeline stages"
+// @Header 200 {string} Digest "SHA-256 checksum of the report (base64)"
+// @Header 200 {string} X-Report-Signature "Ed25519 signature of the report's checksum with key id"
+// @Header 200 {string} X-Report-Manifest "Base64 json manifest of the report (when manifest=true)"
+// @Header 200 {string} X-Report-Manifest-Signature "Ed25519 signature of the manifest's checksum with key id (when manifest=true)"
+// @Header 200 {string} Content-Location "Download path of the stored report (when persist=true)"
+func (rh *ReportsHandler) Balances(w http.ResponseWriter, r *http.Request) {
+	rh.sendExport(w, r, reports.ExportBalances)
+}
+
+// sendExport generates export and sends it as report file
+func (rh *ReportsHandler) sendExport(w http.ResponseWriter, r *http.Request, export string) {
+	fileMetadata, geErr := rh.reportUseCase.GenerateExport(r.Context(), export, reportQuery(r))
+	if geErr != nil {
+		JsonResponseError(w, geErr.GetStatus(), geErr.GetError().Error())
+		return
+	}
+	sendReportFile(w, r, fileMetadata)
+}
+
+// PublicKey godoc
+// @Summary Reports public key
+// @Description Get Ed25519 public key for verification of reports signatures
+// @Tags reports
+// @Produce json
+// @Success 200 {object} serializers.ReportPublicKeySerializer
+// @Failure 404 {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/reports/public-key [get]
+func (rh *ReportsHandler) PublicKey(w http.ResponseWriter, r *http.Request) {
+	publicKey, pkErr := rh.reportUseCase.PublicKey()
+	if pkErr != nil {
+		JsonResponseError(w, pkErr.GetStatus(), pkErr.GetError().Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(serializers.ReportPublicKeySerializer{
+		KeyID:     publicKey.KeyID,
+		Algorithm: publicKey.Algorithm,
+		PublicKey: base64.StdEncoding.EncodeToString(publicKey.PublicKey),
+	})
+}
+
+// Download godoc
+// @Summary Download report
+// @Description Download report persisted with persist=true
+// @Tags reports
+// @Produce octet-stream
+// @Param name path string true "Report name"
+// @Success 200 {file} file
+// @Success 206 {file} file
+// @Failure 400 {object} ErrorMsg
+// @Failure 404 {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/reports/files/{name} [get]
+// @Header 200 {string} Content-Type "Content type of the report format"
+// @Header 200 {string} Digest "SHA-256 checksum of the report (base64)"
+// @Header 200 {string} X-Report-Signature "Ed25519 signature of the report's checksum with key id"
+func (rh *ReportsHandler) Download(w http.ResponseWriter, r *http.Request) {
+	fileMetadata, dErr := rh.reportUseCase.Download(r.Context(), mux.Vars(r)["name"])
+	if dErr != nil {
+		JsonResponseError(w, dErr.GetStatus(), dErr.GetError().Error())
+		return
+	}
+	sendReportFile(w, r, fileMetadata)
+}
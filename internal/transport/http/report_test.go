@@ -0,0 +1,264 @@
+package http
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/repositories/reports"
+	"billing_system_test_task/internal/usecases"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+)
+
+// storedReport returns content of report saved to memory storage
+func storedReport(data string) entities.ReportFile {
+	storage := reports.NewMemoryStorage()
+	w, _ := storage.Create("report")
+	_, _ = w.Write([]byte(data))
+	_ = w.Close()
+	report, _ := storage.Open("report")
+	return report
+}
+
+// Test summary report endpoint
+func TestReportsHandlerSummary(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		mockData       func(reportUseCase *usecases.MockReportUsecase)
+		expectedStatus int
+		contentType    string
+		errMsg         string
+	}{
+		{
+			name: "Success summary receiving",
+			url:  "/api/reports/summary?period=month&group_by=currency&format=csv",
+			mockData: func(reportUseCase *usecases.MockReportUsecase) {
+				reportUseCase.EXPECT().GenerateSummary(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx interface{}, query map[string][]string) (*entities.FileMetadata, adapters.Error) {
+					if query["period"][0] != "month" || query["group_by"][0] != "currency" {
+						t.Errorf("Query parameters are not passed to use case: %v", query)
+					}
+					return &entities.FileMetadata{
+						Name:        "report.csv",
+						Content:     storedReport("period,currency,count,total\n"),
+						Size:        "28",
+						ContentType: "text/csv; charset=utf-8",
+					}, nil
+				})
+			},
+			expectedStatus: 200,
+			contentType:    "text/csv; charset=utf-8",
+		},
+		{
+			name: "Failed summary receiving",
+			url:  "/api/reports/summary?period=year",
+			mockData: func(reportUseCase *usecases.MockReportUsecase) {
+				reportUseCase.EXPECT().GenerateSummary(gomock.Any(), gomock.Any()).Return(nil, adapters.NewHTTPError(400, fmt.Errorf("unsupported 'period' value: year")))
+			},
+			expectedStatus: 400,
+			errMsg:         "unsupported 'period' value: year",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			reportUseCase := usecases.NewMockReportUsecase(ctrl)
+			tc.mockData(reportUseCase)
+			r := mux.NewRouter()
+			r.HandleFunc("/api/reports/summary", NewReportsHandler(reportUseCase).Summary).Methods("GET")
+
+			req, _ := http.NewRequest("GET", tc.url, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			resp := w.Result()
+			if resp.StatusCode != tc.expectedStatus {
+				t.Errorf("Expected response code %d. Got %d", tc.expectedStatus, resp.StatusCode)
+			}
+			if tc.contentType != "" && resp.Header.Get("Content-Type") != tc.contentType {
+				t.Errorf("Expected content type %s. Got %s", tc.contentType, resp.Header.Get("Content-Type"))
+			}
+			if tc.errMsg != "" {
+				errors := make(map[string]string)
+				respBody, _ := ioutil.ReadAll(resp.Body)
+				if umErr := json.Unmarshal(respBody, &errors); umErr != nil {
+					t.Errorf("Unexpected unmarshalling error: %s", umErr)
+				}
+				if errors["message"] != tc.errMsg {
+					t.Errorf("Expect error message '%s'; Got '%s'", tc.errMsg, errors["message"])
+				}
+			}
+		})
+	}
+}
+
+// Test users and balances export endpoints
+func TestReportsHandlerExports(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reportUseCase := usecases.NewMockReportUsecase(ctrl)
+	reportUseCase.EXPECT().GenerateExport(gomock.Any(), reports.ExportUsers, gomock.Any()).Return(&entities.FileMetadata{
+		Name:        "report.csv",
+		Content:     storedReport("id,email,wallet_id,currency,balance\n"),
+		Size:        "35",
+		ContentType: "text/csv; charset=utf-8",
+	}, nil)
+	reportUseCase.EXPECT().GenerateExport(gomock.Any(), reports.ExportBalances, gomock.Any()).DoAndReturn(func(ctx interface{}, export string, query map[string][]string) (*entities.FileMetadata, adapters.Error) {
+		return nil, adapters.NewHTTPError(400, fmt.Errorf("unsupported format of %s export: %s", export, query["format"][0]))
+	})
+	r := mux.NewRouter()
+	reportsHandler := NewReportsHandler(reportUseCase)
+	r.HandleFunc("/api/reports/users", reportsHandler.Users).Methods("GET")
+	r.HandleFunc("/api/reports/balances", reportsHandler.Balances).Methods("GET")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/reports/users?format=csv", nil))
+	if resp := w.Result(); resp.StatusCode != 200 || resp.Header.Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Errorf("Wrong users export response: %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/reports/balances?format=qif", nil))
+	errors := make(map[string]string)
+	_ = json.Unmarshal(w.Body.Bytes(), &errors)
+	if w.Code != 400 || errors["message"] != "unsupported format of balances export: qif" {
+		t.Errorf("Wrong balances export response: %d %v", w.Code, errors)
+	}
+}
+
+// Test checksum, signature and manifest headers of report
+func TestSendReportFileSignatureHeaders(t *testing.T) {
+	metadata := &entities.FileMetadata{
+		Name:        "report-1.json",
+		Content:     storedReport("[]\n"),
+		Persisted:   true,
+		Size:        "3",
+		ContentType: "application/json",
+		SHA256:      []byte{0xab, 0xcd},
+		Signature:   []byte{1, 2, 3},
+		KeyID:       "0102030405060708",
+		Manifest:    &entities.ReportManifest{Format: "json", Rows: 0, SHA256: "abcd"},
+	}
+	metadata.ManifestData, _ = json.Marshal(metadata.Manifest)
+	metadata.ManifestSignature = []byte{4, 5, 6}
+	req, _ := http.NewRequest("GET", "/api/reports/summary", nil)
+	w := httptest.NewRecorder()
+	sendReportFile(w, req, metadata)
+
+	resp := w.Result()
+	if resp.Header.Get("Content-Disposition") != `attachment; filename="report-1.json"` {
+		t.Errorf("Wrong Content-Disposition header: %s", resp.Header.Get("Content-Disposition"))
+	}
+	if resp.Header.Get("Content-Location") != "/api/reports/files/report-1.json" || resp.Header.Get("Content-Length") != "3" {
+		t.Errorf("Wrong Content-Location or Content-Length header: %v", resp.Header)
+	}
+	if resp.Header.Get("Digest") != "SHA-256=q80=" {
+		t.Errorf("Wrong Digest header: %s", resp.Header.Get("Digest"))
+	}
+	expectedSignature := `keyId="0102030405060708", algorithm="ed25519", signature="AQID"`
+	if resp.Header.Get("X-Report-Signature") != expectedSignature {
+		t.Errorf("Wrong X-Report-Signature header: %s", resp.Header.Get("X-Report-Signature"))
+	}
+	manifestJSON, decodeErr := base64.StdEncoding.DecodeString(resp.Header.Get("X-Report-Manifest"))
+	if decodeErr != nil {
+		t.Fatalf("Unexpected decoding error: %s", decodeErr)
+	}
+	manifest := entities.ReportManifest{}
+	if umErr := json.Unmarshal(manifestJSON, &manifest); umErr != nil || manifest.SHA256 != "abcd" {
+		t.Errorf("Wrong manifest: %s", manifestJSON)
+	}
+	expectedManifestSignature := `keyId="0102030405060708", algorithm="ed25519", signature="BAUG"`
+	if resp.Header.Get("X-Report-Manifest-Signature") != expectedManifestSignature {
+		t.Errorf("Wrong X-Report-Manifest-Signature header: %s", resp.Header.Get("X-Report-Manifest-Signature"))
+	}
+}
+
+// Test public key endpoint
+func TestReportsHandlerPublicKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reportUseCase := usecases.NewMockReportUsecase(ctrl)
+	reportUseCase.EXPECT().PublicKey().Return(&entities.ReportPublicKey{
+		KeyID:     "0102030405060708",
+		Algorithm: "ed25519",
+		PublicKey: []byte{1, 2, 3},
+	}, nil)
+	reportUseCase.EXPECT().PublicKey().Return(nil, adapters.NewHTTPError(404, fmt.Errorf("reports signing is not configured")))
+	handler := NewReportsHandler(reportUseCase)
+
+	w := httptest.NewRecorder()
+	handler.PublicKey(w, httptest.NewRequest("GET", "/api/reports/public-key", nil))
+	body := map[string]string{}
+	_ = json.NewDecoder(w.Result().Body).Decode(&body)
+	if w.Code != 200 || body["key_id"] != "0102030405060708" || body["algorithm"] != "ed25519" || body["public_key"] != "AQID" {
+		t.Errorf("Wrong public key response %d: %v", w.Code, body)
+	}
+
+	w = httptest.NewRecorder()
+	handler.PublicKey(w, httptest.NewRequest("GET", "/api/reports/public-key", nil))
+	if w.Code != 404 {
+		t.Errorf("Expected response code 404. Got %d", w.Code)
+	}
+}
+
+// Test download of persisted report
+func TestReportsHandlerDownload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reportUseCase := usecases.NewMockReportUsecase(ctrl)
+	reportUseCase.EXPECT().Download(gomock.Any(), "report-1.csv").Return(&entities.FileMetadata{
+		Name:        "report-1.csv",
+		Content:     storedReport("id,amount\n1,10.00\n"),
+		Persisted:   true,
+		Size:        "19",
+		ContentType: "text/csv; charset=utf-8",
+	}, nil).Times(2)
+	reportUseCase.EXPECT().Download(gomock.Any(), "report-2.csv").Return(nil, adapters.NewHTTPError(404, reports.ErrReportNotFound))
+	r := mux.NewRouter()
+	r.HandleFunc("/api/reports/files/{name}", NewReportsHandler(reportUseCase).Download).Methods("GET")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/reports/files/report-1.csv", nil))
+	if w.Code != 200 || w.Body.String() != "id,amount\n1,10.00\n" || w.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Errorf("Wrong download response %d: %q", w.Code, w.Body.String())
+	}
+
+	// Range requests are supported by storage's content
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/reports/files/report-1.csv", nil)
+	req.Header.Set("Range", "bytes=10-")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusPartialContent || w.Body.String() != "1,10.00\n" {
+		t.Errorf("Wrong range response %d: %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/reports/files/report-2.csv", nil))
+	if w.Code != 404 || !strings.Contains(w.Body.String(), "report not found") {
+		t.Errorf("Expected response code 404. Got %d", w.Code)
+	}
+}
+
+// Test encryption passphrase is passed from header and not from URL
+func TestReportQueryPassphrase(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/reports/summary?encrypt=passphrase&passphrase=leaked", nil)
+	if query := reportQuery(req); query.Get("passphrase") != "" || query.Get("encrypt") != "passphrase" {
+		t.Errorf("Passphrase from URL is accepted: %v", query)
+	}
+	req.Header.Set("X-Report-Passphrase", "secret")
+	if query := reportQuery(req); query.Get("passphrase") != "secret" {
+		t.Errorf("Passphrase from header is not passed: %v", query)
+	}
+}
@@ -0,0 +1,14 @@
+package serializers
+
+import "time"
+
+// APIKeySerializer serializes API key; the key itself is returned only on creation
+type APIKeySerializer struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Key       string    `json:"key,omitempty"`
+	Prefix    string    `json:"prefix"`
+	UserID    *int      `json:"user_id"`
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,12 @@
+package serializers
+
+import "time"
+
+// FeedConsumerSerializer serializes acknowledged position of feed consumer
+type FeedConsumerSerializer struct {
+	Name            string     `json:"name"`
+	Cursor          string     `json:"cursor"`
+	LastOperationID int        `json:"last_operation_id"`
+	LastCreatedAt   *time.Time `json:"last_created_at"`
+	AckedAt         *time.Time `json:"acked_at"`
+}
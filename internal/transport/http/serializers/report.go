@@ -0,0 +1,20 @@
+package serializers
+
+import "time"
+
+// ReportPublicKeySerializer serializes public key of reports signatures to json
+type ReportPublicKeySerializer struct {
+	KeyID     string `json:"key_id"`
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key"`
+}
+
+// ReportTemplateSerializer serializes report template to json
+type ReportTemplateSerializer struct {
+	Name      string    `json:"name"`
+	Header    string    `json:"header"`
+	Row       string    `json:"row"`
+	Footer    string    `json:"footer"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -1,6 +1,29 @@
 package serializers
 
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
 // walletSerializer serializes data to json
 type WalletSerializer struct {
 	WalletFrom int `json:"wallet_from"`
 }
+
+// WalletBalanceSerializer serializes balance event of wallet's stream
+type WalletBalanceSerializer struct {
+	WalletID int             `json:"wallet_id"`
+	Balance  decimal.Decimal `json:"balance"`
+	Currency string          `json:"currency"`
+}
+
+// WalletOperationSerializer serializes operation event of wallet's stream
+type WalletOperationSerializer struct {
+	ID         int             `json:"id"`
+	Operation  string          `json:"operation"`
+	WalletFrom *int            `json:"wallet_from"`
+	WalletTo   int             `json:"wallet_to"`
+	Amount     decimal.Decimal `json:"amount"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
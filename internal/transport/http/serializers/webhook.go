@@ -0,0 +1,26 @@
+package serializers
+
+import "time"
+
+// WebhookSerializer serializes webhook subscription; the secret is returned only on creation
+type WebhookSerializer struct {
+	ID         int       `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret,omitempty"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookAttemptSerializer serializes record of delivery log
+type WebhookAttemptSerializer struct {
+	ID         int       `json:"id"`
+	DeliveryID int       `json:"delivery_id"`
+	EventID    int       `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
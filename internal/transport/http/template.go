@@ -0,0 +1,152 @@
+package http
+
+import (
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/transport/http/forms"
+	"billing_system_test_task/internal/transport/http/serializers"
+	"billing_system_test_task/internal/usecases"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// TemplatesHandler represents handler structure for the user-defined report templates
+type TemplatesHandler struct {
+	templateUseCase usecases.ReportTemplateUsecase
+}
+
+// NewTemplatesHandler returns controller instance
+func NewTemplatesHandler(templateUseCase usecases.ReportTemplateUsecase) *TemplatesHandler {
+	return &TemplatesHandler{
+		templateUseCase: templateUseCase,
+	}
+}
+
+// Save godoc
+// @Summary Save report template
+// @Description Create or replace text/template layout of operations report, used with format=template&template={name}.
+// @Description Row section gets .ID, .Operation, .WalletFrom (0 when absent), .WalletTo, .Amount and .CreatedAt;
+// @Description header and footer get .GeneratedAt, .Rows and .Total (zero in header).
+// @Description Helpers: money, neg, date, upper, lower, replace, pad, lpad and csv. Range and nested templates are not allowed,
+// @Description printf width and precision must be literal numbers up to 65536.
+// @Tags reports
+// @Accept  json
+// @Produce  json
+// @Param name path string true "Template name (letters, digits, '_', '.', '-')"
+// @Param template body forms.ReportTemplateForm true "Template sections"
+// @Success 200 {object} serializers.ReportTemplateSerializer "Saved template"
+// @Failure 400 {object} FormErrorSerializer "Template validation error"
+// @Failure default {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/report-templates/{name} [put]
+func (th *TemplatesHandler) Save(w http.ResponseWriter, r *http.Request) {
+	var templateForm forms.ReportTemplateForm
+	decoder := json.NewDecoder(r.Body)
+	if decodeErr := decoder.Decode(&templateForm); decodeErr != nil {
+		JsonResponseError(w, http.StatusBadRequest, fmt.Sprintf("Error json form decoding: %s", decodeErr))
+		return
+	}
+
+	// Validate body parameters
+	if formError := templateForm.Submit(); formError != nil {
+		log.Println(fmt.Sprintf("[ERROR] Report template error - %s", *formError))
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(FormErrorSerializer{Messages: *formError})
+		return
+	}
+
+	saved, saveErr := th.templateUseCase.Save(r.Context(), &entities.ReportTemplate{
+		Name:   mux.Vars(r)["name"],
+		Header: templateForm.Header,
+		Row:    templateForm.Row,
+		Footer: templateForm.Footer,
+	})
+	if saveErr != nil {
+		JsonResponseError(w, saveErr.GetStatus(), fmt.Sprintf("Error of report template saving: %s", saveErr.GetError()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(newReportTemplateSerializer(saved))
+}
+
+// Get godoc
+// @Summary Report template
+// @Description Get report template by name
+// @Tags reports
+// @Produce  json
+// @Param name path string true "Template name"
+// @Success 200 {object} serializers.ReportTemplateSerializer
+// @Failure 404 {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/report-templates/{name} [get]
+func (th *TemplatesHandler) Get(w http.ResponseWriter, r *http.Request) {
+	reportTemplate, getErr := th.templateUseCase.Get(r.Context(), mux.Vars(r)["name"])
+	if getErr != nil {
+		JsonResponseError(w, getErr.GetStatus(), getErr.GetError().Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(newReportTemplateSerializer(reportTemplate))
+}
+
+// List godoc
+// @Summary Report templates
+// @Description Get all report templates
+// @Tags reports
+// @Produce  json
+// @Success 200 {array} serializers.ReportTemplateSerializer
+// @Failure default {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/report-templates/ [get]
+func (th *TemplatesHandler) List(w http.ResponseWriter, r *http.Request) {
+	templates, listErr := th.templateUseCase.List(r.Context())
+	if listErr != nil {
+		JsonResponseError(w, listErr.GetStatus(), listErr.GetError().Error())
+		return
+	}
+	serialized := make([]serializers.ReportTemplateSerializer, 0, len(templates))
+	for _, reportTemplate := range templates {
+		serialized = append(serialized, newReportTemplateSerializer(reportTemplate))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(serialized)
+}
+
+// Delete godoc
+// @Summary Delete report template
+// @Description Delete report template by name
+// @Tags reports
+// @Param name path string true "Template name"
+// @Success 204 {string} string "Template is deleted"
+// @Failure 404 {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/report-templates/{name} [delete]
+func (th *TemplatesHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if deleteErr := th.templateUseCase.Delete(r.Context(), mux.Vars(r)["name"]); deleteErr != nil {
+		JsonResponseError(w, deleteErr.GetStatus(), deleteErr.GetError().Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// newReportTemplateSerializer returns serialized report template
+func newReportTemplateSerializer(reportTemplate *entities.ReportTemplate) serializers.ReportTemplateSerializer {
+	return serializers.ReportTemplateSerializer{
+		Name:      reportTemplate.Name,
+		Header:    reportTemplate.Header,
+		Row:       reportTemplate.Row,
+		Footer:    reportTemplate.Footer,
+		CreatedAt: reportTemplate.CreatedAt,
+		UpdatedAt: reportTemplate.UpdatedAt,
+	}
+}
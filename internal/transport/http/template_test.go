@@ -0,0 +1,133 @@
+package http
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/usecases"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+)
+
+// newTemplatesRouter returns router with report templates endpoints
+func newTemplatesRouter(templateUseCase usecases.ReportTemplateUsecase) *mux.Router {
+	r := mux.NewRouter()
+	handler := NewTemplatesHandler(templateUseCase)
+	r.HandleFunc("/api/report-templates/", handler.List).Methods("GET")
+	r.HandleFunc("/api/report-templates/{name}", handler.Get).Methods("GET")
+	r.HandleFunc("/api/report-templates/{name}", handler.Save).Methods("PUT")
+	r.HandleFunc("/api/report-templates/{name}", handler.Delete).Methods("DELETE")
+	return r
+}
+
+// Test report templates endpoints
+func TestTemplatesHandler(t *testing.T) {
+	now := time.Date(2022, time.October, 25, 12, 0, 0, 0, time.UTC)
+	partner := &entities.ReportTemplate{Name: "partner", Row: "{{.ID}}\n", CreatedAt: now, UpdatedAt: now}
+	serialized := `{"name":"partner","header":"","row":"{{.ID}}\n","footer":"","created_at":"2022-10-25T12:00:00Z","updated_at":"2022-10-25T12:00:00Z"}`
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		mockData       func(templateUseCase *usecases.MockReportTemplateUsecase)
+		expectedStatus int
+		expected       string
+	}{
+		{
+			name:   "Success template saving",
+			method: "PUT",
+			url:    "/api/report-templates/partner",
+			body:   `{"row": "{{.ID}}\n"}`,
+			mockData: func(templateUseCase *usecases.MockReportTemplateUsecase) {
+				templateUseCase.EXPECT().Save(gomock.Any(), &entities.ReportTemplate{Name: "partner", Row: "{{.ID}}\n"}).Return(partner, nil)
+			},
+			expectedStatus: 200,
+			expected:       serialized,
+		},
+		{
+			name:           "Missing row section",
+			method:         "PUT",
+			url:            "/api/report-templates/partner",
+			body:           `{"header": "id"}`,
+			mockData:       func(templateUseCase *usecases.MockReportTemplateUsecase) {},
+			expectedStatus: 400,
+			expected:       `"row"`,
+		},
+		{
+			name:   "Invalid template",
+			method: "PUT",
+			url:    "/api/report-templates/partner",
+			body:   `{"row": "{{range .ID}}{{end}}"}`,
+			mockData: func(templateUseCase *usecases.MockReportTemplateUsecase) {
+				templateUseCase.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil, adapters.NewHTTPError(400, fmt.Errorf("error of row section parsing: range action is not allowed")))
+			},
+			expectedStatus: 400,
+			expected:       "Error of report template saving: error of row section parsing: range action is not allowed",
+		},
+		{
+			name:   "Success template receiving",
+			method: "GET",
+			url:    "/api/report-templates/partner",
+			mockData: func(templateUseCase *usecases.MockReportTemplateUsecase) {
+				templateUseCase.EXPECT().Get(gomock.Any(), "partner").Return(partner, nil)
+			},
+			expectedStatus: 200,
+			expected:       serialized,
+		},
+		{
+			name:   "Success templates list",
+			method: "GET",
+			url:    "/api/report-templates/",
+			mockData: func(templateUseCase *usecases.MockReportTemplateUsecase) {
+				templateUseCase.EXPECT().List(gomock.Any()).Return([]*entities.ReportTemplate{partner}, nil)
+			},
+			expectedStatus: 200,
+			expected:       "[" + serialized + "]",
+		},
+		{
+			name:   "Deleting of unknown template",
+			method: "DELETE",
+			url:    "/api/report-templates/unknown",
+			mockData: func(templateUseCase *usecases.MockReportTemplateUsecase) {
+				templateUseCase.EXPECT().Delete(gomock.Any(), "unknown").Return(adapters.NewHTTPError(404, fmt.Errorf("report template is not found")))
+			},
+			expectedStatus: 404,
+			expected:       "report template is not found",
+		},
+		{
+			name:   "Success template deleting",
+			method: "DELETE",
+			url:    "/api/report-templates/partner",
+			mockData: func(templateUseCase *usecases.MockReportTemplateUsecase) {
+				templateUseCase.EXPECT().Delete(gomock.Any(), "partner").Return(nil)
+			},
+			expectedStatus: 204,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			templateUseCase := usecases.NewMockReportTemplateUsecase(ctrl)
+			tc.mockData(templateUseCase)
+
+			w := httptest.NewRecorder()
+			newTemplatesRouter(templateUseCase).ServeHTTP(w, httptest.NewRequest(tc.method, tc.url, strings.NewReader(tc.body)))
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body)
+			}
+			if tc.expectedStatus == 200 && strings.TrimSpace(w.Body.String()) != tc.expected {
+				t.Errorf("Wrong response: %s", w.Body)
+			}
+			if !strings.Contains(w.Body.String(), tc.expected) {
+				t.Errorf("Wrong response: %s", w.Body)
+			}
+		})
+	}
+}
@@ -39,6 +39,8 @@ func NewUserHandler(userUseCase usecases.UserUseCase) *UsersHandler {
 // @Success 201 {object} serializers.UserSerializer "Create user response"
 // @Failure 400 {object} FormErrorSerializer "User form validation error"
 // @Failure default {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /api/users/ [post]
 func (uh *UsersHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var (
@@ -88,6 +90,8 @@ func (uh *UsersHandler) Create(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} serializers.UserSerializer "Retrieving user information with updated balance"
 // @Failure 400 {object} FormErrorSerializer "Enroll form validation error"
 // @Failure default {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /api/users/{id}/enroll/ [post]
 func (uh *UsersHandler) Enroll(w http.ResponseWriter, r *http.Request) {
 	var (
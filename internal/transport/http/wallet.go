@@ -32,6 +32,8 @@ func NewWalletsHandler(walletUseCase usecases.WalletUseCase) *WalletsHandler {
 // @Success 200 {object} serializers.WalletSerializer "Wallet from id"
 // @Failure 400 {object} FormErrorSerializer "Wallet transfer validation error"
 // @Failure default {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /api/wallets/transfer/ [post]
 func (wh *WalletsHandler) Transfer(w http.ResponseWriter, r *http.Request) {
 	var (
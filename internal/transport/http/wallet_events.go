@@ -0,0 +1,210 @@
+package http
+
+import (
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/transport/http/serializers"
+	"billing_system_test_task/internal/usecases"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+)
+
+// streamRetry is reconnection delay of SSE clients in milliseconds
+const streamRetry = 3000
+
+// connKey is context key of client's connection
+type connKey struct{}
+
+// ConnContext puts connection into context of its requests, so streams extend write deadline of the server;
+// it is used as http.Server.ConnContext
+func ConnContext(ctx context.Context, conn net.Conn) context.Context {
+	return context.WithValue(ctx, connKey{}, conn)
+}
+
+// WalletEventsHandler represents handler structure for the live updates of wallets
+type WalletEventsHandler struct {
+	walletEventsUseCase usecases.WalletEventsUsecase
+	heartbeat           time.Duration
+	done                chan struct{}
+	closeOnce           sync.Once
+}
+
+// NewWalletEventsHandler returns controller instance; streams send heartbeats with the interval
+func NewWalletEventsHandler(walletEventsUseCase usecases.WalletEventsUsecase, heartbeat time.Duration) *WalletEventsHandler {
+	return &WalletEventsHandler{
+		walletEventsUseCase: walletEventsUseCase,
+		heartbeat:           heartbeat,
+		done:                make(chan struct{}),
+	}
+}
+
+// Close ends open streams, so the server shuts down without waiting for clients; it is registered by http.Server.RegisterOnShutdown
+func (weh *WalletEventsHandler) Close() {
+	weh.closeOnce.Do(func() { close(weh.done) })
+}
+
+// Stream godoc
+// @Summary Wallet events
+// @Description Server-Sent Events stream of wallet's changes. "balance" event is sent on connection and when balance changes,
+// @Description "operation" event is sent for each new operation of the wallet, its id is position which resumes
+// @Description the stream in Last-Event-ID header. Comments are sent as heartbeats.
+// @Tags wallets
+// @Produce  text/event-stream
+// @Param id path int true "Wallet ID"
+// @Param Last-Event-ID header string false "Id of the last received operation event"
+// @Success 200 {string} string "Stream of events"
+// @Failure 400 {object} ErrorMsg
+// @Failure 403 {object} ErrorMsg
+// @Failure 404 {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/wallets/{id}/events [get]
+func (weh *WalletEventsHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	walletID, convErr := strconv.Atoi(mux.Vars(r)["id"])
+	if convErr != nil {
+		JsonResponseError(w, http.StatusBadRequest, fmt.Sprintf("Error formatting wallet id to int: %s", convErr))
+		return
+	}
+	flusher, isFlusher := w.(http.Flusher)
+	if !isFlusher {
+		JsonResponseError(w, http.StatusInternalServerError, "Streaming is not supported")
+		return
+	}
+
+	// Subscription precedes reading of wallet, so changes are not missed between them
+	wakeUps, cancel := weh.walletEventsUseCase.Subscribe(walletID)
+	defer cancel()
+	wallet, position, openErr := weh.walletEventsUseCase.Open(ctx, walletID, r.Header.Get("Last-Event-ID"))
+	if openErr != nil {
+		JsonResponseError(w, openErr.GetStatus(), openErr.GetError().Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	stream := &eventStream{w: w, flusher: flusher, conn: connFromContext(ctx), timeout: 2 * weh.heartbeat}
+	if writeErr := stream.write(fmt.Sprintf("retry: %d\n\n", streamRetry)); writeErr != nil {
+		return
+	}
+	if writeErr := stream.balance(wallet); writeErr != nil {
+		return
+	}
+	balance := wallet.Balance
+
+	heartbeat := time.NewTicker(weh.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		// Operations are read on connection to resume stream, on wake-ups and on heartbeats,
+		// because operations become visible after older transactions finish
+		var updateErr error
+		position, balance, updateErr = weh.update(ctx, stream, walletID, position, balance)
+		if updateErr != nil {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-weh.done:
+			return
+		case <-wakeUps:
+		case <-heartbeat.C:
+			if writeErr := stream.write(": heartbeat\n\n"); writeErr != nil {
+				return
+			}
+		}
+	}
+}
+
+// update sends new operations and changed balance; it returns position and balance sent to client
+func (weh *WalletEventsHandler) update(ctx context.Context, stream *eventStream, walletID int, position entities.FeedPosition, balance decimal.Decimal) (entities.FeedPosition, decimal.Decimal, error) {
+	wallet, operations, updatesErr := weh.walletEventsUseCase.Updates(ctx, walletID, position)
+	if updatesErr != nil {
+		_ = stream.event("", "error", ErrorMsg{Message: updatesErr.GetError().Error()})
+		return position, balance, updatesErr.GetError()
+	}
+	for _, operation := range operations {
+		if writeErr := stream.operation(operation); writeErr != nil {
+			return position, balance, writeErr
+		}
+		position = operation.Position
+	}
+	if !wallet.Balance.Equal(balance) {
+		if writeErr := stream.balance(wallet); writeErr != nil {
+			return position, balance, writeErr
+		}
+	}
+	return position, wallet.Balance, nil
+}
+
+// eventStream writes Server-Sent Events
+type eventStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	conn    net.Conn // nil when connection is unknown, e.g. in tests
+	timeout time.Duration
+}
+
+func (es *eventStream) balance(wallet *entities.Wallet) error {
+	return es.event("", "balance", serializers.WalletBalanceSerializer{
+		WalletID: wallet.ID,
+		Balance:  wallet.Balance,
+		Currency: wallet.Currency,
+	})
+}
+
+func (es *eventStream) operation(operation *entities.FeedOperation) error {
+	serialized := serializers.WalletOperationSerializer{
+		ID:        operation.ID,
+		Operation: operation.Operation,
+		WalletTo:  operation.WalletTo,
+		Amount:    operation.Amount,
+		CreatedAt: operation.CreatedAt,
+	}
+	if operation.WalletFrom.Valid {
+		walletFrom := int(operation.WalletFrom.Int32)
+		serialized.WalletFrom = &walletFrom
+	}
+	return es.event(operation.Position.String(), "operation", serialized)
+}
+
+func (es *eventStream) event(id, name string, data interface{}) error {
+	encoded, marshalErr := json.Marshal(data)
+	if marshalErr != nil {
+		return marshalErr
+	}
+	message := fmt.Sprintf("event: %s\ndata: %s\n\n", name, encoded)
+	if id != "" {
+		message = fmt.Sprintf("id: %s\n", id) + message
+	}
+	return es.write(message)
+}
+
+// write sends message; write deadline of the server is extended, so stream is closed only when client does not read it
+func (es *eventStream) write(message string) error {
+	if es.conn != nil {
+		_ = es.conn.SetWriteDeadline(time.Now().Add(es.timeout))
+	}
+	if _, writeErr := es.w.Write([]byte(message)); writeErr != nil {
+		return writeErr
+	}
+	es.flusher.Flush()
+	return nil
+}
+
+func connFromContext(ctx context.Context) net.Conn {
+	conn, _ := ctx.Value(connKey{}).(net.Conn)
+	return conn
+}
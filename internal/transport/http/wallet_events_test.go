@@ -0,0 +1,143 @@
+package http
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/usecases"
+	"bufio"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+)
+
+// newWalletEventsServer returns server with wallet events endpoint
+func newWalletEventsServer(walletEventsUseCase usecases.WalletEventsUsecase, heartbeat time.Duration) *httptest.Server {
+	r := mux.NewRouter()
+	r.HandleFunc("/api/wallets/{id}/events", NewWalletEventsHandler(walletEventsUseCase, heartbeat).Stream).Methods("GET")
+	server := httptest.NewUnstartedServer(r)
+	server.Config.ConnContext = ConnContext
+	server.Start()
+	return server
+}
+
+// readEvent returns the next event of stream without trailing empty line
+func readEvent(t *testing.T, reader *bufio.Reader) string {
+	lines := []string{}
+	for {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil {
+			t.Fatalf("Error of stream reading: %s", readErr)
+		}
+		if line == "\n" {
+			return strings.Join(lines, "")
+		}
+		lines = append(lines, line)
+	}
+}
+
+// Test wallet's stream pushes operations and balance changes
+func TestWalletEventsHandlerStream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	now := time.Date(2022, time.December, 6, 12, 0, 0, 0, time.UTC)
+	wallet := func(balance int64) *entities.Wallet {
+		return &entities.Wallet{ID: 1, UserID: 1, Balance: decimal.NewFromInt(balance), Currency: "USD"}
+	}
+	deposit := &entities.FeedOperation{
+		Position:        entities.FeedPosition{TxID: 701, OperationID: 15},
+		WalletOperation: entities.WalletOperation{ID: 15, Operation: "deposit", WalletFrom: sql.NullInt32{Int32: 2, Valid: true}, WalletTo: 1, Amount: decimal.NewFromInt(10), CreatedAt: now},
+	}
+	wakeUps := make(chan struct{}, 1)
+	cancelled := make(chan struct{})
+	walletEventsUseCase := usecases.NewMockWalletEventsUsecase(ctrl)
+	walletEventsUseCase.EXPECT().Subscribe(1).Return((<-chan struct{})(wakeUps), func() { close(cancelled) })
+	walletEventsUseCase.EXPECT().Open(gomock.Any(), 1, "650-9").Return(wallet(100), entities.FeedPosition{TxID: 650, OperationID: 9}, nil)
+	gomock.InOrder(
+		// Resumed stream has no missed operations
+		walletEventsUseCase.EXPECT().Updates(gomock.Any(), 1, entities.FeedPosition{TxID: 650, OperationID: 9}).Return(wallet(100), []*entities.FeedOperation{}, nil),
+		walletEventsUseCase.EXPECT().Updates(gomock.Any(), 1, entities.FeedPosition{TxID: 650, OperationID: 9}).Return(wallet(110), []*entities.FeedOperation{deposit}, nil),
+		walletEventsUseCase.EXPECT().Updates(gomock.Any(), 1, entities.FeedPosition{TxID: 701, OperationID: 15}).Return(wallet(110), []*entities.FeedOperation{}, nil).AnyTimes(),
+	)
+
+	server := newWalletEventsServer(walletEventsUseCase, time.Hour)
+	defer server.Close()
+	request, _ := http.NewRequest("GET", server.URL+"/api/wallets/1/events", nil)
+	request.Header.Set("Last-Event-ID", "650-9")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if response.StatusCode != 200 || response.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Wrong response: %d %v", response.StatusCode, response.Header)
+	}
+	reader := bufio.NewReader(response.Body)
+
+	expected := []string{
+		"retry: 3000\n",
+		"event: balance\ndata: {\"wallet_id\":1,\"balance\":\"100\",\"currency\":\"USD\"}\n",
+	}
+	for _, event := range expected {
+		if received := readEvent(t, reader); received != event {
+			t.Errorf("Expected %q, got %q", event, received)
+		}
+	}
+
+	wakeUps <- struct{}{}
+	expected = []string{
+		"id: 701-15\nevent: operation\ndata: {\"id\":15,\"operation\":\"deposit\",\"wallet_from\":2,\"wallet_to\":1,\"amount\":\"10\",\"created_at\":\"2022-12-06T12:00:00Z\"}\n",
+		"event: balance\ndata: {\"wallet_id\":1,\"balance\":\"110\",\"currency\":\"USD\"}\n",
+	}
+	for _, event := range expected {
+		if received := readEvent(t, reader); received != event {
+			t.Errorf("Expected %q, got %q", event, received)
+		}
+	}
+
+	// Subscription is cancelled when client disconnects
+	response.Body.Close()
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Error("Subscription is not cancelled")
+	}
+}
+
+// Test heartbeats and rejected streams
+func TestWalletEventsHandlerHeartbeat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	walletEventsUseCase := usecases.NewMockWalletEventsUsecase(ctrl)
+	walletEventsUseCase.EXPECT().Subscribe(gomock.Any()).Return(make(<-chan struct{}), func() {}).Times(2)
+	walletEventsUseCase.EXPECT().Open(gomock.Any(), 2, "").Return(nil, entities.FeedPosition{}, adapters.NewHTTPError(403, fmt.Errorf("2 has no access to wallet of user 2")))
+	walletEventsUseCase.EXPECT().Open(gomock.Any(), 1, "").Return(&entities.Wallet{ID: 1}, entities.FeedPosition{}, nil)
+	walletEventsUseCase.EXPECT().Updates(gomock.Any(), 1, entities.FeedPosition{}).Return(&entities.Wallet{ID: 1}, []*entities.FeedOperation{}, nil).AnyTimes()
+
+	server := newWalletEventsServer(walletEventsUseCase, 20*time.Millisecond)
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/api/wallets/2/events")
+	if err != nil || response.StatusCode != 403 {
+		t.Fatalf("Expected forbidden stream, got %v (%v)", response, err)
+	}
+	response.Body.Close()
+
+	response, err = http.Get(server.URL + "/api/wallets/1/events")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer response.Body.Close()
+	reader := bufio.NewReader(response.Body)
+	readEvent(t, reader) // retry
+	readEvent(t, reader) // balance
+	if received := readEvent(t, reader); received != ": heartbeat\n" {
+		t.Errorf("Expected heartbeat, got %q", received)
+	}
+}
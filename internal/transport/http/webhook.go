@@ -0,0 +1,203 @@
+package http
+
+import (
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/transport/http/forms"
+	"billing_system_test_task/internal/transport/http/serializers"
+	"billing_system_test_task/internal/usecases"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// WebhooksHandler represents handler structure for the webhook subscriptions
+type WebhooksHandler struct {
+	webhookUseCase usecases.WebhookUsecase
+}
+
+// NewWebhooksHandler returns controller instance
+func NewWebhooksHandler(webhookUseCase usecases.WebhookUsecase) *WebhooksHandler {
+	return &WebhooksHandler{
+		webhookUseCase: webhookUseCase,
+	}
+}
+
+// Create godoc
+// @Summary Create webhook subscription
+// @Description Subscribe URL to events: user.created, wallet.enrolled and transfer.completed.
+// @Description Events are posted as {"id", "type", "created_at", "data"} with X-Webhook-Event, X-Webhook-Delivery and
+// @Description X-Webhook-Signature "t={unix time},v1={hex HMAC-SHA256 of '{t}.{body}' with the secret}" headers.
+// @Description Responses other than 2xx are retried with exponential backoff. Secret is generated when it is not given
+// @Description and is returned only in this response. Subscriptions receive events of all wallets, so they are managed
+// @Description by admins only; URLs must resolve to public addresses.
+// @Tags webhooks
+// @Accept  json
+// @Produce  json
+// @Param webhook body forms.WebhookForm true "URL, event types and secret of subscription"
+// @Success 201 {object} serializers.WebhookSerializer "Created subscription"
+// @Failure 400 {object} FormErrorSerializer "Form validation error"
+// @Failure default {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/webhooks/ [post]
+func (wh *WebhooksHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var webhookForm forms.WebhookForm
+	decoder := json.NewDecoder(r.Body)
+	if decodeErr := decoder.Decode(&webhookForm); decodeErr != nil {
+		JsonResponseError(w, http.StatusBadRequest, fmt.Sprintf("Error json form decoding: %s", decodeErr))
+		return
+	}
+
+	// Validate body parameters
+	if formError := webhookForm.Submit(); formError != nil {
+		log.Println(fmt.Sprintf("[ERROR] Webhook error - %s", *formError))
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(FormErrorSerializer{Messages: *formError})
+		return
+	}
+
+	subscription, createErr := wh.webhookUseCase.CreateSubscription(r.Context(), &entities.WebhookSubscription{
+		URL:        webhookForm.URL,
+		EventTypes: webhookForm.EventTypes,
+		Secret:     webhookForm.Secret,
+	})
+	if createErr != nil {
+		JsonResponseError(w, createErr.GetStatus(), fmt.Sprintf("Error of webhook creation: %s", createErr.GetError()))
+		return
+	}
+	serialized := newWebhookSerializer(subscription)
+	serialized.Secret = subscription.Secret
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(serialized)
+}
+
+// List godoc
+// @Summary Webhook subscriptions
+// @Description Get active webhook subscriptions
+// @Tags webhooks
+// @Produce  json
+// @Success 200 {array} serializers.WebhookSerializer "Subscriptions"
+// @Failure default {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/webhooks/ [get]
+func (wh *WebhooksHandler) List(w http.ResponseWriter, r *http.Request) {
+	subscriptions, listErr := wh.webhookUseCase.ListSubscriptions(r.Context())
+	if listErr != nil {
+		JsonResponseError(w, listErr.GetStatus(), listErr.GetError().Error())
+		return
+	}
+	serialized := make([]serializers.WebhookSerializer, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		serialized = append(serialized, newWebhookSerializer(subscription))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(serialized)
+}
+
+// Delete godoc
+// @Summary Delete webhook subscription
+// @Description Deactivate webhook subscription and cancel its pending deliveries, delivery log is kept
+// @Tags webhooks
+// @Param id path int true "Subscription ID"
+// @Success 204 {string} string "Subscription is deleted"
+// @Failure 404 {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/webhooks/{id} [delete]
+func (wh *WebhooksHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, convErr := strconv.Atoi(mux.Vars(r)["id"])
+	if convErr != nil {
+		JsonResponseError(w, http.StatusBadRequest, fmt.Sprintf("Error formatting webhook id to int: %s", convErr))
+		return
+	}
+	if deleteErr := wh.webhookUseCase.DeleteSubscription(r.Context(), id); deleteErr != nil {
+		JsonResponseError(w, deleteErr.GetStatus(), deleteErr.GetError().Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deliveries godoc
+// @Summary Webhook delivery log
+// @Description Get the latest 100 delivery attempts of subscription, status_code is 0 when response is not received
+// @Tags webhooks
+// @Produce  json
+// @Param id path int true "Subscription ID"
+// @Success 200 {array} serializers.WebhookAttemptSerializer "Attempts"
+// @Failure default {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/webhooks/{id}/deliveries [get]
+func (wh *WebhooksHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	id, convErr := strconv.Atoi(mux.Vars(r)["id"])
+	if convErr != nil {
+		JsonResponseError(w, http.StatusBadRequest, fmt.Sprintf("Error formatting webhook id to int: %s", convErr))
+		return
+	}
+	attempts, listErr := wh.webhookUseCase.ListAttempts(r.Context(), id)
+	if listErr != nil {
+		JsonResponseError(w, listErr.GetStatus(), listErr.GetError().Error())
+		return
+	}
+	serialized := make([]serializers.WebhookAttemptSerializer, 0, len(attempts))
+	for _, attempt := range attempts {
+		serialized = append(serialized, serializers.WebhookAttemptSerializer{
+			ID:         attempt.ID,
+			DeliveryID: attempt.DeliveryID,
+			EventID:    attempt.EventID,
+			EventType:  attempt.EventType,
+			Attempt:    attempt.Attempt,
+			StatusCode: attempt.StatusCode,
+			Error:      attempt.Error,
+			DurationMs: attempt.Duration.Milliseconds(),
+			CreatedAt:  attempt.CreatedAt,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(serialized)
+}
+
+// Redeliver godoc
+// @Summary Redeliver webhook
+// @Description Send delivery again with new attempts, e.g. after receiver is fixed; subscription must be active
+// @Tags webhooks
+// @Param id path int true "Delivery ID"
+// @Success 202 {string} string "Delivery is scheduled"
+// @Failure 404 {object} ErrorMsg
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/webhook-deliveries/{id}/redeliver [post]
+func (wh *WebhooksHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	id, convErr := strconv.Atoi(mux.Vars(r)["id"])
+	if convErr != nil {
+		JsonResponseError(w, http.StatusBadRequest, fmt.Sprintf("Error formatting delivery id to int: %s", convErr))
+		return
+	}
+	if redeliverErr := wh.webhookUseCase.Redeliver(r.Context(), id); redeliverErr != nil {
+		JsonResponseError(w, redeliverErr.GetStatus(), redeliverErr.GetError().Error())
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func newWebhookSerializer(subscription *entities.WebhookSubscription) serializers.WebhookSerializer {
+	eventTypes := subscription.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	return serializers.WebhookSerializer{
+		ID:         subscription.ID,
+		URL:        subscription.URL,
+		EventTypes: eventTypes,
+		Active:     subscription.Active,
+		CreatedAt:  subscription.CreatedAt,
+	}
+}
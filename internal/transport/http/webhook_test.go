@@ -0,0 +1,142 @@
+package http
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/repositories"
+	"billing_system_test_task/internal/usecases"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+)
+
+// newWebhooksRouter returns router with webhooks endpoints
+func newWebhooksRouter(webhookUseCase usecases.WebhookUsecase) *mux.Router {
+	r := mux.NewRouter()
+	handler := NewWebhooksHandler(webhookUseCase)
+	r.HandleFunc("/api/webhooks/", handler.Create).Methods("POST")
+	r.HandleFunc("/api/webhooks/", handler.List).Methods("GET")
+	r.HandleFunc("/api/webhooks/{id}", handler.Delete).Methods("DELETE")
+	r.HandleFunc("/api/webhooks/{id}/deliveries", handler.Deliveries).Methods("GET")
+	r.HandleFunc("/api/webhook-deliveries/{id}/redeliver", handler.Redeliver).Methods("POST")
+	return r
+}
+
+// Test webhooks endpoints
+func TestWebhooksHandler(t *testing.T) {
+	now := time.Date(2022, time.November, 22, 12, 0, 0, 0, time.UTC)
+	subscription := &entities.WebhookSubscription{
+		ID: 1, URL: "https://example.com/hook", EventTypes: []string{entities.EventTransferCompleted}, Secret: "whsec_secret", Active: true, CreatedAt: now,
+	}
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		mockData       func(webhookUseCase *usecases.MockWebhookUsecase)
+		expectedStatus int
+		expected       string
+	}{
+		{
+			name:   "Success subscription creation",
+			method: "POST",
+			url:    "/api/webhooks/",
+			body:   `{"url": "https://example.com/hook", "event_types": ["transfer.completed"]}`,
+			mockData: func(webhookUseCase *usecases.MockWebhookUsecase) {
+				webhookUseCase.EXPECT().CreateSubscription(gomock.Any(), &entities.WebhookSubscription{
+					URL: "https://example.com/hook", EventTypes: []string{entities.EventTransferCompleted},
+				}).Return(subscription, nil)
+			},
+			expectedStatus: 201,
+			expected:       `{"id":1,"url":"https://example.com/hook","event_types":["transfer.completed"],"secret":"whsec_secret","active":true,"created_at":"2022-11-22T12:00:00Z"}`,
+		},
+		{
+			name:           "Invalid url",
+			method:         "POST",
+			url:            "/api/webhooks/",
+			body:           `{"url": "example", "event_types": ["transfer.completed"]}`,
+			mockData:       func(webhookUseCase *usecases.MockWebhookUsecase) {},
+			expectedStatus: 400,
+			expected:       "Invalid url format",
+		},
+		{
+			name:   "Unknown event type",
+			method: "POST",
+			url:    "/api/webhooks/",
+			body:   `{"url": "https://example.com/hook", "event_types": ["wallet.deleted"]}`,
+			mockData: func(webhookUseCase *usecases.MockWebhookUsecase) {
+				webhookUseCase.EXPECT().CreateSubscription(gomock.Any(), gomock.Any()).
+					Return(nil, adapters.NewHTTPErrorsFactory().DefaultError(fmt.Errorf("unknown event type: wallet.deleted")))
+			},
+			expectedStatus: 400,
+			expected:       "unknown event type: wallet.deleted",
+		},
+		{
+			name:   "Subscriptions without secrets",
+			method: "GET",
+			url:    "/api/webhooks/",
+			mockData: func(webhookUseCase *usecases.MockWebhookUsecase) {
+				webhookUseCase.EXPECT().ListSubscriptions(gomock.Any()).Return([]*entities.WebhookSubscription{subscription}, nil)
+			},
+			expectedStatus: 200,
+			expected:       `[{"id":1,"url":"https://example.com/hook","event_types":["transfer.completed"],"active":true,"created_at":"2022-11-22T12:00:00Z"}]`,
+		},
+		{
+			name:   "Missing subscription deletion",
+			method: "DELETE",
+			url:    "/api/webhooks/7",
+			mockData: func(webhookUseCase *usecases.MockWebhookUsecase) {
+				webhookUseCase.EXPECT().DeleteSubscription(gomock.Any(), 7).Return(adapters.NewHTTPErrorsFactory().NotFound(repositories.ErrWebhookNotFound))
+			},
+			expectedStatus: 404,
+			expected:       "webhook is not found",
+		},
+		{
+			name:   "Delivery log",
+			method: "GET",
+			url:    "/api/webhooks/1/deliveries",
+			mockData: func(webhookUseCase *usecases.MockWebhookUsecase) {
+				webhookUseCase.EXPECT().ListAttempts(gomock.Any(), 1).Return([]*entities.WebhookAttempt{{
+					ID: 7, DeliveryID: 3, EventID: 5, EventType: entities.EventTransferCompleted, Attempt: 2,
+					Error: "unexpected status 500", StatusCode: 500, Duration: 120 * time.Millisecond, CreatedAt: now,
+				}}, nil)
+			},
+			expectedStatus: 200,
+			expected:       `[{"id":7,"delivery_id":3,"event_id":5,"event_type":"transfer.completed","attempt":2,"status_code":500,"error":"unexpected status 500","duration_ms":120,"created_at":"2022-11-22T12:00:00Z"}]`,
+		},
+		{
+			name:   "Success redelivery",
+			method: "POST",
+			url:    "/api/webhook-deliveries/3/redeliver",
+			mockData: func(webhookUseCase *usecases.MockWebhookUsecase) {
+				webhookUseCase.EXPECT().Redeliver(gomock.Any(), 3).Return(nil)
+			},
+			expectedStatus: 202,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			webhookUseCase := usecases.NewMockWebhookUsecase(ctrl)
+			tc.mockData(webhookUseCase)
+
+			w := httptest.NewRecorder()
+			newWebhooksRouter(webhookUseCase).ServeHTTP(w, httptest.NewRequest(tc.method, tc.url, strings.NewReader(tc.body)))
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body)
+			}
+			if (tc.expectedStatus == 200 || tc.expectedStatus == 201) && strings.TrimSpace(w.Body.String()) != tc.expected {
+				t.Errorf("Wrong response: %s", w.Body)
+			}
+			if !strings.Contains(w.Body.String(), tc.expected) {
+				t.Errorf("Wrong response: %s", w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package usecases
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/auth"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/repositories"
+	"context"
+	"fmt"
+	"log"
+)
+
+type AccessUsecase interface {
+	Authorize(ctx context.Context, attempt *entities.AuditEvent) adapters.Error
+}
+
+type AccessInteractor struct {
+	auditRepo     repositories.AuditManager
+	errorsFactory adapters.ErrorsFactory
+}
+
+func NewAccessInteractor(auditRepo repositories.AuditManager, errorsFactory adapters.ErrorsFactory) *AccessInteractor {
+	return &AccessInteractor{
+		auditRepo:     auditRepo,
+		errorsFactory: errorsFactory,
+	}
+}
+
+// Authorize checks that roles of principal grant permission of the action; denied attempts are
+// written to the audit trail. Actions without mapped permission are denied for everyone.
+func (ai *AccessInteractor) Authorize(ctx context.Context, attempt *entities.AuditEvent) adapters.Error {
+	principal := entities.PrincipalFromContext(ctx)
+	if principal != nil && attempt.Permission != "" && auth.HasPermission(principal, attempt.Permission) {
+		return nil
+	}
+
+	attempt.Outcome = entities.AuditOutcomeDenied
+	if principal != nil {
+		attempt.Subject = principal.Subject
+		attempt.AuthMethod = principal.Method
+	}
+	// Failure of audit trail must not grant access, it is only logged
+	if recordErr := ai.auditRepo.Record(ctx, attempt); recordErr != nil {
+		log.Printf("[ERROR] Audit of denied %s by %q is not recorded: %s", attempt.Action, attempt.Subject, recordErr)
+	}
+
+	if principal == nil {
+		return ai.errorsFactory.Unauthorized(fmt.Errorf("authentication is required"))
+	}
+	if attempt.Permission == "" {
+		return ai.errorsFactory.Forbidden(fmt.Errorf("%s is not allowed", attempt.Action))
+	}
+	return ai.errorsFactory.Forbidden(fmt.Errorf("%s permission is required", attempt.Permission))
+}
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/usecases/access.go
+
+// Package usecases is a generated GoMock package.
+package usecases
+
+import (
+	adapters "billing_system_test_task/internal/adapters"
+	entities "billing_system_test_task/internal/entities"
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockAccessUsecase is a mock of AccessUsecase interface
+type MockAccessUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccessUsecaseMockRecorder
+}
+
+// MockAccessUsecaseMockRecorder is the mock recorder for MockAccessUsecase
+type MockAccessUsecaseMockRecorder struct {
+	mock *MockAccessUsecase
+}
+
+// NewMockAccessUsecase creates a new mock instance
+func NewMockAccessUsecase(ctrl *gomock.Controller) *MockAccessUsecase {
+	mock := &MockAccessUsecase{ctrl: ctrl}
+	mock.recorder = &MockAccessUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAccessUsecase) EXPECT() *MockAccessUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method
+func (m *MockAccessUsecase) Authorize(ctx context.Context, attempt *entities.AuditEvent) adapters.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, attempt)
+	ret0, _ := ret[0].(adapters.Error)
+	return ret0
+}
+
+// Authorize indicates an expected call of Authorize
+func (mr *MockAccessUsecaseMockRecorder) Authorize(ctx, attempt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockAccessUsecase)(nil).Authorize), ctx, attempt)
+}
@@ -0,0 +1,78 @@
+package usecases
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/auth"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/repositories"
+	"context"
+	"fmt"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// Test authorization of actions and audit of denied attempts
+func TestAccessUsecaseAuthorize(t *testing.T) {
+	finance := &entities.Principal{Subject: "api_key:5", Scopes: []string{auth.RoleFinance}, Method: entities.AuthMethodAPIKey}
+	user := &entities.Principal{Subject: "7", UserID: 7, Method: entities.AuthMethodJWT}
+	tests := []struct {
+		name       string
+		principal  *entities.Principal
+		action     string
+		permission string
+		audited    *entities.AuditEvent
+		auditErr   error
+		status     int
+	}{
+		{name: "Allowed action", principal: finance, action: "REPORTS_SUMMARY", permission: auth.PermReportsRead},
+		{
+			name: "Denied action", principal: user, action: "REPORTS_SUMMARY", permission: auth.PermReportsRead,
+			audited: &entities.AuditEvent{Subject: "7", AuthMethod: "jwt", Action: "REPORTS_SUMMARY", Permission: auth.PermReportsRead, Outcome: "denied", RequestMethod: "GET", Path: "/api/reports/summary"},
+			status:  403,
+		},
+		{
+			name: "Denied action with audit failure", principal: user, action: "REPORTS_SUMMARY", permission: auth.PermReportsRead,
+			audited:  &entities.AuditEvent{Subject: "7", AuthMethod: "jwt", Action: "REPORTS_SUMMARY", Permission: auth.PermReportsRead, Outcome: "denied", RequestMethod: "GET", Path: "/api/reports/summary"},
+			auditErr: fmt.Errorf("[AUDIT_RECORD]: connection error"),
+			status:   403,
+		},
+		{
+			name: "Action without permission", principal: &entities.Principal{Subject: "api_key:1", Scopes: []string{auth.RoleAdmin}}, action: "UNKNOWN",
+			audited: &entities.AuditEvent{Subject: "api_key:1", Action: "UNKNOWN", Outcome: "denied", RequestMethod: "GET", Path: "/api/reports/summary"},
+			status:  403,
+		},
+		{
+			name: "Anonymous", action: "REPORTS_SUMMARY", permission: auth.PermReportsRead,
+			audited: &entities.AuditEvent{Action: "REPORTS_SUMMARY", Permission: auth.PermReportsRead, Outcome: "denied", RequestMethod: "GET", Path: "/api/reports/summary"},
+			status:  401,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockAudit := repositories.NewMockAuditManager(ctrl)
+			ctx := context.Background()
+			if tc.principal != nil {
+				ctx = entities.WithPrincipal(ctx, tc.principal)
+			}
+			if tc.audited != nil {
+				mockAudit.EXPECT().Record(ctx, tc.audited).Return(tc.auditErr)
+			}
+
+			err := NewAccessInteractor(mockAudit, adapters.NewHTTPErrorsFactory()).Authorize(ctx, &entities.AuditEvent{
+				Action:        tc.action,
+				Permission:    tc.permission,
+				RequestMethod: "GET",
+				Path:          "/api/reports/summary",
+			})
+			if tc.status == 0 && err != nil {
+				t.Errorf("Unexpected error: %s", err.GetError())
+			}
+			if tc.status != 0 && (err == nil || err.GetStatus() != tc.status) {
+				t.Errorf("Expected error with status %d, got %v", tc.status, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package usecases
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/auth"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/repositories"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// scopeRe restricts scopes of API keys, they are stored space-separated
+var scopeRe = regexp.MustCompile(`^[a-z][a-z0-9_:.-]{0,63}$`)
+
+type AuthUsecase interface {
+	Authenticate(ctx context.Context, credential string) (*entities.Principal, adapters.Error)
+	CreateAPIKey(ctx context.Context, apiKey *entities.APIKey) (*entities.APIKey, string, adapters.Error)
+	RevokeAPIKey(ctx context.Context, id int) adapters.Error
+}
+
+type AuthInteractor struct {
+	apiKeysRepo   repositories.APIKeysManager
+	tokenVerifier auth.TokenVerifier
+	errorsFactory adapters.ErrorsFactory
+}
+
+// NewAuthInteractor returns authentication use cases; tokens are rejected when tokenVerifier is nil
+func NewAuthInteractor(apiKeysRepo repositories.APIKeysManager, tokenVerifier auth.TokenVerifier, errorsFactory adapters.ErrorsFactory) *AuthInteractor {
+	return &AuthInteractor{
+		apiKeysRepo:   apiKeysRepo,
+		tokenVerifier: tokenVerifier,
+		errorsFactory: errorsFactory,
+	}
+}
+
+// Authenticate returns principal of API key or JWT
+func (ai *AuthInteractor) Authenticate(ctx context.Context, credential string) (*entities.Principal, adapters.Error) {
+	if auth.IsAPIKey(credential) {
+		apiKey, getErr := ai.apiKeysRepo.GetByHash(ctx, auth.HashAPIKey(credential))
+		if getErr == repositories.ErrAPIKeyNotFound {
+			return nil, ai.errorsFactory.Unauthorized(fmt.Errorf("invalid API key"))
+		}
+		if getErr != nil {
+			return nil, ai.errorsFactory.DefaultError(getErr)
+		}
+		return &entities.Principal{
+			Subject: fmt.Sprintf("api_key:%d", apiKey.ID),
+			UserID:  apiKey.UserID,
+			Scopes:  apiKey.Scopes,
+			Method:  entities.AuthMethodAPIKey,
+		}, nil
+	}
+
+	if ai.tokenVerifier == nil {
+		return nil, ai.errorsFactory.Unauthorized(fmt.Errorf("token authentication is not configured"))
+	}
+	claims, verifyErr := ai.tokenVerifier.Verify(credential)
+	if verifyErr != nil {
+		return nil, ai.errorsFactory.Unauthorized(fmt.Errorf("invalid token: %s", verifyErr))
+	}
+	principal := &entities.Principal{
+		Subject: claims.Subject,
+		Scopes:  claims.Scopes,
+		Method:  entities.AuthMethodJWT,
+	}
+	// Subject of end user's token is id of the user
+	if userID, parseErr := strconv.Atoi(claims.Subject); parseErr == nil && userID > 0 {
+		principal.UserID = userID
+	}
+	return principal, nil
+}
+
+// CreateAPIKey generates API key; the key is returned only once, its hash is stored
+func (ai *AuthInteractor) CreateAPIKey(ctx context.Context, apiKey *entities.APIKey) (*entities.APIKey, string, adapters.Error) {
+	if authErr := requireScope(ctx, ai.errorsFactory, entities.ScopeAdmin); authErr != nil {
+		return nil, "", authErr
+	}
+	for _, scope := range apiKey.Scopes {
+		if !scopeRe.MatchString(scope) {
+			return nil, "", ai.errorsFactory.DefaultError(fmt.Errorf("invalid scope: %s", scope))
+		}
+	}
+	key, prefix, keyHash, generateErr := auth.GenerateAPIKey()
+	if generateErr != nil {
+		return nil, "", ai.errorsFactory.DefaultError(generateErr)
+	}
+	apiKey.Prefix = prefix
+	created, createErr := ai.apiKeysRepo.Create(ctx, apiKey, keyHash)
+	if createErr != nil {
+		return nil, "", ai.errorsFactory.DefaultError(createErr)
+	}
+	return created, key, nil
+}
+
+// RevokeAPIKey revokes API key, it is rejected by the next requests
+func (ai *AuthInteractor) RevokeAPIKey(ctx context.Context, id int) adapters.Error {
+	if authErr := requireScope(ctx, ai.errorsFactory, entities.ScopeAdmin); authErr != nil {
+		return authErr
+	}
+	revokeErr := ai.apiKeysRepo.Revoke(ctx, id)
+	if revokeErr == repositories.ErrAPIKeyNotFound {
+		return ai.errorsFactory.NotFound(revokeErr)
+	}
+	if revokeErr != nil {
+		return ai.errorsFactory.DefaultError(revokeErr)
+	}
+	return nil
+}
+
+// requireScope checks that caller is authenticated and granted with scope
+func requireScope(ctx context.Context, errorsFactory adapters.ErrorsFactory, scope string) adapters.Error {
+	principal := entities.PrincipalFromContext(ctx)
+	if principal == nil {
+		return errorsFactory.Unauthorized(fmt.Errorf("authentication is required"))
+	}
+	if !principal.HasScope(scope) {
+		return errorsFactory.Forbidden(fmt.Errorf("%s scope is required", scope))
+	}
+	return nil
+}
+
+// requirePermission checks that one of caller's roles grants permission
+func requirePermission(ctx context.Context, errorsFactory adapters.ErrorsFactory, permission string) adapters.Error {
+	principal := entities.PrincipalFromContext(ctx)
+	if principal == nil {
+		return errorsFactory.Unauthorized(fmt.Errorf("authentication is required"))
+	}
+	if !auth.HasPermission(principal, permission) {
+		return errorsFactory.Forbidden(fmt.Errorf("%s permission is required", permission))
+	}
+	return nil
+}
+
+// requireOwner checks that caller is the user owning resource or has admin scope
+func requireOwner(ctx context.Context, errorsFactory adapters.ErrorsFactory, ownerID int) adapters.Error {
+	principal := entities.PrincipalFromContext(ctx)
+	if principal == nil {
+		return errorsFactory.Unauthorized(fmt.Errorf("authentication is required"))
+	}
+	if principal.HasScope(entities.ScopeAdmin) || principal.UserID != 0 && principal.UserID == ownerID {
+		return nil
+	}
+	return errorsFactory.Forbidden(fmt.Errorf("%s has no access to wallet of user %d", principal.Subject, ownerID))
+}
+
+// requireWalletOwner returns wallet of the caller; for callers without admin scope missing wallet and wallet
+// of another user are not found alike, so they can not learn which wallets exist
+func requireWalletOwner(ctx context.Context, errorsFactory adapters.ErrorsFactory, walletRepo repositories.WalletsManager, walletID int) (*entities.Wallet, adapters.Error) {
+	principal := entities.PrincipalFromContext(ctx)
+	if principal == nil {
+		return nil, errorsFactory.Unauthorized(fmt.Errorf("authentication is required"))
+	}
+	wallet, getErr := walletRepo.GetByID(ctx, walletID)
+	if getErr != nil && principal.HasScope(entities.ScopeAdmin) {
+		return nil, errorsFactory.NotFound(getErr)
+	}
+	if getErr != nil || requireOwner(ctx, errorsFactory, wallet.UserID) != nil {
+		return nil, errorsFactory.NotFound(fmt.Errorf("wallet %d is not found", walletID))
+	}
+	return wallet, nil
+}
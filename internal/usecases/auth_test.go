@@ -0,0 +1,150 @@
+package usecases
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/auth"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/repositories"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// Test authentication with API keys and tokens
+func TestAuthUsecaseAuthenticate(t *testing.T) {
+	secret := []byte("secret")
+	verifier, _ := auth.NewJWTVerifier(auth.JWTConfig{Secret: secret})
+	token, _ := auth.SignHS256(map[string]interface{}{"sub": "7", "exp": time.Now().Add(time.Minute).Unix(), "scope": "read"}, secret)
+	serviceToken, _ := auth.SignHS256(map[string]interface{}{"sub": "reports-service", "exp": time.Now().Add(time.Minute).Unix()}, secret)
+	expiredToken, _ := auth.SignHS256(map[string]interface{}{"sub": "7", "exp": time.Now().Add(-time.Hour).Unix()}, secret)
+
+	tests := []struct {
+		name       string
+		credential string
+		verifier   auth.TokenVerifier
+		mockQuery  func(mockAPIKeys *repositories.MockAPIKeysManager)
+		expected   *entities.Principal
+		status     int
+	}{
+		{
+			name:       "Valid API key",
+			credential: "bk_key",
+			verifier:   verifier,
+			mockQuery: func(mockAPIKeys *repositories.MockAPIKeysManager) {
+				mockAPIKeys.EXPECT().GetByHash(gomock.Any(), auth.HashAPIKey("bk_key")).
+					Return(&entities.APIKey{ID: 3, Scopes: []string{entities.ScopeAdmin}}, nil)
+			},
+			expected: &entities.Principal{Subject: "api_key:3", Scopes: []string{entities.ScopeAdmin}, Method: entities.AuthMethodAPIKey},
+		},
+		{
+			name:       "Revoked API key",
+			credential: "bk_revoked",
+			verifier:   verifier,
+			mockQuery: func(mockAPIKeys *repositories.MockAPIKeysManager) {
+				mockAPIKeys.EXPECT().GetByHash(gomock.Any(), auth.HashAPIKey("bk_revoked")).Return(nil, repositories.ErrAPIKeyNotFound)
+			},
+			status: 401,
+		},
+		{name: "Token of user", credential: token, verifier: verifier, expected: &entities.Principal{Subject: "7", UserID: 7, Scopes: []string{"read"}, Method: entities.AuthMethodJWT}},
+		{name: "Token of service", credential: serviceToken, verifier: verifier, expected: &entities.Principal{Subject: "reports-service", Method: entities.AuthMethodJWT}},
+		{name: "Expired token", credential: expiredToken, verifier: verifier, status: 401},
+		{name: "Tokens are not configured", credential: token, status: 401},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockAPIKeys := repositories.NewMockAPIKeysManager(ctrl)
+			if tc.mockQuery != nil {
+				tc.mockQuery(mockAPIKeys)
+			}
+
+			principal, err := NewAuthInteractor(mockAPIKeys, tc.verifier, adapters.NewHTTPErrorsFactory()).Authenticate(context.Background(), tc.credential)
+			if tc.status != 0 {
+				if err == nil || err.GetStatus() != tc.status {
+					t.Errorf("Expected error with status %d, got %v", tc.status, err)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(principal, tc.expected) {
+				t.Errorf("Expected principal %+v, got %+v (%v)", tc.expected, principal, err)
+			}
+		})
+	}
+}
+
+// Test API keys are managed by admins only
+func TestAuthUsecaseAPIKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockAPIKeys := repositories.NewMockAPIKeysManager(ctrl)
+	interactor := NewAuthInteractor(mockAPIKeys, nil, adapters.NewHTTPErrorsFactory())
+	admin := entities.WithPrincipal(context.Background(), &entities.Principal{Subject: "api_key:1", Scopes: []string{entities.ScopeAdmin}})
+	user := entities.WithPrincipal(context.Background(), &entities.Principal{Subject: "7", UserID: 7})
+
+	var storedHash string
+	mockAPIKeys.EXPECT().Create(admin, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, apiKey *entities.APIKey, keyHash string) (*entities.APIKey, error) {
+			storedHash = keyHash
+			return &entities.APIKey{ID: 2, Name: apiKey.Name, Prefix: apiKey.Prefix, UserID: apiKey.UserID}, nil
+		},
+	)
+	created, key, err := interactor.CreateAPIKey(admin, &entities.APIKey{Name: "partner", UserID: 7, Scopes: []string{"read"}})
+	if err != nil || created.ID != 2 || !strings.HasPrefix(key, created.Prefix) || storedHash != auth.HashAPIKey(key) {
+		t.Errorf("Wrong API key %s: %+v (%v)", key, created, err)
+	}
+	if _, _, err := interactor.CreateAPIKey(admin, &entities.APIKey{Name: "partner", Scopes: []string{"read write"}}); err == nil || err.GetStatus() != 400 {
+		t.Errorf("Expected bad request error, got %v", err)
+	}
+	if _, _, err := interactor.CreateAPIKey(user, &entities.APIKey{Name: "partner"}); err == nil || err.GetStatus() != 403 {
+		t.Errorf("Expected forbidden error, got %v", err)
+	}
+	if _, _, err := interactor.CreateAPIKey(context.Background(), &entities.APIKey{Name: "partner"}); err == nil || err.GetStatus() != 401 {
+		t.Errorf("Expected unauthorized error, got %v", err)
+	}
+
+	mockAPIKeys.EXPECT().Revoke(admin, 2).Return(nil)
+	if err := interactor.RevokeAPIKey(admin, 2); err != nil {
+		t.Errorf("Unexpected error: %s", err.GetError())
+	}
+	mockAPIKeys.EXPECT().Revoke(admin, 2).Return(repositories.ErrAPIKeyNotFound)
+	if err := interactor.RevokeAPIKey(admin, 2); err == nil || err.GetStatus() != 404 {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	if err := interactor.RevokeAPIKey(user, 2); err == nil || err.GetStatus() != 403 {
+		t.Errorf("Expected forbidden error, got %v", err)
+	}
+}
+
+// Test ownership checks of wallets
+func TestRequireOwner(t *testing.T) {
+	errFactory := adapters.NewHTTPErrorsFactory()
+	tests := []struct {
+		name      string
+		principal *entities.Principal
+		status    int
+	}{
+		{name: "Owner", principal: &entities.Principal{Subject: "1", UserID: 1}},
+		{name: "Admin", principal: &entities.Principal{Subject: "api_key:1", Scopes: []string{entities.ScopeAdmin}}},
+		{name: "Another user", principal: &entities.Principal{Subject: "2", UserID: 2}, status: 403},
+		{name: "Server key without user", principal: &entities.Principal{Subject: "api_key:2"}, status: 403},
+		{name: "Anonymous", status: 401},
+	}
+	for _, tc := range tests {
+		ctx := context.Background()
+		if tc.principal != nil {
+			ctx = entities.WithPrincipal(ctx, tc.principal)
+		}
+		err := requireOwner(ctx, errFactory, 1)
+		if tc.status == 0 && err != nil {
+			t.Errorf("[%s] Unexpected error: %s", tc.name, err.GetError())
+		}
+		if tc.status != 0 && (err == nil || err.GetStatus() != tc.status) {
+			t.Errorf("[%s] Expected error with status %d, got %v", tc.name, tc.status, err)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package usecases
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/pipeline"
+	"billing_system_test_task/internal/repositories"
+	"billing_system_test_task/internal/repositories/reports"
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+// nameRe restricts names of feed consumers and report templates
+var nameRe = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+type FeedUsecase interface {
+	Operations(ctx context.Context, consumer string, queryParams url.Values) (*entities.FeedBatch, adapters.Error)
+	Ack(ctx context.Context, consumer, cursor string) (*entities.FeedConsumer, adapters.Error)
+}
+
+type FeedInteractor struct {
+	feedRepo            repositories.FeedManager
+	walletOperationRepo repositories.OperationsManager
+	queryParameters     reports.QueryReaderManager
+	fileHandler         reports.FileHandlingManager
+	processManager      reports.PipelineManager
+	errorsFactory       adapters.ErrorsFactory
+}
+
+func NewFeedInteractor(feedRepo repositories.FeedManager, walletOperationRepo repositories.OperationsManager, queryParameters reports.QueryReaderManager, fileHandler reports.FileHandlingManager, processManager reports.PipelineManager, errorsFactory adapters.ErrorsFactory) *FeedInteractor {
+	return &FeedInteractor{
+		feedRepo:            feedRepo,
+		walletOperationRepo: walletOperationRepo,
+		queryParameters:     queryParameters,
+		fileHandler:         fileHandler,
+		processManager:      processManager,
+		errorsFactory:       errorsFactory,
+	}
+}
+
+// Operations writes operations after consumer's acknowledged position to report; new consumer is owned by caller.
+// Position is not moved until batch is acknowledged, so the batch is repeated on failures.
+func (fi *FeedInteractor) Operations(ctx context.Context, consumer string, queryParams url.Values) (*entities.FeedBatch, adapters.Error) {
+	principal := entities.PrincipalFromContext(ctx)
+	if principal == nil {
+		return nil, fi.errorsFactory.Unauthorized(fmt.Errorf("authentication is required"))
+	}
+	if !nameRe.MatchString(consumer) {
+		return nil, fi.errorsFactory.DefaultError(fmt.Errorf("invalid name of feed consumer: %s", consumer))
+	}
+
+	// Parse query parameters
+	qp, qpErr := fi.queryParameters.ParseFeed(queryParams)
+	if qpErr != nil {
+		return nil, fi.errorsFactory.DefaultError(qpErr)
+	}
+
+	feedConsumer, consumerErr := fi.feedRepo.Consumer(ctx, consumer, principal.Subject)
+	if consumerErr != nil {
+		return nil, fi.errorsFactory.DefaultError(consumerErr)
+	}
+	if ownerErr := fi.requireConsumerOwner(ctx, feedConsumer); ownerErr != nil {
+		return nil, ownerErr
+	}
+
+	batch := &entities.FeedBatch{
+		Consumer: feedConsumer,
+		To:       feedConsumer.Position,
+	}
+	to, batchErr := fi.feedRepo.NextBatch(ctx, feedConsumer.Position, qp.Limit)
+	if batchErr != nil {
+		return nil, fi.errorsFactory.DefaultError(batchErr)
+	}
+	if to == nil {
+		return batch, nil
+	}
+
+	// Batch is bounded by position, so operations committed meanwhile are left for the next batch
+	after := feedConsumer.Position
+	qp.ListParams.After, qp.ListParams.Until = &after, to
+	report, reportErr := generateReport(fi.fileHandler, fi.errorsFactory, qp, func(marshaller reports.FileMarshallingManager) ([]pipeline.StageStats, error) {
+		return fi.processManager.Process(ctx, fi.walletOperationRepo, qp.ListParams, marshaller)
+	})
+	if reportErr != nil {
+		return nil, reportErr
+	}
+	batch.To = *to
+	batch.Report = report
+	return batch, nil
+}
+
+// Ack moves consumer's position to the end of delivered batch; only owner of consumer or admin can move it
+func (fi *FeedInteractor) Ack(ctx context.Context, consumer, cursor string) (*entities.FeedConsumer, adapters.Error) {
+	position, cursorErr := entities.ParseFeedPosition(cursor)
+	if cursorErr != nil {
+		return nil, fi.errorsFactory.DefaultError(cursorErr)
+	}
+	current, getErr := fi.feedRepo.Get(ctx, consumer)
+	if getErr == repositories.ErrFeedConsumerNotFound {
+		return nil, fi.errorsFactory.NotFound(getErr)
+	}
+	if getErr != nil {
+		return nil, fi.errorsFactory.DefaultError(getErr)
+	}
+	if ownerErr := fi.requireConsumerOwner(ctx, current); ownerErr != nil {
+		return nil, ownerErr
+	}
+	feedConsumer, ackErr := fi.feedRepo.Ack(ctx, consumer, position)
+	if ackErr == repositories.ErrFeedConsumerNotFound {
+		return nil, fi.errorsFactory.NotFound(ackErr)
+	}
+	if ackErr != nil {
+		return nil, fi.errorsFactory.DefaultError(ackErr)
+	}
+	return feedConsumer, nil
+}
+
+// requireConsumerOwner checks that consumer is created by caller or caller has admin scope; consumers of
+// other principals are not found like missing ones, so their names and positions are not disclosed
+func (fi *FeedInteractor) requireConsumerOwner(ctx context.Context, consumer *entities.FeedConsumer) adapters.Error {
+	principal := entities.PrincipalFromContext(ctx)
+	if principal == nil {
+		return fi.errorsFactory.Unauthorized(fmt.Errorf("authentication is required"))
+	}
+	if principal.HasScope(entities.ScopeAdmin) || consumer.Owner != "" && consumer.Owner == principal.Subject {
+		return nil
+	}
+	return fi.errorsFactory.NotFound(repositories.ErrFeedConsumerNotFound)
+}
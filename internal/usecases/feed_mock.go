@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/usecases/feed.go
+
+// Package usecases is a generated GoMock package.
+package usecases
+
+import (
+	adapters "billing_system_test_task/internal/adapters"
+	entities "billing_system_test_task/internal/entities"
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	url "net/url"
+	reflect "reflect"
+)
+
+// MockFeedUsecase is a mock of FeedUsecase interface
+type MockFeedUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedUsecaseMockRecorder
+}
+
+// MockFeedUsecaseMockRecorder is the mock recorder for MockFeedUsecase
+type MockFeedUsecaseMockRecorder struct {
+	mock *MockFeedUsecase
+}
+
+// NewMockFeedUsecase creates a new mock instance
+func NewMockFeedUsecase(ctrl *gomock.Controller) *MockFeedUsecase {
+	mock := &MockFeedUsecase{ctrl: ctrl}
+	mock.recorder = &MockFeedUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockFeedUsecase) EXPECT() *MockFeedUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Operations mocks base method
+func (m *MockFeedUsecase) Operations(ctx context.Context, consumer string, queryParams url.Values) (*entities.FeedBatch, adapters.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Operations", ctx, consumer, queryParams)
+	ret0, _ := ret[0].(*entities.FeedBatch)
+	ret1, _ := ret[1].(adapters.Error)
+	return ret0, ret1
+}
+
+// Operations indicates an expected call of Operations
+func (mr *MockFeedUsecaseMockRecorder) Operations(ctx, consumer, queryParams interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Operations", reflect.TypeOf((*MockFeedUsecase)(nil).Operations), ctx, consumer, queryParams)
+}
+
+// Ack mocks base method
+func (m *MockFeedUsecase) Ack(ctx context.Context, consumer, cursor string) (*entities.FeedConsumer, adapters.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ack", ctx, consumer, cursor)
+	ret0, _ := ret[0].(*entities.FeedConsumer)
+	ret1, _ := ret[1].(adapters.Error)
+	return ret0, ret1
+}
+
+// Ack indicates an expected call of Ack
+func (mr *MockFeedUsecaseMockRecorder) Ack(ctx, consumer, cursor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ack", reflect.TypeOf((*MockFeedUsecase)(nil).Ack), ctx, consumer, cursor)
+}
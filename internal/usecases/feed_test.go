@@ -0,0 +1,129 @@
+package usecases
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/auth"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/pipeline"
+	"billing_system_test_task/internal/repositories"
+	"billing_system_test_task/internal/repositories/reports"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+)
+
+// Test receiving of operations feed batch
+func TestFeedUsecaseOperations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := entities.WithPrincipal(context.Background(), financePrincipal)
+
+	mockFeed := repositories.NewMockFeedManager(ctrl)
+	mockOperations := repositories.NewMockOperationsManager(ctrl)
+	mockQueryParams := reports.NewMockQueryReaderManager(ctrl)
+	mockPipes := reports.NewMockPipelineManager(ctrl)
+	fileHandler := reports.NewFileHandler(reports.NewMemoryStorage(), nil, nil)
+	interactor := NewFeedInteractor(mockFeed, mockOperations, mockQueryParams, fileHandler, mockPipes, adapters.NewHTTPErrorsFactory())
+
+	offset := entities.FeedPosition{TxID: 500, OperationID: 10}
+	to := &entities.FeedPosition{TxID: 510, OperationID: 11}
+	newQueryParams := func() *reports.QueryParams {
+		return &reports.QueryParams{Format: "ndjson", ListParams: &repositories.ListParams{}, Limit: 2, Options: reports.DefaultFormatOptions()}
+	}
+
+	// Batch is bounded by consumer's offset and the last operation of the batch
+	mockQueryParams.EXPECT().ParseFeed(gomock.Any()).Return(newQueryParams(), nil)
+	mockFeed.EXPECT().Consumer(ctx, "warehouse", "api_key:2").Return(&entities.FeedConsumer{Name: "warehouse", Position: offset, Owner: "api_key:2"}, nil)
+	mockFeed.EXPECT().NextBatch(ctx, offset, 2).Return(to, nil)
+	mockPipes.EXPECT().Process(ctx, mockOperations, gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, om repositories.OperationsManager, params *repositories.ListParams, marshaller reports.FileMarshallingManager) ([]pipeline.StageStats, error) {
+		if *params.After != offset || params.Until != to {
+			return nil, fmt.Errorf("wrong range of batch: %v - %v", params.After, params.Until)
+		}
+		mr, _ := marshaller.MarshallRow(reports.OperationRow{WalletOperation: &entities.WalletOperation{ID: 11, Operation: "deposit", WalletTo: 1, Amount: decimal.NewFromInt(5), CreatedAt: time.Date(2022, time.October, 18, 0, 0, 0, 0, time.UTC)}})
+		return nil, marshaller.WriteToFile(mr)
+	})
+	batch, err := interactor.Operations(ctx, "warehouse", url.Values{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.GetError())
+	}
+	defer batch.Report.Content.Close()
+	content, _ := ioutil.ReadAll(batch.Report.Content)
+	if batch.To != *to || string(content) != `{"id":11,"operation":"deposit","wallet_from":null,"wallet_to":1,"amount":"5","created_at":"2022-10-18T00:00:00Z"}`+"\n" {
+		t.Errorf("Wrong batch to %s: %q", batch.To, content)
+	}
+
+	// Empty batch does not create report
+	mockQueryParams.EXPECT().ParseFeed(gomock.Any()).Return(newQueryParams(), nil)
+	mockFeed.EXPECT().Consumer(ctx, "warehouse", "api_key:2").Return(&entities.FeedConsumer{Name: "warehouse", Position: *to, Owner: "api_key:2"}, nil)
+	mockFeed.EXPECT().NextBatch(ctx, *to, 2).Return(nil, nil)
+	if batch, err = interactor.Operations(ctx, "warehouse", url.Values{}); err != nil || batch.Report != nil || batch.To != *to {
+		t.Errorf("Expected empty batch, got %+v (%v)", batch, err)
+	}
+
+	if _, err = interactor.Operations(ctx, "ware house", url.Values{}); err == nil || err.GetStatus() != 400 {
+		t.Errorf("Expected invalid consumer name error, got %v", err)
+	}
+
+	// Consumer of another principal is not found
+	mockQueryParams.EXPECT().ParseFeed(gomock.Any()).Return(newQueryParams(), nil)
+	mockFeed.EXPECT().Consumer(ctx, "warehouse", "api_key:2").Return(&entities.FeedConsumer{Name: "warehouse", Position: *to, Owner: "api_key:3"}, nil)
+	if _, err = interactor.Operations(ctx, "warehouse", url.Values{}); err == nil || err.GetStatus() != 404 {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
+
+// financePrincipal is API key consuming the feed
+var financePrincipal = &entities.Principal{Subject: "api_key:2", Scopes: []string{auth.RoleFinance}, Method: entities.AuthMethodAPIKey}
+
+// Test acknowledgement of feed batch
+func TestFeedUsecaseAck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := entities.WithPrincipal(context.Background(), financePrincipal)
+
+	mockFeed := repositories.NewMockFeedManager(ctrl)
+	interactor := NewFeedInteractor(mockFeed, nil, nil, nil, nil, adapters.NewHTTPErrorsFactory())
+
+	position := entities.FeedPosition{TxID: 510, OperationID: 11}
+	mockFeed.EXPECT().Get(ctx, "warehouse").Return(&entities.FeedConsumer{Name: "warehouse", Owner: "api_key:2"}, nil)
+	mockFeed.EXPECT().Ack(ctx, "warehouse", position).Return(&entities.FeedConsumer{Name: "warehouse", Position: position, Owner: "api_key:2"}, nil)
+	consumer, err := interactor.Ack(ctx, "warehouse", "510-11")
+	if err != nil || consumer.Position != position {
+		t.Errorf("Wrong acknowledged consumer: %+v (%v)", consumer, err)
+	}
+
+	mockFeed.EXPECT().Get(ctx, "unknown").Return(nil, repositories.ErrFeedConsumerNotFound)
+	if _, err = interactor.Ack(ctx, "unknown", "510-11"); err == nil || err.GetStatus() != 404 {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+
+	// Consumers of other principals and consumers without owner are not moved
+	mockFeed.EXPECT().Get(ctx, "other").Return(&entities.FeedConsumer{Name: "other", Owner: "api_key:3"}, nil)
+	if _, err = interactor.Ack(ctx, "other", "510-11"); err == nil || err.GetStatus() != 404 {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	mockFeed.EXPECT().Get(ctx, "legacy").Return(&entities.FeedConsumer{Name: "legacy"}, nil)
+	if _, err = interactor.Ack(ctx, "legacy", "510-11"); err == nil || err.GetStatus() != 404 {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+
+	// Admin moves consumers of other principals
+	admin := entities.WithPrincipal(context.Background(), &entities.Principal{Subject: "api_key:1", Scopes: []string{entities.ScopeAdmin}, Method: entities.AuthMethodAPIKey})
+	mockFeed.EXPECT().Get(admin, "other").Return(&entities.FeedConsumer{Name: "other", Owner: "api_key:3"}, nil)
+	mockFeed.EXPECT().Ack(admin, "other", position).Return(&entities.FeedConsumer{Name: "other", Position: position, Owner: "api_key:3"}, nil)
+	if _, err = interactor.Ack(admin, "other", "510-11"); err != nil {
+		t.Errorf("Unexpected error: %s", err.GetError())
+	}
+
+	for _, cursor := range []string{"510", "510-x", "-1-2", "1-2-3"} {
+		if _, err = interactor.Ack(ctx, "warehouse", cursor); err == nil || err.GetStatus() != 400 {
+			t.Errorf("Expected invalid cursor error for %s, got %v", cursor, err)
+		}
+	}
+}
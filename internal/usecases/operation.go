@@ -2,7 +2,9 @@ package usecases
 
 import (
 	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/auth"
 	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/pipeline"
 	"billing_system_test_task/internal/repositories"
 	"billing_system_test_task/internal/repositories/reports"
 	"context"
@@ -11,19 +13,26 @@ import (
 
 type WalletOperationUsecase interface {
 	GenerateReport(ctx context.Context, queryParams url.Values) (*entities.FileMetadata, adapters.Error)
+	List(ctx context.Context, params *repositories.ListParams) (<-chan *entities.WalletOperation, <-chan error, adapters.Error)
 }
 
 type WalletOperationInteractor struct {
 	walletOperationRepo     repositories.OperationsManager
+	walletRepo              repositories.WalletsManager
+	statementRepo           reports.StatementManager
+	templateRepo            reports.TemplateManager
 	queryParameters         reports.QueryReaderManager
 	fileHandler             reports.FileHandlingManager
 	operationProcessManager reports.PipelineManager
 	errorsFactory           adapters.ErrorsFactory
 }
 
-func NewWalletOperationInteractor(walletOperationRepo repositories.OperationsManager, queryParameters reports.QueryReaderManager, fileHandler reports.FileHandlingManager, operationProcessManager reports.PipelineManager, errorsFactory adapters.ErrorsFactory) *WalletOperationInteractor {
+func NewWalletOperationInteractor(walletOperationRepo repositories.OperationsManager, walletRepo repositories.WalletsManager, statementRepo reports.StatementManager, templateRepo reports.TemplateManager, queryParameters reports.QueryReaderManager, fileHandler reports.FileHandlingManager, operationProcessManager reports.PipelineManager, errorsFactory adapters.ErrorsFactory) *WalletOperationInteractor {
 	return &WalletOperationInteractor{
 		walletOperationRepo:     walletOperationRepo,
+		walletRepo:              walletRepo,
+		statementRepo:           statementRepo,
+		templateRepo:            templateRepo,
 		queryParameters:         queryParameters,
 		fileHandler:             fileHandler,
 		operationProcessManager: operationProcessManager,
@@ -38,37 +47,45 @@ func (wor *WalletOperationInteractor) GenerateReport(ctx context.Context, queryP
 		return nil, wor.errorsFactory.DefaultError(qpErr)
 	}
 
-	// Create new file for report
-	fileParams, fpErr := wor.fileHandler.Create(qp.Format)
-	if fpErr != nil {
-		return nil, wor.errorsFactory.DefaultError(fpErr)
+	// Bank statement needs balances of the wallet before entries are written
+	if reports.IsStatementFormat(qp.Format) {
+		// Callers without operations:read get statements of own wallets only
+		if permissionErr := requirePermission(ctx, wor.errorsFactory, auth.PermOperationsRead); permissionErr != nil {
+			if _, ownerErr := requireWalletOwner(ctx, wor.errorsFactory, wor.walletRepo, qp.ListParams.WalletID); ownerErr != nil {
+				return nil, ownerErr
+			}
+		}
+		statement, statementErr := wor.statementRepo.Statement(ctx, qp.ListParams)
+		if statementErr != nil {
+			return nil, wor.errorsFactory.DefaultError(statementErr)
+		}
+		qp.Options.Statement = statement
 	}
 
-	// Creates file marshaller
-	fileHandler, fhErr := wor.fileHandler.CreateMarshaller(
-		fileParams.File,
-		qp.Format,
-		fileParams.CsvWriter,
-	)
-	if fhErr != nil {
-		return nil, wor.errorsFactory.DefaultError(fhErr)
+	// User-defined template is stored by admins
+	if qp.Format == reports.TemplateFormat {
+		reportTemplate, templateErr := wor.templateRepo.Get(ctx, qp.Options.TemplateName)
+		if templateErr != nil {
+			return nil, wor.errorsFactory.DefaultError(templateErr)
+		}
+		compiled, compileErr := reports.CompileTemplate(reportTemplate)
+		if compileErr != nil {
+			return nil, wor.errorsFactory.DefaultError(compileErr)
+		}
+		qp.Options.Template = compiled
 	}
 
-	// Process receiving, marshalling and writing to file wallet operations
-	processErr := wor.operationProcessManager.Process(ctx, wor.walletOperationRepo, qp.ListParams, fileHandler)
-	if processErr != nil {
-		return nil, wor.errorsFactory.DefaultError(processErr)
-	}
+	return generateReport(wor.fileHandler, wor.errorsFactory, qp, func(marshaller reports.FileMarshallingManager) ([]pipeline.StageStats, error) {
+		// Process receiving, marshalling and writing to file wallet operations
+		return wor.operationProcessManager.Process(ctx, wor.walletOperationRepo, qp.ListParams, marshaller)
+	})
+}
 
-	// Get file metadata
-	metadata, metadataErr := wor.fileHandler.GetFileMetadata(fileParams.File)
-	if metadataErr != nil {
-		return nil, wor.errorsFactory.DefaultError(metadataErr)
+// List returns channel of wallet operations filtered by parameters and channel of reading error
+func (wor *WalletOperationInteractor) List(ctx context.Context, params *repositories.ListParams) (<-chan *entities.WalletOperation, <-chan error, adapters.Error) {
+	operations, readErrs, listErr := wor.walletOperationRepo.List(ctx, params)
+	if listErr != nil {
+		return nil, nil, wor.errorsFactory.DefaultError(listErr)
 	}
-	return &entities.FileMetadata{
-		File:        fileParams.File,
-		Path:        fileParams.Path,
-		Size:        metadata.Size,
-		ContentType: metadata.ContentType,
-	}, nil
+	return operations, readErrs, nil
 }
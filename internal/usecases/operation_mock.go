@@ -7,6 +7,7 @@ package usecases
 import (
 	adapters "billing_system_test_task/internal/adapters"
 	entities "billing_system_test_task/internal/entities"
+	repositories "billing_system_test_task/internal/repositories"
 	context "context"
 	gomock "github.com/golang/mock/gomock"
 	url "net/url"
@@ -50,3 +51,19 @@ func (mr *MockWalletOperationUsecaseMockRecorder) GenerateReport(ctx, queryParam
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateReport", reflect.TypeOf((*MockWalletOperationUsecase)(nil).GenerateReport), ctx, queryParams)
 }
+
+// List mocks base method
+func (m *MockWalletOperationUsecase) List(ctx context.Context, params *repositories.ListParams) (<-chan *entities.WalletOperation, <-chan error, adapters.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, params)
+	ret0, _ := ret[0].(<-chan *entities.WalletOperation)
+	ret1, _ := ret[1].(<-chan error)
+	ret2, _ := ret[2].(adapters.Error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List
+func (mr *MockWalletOperationUsecaseMockRecorder) List(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockWalletOperationUsecase)(nil).List), ctx, params)
+}
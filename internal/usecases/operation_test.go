@@ -2,21 +2,26 @@ package usecases
 
 import (
 	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/auth"
 	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/pipeline"
 	"billing_system_test_task/internal/repositories"
 	"billing_system_test_task/internal/repositories/reports"
 	"context"
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/url"
-	"os"
 	reflect "reflect"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	gomock "github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
 )
 
 type walletOperationTest struct {
@@ -38,22 +43,24 @@ var walletOperationTests = []walletOperationTest{
 			qp := &reports.QueryParams{
 				Format: "json",
 			}
-			f, _ := os.CreateTemp("", "_example_file")
+			storage, w := newReportWriter("report.json")
 			fp := &entities.FileParams{
-				File: f,
-				Path: "_example_file",
-				Name: "_example_file",
+				Writer: w,
+				Name:   "report.json",
 			}
 			fm := reports.NewJSONHandler(
-				f,
+				w,
 				mu,
 				json.Marshal,
 			)
 			mockQueryParams.EXPECT().Parse(gomock.Any()).Return(qp, nil)
-			mockFileHandler.EXPECT().Create("json").Return(fp, nil)
-			mockFileHandler.EXPECT().CreateMarshaller(f, "json", nil).Return(fm, nil)
-			mockPipes.EXPECT().Process(ctx, mockOperationsRepo, nil, fm).Return(nil)
-			mockFileHandler.EXPECT().GetFileMetadata(f).Return(&entities.Metadata{
+			mockFileHandler.EXPECT().Create("json", nil).Return(fp, nil)
+			mockFileHandler.EXPECT().CreateMarshaller(w, "json", nil, nil).Return(fm, nil)
+			mockPipes.EXPECT().Process(ctx, mockOperationsRepo, nil, fm).Return([]pipeline.StageStats{
+				{Pipeline: "operations_report", Stage: "read", ItemsOut: 1},
+			}, nil)
+			mockFileHandler.EXPECT().Open("report.json").DoAndReturn(storage.Open)
+			mockFileHandler.EXPECT().GetFileMetadata(gomock.Any(), "json").Return(&entities.Metadata{
 				Size:        "100",
 				ContentType: "json",
 			}, nil)
@@ -61,7 +68,7 @@ var walletOperationTests = []walletOperationTest{
 		},
 		expectedResultMatch: func(actual interface{}) bool {
 			actualMetadata := actual.(*entities.FileMetadata)
-			return actualMetadata != nil
+			return actualMetadata != nil && len(actualMetadata.Stats) == 1 && actualMetadata.Name == "report.json" && actualMetadata.Content != nil
 		},
 	},
 	walletOperationTest{
@@ -83,7 +90,7 @@ var walletOperationTests = []walletOperationTest{
 				Format: "json",
 			}
 			mockQueryParams.EXPECT().Parse(gomock.Any()).Return(qp, nil)
-			mockFileHandler.EXPECT().Create("json").Return(nil, fmt.Errorf("Create file error"))
+			mockFileHandler.EXPECT().Create("json", nil).Return(nil, fmt.Errorf("Create file error"))
 		},
 		err: fmt.Errorf("Create file error"),
 	},
@@ -95,15 +102,15 @@ var walletOperationTests = []walletOperationTest{
 			qp := &reports.QueryParams{
 				Format: "json",
 			}
-			f, _ := os.CreateTemp("", "_example_file")
+			_, w := newReportWriter("report.json")
 			fp := &entities.FileParams{
-				File: f,
-				Path: "_example_file",
-				Name: "_example_file",
+				Writer: w,
+				Name:   "report.json",
 			}
 			mockQueryParams.EXPECT().Parse(gomock.Any()).Return(qp, nil)
-			mockFileHandler.EXPECT().Create("json").Return(fp, nil)
-			mockFileHandler.EXPECT().CreateMarshaller(f, "json", nil).Return(nil, fmt.Errorf("create marshaller error"))
+			mockFileHandler.EXPECT().Create("json", nil).Return(fp, nil)
+			mockFileHandler.EXPECT().CreateMarshaller(w, "json", nil, nil).Return(nil, fmt.Errorf("create marshaller error"))
+			mockFileHandler.EXPECT().Remove("report.json").Return(nil)
 
 		},
 		err: fmt.Errorf("create marshaller error"),
@@ -117,21 +124,21 @@ var walletOperationTests = []walletOperationTest{
 			qp := &reports.QueryParams{
 				Format: "json",
 			}
-			f, _ := os.CreateTemp("", "_example_file")
+			_, w := newReportWriter("report.json")
 			fp := &entities.FileParams{
-				File: f,
-				Path: "_example_file",
-				Name: "_example_file",
+				Writer: w,
+				Name:   "report.json",
 			}
 			fm := reports.NewJSONHandler(
-				f,
+				w,
 				mu,
 				json.Marshal,
 			)
 			mockQueryParams.EXPECT().Parse(gomock.Any()).Return(qp, nil)
-			mockFileHandler.EXPECT().Create("json").Return(fp, nil)
-			mockFileHandler.EXPECT().CreateMarshaller(f, "json", nil).Return(fm, nil)
-			mockPipes.EXPECT().Process(ctx, mockOperationsRepo, nil, fm).Return(fmt.Errorf("process error"))
+			mockFileHandler.EXPECT().Create("json", nil).Return(fp, nil)
+			mockFileHandler.EXPECT().CreateMarshaller(w, "json", nil, nil).Return(fm, nil)
+			mockPipes.EXPECT().Process(ctx, mockOperationsRepo, nil, fm).Return(nil, fmt.Errorf("process error"))
+			mockFileHandler.EXPECT().Remove("report.json").Return(nil)
 		},
 		err: fmt.Errorf("process error"),
 	},
@@ -144,26 +151,70 @@ var walletOperationTests = []walletOperationTest{
 			qp := &reports.QueryParams{
 				Format: "json",
 			}
-			f, _ := os.CreateTemp("", "_example_file")
+			storage, w := newReportWriter("report.json")
 			fp := &entities.FileParams{
-				File: f,
-				Path: "_example_file",
-				Name: "_example_file",
+				Writer: w,
+				Name:   "report.json",
 			}
 			fm := reports.NewJSONHandler(
-				f,
+				w,
 				mu,
 				json.Marshal,
 			)
 			mockQueryParams.EXPECT().Parse(gomock.Any()).Return(qp, nil)
-			mockFileHandler.EXPECT().Create("json").Return(fp, nil)
-			mockFileHandler.EXPECT().CreateMarshaller(f, "json", nil).Return(fm, nil)
-			mockPipes.EXPECT().Process(ctx, mockOperationsRepo, nil, fm).Return(nil)
-			mockFileHandler.EXPECT().GetFileMetadata(f).Return(nil, fmt.Errorf("metadata error"))
+			mockFileHandler.EXPECT().Create("json", nil).Return(fp, nil)
+			mockFileHandler.EXPECT().CreateMarshaller(w, "json", nil, nil).Return(fm, nil)
+			mockPipes.EXPECT().Process(ctx, mockOperationsRepo, nil, fm).Return(nil, nil)
+			mockFileHandler.EXPECT().Open("report.json").DoAndReturn(storage.Open)
+			mockFileHandler.EXPECT().GetFileMetadata(gomock.Any(), "json").Return(nil, fmt.Errorf("metadata error"))
+			mockFileHandler.EXPECT().Remove("report.json").Return(nil)
 
 		},
 		err: fmt.Errorf("metadata error"),
 	},
+	walletOperationTest{
+		name:     "Failed reports generation (marshaller close error)",
+		funcName: "GenerateReport",
+		args:     []driver.Value{},
+		mockQuery: func(ctx context.Context, mockOperationsRepo repositories.OperationsManager, mockQueryParams reports.MockQueryReaderManager, mockPipes reports.MockPipelineManager, mockFileMarshaller reports.MockFileMarshallingManager, mockFileHandler reports.MockFileHandlingManager) {
+			qp := &reports.QueryParams{
+				Format: "csv",
+			}
+			_, w := newReportWriter("report.csv")
+			fp := &entities.FileParams{
+				Writer:    w,
+				Name:      "report.csv",
+				CsvWriter: failedFlushCSVWriter{},
+			}
+			fm := reports.NewCSVHandler(failedFlushCSVWriter{}, &sync.Mutex{})
+			mockQueryParams.EXPECT().Parse(gomock.Any()).Return(qp, nil)
+			mockFileHandler.EXPECT().Create("csv", nil).Return(fp, nil)
+			mockFileHandler.EXPECT().CreateMarshaller(w, "csv", failedFlushCSVWriter{}, nil).Return(fm, nil)
+			mockPipes.EXPECT().Process(ctx, mockOperationsRepo, nil, fm).Return(nil, nil)
+			mockFileHandler.EXPECT().Remove("report.csv").Return(nil)
+		},
+		err: fmt.Errorf("error of csv flushing: flush error"),
+	},
+}
+
+// newReportWriter returns writer of report in memory storage
+func newReportWriter(name string) (*reports.MemoryStorage, io.WriteCloser) {
+	storage := reports.NewMemoryStorage()
+	w, _ := storage.Create(name)
+	return storage, w
+}
+
+// failedFlushCSVWriter implements CSVWriter interface with flush error
+type failedFlushCSVWriter struct{}
+
+func (fw failedFlushCSVWriter) Write(record []string) error {
+	return nil
+}
+
+func (fw failedFlushCSVWriter) Flush() {}
+
+func (fw failedFlushCSVWriter) Error() error {
+	return fmt.Errorf("flush error")
 }
 
 func TestWalletOperationUsecase(t *testing.T) {
@@ -189,7 +240,9 @@ func TestWalletOperationUsecase(t *testing.T) {
 		mockFileMarshaller := reports.NewMockFileMarshallingManager(ctrl)
 		mockFileHandler := reports.NewMockFileHandlingManager(ctrl)
 
-		interactor := NewWalletOperationInteractor(operationsRepo, mockQueryParams, mockFileHandler, mockPipes, errFactory)
+		mockStatement := reports.NewMockStatementManager(ctrl)
+
+		interactor := NewWalletOperationInteractor(operationsRepo, nil, mockStatement, nil, mockQueryParams, mockFileHandler, mockPipes, errFactory)
 
 		for _, arg := range tc.args {
 			realArgs = append(realArgs, reflect.ValueOf(arg))
@@ -232,3 +285,144 @@ func TestWalletOperationUsecase(t *testing.T) {
 		}
 	}
 }
+
+// Test camt053 report generation loads wallet's statement before marshaller creation; users get statements of own wallets only
+func TestWalletOperationUsecaseStatement(t *testing.T) {
+	finance := &entities.Principal{Subject: "api_key:1", Scopes: []string{auth.RoleFinance}}
+	tests := []struct {
+		name         string
+		principal    *entities.Principal
+		walletOwner  int // owner of read wallet, wallet is not read when zero
+		statementErr error
+		err          string
+	}{
+		{name: "Success statement report generation", principal: finance},
+		{name: "Success statement report generation of own wallet", principal: &entities.Principal{Subject: "1", UserID: 1}, walletOwner: 1},
+		{name: "Failed statement report generation (wallet of other user)", principal: &entities.Principal{Subject: "2", UserID: 2}, walletOwner: 1, err: "wallet 1 is not found"},
+		{name: "Failed statement report generation (statement error)", principal: finance, statementErr: fmt.Errorf("[STATEMENT]: wallet 1 does not exist"), err: "[STATEMENT]: wallet 1 does not exist"},
+	}
+	for _, tc := range tests {
+		ctrl := gomock.NewController(t)
+		ctx := entities.WithPrincipal(context.Background(), tc.principal)
+
+		operationsRepo := repositories.NewMockOperationsManager(ctrl)
+		walletsRepo := repositories.NewMockWalletsManager(ctrl)
+		mockStatement := reports.NewMockStatementManager(ctrl)
+		mockQueryParams := reports.NewMockQueryReaderManager(ctrl)
+		mockPipes := reports.NewMockPipelineManager(ctrl)
+		mockFileHandler := reports.NewMockFileHandlingManager(ctrl)
+		interactor := NewWalletOperationInteractor(operationsRepo, walletsRepo, mockStatement, nil, mockQueryParams, mockFileHandler, mockPipes, adapters.NewHTTPErrorsFactory())
+		if tc.walletOwner != 0 {
+			walletsRepo.EXPECT().GetByID(ctx, 1).Return(&entities.Wallet{ID: 1, UserID: tc.walletOwner}, nil)
+		}
+
+		listParams := &repositories.ListParams{WalletID: 1}
+		qp := &reports.QueryParams{
+			Format:     "camt053",
+			ListParams: listParams,
+			Options:    reports.DefaultFormatOptions(),
+		}
+		statement := &entities.AccountStatement{WalletID: 1, Currency: "USD"}
+		mockQueryParams.EXPECT().Parse(gomock.Any()).Return(qp, nil)
+		// Statement of wallet of other user is not loaded
+		if tc.err != "" && tc.statementErr == nil {
+			if _, reportErr := interactor.GenerateReport(ctx, url.Values{}); reportErr == nil || reportErr.GetStatus() != 404 || reportErr.GetError().Error() != tc.err {
+				t.Errorf("[%s] expected not found error, got %v", tc.name, reportErr)
+			}
+			ctrl.Finish()
+			continue
+		}
+		mockStatement.EXPECT().Statement(ctx, listParams).Return(statement, tc.statementErr)
+		if tc.statementErr == nil {
+			storage, w := newReportWriter("report.xml")
+			fm, _ := reports.NewCamt053Handler(w, &sync.Mutex{}, statement, time.Now())
+			mockFileHandler.EXPECT().Create("camt053", nil).Return(&entities.FileParams{Writer: w, Name: "report.xml"}, nil)
+			mockFileHandler.EXPECT().CreateMarshaller(w, "camt053", nil, gomock.Any()).DoAndReturn(
+				func(_ io.Writer, _ string, _ reports.CSVWriter, options *reports.FormatOptions) (reports.FileMarshallingManager, error) {
+					if options.Statement != statement {
+						t.Errorf("[%s] statement is not passed to marshaller", tc.name)
+					}
+					return fm, nil
+				})
+			mockPipes.EXPECT().Process(ctx, operationsRepo, listParams, fm).Return(nil, nil)
+			mockFileHandler.EXPECT().Open("report.xml").DoAndReturn(storage.Open)
+			mockFileHandler.EXPECT().GetFileMetadata(gomock.Any(), "camt053").Return(&entities.Metadata{Size: "100", ContentType: "application/xml"}, nil)
+		}
+
+		_, reportErr := interactor.GenerateReport(ctx, url.Values{})
+		if tc.err == "" && reportErr != nil {
+			t.Errorf("[%s] unexpected err: %s", tc.name, reportErr.GetError())
+		}
+		if tc.err != "" && (reportErr == nil || reportErr.GetError().Error() != tc.err) {
+			t.Errorf("[%s] expected error %s, got %v", tc.name, tc.err, reportErr)
+		}
+		ctrl.Finish()
+	}
+}
+
+// Test template report loads stored template by name
+func TestWalletOperationUsecaseTemplate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	operationsRepo := repositories.NewMockOperationsManager(ctrl)
+	mockTemplates := reports.NewMockTemplateManager(ctrl)
+	mockQueryParams := reports.NewMockQueryReaderManager(ctrl)
+	mockPipes := reports.NewMockPipelineManager(ctrl)
+	fileHandler := reports.NewFileHandler(reports.NewMemoryStorage(), nil, nil)
+	interactor := NewWalletOperationInteractor(operationsRepo, nil, nil, mockTemplates, mockQueryParams, fileHandler, mockPipes, adapters.NewHTTPErrorsFactory())
+
+	newQueryParams := func(name string) *reports.QueryParams {
+		qp := &reports.QueryParams{Format: reports.TemplateFormat, ListParams: &repositories.ListParams{}, Options: reports.DefaultFormatOptions()}
+		qp.Options.TemplateName = name
+		return qp
+	}
+	mockQueryParams.EXPECT().Parse(gomock.Any()).Return(newQueryParams("partner"), nil)
+	mockTemplates.EXPECT().Get(ctx, "partner").Return(&entities.ReportTemplate{Name: "partner", Row: "{{.ID}}:{{.Amount | money 2}}\n", Footer: "{{.Rows}}\n"}, nil)
+	mockPipes.EXPECT().Process(ctx, operationsRepo, gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, om repositories.OperationsManager, params *repositories.ListParams, marshaller reports.FileMarshallingManager) ([]pipeline.StageStats, error) {
+		mr, _ := marshaller.MarshallRow(reports.OperationRow{WalletOperation: &entities.WalletOperation{ID: 7, Amount: decimal.NewFromInt(5)}})
+		return nil, marshaller.WriteToFile(mr)
+	})
+	metadata, err := interactor.GenerateReport(ctx, url.Values{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.GetError())
+	}
+	defer metadata.Content.Close()
+	content, _ := ioutil.ReadAll(metadata.Content)
+	if string(content) != "7:5.00\n1\n" || metadata.ContentType != "text/plain; charset=utf-8" {
+		t.Errorf("Wrong template report %s: %q", metadata.ContentType, content)
+	}
+
+	mockQueryParams.EXPECT().Parse(gomock.Any()).Return(newQueryParams("unknown"), nil)
+	mockTemplates.EXPECT().Get(ctx, "unknown").Return(nil, reports.ErrTemplateNotFound)
+	if _, err = interactor.GenerateReport(ctx, url.Values{}); err == nil || err.GetStatus() != 400 || err.GetError() != reports.ErrTemplateNotFound {
+		t.Errorf("Expected template not found error, got %v", err)
+	}
+}
+
+// Test listing of operations passes parameters to repository
+func TestWalletOperationUsecaseList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	params := &repositories.ListParams{WalletID: 1, Page: 2, PerPage: 10}
+	operations := make(chan *entities.WalletOperation, 1)
+	operations <- &entities.WalletOperation{ID: 1, WalletTo: 1}
+	close(operations)
+	readErrs := make(chan error)
+	close(readErrs)
+
+	operationsRepo := repositories.NewMockOperationsManager(ctrl)
+	operationsRepo.EXPECT().List(ctx, params).Return((<-chan *entities.WalletOperation)(operations), (<-chan error)(readErrs), nil)
+	operationsRepo.EXPECT().List(ctx, params).Return(nil, nil, fmt.Errorf("[OPERATIONS_LIST]: connection error"))
+	interactor := NewWalletOperationInteractor(operationsRepo, nil, nil, nil, nil, nil, nil, adapters.NewHTTPErrorsFactory())
+
+	result, resultErrs, err := interactor.List(ctx, params)
+	if err != nil || result != operations || resultErrs != readErrs {
+		t.Errorf("Unexpected result: %v (%v)", result, err)
+	}
+	if _, _, err = interactor.List(ctx, params); err == nil || err.GetStatus() != 400 {
+		t.Errorf("Expected error of listing, got %v", err)
+	}
+}
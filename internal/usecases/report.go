@@ -0,0 +1,257 @@
+package usecases
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/pipeline"
+	"billing_system_test_task/internal/repositories/reports"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+)
+
+type ReportUsecase interface {
+	GenerateSummary(ctx context.Context, queryParams url.Values) (*entities.FileMetadata, adapters.Error)
+	GenerateExport(ctx context.Context, export string, queryParams url.Values) (*entities.FileMetadata, adapters.Error)
+	PublicKey() (*entities.ReportPublicKey, adapters.Error)
+	Download(ctx context.Context, name string) (*entities.FileMetadata, adapters.Error)
+}
+
+type ReportInteractor struct {
+	summaryRepo     reports.SummaryManager
+	exportRepo      reports.ExportManager
+	queryParameters reports.QueryReaderManager
+	fileHandler     reports.FileHandlingManager
+	processManager  reports.PipelineManager
+	signer          reports.ReportSigner
+	errorsFactory   adapters.ErrorsFactory
+}
+
+func NewReportInteractor(summaryRepo reports.SummaryManager, exportRepo reports.ExportManager, queryParameters reports.QueryReaderManager, fileHandler reports.FileHandlingManager, processManager reports.PipelineManager, signer reports.ReportSigner, errorsFactory adapters.ErrorsFactory) *ReportInteractor {
+	return &ReportInteractor{
+		summaryRepo:     summaryRepo,
+		exportRepo:      exportRepo,
+		queryParameters: queryParameters,
+		fileHandler:     fileHandler,
+		processManager:  processManager,
+		signer:          signer,
+		errorsFactory:   errorsFactory,
+	}
+}
+
+// GenerateSummary writes operations aggregated by period and given dimensions to report file
+func (ri *ReportInteractor) GenerateSummary(ctx context.Context, queryParams url.Values) (*entities.FileMetadata, adapters.Error) {
+	// Parse query parameters
+	qp, qpErr := ri.queryParameters.ParseSummary(queryParams)
+	if qpErr != nil {
+		return nil, ri.errorsFactory.DefaultError(qpErr)
+	}
+
+	return generateReport(ri.fileHandler, ri.errorsFactory, qp, func(marshaller reports.FileMarshallingManager) ([]pipeline.StageStats, error) {
+		// Aggregate operations and write rows to file
+		return ri.processManager.ProcessSummary(ctx, ri.summaryRepo, qp.Summary, marshaller)
+	})
+}
+
+// GenerateExport writes rows of users list or balances snapshot to report file
+func (ri *ReportInteractor) GenerateExport(ctx context.Context, export string, queryParams url.Values) (*entities.FileMetadata, adapters.Error) {
+	// Parse query parameters
+	qp, qpErr := ri.queryParameters.ParseExport(export, queryParams)
+	if qpErr != nil {
+		return nil, ri.errorsFactory.DefaultError(qpErr)
+	}
+
+	return generateReport(ri.fileHandler, ri.errorsFactory, qp, func(marshaller reports.FileMarshallingManager) ([]pipeline.StageStats, error) {
+		// Read exported rows and write them to file
+		return ri.processManager.ProcessExport(ctx, ri.exportRepo, qp.Export, marshaller)
+	})
+}
+
+// PublicKey returns key for verification of reports signatures
+func (ri *ReportInteractor) PublicKey() (*entities.ReportPublicKey, adapters.Error) {
+	if ri.signer == nil {
+		return nil, ri.errorsFactory.NotFound(fmt.Errorf("reports signing is not configured"))
+	}
+	return &entities.ReportPublicKey{
+		KeyID:     ri.signer.KeyID(),
+		Algorithm: reports.SignatureAlgorithm,
+		PublicKey: ri.signer.PublicKey(),
+	}, nil
+}
+
+// Download returns persisted report from storage
+func (ri *ReportInteractor) Download(ctx context.Context, name string) (*entities.FileMetadata, adapters.Error) {
+	fileMetadata, openErr := openReport(ri.fileHandler, ri.errorsFactory, name, reports.FormatByName(name))
+	if openErr != nil {
+		return nil, openErr
+	}
+	fileMetadata.Persisted = true
+	return fileMetadata, nil
+}
+
+// generateReport creates report in requested format, fills it with process and returns its metadata.
+// Report is removed from storage after sending unless it was requested to persist.
+func generateReport(fileHandler reports.FileHandlingManager, errorsFactory adapters.ErrorsFactory, qp *reports.QueryParams, process func(marshaller reports.FileMarshallingManager) ([]pipeline.StageStats, error)) (*entities.FileMetadata, adapters.Error) {
+	// Create new report in storage
+	fileParams, fpErr := fileHandler.Create(qp.Format, qp.Archive)
+	if fpErr != nil {
+		return nil, errorsFactory.DefaultError(fpErr)
+	}
+
+	// Creates file marshaller
+	marshaller, fhErr := fileHandler.CreateMarshaller(
+		fileParams.Writer,
+		qp.Format,
+		fileParams.CsvWriter,
+		qp.Options,
+	)
+	if fhErr != nil {
+		discardReport(fileHandler, fileParams)
+		return nil, errorsFactory.DefaultError(fhErr)
+	}
+
+	stats, processErr := process(marshaller)
+	if processErr != nil {
+		discardReport(fileHandler, fileParams)
+		return nil, errorsFactory.DefaultError(processErr)
+	}
+
+	// Flush buffered data and finalize file's format
+	if closeErr := marshaller.Close(); closeErr != nil {
+		discardReport(fileHandler, fileParams)
+		return nil, errorsFactory.DefaultError(closeErr)
+	}
+
+	// Store report
+	if closeErr := fileParams.Writer.Close(); closeErr != nil {
+		fileHandler.Remove(fileParams.Name)
+		return nil, errorsFactory.DefaultError(fmt.Errorf("error of report storing: %s", closeErr))
+	}
+
+	// Content type of archive differs from report's format
+	fileMetadata, metadataErr := openReport(fileHandler, errorsFactory, fileParams.Name, reports.FormatByName(fileParams.Name))
+	if metadataErr != nil {
+		fileHandler.Remove(fileParams.Name)
+		return nil, metadataErr
+	}
+	fileMetadata.Stats = newStageStats(stats)
+	fileMetadata.Persisted = qp.Persist
+	if !qp.Persist {
+		fileMetadata.Content = &temporaryReport{
+			ReportFile:  fileMetadata.Content,
+			fileHandler: fileHandler,
+			name:        fileParams.Name,
+		}
+	}
+	if qp.Manifest {
+		fileMetadata.Manifest = newReportManifest(qp, fileMetadata, stats)
+		manifestData, manifestSignature, signErr := fileHandler.SignManifest(fileMetadata.Manifest)
+		if signErr != nil {
+			fileMetadata.Content.Close()
+			return nil, errorsFactory.DefaultError(signErr)
+		}
+		fileMetadata.ManifestData = manifestData
+		fileMetadata.ManifestSignature = manifestSignature
+	}
+	return fileMetadata, nil
+}
+
+// openReport opens stored report and reads its metadata
+func openReport(fileHandler reports.FileHandlingManager, errorsFactory adapters.ErrorsFactory, name, format string) (*entities.FileMetadata, adapters.Error) {
+	content, openErr := fileHandler.Open(name)
+	if openErr == reports.ErrReportNotFound {
+		return nil, errorsFactory.NotFound(openErr)
+	}
+	if openErr != nil {
+		return nil, errorsFactory.DefaultError(openErr)
+	}
+
+	metadata, metadataErr := fileHandler.GetFileMetadata(content, format)
+	if metadataErr != nil {
+		content.Close()
+		return nil, errorsFactory.DefaultError(metadataErr)
+	}
+
+	// Rewind content for sending
+	if _, seekErr := content.Seek(0, io.SeekStart); seekErr != nil {
+		content.Close()
+		return nil, errorsFactory.DefaultError(seekErr)
+	}
+
+	return &entities.FileMetadata{
+		Name:        name,
+		Content:     content,
+		Size:        metadata.Size,
+		ContentType: metadata.ContentType,
+		SHA256:      metadata.SHA256,
+		Signature:   metadata.Signature,
+		KeyID:       metadata.KeyID,
+	}, nil
+}
+
+// discardReport closes writer of failed report and removes it from storage
+func discardReport(fileHandler reports.FileHandlingManager, fileParams *entities.FileParams) {
+	fileParams.Writer.Close()
+	fileHandler.Remove(fileParams.Name)
+}
+
+// temporaryReport removes report from storage when its content is closed
+type temporaryReport struct {
+	entities.ReportFile
+	fileHandler reports.FileHandlingManager
+	name        string
+}
+
+func (tr *temporaryReport) Close() error {
+	closeErr := tr.ReportFile.Close()
+	if removeErr := tr.fileHandler.Remove(tr.name); removeErr != nil && closeErr == nil {
+		closeErr = removeErr
+	}
+	return closeErr
+}
+
+// newStageStats returns metrics of pipeline stages for report's metadata
+func newStageStats(stats []pipeline.StageStats) []entities.StageStats {
+	stageStats := make([]entities.StageStats, 0, len(stats))
+	for _, st := range stats {
+		stageStats = append(stageStats, entities.StageStats{
+			Stage:          st.Stage,
+			ItemsIn:        st.ItemsIn,
+			ItemsOut:       st.ItemsOut,
+			SendBlocked:    st.SendBlocked,
+			ReceiveBlocked: st.ReceiveBlocked,
+			Duration:       st.Duration,
+		})
+	}
+	return stageStats
+}
+
+// newReportManifest describes generated report, its checksum and signature
+func newReportManifest(qp *reports.QueryParams, metadata *entities.FileMetadata, stats []pipeline.StageStats) *entities.ReportManifest {
+	manifest := &entities.ReportManifest{
+		Format:      qp.Format,
+		Filters:     qp.Filters,
+		Size:        metadata.Size,
+		GeneratedAt: time.Now().UTC(),
+		SHA256:      hex.EncodeToString(metadata.SHA256),
+	}
+	if qp.Archive != nil {
+		manifest.Compression = qp.Archive.Compression
+		manifest.Encryption = qp.Archive.Encryption
+	}
+	for _, st := range stats {
+		if st.Stage == "write" {
+			manifest.Rows = int(st.ItemsIn)
+		}
+	}
+	if metadata.Signature != nil {
+		manifest.Algorithm = reports.SignatureAlgorithm
+		manifest.KeyID = metadata.KeyID
+		manifest.Signature = base64.StdEncoding.EncodeToString(metadata.Signature)
+	}
+	return manifest
+}
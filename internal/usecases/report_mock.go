@@ -0,0 +1,97 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/usecases/report.go
+
+// Package usecases is a generated GoMock package.
+package usecases
+
+import (
+	adapters "billing_system_test_task/internal/adapters"
+	entities "billing_system_test_task/internal/entities"
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	url "net/url"
+	reflect "reflect"
+)
+
+// MockReportUsecase is a mock of ReportUsecase interface
+type MockReportUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportUsecaseMockRecorder
+}
+
+// MockReportUsecaseMockRecorder is the mock recorder for MockReportUsecase
+type MockReportUsecaseMockRecorder struct {
+	mock *MockReportUsecase
+}
+
+// NewMockReportUsecase creates a new mock instance
+func NewMockReportUsecase(ctrl *gomock.Controller) *MockReportUsecase {
+	mock := &MockReportUsecase{ctrl: ctrl}
+	mock.recorder = &MockReportUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockReportUsecase) EXPECT() *MockReportUsecaseMockRecorder {
+	return m.recorder
+}
+
+// GenerateSummary mocks base method
+func (m *MockReportUsecase) GenerateSummary(ctx context.Context, queryParams url.Values) (*entities.FileMetadata, adapters.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateSummary", ctx, queryParams)
+	ret0, _ := ret[0].(*entities.FileMetadata)
+	ret1, _ := ret[1].(adapters.Error)
+	return ret0, ret1
+}
+
+// GenerateSummary indicates an expected call of GenerateSummary
+func (mr *MockReportUsecaseMockRecorder) GenerateSummary(ctx, queryParams interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateSummary", reflect.TypeOf((*MockReportUsecase)(nil).GenerateSummary), ctx, queryParams)
+}
+
+// GenerateExport mocks base method
+func (m *MockReportUsecase) GenerateExport(ctx context.Context, export string, queryParams url.Values) (*entities.FileMetadata, adapters.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateExport", ctx, export, queryParams)
+	ret0, _ := ret[0].(*entities.FileMetadata)
+	ret1, _ := ret[1].(adapters.Error)
+	return ret0, ret1
+}
+
+// GenerateExport indicates an expected call of GenerateExport
+func (mr *MockReportUsecaseMockRecorder) GenerateExport(ctx, export, queryParams interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateExport", reflect.TypeOf((*MockReportUsecase)(nil).GenerateExport), ctx, export, queryParams)
+}
+
+// PublicKey mocks base method
+func (m *MockReportUsecase) PublicKey() (*entities.ReportPublicKey, adapters.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublicKey")
+	ret0, _ := ret[0].(*entities.ReportPublicKey)
+	ret1, _ := ret[1].(adapters.Error)
+	return ret0, ret1
+}
+
+// PublicKey indicates an expected call of PublicKey
+func (mr *MockReportUsecaseMockRecorder) PublicKey() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublicKey", reflect.TypeOf((*MockReportUsecase)(nil).PublicKey))
+}
+
+// Download mocks base method
+func (m *MockReportUsecase) Download(ctx context.Context, name string) (*entities.FileMetadata, adapters.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Download", ctx, name)
+	ret0, _ := ret[0].(*entities.FileMetadata)
+	ret1, _ := ret[1].(adapters.Error)
+	return ret0, ret1
+}
+
+// Download indicates an expected call of Download
+func (mr *MockReportUsecaseMockRecorder) Download(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Download", reflect.TypeOf((*MockReportUsecase)(nil).Download), ctx, name)
+}
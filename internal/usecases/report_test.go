@@ -0,0 +1,279 @@
+package usecases
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/pipeline"
+	"billing_system_test_task/internal/repositories/reports"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"sync"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+type reportTest struct {
+	name      string
+	mockQuery func(ctx context.Context, mockSummary *reports.MockSummaryManager, mockQueryParams *reports.MockQueryReaderManager, mockPipes *reports.MockPipelineManager, mockFileHandler *reports.MockFileHandlingManager)
+	err       error
+}
+
+var reportTests = []reportTest{
+	{
+		name: "Success summary generation",
+		mockQuery: func(ctx context.Context, mockSummary *reports.MockSummaryManager, mockQueryParams *reports.MockQueryReaderManager, mockPipes *reports.MockPipelineManager, mockFileHandler *reports.MockFileHandlingManager) {
+			qp := &reports.QueryParams{
+				Format:  "json",
+				Summary: &reports.SummaryParams{Period: reports.PeriodMonth},
+			}
+			storage, w := newReportWriter("report.json")
+			fp := &entities.FileParams{
+				Writer: w,
+				Name:   "report.json",
+			}
+			fm := reports.NewJSONHandler(w, &sync.Mutex{}, json.Marshal)
+			mockQueryParams.EXPECT().ParseSummary(gomock.Any()).Return(qp, nil)
+			mockFileHandler.EXPECT().Create("json", nil).Return(fp, nil)
+			mockFileHandler.EXPECT().CreateMarshaller(w, "json", nil, nil).Return(fm, nil)
+			mockPipes.EXPECT().ProcessSummary(ctx, mockSummary, qp.Summary, fm).Return([]pipeline.StageStats{
+				{Pipeline: "summary_report", Stage: "read", ItemsOut: 3},
+			}, nil)
+			mockFileHandler.EXPECT().Open("report.json").DoAndReturn(storage.Open)
+			mockFileHandler.EXPECT().GetFileMetadata(gomock.Any(), "json").Return(&entities.Metadata{
+				Size:        "3",
+				ContentType: "application/json",
+			}, nil)
+		},
+	},
+	{
+		name: "Failed summary generation (query params error)",
+		mockQuery: func(ctx context.Context, mockSummary *reports.MockSummaryManager, mockQueryParams *reports.MockQueryReaderManager, mockPipes *reports.MockPipelineManager, mockFileHandler *reports.MockFileHandlingManager) {
+			mockQueryParams.EXPECT().ParseSummary(gomock.Any()).Return(nil, fmt.Errorf("unsupported 'period' value: year"))
+		},
+		err: fmt.Errorf("unsupported 'period' value: year"),
+	},
+	{
+		name: "Failed summary generation (process error)",
+		mockQuery: func(ctx context.Context, mockSummary *reports.MockSummaryManager, mockQueryParams *reports.MockQueryReaderManager, mockPipes *reports.MockPipelineManager, mockFileHandler *reports.MockFileHandlingManager) {
+			qp := &reports.QueryParams{
+				Format:  "json",
+				Summary: &reports.SummaryParams{Period: reports.PeriodDay},
+			}
+			_, w := newReportWriter("report.json")
+			fp := &entities.FileParams{
+				Writer: w,
+				Name:   "report.json",
+			}
+			fm := reports.NewJSONHandler(w, &sync.Mutex{}, json.Marshal)
+			mockQueryParams.EXPECT().ParseSummary(gomock.Any()).Return(qp, nil)
+			mockFileHandler.EXPECT().Create("json", nil).Return(fp, nil)
+			mockFileHandler.EXPECT().CreateMarshaller(w, "json", nil, nil).Return(fm, nil)
+			mockPipes.EXPECT().ProcessSummary(ctx, mockSummary, qp.Summary, fm).Return(nil, fmt.Errorf("summary processing failed"))
+			mockFileHandler.EXPECT().Remove("report.json").Return(nil)
+		},
+		err: fmt.Errorf("summary processing failed"),
+	},
+}
+
+// Test generation of summary report
+func TestReportUsecaseGenerateSummary(t *testing.T) {
+	for _, tc := range reportTests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ctx := context.Background()
+
+			mockSummary := reports.NewMockSummaryManager(ctrl)
+			mockQueryParams := reports.NewMockQueryReaderManager(ctrl)
+			mockPipes := reports.NewMockPipelineManager(ctrl)
+			mockFileHandler := reports.NewMockFileHandlingManager(ctrl)
+			tc.mockQuery(ctx, mockSummary, mockQueryParams, mockPipes, mockFileHandler)
+
+			interactor := NewReportInteractor(mockSummary, nil, mockQueryParams, mockFileHandler, mockPipes, nil, adapters.NewHTTPErrorsFactory())
+			metadata, err := interactor.GenerateSummary(ctx, url.Values{})
+			if tc.err != nil {
+				if err == nil || err.GetError().Error() != tc.err.Error() {
+					t.Errorf("Expected error '%s', got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err.GetError())
+			}
+			if metadata.ContentType != "application/json" || len(metadata.Stats) != 1 {
+				t.Errorf("Wrong metadata: %+v", metadata)
+			}
+		})
+	}
+}
+
+// Test detached manifest of signed summary report
+func TestReportUsecaseGenerateSummaryManifest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	mockSummary := reports.NewMockSummaryManager(ctrl)
+	mockQueryParams := reports.NewMockQueryReaderManager(ctrl)
+	mockPipes := reports.NewMockPipelineManager(ctrl)
+	mockFileHandler := reports.NewMockFileHandlingManager(ctrl)
+
+	qp := &reports.QueryParams{
+		Format:   "json",
+		Summary:  &reports.SummaryParams{Period: reports.PeriodMonth},
+		Filters:  map[string]string{"period": "month"},
+		Manifest: true,
+	}
+	storage, w := newReportWriter("report.json")
+	fm := reports.NewJSONHandler(w, &sync.Mutex{}, json.Marshal)
+	mockQueryParams.EXPECT().ParseSummary(gomock.Any()).Return(qp, nil)
+	mockFileHandler.EXPECT().Create("json", nil).Return(&entities.FileParams{Writer: w, Name: "report.json"}, nil)
+	mockFileHandler.EXPECT().CreateMarshaller(w, "json", nil, nil).Return(fm, nil)
+	mockPipes.EXPECT().ProcessSummary(ctx, mockSummary, qp.Summary, fm).Return([]pipeline.StageStats{
+		{Pipeline: "summary_report", Stage: "read", ItemsOut: 3},
+		{Pipeline: "summary_report", Stage: "write", ItemsIn: 3},
+	}, nil)
+	mockFileHandler.EXPECT().Open("report.json").DoAndReturn(storage.Open)
+	mockFileHandler.EXPECT().GetFileMetadata(gomock.Any(), "json").Return(&entities.Metadata{
+		Size:        "3",
+		ContentType: "application/json",
+		SHA256:      []byte{0xab, 0xcd},
+		Signature:   []byte{1, 2, 3},
+		KeyID:       "0102030405060708",
+	}, nil)
+	signer, _ := reports.GenerateEd25519Signer()
+	mockFileHandler.EXPECT().SignManifest(gomock.Any()).DoAndReturn(func(manifest *entities.ReportManifest) ([]byte, []byte, error) {
+		return reports.SignManifest(manifest, signer)
+	})
+
+	interactor := NewReportInteractor(mockSummary, nil, mockQueryParams, mockFileHandler, mockPipes, signer, adapters.NewHTTPErrorsFactory())
+	metadata, err := interactor.GenerateSummary(ctx, url.Values{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.GetError())
+	}
+	if len(metadata.Stats) != 2 || metadata.Stats[1].Stage != "write" || metadata.Stats[1].ItemsIn != 3 {
+		t.Errorf("Wrong stats: %+v", metadata.Stats)
+	}
+	manifest := metadata.Manifest
+	if manifest == nil {
+		t.Fatal("Expected manifest, got nil")
+	}
+	if manifest.Rows != 3 || manifest.SHA256 != "abcd" || manifest.Signature != "AQID" || manifest.KeyID != "0102030405060708" ||
+		manifest.Algorithm != reports.SignatureAlgorithm || manifest.Filters["period"] != "month" || manifest.GeneratedAt.IsZero() {
+		t.Errorf("Wrong manifest: %+v", manifest)
+	}
+	if verifyErr := reports.VerifyManifestSignature(metadata.ManifestData, metadata.ManifestSignature, signer.PublicKey()); verifyErr != nil {
+		t.Errorf("Manifest is not verified: %s", verifyErr)
+	}
+}
+
+// Test temporary report is removed from storage after sending and persisted report is kept
+func TestReportUsecaseGenerateSummaryPersist(t *testing.T) {
+	for _, persist := range []bool{false, true} {
+		ctrl := gomock.NewController(t)
+		ctx := context.Background()
+
+		mockSummary := reports.NewMockSummaryManager(ctrl)
+		mockQueryParams := reports.NewMockQueryReaderManager(ctrl)
+		mockPipes := reports.NewMockPipelineManager(ctrl)
+		storage := reports.NewMemoryStorage()
+		fileHandler := reports.NewFileHandler(storage, nil, nil)
+
+		qp := &reports.QueryParams{
+			Format:  "json",
+			Summary: &reports.SummaryParams{Period: reports.PeriodMonth},
+			Persist: persist,
+		}
+		mockQueryParams.EXPECT().ParseSummary(gomock.Any()).Return(qp, nil)
+		mockPipes.EXPECT().ProcessSummary(ctx, mockSummary, qp.Summary, gomock.Any()).Return(nil, nil)
+
+		interactor := NewReportInteractor(mockSummary, nil, mockQueryParams, fileHandler, mockPipes, nil, adapters.NewHTTPErrorsFactory())
+		metadata, err := interactor.GenerateSummary(ctx, url.Values{})
+		if err != nil {
+			t.Fatalf("[persist=%t] Unexpected error: %s", persist, err.GetError())
+		}
+		if metadata.Persisted != persist {
+			t.Errorf("[persist=%t] Wrong persisted flag", persist)
+		}
+		_ = metadata.Content.Close()
+
+		downloaded, downloadErr := interactor.Download(ctx, metadata.Name)
+		if persist {
+			if downloadErr != nil {
+				t.Fatalf("Unexpected error: %s", downloadErr.GetError())
+			}
+			if downloaded.ContentType != "application/json" || downloaded.Size != metadata.Size || !downloaded.Persisted {
+				t.Errorf("Wrong downloaded report: %+v", downloaded)
+			}
+			_ = downloaded.Content.Close()
+		} else if downloadErr == nil || downloadErr.GetStatus() != 404 {
+			t.Errorf("Expected temporary report to be removed, got %v", downloadErr)
+		}
+		ctrl.Finish()
+	}
+}
+
+// Test generation of users export
+func TestReportUsecaseGenerateExport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	mockExport := reports.NewMockExportManager(ctrl)
+	mockQueryParams := reports.NewMockQueryReaderManager(ctrl)
+	mockPipes := reports.NewMockPipelineManager(ctrl)
+	fileHandler := reports.NewFileHandler(reports.NewMemoryStorage(), nil, nil)
+
+	qp := &reports.QueryParams{
+		Format:   "csv",
+		Export:   reports.ExportUsers,
+		Options:  reports.DefaultFormatOptions(),
+		Manifest: true,
+	}
+	qp.Options.Columns = reports.ExportColumns[reports.ExportUsers]
+	mockQueryParams.EXPECT().ParseExport(reports.ExportUsers, gomock.Any()).Return(qp, nil)
+	mockPipes.EXPECT().ProcessExport(ctx, mockExport, reports.ExportUsers, gomock.Any()).DoAndReturn(func(ctx context.Context, em reports.ExportManager, export string, marshaller reports.FileMarshallingManager) ([]pipeline.StageStats, error) {
+		mr, _ := marshaller.MarshallRow(reports.UserRow{ID: 1, Email: "user@example.com"})
+		return []pipeline.StageStats{{Pipeline: "users_report", Stage: "write", ItemsIn: 1}}, marshaller.WriteToFile(mr)
+	})
+
+	interactor := NewReportInteractor(nil, mockExport, mockQueryParams, fileHandler, mockPipes, nil, adapters.NewHTTPErrorsFactory())
+	metadata, err := interactor.GenerateExport(ctx, reports.ExportUsers, url.Values{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.GetError())
+	}
+	defer metadata.Content.Close()
+	content, _ := ioutil.ReadAll(metadata.Content)
+	if string(content) != "id,email,wallet_id,currency,balance\n1,user@example.com,,,\n" {
+		t.Errorf("Wrong export: %q", content)
+	}
+	if metadata.Manifest == nil || metadata.Manifest.Rows != 1 {
+		t.Errorf("Wrong manifest: %+v", metadata.Manifest)
+	}
+
+	mockQueryParams.EXPECT().ParseExport("holds", gomock.Any()).Return(nil, fmt.Errorf("unknown export: holds"))
+	if _, err = interactor.GenerateExport(ctx, "holds", url.Values{}); err == nil || err.GetStatus() != 400 {
+		t.Errorf("Expected bad request error, got %v", err)
+	}
+}
+
+// Test public key of reports signatures
+func TestReportUsecasePublicKey(t *testing.T) {
+	signer, _ := reports.GenerateEd25519Signer()
+	publicKey, err := NewReportInteractor(nil, nil, nil, nil, nil, signer, adapters.NewHTTPErrorsFactory()).PublicKey()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.GetError())
+	}
+	if publicKey.KeyID != signer.KeyID() || publicKey.Algorithm != "ed25519" || len(publicKey.PublicKey) != 32 {
+		t.Errorf("Wrong public key: %+v", publicKey)
+	}
+
+	_, err = NewReportInteractor(nil, nil, nil, nil, nil, nil, adapters.NewHTTPErrorsFactory()).PublicKey()
+	if err == nil || err.GetStatus() != 404 {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
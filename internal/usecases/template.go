@@ -0,0 +1,76 @@
+package usecases
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/repositories/reports"
+	"context"
+	"fmt"
+)
+
+type ReportTemplateUsecase interface {
+	Save(ctx context.Context, reportTemplate *entities.ReportTemplate) (*entities.ReportTemplate, adapters.Error)
+	Get(ctx context.Context, name string) (*entities.ReportTemplate, adapters.Error)
+	List(ctx context.Context) ([]*entities.ReportTemplate, adapters.Error)
+	Delete(ctx context.Context, name string) adapters.Error
+}
+
+type ReportTemplateInteractor struct {
+	templateRepo  reports.TemplateManager
+	errorsFactory adapters.ErrorsFactory
+}
+
+func NewReportTemplateInteractor(templateRepo reports.TemplateManager, errorsFactory adapters.ErrorsFactory) *ReportTemplateInteractor {
+	return &ReportTemplateInteractor{
+		templateRepo:  templateRepo,
+		errorsFactory: errorsFactory,
+	}
+}
+
+// Save validates template and stores it
+func (rti *ReportTemplateInteractor) Save(ctx context.Context, reportTemplate *entities.ReportTemplate) (*entities.ReportTemplate, adapters.Error) {
+	if !nameRe.MatchString(reportTemplate.Name) {
+		return nil, rti.errorsFactory.DefaultError(fmt.Errorf("invalid name of report template: %s", reportTemplate.Name))
+	}
+	if validationErr := reports.ValidateTemplate(reportTemplate); validationErr != nil {
+		return nil, rti.errorsFactory.DefaultError(validationErr)
+	}
+	saved, saveErr := rti.templateRepo.Save(ctx, reportTemplate)
+	if saveErr != nil {
+		return nil, rti.errorsFactory.DefaultError(saveErr)
+	}
+	return saved, nil
+}
+
+// Get returns stored template
+func (rti *ReportTemplateInteractor) Get(ctx context.Context, name string) (*entities.ReportTemplate, adapters.Error) {
+	reportTemplate, getErr := rti.templateRepo.Get(ctx, name)
+	if getErr == reports.ErrTemplateNotFound {
+		return nil, rti.errorsFactory.NotFound(getErr)
+	}
+	if getErr != nil {
+		return nil, rti.errorsFactory.DefaultError(getErr)
+	}
+	return reportTemplate, nil
+}
+
+// List returns all stored templates
+func (rti *ReportTemplateInteractor) List(ctx context.Context) ([]*entities.ReportTemplate, adapters.Error) {
+	templates, listErr := rti.templateRepo.List(ctx)
+	if listErr != nil {
+		return nil, rti.errorsFactory.DefaultError(listErr)
+	}
+	return templates, nil
+}
+
+// Delete removes stored template
+func (rti *ReportTemplateInteractor) Delete(ctx context.Context, name string) adapters.Error {
+	deleteErr := rti.templateRepo.Delete(ctx, name)
+	if deleteErr == reports.ErrTemplateNotFound {
+		return rti.errorsFactory.NotFound(deleteErr)
+	}
+	if deleteErr != nil {
+		return rti.errorsFactory.DefaultError(deleteErr)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/usecases/template.go
+
+// Package usecases is a generated GoMock package.
+package usecases
+
+import (
+	adapters "billing_system_test_task/internal/adapters"
+	entities "billing_system_test_task/internal/entities"
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockReportTemplateUsecase is a mock of ReportTemplateUsecase interface
+type MockReportTemplateUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportTemplateUsecaseMockRecorder
+}
+
+// MockReportTemplateUsecaseMockRecorder is the mock recorder for MockReportTemplateUsecase
+type MockReportTemplateUsecaseMockRecorder struct {
+	mock *MockReportTemplateUsecase
+}
+
+// NewMockReportTemplateUsecase creates a new mock instance
+func NewMockReportTemplateUsecase(ctrl *gomock.Controller) *MockReportTemplateUsecase {
+	mock := &MockReportTemplateUsecase{ctrl: ctrl}
+	mock.recorder = &MockReportTemplateUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockReportTemplateUsecase) EXPECT() *MockReportTemplateUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Save mocks base method
+func (m *MockReportTemplateUsecase) Save(ctx context.Context, reportTemplate *entities.ReportTemplate) (*entities.ReportTemplate, adapters.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, reportTemplate)
+	ret0, _ := ret[0].(*entities.ReportTemplate)
+	ret1, _ := ret[1].(adapters.Error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save
+func (mr *MockReportTemplateUsecaseMockRecorder) Save(ctx, reportTemplate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockReportTemplateUsecase)(nil).Save), ctx, reportTemplate)
+}
+
+// Get mocks base method
+func (m *MockReportTemplateUsecase) Get(ctx context.Context, name string) (*entities.ReportTemplate, adapters.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, name)
+	ret0, _ := ret[0].(*entities.ReportTemplate)
+	ret1, _ := ret[1].(adapters.Error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockReportTemplateUsecaseMockRecorder) Get(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockReportTemplateUsecase)(nil).Get), ctx, name)
+}
+
+// List mocks base method
+func (m *MockReportTemplateUsecase) List(ctx context.Context) ([]*entities.ReportTemplate, adapters.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]*entities.ReportTemplate)
+	ret1, _ := ret[1].(adapters.Error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockReportTemplateUsecaseMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockReportTemplateUsecase)(nil).List), ctx)
+}
+
+// Delete mocks base method
+func (m *MockReportTemplateUsecase) Delete(ctx context.Context, name string) adapters.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, name)
+	ret0, _ := ret[0].(adapters.Error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockReportTemplateUsecaseMockRecorder) Delete(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockReportTemplateUsecase)(nil).Delete), ctx, name)
+}
@@ -0,0 +1,70 @@
+package usecases
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/repositories/reports"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// Test templates are validated before saving
+func TestReportTemplateUsecaseSave(t *testing.T) {
+	tests := []struct {
+		name     string
+		template *entities.ReportTemplate
+		saved    bool
+		err      string
+	}{
+		{name: "Valid template", template: &entities.ReportTemplate{Name: "partner.v1", Row: "{{.ID}};{{.Amount | money 2}}\n"}, saved: true},
+		{name: "Invalid name", template: &entities.ReportTemplate{Name: "partner/v1", Row: "{{.ID}}"}, err: "invalid name of report template: partner/v1"},
+		{name: "Invalid template", template: &entities.ReportTemplate{Name: "partner", Row: "{{.Balance}}"}, err: "error of row section execution"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockTemplates := reports.NewMockTemplateManager(ctrl)
+			if tc.saved {
+				mockTemplates.EXPECT().Save(gomock.Any(), tc.template).Return(tc.template, nil)
+			}
+
+			saved, err := NewReportTemplateInteractor(mockTemplates, adapters.NewHTTPErrorsFactory()).Save(context.Background(), tc.template)
+			if tc.err != "" {
+				if err == nil || err.GetStatus() != 400 || !strings.Contains(err.GetError().Error(), tc.err) {
+					t.Errorf("Expected error '%s', got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil || saved != tc.template {
+				t.Errorf("Wrong saved template: %+v (%v)", saved, err)
+			}
+		})
+	}
+}
+
+// Test missing templates are not found
+func TestReportTemplateUsecaseNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	mockTemplates := reports.NewMockTemplateManager(ctrl)
+	interactor := NewReportTemplateInteractor(mockTemplates, adapters.NewHTTPErrorsFactory())
+
+	mockTemplates.EXPECT().Get(ctx, "unknown").Return(nil, reports.ErrTemplateNotFound)
+	if _, err := interactor.Get(ctx, "unknown"); err == nil || err.GetStatus() != 404 {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	mockTemplates.EXPECT().Delete(ctx, "unknown").Return(reports.ErrTemplateNotFound)
+	if err := interactor.Delete(ctx, "unknown"); err == nil || err.GetStatus() != 404 {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	mockTemplates.EXPECT().List(ctx).Return(nil, fmt.Errorf("[TEMPLATES_LIST]: query error"))
+	if _, err := interactor.List(ctx); err == nil || err.GetStatus() != 400 {
+		t.Errorf("Expected bad request error, got %v", err)
+	}
+}
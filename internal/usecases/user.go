@@ -3,6 +3,7 @@ package usecases
 import (
 	"billing_system_test_task/internal/adapters"
 	trx "billing_system_test_task/internal/adapters/tx"
+	"billing_system_test_task/internal/auth"
 	"billing_system_test_task/internal/entities"
 	"billing_system_test_task/internal/repositories"
 	"context"
@@ -14,6 +15,7 @@ import (
 type UserUseCase interface {
 	Create(ctx context.Context, email string) (*entities.User, adapters.Error)
 	Enroll(ctx context.Context, userID int, amount decimal.Decimal) (*entities.User, adapters.Error)
+	Get(ctx context.Context, userID int) (*entities.User, adapters.Error)
 }
 
 type UserInteractor struct {
@@ -22,31 +24,42 @@ type UserInteractor struct {
 	walletsRepo       repositories.WalletsManager
 	operationsManager repositories.OperationsManager
 	txManager         trx.TxBeginner
+	outboxRepo        repositories.OutboxManager
 }
 
-func NewUserInteractor(userRepo repositories.UsersManager, walletsRepo repositories.WalletsManager, operationsManager repositories.OperationsManager, txManager trx.TxBeginner, errorsFactory adapters.ErrorsFactory) *UserInteractor {
+func NewUserInteractor(userRepo repositories.UsersManager, walletsRepo repositories.WalletsManager, operationsManager repositories.OperationsManager, outboxRepo repositories.OutboxManager, txManager trx.TxBeginner, errorsFactory adapters.ErrorsFactory) *UserInteractor {
 	return &UserInteractor{
 		userRepo:          userRepo,
 		walletsRepo:       walletsRepo,
 		txManager:         txManager,
 		operationsManager: operationsManager,
+		outboxRepo:        outboxRepo,
 		errorsFactory:     errorsFactory,
 	}
 }
 
 // Create creates new user, its wallet and operation for that event
 func (ui UserInteractor) Create(ctx context.Context, email string) (*entities.User, adapters.Error) {
-	var (
-		tx    trx.Tx
-		txErr error
-	)
-	defer trx.RollbackTx(tx, txErr)
-
-	tx, txErr = ui.txManager.BeginTrx(ctx, nil)
+	tx, txErr := ui.txManager.BeginTrx(ctx, nil)
 	if txErr != nil {
 		return nil, ui.errorsFactory.DefaultError(txErr)
 	}
 
+	user, createErr := ui.create(ctx, tx, email)
+	if createErr != nil {
+		_ = tx.Rollback()
+		return nil, createErr
+	}
+
+	if commitErr := tx.Commit(); commitErr != nil {
+		return nil, ui.errorsFactory.DefaultError(commitErr)
+	}
+
+	return user, nil
+}
+
+// create writes user, its wallet, operation and event in transaction
+func (ui UserInteractor) create(ctx context.Context, tx trx.Tx, email string) (*entities.User, adapters.Error) {
 	txUserRepo := ui.userRepo.WithTx(tx)
 	userID, userErr := txUserRepo.Create(ctx, email)
 	if userErr != nil {
@@ -68,25 +81,43 @@ func (ui UserInteractor) Create(ctx context.Context, email string) (*entities.Us
 		return nil, ui.errorsFactory.NotFound(getUserErr)
 	}
 
-	if commitErr := tx.Commit(); commitErr != nil {
-		return nil, ui.errorsFactory.DefaultError(commitErr)
+	outboxErr := ui.outboxRepo.WithTx(tx).Add(ctx, entities.EventUserCreated, map[string]interface{}{
+		"user_id":   user.ID,
+		"email":     user.Email,
+		"wallet_id": walletID,
+	})
+	if outboxErr != nil {
+		return nil, ui.errorsFactory.DefaultError(outboxErr)
 	}
-
 	return user, nil
 }
 
+// Enroll deposits amount to wallet of the user in transaction, which is rolled back on any error
 func (ui UserInteractor) Enroll(ctx context.Context, userID int, amount decimal.Decimal) (*entities.User, adapters.Error) {
-	var (
-		tx    trx.Tx
-		txErr error
-	)
-	defer trx.RollbackTx(tx, txErr)
+	// Enrollment deposits funds from outside of the system, so it is allowed to finance and admins only
+	if authErr := requirePermission(ctx, ui.errorsFactory, auth.PermWalletsEnroll); authErr != nil {
+		return nil, authErr
+	}
 
-	tx, txErr = ui.txManager.BeginTrx(ctx, nil)
+	tx, txErr := ui.txManager.BeginTrx(ctx, nil)
 	if txErr != nil {
 		return nil, ui.errorsFactory.DefaultError(txErr)
 	}
 
+	enrolledUser, enrollErr := ui.enroll(ctx, tx, userID, amount)
+	if enrollErr != nil {
+		_ = tx.Rollback()
+		return nil, enrollErr
+	}
+
+	if commitErr := tx.Commit(); commitErr != nil {
+		return nil, ui.errorsFactory.DefaultError(commitErr)
+	}
+	return enrolledUser, nil
+}
+
+// enroll writes deposit with its operation and event in transaction
+func (ui UserInteractor) enroll(ctx context.Context, tx trx.Tx, userID int, amount decimal.Decimal) (*entities.User, adapters.Error) {
 	txUserRepo := ui.userRepo.WithTx(tx)
 
 	user, getUserErr := txUserRepo.GetByID(ctx, userID)
@@ -99,13 +130,37 @@ func (ui UserInteractor) Enroll(ctx context.Context, userID int, amount decimal.
 		return nil, ui.errorsFactory.DefaultError(enrollWalletErr)
 	}
 
+	// Deposit without source wallet keeps balance reconcilable with operations
+	_, depositOpErr := ui.operationsManager.WithTx(tx).Create(ctx, repositories.Deposit, 0, walletID, amount)
+	if depositOpErr != nil {
+		return nil, ui.errorsFactory.DefaultError(depositOpErr)
+	}
+
 	enrolledUser, enrolledUserErr := txUserRepo.GetByWalletID(ctx, walletID)
 	if enrolledUserErr != nil {
 		return nil, ui.errorsFactory.NotFound(enrolledUserErr)
 	}
 
-	if commitErr := tx.Commit(); commitErr != nil {
-		return nil, ui.errorsFactory.DefaultError(commitErr)
+	outboxErr := ui.outboxRepo.WithTx(tx).Add(ctx, entities.EventWalletEnrolled, map[string]interface{}{
+		"user_id":   enrolledUser.ID,
+		"wallet_id": walletID,
+		"amount":    amount,
+		"balance":   enrolledUser.Wallet.Balance,
+	})
+	if outboxErr != nil {
+		return nil, ui.errorsFactory.DefaultError(outboxErr)
 	}
 	return enrolledUser, nil
 }
+
+// Get returns user with wallet, only the user or admin can read it
+func (ui UserInteractor) Get(ctx context.Context, userID int) (*entities.User, adapters.Error) {
+	if authErr := requireOwner(ctx, ui.errorsFactory, userID); authErr != nil {
+		return nil, authErr
+	}
+	user, getUserErr := ui.userRepo.GetByID(ctx, userID)
+	if getUserErr != nil {
+		return nil, ui.errorsFactory.NotFound(getUserErr)
+	}
+	return user, nil
+}
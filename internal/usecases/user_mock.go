@@ -65,3 +65,18 @@ func (mr *MockUserUseCaseMockRecorder) Enroll(ctx, userID, amount interface{}) *
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enroll", reflect.TypeOf((*MockUserUseCase)(nil).Enroll), ctx, userID, amount)
 }
+
+// Get mocks base method
+func (m *MockUserUseCase) Get(ctx context.Context, userID int) (*entities.User, adapters.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, userID)
+	ret0, _ := ret[0].(*entities.User)
+	ret1, _ := ret[1].(adapters.Error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockUserUseCaseMockRecorder) Get(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockUserUseCase)(nil).Get), ctx, userID)
+}
@@ -3,6 +3,7 @@ package usecases
 import (
 	"billing_system_test_task/internal/adapters"
 	"billing_system_test_task/internal/adapters/tx"
+	"billing_system_test_task/internal/auth"
 	"billing_system_test_task/internal/entities"
 	"billing_system_test_task/internal/repositories"
 	"context"
@@ -23,6 +24,7 @@ type userUsecaseTest struct {
 	mockQuery           func(ctx context.Context, mockUserRepo *repositories.MockUsersManager, mockWalletRepo *repositories.MockWalletsManager, mockOperationRepo *repositories.MockOperationsManager, mockTxManager *tx.MockTxBeginner, txMock *tx.MockTx)
 	err                 error
 	expectedResultMatch func(actual interface{}) bool
+	principal           *entities.Principal // finance service when nil
 }
 
 var userUsecaseTests = []userUsecaseTest{
@@ -228,6 +230,8 @@ var userUsecaseTests = []userUsecaseTest{
 			// Exec insert wallets query
 			mockWalletRepo.EXPECT().WithTx(txMock).Return(mockWalletRepo)
 			mockWalletRepo.EXPECT().Enroll(ctx, 1, decimal.NewFromInt(10)).Return(1, nil)
+			mockOperationRepo.EXPECT().WithTx(txMock).Return(mockOperationRepo)
+			mockOperationRepo.EXPECT().Create(ctx, repositories.Deposit, 0, 1, decimal.NewFromInt(10)).Return(1, nil)
 
 			mockUserRepo.EXPECT().WithTx(txMock).Return(mockUserRepo)
 			user.Wallet.Balance = user.Wallet.Balance.Add(decimal.NewFromInt(10))
@@ -322,6 +326,8 @@ var userUsecaseTests = []userUsecaseTest{
 			// Exec insert wallets query
 			mockWalletRepo.EXPECT().WithTx(txMock).Return(mockWalletRepo)
 			mockWalletRepo.EXPECT().Enroll(ctx, 1, decimal.NewFromInt(10)).Return(1, nil)
+			mockOperationRepo.EXPECT().WithTx(txMock).Return(mockOperationRepo)
+			mockOperationRepo.EXPECT().Create(ctx, repositories.Deposit, 0, 1, decimal.NewFromInt(10)).Return(1, nil)
 
 			mockUserRepo.EXPECT().WithTx(txMock).Return(mockUserRepo)
 			user.Wallet.Balance = user.Wallet.Balance.Add(decimal.NewFromInt(10))
@@ -354,6 +360,8 @@ var userUsecaseTests = []userUsecaseTest{
 			// Exec insert wallets query
 			mockWalletRepo.EXPECT().WithTx(txMock).Return(mockWalletRepo)
 			mockWalletRepo.EXPECT().Enroll(ctx, 1, decimal.NewFromInt(10)).Return(1, nil)
+			mockOperationRepo.EXPECT().WithTx(txMock).Return(mockOperationRepo)
+			mockOperationRepo.EXPECT().Create(ctx, repositories.Deposit, 0, 1, decimal.NewFromInt(10)).Return(1, nil)
 
 			mockUserRepo.EXPECT().WithTx(txMock).Return(mockUserRepo)
 			user.Wallet.Balance = user.Wallet.Balance.Add(decimal.NewFromInt(10))
@@ -365,12 +373,26 @@ var userUsecaseTests = []userUsecaseTest{
 		},
 		err: fmt.Errorf("commit error"),
 	},
+	userUsecaseTest{
+		name:      "Failed user's wallet enrollment (user enrolls own wallet)",
+		funcName:  "Enroll",
+		args:      []driver.Value{1, decimal.NewFromInt(10)},
+		principal: &entities.Principal{Subject: "1", UserID: 1, Method: entities.AuthMethodJWT},
+		mockQuery: func(ctx context.Context, mockUserRepo *repositories.MockUsersManager, mockWalletRepo *repositories.MockWalletsManager, mockOperationRepo *repositories.MockOperationsManager, mockTxManager *tx.MockTxBeginner, txMock *tx.MockTx) {
+			// Transaction is not started
+		},
+		err: fmt.Errorf("wallets:enroll permission is required"),
+	},
 }
 
 func TestUserUsecase(t *testing.T) {
 	for _, tc := range userUsecaseTests {
 		ctrl := gomock.NewController(t)
-		ctx := context.Background()
+		principal := tc.principal
+		if principal == nil {
+			principal = &entities.Principal{Subject: "api_key:1", Scopes: []string{auth.RoleFinance}, Method: entities.AuthMethodAPIKey}
+		}
+		ctx := entities.WithPrincipal(context.Background(), principal)
 		realArgs := []reflect.Value{
 			reflect.ValueOf(ctx),
 		}
@@ -387,7 +409,11 @@ func TestUserUsecase(t *testing.T) {
 		usersRepo := repositories.NewMockUsersManager(ctrl)
 		operationsRepo := repositories.NewMockOperationsManager(ctrl)
 
-		interactor := NewUserInteractor(usersRepo, walletsRepo, operationsRepo, txManager, errFactory)
+		outboxRepo := repositories.NewMockOutboxManager(ctrl)
+		outboxRepo.EXPECT().WithTx(gomock.Any()).Return(outboxRepo).AnyTimes()
+		outboxRepo.EXPECT().Add(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+		interactor := NewUserInteractor(usersRepo, walletsRepo, operationsRepo, outboxRepo, txManager, errFactory)
 
 		for _, arg := range tc.args {
 			realArgs = append(realArgs, reflect.ValueOf(arg))
@@ -439,3 +465,78 @@ func TestUserUsecase(t *testing.T) {
 		}
 	}
 }
+
+// Test transaction of enrollment is rolled back when its event is not written
+func TestUserEnrollOutboxRollback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := entities.WithPrincipal(context.Background(), &entities.Principal{Subject: "api_key:1", Scopes: []string{auth.RoleFinance}, Method: entities.AuthMethodAPIKey})
+	txManager := tx.NewMockTxBeginner(ctrl)
+	txMock := tx.NewMockTx(ctrl)
+	usersRepo := repositories.NewMockUsersManager(ctrl)
+	walletsRepo := repositories.NewMockWalletsManager(ctrl)
+	operationsRepo := repositories.NewMockOperationsManager(ctrl)
+	outboxRepo := repositories.NewMockOutboxManager(ctrl)
+	user := &entities.User{ID: 1, Wallet: &entities.Wallet{ID: 1, UserID: 1, Balance: decimal.NewFromInt(10)}}
+
+	txManager.EXPECT().BeginTrx(ctx, nil).Return(txMock, nil)
+	usersRepo.EXPECT().WithTx(txMock).Return(usersRepo)
+	usersRepo.EXPECT().GetByID(ctx, 1).Return(user, nil)
+	walletsRepo.EXPECT().WithTx(txMock).Return(walletsRepo)
+	walletsRepo.EXPECT().Enroll(ctx, 1, decimal.NewFromInt(10)).Return(1, nil)
+	operationsRepo.EXPECT().WithTx(txMock).Return(operationsRepo)
+	operationsRepo.EXPECT().Create(ctx, repositories.Deposit, 0, 1, decimal.NewFromInt(10)).Return(1, nil)
+	usersRepo.EXPECT().GetByWalletID(ctx, 1).Return(user, nil)
+	outboxRepo.EXPECT().WithTx(txMock).Return(outboxRepo)
+	add := outboxRepo.EXPECT().Add(ctx, entities.EventWalletEnrolled, gomock.Any()).Return(fmt.Errorf("[OUTBOX_ADD]: connection error"))
+	txMock.EXPECT().Rollback().Return(nil).After(add)
+
+	interactor := NewUserInteractor(usersRepo, walletsRepo, operationsRepo, outboxRepo, txManager, adapters.NewHTTPErrorsFactory())
+	if _, err := interactor.Enroll(ctx, 1, decimal.NewFromInt(10)); err == nil {
+		t.Error("Expected error of event writing")
+	}
+}
+
+// Test reading of user is allowed for the user and admin
+func TestUserUsecaseGet(t *testing.T) {
+	user := &entities.User{ID: 1, Email: "user@example.com", Wallet: &entities.Wallet{ID: 1, UserID: 1}}
+	tests := []struct {
+		name      string
+		principal *entities.Principal
+		userID    int
+		getErr    error
+		status    int
+	}{
+		{name: "Owner reads user", principal: &entities.Principal{Subject: "1", UserID: 1}, userID: 1},
+		{name: "Admin reads user", principal: &entities.Principal{Subject: "admin", Scopes: []string{entities.ScopeAdmin}}, userID: 1},
+		{name: "Other user is forbidden", principal: &entities.Principal{Subject: "2", UserID: 2}, userID: 1, status: 403},
+		{name: "Anonymous caller", userID: 1, status: 401},
+		{name: "User is not found", principal: &entities.Principal{Subject: "admin", Scopes: []string{entities.ScopeAdmin}}, userID: 5, getErr: fmt.Errorf("[USER_GET_BY_ID]: sql: no rows in result set"), status: 404},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ctx := context.Background()
+			if tc.principal != nil {
+				ctx = entities.WithPrincipal(ctx, tc.principal)
+			}
+			usersRepo := repositories.NewMockUsersManager(ctrl)
+			if tc.status == 0 || tc.getErr != nil {
+				usersRepo.EXPECT().GetByID(ctx, tc.userID).Return(user, tc.getErr)
+			}
+
+			interactor := NewUserInteractor(usersRepo, nil, nil, nil, nil, adapters.NewHTTPErrorsFactory())
+			result, err := interactor.Get(ctx, tc.userID)
+			if tc.status != 0 {
+				if err == nil || err.GetStatus() != tc.status {
+					t.Errorf("Expected status %d, got %v", tc.status, err)
+				}
+				return
+			}
+			if err != nil || result != user {
+				t.Errorf("Unexpected result: %v (%v)", result, err)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package usecases
 import (
 	"billing_system_test_task/internal/adapters"
 	trx "billing_system_test_task/internal/adapters/tx"
+	"billing_system_test_task/internal/entities"
 	"billing_system_test_task/internal/repositories"
 	"context"
 	"fmt"
@@ -13,6 +14,9 @@ import (
 // WalletUseCase represents contracts for wallet's use cases
 type WalletUseCase interface {
 	Transfer(ctx context.Context, walletFrom, walletTo int, amount decimal.Decimal) (int, adapters.Error)
+	Get(ctx context.Context, walletID int) (*entities.Wallet, adapters.Error)
+	SetFrozen(ctx context.Context, walletID int, frozen bool) (*entities.Wallet, adapters.Error)
+	Reconcile(ctx context.Context) ([]*entities.BalanceDiscrepancy, adapters.Error)
 }
 
 type WalletInteractor struct {
@@ -20,36 +24,48 @@ type WalletInteractor struct {
 	errFactory        adapters.ErrorsFactory
 	txManager         trx.TxBeginner
 	operationsManager repositories.OperationsManager
+	outboxRepo        repositories.OutboxManager
 }
 
-func NewWalletInteractor(walletRepo repositories.WalletsManager, operationsManager repositories.OperationsManager, errFactory adapters.ErrorsFactory, txManager trx.TxBeginner) *WalletInteractor {
+func NewWalletInteractor(walletRepo repositories.WalletsManager, operationsManager repositories.OperationsManager, outboxRepo repositories.OutboxManager, errFactory adapters.ErrorsFactory, txManager trx.TxBeginner) *WalletInteractor {
 	return &WalletInteractor{
 		walletRepo:        walletRepo,
 		errFactory:        errFactory,
 		txManager:         txManager,
 		operationsManager: operationsManager,
+		outboxRepo:        outboxRepo,
 	}
 }
 
+// Transfer moves funds between wallets in transaction, which is rolled back on any error
 func (wi *WalletInteractor) Transfer(ctx context.Context, walletFrom, walletTo int, amount decimal.Decimal) (int, adapters.Error) {
-	var (
-		tx    trx.Tx
-		txErr error
-	)
-	defer trx.RollbackTx(tx, txErr)
-
 	// Start transaction
-	tx, txErr = wi.txManager.BeginTrx(ctx, nil)
+	tx, txErr := wi.txManager.BeginTrx(ctx, nil)
 	if txErr != nil {
 		return 0, wi.errFactory.DefaultError(txErr)
 	}
 
+	walletSourceID, transferErr := wi.transfer(ctx, tx, walletFrom, walletTo, amount)
+	if transferErr != nil {
+		_ = tx.Rollback()
+		return 0, transferErr
+	}
+
+	// Commit transaction
+	if commitErr := tx.Commit(); commitErr != nil {
+		return 0, wi.errFactory.DefaultError(commitErr)
+	}
+	return walletSourceID, nil
+}
+
+// transfer checks wallets and writes transfer with its operations and event in transaction
+func (wi *WalletInteractor) transfer(ctx context.Context, tx trx.Tx, walletFrom, walletTo int, amount decimal.Decimal) (int, adapters.Error) {
 	txWalletRepo := wi.walletRepo.WithTx(tx)
 
-	// Receive source wallet
-	sourceWallet, getSourceWalletErr := txWalletRepo.GetByID(ctx, walletFrom)
-	if getSourceWalletErr != nil {
-		return 0, wi.errFactory.NotFound(getSourceWalletErr)
+	// Receive source wallet, caller must own it
+	sourceWallet, ownerErr := requireWalletOwner(ctx, wi.errFactory, txWalletRepo, walletFrom)
+	if ownerErr != nil {
+		return 0, ownerErr
 	}
 
 	// Check source wallet balance
@@ -87,9 +103,47 @@ func (wi *WalletInteractor) Transfer(ctx context.Context, walletFrom, walletTo i
 		return 0, wi.errFactory.DefaultError(withdrawalOpErrr)
 	}
 
-	// Commit transaction
-	if commitErr := tx.Commit(); commitErr != nil {
-		return 0, wi.errFactory.DefaultError(commitErr)
+	// Write event of the transfer, it is published after commit
+	outboxErr := wi.outboxRepo.WithTx(tx).Add(ctx, entities.EventTransferCompleted, map[string]interface{}{
+		"wallet_from": walletFrom,
+		"wallet_to":   walletTo,
+		"amount":      amount,
+	})
+	if outboxErr != nil {
+		return 0, wi.errFactory.DefaultError(outboxErr)
 	}
 	return walletSourceID, nil
 }
+
+// Get returns wallet, only its owner or admin can read it
+func (wi *WalletInteractor) Get(ctx context.Context, walletID int) (*entities.Wallet, adapters.Error) {
+	return requireWalletOwner(ctx, wi.errFactory, wi.walletRepo, walletID)
+}
+
+// SetFrozen freezes or unfreezes wallet, it requires admin scope
+func (wi *WalletInteractor) SetFrozen(ctx context.Context, walletID int, frozen bool) (*entities.Wallet, adapters.Error) {
+	if authErr := requireScope(ctx, wi.errFactory, entities.ScopeAdmin); authErr != nil {
+		return nil, authErr
+	}
+	wallet, getWalletErr := wi.walletRepo.GetByID(ctx, walletID)
+	if getWalletErr != nil {
+		return nil, wi.errFactory.NotFound(getWalletErr)
+	}
+	if setErr := wi.walletRepo.SetFrozen(ctx, walletID, frozen); setErr != nil {
+		return nil, wi.errFactory.DefaultError(setErr)
+	}
+	wallet.Frozen = frozen
+	return wallet, nil
+}
+
+// Reconcile returns wallets whose balances differ from their operations, it requires admin scope
+func (wi *WalletInteractor) Reconcile(ctx context.Context) ([]*entities.BalanceDiscrepancy, adapters.Error) {
+	if authErr := requireScope(ctx, wi.errFactory, entities.ScopeAdmin); authErr != nil {
+		return nil, authErr
+	}
+	discrepancies, reconcileErr := wi.walletRepo.Reconcile(ctx)
+	if reconcileErr != nil {
+		return nil, wi.errFactory.DefaultError(reconcileErr)
+	}
+	return discrepancies, nil
+}
@@ -0,0 +1,79 @@
+package usecases
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/repositories"
+	"context"
+)
+
+// walletEventsBatch is the number of operations read by one update of wallet's stream
+const walletEventsBatch = 500
+
+// WalletNotifier represents source of wallets' change wake-ups
+type WalletNotifier interface {
+	Subscribe(walletID int) (<-chan struct{}, func())
+}
+
+// WalletEventsUsecase represents contracts for live updates of wallet
+type WalletEventsUsecase interface {
+	Open(ctx context.Context, walletID int, lastEventID string) (*entities.Wallet, entities.FeedPosition, adapters.Error)
+	Updates(ctx context.Context, walletID int, after entities.FeedPosition) (*entities.Wallet, []*entities.FeedOperation, adapters.Error)
+	Subscribe(walletID int) (<-chan struct{}, func())
+}
+
+type WalletEventsInteractor struct {
+	walletRepo    repositories.WalletsManager
+	feedRepo      repositories.FeedManager
+	notifier      WalletNotifier
+	errorsFactory adapters.ErrorsFactory
+}
+
+func NewWalletEventsInteractor(walletRepo repositories.WalletsManager, feedRepo repositories.FeedManager, notifier WalletNotifier, errorsFactory adapters.ErrorsFactory) *WalletEventsInteractor {
+	return &WalletEventsInteractor{
+		walletRepo:    walletRepo,
+		feedRepo:      feedRepo,
+		notifier:      notifier,
+		errorsFactory: errorsFactory,
+	}
+}
+
+// Open checks caller owns wallet and returns wallet with position of the stream:
+// given Last-Event-ID resumes stream, otherwise it starts after the latest operation
+func (wei *WalletEventsInteractor) Open(ctx context.Context, walletID int, lastEventID string) (*entities.Wallet, entities.FeedPosition, adapters.Error) {
+	wallet, ownerErr := requireWalletOwner(ctx, wei.errorsFactory, wei.walletRepo, walletID)
+	if ownerErr != nil {
+		return nil, entities.FeedPosition{}, ownerErr
+	}
+
+	if lastEventID != "" {
+		position, parseErr := entities.ParseFeedPosition(lastEventID)
+		if parseErr != nil {
+			return nil, entities.FeedPosition{}, wei.errorsFactory.DefaultError(parseErr)
+		}
+		return wallet, position, nil
+	}
+	position, positionErr := wei.feedRepo.WalletPosition(ctx, walletID)
+	if positionErr != nil {
+		return nil, entities.FeedPosition{}, wei.errorsFactory.DefaultError(positionErr)
+	}
+	return wallet, position, nil
+}
+
+// Updates returns current state of wallet and its operations after position
+func (wei *WalletEventsInteractor) Updates(ctx context.Context, walletID int, after entities.FeedPosition) (*entities.Wallet, []*entities.FeedOperation, adapters.Error) {
+	wallet, getErr := wei.walletRepo.GetByID(ctx, walletID)
+	if getErr != nil {
+		return nil, nil, wei.errorsFactory.NotFound(getErr)
+	}
+	operations, listErr := wei.feedRepo.WalletOperations(ctx, walletID, after, walletEventsBatch)
+	if listErr != nil {
+		return nil, nil, wei.errorsFactory.DefaultError(listErr)
+	}
+	return wallet, operations, nil
+}
+
+// Subscribe returns wake-ups of wallet's changes
+func (wei *WalletEventsInteractor) Subscribe(walletID int) (<-chan struct{}, func()) {
+	return wei.notifier.Subscribe(walletID)
+}
@@ -0,0 +1,121 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/usecases/wallet_events.go
+
+// Package usecases is a generated GoMock package.
+package usecases
+
+import (
+	adapters "billing_system_test_task/internal/adapters"
+	entities "billing_system_test_task/internal/entities"
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockWalletNotifier is a mock of WalletNotifier interface
+type MockWalletNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockWalletNotifierMockRecorder
+}
+
+// MockWalletNotifierMockRecorder is the mock recorder for MockWalletNotifier
+type MockWalletNotifierMockRecorder struct {
+	mock *MockWalletNotifier
+}
+
+// NewMockWalletNotifier creates a new mock instance
+func NewMockWalletNotifier(ctrl *gomock.Controller) *MockWalletNotifier {
+	mock := &MockWalletNotifier{ctrl: ctrl}
+	mock.recorder = &MockWalletNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWalletNotifier) EXPECT() *MockWalletNotifierMockRecorder {
+	return m.recorder
+}
+
+// Subscribe mocks base method
+func (m *MockWalletNotifier) Subscribe(walletID int) (<-chan struct{}, func()) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", walletID)
+	ret0, _ := ret[0].(<-chan struct{})
+	ret1, _ := ret[1].(func())
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe
+func (mr *MockWalletNotifierMockRecorder) Subscribe(walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockWalletNotifier)(nil).Subscribe), walletID)
+}
+
+// MockWalletEventsUsecase is a mock of WalletEventsUsecase interface
+type MockWalletEventsUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockWalletEventsUsecaseMockRecorder
+}
+
+// MockWalletEventsUsecaseMockRecorder is the mock recorder for MockWalletEventsUsecase
+type MockWalletEventsUsecaseMockRecorder struct {
+	mock *MockWalletEventsUsecase
+}
+
+// NewMockWalletEventsUsecase creates a new mock instance
+func NewMockWalletEventsUsecase(ctrl *gomock.Controller) *MockWalletEventsUsecase {
+	mock := &MockWalletEventsUsecase{ctrl: ctrl}
+	mock.recorder = &MockWalletEventsUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWalletEventsUsecase) EXPECT() *MockWalletEventsUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Open mocks base method
+func (m *MockWalletEventsUsecase) Open(ctx context.Context, walletID int, lastEventID string) (*entities.Wallet, entities.FeedPosition, adapters.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", ctx, walletID, lastEventID)
+	ret0, _ := ret[0].(*entities.Wallet)
+	ret1, _ := ret[1].(entities.FeedPosition)
+	ret2, _ := ret[2].(adapters.Error)
+	return ret0, ret1, ret2
+}
+
+// Open indicates an expected call of Open
+func (mr *MockWalletEventsUsecaseMockRecorder) Open(ctx, walletID, lastEventID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockWalletEventsUsecase)(nil).Open), ctx, walletID, lastEventID)
+}
+
+// Updates mocks base method
+func (m *MockWalletEventsUsecase) Updates(ctx context.Context, walletID int, after entities.FeedPosition) (*entities.Wallet, []*entities.FeedOperation, adapters.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Updates", ctx, walletID, after)
+	ret0, _ := ret[0].(*entities.Wallet)
+	ret1, _ := ret[1].([]*entities.FeedOperation)
+	ret2, _ := ret[2].(adapters.Error)
+	return ret0, ret1, ret2
+}
+
+// Updates indicates an expected call of Updates
+func (mr *MockWalletEventsUsecaseMockRecorder) Updates(ctx, walletID, after interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Updates", reflect.TypeOf((*MockWalletEventsUsecase)(nil).Updates), ctx, walletID, after)
+}
+
+// Subscribe mocks base method
+func (m *MockWalletEventsUsecase) Subscribe(walletID int) (<-chan struct{}, func()) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", walletID)
+	ret0, _ := ret[0].(<-chan struct{})
+	ret1, _ := ret[1].(func())
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe
+func (mr *MockWalletEventsUsecaseMockRecorder) Subscribe(walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockWalletEventsUsecase)(nil).Subscribe), walletID)
+}
@@ -0,0 +1,108 @@
+package usecases
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/repositories"
+	"context"
+	"fmt"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+)
+
+// Test opening of wallet's stream
+func TestWalletEventsUsecaseOpen(t *testing.T) {
+	owner := &entities.Principal{Subject: "1", UserID: 1}
+	tests := []struct {
+		name        string
+		principal   *entities.Principal
+		lastEventID string
+		mockData    func(walletRepo *repositories.MockWalletsManager, feedRepo *repositories.MockFeedManager)
+		position    entities.FeedPosition
+		status      int
+	}{
+		{
+			name:      "Stream starts after the latest operation",
+			principal: owner,
+			mockData: func(walletRepo *repositories.MockWalletsManager, feedRepo *repositories.MockFeedManager) {
+				walletRepo.EXPECT().GetByID(gomock.Any(), 1).Return(&entities.Wallet{ID: 1, UserID: 1}, nil)
+				feedRepo.EXPECT().WalletPosition(gomock.Any(), 1).Return(entities.FeedPosition{TxID: 700, OperationID: 12}, nil)
+			},
+			position: entities.FeedPosition{TxID: 700, OperationID: 12},
+		},
+		{
+			name:        "Stream is resumed",
+			principal:   &entities.Principal{Subject: "api_key:1", Scopes: []string{entities.ScopeAdmin}},
+			lastEventID: "650-9",
+			mockData: func(walletRepo *repositories.MockWalletsManager, feedRepo *repositories.MockFeedManager) {
+				walletRepo.EXPECT().GetByID(gomock.Any(), 1).Return(&entities.Wallet{ID: 1, UserID: 1}, nil)
+			},
+			position: entities.FeedPosition{TxID: 650, OperationID: 9},
+		},
+		{
+			name:        "Invalid Last-Event-ID",
+			principal:   owner,
+			lastEventID: "9",
+			mockData: func(walletRepo *repositories.MockWalletsManager, feedRepo *repositories.MockFeedManager) {
+				walletRepo.EXPECT().GetByID(gomock.Any(), 1).Return(&entities.Wallet{ID: 1, UserID: 1}, nil)
+			},
+			status: 400,
+		},
+		{
+			name:      "Wallet of other user",
+			principal: &entities.Principal{Subject: "2", UserID: 2},
+			mockData: func(walletRepo *repositories.MockWalletsManager, feedRepo *repositories.MockFeedManager) {
+				walletRepo.EXPECT().GetByID(gomock.Any(), 1).Return(&entities.Wallet{ID: 1, UserID: 1}, nil)
+			},
+			status: 404,
+		},
+		{
+			name:      "Missing wallet",
+			principal: owner,
+			mockData: func(walletRepo *repositories.MockWalletsManager, feedRepo *repositories.MockFeedManager) {
+				walletRepo.EXPECT().GetByID(gomock.Any(), 1).Return(nil, fmt.Errorf("wallet is not found"))
+			},
+			status: 404,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			walletRepo := repositories.NewMockWalletsManager(ctrl)
+			feedRepo := repositories.NewMockFeedManager(ctrl)
+			tc.mockData(walletRepo, feedRepo)
+
+			interactor := NewWalletEventsInteractor(walletRepo, feedRepo, nil, adapters.NewHTTPErrorsFactory())
+			_, position, err := interactor.Open(entities.WithPrincipal(context.Background(), tc.principal), 1, tc.lastEventID)
+			if tc.status != 0 {
+				if err == nil || err.GetStatus() != tc.status {
+					t.Errorf("Expected status %d, got %v", tc.status, err)
+				}
+				return
+			}
+			if err != nil || position != tc.position {
+				t.Errorf("Wrong position: %v (%v)", position, err)
+			}
+		})
+	}
+}
+
+// Test updates of wallet's stream
+func TestWalletEventsUsecaseUpdates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	walletRepo := repositories.NewMockWalletsManager(ctrl)
+	feedRepo := repositories.NewMockFeedManager(ctrl)
+	after := entities.FeedPosition{TxID: 700, OperationID: 12}
+
+	walletRepo.EXPECT().GetByID(ctx, 1).Return(&entities.Wallet{ID: 1, UserID: 1, Balance: decimal.NewFromInt(10)}, nil)
+	feedRepo.EXPECT().WalletOperations(ctx, 1, after, walletEventsBatch).Return([]*entities.FeedOperation{{Position: entities.FeedPosition{TxID: 701, OperationID: 15}}}, nil)
+	wallet, operations, err := NewWalletEventsInteractor(walletRepo, feedRepo, nil, adapters.NewHTTPErrorsFactory()).Updates(ctx, 1, after)
+	if err != nil || !wallet.Balance.Equal(decimal.NewFromInt(10)) || len(operations) != 1 {
+		t.Errorf("Wrong updates: %v %v (%v)", wallet, operations, err)
+	}
+}
@@ -6,6 +6,7 @@ package usecases
 
 import (
 	adapters "billing_system_test_task/internal/adapters"
+	entities "billing_system_test_task/internal/entities"
 	context "context"
 	gomock "github.com/golang/mock/gomock"
 	decimal "github.com/shopspring/decimal"
@@ -49,3 +50,48 @@ func (mr *MockWalletUseCaseMockRecorder) Transfer(ctx, walletFrom, walletTo, amo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockWalletUseCase)(nil).Transfer), ctx, walletFrom, walletTo, amount)
 }
+
+// Get mocks base method
+func (m *MockWalletUseCase) Get(ctx context.Context, walletID int) (*entities.Wallet, adapters.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, walletID)
+	ret0, _ := ret[0].(*entities.Wallet)
+	ret1, _ := ret[1].(adapters.Error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockWalletUseCaseMockRecorder) Get(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockWalletUseCase)(nil).Get), ctx, walletID)
+}
+
+// SetFrozen mocks base method
+func (m *MockWalletUseCase) SetFrozen(ctx context.Context, walletID int, frozen bool) (*entities.Wallet, adapters.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFrozen", ctx, walletID, frozen)
+	ret0, _ := ret[0].(*entities.Wallet)
+	ret1, _ := ret[1].(adapters.Error)
+	return ret0, ret1
+}
+
+// SetFrozen indicates an expected call of SetFrozen
+func (mr *MockWalletUseCaseMockRecorder) SetFrozen(ctx, walletID, frozen interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFrozen", reflect.TypeOf((*MockWalletUseCase)(nil).SetFrozen), ctx, walletID, frozen)
+}
+
+// Reconcile mocks base method
+func (m *MockWalletUseCase) Reconcile(ctx context.Context) ([]*entities.BalanceDiscrepancy, adapters.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconcile", ctx)
+	ret0, _ := ret[0].([]*entities.BalanceDiscrepancy)
+	ret1, _ := ret[1].(adapters.Error)
+	return ret0, ret1
+}
+
+// Reconcile indicates an expected call of Reconcile
+func (mr *MockWalletUseCaseMockRecorder) Reconcile(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockWalletUseCase)(nil).Reconcile), ctx)
+}
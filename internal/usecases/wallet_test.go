@@ -23,6 +23,7 @@ type walletUsecaseTest struct {
 	mockQuery           func(ctx context.Context, mockWalletRepo *repositories.MockWalletsManager, mockOperationRepo *repositories.MockOperationsManager, mockTxManager *tx.MockTxBeginner, txMock *tx.MockTx)
 	err                 error
 	expectedResultMatch func(actual interface{}) bool
+	principal           *entities.Principal // owner of wallet 1 when nil
 }
 
 var walletUseCases = []walletUsecaseTest{
@@ -96,7 +97,7 @@ var walletUseCases = []walletUsecaseTest{
 			txMock.EXPECT().Rollback().Return(nil)
 
 		},
-		err: fmt.Errorf("source wallet error"),
+		err: fmt.Errorf("wallet 1 is not found"),
 	},
 	walletUsecaseTest{
 		name:     "Failed wallet transfer (source wallet balance is 0)",
@@ -343,13 +344,55 @@ var walletUseCases = []walletUsecaseTest{
 		},
 		err: fmt.Errorf("tx commit err"),
 	},
+	walletUsecaseTest{
+		name:      "Failed wallet transfer (source wallet of another user is not found)",
+		args:      []driver.Value{1, 2, decimal.NewFromInt(10)},
+		funcName:  "Transfer",
+		principal: &entities.Principal{Subject: "2", UserID: 2, Method: entities.AuthMethodJWT},
+		mockQuery: func(ctx context.Context, mockWalletRepo *repositories.MockWalletsManager, mockOperationRepo *repositories.MockOperationsManager, mockTxManager *tx.MockTxBeginner, txMock *tx.MockTx) {
+			// Start wallet transfer transaction
+			mockTxManager.EXPECT().BeginTrx(ctx, nil).Return(txMock, nil)
+
+			// Receive source wallet owned by the first user
+			mockWalletRepo.EXPECT().WithTx(txMock).Return(mockWalletRepo)
+			mockWalletRepo.EXPECT().GetByID(ctx, 1).Return(&entities.Wallet{ID: 1, UserID: 1, Balance: decimal.NewFromInt(100)}, nil)
+
+			// Rollback wallet transfer transaction
+			txMock.EXPECT().Rollback().Return(nil)
+		},
+		err: fmt.Errorf("wallet 1 is not found"),
+	},
+	walletUsecaseTest{
+		name:      "Success wallet transfer by admin",
+		args:      []driver.Value{1, 2, decimal.NewFromInt(10)},
+		funcName:  "Transfer",
+		principal: &entities.Principal{Subject: "api_key:1", Scopes: []string{entities.ScopeAdmin}, Method: entities.AuthMethodAPIKey},
+		mockQuery: func(ctx context.Context, mockWalletRepo *repositories.MockWalletsManager, mockOperationRepo *repositories.MockOperationsManager, mockTxManager *tx.MockTxBeginner, txMock *tx.MockTx) {
+			mockTxManager.EXPECT().BeginTrx(ctx, nil).Return(txMock, nil)
+			mockWalletRepo.EXPECT().WithTx(txMock).Return(mockWalletRepo).Times(3)
+			mockWalletRepo.EXPECT().GetByID(ctx, 1).Return(&entities.Wallet{ID: 1, UserID: 1, Balance: decimal.NewFromInt(100)}, nil)
+			mockWalletRepo.EXPECT().GetByID(ctx, 2).Return(&entities.Wallet{ID: 2, UserID: 2, Balance: decimal.NewFromInt(100)}, nil)
+			mockWalletRepo.EXPECT().Transfer(ctx, 1, 2, decimal.NewFromInt(10)).Return(1, nil)
+			mockOperationRepo.EXPECT().WithTx(txMock).Return(mockOperationRepo).Times(2)
+			mockOperationRepo.EXPECT().Create(ctx, repositories.Deposit, 1, 2, decimal.NewFromInt(10)).Return(1, nil)
+			mockOperationRepo.EXPECT().Create(ctx, repositories.Withdrawal, 2, 1, decimal.NewFromInt(10)).Return(2, nil)
+			txMock.EXPECT().Commit().Return(nil)
+		},
+		expectedResultMatch: func(actual interface{}) bool {
+			return actual.(int) == 1
+		},
+	},
 }
 
 // Test usecases for wallet
 func TestWalletUsecase(t *testing.T) {
 	for _, tc := range walletUseCases {
 		ctrl := gomock.NewController(t)
-		ctx := context.Background()
+		principal := tc.principal
+		if principal == nil {
+			principal = &entities.Principal{Subject: "1", UserID: 1, Method: entities.AuthMethodJWT}
+		}
+		ctx := entities.WithPrincipal(context.Background(), principal)
 		realArgs := []reflect.Value{
 			reflect.ValueOf(ctx),
 		}
@@ -365,7 +408,11 @@ func TestWalletUsecase(t *testing.T) {
 		walletsRepo := repositories.NewMockWalletsManager(ctrl)
 		operationsRepo := repositories.NewMockOperationsManager(ctrl)
 
-		interactor := NewWalletInteractor(walletsRepo, operationsRepo, errFactory, txManager)
+		outboxRepo := repositories.NewMockOutboxManager(ctrl)
+		outboxRepo.EXPECT().WithTx(gomock.Any()).Return(outboxRepo).AnyTimes()
+		outboxRepo.EXPECT().Add(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+		interactor := NewWalletInteractor(walletsRepo, operationsRepo, outboxRepo, errFactory, txManager)
 
 		for _, arg := range tc.args {
 			realArgs = append(realArgs, reflect.ValueOf(arg))
@@ -417,3 +464,173 @@ func TestWalletUsecase(t *testing.T) {
 		}
 	}
 }
+
+// Test event of transfer is written in its transaction before commit
+func TestWalletTransferOutbox(t *testing.T) {
+	tests := []struct {
+		name      string
+		outboxErr error
+	}{
+		{name: "Event is written"},
+		{name: "Transfer is rolled back without event", outboxErr: fmt.Errorf("[OUTBOX_ADD]: connection error")},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ctx := entities.WithPrincipal(context.Background(), &entities.Principal{Subject: "1", UserID: 1})
+			txManager := tx.NewMockTxBeginner(ctrl)
+			txMock := tx.NewMockTx(ctrl)
+			walletsRepo := repositories.NewMockWalletsManager(ctrl)
+			operationsRepo := repositories.NewMockOperationsManager(ctrl)
+			outboxRepo := repositories.NewMockOutboxManager(ctrl)
+
+			txManager.EXPECT().BeginTrx(ctx, nil).Return(txMock, nil)
+			walletsRepo.EXPECT().WithTx(txMock).Return(walletsRepo)
+			walletsRepo.EXPECT().GetByID(ctx, 1).Return(&entities.Wallet{ID: 1, UserID: 1, Balance: decimal.NewFromInt(100)}, nil)
+			walletsRepo.EXPECT().GetByID(ctx, 2).Return(&entities.Wallet{ID: 2, UserID: 2}, nil)
+			walletsRepo.EXPECT().Transfer(ctx, 1, 2, decimal.NewFromInt(10)).Return(1, nil)
+			operationsRepo.EXPECT().WithTx(txMock).Return(operationsRepo)
+			operationsRepo.EXPECT().Create(ctx, gomock.Any(), gomock.Any(), gomock.Any(), decimal.NewFromInt(10)).Times(2).Return(1, nil)
+			outboxRepo.EXPECT().WithTx(txMock).Return(outboxRepo)
+			add := outboxRepo.EXPECT().Add(ctx, entities.EventTransferCompleted, map[string]interface{}{
+				"wallet_from": 1,
+				"wallet_to":   2,
+				"amount":      decimal.NewFromInt(10),
+			}).Return(tc.outboxErr)
+			if tc.outboxErr == nil {
+				txMock.EXPECT().Commit().Return(nil).After(add)
+			} else {
+				txMock.EXPECT().Rollback().Return(nil).After(add)
+			}
+
+			interactor := NewWalletInteractor(walletsRepo, operationsRepo, outboxRepo, adapters.NewHTTPErrorsFactory(), txManager)
+			_, err := interactor.Transfer(ctx, 1, 2, decimal.NewFromInt(10))
+			if (err != nil) != (tc.outboxErr != nil) {
+				t.Errorf("Unexpected result: %v", err)
+			}
+		})
+	}
+}
+
+// Test transaction of transfer is rolled back when source wallet is not owned by caller
+func TestWalletTransferOwnerRollback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := entities.WithPrincipal(context.Background(), &entities.Principal{Subject: "2", UserID: 2})
+	txManager := tx.NewMockTxBeginner(ctrl)
+	txMock := tx.NewMockTx(ctrl)
+	walletsRepo := repositories.NewMockWalletsManager(ctrl)
+
+	txManager.EXPECT().BeginTrx(ctx, nil).Return(txMock, nil)
+	walletsRepo.EXPECT().WithTx(txMock).Return(walletsRepo)
+	get := walletsRepo.EXPECT().GetByID(ctx, 1).Return(&entities.Wallet{ID: 1, UserID: 1, Balance: decimal.NewFromInt(100)}, nil)
+	txMock.EXPECT().Rollback().Return(nil).After(get)
+
+	interactor := NewWalletInteractor(walletsRepo, repositories.NewMockOperationsManager(ctrl), repositories.NewMockOutboxManager(ctrl), adapters.NewHTTPErrorsFactory(), txManager)
+	if _, err := interactor.Transfer(ctx, 1, 2, decimal.NewFromInt(10)); err == nil || err.GetStatus() != 404 {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
+
+// Test reading of wallet is allowed for its owner and admin
+func TestWalletUsecaseGet(t *testing.T) {
+	wallet := &entities.Wallet{ID: 3, UserID: 1, Balance: decimal.NewFromInt(100), Currency: "USD"}
+	tests := []struct {
+		name      string
+		principal *entities.Principal
+		getErr    error
+		status    int
+	}{
+		{name: "Owner reads wallet", principal: &entities.Principal{Subject: "1", UserID: 1}},
+		{name: "Admin reads wallet", principal: &entities.Principal{Subject: "admin", Scopes: []string{entities.ScopeAdmin}}},
+		{name: "Wallet of other user is not found", principal: &entities.Principal{Subject: "2", UserID: 2}, status: 404},
+		{name: "Wallet is not found", principal: &entities.Principal{Subject: "1", UserID: 1}, getErr: fmt.Errorf("[WALLET_GET_BY_ID]: sql: no rows in result set"), status: 404},
+		{name: "Admin gets error of missing wallet", principal: &entities.Principal{Subject: "admin", Scopes: []string{entities.ScopeAdmin}}, getErr: fmt.Errorf("[WALLET_GET_BY_ID]: sql: no rows in result set"), status: 404},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ctx := entities.WithPrincipal(context.Background(), tc.principal)
+			walletsRepo := repositories.NewMockWalletsManager(ctrl)
+			walletsRepo.EXPECT().GetByID(ctx, 3).Return(wallet, tc.getErr)
+
+			interactor := NewWalletInteractor(walletsRepo, nil, nil, adapters.NewHTTPErrorsFactory(), nil)
+			result, err := interactor.Get(ctx, 3)
+			if tc.status != 0 {
+				if err == nil || err.GetStatus() != tc.status {
+					t.Errorf("Expected status %d, got %v", tc.status, err)
+				}
+				return
+			}
+			if err != nil || result != wallet {
+				t.Errorf("Unexpected result: %v (%v)", result, err)
+			}
+		})
+	}
+}
+
+// Test only admins freeze wallets
+func TestWalletUsecaseSetFrozen(t *testing.T) {
+	admin := &entities.Principal{Subject: "admin", Scopes: []string{entities.ScopeAdmin}}
+	tests := []struct {
+		name      string
+		principal *entities.Principal
+		getErr    error
+		setErr    error
+		status    int
+	}{
+		{name: "Admin freezes wallet", principal: admin},
+		{name: "User is forbidden", principal: &entities.Principal{Subject: "1", UserID: 1}, status: 403},
+		{name: "Wallet is not found", principal: admin, getErr: fmt.Errorf("[WALLET_GET_BY_ID]: sql: no rows in result set"), status: 404},
+		{name: "Wallet is not updated", principal: admin, setErr: fmt.Errorf("[WALLET_SET_FROZEN]: wallet 3 does not exist"), status: 400},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ctx := entities.WithPrincipal(context.Background(), tc.principal)
+			walletsRepo := repositories.NewMockWalletsManager(ctrl)
+			if tc.status != 403 {
+				walletsRepo.EXPECT().GetByID(ctx, 3).Return(&entities.Wallet{ID: 3, UserID: 1}, tc.getErr)
+			}
+			if tc.status != 403 && tc.getErr == nil {
+				walletsRepo.EXPECT().SetFrozen(ctx, 3, true).Return(tc.setErr)
+			}
+
+			interactor := NewWalletInteractor(walletsRepo, nil, nil, adapters.NewHTTPErrorsFactory(), nil)
+			result, err := interactor.SetFrozen(ctx, 3, true)
+			if tc.status != 0 {
+				if err == nil || err.GetStatus() != tc.status {
+					t.Errorf("Expected status %d, got %v", tc.status, err)
+				}
+				return
+			}
+			if err != nil || !result.Frozen {
+				t.Errorf("Unexpected result: %v (%v)", result, err)
+			}
+		})
+	}
+}
+
+// Test only admins reconcile balances
+func TestWalletUsecaseReconcile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	walletsRepo := repositories.NewMockWalletsManager(ctrl)
+	interactor := NewWalletInteractor(walletsRepo, nil, nil, adapters.NewHTTPErrorsFactory(), nil)
+
+	userCtx := entities.WithPrincipal(context.Background(), &entities.Principal{Subject: "1", UserID: 1})
+	if _, err := interactor.Reconcile(userCtx); err == nil || err.GetStatus() != 403 {
+		t.Errorf("Expected forbidden, got %v", err)
+	}
+
+	adminCtx := entities.WithPrincipal(context.Background(), &entities.Principal{Subject: "admin", Scopes: []string{entities.ScopeAdmin}})
+	discrepancies := []*entities.BalanceDiscrepancy{{WalletID: 3, Balance: decimal.NewFromInt(10), Ledger: decimal.Zero}}
+	walletsRepo.EXPECT().Reconcile(adminCtx).Return(discrepancies, nil)
+	result, err := interactor.Reconcile(adminCtx)
+	if err != nil || !reflect.DeepEqual(result, discrepancies) {
+		t.Errorf("Unexpected result: %v (%v)", result, err)
+	}
+}
@@ -0,0 +1,209 @@
+package usecases
+
+import (
+	"billing_system_test_task/internal/adapters"
+	trx "billing_system_test_task/internal/adapters/tx"
+	"billing_system_test_task/internal/auth"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/repositories"
+	"billing_system_test_task/internal/webhooks"
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"time"
+)
+
+// deliveriesBatch is the number of deliveries sent by one run of the worker
+const deliveriesBatch = 50
+
+// attemptsLogLimit is the number of the latest attempts returned in delivery log
+const attemptsLogLimit = 100
+
+// WebhookUsecase represents contracts for webhook's use cases
+type WebhookUsecase interface {
+	CreateSubscription(ctx context.Context, subscription *entities.WebhookSubscription) (*entities.WebhookSubscription, adapters.Error)
+	ListSubscriptions(ctx context.Context) ([]*entities.WebhookSubscription, adapters.Error)
+	DeleteSubscription(ctx context.Context, id int) adapters.Error
+	ListAttempts(ctx context.Context, subscriptionID int) ([]*entities.WebhookAttempt, adapters.Error)
+	Redeliver(ctx context.Context, deliveryID int) adapters.Error
+	DeliverDue(ctx context.Context) (int, error)
+}
+
+type WebhookInteractor struct {
+	webhookRepo   repositories.WebhooksManager
+	sender        webhooks.Sender
+	errorsFactory adapters.ErrorsFactory
+}
+
+func NewWebhookInteractor(webhookRepo repositories.WebhooksManager, sender webhooks.Sender, errorsFactory adapters.ErrorsFactory) *WebhookInteractor {
+	return &WebhookInteractor{
+		webhookRepo:   webhookRepo,
+		sender:        sender,
+		errorsFactory: errorsFactory,
+	}
+}
+
+// CreateSubscription validates subscription and stores it; secret is generated when it is not given.
+// Addresses of host names are checked by sender at dial time
+func (wi *WebhookInteractor) CreateSubscription(ctx context.Context, subscription *entities.WebhookSubscription) (*entities.WebhookSubscription, adapters.Error) {
+	if permissionErr := requirePermission(ctx, wi.errorsFactory, auth.PermWebhooksManage); permissionErr != nil {
+		return nil, permissionErr
+	}
+	subscriptionURL, parseErr := url.Parse(subscription.URL)
+	if parseErr != nil || (subscriptionURL.Scheme != "http" && subscriptionURL.Scheme != "https") || subscriptionURL.Hostname() == "" {
+		return nil, wi.errorsFactory.DefaultError(fmt.Errorf("invalid webhook url: %s", subscription.URL))
+	}
+	if ip := net.ParseIP(subscriptionURL.Hostname()); ip != nil && !webhooks.IsPublicAddress(ip) {
+		return nil, wi.errorsFactory.DefaultError(fmt.Errorf("webhook address %s is not public", ip))
+	}
+	if len(subscription.EventTypes) == 0 {
+		return nil, wi.errorsFactory.DefaultError(fmt.Errorf("event types of webhook are not set"))
+	}
+	for _, eventType := range subscription.EventTypes {
+		if !containsEventType(eventType) {
+			return nil, wi.errorsFactory.DefaultError(fmt.Errorf("unknown event type: %s", eventType))
+		}
+	}
+	if subscription.Secret == "" {
+		secret, secretErr := webhooks.GenerateSecret()
+		if secretErr != nil {
+			return nil, wi.errorsFactory.DefaultError(secretErr)
+		}
+		subscription.Secret = secret
+	}
+	created, createErr := wi.webhookRepo.CreateSubscription(ctx, subscription)
+	if createErr != nil {
+		return nil, wi.errorsFactory.DefaultError(createErr)
+	}
+	return created, nil
+}
+
+// ListSubscriptions returns active subscriptions
+func (wi *WebhookInteractor) ListSubscriptions(ctx context.Context) ([]*entities.WebhookSubscription, adapters.Error) {
+	if permissionErr := requirePermission(ctx, wi.errorsFactory, auth.PermWebhooksManage); permissionErr != nil {
+		return nil, permissionErr
+	}
+	subscriptions, listErr := wi.webhookRepo.ListSubscriptions(ctx)
+	if listErr != nil {
+		return nil, wi.errorsFactory.DefaultError(listErr)
+	}
+	return subscriptions, nil
+}
+
+// DeleteSubscription deactivates subscription
+func (wi *WebhookInteractor) DeleteSubscription(ctx context.Context, id int) adapters.Error {
+	if permissionErr := requirePermission(ctx, wi.errorsFactory, auth.PermWebhooksManage); permissionErr != nil {
+		return permissionErr
+	}
+	deleteErr := wi.webhookRepo.DeleteSubscription(ctx, id)
+	if deleteErr == repositories.ErrWebhookNotFound {
+		return wi.errorsFactory.NotFound(deleteErr)
+	}
+	if deleteErr != nil {
+		return wi.errorsFactory.DefaultError(deleteErr)
+	}
+	return nil
+}
+
+// ListAttempts returns delivery log of subscription
+func (wi *WebhookInteractor) ListAttempts(ctx context.Context, subscriptionID int) ([]*entities.WebhookAttempt, adapters.Error) {
+	if permissionErr := requirePermission(ctx, wi.errorsFactory, auth.PermWebhooksManage); permissionErr != nil {
+		return nil, permissionErr
+	}
+	attempts, listErr := wi.webhookRepo.ListAttempts(ctx, subscriptionID, attemptsLogLimit)
+	if listErr != nil {
+		return nil, wi.errorsFactory.DefaultError(listErr)
+	}
+	return attempts, nil
+}
+
+// Redeliver schedules delivery to be sent again
+func (wi *WebhookInteractor) Redeliver(ctx context.Context, deliveryID int) adapters.Error {
+	if permissionErr := requirePermission(ctx, wi.errorsFactory, auth.PermWebhooksManage); permissionErr != nil {
+		return permissionErr
+	}
+	redeliverErr := wi.webhookRepo.Redeliver(ctx, deliveryID)
+	if redeliverErr == repositories.ErrWebhookNotFound {
+		return wi.errorsFactory.NotFound(redeliverErr)
+	}
+	if redeliverErr != nil {
+		return wi.errorsFactory.DefaultError(redeliverErr)
+	}
+	return nil
+}
+
+// Publish stores committed outbox event and schedules its deliveries to subscriptions; repeated event is ignored
+func (wi *WebhookInteractor) Publish(ctx context.Context, event *entities.OutboxEvent) error {
+	_, createErr := wi.webhookRepo.CreateEvent(ctx, event.ID, event.Type, event.Payload)
+	return createErr
+}
+
+// PublishTx stores committed outbox event in transaction of dispatcher which marks the event as sent
+func (wi *WebhookInteractor) PublishTx(ctx context.Context, tx trx.Tx, event *entities.OutboxEvent) error {
+	_, createErr := wi.webhookRepo.WithTx(tx).CreateEvent(ctx, event.ID, event.Type, event.Payload)
+	return createErr
+}
+
+// DeliverDue sends due deliveries and records attempts; failed deliveries are retried with backoff
+// until webhooks.MaxAttempts. Returns the number of sent deliveries.
+func (wi *WebhookInteractor) DeliverDue(ctx context.Context) (int, error) {
+	deliveries, claimErr := wi.webhookRepo.ClaimDueDeliveries(ctx, deliveriesBatch)
+	if claimErr != nil {
+		return 0, claimErr
+	}
+	for _, delivery := range deliveries {
+		started := time.Now()
+		statusCode, sendErr := wi.sender.Send(ctx, delivery)
+		attempt := &entities.WebhookAttempt{
+			DeliveryID: delivery.ID,
+			EventID:    delivery.Event.ID,
+			EventType:  delivery.Event.Type,
+			Attempt:    delivery.Attempts + 1,
+			StatusCode: statusCode,
+			Duration:   time.Since(started),
+		}
+		status, retryIn := entities.DeliverySucceeded, time.Duration(0)
+		if sendErr != nil {
+			attempt.Error = sendErr.Error()
+			status, retryIn = entities.DeliveryPending, webhooks.Backoff(attempt.Attempt)
+			if attempt.Attempt >= webhooks.MaxAttempts {
+				status = entities.DeliveryFailed
+			}
+		}
+		if recordErr := wi.webhookRepo.RecordAttempt(ctx, attempt, status, retryIn); recordErr != nil {
+			return 0, recordErr
+		}
+	}
+	return len(deliveries), nil
+}
+
+// Run delivers webhooks until context is cancelled; batches are sent without waiting while deliveries are due
+func (wi *WebhookInteractor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		sent, deliverErr := wi.DeliverDue(ctx)
+		if deliverErr != nil {
+			log.Printf("[ERROR] Error of webhooks delivery: %s", deliverErr)
+		}
+		if sent == deliveriesBatch && ctx.Err() == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func containsEventType(eventType string) bool {
+	for _, known := range entities.WebhookEventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
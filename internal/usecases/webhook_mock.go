@@ -0,0 +1,124 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/usecases/webhook.go
+
+// Package usecases is a generated GoMock package.
+package usecases
+
+import (
+	adapters "billing_system_test_task/internal/adapters"
+	entities "billing_system_test_task/internal/entities"
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockWebhookUsecase is a mock of WebhookUsecase interface
+type MockWebhookUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookUsecaseMockRecorder
+}
+
+// MockWebhookUsecaseMockRecorder is the mock recorder for MockWebhookUsecase
+type MockWebhookUsecaseMockRecorder struct {
+	mock *MockWebhookUsecase
+}
+
+// NewMockWebhookUsecase creates a new mock instance
+func NewMockWebhookUsecase(ctrl *gomock.Controller) *MockWebhookUsecase {
+	mock := &MockWebhookUsecase{ctrl: ctrl}
+	mock.recorder = &MockWebhookUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWebhookUsecase) EXPECT() *MockWebhookUsecaseMockRecorder {
+	return m.recorder
+}
+
+// CreateSubscription mocks base method
+func (m *MockWebhookUsecase) CreateSubscription(ctx context.Context, subscription *entities.WebhookSubscription) (*entities.WebhookSubscription, adapters.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubscription", ctx, subscription)
+	ret0, _ := ret[0].(*entities.WebhookSubscription)
+	ret1, _ := ret[1].(adapters.Error)
+	return ret0, ret1
+}
+
+// CreateSubscription indicates an expected call of CreateSubscription
+func (mr *MockWebhookUsecaseMockRecorder) CreateSubscription(ctx, subscription interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscription", reflect.TypeOf((*MockWebhookUsecase)(nil).CreateSubscription), ctx, subscription)
+}
+
+// ListSubscriptions mocks base method
+func (m *MockWebhookUsecase) ListSubscriptions(ctx context.Context) ([]*entities.WebhookSubscription, adapters.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubscriptions", ctx)
+	ret0, _ := ret[0].([]*entities.WebhookSubscription)
+	ret1, _ := ret[1].(adapters.Error)
+	return ret0, ret1
+}
+
+// ListSubscriptions indicates an expected call of ListSubscriptions
+func (mr *MockWebhookUsecaseMockRecorder) ListSubscriptions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscriptions", reflect.TypeOf((*MockWebhookUsecase)(nil).ListSubscriptions), ctx)
+}
+
+// DeleteSubscription mocks base method
+func (m *MockWebhookUsecase) DeleteSubscription(ctx context.Context, id int) adapters.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSubscription", ctx, id)
+	ret0, _ := ret[0].(adapters.Error)
+	return ret0
+}
+
+// DeleteSubscription indicates an expected call of DeleteSubscription
+func (mr *MockWebhookUsecaseMockRecorder) DeleteSubscription(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubscription", reflect.TypeOf((*MockWebhookUsecase)(nil).DeleteSubscription), ctx, id)
+}
+
+// ListAttempts mocks base method
+func (m *MockWebhookUsecase) ListAttempts(ctx context.Context, subscriptionID int) ([]*entities.WebhookAttempt, adapters.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAttempts", ctx, subscriptionID)
+	ret0, _ := ret[0].([]*entities.WebhookAttempt)
+	ret1, _ := ret[1].(adapters.Error)
+	return ret0, ret1
+}
+
+// ListAttempts indicates an expected call of ListAttempts
+func (mr *MockWebhookUsecaseMockRecorder) ListAttempts(ctx, subscriptionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAttempts", reflect.TypeOf((*MockWebhookUsecase)(nil).ListAttempts), ctx, subscriptionID)
+}
+
+// Redeliver mocks base method
+func (m *MockWebhookUsecase) Redeliver(ctx context.Context, deliveryID int) adapters.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeliver", ctx, deliveryID)
+	ret0, _ := ret[0].(adapters.Error)
+	return ret0
+}
+
+// Redeliver indicates an expected call of Redeliver
+func (mr *MockWebhookUsecaseMockRecorder) Redeliver(ctx, deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeliver", reflect.TypeOf((*MockWebhookUsecase)(nil).Redeliver), ctx, deliveryID)
+}
+
+// DeliverDue mocks base method
+func (m *MockWebhookUsecase) DeliverDue(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeliverDue", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeliverDue indicates an expected call of DeliverDue
+func (mr *MockWebhookUsecaseMockRecorder) DeliverDue(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeliverDue", reflect.TypeOf((*MockWebhookUsecase)(nil).DeliverDue), ctx)
+}
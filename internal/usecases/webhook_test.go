@@ -0,0 +1,197 @@
+package usecases
+
+import (
+	"billing_system_test_task/internal/adapters"
+	"billing_system_test_task/internal/adapters/tx"
+	"billing_system_test_task/internal/auth"
+	"billing_system_test_task/internal/entities"
+	"billing_system_test_task/internal/repositories"
+	"billing_system_test_task/internal/webhooks"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// webhooksAdminContext returns context of admin managing webhooks
+func webhooksAdminContext() context.Context {
+	return entities.WithPrincipal(context.Background(), &entities.Principal{
+		Subject: "api_key:1",
+		Scopes:  []string{entities.ScopeAdmin},
+		Method:  entities.AuthMethodAPIKey,
+	})
+}
+
+// Test subscriptions are validated before saving
+func TestWebhookUsecaseCreateSubscription(t *testing.T) {
+	finance := &entities.Principal{Subject: "api_key:2", Scopes: []string{auth.RoleFinance}, Method: entities.AuthMethodAPIKey}
+	tests := []struct {
+		name         string
+		principal    *entities.Principal
+		subscription *entities.WebhookSubscription
+		saved        bool
+		status       int
+		err          string
+	}{
+		{name: "Valid subscription", subscription: &entities.WebhookSubscription{URL: "https://example.com/hook", EventTypes: []string{entities.EventTransferCompleted}}, saved: true},
+		{name: "Invalid scheme", subscription: &entities.WebhookSubscription{URL: "ftp://example.com/hook", EventTypes: []string{entities.EventTransferCompleted}}, err: "invalid webhook url"},
+		{name: "No event types", subscription: &entities.WebhookSubscription{URL: "https://example.com/hook"}, err: "event types of webhook are not set"},
+		{name: "Unknown event type", subscription: &entities.WebhookSubscription{URL: "https://example.com/hook", EventTypes: []string{"wallet.deleted"}}, err: "unknown event type: wallet.deleted"},
+		{name: "Loopback address", subscription: &entities.WebhookSubscription{URL: "http://127.0.0.1:8000/api/users", EventTypes: []string{entities.EventTransferCompleted}}, err: "webhook address 127.0.0.1 is not public"},
+		{name: "Metadata address", subscription: &entities.WebhookSubscription{URL: "http://169.254.169.254/latest", EventTypes: []string{entities.EventTransferCompleted}}, err: "webhook address 169.254.169.254 is not public"},
+		{name: "Private IPv6 address", subscription: &entities.WebhookSubscription{URL: "http://[fd00::1]/hook", EventTypes: []string{entities.EventTransferCompleted}}, err: "webhook address fd00::1 is not public"},
+		{name: "Finance is forbidden", principal: finance, subscription: &entities.WebhookSubscription{URL: "https://example.com/hook", EventTypes: []string{entities.EventTransferCompleted}}, status: 403, err: "webhooks:manage permission is required"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockWebhooks := repositories.NewMockWebhooksManager(ctrl)
+			if tc.saved {
+				mockWebhooks.EXPECT().CreateSubscription(gomock.Any(), tc.subscription).Return(tc.subscription, nil)
+			}
+
+			ctx := webhooksAdminContext()
+			if tc.principal != nil {
+				ctx = entities.WithPrincipal(context.Background(), tc.principal)
+			}
+			status := tc.status
+			if status == 0 {
+				status = 400
+			}
+			saved, err := NewWebhookInteractor(mockWebhooks, nil, adapters.NewHTTPErrorsFactory()).CreateSubscription(ctx, tc.subscription)
+			if tc.err != "" {
+				if err == nil || err.GetStatus() != status || !strings.Contains(err.GetError().Error(), tc.err) {
+					t.Errorf("Expected error '%s', got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil || !strings.HasPrefix(saved.Secret, "whsec_") {
+				t.Errorf("Wrong saved subscription: %+v (%v)", saved, err)
+			}
+		})
+	}
+}
+
+// Test due deliveries are sent to receiver and attempts are recorded with retries
+func TestWebhookUsecaseDeliverDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	received := make(chan string, 3)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if webhooks.Verify("secret", r.Header.Get(webhooks.SignatureHeader), body, time.Minute, time.Now()) != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		received <- r.URL.Path
+		if r.URL.Path == "/failing" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer receiver.Close()
+
+	delivery := func(id, attempts int, path string) *entities.WebhookDelivery {
+		return &entities.WebhookDelivery{
+			ID:           id,
+			Status:       entities.DeliveryPending,
+			Attempts:     attempts,
+			Subscription: entities.WebhookSubscription{ID: 1, URL: receiver.URL + path, Secret: "secret"},
+			Event:        entities.WebhookEvent{ID: 5, Type: entities.EventTransferCompleted, Payload: []byte(`{"amount":"10"}`)},
+		}
+	}
+	mockWebhooks := repositories.NewMockWebhooksManager(ctrl)
+	mockWebhooks.EXPECT().ClaimDueDeliveries(ctx, deliveriesBatch).Return([]*entities.WebhookDelivery{
+		delivery(1, 0, "/ok"),
+		delivery(2, 2, "/failing"),
+		delivery(3, webhooks.MaxAttempts-1, "/failing"),
+	}, nil)
+	recorded := map[int]string{}
+	mockWebhooks.EXPECT().RecordAttempt(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Times(3).
+		DoAndReturn(func(ctx context.Context, attempt *entities.WebhookAttempt, status string, retryIn time.Duration) error {
+			recorded[attempt.DeliveryID] = status
+			switch attempt.DeliveryID {
+			case 1:
+				if attempt.Attempt != 1 || attempt.StatusCode != 200 || attempt.Error != "" || retryIn != 0 {
+					t.Errorf("Wrong successful attempt: %+v %s", attempt, retryIn)
+				}
+			case 2:
+				if attempt.Attempt != 3 || attempt.StatusCode != 500 || attempt.Error == "" || retryIn != webhooks.Backoff(3) {
+					t.Errorf("Wrong failed attempt: %+v %s", attempt, retryIn)
+				}
+			}
+			return nil
+		})
+
+	sent, err := NewWebhookInteractor(mockWebhooks, webhooks.NewHTTPSender(time.Second, func(net.IP) bool { return true }), adapters.NewHTTPErrorsFactory()).DeliverDue(ctx)
+	if err != nil || sent != 3 {
+		t.Fatalf("Unexpected result: %d (%v)", sent, err)
+	}
+	if len(received) != 3 {
+		t.Errorf("Expected 3 signed requests, got %d", len(received))
+	}
+	expected := map[int]string{1: entities.DeliverySucceeded, 2: entities.DeliveryPending, 3: entities.DeliveryFailed}
+	for id, status := range expected {
+		if recorded[id] != status {
+			t.Errorf("Delivery %d: expected %s, got %s", id, status, recorded[id])
+		}
+	}
+}
+
+// Test missing subscriptions and deliveries are not found
+func TestWebhookUsecaseNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := webhooksAdminContext()
+	mockWebhooks := repositories.NewMockWebhooksManager(ctrl)
+	interactor := NewWebhookInteractor(mockWebhooks, nil, adapters.NewHTTPErrorsFactory())
+
+	mockWebhooks.EXPECT().DeleteSubscription(ctx, 7).Return(repositories.ErrWebhookNotFound)
+	if err := interactor.DeleteSubscription(ctx, 7); err == nil || err.GetStatus() != 404 {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	mockWebhooks.EXPECT().Redeliver(ctx, 7).Return(repositories.ErrWebhookNotFound)
+	if err := interactor.Redeliver(ctx, 7); err == nil || err.GetStatus() != 404 {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	user := entities.WithPrincipal(context.Background(), &entities.Principal{Subject: "7", UserID: 7})
+	if err := interactor.Redeliver(user, 7); err == nil || err.GetStatus() != 403 {
+		t.Errorf("Expected forbidden error, got %v", err)
+	}
+}
+
+// Test committed outbox events are stored for webhooks
+func TestWebhookUsecasePublish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	mockWebhooks := repositories.NewMockWebhooksManager(ctrl)
+	interactor := NewWebhookInteractor(mockWebhooks, nil, adapters.NewHTTPErrorsFactory())
+	event := &entities.OutboxEvent{ID: 1, Type: entities.EventTransferCompleted, Payload: []byte(`{"amount":"10"}`)}
+
+	mockWebhooks.EXPECT().CreateEvent(ctx, int64(1), entities.EventTransferCompleted, []byte(event.Payload)).Return(&entities.WebhookEvent{ID: 5}, nil)
+	if err := interactor.Publish(ctx, event); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	mockWebhooks.EXPECT().CreateEvent(ctx, int64(1), entities.EventTransferCompleted, []byte(event.Payload)).Return(nil, fmt.Errorf("[WEBHOOK_EVENT_CREATE]: connection error"))
+	if err := interactor.Publish(ctx, event); err == nil {
+		t.Error("Expected error of event storing")
+	}
+
+	// Event is stored in transaction of dispatcher
+	txMock := tx.NewMockTx(ctrl)
+	mockWebhooks.EXPECT().WithTx(txMock).Return(mockWebhooks)
+	mockWebhooks.EXPECT().CreateEvent(ctx, int64(1), entities.EventTransferCompleted, []byte(event.Payload)).Return(nil, nil)
+	if err := interactor.PublishTx(ctx, txMock, event); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
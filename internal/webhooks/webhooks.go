@@ -0,0 +1,213 @@
+package webhooks
+
+import (
+	"billing_system_test_task/internal/entities"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Headers of webhook requests
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Retries of failed deliveries: attempts are delayed by BaseBackoff doubled after each attempt up to MaxBackoff
+const (
+	MaxAttempts = 8
+	BaseBackoff = 30 * time.Second
+	MaxBackoff  = 6 * time.Hour
+)
+
+// maxResponseSize is the size of receiver's response which is read to reuse connection
+const maxResponseSize = 64 << 10
+
+// Body is JSON body of webhook request
+type Body struct {
+	ID        int             `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// GenerateSecret returns random secret of subscription
+func GenerateSecret() (string, error) {
+	random := make([]byte, 24)
+	if _, readErr := rand.Read(random); readErr != nil {
+		return "", fmt.Errorf("error of webhook secret generation: %s", readErr)
+	}
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+// Sign returns value of signature header: timestamp and HMAC-SHA256 of "timestamp.body" in hex,
+// e.g. "t=1669118400,v1=5257a8...". Timestamp is signed to prevent replay of old requests.
+func Sign(secret string, timestamp int64, body []byte) string {
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(signature(secret, timestamp, body)))
+}
+
+// Verify checks signature header of received webhook; requests older than tolerance are rejected
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var (
+		timestamp int64
+		signed    []byte
+	)
+	for _, part := range strings.Split(header, ",") {
+		keyValue := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(keyValue) != 2 {
+			continue
+		}
+		switch keyValue[0] {
+		case "t":
+			timestamp, _ = strconv.ParseInt(keyValue[1], 10, 64)
+		case "v1":
+			signed, _ = hex.DecodeString(keyValue[1])
+		}
+	}
+	if timestamp == 0 || signed == nil {
+		return fmt.Errorf("malformed signature header")
+	}
+	if age := now.Sub(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("signature timestamp is out of tolerance")
+	}
+	if !hmac.Equal(signed, signature(secret, timestamp, body)) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+func signature(secret string, timestamp int64, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// Backoff returns delay before the attempt following given one
+func Backoff(attempt int) time.Duration {
+	delay := BaseBackoff
+	for i := 1; i < attempt && delay < MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > MaxBackoff {
+		return MaxBackoff
+	}
+	return delay
+}
+
+// Sender defines contracts for sending of webhook requests
+type Sender interface {
+	Send(ctx context.Context, delivery *entities.WebhookDelivery) (int, error)
+}
+
+// HTTPSender implements Sender interface
+type HTTPSender struct {
+	client       *http.Client
+	now          func() time.Time
+	allowAddress func(ip net.IP) bool
+}
+
+// NewHTTPSender returns sender with request timeout; connections to addresses rejected by allowAddress
+// are refused at dial time, nil allows public addresses only
+func NewHTTPSender(timeout time.Duration, allowAddress func(ip net.IP) bool) *HTTPSender {
+	if allowAddress == nil {
+		allowAddress = IsPublicAddress
+	}
+	hs := &HTTPSender{now: time.Now, allowAddress: allowAddress}
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		// Resolved address is checked, so host names pointing to internal addresses are refused too
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, splitErr := net.SplitHostPort(address)
+			if splitErr != nil {
+				return fmt.Errorf("invalid webhook address %s: %s", address, splitErr)
+			}
+			if ip := net.ParseIP(host); ip == nil || !hs.allowAddress(ip) {
+				return fmt.Errorf("webhook address %s is not public", host)
+			}
+			return nil
+		},
+	}
+	hs.client = &http.Client{
+		Timeout: timeout,
+		// Proxy is not used, it would be dialed instead of the receiver
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		// Redirects are not followed, receiver must answer on subscribed URL
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return hs
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, RFC 6598
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// IsPublicAddress reports whether ip is a public unicast address; loopback, private, link-local,
+// multicast, unspecified and shared addresses are not public
+func IsPublicAddress(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4[0] != 0 && ip4[0] != 255 && !sharedAddressSpace.Contains(ip4)
+	}
+	return true
+}
+
+// Send posts signed event to subscription's URL and returns status code of response;
+// responses other than 2xx are errors
+func (hs *HTTPSender) Send(ctx context.Context, delivery *entities.WebhookDelivery) (int, error) {
+	body, marshalErr := json.Marshal(Body{
+		ID:        delivery.Event.ID,
+		Type:      delivery.Event.Type,
+		CreatedAt: delivery.Event.CreatedAt.UTC(),
+		Data:      delivery.Event.Payload,
+	})
+	if marshalErr != nil {
+		return 0, fmt.Errorf("error of webhook body marshalling: %s", marshalErr)
+	}
+	request, requestErr := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Subscription.URL, bytes.NewReader(body))
+	if requestErr != nil {
+		return 0, fmt.Errorf("error of webhook request: %s", requestErr)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "billing-webhooks/1.0")
+	request.Header.Set(EventHeader, delivery.Event.Type)
+	request.Header.Set(DeliveryHeader, strconv.Itoa(delivery.ID))
+	request.Header.Set(SignatureHeader, Sign(delivery.Subscription.Secret, hs.now().Unix(), body))
+
+	response, sendErr := hs.client.Do(request)
+	if sendErr != nil {
+		return 0, fmt.Errorf("error of webhook sending: %s", sendErr)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(response.Body, maxResponseSize))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
@@ -0,0 +1,164 @@
+package webhooks
+
+import (
+	"billing_system_test_task/internal/entities"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test signatures of webhook requests
+func TestSignVerify(t *testing.T) {
+	now := time.Unix(1669118400, 0)
+	body := []byte(`{"id":1}`)
+	header := Sign("secret", now.Unix(), body)
+	if !strings.HasPrefix(header, "t=1669118400,v1=") {
+		t.Fatalf("Wrong signature header: %s", header)
+	}
+
+	testCases := []struct {
+		name    string
+		secret  string
+		header  string
+		body    []byte
+		now     time.Time
+		isError bool
+	}{
+		{name: "valid", secret: "secret", header: header, body: body, now: now},
+		{name: "valid within tolerance", secret: "secret", header: header, body: body, now: now.Add(4 * time.Minute)},
+		{name: "other secret", secret: "other", header: header, body: body, now: now, isError: true},
+		{name: "changed body", secret: "secret", header: header, body: []byte(`{"id":2}`), now: now, isError: true},
+		{name: "old request", secret: "secret", header: header, body: body, now: now.Add(6 * time.Minute), isError: true},
+		{name: "malformed header", secret: "secret", header: "v1=abc", body: body, now: now, isError: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			verifyErr := Verify(testCase.secret, testCase.header, testCase.body, 5*time.Minute, testCase.now)
+			if (verifyErr != nil) != testCase.isError {
+				t.Errorf("Expected error %v, got %v", testCase.isError, verifyErr)
+			}
+		})
+	}
+}
+
+// Test delays between attempts
+func TestBackoff(t *testing.T) {
+	testCases := []struct {
+		attempt int
+		delay   time.Duration
+	}{
+		{attempt: 1, delay: 30 * time.Second},
+		{attempt: 2, delay: time.Minute},
+		{attempt: 5, delay: 8 * time.Minute},
+		{attempt: 20, delay: MaxBackoff},
+	}
+	for _, testCase := range testCases {
+		if delay := Backoff(testCase.attempt); delay != testCase.delay {
+			t.Errorf("Attempt %d: expected %s, got %s", testCase.attempt, testCase.delay, delay)
+		}
+	}
+}
+
+// Test sending of signed requests to receiver
+func TestHTTPSenderSend(t *testing.T) {
+	var (
+		status   = http.StatusOK
+		received *http.Request
+		body     []byte
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	now := time.Unix(1669118400, 0)
+	sender := NewHTTPSender(time.Second, func(net.IP) bool { return true })
+	sender.now = func() time.Time { return now }
+	delivery := &entities.WebhookDelivery{
+		ID:           3,
+		Subscription: entities.WebhookSubscription{ID: 1, URL: receiver.URL + "/hook", Secret: "secret"},
+		Event: entities.WebhookEvent{
+			ID: 5, Type: entities.EventUserCreated, Payload: json.RawMessage(`{"user_id":1}`), CreatedAt: now,
+		},
+	}
+
+	code, sendErr := sender.Send(context.Background(), delivery)
+	if sendErr != nil || code != http.StatusOK {
+		t.Fatalf("Unexpected result: %d (%v)", code, sendErr)
+	}
+	if received.URL.Path != "/hook" || received.Header.Get(EventHeader) != entities.EventUserCreated || received.Header.Get(DeliveryHeader) != "3" {
+		t.Errorf("Wrong request: %s %v", received.URL, received.Header)
+	}
+	if verifyErr := Verify("secret", received.Header.Get(SignatureHeader), body, time.Minute, now); verifyErr != nil {
+		t.Errorf("Wrong signature: %s", verifyErr)
+	}
+	sent := Body{}
+	if unmarshalErr := json.Unmarshal(body, &sent); unmarshalErr != nil || sent.ID != 5 || sent.Type != entities.EventUserCreated || string(sent.Data) != `{"user_id":1}` {
+		t.Errorf("Wrong body: %s (%v)", body, unmarshalErr)
+	}
+
+	status = http.StatusServiceUnavailable
+	if code, sendErr := sender.Send(context.Background(), delivery); sendErr == nil || code != http.StatusServiceUnavailable {
+		t.Errorf("Expected error of status, got %d (%v)", code, sendErr)
+	}
+
+	receiver.Close()
+	if code, sendErr := sender.Send(context.Background(), delivery); sendErr == nil || code != 0 {
+		t.Errorf("Expected error of connection, got %d (%v)", code, sendErr)
+	}
+}
+
+// Test receivers on non-public addresses are refused at dial time
+func TestHTTPSenderRefusesInternalAddresses(t *testing.T) {
+	requested := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	defer receiver.Close()
+
+	delivery := &entities.WebhookDelivery{
+		ID:           1,
+		Subscription: entities.WebhookSubscription{ID: 1, URL: receiver.URL, Secret: "secret"},
+		Event:        entities.WebhookEvent{ID: 1, Type: entities.EventUserCreated, Payload: json.RawMessage(`{}`)},
+	}
+	code, sendErr := NewHTTPSender(time.Second, nil).Send(context.Background(), delivery)
+	if sendErr == nil || !strings.Contains(sendErr.Error(), "is not public") || code != 0 || requested {
+		t.Errorf("Expected refused connection, got %d (%v)", code, sendErr)
+	}
+}
+
+// Test public addresses are told from internal ones
+func TestIsPublicAddress(t *testing.T) {
+	testCases := []struct {
+		address string
+		public  bool
+	}{
+		{address: "93.184.216.34", public: true},
+		{address: "2606:2800:220:1:248:1893:25c8:1946", public: true},
+		{address: "127.0.0.1", public: false},
+		{address: "10.1.2.3", public: false},
+		{address: "172.16.0.1", public: false},
+		{address: "192.168.1.1", public: false},
+		{address: "169.254.169.254", public: false},
+		{address: "100.64.0.1", public: false},
+		{address: "0.0.0.0", public: false},
+		{address: "224.0.0.1", public: false},
+		{address: "::1", public: false},
+		{address: "fd00::1", public: false},
+		{address: "fe80::1", public: false},
+		{address: "::ffff:127.0.0.1", public: false},
+	}
+	for _, testCase := range testCases {
+		if public := IsPublicAddress(net.ParseIP(testCase.address)); public != testCase.public {
+			t.Errorf("%s: expected %t, got %t", testCase.address, testCase.public, public)
+		}
+	}
+}
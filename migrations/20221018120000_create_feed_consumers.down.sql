@@ -0,0 +1,3 @@
+drop table feed_consumers;
+drop index wallet_operations_tx_id_idx;
+alter table wallet_operations drop column tx_id;
@@ -0,0 +1,13 @@
+-- Transaction id orders operations by commit visibility: ids of rows inserted by
+-- concurrent transactions may be committed out of order
+alter table wallet_operations add column tx_id bigint not null default txid_current();
+create index wallet_operations_tx_id_idx on wallet_operations (tx_id, id);
+
+create table feed_consumers (
+    name varchar(64) PRIMARY KEY,
+    last_tx_id bigint NOT NULL default 0,
+    last_operation_id int NOT NULL default 0,
+    last_created_at timestamp without time zone,
+    acked_at timestamp without time zone,
+    created_at timestamp without time zone default current_timestamp
+);
@@ -0,0 +1 @@
+drop table report_templates;
@@ -0,0 +1,8 @@
+create table report_templates (
+    name varchar(64) PRIMARY KEY,
+    header text NOT NULL default '',
+    row text NOT NULL,
+    footer text NOT NULL default '',
+    created_at timestamp without time zone default current_timestamp,
+    updated_at timestamp without time zone default current_timestamp
+);
@@ -0,0 +1,11 @@
+create table api_keys (
+    id SERIAL PRIMARY KEY,
+    name varchar(100) NOT NULL,
+    prefix varchar(16) NOT NULL,
+    key_hash char(64) NOT NULL UNIQUE,
+    user_id INT,
+    scopes varchar(255) NOT NULL default '',
+    created_at timestamp without time zone default current_timestamp,
+    revoked_at timestamp without time zone,
+    CONSTRAINT fk_api_key_user FOREIGN KEY(user_id) REFERENCES users(id)
+);
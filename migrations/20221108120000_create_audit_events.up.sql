@@ -0,0 +1,13 @@
+create table audit_events (
+    id SERIAL PRIMARY KEY,
+    subject varchar(255) NOT NULL default '',
+    auth_method varchar(16) NOT NULL default '',
+    action varchar(64) NOT NULL,
+    permission varchar(64) NOT NULL default '',
+    outcome varchar(16) NOT NULL,
+    request_method varchar(16) NOT NULL,
+    path varchar(2048) NOT NULL,
+    remote_addr varchar(255) NOT NULL default '',
+    created_at timestamp without time zone default current_timestamp
+);
+create index audit_events_subject_idx on audit_events(subject, created_at);
@@ -0,0 +1 @@
+drop table rate_limit_buckets;
\ No newline at end of file
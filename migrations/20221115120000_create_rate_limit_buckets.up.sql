@@ -0,0 +1,6 @@
+create table rate_limit_buckets (
+    key varchar(255) PRIMARY KEY,
+    tokens double precision NOT NULL,
+    allowed boolean NOT NULL default true,
+    updated_at timestamp with time zone NOT NULL default now()
+);
@@ -0,0 +1,4 @@
+drop table webhook_attempts;
+drop table webhook_deliveries;
+drop table webhook_events;
+drop table webhook_subscriptions;
\ No newline at end of file
@@ -0,0 +1,36 @@
+create table webhook_subscriptions (
+    id SERIAL PRIMARY KEY,
+    url varchar(2048) NOT NULL,
+    event_types text[] NOT NULL,
+    secret varchar(255) NOT NULL,
+    active boolean NOT NULL default true,
+    created_at timestamp without time zone default current_timestamp
+);
+create table webhook_events (
+    id SERIAL PRIMARY KEY,
+    type varchar(64) NOT NULL,
+    payload jsonb NOT NULL,
+    created_at timestamp without time zone default current_timestamp
+);
+create table webhook_deliveries (
+    id SERIAL PRIMARY KEY,
+    subscription_id INT NOT NULL,
+    event_id INT NOT NULL,
+    status varchar(16) NOT NULL default 'pending',
+    attempts INT NOT NULL default 0,
+    next_attempt_at timestamp without time zone NOT NULL default current_timestamp,
+    updated_at timestamp without time zone default current_timestamp,
+    CONSTRAINT fk_delivery_subscription FOREIGN KEY(subscription_id) REFERENCES webhook_subscriptions(id),
+    CONSTRAINT fk_delivery_event FOREIGN KEY(event_id) REFERENCES webhook_events(id)
+);
+create index webhook_deliveries_due_idx on webhook_deliveries(next_attempt_at) where status = 'pending';
+create table webhook_attempts (
+    id SERIAL PRIMARY KEY,
+    delivery_id INT NOT NULL,
+    attempt INT NOT NULL,
+    status_code INT NOT NULL default 0,
+    error text NOT NULL default '',
+    duration_ms INT NOT NULL default 0,
+    created_at timestamp without time zone default current_timestamp,
+    CONSTRAINT fk_attempt_delivery FOREIGN KEY(delivery_id) REFERENCES webhook_deliveries(id)
+);
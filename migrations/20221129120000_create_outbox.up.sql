@@ -0,0 +1,11 @@
+create table outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_type varchar(64) NOT NULL,
+    payload jsonb NOT NULL,
+    attempts INT NOT NULL default 0,
+    last_error text NOT NULL default '',
+    next_attempt_at timestamp without time zone NOT NULL default current_timestamp,
+    created_at timestamp without time zone default current_timestamp,
+    sent_at timestamp without time zone
+);
+create index outbox_pending_idx on outbox(next_attempt_at) where sent_at is null;
@@ -0,0 +1,3 @@
+drop trigger wallet_operations_notify on wallet_operations;
+drop trigger wallets_notify on wallets;
+drop function notify_wallet_event();
@@ -0,0 +1,17 @@
+-- Wallet events are notified on commit with wallet id as payload; listeners read changes
+-- from wallets and wallet_operations
+create function notify_wallet_event() returns trigger as $$
+begin
+    if TG_TABLE_NAME = 'wallets' then
+        perform pg_notify('wallet_events', NEW.id::text);
+    else
+        perform pg_notify('wallet_events', NEW.wallet_to::text);
+    end if;
+    return NEW;
+end;
+$$ language plpgsql;
+
+create trigger wallets_notify after update of balance on wallets
+    for each row when (OLD.balance is distinct from NEW.balance) execute procedure notify_wallet_event();
+create trigger wallet_operations_notify after insert on wallet_operations
+    for each row execute procedure notify_wallet_event();
@@ -0,0 +1,3 @@
+drop trigger if exists wallets_frozen on wallets;
+drop function if exists check_wallet_frozen();
+alter table wallets drop column if exists frozen;
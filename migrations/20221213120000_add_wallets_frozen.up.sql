@@ -0,0 +1,11 @@
+-- Balance of frozen wallet can not be changed, so transfers and enrollments of the wallet fail
+alter table wallets add column frozen boolean not null default false;
+
+create function check_wallet_frozen() returns trigger as $$
+begin
+    raise exception 'wallet % is frozen', NEW.id using errcode = 'check_violation';
+end;
+$$ language plpgsql;
+
+create trigger wallets_frozen before update of balance on wallets
+    for each row when (OLD.frozen and OLD.balance is distinct from NEW.balance) execute procedure check_wallet_frozen();
@@ -0,0 +1 @@
+alter table webhook_events drop column if exists outbox_id;
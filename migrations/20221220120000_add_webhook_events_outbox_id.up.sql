@@ -0,0 +1,3 @@
+-- Webhook event is stored once per outbox event, so repeated dispatching does not duplicate deliveries
+alter table webhook_events add column outbox_id bigint;
+alter table webhook_events add constraint webhook_events_outbox_id_key unique (outbox_id);
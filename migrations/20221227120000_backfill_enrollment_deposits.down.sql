@@ -0,0 +1,2 @@
+-- Backfilled deposits can not be told from deposits of enrollments, so they are kept
+select 1;
@@ -0,0 +1,14 @@
+-- Enrollments before deposit operations changed balances only, so their amounts are restored as deposits;
+-- deposits are dated by creation of wallets to keep opening balances of statements non-negative
+insert into wallet_operations (operation, wallet_from, wallet_to, amount, created_at)
+select 'deposit', null, balances.id, balances.balance - balances.ledger, balances.created_at
+from (
+    select w.id, w.balance,
+        coalesce(sum(o.amount) filter (where o.operation = 'deposit'), 0) - coalesce(sum(o.amount) filter (where o.operation = 'withdrawal'), 0) as ledger,
+        coalesce(min(o.created_at), current_timestamp) as created_at
+    from wallets w
+    left join wallet_operations o on o.wallet_to = w.id
+    group by w.id, w.balance
+) as balances
+where balances.balance > balances.ledger
+order by balances.id;
@@ -0,0 +1 @@
+alter table feed_consumers drop column owner;
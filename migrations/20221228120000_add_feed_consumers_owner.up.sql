@@ -0,0 +1,3 @@
+-- Owner is subject of principal which created the consumer; consumers created before
+-- owners were recorded have no owner and are available to admins only
+alter table feed_consumers add column owner varchar(255);
@@ -0,0 +1,11 @@
+// Package migrations embeds SQL migrations of the database; they are applied by `billing migrate`.
+//
+// Migration is a pair of <version>_<name>.up.sql and <version>_<name>.down.sql files.
+package migrations
+
+import "embed"
+
+// FS contains SQL files of migrations
+//
+//go:embed *.sql
+var FS embed.FS
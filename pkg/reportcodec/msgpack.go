@@ -0,0 +1,239 @@
+package reportcodec
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// msgpackTimestampType is the extension type of MessagePack timestamps
+const msgpackTimestampType = -1
+
+// maxMsgpackDepth limits nesting of decoded arrays and maps
+const maxMsgpackDepth = 32
+
+// ErrInvalidMsgpack is returned for malformed MessagePack values
+var ErrInvalidMsgpack = errors.New("invalid msgpack value")
+
+// MsgpackReader reads rows from MessagePack report. Report is a stream of maps,
+// one per row, keyed by column names. Values are decoded to nil, bool, int64, uint64,
+// float64, string, []byte, time.Time, []interface{} and map[string]interface{}.
+type MsgpackReader struct {
+	r *bufio.Reader
+}
+
+// NewMsgpackReader returns reader of MessagePack report
+func NewMsgpackReader(r io.Reader) *MsgpackReader {
+	return &MsgpackReader{
+		r: bufio.NewReader(r),
+	}
+}
+
+// Next returns next row of the report or io.EOF at the end of the report
+func (mr *MsgpackReader) Next() (map[string]interface{}, error) {
+	if _, peekErr := mr.r.Peek(1); peekErr != nil {
+		return nil, peekErr
+	}
+	value, decodeErr := mr.decode(0)
+	if decodeErr != nil {
+		if decodeErr == io.EOF {
+			decodeErr = io.ErrUnexpectedEOF
+		}
+		return nil, decodeErr
+	}
+	row, isMap := value.(map[string]interface{})
+	if !isMap {
+		return nil, fmt.Errorf("%s: row is %T, not a map", ErrInvalidMsgpack, value)
+	}
+	return row, nil
+}
+
+// decode reads single value of any type
+func (mr *MsgpackReader) decode(depth int) (interface{}, error) {
+	if depth > maxMsgpackDepth {
+		return nil, fmt.Errorf("%s: nesting is too deep", ErrInvalidMsgpack)
+	}
+	code, readErr := mr.r.ReadByte()
+	if readErr != nil {
+		return nil, readErr
+	}
+	switch {
+	case code <= 0x7f:
+		return int64(code), nil
+	case code >= 0xe0:
+		return int64(int8(code)), nil
+	case code&0xf0 == 0x80:
+		return mr.decodeMap(int(code&0x0f), depth)
+	case code&0xf0 == 0x90:
+		return mr.decodeArray(int(code&0x0f), depth)
+	case code&0xe0 == 0xa0:
+		return mr.decodeString(int(code & 0x1f))
+	}
+
+	switch code {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		size, sizeErr := mr.readUint(1 << (code - 0xc4))
+		if sizeErr != nil {
+			return nil, sizeErr
+		}
+		return mr.readBytes(size)
+	case 0xc7, 0xc8, 0xc9:
+		size, sizeErr := mr.readUint(1 << (code - 0xc7))
+		if sizeErr != nil {
+			return nil, sizeErr
+		}
+		return mr.decodeExtension(size)
+	case 0xca:
+		bits, bitsErr := mr.readUint(4)
+		return float64(math.Float32frombits(uint32(bits))), bitsErr
+	case 0xcb:
+		bits, bitsErr := mr.readUint(8)
+		return math.Float64frombits(bits), bitsErr
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		return mr.readUint(1 << (code - 0xcc))
+	case 0xd0:
+		value, valueErr := mr.readUint(1)
+		return int64(int8(value)), valueErr
+	case 0xd1:
+		value, valueErr := mr.readUint(2)
+		return int64(int16(value)), valueErr
+	case 0xd2:
+		value, valueErr := mr.readUint(4)
+		return int64(int32(value)), valueErr
+	case 0xd3:
+		value, valueErr := mr.readUint(8)
+		return int64(value), valueErr
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return mr.decodeExtension(1 << (code - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		size, sizeErr := mr.readUint(1 << (code - 0xd9))
+		if sizeErr != nil {
+			return nil, sizeErr
+		}
+		return mr.decodeString(int(size))
+	case 0xdc, 0xdd:
+		size, sizeErr := mr.readUint(2 << (code - 0xdc))
+		if sizeErr != nil {
+			return nil, sizeErr
+		}
+		return mr.decodeArray(int(size), depth)
+	case 0xde, 0xdf:
+		size, sizeErr := mr.readUint(2 << (code - 0xde))
+		if sizeErr != nil {
+			return nil, sizeErr
+		}
+		return mr.decodeMap(int(size), depth)
+	}
+	return nil, fmt.Errorf("%s: unknown type 0x%x", ErrInvalidMsgpack, code)
+}
+
+// decodeMap reads map with string keys
+func (mr *MsgpackReader) decodeMap(size, depth int) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, minSize(size))
+	for idx := 0; idx < size; idx++ {
+		key, keyErr := mr.decode(depth + 1)
+		if keyErr != nil {
+			return nil, keyErr
+		}
+		name, isString := key.(string)
+		if !isString {
+			return nil, fmt.Errorf("%s: key of map is %T, not a string", ErrInvalidMsgpack, key)
+		}
+		value, valueErr := mr.decode(depth + 1)
+		if valueErr != nil {
+			return nil, valueErr
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// decodeArray reads array of values
+func (mr *MsgpackReader) decodeArray(size, depth int) ([]interface{}, error) {
+	values := make([]interface{}, 0, minSize(size))
+	for idx := 0; idx < size; idx++ {
+		value, valueErr := mr.decode(depth + 1)
+		if valueErr != nil {
+			return nil, valueErr
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func (mr *MsgpackReader) decodeString(size int) (string, error) {
+	data, readErr := mr.readBytes(uint64(size))
+	return string(data), readErr
+}
+
+// decodeExtension reads extension value; only timestamps are supported
+func (mr *MsgpackReader) decodeExtension(size uint64) (time.Time, error) {
+	extType, typeErr := mr.r.ReadByte()
+	if typeErr != nil {
+		return time.Time{}, typeErr
+	}
+	if int8(extType) != msgpackTimestampType {
+		return time.Time{}, fmt.Errorf("%s: unsupported extension type %d", ErrInvalidMsgpack, int8(extType))
+	}
+	data, readErr := mr.readBytes(size)
+	if readErr != nil {
+		return time.Time{}, readErr
+	}
+	switch len(data) {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(data)), 0).UTC(), nil
+	case 8:
+		value := binary.BigEndian.Uint64(data)
+		return time.Unix(int64(value&(1<<34-1)), int64(value>>34)).UTC(), nil
+	case 12:
+		nanos := binary.BigEndian.Uint32(data[:4])
+		return time.Unix(int64(binary.BigEndian.Uint64(data[4:])), int64(nanos)).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("%s: timestamp of %d bytes", ErrInvalidMsgpack, len(data))
+}
+
+// readUint reads big-endian unsigned integer of given size
+func (mr *MsgpackReader) readUint(size int) (uint64, error) {
+	data := make([]byte, size)
+	if _, readErr := io.ReadFull(mr.r, data); readErr != nil {
+		return 0, readErr
+	}
+	var value uint64
+	for _, b := range data {
+		value = value<<8 | uint64(b)
+	}
+	return value, nil
+}
+
+// readBytes reads value's bytes; size is limited to protect from malformed reports
+func (mr *MsgpackReader) readBytes(size uint64) ([]byte, error) {
+	if size > MaxMessageSize {
+		return nil, fmt.Errorf("%s: value of %d bytes exceeds limit", ErrInvalidMsgpack, size)
+	}
+	data := make([]byte, size)
+	if _, readErr := io.ReadFull(mr.r, data); readErr != nil {
+		if readErr == io.EOF && size > 0 {
+			readErr = io.ErrUnexpectedEOF
+		}
+		return nil, readErr
+	}
+	return data, nil
+}
+
+// minSize limits preallocation for declared size of map or array
+func minSize(size int) int {
+	if size > 64 {
+		return 64
+	}
+	return size
+}
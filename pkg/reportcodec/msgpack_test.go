@@ -0,0 +1,91 @@
+package reportcodec
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test decoding of values of all msgpack types
+func TestMsgpackReaderTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected interface{}
+	}{
+		{name: "nil", value: "c0", expected: nil},
+		{name: "bool", value: "c3", expected: true},
+		{name: "positive fixint", value: "7f", expected: int64(127)},
+		{name: "negative fixint", value: "e0", expected: int64(-32)},
+		{name: "uint16", value: "cd0100", expected: uint64(256)},
+		{name: "uint64", value: "cfffffffffffffffff", expected: uint64(1<<64 - 1)},
+		{name: "int8", value: "d080", expected: int64(-128)},
+		{name: "int32", value: "d2ffff0000", expected: int64(-65536)},
+		{name: "float32", value: "ca3fc00000", expected: float64(1.5)},
+		{name: "float64", value: "cb4024000000000000", expected: float64(10)},
+		{name: "fixstr", value: "a3555344", expected: "USD"},
+		{name: "str8", value: "d90461626364", expected: "abcd"},
+		{name: "bin8", value: "c4020102", expected: []byte{1, 2}},
+		{name: "fixarray", value: "920102", expected: []interface{}{int64(1), int64(2)}},
+		{name: "map16", value: "de0001a161c0", expected: map[string]interface{}{"a": nil}},
+		{name: "timestamp32", value: "d6ff603c2e80", expected: time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "timestamp64", value: "d7ff000007d0603c2e80", expected: time.Date(2021, time.March, 1, 0, 0, 0, 500, time.UTC)},
+		{name: "timestamp96", value: "c70cff00000001ffffffffffffffff", expected: time.Date(1969, time.December, 31, 23, 59, 59, 1, time.UTC)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Values are wrapped into row {"v": value}
+			data, _ := hex.DecodeString("81a176" + tc.value)
+			row, err := NewMsgpackReader(bytes.NewReader(data)).Next()
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(row["v"], tc.expected) {
+				t.Errorf("Expected %#v, got %#v", tc.expected, row["v"])
+			}
+		})
+	}
+}
+
+// Test reading of rows stream and malformed reports
+func TestMsgpackReaderStream(t *testing.T) {
+	// {"id": 1} {"id": 2}
+	data, _ := hex.DecodeString("81a2696401" + "81a2696402")
+	reader := NewMsgpackReader(bytes.NewReader(data))
+	for _, expected := range []int64{1, 2} {
+		row, err := reader.Next()
+		if err != nil || row["id"] != expected {
+			t.Fatalf("Expected row with id %d, got %v (%v)", expected, row, err)
+		}
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		err   string
+	}{
+		{name: "Truncated row", value: "82a2696401", err: io.ErrUnexpectedEOF.Error()},
+		{name: "Row is not a map", value: "01", err: "row is int64, not a map"},
+		{name: "Key is not a string", value: "810101", err: "key of map is int64"},
+		{name: "Unknown type", value: "c1", err: "unknown type 0xc1"},
+		{name: "Unsupported extension", value: "81a176d40501", err: "unsupported extension type 5"},
+		{name: "Too large value", value: "81a176db7fffffff", err: "exceeds limit"},
+		{name: "Too deep nesting", value: strings.Repeat("91", 40) + "c0", err: "nesting is too deep"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(tc.value)
+			_, err := NewMsgpackReader(bytes.NewReader(data)).Next()
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("Expected error '%s', got %v", tc.err, err)
+			}
+		})
+	}
+}
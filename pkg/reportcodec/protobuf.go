@@ -0,0 +1,301 @@
+// Package reportcodec reads binary reports of billing service: length-delimited
+// protobuf stream of wallet operations (see wallet_operation.proto) and stream of
+// MessagePack maps.
+package reportcodec
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// MaxMessageSize limits size of a single message of report stream
+const MaxMessageSize = 1 << 20
+
+// Field numbers of WalletOperation message
+const (
+	fieldID         = 1
+	fieldOperation  = 2
+	fieldWalletFrom = 3
+	fieldWalletTo   = 4
+	fieldAmount     = 5
+	fieldCreatedAt  = 6
+)
+
+// Protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// ErrInvalidMessage is returned for malformed protobuf messages
+var ErrInvalidMessage = errors.New("invalid protobuf message")
+
+// WalletOperation represents billing.reports.v1.WalletOperation message
+type WalletOperation struct {
+	ID         int64
+	Operation  string
+	WalletFrom *int64
+	WalletTo   int64
+	Amount     string
+	CreatedAt  time.Time
+}
+
+// Marshal encodes operation to protobuf wire format
+func (wo *WalletOperation) Marshal() []byte {
+	data := make([]byte, 0, 64)
+	if wo.ID != 0 {
+		data = appendVarintField(data, fieldID, uint64(wo.ID))
+	}
+	if wo.Operation != "" {
+		data = appendBytesField(data, fieldOperation, []byte(wo.Operation))
+	}
+	if wo.WalletFrom != nil {
+		data = appendVarintField(data, fieldWalletFrom, uint64(*wo.WalletFrom))
+	}
+	if wo.WalletTo != 0 {
+		data = appendVarintField(data, fieldWalletTo, uint64(wo.WalletTo))
+	}
+	if wo.Amount != "" {
+		data = appendBytesField(data, fieldAmount, []byte(wo.Amount))
+	}
+	if !wo.CreatedAt.IsZero() {
+		data = appendBytesField(data, fieldCreatedAt, marshalTimestamp(wo.CreatedAt))
+	}
+	return data
+}
+
+// AppendDelimited appends operation prefixed with its size to data
+func (wo *WalletOperation) AppendDelimited(data []byte) []byte {
+	message := wo.Marshal()
+	data = appendVarint(data, uint64(len(message)))
+	return append(data, message...)
+}
+
+// Unmarshal decodes operation from protobuf wire format; unknown fields are skipped
+func (wo *WalletOperation) Unmarshal(data []byte) error {
+	*wo = WalletOperation{}
+	for len(data) > 0 {
+		field, wireType, value, rest, fieldErr := consumeField(data)
+		if fieldErr != nil {
+			return fieldErr
+		}
+		data = rest
+
+		expected, known := operationWireTypes[field]
+		if !known {
+			continue
+		}
+		if wireType != expected {
+			return fmt.Errorf("%s: wrong wire type %d of field %d", ErrInvalidMessage, wireType, field)
+		}
+		switch field {
+		case fieldID:
+			wo.ID = int64(value.varint)
+		case fieldOperation:
+			wo.Operation = string(value.bytes)
+		case fieldWalletFrom:
+			walletFrom := int64(value.varint)
+			wo.WalletFrom = &walletFrom
+		case fieldWalletTo:
+			wo.WalletTo = int64(value.varint)
+		case fieldAmount:
+			wo.Amount = string(value.bytes)
+		case fieldCreatedAt:
+			createdAt, timestampErr := unmarshalTimestamp(value.bytes)
+			if timestampErr != nil {
+				return timestampErr
+			}
+			wo.CreatedAt = createdAt
+		}
+	}
+	return nil
+}
+
+// Wire types of WalletOperation fields
+var operationWireTypes = map[uint64]int{
+	fieldID:         wireVarint,
+	fieldOperation:  wireBytes,
+	fieldWalletFrom: wireVarint,
+	fieldWalletTo:   wireVarint,
+	fieldAmount:     wireBytes,
+	fieldCreatedAt:  wireBytes,
+}
+
+// OperationReader reads wallet operations from length-delimited protobuf report
+type OperationReader struct {
+	r      *bufio.Reader
+	buffer []byte
+}
+
+// NewOperationReader returns reader of protobuf report
+func NewOperationReader(r io.Reader) *OperationReader {
+	return &OperationReader{
+		r: bufio.NewReader(r),
+	}
+}
+
+// Next returns next operation of the report or io.EOF at the end of the report
+func (or *OperationReader) Next() (*WalletOperation, error) {
+	size, sizeErr := readVarint(or.r)
+	if sizeErr != nil {
+		return nil, sizeErr
+	}
+	if size > MaxMessageSize {
+		return nil, fmt.Errorf("%s: message of %d bytes exceeds limit", ErrInvalidMessage, size)
+	}
+	if uint64(cap(or.buffer)) < size {
+		or.buffer = make([]byte, size)
+	}
+	message := or.buffer[:size]
+	if _, readErr := io.ReadFull(or.r, message); readErr != nil {
+		if readErr == io.EOF {
+			readErr = io.ErrUnexpectedEOF
+		}
+		return nil, readErr
+	}
+	operation := &WalletOperation{}
+	if unmarshalErr := operation.Unmarshal(message); unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+	return operation, nil
+}
+
+// fieldValue represents value of decoded field
+type fieldValue struct {
+	varint uint64
+	bytes  []byte
+}
+
+// consumeField decodes field's tag and value and returns rest of the message
+func consumeField(data []byte) (uint64, int, fieldValue, []byte, error) {
+	value := fieldValue{}
+	tag, n := consumeVarint(data)
+	if n == 0 || tag>>3 == 0 {
+		return 0, 0, value, nil, fmt.Errorf("%s: invalid tag", ErrInvalidMessage)
+	}
+	data = data[n:]
+	field, wireType := tag>>3, int(tag&7)
+	switch wireType {
+	case wireVarint:
+		value.varint, n = consumeVarint(data)
+		if n == 0 {
+			return 0, 0, value, nil, fmt.Errorf("%s: invalid varint of field %d", ErrInvalidMessage, field)
+		}
+	case wireFixed64:
+		n = 8
+	case wireFixed32:
+		n = 4
+	case wireBytes:
+		size, sizeLen := consumeVarint(data)
+		if sizeLen == 0 || size > uint64(len(data)-sizeLen) {
+			return 0, 0, value, nil, fmt.Errorf("%s: invalid length of field %d", ErrInvalidMessage, field)
+		}
+		value.bytes = data[sizeLen : sizeLen+int(size)]
+		n = sizeLen + int(size)
+	default:
+		return 0, 0, value, nil, fmt.Errorf("%s: unsupported wire type %d", ErrInvalidMessage, wireType)
+	}
+	if n > len(data) {
+		return 0, 0, value, nil, fmt.Errorf("%s: truncated field %d", ErrInvalidMessage, field)
+	}
+	return field, wireType, value, data[n:], nil
+}
+
+// marshalTimestamp encodes time as google.protobuf.Timestamp message
+func marshalTimestamp(t time.Time) []byte {
+	data := make([]byte, 0, 16)
+	if seconds := t.Unix(); seconds != 0 {
+		data = appendVarintField(data, 1, uint64(seconds))
+	}
+	if nanos := t.Nanosecond(); nanos != 0 {
+		data = appendVarintField(data, 2, uint64(nanos))
+	}
+	return data
+}
+
+// unmarshalTimestamp decodes google.protobuf.Timestamp message to UTC time
+func unmarshalTimestamp(data []byte) (time.Time, error) {
+	var seconds, nanos int64
+	for len(data) > 0 {
+		field, wireType, value, rest, fieldErr := consumeField(data)
+		if fieldErr != nil {
+			return time.Time{}, fieldErr
+		}
+		data = rest
+		if (field == 1 || field == 2) && wireType != wireVarint {
+			return time.Time{}, fmt.Errorf("%s: wrong wire type %d of timestamp", ErrInvalidMessage, wireType)
+		}
+		switch field {
+		case 1:
+			seconds = int64(value.varint)
+		case 2:
+			nanos = int64(int32(value.varint))
+		}
+	}
+	if nanos < 0 || nanos > 999999999 {
+		return time.Time{}, fmt.Errorf("%s: invalid nanos of timestamp", ErrInvalidMessage)
+	}
+	return time.Unix(seconds, nanos).UTC(), nil
+}
+
+func appendVarintField(data []byte, field int, value uint64) []byte {
+	data = appendVarint(data, uint64(field)<<3|wireVarint)
+	return appendVarint(data, value)
+}
+
+func appendBytesField(data []byte, field int, value []byte) []byte {
+	data = appendVarint(data, uint64(field)<<3|wireBytes)
+	data = appendVarint(data, uint64(len(value)))
+	return append(data, value...)
+}
+
+func appendVarint(data []byte, value uint64) []byte {
+	for value >= 0x80 {
+		data = append(data, byte(value)|0x80)
+		value >>= 7
+	}
+	return append(data, byte(value))
+}
+
+// consumeVarint decodes varint; it returns zero length for invalid varint
+func consumeVarint(data []byte) (uint64, int) {
+	var value uint64
+	for idx := 0; idx < len(data) && idx < 10; idx++ {
+		b := data[idx]
+		if idx == 9 && b > 1 {
+			return 0, 0
+		}
+		value |= uint64(b&0x7f) << (7 * idx)
+		if b < 0x80 {
+			return value, idx + 1
+		}
+	}
+	return 0, 0
+}
+
+// readVarint reads varint from stream; it returns io.EOF only before the first byte
+func readVarint(r io.ByteReader) (uint64, error) {
+	var value uint64
+	for idx := 0; idx < 10; idx++ {
+		b, readErr := r.ReadByte()
+		if readErr != nil {
+			if readErr == io.EOF && idx > 0 {
+				readErr = io.ErrUnexpectedEOF
+			}
+			return 0, readErr
+		}
+		if idx == 9 && b > 1 {
+			break
+		}
+		value |= uint64(b&0x7f) << (7 * idx)
+		if b < 0x80 {
+			return value, nil
+		}
+	}
+	return 0, fmt.Errorf("%s: invalid varint", ErrInvalidMessage)
+}
@@ -0,0 +1,131 @@
+package reportcodec
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test encoding of operation according to protobuf wire format
+func TestWalletOperationMarshal(t *testing.T) {
+	walletFrom := int64(0)
+	operation := &WalletOperation{
+		ID:         150,
+		Operation:  "deposit",
+		WalletFrom: &walletFrom,
+		WalletTo:   2,
+		Amount:     "10.50",
+		CreatedAt:  time.Date(2021, time.March, 1, 0, 0, 0, 500, time.UTC),
+	}
+	// Set optional field is written even with default value
+	expected := "089601" + "12076465706f736974" + "1800" + "2002" + "2a0531302e3530" + "3209" + "0880ddf08106" + "10f403"
+	data := operation.Marshal()
+	if hex.EncodeToString(data) != expected {
+		t.Errorf("Wrong encoding.\nExpected: %s\nGot:      %x", expected, data)
+	}
+
+	decoded := &WalletOperation{}
+	if err := decoded.Unmarshal(data); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(decoded, operation) {
+		t.Errorf("Wrong decoded operation.\nExpected: %+v\nGot:      %+v", operation, decoded)
+	}
+
+	// Default values and unset optional field are not written
+	if data = (&WalletOperation{WalletTo: 3}).Marshal(); hex.EncodeToString(data) != "2003" {
+		t.Errorf("Wrong encoding of defaults: %x", data)
+	}
+}
+
+// Test decoding skips unknown fields and rejects malformed messages
+func TestWalletOperationUnmarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected *WalletOperation
+		err      string
+	}{
+		{
+			name: "Unknown fields of all wire types",
+			// wallet_to: 7, field 10 (varint), 11 (fixed64), 12 (bytes), 13 (fixed32)
+			data:     "2007" + "5001" + "590102030405060708" + "62026869" + "6d01020304",
+			expected: &WalletOperation{WalletTo: 7},
+		},
+		{
+			name:     "Negative wallet",
+			data:     "18ffffffffffffffffff01",
+			expected: &WalletOperation{WalletFrom: func() *int64 { v := int64(-1); return &v }()},
+		},
+		{name: "Truncated string", data: "1207646570", err: "invalid length of field 2"},
+		{name: "Wrong wire type", data: "0d01020304", err: "wrong wire type 5 of field 1"},
+		{name: "Zero field", data: "0001", err: "invalid tag"},
+		{name: "Invalid timestamp", data: "320b10ffffffffffffffffff01", err: "invalid nanos of timestamp"},
+		{name: "Unsupported wire type", data: "0b", err: "unsupported wire type 3"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(tc.data)
+			operation := &WalletOperation{}
+			err := operation.Unmarshal(data)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Errorf("Expected error '%s', got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(operation, tc.expected) {
+				t.Errorf("Expected %+v, got %+v", tc.expected, operation)
+			}
+		})
+	}
+}
+
+// Test reading of length-delimited stream
+func TestOperationReader(t *testing.T) {
+	operations := []*WalletOperation{
+		{ID: 1, Operation: "deposit", WalletTo: 1, Amount: "100"},
+		{},
+		{ID: 300, Operation: "withdrawal", WalletTo: 1, Amount: "0.01", CreatedAt: time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC)},
+	}
+	var stream []byte
+	for _, operation := range operations {
+		stream = operation.AppendDelimited(stream)
+	}
+
+	reader := NewOperationReader(bytes.NewReader(stream))
+	for idx, expected := range operations {
+		operation, err := reader.Next()
+		if err != nil {
+			t.Fatalf("[%d] Unexpected error: %s", idx, err)
+		}
+		if !reflect.DeepEqual(operation, expected) {
+			t.Errorf("[%d] Expected %+v, got %+v", idx, expected, operation)
+		}
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+
+	for _, truncated := range [][]byte{stream[:len(stream)-1], {0x80}} {
+		reader = NewOperationReader(bytes.NewReader(truncated))
+		var err error
+		for err == nil {
+			_, err = reader.Next()
+		}
+		if err != io.ErrUnexpectedEOF {
+			t.Errorf("Expected io.ErrUnexpectedEOF for truncated stream, got %v", err)
+		}
+	}
+
+	if _, err := NewOperationReader(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0x7f})).Next(); err == nil || !strings.Contains(err.Error(), "exceeds limit") {
+		t.Errorf("Expected size limit error, got %v", err)
+	}
+}